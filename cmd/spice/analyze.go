@@ -72,6 +72,30 @@ Examples:
 	return cmd
 }
 
+// openAnalysisStorage opens the SQLite database analysis runs against. Its
+// sessions are kept in SQLite, so it fails when storage.driver selects
+// PostgreSQL rather than reading a local file the other commands don't use.
+func openAnalysisStorage() (*storage.SQLiteStorage, error) {
+	postgres, err := usePostgres()
+	if err != nil {
+		return nil, err
+	}
+	if postgres {
+		return nil, fmt.Errorf("spice analyze keeps its sessions in SQLite and does not support storage.driver postgres")
+	}
+
+	dbPath := viper.GetString("database.path")
+	if dbPath == "" {
+		dbPath = "~/.local/share/spice/spice.db"
+	}
+
+	db, err := storage.NewSQLiteStorage(expandPath(dbPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	return db, nil
+}
+
 func runAnalyze(cmd *cobra.Command, _ []string) error {
 	ctx := cmd.Context()
 
@@ -134,15 +158,9 @@ func runAnalyze(cmd *cobra.Command, _ []string) error {
 		"focus", focusStr)
 
 	// Set up database
-	dbPath := viper.GetString("database.path")
-	if dbPath == "" {
-		dbPath = "~/.local/share/spice/spice.db"
-	}
-	dbPath = expandPath(dbPath)
-
-	db, err := storage.NewSQLiteStorage(dbPath)
+	db, err := openAnalysisStorage()
 	if err != nil {
-		return fmt.Errorf("failed to open database: %w", err)
+		return err
 	}
 	defer func() {
 		if closeErr := db.Close(); closeErr != nil {
@@ -24,11 +24,13 @@ func authCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "auth",
 		Short: "Authenticate with external services",
-		Long:  `Authenticate with external services like Plaid and Google Sheets.`,
+		Long: `Authenticate with external services like Plaid and Google Sheets, and check
+that a local LLM provider is set up.`,
 	}
 
 	cmd.AddCommand(authPlaidCmd())
 	cmd.AddCommand(authSheetsCmd())
+	cmd.AddCommand(authTestCmd())
 
 	return cmd
 }
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/llm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func authTestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test",
+		Short: "Check that an LLM provider is set up",
+		Long: `Check that an LLM provider is ready to classify transactions.
+
+For ollama, this checks that the Ollama server at llm.ollama.base_url
+(default http://localhost:11434) is running and that the configured model
+has been pulled.
+
+Examples:
+  spice auth test --provider ollama
+  spice auth test --provider ollama --model llama3:8b`,
+		RunE: runAuthTest,
+	}
+
+	cmd.Flags().String("provider", "", "LLM provider to test (defaults to llm.provider)")
+	cmd.Flags().String("model", "", "Model to look for (defaults to llm.model)")
+
+	return cmd
+}
+
+func runAuthTest(cmd *cobra.Command, _ []string) error {
+	provider, _ := cmd.Flags().GetString("provider")
+	if provider == "" {
+		provider = viper.GetString("llm.provider")
+	}
+	model, _ := cmd.Flags().GetString("model")
+	if model == "" {
+		model = viper.GetString("llm.model")
+	}
+
+	switch strings.ToLower(provider) {
+	case "ollama":
+		baseURL := viper.GetString("llm.ollama.base_url")
+		if baseURL == "" {
+			baseURL = llm.DefaultOllamaBaseURL
+		}
+		if model == "" {
+			model = llm.DefaultOllamaModel
+		}
+		if err := llm.PingOllama(cmd.Context(), baseURL, model); err != nil {
+			return fmt.Errorf("ollama check failed: %w", err)
+		}
+		slog.Info(fmt.Sprintf("✓ Ollama is running at %s and %s is available", baseURL, model))
+		return nil
+	case "":
+		return fmt.Errorf("no provider given; use --provider or set llm.provider")
+	default:
+		return fmt.Errorf("testing provider %s is not supported yet; only ollama can be tested", provider)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/classification"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/spf13/cobra"
+)
+
+func backfillCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backfill",
+		Short: "Fill in data missing from older transactions",
+		Long:  `Repair transactions imported before newer fields were tracked.`,
+	}
+
+	cmd.AddCommand(backfillDirectionsCmd())
+
+	return cmd
+}
+
+func backfillDirectionsCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "directions",
+		Short: "Set income/expense/transfer on transactions without a direction",
+		Long: `Infer a direction for every transaction that has none, such as those imported
+before directions were tracked or from sources that don't provide one.
+
+Each transaction is checked against the built-in direction patterns
+(payroll, refunds, transfers, ...), then its bank transaction type, then the
+type of the category it is classified in, and finally the sign of its amount.
+Transactions with no usable signal are left unchanged.
+
+Examples:
+  # Preview what would change
+  spice backfill directions --dry-run
+
+  # Update the database
+  spice backfill directions`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			store, err := initStorage(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+			defer func() {
+				if closeErr := store.Close(); closeErr != nil {
+					slog.Error("failed to close storage", "error", closeErr)
+				}
+			}()
+
+			rows, err := store.QueryTransactions(ctx, model.TransactionQuery{MissingDirection: true})
+			if err != nil {
+				return fmt.Errorf("failed to find transactions without a direction: %w", err)
+			}
+
+			categories, err := store.GetCategories(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get categories: %w", err)
+			}
+			categoryTypes := make(map[string]model.CategoryType, len(categories))
+			for _, cat := range categories {
+				categoryTypes[cat.Name] = cat.Type
+			}
+
+			detector, err := classification.NewPatternDetector(classification.DefaultPatterns())
+			if err != nil {
+				return fmt.Errorf("failed to initialize pattern detector: %w", err)
+			}
+
+			directions, undetermined, err := inferMissingDirections(ctx, detector, rows, categoryTypes)
+			if err != nil {
+				return err
+			}
+
+			if !dryRun && len(directions) > 0 {
+				if err := store.UpdateTransactionDirections(ctx, directions); err != nil {
+					return fmt.Errorf("failed to update directions: %w", err)
+				}
+			}
+
+			writeDirectionBackfillSummary(cmd.OutOrStdout(), directions, undetermined, dryRun)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be set without changing the database")
+
+	return cmd
+}
+
+// inferMissingDirections infers a direction for each transaction, returning the
+// directions keyed by transaction ID and the number that could not be determined.
+func inferMissingDirections(ctx context.Context, detector *classification.PatternDetector, rows []model.Classification, categoryTypes map[string]model.CategoryType) (map[string]model.TransactionDirection, int, error) {
+	directions := make(map[string]model.TransactionDirection, len(rows))
+	undetermined := 0
+	for _, row := range rows {
+		direction, err := classification.InferDirection(ctx, detector, row.Transaction, categoryTypes[row.Category])
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to infer direction for transaction %s: %w", row.Transaction.ID, err)
+		}
+		if direction == "" {
+			undetermined++
+			continue
+		}
+		directions[row.Transaction.ID] = direction
+	}
+	return directions, undetermined, nil
+}
+
+func writeDirectionBackfillSummary(w io.Writer, directions map[string]model.TransactionDirection, undetermined int, dryRun bool) {
+	if len(directions) == 0 && undetermined == 0 {
+		_, _ = fmt.Fprintln(w, "All transactions already have a direction")
+		return
+	}
+
+	counts := make(map[model.TransactionDirection]int)
+	for _, direction := range directions {
+		counts[direction]++
+	}
+
+	verb := "Set"
+	if dryRun {
+		verb = "Would set"
+	}
+	_, _ = fmt.Fprintf(w, "%s direction on %d transactions:\n", verb, len(directions))
+	_, _ = fmt.Fprintf(w, "  Income:   %d\n", counts[model.DirectionIncome])
+	_, _ = fmt.Fprintf(w, "  Expense:  %d\n", counts[model.DirectionExpense])
+	_, _ = fmt.Fprintf(w, "  Transfer: %d\n", counts[model.DirectionTransfer])
+	if undetermined > 0 {
+		_, _ = fmt.Fprintf(w, "%d transactions could not be determined and were left unchanged\n", undetermined)
+	}
+}
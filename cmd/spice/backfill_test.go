@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/classification"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInferMissingDirections(t *testing.T) {
+	detector, err := classification.NewPatternDetector(classification.DefaultPatterns())
+	require.NoError(t, err)
+
+	rows := []model.Classification{
+		{Transaction: model.Transaction{ID: "pay", Name: "ACME PAYROLL", Amount: 2000}},
+		{Transaction: model.Transaction{ID: "client", Name: "ACME LLC", Amount: 500}, Category: "Consulting"},
+		{Transaction: model.Transaction{ID: "shop", Name: "CORNER STORE", Amount: 12}},
+		{Transaction: model.Transaction{ID: "zero", Name: "CORNER STORE"}},
+	}
+	categoryTypes := map[string]model.CategoryType{"Consulting": model.CategoryTypeIncome}
+
+	directions, undetermined, err := inferMissingDirections(context.Background(), detector, rows, categoryTypes)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]model.TransactionDirection{
+		"pay":    model.DirectionIncome,
+		"client": model.DirectionIncome,
+		"shop":   model.DirectionExpense,
+	}, directions)
+	assert.Equal(t, 1, undetermined)
+
+	var buf bytes.Buffer
+	writeDirectionBackfillSummary(&buf, directions, undetermined, true)
+	out := buf.String()
+	assert.Contains(t, out, "Would set direction on 3 transactions")
+	assert.Contains(t, out, "Income:   2")
+	assert.Contains(t, out, "Expense:  1")
+	assert.Contains(t, out, "1 transactions could not be determined")
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/cli"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/spf13/cobra"
+)
+
+func businessCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "business",
+		Short: "Manage business expense percentages",
+		Long:  `Manage the business percentage of classified transactions used for the Business Expenses report.`,
+	}
+
+	cmd.AddCommand(businessSetCmd())
+
+	return cmd
+}
+
+func businessSetCmd() *cobra.Command {
+	var (
+		category string
+		vendor   string
+		percent  int
+		dryRun   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "set",
+		Short: "Bulk-set the business percentage for a category or vendor",
+		Long: `Set the business percentage on all existing classifications for a category
+or a vendor. Income and system categories are never changed.
+
+Examples:
+  # Mark all office supplies as fully deductible
+  spice business set --category "Office Supplies" --percent 100
+
+  # Mark a vendor as half business use
+  spice business set --vendor "Verizon" --percent 50
+
+  # Preview the affected transactions without saving
+  spice business set --vendor "Verizon" --percent 50 --dry-run`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			if (category == "") == (vendor == "") {
+				return fmt.Errorf("specify exactly one of --category or --vendor")
+			}
+			if !cmd.Flags().Changed("percent") {
+				return fmt.Errorf("--percent is required")
+			}
+			if percent < 0 || percent > 100 {
+				return fmt.Errorf("business percentage must be between 0 and 100")
+			}
+
+			store, err := initStorage(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+			defer func() {
+				if closeErr := store.Close(); closeErr != nil {
+					slog.Error("failed to close storage", "error", closeErr)
+				}
+			}()
+
+			if dryRun {
+				classifications, err := store.GetClassificationsByDateRange(ctx, time.Time{}, time.Now().AddDate(100, 0, 0))
+				if err != nil {
+					return fmt.Errorf("failed to get classifications: %w", err)
+				}
+				categories, err := store.GetCategories(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to get categories: %w", err)
+				}
+
+				affected := filterBusinessPercentTargets(classifications, categories, category, vendor)
+				return printBusinessPercentPreview(affected, percent)
+			}
+
+			var updated int64
+			target := category
+			if category != "" {
+				updated, err = store.UpdateBusinessPercentByCategory(ctx, category, percent)
+			} else {
+				target = vendor
+				updated, err = store.UpdateBusinessPercentByVendor(ctx, vendor, percent)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to update business percentage: %w", err)
+			}
+
+			fmt.Println(cli.FormatSuccess(fmt.Sprintf("✓ Set business percentage to %d%% for %d transactions (%s)", percent, updated, target))) //nolint:forbidigo // User-facing output
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&category, "category", "c", "", "Category whose classifications should be updated")
+	cmd.Flags().StringVarP(&vendor, "vendor", "v", "", "Vendor (merchant name) whose classifications should be updated")
+	cmd.Flags().IntVarP(&percent, "percent", "p", 0, "Business percentage to set (0-100)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview affected transactions without saving")
+
+	return cmd
+}
+
+// filterBusinessPercentTargets returns the classifications a bulk business percentage
+// update would touch, mirroring the storage update rules.
+func filterBusinessPercentTargets(classifications []model.Classification, categories []model.Category, category, vendor string) []model.Classification {
+	categoryTypes := make(map[string]model.CategoryType, len(categories))
+	for _, cat := range categories {
+		categoryTypes[cat.Name] = cat.Type
+	}
+
+	var affected []model.Classification
+	for _, c := range classifications {
+		if catType := categoryTypes[c.Category]; catType == model.CategoryTypeIncome || catType == model.CategoryTypeSystem {
+			continue
+		}
+
+		if category != "" && c.Category != category {
+			continue
+		}
+		if vendor != "" && businessVendorName(c.Transaction) != vendor {
+			continue
+		}
+
+		affected = append(affected, c)
+	}
+
+	sort.Slice(affected, func(i, j int) bool {
+		return affected[i].Transaction.Date.Before(affected[j].Transaction.Date)
+	})
+
+	return affected
+}
+
+// businessVendorName returns the merchant name, falling back to the raw name.
+func businessVendorName(txn model.Transaction) string {
+	if strings.TrimSpace(txn.MerchantName) == "" {
+		return txn.Name
+	}
+	return txn.MerchantName
+}
+
+// printBusinessPercentPreview shows each affected transaction with its deductible change.
+func printBusinessPercentPreview(affected []model.Classification, percent int) error {
+	if len(affected) == 0 {
+		fmt.Println(cli.InfoStyle.Render("No matching classifications found.")) //nolint:forbidigo // User-facing output
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "DATE\tVENDOR\tCATEGORY\tAMOUNT\tOLD %\tNEW %\tDEDUCTIBLE CHANGE")
+	_, _ = fmt.Fprintln(w, "────\t──────\t────────\t──────\t─────\t─────\t─────────────────")
+
+	var totalChange float64
+	changed := 0
+	for _, c := range affected {
+		change := c.Transaction.Amount * (float64(percent) - c.BusinessPercent) / 100
+		totalChange += change
+		if c.BusinessPercent != float64(percent) {
+			changed++
+		}
+
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t$%.2f\t%.0f%%\t%d%%\t%+.2f\n",
+			c.Transaction.Date.Format("2006-01-02"),
+			businessVendorName(c.Transaction),
+			c.Category,
+			c.Transaction.Amount,
+			c.BusinessPercent,
+			percent,
+			change)
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Println()                                                                                      //nolint:forbidigo // User-facing output
+	fmt.Printf("%d transactions match, %d would change\n", len(affected), changed)                     //nolint:forbidigo // User-facing output
+	fmt.Printf("Net deductible change: %+.2f\n", totalChange)                                          //nolint:forbidigo // User-facing output
+	fmt.Println(cli.InfoStyle.Render("Dry run: no changes saved. Re-run without --dry-run to apply.")) //nolint:forbidigo // User-facing output
+
+	return nil
+}
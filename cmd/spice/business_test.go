@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterBusinessPercentTargets(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	categories := []model.Category{
+		{Name: "Office Supplies", Type: model.CategoryTypeExpense},
+		{Name: "Phone", Type: model.CategoryTypeExpense},
+		{Name: "Refunds", Type: model.CategoryTypeIncome},
+	}
+
+	classifications := []model.Classification{
+		{Category: "Phone", Transaction: model.Transaction{ID: "3", MerchantName: "Verizon", Date: day(20)}},
+		{Category: "Office Supplies", Transaction: model.Transaction{ID: "1", MerchantName: "Staples", Date: day(5)}},
+		{Category: "Office Supplies", Transaction: model.Transaction{ID: "2", Name: "STAPLES", Date: day(10)}},
+		{Category: "Phone", Transaction: model.Transaction{ID: "4", MerchantName: "Verizon", Date: day(1)}},
+		{Category: "Refunds", Transaction: model.Transaction{ID: "5", MerchantName: "Verizon", Date: day(2)}},
+		{Category: "Phone", Transaction: model.Transaction{ID: "6", Name: "Verizon", Date: day(3)}},
+	}
+
+	ids := func(cs []model.Classification) []string {
+		out := make([]string, 0, len(cs))
+		for _, c := range cs {
+			out = append(out, c.Transaction.ID)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name     string
+		category string
+		vendor   string
+		want     []string
+	}{
+		{name: "by category", category: "Office Supplies", want: []string{"1", "2"}},
+		// Income classifications are skipped; raw names match when merchant is empty
+		{name: "by vendor", vendor: "Verizon", want: []string{"4", "6", "3"}},
+		{name: "income category never matches", category: "Refunds", want: []string{}},
+		{name: "unknown vendor", vendor: "Nobody", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterBusinessPercentTargets(classifications, categories, tt.category, tt.vendor)
+			assert.Equal(t, tt.want, ids(got))
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"text/tabwriter"
@@ -14,6 +15,7 @@ import (
 	"github.com/Veraticus/the-spice-must-flow/internal/cli"
 	"github.com/Veraticus/the-spice-must-flow/internal/engine"
 	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
 	"github.com/Veraticus/the-spice-must-flow/internal/storage"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
@@ -31,6 +33,13 @@ func categoriesCmd() *cobra.Command {
 	cmd.AddCommand(updateCategoryCmd())
 	cmd.AddCommand(deleteCategoryCmd())
 	cmd.AddCommand(mergeCategoriesCmd())
+	cmd.AddCommand(setCategoryReviewCmd())
+	cmd.AddCommand(setCategoryRuleThresholdCmd())
+	cmd.AddCommand(dedupeCategoriesCmd())
+	cmd.AddCommand(trendCategoriesCmd())
+	cmd.AddCommand(categoriesAliasCmd())
+	cmd.AddCommand(categoriesSnapshotsCmd())
+	cmd.AddCommand(seedCategoriesCmd())
 
 	return cmd
 }
@@ -99,19 +108,23 @@ func listCategoriesCmd() *cobra.Command {
 
 			// Header
 			headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("86"))
-			if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
 				headerStyle.Render("ID"),
 				headerStyle.Render("Name"),
 				headerStyle.Render("Type"),
 				headerStyle.Render("Business %"),
+				headerStyle.Render("Review"),
+				headerStyle.Render("Rule At"),
 				headerStyle.Render("Description")); err != nil {
 				slog.Error("failed to write table header", "error", err)
 			}
-			if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
 				strings.Repeat("-", 4),
 				strings.Repeat("-", 20),
 				strings.Repeat("-", 10),
 				strings.Repeat("-", 10),
+				strings.Repeat("-", 6),
+				strings.Repeat("-", 7),
 				strings.Repeat("-", 50)); err != nil {
 				slog.Error("failed to write table separator", "error", err)
 			}
@@ -143,7 +156,18 @@ func listCategoriesCmd() *cobra.Command {
 					businessPctStr = lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("N/A")
 				}
 
-				if _, err := fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", cat.ID, cat.Name, typeStr, businessPctStr, desc); err != nil {
+				reviewStr := "auto"
+				if cat.AlwaysReview {
+					reviewStr = lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render("always")
+				}
+
+				// Confidence needed to create a vendor rule, dimmed when it's the default
+				ruleStr := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render(fmt.Sprintf("%.0f%%", engine.DefaultVendorRuleThreshold*100))
+				if cat.VendorRuleThreshold > 0 {
+					ruleStr = fmt.Sprintf("%.0f%%", cat.VendorRuleThreshold*100)
+				}
+
+				if _, err := fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", cat.ID, cat.Name, typeStr, businessPctStr, reviewStr, ruleStr, desc); err != nil {
 					slog.Error("failed to write category row", "error", err, "category", cat.Name)
 				}
 			}
@@ -162,6 +186,7 @@ func addCategoryCmd() *cobra.Command {
 		categoryDescription string
 		skipDescription     bool
 		isIncome            bool
+		questionnaire       bool
 		confidenceThreshold float64
 	)
 
@@ -184,7 +209,10 @@ Examples:
   spice categories add "Travel" "Entertainment" --no-description
   
   # Prompt for descriptions when confidence is below 0.8
-  spice categories add "CrossnoKaye" "LiveWorld" --confidence-threshold 0.8`,
+  spice categories add "CrossnoKaye" "LiveWorld" --confidence-threshold 0.8
+  
+  # Answer a few questions to set each category's default business percentage
+  spice categories add "Phone" "Coworking" --business-questionnaire`,
 		Args: cobra.MinimumNArgs(1),
 		RunE: func(_ *cobra.Command, args []string) error {
 			ctx := context.Background()
@@ -285,6 +313,12 @@ Examples:
 					return fmt.Errorf("failed to create category %q: %w", categoryName, err)
 				}
 
+				if questionnaire && categoryType == model.CategoryTypeExpense {
+					if err := askCategoryBusinessUse(ctx, store, category); err != nil {
+						return err
+					}
+				}
+
 				createdCategories = append(createdCategories, *category)
 			}
 
@@ -297,6 +331,9 @@ Examples:
 						typeDisplay = "income"
 					}
 					fmt.Printf("  • %s (ID: %d, type: %s)", cat.Name, cat.ID, typeDisplay) //nolint:forbidigo // User-facing output
+					if cat.DefaultBusinessPercent > 0 {
+						fmt.Printf(" [%d%% business]", cat.DefaultBusinessPercent) //nolint:forbidigo // User-facing output
+					}
 					if cat.Description != "" && !skipDescription {
 						fmt.Printf(" - %s", cat.Description) //nolint:forbidigo // User-facing output
 					}
@@ -318,11 +355,30 @@ Examples:
 	cmd.Flags().StringVar(&categoryDescription, "description", "", "Category description (auto-generated if not provided)")
 	cmd.Flags().BoolVar(&skipDescription, "no-description", false, "Skip AI description generation")
 	cmd.Flags().BoolVar(&isIncome, "income", false, "Create income categories instead of expense categories")
+	cmd.Flags().BoolVar(&questionnaire, "business-questionnaire", false, "Ask how each expense category is used for business to set its default business percentage")
 	cmd.Flags().Float64Var(&confidenceThreshold, "confidence-threshold", 0.95, "Prompt for description when AI confidence is below this threshold (0.0-1.0)")
 
 	return cmd
 }
 
+// askCategoryBusinessUse runs the business use questionnaire for a new expense
+// category and saves the business percentage it suggests. Skipping keeps 0%.
+func askCategoryBusinessUse(ctx context.Context, store service.Storage, category *model.Category) error {
+	percent, ok, err := cli.NewCLIPrompter(nil, nil).AskBusinessUse(ctx, category.Name)
+	if err != nil {
+		return fmt.Errorf("business questionnaire for %q: %w", category.Name, err)
+	}
+	if !ok || percent == 0 {
+		return nil
+	}
+
+	if err := store.UpdateCategoryBusinessPercent(ctx, category.ID, percent); err != nil {
+		return fmt.Errorf("failed to update business percentage for %q: %w", category.Name, err)
+	}
+	category.DefaultBusinessPercent = percent
+	return nil
+}
+
 func updateCategoryCmd() *cobra.Command {
 	var (
 		categoryName        string
@@ -721,3 +777,150 @@ This will move all transactions from category ID 5 to category ID 7 and delete c
 
 	return cmd
 }
+
+// duplicateCategoryGroup is a set of categories whose names differ only by case or whitespace.
+type duplicateCategoryGroup struct {
+	keep       model.Category
+	duplicates []model.Category
+}
+
+// normalizeCategoryName folds case and collapses whitespace so near-identical names compare equal.
+func normalizeCategoryName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// findDuplicateCategories groups categories that normalize to the same name.
+// Each group keeps a category whose name is already tidy (no stray whitespace),
+// preferring the oldest by ID, and lists the rest as duplicates to merge into it.
+func findDuplicateCategories(categories []model.Category) []duplicateCategoryGroup {
+	byName := make(map[string][]model.Category)
+	var order []string
+	for _, cat := range categories {
+		key := normalizeCategoryName(cat.Name)
+		if _, seen := byName[key]; !seen {
+			order = append(order, key)
+		}
+		byName[key] = append(byName[key], cat)
+	}
+
+	var groups []duplicateCategoryGroup
+	for _, key := range order {
+		members := byName[key]
+		if len(members) < 2 {
+			continue
+		}
+
+		sort.Slice(members, func(i, j int) bool {
+			iTidy := members[i].Name == strings.Join(strings.Fields(members[i].Name), " ")
+			jTidy := members[j].Name == strings.Join(strings.Fields(members[j].Name), " ")
+			if iTidy != jTidy {
+				return iTidy
+			}
+			return members[i].ID < members[j].ID
+		})
+
+		groups = append(groups, duplicateCategoryGroup{keep: members[0], duplicates: members[1:]})
+	}
+
+	return groups
+}
+
+func dedupeCategoriesCmd() *cobra.Command {
+	var force bool
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "dedupe",
+		Short: "Find and merge categories that differ only by case or whitespace",
+		Long: `Find categories whose names differ only by case or whitespace, such as
+"Travel", "travel" and "Travel ", and merge each group into one category.
+
+Classifications, vendor rules, check patterns and pattern rules that point at a
+duplicate are moved to the kept category before the duplicate is deleted.
+
+Examples:
+  # Show duplicate groups without changing anything
+  spice categories dedupe --dry-run
+
+  # Merge every group without prompting
+  spice categories dedupe --force`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			store, err := initStorage(ctx)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if closeErr := store.Close(); closeErr != nil {
+					slog.Error("failed to close storage", "error", closeErr)
+				}
+			}()
+
+			categories, err := store.GetCategories(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get categories: %w", err)
+			}
+
+			groups := findDuplicateCategories(categories)
+			if len(groups) == 0 {
+				fmt.Println(cli.FormatSuccess("✓ No duplicate categories found")) //nolint:forbidigo // User-facing output
+				return nil
+			}
+
+			fmt.Println(cli.InfoStyle.Render(fmt.Sprintf("Found %d group(s) of duplicate categories:", len(groups)))) //nolint:forbidigo // User-facing output
+			fmt.Println()                                                                                             //nolint:forbidigo // User-facing output
+
+			merged := 0
+			for i, group := range groups {
+				fmt.Printf("%d. Keep %q (ID: %d)\n", i+1, group.keep.Name, group.keep.ID) //nolint:forbidigo // User-facing output
+				for _, dup := range group.duplicates {
+					fmt.Printf("   merge %q (ID: %d)\n", dup.Name, dup.ID) //nolint:forbidigo // User-facing output
+				}
+
+				if dryRun {
+					fmt.Println() //nolint:forbidigo // User-facing output
+					continue
+				}
+
+				if !force {
+					fmt.Printf("Merge into %q? (y/N): ", group.keep.Name) //nolint:forbidigo // User prompt
+					var response string
+					if _, err := fmt.Scanln(&response); err != nil {
+						// EOF or empty input is treated as "N"
+						response = "n"
+					}
+					if strings.ToLower(response) != "y" {
+						fmt.Println("   Skipped.") //nolint:forbidigo // User-facing output
+						fmt.Println()              //nolint:forbidigo // User-facing output
+						continue
+					}
+				}
+
+				for _, dup := range group.duplicates {
+					result, err := store.MergeCategories(ctx, dup.ID, group.keep.ID)
+					if err != nil {
+						return fmt.Errorf("failed to merge %q into %q: %w", dup.Name, group.keep.Name, err)
+					}
+					fmt.Printf("   ✓ Merged %q: %d transactions, %d vendors, %d check patterns, %d pattern rules\n", //nolint:forbidigo // User-facing output
+						dup.Name, result.Classifications, result.Vendors, result.CheckPatterns, result.PatternRules)
+					merged++
+				}
+				fmt.Println() //nolint:forbidigo // User-facing output
+			}
+
+			if dryRun {
+				fmt.Println(cli.InfoStyle.Render("Dry run: no categories were changed")) //nolint:forbidigo // User-facing output
+				return nil
+			}
+
+			fmt.Println(cli.SuccessStyle.Render(fmt.Sprintf("✓ Merged %d duplicate categories", merged))) //nolint:forbidigo // User-facing output
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Merge every group without prompting")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Report duplicate groups without merging")
+
+	return cmd
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/common"
+	"github.com/spf13/cobra"
+)
+
+func categoriesAliasCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "alias",
+		Short: "Manage other names the AI uses for categories",
+		Long: `Category aliases are other names the AI may use for a category, such as
+"Dining" for "Food & Dining". When the AI ranks a merchant under an alias,
+the ranking counts for the category instead of being dropped as unknown or
+suggested as a new category.`,
+	}
+
+	cmd.AddCommand(categoriesAliasAddCmd())
+	cmd.AddCommand(categoriesAliasListCmd())
+	cmd.AddCommand(categoriesAliasDeleteCmd())
+
+	return cmd
+}
+
+func categoriesAliasAddCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "add <alias> <category>",
+		Short: "Add an alias for a category",
+		Long: `Add an alias for an existing category, replacing the category it pointed to
+before. Aliases and category names are matched case-insensitively, and an alias
+can't be the name of another category.
+
+Examples:
+  spice categories alias add Dining "Food & Dining"
+  spice categories alias add "Software Subscriptions" Software`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			alias := strings.TrimSpace(args[0])
+			category := strings.TrimSpace(args[1])
+
+			db, cleanup, err := getDatabase()
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			if err := db.SaveCategoryAlias(ctx, alias, category); err != nil {
+				return fmt.Errorf("failed to save category alias: %w", err)
+			}
+
+			slog.Info(fmt.Sprintf("✓ %s is now an alias of %s", alias, category))
+			return nil
+		},
+	}
+}
+
+func categoriesAliasListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List category aliases",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			db, cleanup, err := getDatabase()
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			aliases, err := db.GetCategoryAliases(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get category aliases: %w", err)
+			}
+
+			if len(aliases) == 0 {
+				slog.Info("No category aliases found")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "ALIAS\tCATEGORY\tCREATED")
+			_, _ = fmt.Fprintln(w, "─────\t────────\t───────")
+			for _, alias := range aliases {
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", alias.Alias, alias.Category, alias.CreatedAt.Format("2006-01-02"))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func categoriesAliasDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <alias>",
+		Short: "Delete a category alias",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			db, cleanup, err := getDatabase()
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			if err := db.DeleteCategoryAlias(ctx, args[0]); err != nil {
+				if errors.Is(err, common.ErrNotFound) {
+					return fmt.Errorf("no category alias '%s'", args[0])
+				}
+				return fmt.Errorf("failed to delete category alias: %w", err)
+			}
+
+			slog.Info(fmt.Sprintf("✓ Alias %s deleted", args[0]))
+			return nil
+		},
+	}
+}
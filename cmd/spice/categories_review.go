@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func setCategoryReviewCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-review <name> <always|auto>",
+		Short: "Always review transactions suggested for a category",
+		Long: `Choose whether transactions suggested for a category can be saved without
+review.
+
+With "always", every transaction the AI, a vendor rule or a pattern rule
+suggests for the category goes to manual review, however confident the
+suggestion. Use it for categories with tax or compliance implications, like
+Business or Medical. With --no-review they are left unclassified until you
+review them. "auto" restores the normal auto-accept behavior.
+
+Examples:
+  spice categories set-review Medical always
+  spice categories set-review "Business Travel" auto`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			name := strings.TrimSpace(args[0])
+
+			var alwaysReview bool
+			switch strings.ToLower(args[1]) {
+			case "always":
+				alwaysReview = true
+			case "auto":
+				alwaysReview = false
+			default:
+				return fmt.Errorf("invalid review mode %q: must be always or auto", args[1])
+			}
+
+			db, cleanup, err := getDatabase()
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			category, err := db.GetCategoryByName(ctx, name)
+			if errors.Is(err, storage.ErrCategoryNotFound) {
+				return fmt.Errorf("no category '%s'", name)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to get category: %w", err)
+			}
+
+			if err := db.SetCategoryAlwaysReview(ctx, category.ID, alwaysReview); err != nil {
+				return fmt.Errorf("failed to update category review: %w", err)
+			}
+
+			if alwaysReview {
+				slog.Info(fmt.Sprintf("✓ Transactions suggested for %s will always be reviewed", category.Name))
+			} else {
+				slog.Info(fmt.Sprintf("✓ Transactions suggested for %s can be auto-accepted again", category.Name))
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/engine"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+func setCategoryRuleThresholdCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-rule-threshold <name> <threshold|default>",
+		Short: "Set the confidence needed to create vendor rules for a category",
+		Long: fmt.Sprintf(`Set how confident an auto-accepted AI suggestion for a category must be
+before a vendor rule is created for its merchant. Once a merchant has a vendor
+rule, its later transactions are filed under the category without asking the AI.
+
+Lower the threshold for categories where a wrong rule is cheap, like Groceries,
+and raise it for ones where mistakes are costly, like Business. The threshold
+is between 0 and 1; "default" restores the default of %.2f.
+
+Examples:
+  spice categories set-rule-threshold Groceries 0.75
+  spice categories set-rule-threshold Business 0.98
+  spice categories set-rule-threshold Business default`, engine.DefaultVendorRuleThreshold),
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			name := strings.TrimSpace(args[0])
+
+			var threshold float64
+			if !strings.EqualFold(args[1], "default") {
+				var err error
+				threshold, err = strconv.ParseFloat(args[1], 64)
+				if err != nil || threshold <= 0 || threshold > 1 {
+					return fmt.Errorf("invalid threshold %q: must be above 0 and at most 1, or default", args[1])
+				}
+			}
+
+			db, cleanup, err := getDatabase()
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			category, err := db.GetCategoryByName(ctx, name)
+			if errors.Is(err, storage.ErrCategoryNotFound) {
+				return fmt.Errorf("no category '%s'", name)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to get category: %w", err)
+			}
+
+			if err := db.SetCategoryVendorRuleThreshold(ctx, category.ID, threshold); err != nil {
+				return fmt.Errorf("failed to update vendor rule threshold: %w", err)
+			}
+
+			if threshold == 0 {
+				threshold = engine.DefaultVendorRuleThreshold
+			}
+			slog.Info(fmt.Sprintf("✓ Vendor rules for %s are created at %.0f%% confidence or more", category.Name, threshold*100))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/engine"
+	"github.com/spf13/cobra"
+)
+
+func seedCategoriesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "seed",
+		Short: "Add the starter categories",
+		Long: `Add a starter set of common expense and income categories, like Groceries,
+Dining, Utilities and Salary, plus a Transfers system category.
+
+Categories that already exist, ignoring case, are left alone, so it is safe to
+run on a database that has some categories. Rename, merge or delete the
+starter categories afterwards to fit your finances. 'spice classify' offers to
+do this when the database has no categories.
+
+Examples:
+  spice categories seed`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			db, cleanup, err := getDatabase()
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			created, err := engine.SeedStarterCategories(ctx, db)
+			if err != nil {
+				return err
+			}
+			if len(created) == 0 {
+				slog.Info("All starter categories already exist")
+				return nil
+			}
+
+			slog.Info(fmt.Sprintf("✓ Added %d starter categories", len(created)))
+			for _, cat := range created {
+				fmt.Printf("  • %s (%s)\n", cat.Name, cat.Type) //nolint:forbidigo // User-facing output
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/common"
+	"github.com/spf13/cobra"
+)
+
+func categoriesSnapshotsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "snapshots [id]",
+		Short: "List category sets saved with reports",
+		Long: `List the category snapshots saved by 'spice flow --snapshot', or show the
+categories of one snapshot. Pass a snapshot's ID to 'spice flow --from-snapshot'
+to build its report again with those categories.
+
+Examples:
+  spice categories snapshots
+  spice categories snapshots 3`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			db, cleanup, err := getDatabase()
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+
+			if len(args) == 1 {
+				id, err := strconv.ParseInt(args[0], 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid snapshot ID '%s'", args[0])
+				}
+				snapshot, err := db.GetCategorySnapshot(ctx, id)
+				if err != nil {
+					if errors.Is(err, common.ErrNotFound) {
+						return fmt.Errorf("no category snapshot %d", id)
+					}
+					return fmt.Errorf("failed to get category snapshot: %w", err)
+				}
+
+				_, _ = fmt.Fprintln(w, "NAME\tTYPE\tBUSINESS %")
+				_, _ = fmt.Fprintln(w, "────\t────\t──────────")
+				for _, category := range snapshot.Categories {
+					_, _ = fmt.Fprintf(w, "%s\t%s\t%d\n", category.Name, category.Type, category.DefaultBusinessPercent)
+				}
+				return w.Flush()
+			}
+
+			snapshots, err := db.GetCategorySnapshots(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get category snapshots: %w", err)
+			}
+
+			if len(snapshots) == 0 {
+				slog.Info("No category snapshots found; save one with 'spice flow --snapshot'")
+				return nil
+			}
+
+			_, _ = fmt.Fprintln(w, "ID\tREPORT\tPERIOD\tCATEGORIES\tTAKEN")
+			_, _ = fmt.Fprintln(w, "──\t──────\t──────\t──────────\t─────")
+			for _, snapshot := range snapshots {
+				_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\n",
+					snapshot.ID,
+					snapshot.Report,
+					formatSnapshotPeriod(snapshot.PeriodStart.Local(), snapshot.PeriodEnd.Local()),
+					len(snapshot.Categories),
+					snapshot.CreatedAt.Local().Format("2006-01-02 15:04"))
+			}
+			return w.Flush()
+		},
+	}
+}
@@ -1,10 +1,15 @@
 package main
 
 import (
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/Veraticus/the-spice-must-flow/internal/engine"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAddCategoryCmd(t *testing.T) {
@@ -36,3 +41,55 @@ func TestCategoriesCmd(t *testing.T) {
 
 	assert.NotNil(t, addCmd, "add subcommand should exist")
 }
+
+func TestFindDuplicateCategories(t *testing.T) {
+	categories := []model.Category{
+		{ID: 1, Name: "travel"},
+		{ID: 2, Name: "Groceries"},
+		{ID: 3, Name: "Travel "},
+		{ID: 4, Name: "Travel"},
+		{ID: 5, Name: "Home  Office"},
+		{ID: 6, Name: "home office"},
+		{ID: 7, Name: "Travel Insurance"},
+	}
+
+	groups := findDuplicateCategories(categories)
+	require.Len(t, groups, 2)
+
+	// The oldest tidy name is kept; names with stray whitespace are merged away
+	assert.Equal(t, 1, groups[0].keep.ID)
+	assert.ElementsMatch(t, []int{3, 4}, []int{groups[0].duplicates[0].ID, groups[0].duplicates[1].ID})
+	assert.Equal(t, 3, groups[0].duplicates[1].ID, "untidy names sort last")
+
+	assert.Equal(t, 6, groups[1].keep.ID, "a tidy name wins over an older untidy one")
+	require.Len(t, groups[1].duplicates, 1)
+	assert.Equal(t, 5, groups[1].duplicates[0].ID)
+
+	assert.Empty(t, findDuplicateCategories([]model.Category{{ID: 1, Name: "Travel"}, {ID: 2, Name: "Travel Insurance"}}))
+}
+
+func TestFormatTrendContent(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("empty", func(t *testing.T) {
+		content := formatTrendContent(&engine.SpendingTrend{Start: start, Months: 3})
+		assert.Contains(t, content, "No expense spending")
+		assert.Contains(t, content, "Jan 2024 – Mar 2024")
+	})
+
+	t.Run("sparklines", func(t *testing.T) {
+		content := formatTrendContent(&engine.SpendingTrend{
+			Start:  start,
+			Months: 3,
+			Categories: []engine.CategoryTrend{
+				{Category: "Groceries", Monthly: []float64{100, 0, 50}, Total: 150},
+			},
+		})
+		lines := strings.Split(content, "\n")
+		last := lines[len(lines)-1]
+		assert.Contains(t, last, "Groceries")
+		assert.Contains(t, last, "█▁▄")
+		assert.Contains(t, last, "$      50.00")
+		assert.Contains(t, last, "$     150.00")
+	})
+}
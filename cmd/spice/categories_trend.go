@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/cli"
+	"github.com/Veraticus/the-spice-must-flow/internal/engine"
+	"github.com/spf13/cobra"
+)
+
+func trendCategoriesCmd() *cobra.Command {
+	var months int
+	var month string
+
+	cmd := &cobra.Command{
+		Use:   "trend",
+		Short: "Show monthly spending per category as sparklines",
+		Long: `Show each category's monthly spending over recent months as a sparkline.
+
+Months without spending count as zero and refunds offset their category's
+spending, as on the Monthly Flow sheet. Income, transfers and non-expense
+categories are left out. Nothing is changed in the database.
+
+Examples:
+  # The last 6 months, including this one
+  spice categories trend
+
+  # A year of history ending in June 2024
+  spice categories trend --months 12 --month 2024-06`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			if months <= 0 {
+				return fmt.Errorf("--months must be a positive number")
+			}
+
+			end := time.Now()
+			if month != "" {
+				parsed, err := time.ParseInLocation("2006-01", month, time.Local)
+				if err != nil {
+					return fmt.Errorf("invalid month format '%s', expected YYYY-MM: %w", month, err)
+				}
+				end = parsed
+			}
+
+			store, err := initReadOnlyStorage(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+			defer func() {
+				if closeErr := store.Close(); closeErr != nil {
+					slog.Error("failed to close storage", "error", closeErr)
+				}
+			}()
+
+			trend, err := engine.Trend(ctx, store, end, months)
+			if err != nil {
+				return fmt.Errorf("failed to build trend: %w", err)
+			}
+
+			fmt.Println(cli.RenderBox("Category Spending Trend", formatTrendContent(trend))) //nolint:forbidigo // User-facing output
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&months, "months", engine.DefaultTrendMonths, "Number of months to show")
+	cmd.Flags().StringVarP(&month, "month", "m", "", "Last month to show (format: 2024-01, default: this month)")
+
+	return cmd
+}
+
+func formatTrendContent(trend *engine.SpendingTrend) string {
+	last := trend.Start.AddDate(0, trend.Months-1, 0)
+	period := fmt.Sprintf("%s – %s", trend.Start.Format("Jan 2006"), last.Format("Jan 2006"))
+
+	if len(trend.Categories) == 0 {
+		return fmt.Sprintf("No expense spending between %s.\nRun 'spice classify' to categorize transactions first.", period)
+	}
+
+	width := max(trend.Months, len("Trend"))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", period)
+	fmt.Fprintf(&b, "  %-24s %-*s %12s %12s", "Category", width, "Trend", "Latest", "Total")
+	for _, cat := range trend.Categories {
+		fmt.Fprintf(&b, "\n  %-24s %-*s $%11.2f $%11.2f",
+			truncateString(cat.Category, 24),
+			width, cli.Sparkline(cat.Monthly),
+			cat.Monthly[len(cat.Monthly)-1],
+			cat.Total)
+	}
+
+	return b.String()
+}
@@ -25,6 +25,7 @@ rent, cleaning services, or regular bills.`,
 	cmd.AddCommand(checksEditCmd())
 	cmd.AddCommand(checksDeleteCmd())
 	cmd.AddCommand(checksTestCmd())
+	cmd.AddCommand(checksCalibrationCmd())
 
 	return cmd
 }
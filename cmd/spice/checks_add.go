@@ -23,16 +23,27 @@ You'll be prompted to enter:
   - Pattern name and category
   - Amount matching criteria (exact, range, or multiple)
   - Optional day-of-month restrictions
-  - Notes for future reference`,
+  - Payee/memo text the check must contain
+  - Notes for future reference
+
+Use --confidence below the classify auto-accept threshold for broad patterns
+so their suggestions go to review instead of being auto-accepted.`,
 		RunE: runChecksAdd,
 	}
 
+	cmd.Flags().Float64("confidence", model.DefaultCheckPatternConfidence, "Confidence of this pattern's suggestions (0-1)")
+
 	return cmd
 }
 
 func runChecksAdd(cmd *cobra.Command, _ []string) error {
 	ctx := cmd.Context()
 
+	confidence, _ := cmd.Flags().GetFloat64("confidence")
+	if confidence <= 0 || confidence > 1 {
+		return fmt.Errorf("confidence must be greater than 0 and at most 1")
+	}
+
 	// Initialize storage
 	storage, err := initStorage(ctx)
 	if err != nil {
@@ -173,6 +184,13 @@ func runChecksAdd(cmd *cobra.Command, _ []string) error {
 		pattern.DayOfMonthMax = &maxDay
 	}
 
+	// Optional payee/memo text (checks often carry the payee in the memo)
+	memo, err := promptString(reader, "\nPayee/memo text to match (optional)")
+	if err != nil {
+		return fmt.Errorf("failed to get memo text: %w", err)
+	}
+	pattern.MemoPattern = memo
+
 	// Optional notes
 	notes, err := promptString(reader, "\nNotes (optional)")
 	if err != nil {
@@ -182,6 +200,8 @@ func runChecksAdd(cmd *cobra.Command, _ []string) error {
 		pattern.Notes = notes
 	}
 
+	pattern.Confidence = confidence
+
 	// Create the pattern
 	if err := storage.CreateCheckPattern(ctx, &pattern); err != nil {
 		return fmt.Errorf("failed to create pattern: %w", err)
@@ -197,6 +217,12 @@ func runChecksAdd(cmd *cobra.Command, _ []string) error {
 	if pattern.DayOfMonthMin != nil && pattern.DayOfMonthMax != nil {
 		fmt.Printf("  Only on days %d-%d of the month\n", *pattern.DayOfMonthMin, *pattern.DayOfMonthMax) //nolint:forbidigo // User-facing output
 	}
+	if pattern.MemoPattern != "" {
+		fmt.Printf("  Only when the payee/memo contains %q\n", pattern.MemoPattern) //nolint:forbidigo // User-facing output
+	}
+	if pattern.Confidence < model.DefaultCheckPatternConfidence {
+		fmt.Printf("  Suggests at %.0f%% confidence\n", pattern.Confidence*100) //nolint:forbidigo // User-facing output
+	}
 
 	return nil
 }
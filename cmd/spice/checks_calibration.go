@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"text/tabwriter"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/engine"
+	"github.com/spf13/cobra"
+)
+
+func checksCalibrationCmd() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "calibration",
+		Short: "Show how well the AI's confidence predicted its accuracy",
+		Long: `Bucket the AI classifications in the classification history by confidence
+decile and show how many of each bucket kept the AI's category. When the AI is
+well calibrated, a bucket's accuracy is close to its mean confidence; use the
+buckets to pick an auto-accept threshold, and 'spice classify simulate' to see
+what it would do.
+
+A classification counts as wrong when its transaction was later given another
+category. Merchants you give another category in review are only counted when
+classified with --record-calibration, which keeps the AI's suggestion in the
+history. Nothing is changed.
+
+Examples:
+  spice checks calibration
+  spice checks calibration --output json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			if output != "table" && output != "json" {
+				return fmt.Errorf("invalid output format %q (use table or json)", output)
+			}
+
+			store, err := initReadOnlyStorage(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+			defer func() {
+				if closeErr := store.Close(); closeErr != nil {
+					slog.Error("failed to close storage", "error", closeErr)
+				}
+			}()
+
+			outcomes, err := store.GetConfidenceOutcomes(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get classification history: %w", err)
+			}
+
+			buckets := engine.CalibrateConfidence(outcomes)
+			if output == "json" {
+				return writeCalibrationJSON(cmd.OutOrStdout(), buckets)
+			}
+			return writeCalibrationTable(cmd.OutOrStdout(), buckets, len(outcomes))
+		},
+	}
+
+	cmd.Flags().StringVar(&output, "output", "table", "Output format (table, json)")
+
+	return cmd
+}
+
+// writeCalibrationTable prints the buckets holding predictions, highest
+// confidence first.
+func writeCalibrationTable(w io.Writer, buckets []engine.CalibrationBucket, total int) error {
+	if total == 0 {
+		_, _ = fmt.Fprintln(w, "No AI classifications in the history to calibrate")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "CONFIDENCE\tPREDICTIONS\tMEAN CONFIDENCE\tACCURACY\tGAP")
+	_, _ = fmt.Fprintln(tw, "──────────\t───────────\t───────────────\t────────\t───")
+	for i := len(buckets) - 1; i >= 0; i-- {
+		bucket := buckets[i]
+		if bucket.Predictions == 0 {
+			continue
+		}
+		gap := (bucket.Accuracy() - bucket.MeanConfidence) * 100
+		_, _ = fmt.Fprintf(tw, "%.0f-%.0f%%\t%d\t%.1f%%\t%.1f%%\t%+.1f\n",
+			bucket.Low*100, bucket.High*100, bucket.Predictions,
+			bucket.MeanConfidence*100, bucket.Accuracy()*100, gap)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(w, "\nBased on %d AI classifications; a negative gap means the AI was overconfident\n", total)
+	return nil
+}
+
+func writeCalibrationJSON(w io.Writer, buckets []engine.CalibrationBucket) error {
+	type bucketJSON struct {
+		Low            float64 `json:"low"`
+		High           float64 `json:"high"`
+		Predictions    int     `json:"predictions"`
+		Correct        int     `json:"correct"`
+		MeanConfidence float64 `json:"mean_confidence"`
+		Accuracy       float64 `json:"accuracy"`
+	}
+	out := make([]bucketJSON, 0, len(buckets))
+	for _, bucket := range buckets {
+		out = append(out, bucketJSON{
+			Low:            bucket.Low,
+			High:           bucket.High,
+			Predictions:    bucket.Predictions,
+			Correct:        bucket.Correct,
+			MeanConfidence: bucket.MeanConfidence,
+			Accuracy:       bucket.Accuracy(),
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(out); err != nil {
+		return fmt.Errorf("failed to encode calibration: %w", err)
+	}
+	return nil
+}
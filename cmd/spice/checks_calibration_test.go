@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/engine"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCalibrationTable(t *testing.T) {
+	outcomes := []model.ConfidenceOutcome{
+		{TransactionID: "1", Confidence: 0.95, AICategory: "Food", FinalCategory: "Food"},
+		{TransactionID: "2", Confidence: 0.93, AICategory: "Food", FinalCategory: "Shopping"},
+		{TransactionID: "3", Confidence: 0.55, AICategory: "Food", FinalCategory: "Food"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeCalibrationTable(&buf, engine.CalibrateConfidence(outcomes), len(outcomes)))
+
+	out := buf.String()
+	assert.Regexp(t, `90-100%\s+2\s+94\.0%\s+50\.0%\s+-44\.0`, out)
+	assert.Regexp(t, `50-60%\s+1\s+55\.0%\s+100\.0%\s+\+45\.0`, out)
+	assert.NotContains(t, out, "70-80%", "empty buckets are left out")
+	assert.Less(t, bytes.Index(buf.Bytes(), []byte("90-100%")), bytes.Index(buf.Bytes(), []byte("50-60%")))
+	assert.Contains(t, out, "Based on 3 AI classifications")
+}
+
+func TestWriteCalibrationTable_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeCalibrationTable(&buf, engine.CalibrateConfidence(nil), 0))
+	assert.Equal(t, "No AI classifications in the history to calibrate\n", buf.String())
+}
+
+func TestWriteCalibrationJSON(t *testing.T) {
+	outcomes := []model.ConfidenceOutcome{
+		{TransactionID: "1", Confidence: 0.85, AICategory: "Food", FinalCategory: "Food"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeCalibrationJSON(&buf, engine.CalibrateConfidence(outcomes)))
+
+	var got []map[string]float64
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	require.Len(t, got, 10)
+	assert.Equal(t, 1.0, got[8]["predictions"])
+	assert.Equal(t, 1.0, got[8]["accuracy"])
+	assert.Equal(t, 0.0, got[0]["predictions"])
+}
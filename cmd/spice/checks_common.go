@@ -8,7 +8,7 @@ import (
 
 // formatPatternAmounts formats the amount range for display.
 func formatPatternAmounts(pattern model.CheckPattern) string {
-	// Check for multiple specific amounts first
+	// Multiple specific amounts, plus the range when one is set too
 	if len(pattern.Amounts) > 0 {
 		result := ""
 		for i, amount := range pattern.Amounts {
@@ -17,6 +17,9 @@ func formatPatternAmounts(pattern model.CheckPattern) string {
 			}
 			result += fmt.Sprintf("$%.2f", amount)
 		}
+		if pattern.AmountMin != nil {
+			result += " or " + formatPatternRange(pattern)
+		}
 		return result
 	}
 
@@ -24,6 +27,11 @@ func formatPatternAmounts(pattern model.CheckPattern) string {
 	if pattern.AmountMin == nil {
 		return "N/A"
 	}
+	return formatPatternRange(pattern)
+}
+
+// formatPatternRange formats a pattern's range, or its exact amount.
+func formatPatternRange(pattern model.CheckPattern) string {
 	if pattern.AmountMax == nil || *pattern.AmountMax == *pattern.AmountMin {
 		// Exact amount
 		return fmt.Sprintf("$%.2f", *pattern.AmountMin)
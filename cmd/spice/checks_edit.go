@@ -17,11 +17,14 @@ func checksEditCmd() *cobra.Command {
 		Use:   "edit <pattern-id>",
 		Short: "Edit an existing check pattern",
 		Long: `Edit an existing check pattern. Shows current values as defaults
-and allows you to change each field.`,
+and allows you to change each field. Use --confidence to change how
+strongly the pattern's suggestions are trusted.`,
 		Args: cobra.ExactArgs(1),
 		RunE: runChecksEdit,
 	}
 
+	cmd.Flags().Float64("confidence", 0, "New confidence of this pattern's suggestions (0-1)")
+
 	return cmd
 }
 
@@ -51,6 +54,12 @@ func runChecksEdit(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get pattern: %w", err)
 	}
 
+	confidenceChanged := cmd.Flags().Changed("confidence")
+	newConfidence, _ := cmd.Flags().GetFloat64("confidence")
+	if confidenceChanged && (newConfidence <= 0 || newConfidence > 1) {
+		return fmt.Errorf("confidence must be greater than 0 and at most 1")
+	}
+
 	// Get categories for validation
 	categories, err := storage.GetCategories(ctx)
 	if err != nil {
@@ -68,6 +77,10 @@ func runChecksEdit(cmd *cobra.Command, args []string) error {
 	if pattern.DayOfMonthMin != nil && pattern.DayOfMonthMax != nil {
 		fmt.Printf("  Day restriction: %d-%d\n", *pattern.DayOfMonthMin, *pattern.DayOfMonthMax) //nolint:forbidigo // User-facing output
 	}
+	if pattern.MemoPattern != "" {
+		fmt.Printf("  Payee/memo contains: %s\n", pattern.MemoPattern) //nolint:forbidigo // User-facing output
+	}
+	fmt.Printf("  Confidence: %.0f%%\n", pattern.EffectiveConfidence()*100) //nolint:forbidigo // User-facing output
 	if pattern.Notes != "" {
 		fmt.Printf("  Notes: %s\n", pattern.Notes) //nolint:forbidigo // User-facing output
 	}
@@ -77,6 +90,9 @@ func runChecksEdit(cmd *cobra.Command, args []string) error {
 
 	// Store original values for comparison
 	original := *pattern
+	if confidenceChanged {
+		pattern.Confidence = newConfidence
+	}
 
 	// Edit pattern name
 	patternName, err := promptStringWithDefault(reader, "Pattern name", pattern.PatternName)
@@ -139,6 +155,7 @@ func runChecksEdit(cmd *cobra.Command, args []string) error {
 		}
 		pattern.AmountMin = &amount
 		pattern.AmountMax = nil
+		pattern.Amounts = nil
 
 	case "3": // Range
 		minAmount, errMin := promptAmount(reader, "Minimum amount")
@@ -154,6 +171,7 @@ func runChecksEdit(cmd *cobra.Command, args []string) error {
 		}
 		pattern.AmountMin = &minAmount
 		pattern.AmountMax = &maxAmount
+		pattern.Amounts = nil
 	}
 
 	// Edit day restriction
@@ -197,6 +215,16 @@ func runChecksEdit(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Edit payee/memo text
+	memo, err := promptStringWithDefault(reader, "\nPayee/memo text to match (- to clear)", pattern.MemoPattern)
+	if err != nil {
+		return fmt.Errorf("failed to get memo text: %w", err)
+	}
+	if memo == "-" {
+		memo = ""
+	}
+	pattern.MemoPattern = memo
+
 	// Edit notes
 	currentNotes := pattern.Notes
 	notes, err := promptStringWithDefault(reader, "\nNotes", currentNotes)
@@ -226,6 +254,14 @@ func runChecksEdit(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  Amount: %s → %s\n", formatPatternAmounts(original), formatPatternAmounts(*pattern)) //nolint:forbidigo // User-facing output
 		hasChanges = true
 	}
+	if pattern.MemoPattern != original.MemoPattern {
+		fmt.Printf("  Payee/memo: %q → %q\n", original.MemoPattern, pattern.MemoPattern) //nolint:forbidigo // User-facing output
+		hasChanges = true
+	}
+	if pattern.Confidence != original.Confidence {
+		fmt.Printf("  Confidence: %.0f%% → %.0f%%\n", original.EffectiveConfidence()*100, pattern.EffectiveConfidence()*100) //nolint:forbidigo // User-facing output
+		hasChanges = true
+	}
 
 	if !hasChanges {
 		fmt.Println(cli.InfoStyle.Render("No changes made.")) //nolint:forbidigo // User-facing output
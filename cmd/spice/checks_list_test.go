@@ -45,6 +45,15 @@ func TestFormatPatternAmounts(t *testing.T) {
 			},
 			expected: "$100.00-$200.00",
 		},
+		{
+			name: "amounts and range",
+			pattern: model.CheckPattern{
+				Amounts:   []float64{50, 75},
+				AmountMin: ptr(100.00),
+				AmountMax: ptr(200.00),
+			},
+			expected: "$50.00, $75.00 or $100.00-$200.00",
+		},
 	}
 
 	for _, tt := range tests {
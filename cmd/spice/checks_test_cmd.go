@@ -24,13 +24,15 @@ encountering real transactions.
 Examples:
   spice checks test 100
   spice checks test 100 --date=2024-01-05
-  spice checks test 3000 --check-number=1234`,
+  spice checks test 3000 --check-number=1234
+  spice checks test 120 --memo="Jane's Cleaning"`,
 		Args: cobra.ExactArgs(1),
 		RunE: runChecksTest,
 	}
 
 	cmd.Flags().String("date", "", "Test with specific date (YYYY-MM-DD)")
 	cmd.Flags().String("check-number", "", "Test with specific check number")
+	cmd.Flags().String("memo", "", "Test with payee/memo text on the check")
 
 	return cmd
 }
@@ -58,6 +60,7 @@ func runChecksTest(cmd *cobra.Command, args []string) error {
 
 	// Parse optional check number
 	checkNumberStr, _ := cmd.Flags().GetString("check-number")
+	memo, _ := cmd.Flags().GetString("memo")
 
 	// Initialize storage
 	storage, err := initStorage(ctx)
@@ -75,7 +78,7 @@ func runChecksTest(cmd *cobra.Command, args []string) error {
 		Amount:      amount,
 		Date:        testDate,
 		Type:        "CHECK",
-		Name:        fmt.Sprintf("Check #%s", checkNumberStr),
+		Name:        strings.TrimSpace(fmt.Sprintf("Check #%s %s", checkNumberStr, memo)),
 		CheckNumber: checkNumberStr,
 	}
 
@@ -94,6 +97,9 @@ func runChecksTest(cmd *cobra.Command, args []string) error {
 	if checkNumberStr != "" {
 		fmt.Printf("  Check number: %s\n", checkNumberStr) //nolint:forbidigo // User-facing output
 	}
+	if memo != "" {
+		fmt.Printf("  Memo: %s\n", memo) //nolint:forbidigo // User-facing output
+	}
 	fmt.Println() //nolint:forbidigo // User-facing output
 
 	if len(patterns) == 0 {
@@ -107,8 +113,9 @@ func runChecksTest(cmd *cobra.Command, args []string) error {
 		// Show pattern details
 		fmt.Printf("✓ Pattern: %s (ID: %d)\n", //nolint:forbidigo // User-facing output
 			cli.SuccessStyle.Render(pattern.PatternName), pattern.ID)
-		fmt.Printf("  Category: %s\n", pattern.Category)      //nolint:forbidigo // User-facing output
-		fmt.Printf("  Previous uses: %d\n", pattern.UseCount) //nolint:forbidigo // User-facing output
+		fmt.Printf("  Category: %s\n", pattern.Category)                        //nolint:forbidigo // User-facing output
+		fmt.Printf("  Confidence: %.0f%%\n", pattern.EffectiveConfidence()*100) //nolint:forbidigo // User-facing output
+		fmt.Printf("  Previous uses: %d\n", pattern.UseCount)                   //nolint:forbidigo // User-facing output
 
 		// Show why it matched
 		fmt.Printf("  Matched because:\n") //nolint:forbidigo // User-facing output
@@ -127,9 +134,20 @@ func runChecksTest(cmd *cobra.Command, args []string) error {
 				testDate.Day(), *pattern.DayOfMonthMin, *pattern.DayOfMonthMax)
 		}
 
+		// Memo match
+		if pattern.MemoPattern != "" {
+			fmt.Printf("    - Payee/memo contains %q\n", pattern.MemoPattern) //nolint:forbidigo // User-facing output
+		}
+
 		// Check number match
-		if pattern.CheckNumberPattern != nil {
-			fmt.Printf("    - Check number pattern matched\n") //nolint:forbidigo // User-facing output
+		if pattern.CheckNumberPattern != nil && checkNumberStr != "" {
+			if pattern.CheckNumberPattern.Regex != "" {
+				fmt.Printf("    - Check number %s matches /%s/\n", checkNumberStr, pattern.CheckNumberPattern.Regex) //nolint:forbidigo // User-facing output
+			}
+			if pattern.CheckNumberPattern.Modulo > 0 {
+				fmt.Printf("    - Check number %s %% %d is %d\n", //nolint:forbidigo // User-facing output
+					checkNumberStr, pattern.CheckNumberPattern.Modulo, pattern.CheckNumberPattern.Offset)
+			}
 		}
 
 		if pattern.Notes != "" {
@@ -2,8 +2,10 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -11,10 +13,11 @@ import (
 	"time"
 
 	"github.com/Veraticus/the-spice-must-flow/internal/cli"
+	"github.com/Veraticus/the-spice-must-flow/internal/config"
 	"github.com/Veraticus/the-spice-must-flow/internal/engine"
 	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/notify"
 	"github.com/Veraticus/the-spice-must-flow/internal/service"
-	"github.com/Veraticus/the-spice-must-flow/internal/storage"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -29,7 +32,8 @@ This command fetches transactions from Plaid, groups them by merchant,
 and guides you through categorization with minimal effort using batch LLM calls.
 
 By default, this will classify ALL unclassified transactions. Use --year or --month
-to limit the scope to a specific time period.
+to limit the scope to a specific time period, or --since and --until for a date
+range; either end of the range can be left open.
 
 Examples:
   # Classify all unclassified transactions
@@ -41,37 +45,177 @@ Examples:
   # Force manual review for all items (opposite of --auto-only)
   spice classify --manual-review-all
   
+  # Review merchants you've never classified before, even when confident
+  spice classify --review-new-merchants
+  
+  # Review 25 merchants at a time, with the option to stop and resume later
+  spice classify --review-chunk 25
+  
+  # Show the AI three typical transactions per merchant instead of the first one
+  spice classify --sample-strategy representative --samples 3
+  
+  # Only auto-accept a merchant when every one of its transactions is confident
+  spice classify --group-confidence min
+  
+  # Match refunds to purchases up to 60 days earlier (0 sends refunds to the AI)
+  spice classify --refund-window 60
+  
+  # Split merchants with more than 200 transactions into amount bands
+  spice classify --max-group-size 200
+  
+  # Write merchants needing review to a CSV to classify in a spreadsheet,
+  # then apply it with 'spice review import review.csv'
+  spice classify --review-export review.csv
+  
+  # Log the source, confidence and outcome of every merchant as JSON lines,
+  # written as the run goes so a crash still leaves a partial log
+  spice classify --decisions-out run.jsonl
+  
+  # Only auto-accept rule matches the AI agrees with; send the rest to review
+  spice classify --confirm-rules
+  
+  # Review vendor rules not updated in over a year instead of auto-accepting them
+  spice classify --vendor-rule-decay step --vendor-rule-max-age 365
+  
+  # File merchants the AI is under 30% sure about under "Escalate" instead of
+  # reviewing them now; pick them up later with 'spice recategorize --from-category Escalate'
+  spice classify --escalate-below 0.3
+  
+  # File merchants that fail to classify under a fallback category for their
+  # direction instead of leaving them unclassified
+  spice classify --fallback-expense-category "Uncategorized Expense" --fallback-income-category "Uncategorized Income"
+  
+  # Never auto-accept "Other" above 50% confidence; review those merchants instead
+  spice classify --catch-all-max-confidence 0.5
+  
+  # Don't show AI guesses under 40% confidence during review; pick from the list
+  spice classify --min-suggestion-confidence 0.4
+  
+  # Learning mode: show why the AI picked each suggestion and what else it
+  # considered, with a reason per category
+  spice classify --explain
+  
+  # Nudge the AI's scores toward the category the bank gave a transaction
+  spice classify --bank-category-weight 0.05
+  
+  # Skip a merchant at once when the new category picked for it can't be
+  # created, instead of reviewing it again
+  spice classify --category-retries 0
+  
+  # Save review decisions in one commit per 50 transactions instead of after
+  # every merchant (a crash loses at most the uncommitted ones)
+  spice classify --commit-every 50
+  
+  # Save auto-accepted transactions one at a time instead of 500 per commit
+  spice classify --save-batch-size 1
+  
+  # Auto-accept without the sanity pass that sends suspicious results to review
+  spice classify --validate=false
+  
+  # Fail fast on the first merchant error (useful in CI)
+  spice classify --auto-only --stop-on-error
+  
   # Custom auto-accept threshold (default: 95%)
   spice classify --auto-accept-threshold=0.90
   
   # Maximum performance with more parallel workers
   spice classify --auto-only --parallel-workers=10
   
+  # More workers for local work, but at most 3 AI calls at once
+  spice classify --parallel-workers=10 --max-inflight-llm=3
+  
+  # Estimate AI calls, tokens and cost per provider without classifying anything
+  spice classify --dry-run
+  spice classify --year 2024 --dry-run --output json
+  
+  # On a new database without categories, add the starter categories without
+  # asking, or have the AI propose categories for approval first
+  spice classify --empty-categories seed
+  spice classify --empty-categories discover --discover-merchants 100
+  
+  # When the run finishes, write the report for the months it classified, to
+  # Google Sheets or, with the flow output settings, as CSV files or Excel
+  spice classify --report
+  spice classify --report --report-format xlsx
+  
   # Classify only 2024 transactions
   spice classify --year 2024
   
   # Classify specific month
   spice classify --month 2024-03
   
+  # Classify one quarter at a time
+  spice classify --since 2024-01-01 --until 2024-03-31
+  
   # Re-classify low confidence transactions
   spice classify --rerank 0.85
   
   # Re-classify with custom auto-accept threshold
-  spice classify --rerank 0.80 --auto-accept-threshold=0.90`,
+  spice classify --rerank 0.80 --auto-accept-threshold=0.90
+  
+  # Re-rank half the merchants with each prompt and compare improvement rates
+  spice classify --rerank 0.85 --rerank-prompt compare
+  
+  # Classifications you made or corrected by hand are never changed; let a
+  # re-rank change them too
+  spice classify --rerank 0.85 --force-overwrite`,
 		RunE: runClassify,
 	}
 
+	cmd.AddCommand(classifyCompareCmd())
+	cmd.AddCommand(classifyDiffCmd())
+	cmd.AddCommand(classifySimulateCmd())
+
 	// Flags
 	cmd.Flags().IntP("year", "y", 0, "Year to classify transactions for (default: all transactions)")
 	cmd.Flags().StringP("month", "m", "", "Specific month to classify (format: 2024-01)")
-	cmd.Flags().Bool("dry-run", false, "Preview without saving changes")
+	cmd.Flags().String("since", "", "Classify transactions dated on or after this day (YYYY-MM-DD)")
+	cmd.Flags().String("until", "", "Classify transactions dated on or before this day (YYYY-MM-DD)")
+	cmd.Flags().Bool("dry-run", false, "Estimate AI calls, tokens and cost without classifying or calling the AI")
+	cmd.Flags().String("output", "table", "Output format of the --dry-run estimate (table, json)")
 
 	// Batch configuration flags
 	cmd.Flags().Float64("auto-accept-threshold", 0.95, "Auto-accept classifications above this confidence (0.0-1.0)")
 	cmd.Flags().Int("batch-size", 5, "Number of merchants to process in each LLM batch")
 	cmd.Flags().Int("parallel-workers", 5, "Number of parallel workers for batch processing")
+	cmd.Flags().Int("max-inflight-llm", 0, "Maximum concurrent LLM batch calls (0 uses --parallel-workers)")
 	cmd.Flags().Bool("auto-only", false, "Only auto-accept high confidence items, skip manual review")
 	cmd.Flags().Bool("manual-review-all", false, "Force manual review for all items, even high confidence ones")
+	cmd.Flags().Bool("review-new-merchants", false, "Always review merchants with no classification history, regardless of confidence")
+	cmd.Flags().Int("review-chunk", 0, "Review this many merchants at a time, pausing between chunks (0 reviews all at once)")
+	cmd.Flags().Float64("min-suggestion-confidence", 0, "Hide AI suggestions below this confidence during review and show the category list neutrally (0 always shows them)")
+	cmd.Flags().Bool("explain", false, "Learning mode: show the AI's reason for each suggestion and the other categories it ranked during review")
+	cmd.Flags().Float64("bank-category-weight", 0, "Add this to the AI's score for the category the bank's own category maps to, as a weak prior (0 disables)")
+	cmd.Flags().Bool("business-questionnaire", false, "Ask a few questions about business use when creating an expense category during review, instead of for a percentage")
+	cmd.Flags().String("review-export", "", "Write merchants needing review to this CSV file instead of reviewing them interactively")
+	cmd.Flags().Bool("report", false, "Write the report for the months this run classified when it finishes")
+	cmd.Flags().String("report-format", reportFormatSheets, "Format of the --report report (sheets|csv|xlsx)")
+	cmd.Flags().String("decisions-out", "", "Write the source, confidence and outcome of every merchant to this JSON lines file as the run proceeds")
+	cmd.Flags().Bool("record-calibration", false, "Keep the AI's suggestion in the classification history when review changes it, for 'spice checks calibration'")
+	cmd.Flags().String("sample-strategy", "first", "How to pick the transactions the AI sees per merchant (first|representative)")
+	cmd.Flags().Int("samples", 1, "Number of transactions the AI sees per merchant")
+	cmd.Flags().String("group-confidence", "top", "Confidence that auto-accepts a merchant: the AI's score for the group, or the min/mean of each transaction's score (top|min|mean)")
+	cmd.Flags().Bool("validate", true, "Send auto-accept candidates to review when their direction doesn't suit the category or their amount dwarfs the category's usual amounts")
+	cmd.Flags().Float64("validate-amount-multiple", engine.DefaultValidationAmountMultiple, "Multiple of a category's average amount that fails validation")
+	cmd.Flags().Bool("confirm-rules", false, "Ask the AI about merchants matched by a rule and only auto-accept them when it agrees")
+	cmd.Flags().String("vendor-rule-decay", string(engine.DecayNone), "How the confidence of vendor rules older than --vendor-rule-max-age falls (none|step|linear|exponential)")
+	cmd.Flags().Int("vendor-rule-max-age", int(engine.DefaultRuleDecayAfter.Hours()/24), "Days since a vendor rule was last updated before it starts to decay")
+	cmd.Flags().Int("vendor-rule-decay-days", int(engine.DefaultRuleDecaySpan.Hours()/24), "Days for a stale rule to reach the floor (linear) or halve its distance to it (exponential)")
+	cmd.Flags().Float64("vendor-rule-decay-floor", engine.DefaultRuleDecayFloor, "Lowest confidence a stale vendor rule decays to (0.0-1.0)")
+	cmd.Flags().Float64("escalate-below", 0, "File merchants whose best suggestion is below this confidence under the escalate category instead of reviewing them (0 disables)")
+	cmd.Flags().String("escalate-category", engine.DefaultEscalateCategory, "Category for escalated merchants; created as a system category if missing")
+	cmd.Flags().String("fallback-expense-category", "", "Category for expenses of merchants that fail to classify; created if missing (empty leaves them unclassified)")
+	cmd.Flags().String("fallback-income-category", "", "Category for income of merchants that fail to classify; created if missing (empty leaves them unclassified)")
+	cmd.Flags().String("catch-all-category", engine.DefaultCatchAllCategory, "Generic catch-all category counted in the summary and capped by --catch-all-max-confidence")
+	cmd.Flags().Float64("catch-all-max-confidence", 0, "Cap AI suggestions of the catch-all category at this confidence and always review them (0 disables)")
+	cmd.Flags().Int("category-retries", engine.DefaultCategoryRetries, "Times a merchant is reviewed again when the new category picked for it can't be created (0 skips it)")
+	cmd.Flags().Int("commit-every", 0, "Reviewed transactions saved per commit (0 commits after every merchant)")
+	cmd.Flags().Int("save-batch-size", engine.DefaultSaveBatchSize, "Auto-accepted transactions saved per commit (1 saves them one by one)")
+	cmd.Flags().Int("max-group-size", 0, "Split merchants with more transactions than this into amount bands classified separately (0 disables)")
+	cmd.Flags().Bool("stop-on-error", false, "Stop the run and exit with an error on the first merchant that fails to classify")
+	cmd.Flags().String("empty-categories", emptyCategoriesAsk, "What to do when there are no categories yet: ask, seed the starter categories, discover them with the AI for approval, or continue (ask|seed|discover|continue)")
+	cmd.Flags().Int("discover-merchants", engine.DefaultDiscoveryMerchants, "Busiest merchants the AI proposes categories for with --empty-categories discover")
+	cmd.Flags().Int("refund-window", 30, "Days before a refund to look for the purchase it reverses; matched refunds inherit its category (0 disables)")
 
 	// Reset flags
 	cmd.Flags().Bool("reset", false, "Clear all existing classifications before classifying")
@@ -79,19 +223,62 @@ Examples:
 
 	// Rerank flags
 	cmd.Flags().Float64("rerank", 0, "Re-classify transactions with confidence below this threshold (0.0-1.0)")
+	cmd.Flags().String("rerank-prompt", string(engine.RerankPromptReconsider), "Prompt for --rerank (reconsider|standard|compare)")
+	cmd.Flags().Bool("force-overwrite", false, "Let the AI and rules change classifications you made or corrected by hand")
 
 	// Bind to viper (errors are rare and can be ignored in practice)
 	_ = viper.BindPFlag("classification.year", cmd.Flags().Lookup("year"))
 	_ = viper.BindPFlag("classification.month", cmd.Flags().Lookup("month"))
+	_ = viper.BindPFlag("classification.since", cmd.Flags().Lookup("since"))
+	_ = viper.BindPFlag("classification.until", cmd.Flags().Lookup("until"))
 	_ = viper.BindPFlag("classification.dry_run", cmd.Flags().Lookup("dry-run"))
+	_ = viper.BindPFlag("classification.dry_run_output", cmd.Flags().Lookup("output"))
 	_ = viper.BindPFlag("classification.auto_accept_threshold", cmd.Flags().Lookup("auto-accept-threshold"))
 	_ = viper.BindPFlag("classification.batch_size", cmd.Flags().Lookup("batch-size"))
 	_ = viper.BindPFlag("classification.parallel_workers", cmd.Flags().Lookup("parallel-workers"))
+	_ = viper.BindPFlag("classification.max_inflight_llm", cmd.Flags().Lookup("max-inflight-llm"))
 	_ = viper.BindPFlag("classification.auto_only", cmd.Flags().Lookup("auto-only"))
 	_ = viper.BindPFlag("classification.manual_review_all", cmd.Flags().Lookup("manual-review-all"))
+	_ = viper.BindPFlag("classification.review_new_merchants", cmd.Flags().Lookup("review-new-merchants"))
+	_ = viper.BindPFlag("classification.review_chunk", cmd.Flags().Lookup("review-chunk"))
+	_ = viper.BindPFlag("classification.business_questionnaire", cmd.Flags().Lookup("business-questionnaire"))
+	_ = viper.BindPFlag("classification.min_suggestion_confidence", cmd.Flags().Lookup("min-suggestion-confidence"))
+	_ = viper.BindPFlag("classification.explain", cmd.Flags().Lookup("explain"))
+	_ = viper.BindPFlag("classification.bank_category_weight", cmd.Flags().Lookup("bank-category-weight"))
+	_ = viper.BindPFlag("classification.review_export", cmd.Flags().Lookup("review-export"))
+	_ = viper.BindPFlag("classification.decisions_out", cmd.Flags().Lookup("decisions-out"))
+	_ = viper.BindPFlag("classification.record_calibration", cmd.Flags().Lookup("record-calibration"))
+	_ = viper.BindPFlag("classification.report", cmd.Flags().Lookup("report"))
+	_ = viper.BindPFlag("classification.report_format", cmd.Flags().Lookup("report-format"))
+	_ = viper.BindPFlag("classification.sample_strategy", cmd.Flags().Lookup("sample-strategy"))
+	_ = viper.BindPFlag("classification.sample_count", cmd.Flags().Lookup("samples"))
+	_ = viper.BindPFlag("classification.empty_categories", cmd.Flags().Lookup("empty-categories"))
+	_ = viper.BindPFlag("classification.discover_merchants", cmd.Flags().Lookup("discover-merchants"))
+	_ = viper.BindPFlag("classification.refund_window_days", cmd.Flags().Lookup("refund-window"))
+	_ = viper.BindPFlag("classification.stop_on_error", cmd.Flags().Lookup("stop-on-error"))
+	_ = viper.BindPFlag("classification.max_group_size", cmd.Flags().Lookup("max-group-size"))
+	_ = viper.BindPFlag("classification.group_confidence", cmd.Flags().Lookup("group-confidence"))
+	_ = viper.BindPFlag("classification.validate", cmd.Flags().Lookup("validate"))
+	_ = viper.BindPFlag("classification.confirm_rules", cmd.Flags().Lookup("confirm-rules"))
+	_ = viper.BindPFlag("classification.validate_amount_multiple", cmd.Flags().Lookup("validate-amount-multiple"))
+	_ = viper.BindPFlag("classification.vendor_rule_decay", cmd.Flags().Lookup("vendor-rule-decay"))
+	_ = viper.BindPFlag("classification.vendor_rule_max_age_days", cmd.Flags().Lookup("vendor-rule-max-age"))
+	_ = viper.BindPFlag("classification.vendor_rule_decay_days", cmd.Flags().Lookup("vendor-rule-decay-days"))
+	_ = viper.BindPFlag("classification.vendor_rule_decay_floor", cmd.Flags().Lookup("vendor-rule-decay-floor"))
+	_ = viper.BindPFlag("classification.escalate_below", cmd.Flags().Lookup("escalate-below"))
+	_ = viper.BindPFlag("classification.escalate_category", cmd.Flags().Lookup("escalate-category"))
+	_ = viper.BindPFlag("classification.fallback_expense_category", cmd.Flags().Lookup("fallback-expense-category"))
+	_ = viper.BindPFlag("classification.fallback_income_category", cmd.Flags().Lookup("fallback-income-category"))
+	_ = viper.BindPFlag("classification.catch_all_category", cmd.Flags().Lookup("catch-all-category"))
+	_ = viper.BindPFlag("classification.catch_all_max_confidence", cmd.Flags().Lookup("catch-all-max-confidence"))
+	_ = viper.BindPFlag("classification.category_retries", cmd.Flags().Lookup("category-retries"))
+	_ = viper.BindPFlag("classification.review_commit_every", cmd.Flags().Lookup("commit-every"))
+	_ = viper.BindPFlag("classification.save_batch_size", cmd.Flags().Lookup("save-batch-size"))
 	_ = viper.BindPFlag("classification.reset", cmd.Flags().Lookup("reset"))
 	_ = viper.BindPFlag("classification.reset_vendors", cmd.Flags().Lookup("reset-vendors"))
 	_ = viper.BindPFlag("classification.rerank", cmd.Flags().Lookup("rerank"))
+	_ = viper.BindPFlag("classification.rerank_prompt", cmd.Flags().Lookup("rerank-prompt"))
+	_ = viper.BindPFlag("classification.force_overwrite", cmd.Flags().Lookup("force-overwrite"))
 
 	return cmd
 }
@@ -100,20 +287,166 @@ func runClassify(cmd *cobra.Command, _ []string) error {
 	ctx := cmd.Context()
 	year := viper.GetInt("classification.year")
 	month := viper.GetString("classification.month")
+	since := viper.GetString("classification.since")
+	until := viper.GetString("classification.until")
 	dryRun := viper.GetBool("classification.dry_run")
+	dryRunOutput := viper.GetString("classification.dry_run_output")
 	autoAcceptThreshold := viper.GetFloat64("classification.auto_accept_threshold")
 	batchSize := viper.GetInt("classification.batch_size")
 	parallelWorkers := viper.GetInt("classification.parallel_workers")
+	maxInflightLLM := viper.GetInt("classification.max_inflight_llm")
 	autoOnly := viper.GetBool("classification.auto_only")
 	manualReviewAll := viper.GetBool("classification.manual_review_all")
+	reviewNewMerchants := viper.GetBool("classification.review_new_merchants")
+	reviewChunk := viper.GetInt("classification.review_chunk")
+	reviewExport := viper.GetString("classification.review_export")
+	decisionsOut := viper.GetString("classification.decisions_out")
+	recordCalibration := viper.GetBool("classification.record_calibration")
+	report := viper.GetBool("classification.report")
+	sampleCount := viper.GetInt("classification.sample_count")
+	refundWindowDays := viper.GetInt("classification.refund_window_days")
+	stopOnError := viper.GetBool("classification.stop_on_error")
+	maxGroupSize := viper.GetInt("classification.max_group_size")
 	reset := viper.GetBool("classification.reset")
 	resetVendors := viper.GetString("classification.reset_vendors")
 	rerankThreshold := viper.GetFloat64("classification.rerank")
+	forceOverwrite := viper.GetBool("classification.force_overwrite")
+	validate := viper.GetBool("classification.validate")
+	validateAmountMultiple := viper.GetFloat64("classification.validate_amount_multiple")
+	confirmRules := viper.GetBool("classification.confirm_rules")
+	escalateBelow := viper.GetFloat64("classification.escalate_below")
+	vendorRuleMaxAge := viper.GetInt("classification.vendor_rule_max_age_days")
+	vendorRuleDecayDays := viper.GetInt("classification.vendor_rule_decay_days")
+	vendorRuleDecayFloor := viper.GetFloat64("classification.vendor_rule_decay_floor")
+	escalateCategory := viper.GetString("classification.escalate_category")
+	fallbackExpenseCategory := strings.TrimSpace(viper.GetString("classification.fallback_expense_category"))
+	fallbackIncomeCategory := strings.TrimSpace(viper.GetString("classification.fallback_income_category"))
+	catchAllCategory := viper.GetString("classification.catch_all_category")
+	catchAllMaxConfidence := viper.GetFloat64("classification.catch_all_max_confidence")
+	categoryRetries := viper.GetInt("classification.category_retries")
+	reviewCommitEvery := viper.GetInt("classification.review_commit_every")
+	saveBatchSize := viper.GetInt("classification.save_batch_size")
+	minSuggestionConfidence := viper.GetFloat64("classification.min_suggestion_confidence")
+	explain := viper.GetBool("classification.explain")
+	bankCategoryWeight := viper.GetFloat64("classification.bank_category_weight")
+	discoverMerchants := viper.GetInt("classification.discover_merchants")
 
 	// Validate flag combinations
 	if autoOnly && manualReviewAll {
 		return fmt.Errorf("cannot use both --auto-only and --manual-review-all flags")
 	}
+	if autoOnly && reviewNewMerchants {
+		return fmt.Errorf("cannot use both --auto-only and --review-new-merchants flags")
+	}
+	if maxInflightLLM < 0 {
+		return fmt.Errorf("--max-inflight-llm must not be negative")
+	}
+	if reviewChunk < 0 {
+		return fmt.Errorf("--review-chunk must not be negative")
+	}
+	if reviewExport != "" && (autoOnly || dryRun) {
+		return fmt.Errorf("--review-export cannot be used with --auto-only or --dry-run")
+	}
+	if report && (dryRun || rerankThreshold > 0) {
+		return fmt.Errorf("--report cannot be used with --dry-run or --rerank")
+	}
+	if decisionsOut != "" && (dryRun || rerankThreshold > 0) {
+		return fmt.Errorf("--decisions-out cannot be used with --dry-run or --rerank")
+	}
+	if dryRunOutput != "table" && dryRunOutput != "json" {
+		return fmt.Errorf("invalid output format %q (use table or json)", dryRunOutput)
+	}
+	if dryRun && (reset || rerankThreshold > 0) {
+		return fmt.Errorf("--dry-run cannot be used with --reset or --rerank")
+	}
+	if (since != "" || until != "") && (year != 0 || month != "") {
+		return fmt.Errorf("--since and --until cannot be used with --year or --month")
+	}
+	if (since != "" || until != "") && rerankThreshold > 0 {
+		return fmt.Errorf("--since and --until cannot be used with --rerank")
+	}
+	sinceDate, untilDate, err := parseClassifyDateRange(since, until)
+	if err != nil {
+		return err
+	}
+	sampleStrategy, err := engine.ParseSampleStrategy(viper.GetString("classification.sample_strategy"))
+	if err != nil {
+		return err
+	}
+	groupConfidence, err := engine.ParseConfidenceAggregation(viper.GetString("classification.group_confidence"))
+	if err != nil {
+		return err
+	}
+	rerankPrompt, err := engine.ParseRerankPrompt(viper.GetString("classification.rerank_prompt"))
+	if err != nil {
+		return err
+	}
+	reportFormat, err := parseReportFormat(viper.GetString("classification.report_format"))
+	if err != nil {
+		return err
+	}
+	emptyCategories, err := parseEmptyCategories(viper.GetString("classification.empty_categories"))
+	if err != nil {
+		return err
+	}
+	if discoverMerchants < 1 {
+		return fmt.Errorf("--discover-merchants must be at least 1")
+	}
+	if sampleCount < 1 {
+		return fmt.Errorf("--samples must be at least 1")
+	}
+	if refundWindowDays < 0 {
+		return fmt.Errorf("--refund-window must not be negative")
+	}
+	if maxGroupSize < 0 {
+		return fmt.Errorf("--max-group-size must not be negative")
+	}
+	vendorRuleDecay, err := engine.ParseDecayCurve(viper.GetString("classification.vendor_rule_decay"))
+	if err != nil {
+		return err
+	}
+	if vendorRuleMaxAge < 0 {
+		return fmt.Errorf("--vendor-rule-max-age must not be negative")
+	}
+	if vendorRuleDecayDays < 1 && (vendorRuleDecay == engine.DecayLinear || vendorRuleDecay == engine.DecayExponential) {
+		return fmt.Errorf("--vendor-rule-decay-days must be at least 1")
+	}
+	if vendorRuleDecayFloor < 0 || vendorRuleDecayFloor > 1 {
+		return fmt.Errorf("--vendor-rule-decay-floor must be between 0.0 and 1.0")
+	}
+	if escalateBelow < 0 || escalateBelow >= autoAcceptThreshold {
+		return fmt.Errorf("--escalate-below must be between 0 and --auto-accept-threshold")
+	}
+	if escalateBelow > 0 && strings.TrimSpace(escalateCategory) == "" {
+		return fmt.Errorf("--escalate-category must not be empty")
+	}
+	if fallbackExpenseCategory != "" && strings.EqualFold(fallbackExpenseCategory, fallbackIncomeCategory) {
+		return fmt.Errorf("--fallback-expense-category and --fallback-income-category must differ")
+	}
+	if bankCategoryWeight < 0 || bankCategoryWeight > 1 {
+		return fmt.Errorf("--bank-category-weight must be between 0.0 and 1.0")
+	}
+	if catchAllMaxConfidence < 0 || catchAllMaxConfidence > 1 {
+		return fmt.Errorf("--catch-all-max-confidence must be between 0.0 and 1.0")
+	}
+	if catchAllMaxConfidence > 0 && strings.TrimSpace(catchAllCategory) == "" {
+		return fmt.Errorf("--catch-all-category must not be empty")
+	}
+	if minSuggestionConfidence < 0 || minSuggestionConfidence > 1 {
+		return fmt.Errorf("--min-suggestion-confidence must be between 0.0 and 1.0")
+	}
+	if categoryRetries < 0 {
+		return fmt.Errorf("--category-retries must not be negative")
+	}
+	if reviewCommitEvery < 0 {
+		return fmt.Errorf("--commit-every must not be negative")
+	}
+	if saveBatchSize < 1 {
+		return fmt.Errorf("--save-batch-size must be at least 1")
+	}
+	if validate && validateAmountMultiple <= 1 {
+		return fmt.Errorf("--validate-amount-multiple must be greater than 1")
+	}
 
 	// If manual-review-all is set, effectively set auto-accept threshold to 2.0 (impossible)
 	if manualReviewAll {
@@ -133,7 +466,7 @@ func runClassify(cmd *cobra.Command, _ []string) error {
 	}
 	dbPath = expandPath(dbPath)
 
-	db, err := storage.NewSQLiteStorage(dbPath)
+	db, err := openStorage(dbPath)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
@@ -142,6 +475,7 @@ func runClassify(cmd *cobra.Command, _ []string) error {
 			slog.Error("Failed to close database", "error", closeErr)
 		}
 	}()
+	db.SetOverwriteUserModified(forceOverwrite)
 
 	// Run migrations
 	if migrateErr := db.Migrate(ctx); migrateErr != nil {
@@ -160,14 +494,33 @@ func runClassify(cmd *cobra.Command, _ []string) error {
 	// Initialize components
 	var classifier engine.Classifier
 	var prompter engine.Prompter
+	var reviewExporter *cli.ReviewExporter
 
 	if dryRun {
-		slog.Info("Running in dry-run mode - using mock components")
+		// The estimate never calls the classifier or prompts
 		classifier = engine.NewMockClassifier()
-		prompter = engine.NewMockPrompter(true) // Auto-accept in dry-run
+		prompter = engine.NewMockPrompter(true)
 	} else {
 		// Use real prompter for interactive classification
-		prompter = cli.NewCLIPrompter(nil, nil)
+		statsFilter, filterErr := cli.ParseStatsFilter(
+			viper.GetStringSlice("classification.stats_exclude.directions"),
+			viper.GetStringSlice("classification.stats_exclude.categories"),
+			viper.GetBool("classification.stats_exclude.refunds"),
+		)
+		if filterErr != nil {
+			return filterErr
+		}
+		if reviewExport != "" {
+			reviewExporter = cli.NewReviewExporter()
+			prompter = reviewExporter
+		} else {
+			cliPrompter := cli.NewCLIPrompter(nil, nil)
+			cliPrompter.SetStatsFilter(statsFilter)
+			cliPrompter.SetBusinessQuestionnaire(viper.GetBool("classification.business_questionnaire"))
+			cliPrompter.SetMinSuggestionConfidence(minSuggestionConfidence)
+			cliPrompter.SetExplain(explain)
+			prompter = cliPrompter
+		}
 
 		// Initialize real LLM classifier
 		var llmErr error
@@ -175,14 +528,17 @@ func runClassify(cmd *cobra.Command, _ []string) error {
 		if llmErr != nil {
 			return fmt.Errorf("failed to create LLM client: %w", llmErr)
 		}
-		classifier = llmClient
+		classifier, err = withEmbeddings(llmClient, db)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Create classification engine
 	classificationEngine := engine.New(db, classifier, prompter)
 
 	// Determine date range
-	var fromDate *time.Time
+	fromDate := sinceDate
 	if month != "" {
 		// Parse month
 		parsedMonth, parseErr := time.Parse("2006-01", month)
@@ -196,7 +552,7 @@ func runClassify(cmd *cobra.Command, _ []string) error {
 		startDate := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
 		fromDate = &startDate
 	}
-	// If year is 0 and no month specified, fromDate remains nil (classify everything)
+	// With no year, month or --since, fromDate remains nil (classify everything)
 
 	// Check if we're doing rerank instead of normal classification
 	if rerankThreshold > 0 {
@@ -206,14 +562,18 @@ func runClassify(cmd *cobra.Command, _ []string) error {
 
 		slog.Info("Starting re-classification of low confidence transactions",
 			"confidence_threshold", fmt.Sprintf("%.0f%%", rerankThreshold*100),
-			"auto_accept_threshold", fmt.Sprintf("%.0f%%", autoAcceptThreshold*100))
+			"auto_accept_threshold", fmt.Sprintf("%.0f%%", autoAcceptThreshold*100),
+			"prompt", rerankPrompt)
 
 		opts := engine.RerankOptions{
 			ConfidenceThreshold: rerankThreshold,
 			AutoAcceptThreshold: autoAcceptThreshold,
 			BatchSize:           batchSize,
 			ParallelWorkers:     parallelWorkers,
+			MaxInflightLLM:      maxInflightLLM,
 			SkipManualReview:    autoOnly,
+			Prompt:              rerankPrompt,
+			IncludeUserModified: forceOverwrite,
 		}
 
 		summary, rerankErr := classificationEngine.RerankLowConfidenceTransactions(ctx, opts)
@@ -226,16 +586,92 @@ func runClassify(cmd *cobra.Command, _ []string) error {
 
 		// Show rerank summary
 		slog.Info(summary.GetDisplay())
+		logTieredStats(classifier)
 
 		return nil
 	}
 
 	// Normal classification flow
 	opts := engine.BatchClassificationOptions{
-		AutoAcceptThreshold: autoAcceptThreshold,
-		BatchSize:           batchSize,
-		ParallelWorkers:     parallelWorkers,
-		SkipManualReview:    autoOnly,
+		AutoAcceptThreshold:      autoAcceptThreshold,
+		BatchSize:                batchSize,
+		ParallelWorkers:          parallelWorkers,
+		MaxInflightLLM:           maxInflightLLM,
+		SkipManualReview:         autoOnly,
+		ReviewNewMerchants:       reviewNewMerchants,
+		ReviewChunkSize:          reviewChunk,
+		CategoryRetries:          categoryRetries,
+		ReviewCommitEvery:        reviewCommitEvery,
+		SaveBatchSize:            saveBatchSize,
+		SampleStrategy:           sampleStrategy,
+		SampleCount:              sampleCount,
+		RefundWindowDays:         refundWindowDays,
+		StopOnError:              stopOnError,
+		MaxGroupSize:             maxGroupSize,
+		UntilDate:                untilDate,
+		RecordCalibration:        recordCalibration,
+		GroupConfidence:          groupConfidence,
+		Validate:                 validate,
+		ValidationAmountMultiple: validateAmountMultiple,
+		ConfirmRules:             confirmRules,
+		VendorRuleDecay: engine.RuleDecay{
+			Curve: vendorRuleDecay,
+			After: time.Duration(vendorRuleMaxAge) * 24 * time.Hour,
+			Span:  time.Duration(vendorRuleDecayDays) * 24 * time.Hour,
+			Floor: vendorRuleDecayFloor,
+		},
+		EscalateBelow:           escalateBelow,
+		EscalateCategory:        strings.TrimSpace(escalateCategory),
+		FallbackExpenseCategory: fallbackExpenseCategory,
+		FallbackIncomeCategory:  fallbackIncomeCategory,
+		CatchAllCategory:        strings.TrimSpace(catchAllCategory),
+		CatchAllMaxConfidence:   catchAllMaxConfidence,
+		Explain:                 explain,
+		BankCategoryWeight:      bankCategoryWeight,
+		BankCategoryMapping:     viper.GetStringMapString("classification.bank_category_mapping"),
+	}
+
+	if dryRun {
+		return runClassifyEstimate(ctx, cmd.OutOrStdout(), classificationEngine, fromDate, opts, dryRunOutput)
+	}
+
+	if err := handleEmptyCategories(ctx, emptyCategoriesRun{
+		in:                bufio.NewReader(os.Stdin),
+		out:               cmd.OutOrStdout(),
+		db:                db,
+		engine:            classificationEngine,
+		fromDate:          fromDate,
+		mode:              emptyCategories,
+		opts:              opts,
+		discoverMerchants: discoverMerchants,
+	}); err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		return fmt.Errorf("failed to set up categories: %w", err)
+	}
+
+	if decisionsOut != "" {
+		decisions, closeDecisions, decisionsErr := openDecisionLog(decisionsOut)
+		if decisionsErr != nil {
+			return decisionsErr
+		}
+		defer closeDecisions()
+		opts.Decisions = decisions
+	}
+
+	// The report covers the months of the transactions this run classifies
+	var reportStart, reportEnd time.Time
+	if report {
+		pending, pendingErr := classificationEngine.GetTransactionsToClassifyBetween(ctx, fromDate, untilDate)
+		if pendingErr != nil {
+			return fmt.Errorf("failed to get transactions to classify: %w", pendingErr)
+		}
+		var ok bool
+		if reportStart, reportEnd, ok = reportPeriod(pending); !ok {
+			slog.Info("Nothing to classify, so no report to write")
+			report = false
+		}
 	}
 
 	slog.Info("Starting batch classification",
@@ -253,10 +689,111 @@ func runClassify(cmd *cobra.Command, _ []string) error {
 
 	// Show batch summary as JSON
 	slog.Info(summary.GetDisplay())
+	logTieredStats(classifier)
+
+	if reviewExporter != nil {
+		if err := writeReviewExport(reviewExport, reviewExporter); err != nil {
+			return err
+		}
+	}
+
+	sendClassifySummaryEmail(ctx, summary)
+
+	// A failed report doesn't fail the run; the classifications are saved
+	if report {
+		if err := writeClassifyReport(ctx, db, reportFormat, reportStart, reportEnd); err != nil {
+			slog.Warn("Failed to write the report; run 'spice flow' to try again", "error", err)
+		}
+	}
+
+	return nil
+}
+
+// openDecisionLog creates the decision log file at path, replacing it if it
+// exists. Decisions go straight to the file, unbuffered, so a crash still leaves
+// every line written so far. The returned function closes the file and logs
+// any write error.
+func openDecisionLog(path string) (*engine.DecisionLog, func(), error) {
+	path = expandPath(path)
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create decision log: %w", err)
+	}
+	decisions := engine.NewDecisionLog(file)
+	return decisions, func() {
+		if err := decisions.Err(); err != nil {
+			slog.Error("Decision log is incomplete", "path", path, "error", err)
+		}
+		if err := file.Close(); err != nil {
+			slog.Error("Failed to close decision log", "path", path, "error", err)
+		}
+	}, nil
+}
+
+// writeReviewExport writes the merchants collected for review to a CSV file.
+func writeReviewExport(path string, exporter *cli.ReviewExporter) error {
+	rows := exporter.Rows()
+	if len(rows) == 0 {
+		slog.Info("No merchants need review, nothing exported")
+		return nil
+	}
 
+	file, err := os.Create(expandPath(path))
+	if err != nil {
+		return fmt.Errorf("failed to create review export: %w", err)
+	}
+	if err := cli.WriteReviewCSV(file, rows); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write review export: %w", err)
+	}
+
+	slog.Info(fmt.Sprintf("✓ Exported %d merchants (%d transactions) needing review to %s",
+		len(rows), exporter.GetCompletionStats().TotalTransactions, path))
+	slog.Info(fmt.Sprintf("Fill in the my_category column, then run 'spice review import %s'", path))
 	return nil
 }
 
+// sendClassifySummaryEmail emails the run summary when notify.smtp is configured.
+// Failures are logged but never fail the classify run.
+func sendClassifySummaryEmail(ctx context.Context, summary *engine.BatchClassificationSummary) {
+	smtpConfig, enabled, err := config.LoadSMTPConfig()
+	if !enabled {
+		return
+	}
+	if err != nil {
+		slog.Warn("Skipping summary email: invalid SMTP configuration", "error", err)
+		return
+	}
+
+	notifier, err := notify.NewSMTPNotifier(smtpConfig)
+	if err != nil {
+		slog.Warn("Skipping summary email", "error", err)
+		return
+	}
+
+	if err := notifier.SendClassificationSummary(ctx, summary); err != nil {
+		slog.Warn("Failed to send summary email", "error", err)
+		return
+	}
+
+	slog.Info("Sent classification summary email", "recipients", len(smtpConfig.To))
+}
+
+// logTieredStats reports the embedding hit rate and per-tier counts and
+// estimated cost when those classifiers are enabled.
+func logTieredStats(classifier engine.Classifier) {
+	if embedding, ok := classifier.(*engine.EmbeddingClassifier); ok {
+		slog.Info(embedding.Stats().GetDisplay())
+		classifier = embedding.Next()
+	}
+	if tiered, ok := classifier.(*engine.TieredClassifier); ok {
+		slog.Info(tiered.Stats().GetDisplay())
+	}
+}
+
 // showCompletionStats displays completion statistics
 // nolint:unused // Kept for future use
 func showCompletionStats(stats service.CompletionStats) {
@@ -398,3 +935,28 @@ func handleReset(ctx context.Context, db service.Storage, resetVendors string) e
 
 	return nil
 }
+
+// parseClassifyDateRange parses --since and --until, either of which may be
+// empty to leave that end of the range open. The until date covers the whole
+// of its day.
+func parseClassifyDateRange(since, until string) (sinceDate, untilDate *time.Time, err error) {
+	if since != "" {
+		start, parseErr := time.Parse("2006-01-02", since)
+		if parseErr != nil {
+			return nil, nil, fmt.Errorf("invalid --since date %q, expected YYYY-MM-DD: %w", since, parseErr)
+		}
+		sinceDate = &start
+	}
+	if until != "" {
+		day, parseErr := time.Parse("2006-01-02", until)
+		if parseErr != nil {
+			return nil, nil, fmt.Errorf("invalid --until date %q, expected YYYY-MM-DD: %w", until, parseErr)
+		}
+		end := day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		untilDate = &end
+	}
+	if sinceDate != nil && untilDate != nil && sinceDate.After(*untilDate) {
+		return nil, nil, fmt.Errorf("--since %s is after --until %s", since, until)
+	}
+	return sinceDate, untilDate, nil
+}
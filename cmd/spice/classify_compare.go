@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/cli"
+	"github.com/Veraticus/the-spice-must-flow/internal/engine"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func classifyCompareCmd() *cobra.Command {
+	var (
+		providerA string
+		providerB string
+		modelA    string
+		modelB    string
+		sample    int
+		batchSize int
+		seed      int64
+	)
+
+	cmd := &cobra.Command{
+		Use:   "compare",
+		Short: "Compare two LLM providers on a sample of transactions",
+		Long: `Classify a sample of transactions with two providers and report how often they agree.
+
+This is read-only: no classifications are saved. Use it to evaluate switching providers
+or models before changing your configuration.
+
+Examples:
+  # Compare OpenAI and Anthropic on 200 transactions
+  spice classify compare --provider-a openai --provider-b anthropic --sample 200
+
+  # Compare two models from the same provider
+  spice classify compare --provider-a openai --model-a gpt-4o-mini --provider-b openai --model-b gpt-4`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			if providerA == "" || providerB == "" {
+				return fmt.Errorf("both --provider-a and --provider-b are required")
+			}
+			if sample <= 0 {
+				return fmt.Errorf("--sample must be positive")
+			}
+
+			store, err := initStorage(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+			defer func() {
+				if closeErr := store.Close(); closeErr != nil {
+					slog.Error("failed to close storage", "error", closeErr)
+				}
+			}()
+
+			classifications, err := store.GetClassificationsByDateRange(ctx, time.Time{}, time.Now().AddDate(100, 0, 0))
+			if err != nil {
+				return fmt.Errorf("failed to get classifications: %w", err)
+			}
+			unclassified, err := store.GetTransactionsToClassify(ctx, nil)
+			if err != nil {
+				return fmt.Errorf("failed to get unclassified transactions: %w", err)
+			}
+
+			transactions := make([]model.Transaction, 0, len(classifications)+len(unclassified))
+			for _, c := range classifications {
+				transactions = append(transactions, c.Transaction)
+			}
+			transactions = append(transactions, unclassified...)
+			transactions = sampleTransactions(transactions, sample, seed)
+
+			if len(transactions) == 0 {
+				fmt.Println(cli.InfoStyle.Render("No transactions to compare")) //nolint:forbidigo // User-facing output
+				return nil
+			}
+
+			categories, err := store.GetCategories(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get categories: %w", err)
+			}
+
+			classifierA, err := newLLMClassifier(providerA, compareModel(providerA, modelA))
+			if err != nil {
+				return fmt.Errorf("failed to create provider A: %w", err)
+			}
+			defer func() {
+				if closeErr := classifierA.Close(); closeErr != nil {
+					slog.Error("failed to close provider A", "error", closeErr)
+				}
+			}()
+
+			classifierB, err := newLLMClassifier(providerB, compareModel(providerB, modelB))
+			if err != nil {
+				return fmt.Errorf("failed to create provider B: %w", err)
+			}
+			defer func() {
+				if closeErr := classifierB.Close(); closeErr != nil {
+					slog.Error("failed to close provider B", "error", closeErr)
+				}
+			}()
+
+			fmt.Printf("Comparing %s and %s on %d transactions...\n", providerA, providerB, len(transactions)) //nolint:forbidigo // User-facing output
+
+			report, err := engine.CompareClassifiers(ctx, classifierA, classifierB, transactions, categories, engine.CompareOptions{
+				BatchSize: batchSize,
+			})
+			if err != nil {
+				return fmt.Errorf("comparison failed: %w", err)
+			}
+
+			return printComparisonReport(report, providerA, providerB)
+		},
+	}
+
+	cmd.Flags().StringVar(&providerA, "provider-a", "", "First provider (openai, anthropic, claudecode, gemini, ollama)")
+	cmd.Flags().StringVar(&providerB, "provider-b", "", "Second provider (openai, anthropic, claudecode, gemini, ollama)")
+	cmd.Flags().StringVar(&modelA, "model-a", "", "Model for the first provider (default: provider default)")
+	cmd.Flags().StringVar(&modelB, "model-b", "", "Model for the second provider (default: provider default)")
+	cmd.Flags().IntVar(&sample, "sample", 200, "Number of transactions to sample")
+	cmd.Flags().IntVar(&batchSize, "batch-size", 5, "Number of merchants to process in each LLM batch")
+	cmd.Flags().Int64Var(&seed, "seed", 1, "Random seed for sampling (same seed gives the same sample)")
+
+	return cmd
+}
+
+// compareModel returns the model to use for a provider, preferring an explicit flag
+// and falling back to the configured model only when it belongs to that provider.
+func compareModel(provider, model string) string {
+	if model != "" {
+		return model
+	}
+	if provider == viper.GetString("llm.provider") {
+		return viper.GetString("llm.model")
+	}
+	return ""
+}
+
+// sampleTransactions returns up to n transactions chosen pseudo-randomly with the given seed.
+func sampleTransactions(transactions []model.Transaction, n int, seed int64) []model.Transaction {
+	if len(transactions) <= n {
+		return transactions
+	}
+
+	sampled := make([]model.Transaction, len(transactions))
+	copy(sampled, transactions)
+
+	rng := rand.New(rand.NewSource(seed)) //nolint:gosec // Sampling does not need crypto randomness
+	rng.Shuffle(len(sampled), func(i, j int) {
+		sampled[i], sampled[j] = sampled[j], sampled[i]
+	})
+
+	return sampled[:n]
+}
+
+// printComparisonReport renders agreement, divergent merchants and confidence distributions.
+func printComparisonReport(report *engine.ComparisonReport, providerA, providerB string) error {
+	fmt.Println()                                                                                      //nolint:forbidigo // User-facing output
+	fmt.Println(cli.TitleStyle.Render("Provider Comparison"))                                          //nolint:forbidigo // User-facing output
+	fmt.Printf("  Merchants: %d (%d transactions)\n", report.TotalMerchants, report.TotalTransactions) //nolint:forbidigo // User-facing output
+	fmt.Printf("  Agreement: %.1f%% (%d of %d compared)\n",                                            //nolint:forbidigo // User-facing output
+		report.AgreementRate()*100, report.Agreed, report.Agreed+len(report.Divergent))
+	if report.FailedA > 0 || report.FailedB > 0 {
+		fmt.Printf("  Unclassified: %s %d, %s %d\n", providerA, report.FailedA, providerB, report.FailedB) //nolint:forbidigo // User-facing output
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Println("\nConfidence distribution:") //nolint:forbidigo // User-facing output
+	_, _ = fmt.Fprintf(w, "  RANGE\t%s\t%s\n", providerA, providerB)
+	for i, lower := range engine.ConfidenceBuckets {
+		label := fmt.Sprintf("%.0f%%+", lower*100)
+		if i+1 < len(engine.ConfidenceBuckets) {
+			label = fmt.Sprintf("%.0f-%.0f%%", lower*100, engine.ConfidenceBuckets[i+1]*100)
+		}
+		_, _ = fmt.Fprintf(w, "  %s\t%d\t%d\n", label, report.DistributionA[i], report.DistributionB[i])
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(report.Divergent) == 0 {
+		fmt.Println(cli.FormatSuccess("\n✓ Providers agreed on every merchant")) //nolint:forbidigo // User-facing output
+		return nil
+	}
+
+	fmt.Println("\nDivergent merchants:") //nolint:forbidigo // User-facing output
+	_, _ = fmt.Fprintf(w, "  MERCHANT\tTXNS\t%s\t%s\n", providerA, providerB)
+	for _, d := range report.Divergent {
+		_, _ = fmt.Fprintf(w, "  %s\t%d\t%s (%.0f%%)\t%s (%.0f%%)\n",
+			d.Merchant, d.TransactionCount, d.CategoryA, d.ScoreA*100, d.CategoryB, d.ScoreB*100)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/spf13/cobra"
+)
+
+func classifyDiffCmd() *cobra.Command {
+	var since, output string
+
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Show transactions whose category changed since a point in time",
+		Long: `Show which transactions changed category since a point in time, grouped by
+old and new category with counts and amounts. Use it after 'spice classify --rerank'
+or 'spice recategorize' to audit what a bulk operation did.
+
+Changes come from the classification history. A transaction changed several
+times is shown once, from its category before --since to its latest one.
+
+--since accepts a date (2024-06-01), a local time (2024-06-01 14:30),
+an RFC 3339 timestamp, or a duration ago (90m, 24h).
+
+Examples:
+  # What changed in the last hour
+  spice classify diff --since 1h
+
+  # Everything recategorized since June, as JSON
+  spice classify diff --since 2024-06-01 --output json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			start, err := parseSince(since, time.Now())
+			if err != nil {
+				return err
+			}
+			if output != "table" && output != "json" {
+				return fmt.Errorf("invalid output format %q (use table or json)", output)
+			}
+
+			store, err := initReadOnlyStorage(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+			defer func() {
+				if closeErr := store.Close(); closeErr != nil {
+					slog.Error("failed to close storage", "error", closeErr)
+				}
+			}()
+
+			changes, err := store.GetCategoryChanges(ctx, start)
+			if err != nil {
+				return fmt.Errorf("failed to get category changes: %w", err)
+			}
+
+			groups := groupCategoryChanges(changes)
+			if output == "json" {
+				return writeCategoryChangesJSON(cmd.OutOrStdout(), start, groups)
+			}
+			return writeCategoryChangesTable(cmd.OutOrStdout(), groups)
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "", "Only changes at or after this time (date, time, RFC 3339 or duration ago)")
+	cmd.Flags().StringVar(&output, "output", "table", "Output format (table, json)")
+	_ = cmd.MarkFlagRequired("since")
+
+	return cmd
+}
+
+// parseSince parses a --since value as a duration before now, an RFC 3339
+// timestamp, or a local date or date and time.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("--since duration must not be negative")
+		}
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q (use YYYY-MM-DD, \"YYYY-MM-DD HH:MM\", RFC 3339 or a duration like 24h)", value)
+}
+
+// categoryChangeGroup collects the transactions that moved between the same
+// two categories.
+type categoryChangeGroup struct {
+	From    string
+	To      string
+	Changes []model.CategoryChange
+	Amount  float64
+}
+
+// groupCategoryChanges groups changes by from and to category, largest
+// groups first.
+func groupCategoryChanges(changes []model.CategoryChange) []categoryChangeGroup {
+	index := make(map[[2]string]int)
+	var groups []categoryChangeGroup
+	for _, change := range changes {
+		key := [2]string{change.From, change.To}
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, categoryChangeGroup{From: change.From, To: change.To})
+		}
+		groups[i].Changes = append(groups[i].Changes, change)
+		groups[i].Amount += change.Transaction.Amount
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if len(groups[i].Changes) != len(groups[j].Changes) {
+			return len(groups[i].Changes) > len(groups[j].Changes)
+		}
+		return groups[i].Amount > groups[j].Amount
+	})
+	return groups
+}
+
+// changeCategoryLabel names a category in diff output.
+func changeCategoryLabel(category string) string {
+	if category == "" {
+		return "(unclassified)"
+	}
+	return category
+}
+
+func writeCategoryChangesTable(w io.Writer, groups []categoryChangeGroup) error {
+	if len(groups) == 0 {
+		_, _ = fmt.Fprintln(w, "No category changes in this window")
+		return nil
+	}
+
+	total := 0
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "FROM\tTO\tCOUNT\tAMOUNT")
+	for _, group := range groups {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%.2f\n",
+			changeCategoryLabel(group.From), changeCategoryLabel(group.To), len(group.Changes), group.Amount)
+		total += len(group.Changes)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(w, "\n%d transactions changed category\n", total)
+	return nil
+}
+
+// categoryChangeJSON is the JSON representation of one changed transaction.
+type categoryChangeJSON struct {
+	ID        string  `json:"id"`
+	Date      string  `json:"date"`
+	Merchant  string  `json:"merchant"`
+	ChangedAt string  `json:"changed_at"`
+	Amount    float64 `json:"amount"`
+}
+
+// categoryChangeGroupJSON is the JSON representation of a from -> to group.
+type categoryChangeGroupJSON struct {
+	From         string               `json:"from"`
+	To           string               `json:"to"`
+	Transactions []categoryChangeJSON `json:"transactions"`
+	Count        int                  `json:"count"`
+	Amount       float64              `json:"amount"`
+}
+
+func writeCategoryChangesJSON(w io.Writer, since time.Time, groups []categoryChangeGroup) error {
+	out := struct {
+		Since  string                    `json:"since"`
+		Groups []categoryChangeGroupJSON `json:"groups"`
+		Total  int                       `json:"total_transactions"`
+	}{
+		Since:  since.Format(time.RFC3339),
+		Groups: make([]categoryChangeGroupJSON, 0, len(groups)),
+	}
+
+	for _, group := range groups {
+		item := categoryChangeGroupJSON{
+			From:         group.From,
+			To:           group.To,
+			Count:        len(group.Changes),
+			Amount:       group.Amount,
+			Transactions: make([]categoryChangeJSON, 0, len(group.Changes)),
+		}
+		for _, change := range group.Changes {
+			merchant := change.Transaction.MerchantName
+			if merchant == "" {
+				merchant = change.Transaction.Name
+			}
+			item.Transactions = append(item.Transactions, categoryChangeJSON{
+				ID:        change.Transaction.ID,
+				Date:      change.Transaction.Date.Format("2006-01-02"),
+				Merchant:  merchant,
+				ChangedAt: change.ChangedAt.Format(time.RFC3339),
+				Amount:    change.Transaction.Amount,
+			})
+		}
+		out.Groups = append(out.Groups, item)
+		out.Total += item.Count
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(out); err != nil {
+		return fmt.Errorf("failed to encode category changes as JSON: %w", err)
+	}
+	return nil
+}
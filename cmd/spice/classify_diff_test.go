@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+
+	got, err := parseSince("90m", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-90*time.Minute), got)
+
+	got, err = parseSince("2024-06-01T08:00:00Z", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC), got)
+
+	got, err = parseSince("2024-06-01 14:30", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 6, 1, 14, 30, 0, 0, time.Local), got)
+
+	got, err = parseSince("2024-06-01", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 6, 1, 0, 0, 0, 0, time.Local), got)
+
+	_, err = parseSince("last tuesday", now)
+	assert.ErrorContains(t, err, "invalid --since")
+
+	_, err = parseSince("-1h", now)
+	assert.Error(t, err)
+}
+
+func TestGroupCategoryChanges(t *testing.T) {
+	change := func(id, from, to string, amount float64) model.CategoryChange {
+		return model.CategoryChange{
+			From: from, To: to,
+			ChangedAt:   time.Date(2024, 6, 2, 9, 0, 0, 0, time.UTC),
+			Transaction: model.Transaction{ID: id, MerchantName: "Shop " + id, Amount: amount, Date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		}
+	}
+	groups := groupCategoryChanges([]model.CategoryChange{
+		change("a", "Food", "Groceries", 10),
+		change("b", "Shopping", "Hobbies", 500),
+		change("c", "Food", "Groceries", 15.5),
+		change("d", "", "Travel", 700),
+	})
+
+	require.Len(t, groups, 3)
+	assert.Equal(t, "Food", groups[0].From)
+	assert.Equal(t, "Groceries", groups[0].To)
+	assert.Len(t, groups[0].Changes, 2)
+	assert.InDelta(t, 25.5, groups[0].Amount, 0.001)
+	// Equal counts are ordered by amount
+	assert.Equal(t, "Travel", groups[1].To)
+	assert.Equal(t, "Hobbies", groups[2].To)
+
+	t.Run("table", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeCategoryChangesTable(&buf, groups))
+		assert.Contains(t, buf.String(), "(unclassified)")
+		assert.Contains(t, buf.String(), "4 transactions changed category")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeCategoryChangesJSON(&buf, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), groups))
+
+		var out struct {
+			Since  string `json:"since"`
+			Groups []struct {
+				From         string `json:"from"`
+				To           string `json:"to"`
+				Transactions []struct {
+					ID        string `json:"id"`
+					ChangedAt string `json:"changed_at"`
+				} `json:"transactions"`
+				Count int `json:"count"`
+			} `json:"groups"`
+			Total int `json:"total_transactions"`
+		}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+		assert.Equal(t, "2024-06-01T00:00:00Z", out.Since)
+		assert.Equal(t, 4, out.Total)
+		require.Len(t, out.Groups, 3)
+		assert.Equal(t, 2, out.Groups[0].Count)
+		assert.Equal(t, "c", out.Groups[0].Transactions[1].ID)
+		assert.Equal(t, "2024-06-02T09:00:00Z", out.Groups[0].Transactions[0].ChangedAt)
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeCategoryChangesTable(&buf, nil))
+		assert.Contains(t, buf.String(), "No category changes")
+	})
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/engine"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
+)
+
+// How classify handles a database without categories.
+const (
+	emptyCategoriesAsk      = "ask"
+	emptyCategoriesSeed     = "seed"
+	emptyCategoriesDiscover = "discover"
+	emptyCategoriesContinue = "continue"
+)
+
+// parseEmptyCategories validates a --empty-categories mode.
+func parseEmptyCategories(mode string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case emptyCategoriesAsk, "":
+		return emptyCategoriesAsk, nil
+	case emptyCategoriesSeed:
+		return emptyCategoriesSeed, nil
+	case emptyCategoriesDiscover:
+		return emptyCategoriesDiscover, nil
+	case emptyCategoriesContinue:
+		return emptyCategoriesContinue, nil
+	default:
+		return "", fmt.Errorf("invalid --empty-categories %q (use ask, seed, discover or continue)", mode)
+	}
+}
+
+// emptyCategoriesRun is what classify needs to give a database without
+// categories some before classifying.
+type emptyCategoriesRun struct {
+	in                *bufio.Reader
+	out               io.Writer
+	db                service.Storage
+	engine            *engine.ClassificationEngine
+	fromDate          *time.Time
+	mode              string
+	opts              engine.BatchClassificationOptions
+	discoverMerchants int
+}
+
+// handleEmptyCategories gives the AI categories to choose from when the
+// database has none, as run.mode says: seed the starter categories, approve
+// categories the AI proposes for the busiest merchants, or continue and let
+// the AI invent a category for every merchant. "ask" lets the user choose.
+func handleEmptyCategories(ctx context.Context, run emptyCategoriesRun) error {
+	categories, err := run.db.GetCategories(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get categories: %w", err)
+	}
+	if len(categories) > 0 || run.mode == emptyCategoriesContinue {
+		return nil
+	}
+
+	mode := run.mode
+	if mode == emptyCategoriesAsk {
+		if mode, err = askEmptyCategories(run); err != nil {
+			return err
+		}
+	}
+
+	switch mode {
+	case emptyCategoriesSeed:
+		created, seedErr := engine.SeedStarterCategories(ctx, run.db)
+		if seedErr != nil {
+			return seedErr
+		}
+		slog.Info(fmt.Sprintf("✓ Added %d starter categories; adjust them later with 'spice categories'", len(created)))
+	case emptyCategoriesDiscover:
+		return discoverCategories(ctx, run)
+	default:
+		slog.Warn("Classifying without categories; the AI will invent one for every merchant")
+	}
+	return nil
+}
+
+// askEmptyCategories asks how to handle the empty database. Without an
+// answer, such as when input isn't a terminal, classify continues as is.
+func askEmptyCategories(run emptyCategoriesRun) (string, error) {
+	_, _ = fmt.Fprintln(run.out, "\nThere are no categories yet, so the AI would have to invent one for every merchant.")
+	_, _ = fmt.Fprintf(run.out, "  1) Add %d starter categories (Groceries, Dining, Utilities, Salary, ...)\n", len(engine.StarterCategories))
+	_, _ = fmt.Fprintf(run.out, "  2) Let the AI propose categories for your %d busiest merchants and approve them first\n", run.discoverMerchants)
+	_, _ = fmt.Fprintln(run.out, "  3) Continue without categories")
+	_, _ = fmt.Fprint(run.out, "Choice [1]: ")
+
+	answer, err := run.in.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read choice: %w", err)
+	}
+	if err != nil && strings.TrimSpace(answer) == "" {
+		_, _ = fmt.Fprintln(run.out)
+		return emptyCategoriesContinue, nil
+	}
+
+	switch strings.TrimSpace(answer) {
+	case "", "1":
+		return emptyCategoriesSeed, nil
+	case "2":
+		return emptyCategoriesDiscover, nil
+	case "3":
+		return emptyCategoriesContinue, nil
+	default:
+		return "", fmt.Errorf("invalid choice %q", strings.TrimSpace(answer))
+	}
+}
+
+// discoverCategories shows the categories the AI proposes and creates the
+// ones the user approves.
+func discoverCategories(ctx context.Context, run emptyCategoriesRun) error {
+	slog.Info("Asking the AI to propose categories", "merchants", run.discoverMerchants)
+	proposals, err := run.engine.DiscoverCategories(ctx, run.fromDate, run.discoverMerchants, run.opts)
+	if err != nil {
+		return err
+	}
+	if len(proposals) == 0 {
+		slog.Warn("The AI proposed no categories; classifying without any")
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(run.out, "\nThe AI proposed %d categories:\n", len(proposals))
+	for i, proposal := range proposals {
+		examples := proposal.Merchants
+		if len(examples) > 3 {
+			examples = examples[:3]
+		}
+		_, _ = fmt.Fprintf(run.out, "  %2d) %s (%s, %d transactions, e.g. %s)\n",
+			i+1, proposal.Name, proposal.Type, proposal.Transactions, strings.Join(examples, ", "))
+		if proposal.Description != "" {
+			_, _ = fmt.Fprintf(run.out, "      %s\n", proposal.Description)
+		}
+	}
+	_, _ = fmt.Fprint(run.out, "Create which? (all, none, or numbers like 1,3,4) [all]: ")
+
+	answer, err := run.in.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read approval: %w", err)
+	}
+	if err != nil && strings.TrimSpace(answer) == "" {
+		// Nobody to approve them, so create none
+		_, _ = fmt.Fprintln(run.out)
+		answer = "none"
+	}
+
+	approved, err := selectProposals(proposals, answer)
+	if err != nil {
+		return err
+	}
+	created, err := engine.CreateProposedCategories(ctx, run.db, approved)
+	if err != nil {
+		return err
+	}
+	if len(created) == 0 {
+		slog.Warn("No categories approved; classifying without any")
+		return nil
+	}
+	slog.Info(fmt.Sprintf("✓ Created %d categories", len(created)))
+	return nil
+}
+
+// selectProposals returns the proposals an answer approves: all of them for an
+// empty answer or "all", none for "none", or the listed 1-based numbers.
+func selectProposals(proposals []engine.CategoryProposal, answer string) ([]engine.CategoryProposal, error) {
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	switch answer {
+	case "", "all":
+		return proposals, nil
+	case "none":
+		return nil, nil
+	}
+
+	seen := make(map[int]bool)
+	var selected []engine.CategoryProposal
+	for _, field := range strings.FieldsFunc(answer, func(r rune) bool { return r == ',' || r == ' ' }) {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 1 || n > len(proposals) {
+			return nil, fmt.Errorf("invalid category number %q (use 1-%d)", field, len(proposals))
+		}
+		if !seen[n] {
+			seen[n] = true
+			selected = append(selected, proposals[n-1])
+		}
+	}
+	return selected, nil
+}
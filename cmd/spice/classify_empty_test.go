@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/engine"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectProposals(t *testing.T) {
+	proposals := []engine.CategoryProposal{{Name: "Groceries"}, {Name: "Dining"}, {Name: "Salary"}}
+
+	all, err := selectProposals(proposals, "\n")
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	none, err := selectProposals(proposals, "None")
+	require.NoError(t, err)
+	assert.Empty(t, none)
+
+	some, err := selectProposals(proposals, "3, 1 3")
+	require.NoError(t, err)
+	assert.Equal(t, []engine.CategoryProposal{{Name: "Salary"}, {Name: "Groceries"}}, some)
+
+	_, err = selectProposals(proposals, "4")
+	assert.ErrorContains(t, err, "use 1-3")
+}
+
+func TestHandleEmptyCategories(t *testing.T) {
+	ctx := context.Background()
+	newDB := func(t *testing.T) *storage.SQLiteStorage {
+		t.Helper()
+		db, err := storage.NewSQLiteStorage(":memory:")
+		require.NoError(t, err)
+		require.NoError(t, db.Migrate(ctx))
+		t.Cleanup(func() { _ = db.Close() })
+		return db
+	}
+	run := func(db *storage.SQLiteStorage, mode, input string) (emptyCategoriesRun, *bytes.Buffer) {
+		var out bytes.Buffer
+		return emptyCategoriesRun{
+			in:                bufio.NewReader(strings.NewReader(input)),
+			out:               &out,
+			db:                db,
+			engine:            engine.New(db, engine.NewMockClassifier(), engine.NewMockPrompter(true)),
+			mode:              mode,
+			discoverMerchants: engine.DefaultDiscoveryMerchants,
+		}, &out
+	}
+	countCategories := func(t *testing.T, db *storage.SQLiteStorage) int {
+		t.Helper()
+		categories, err := db.GetCategories(ctx)
+		require.NoError(t, err)
+		return len(categories)
+	}
+
+	t.Run("asking defaults to the starter categories", func(t *testing.T) {
+		db := newDB(t)
+		r, out := run(db, emptyCategoriesAsk, "\n")
+		require.NoError(t, handleEmptyCategories(ctx, r))
+		assert.Contains(t, out.String(), "no categories yet")
+		assert.Equal(t, len(engine.StarterCategories), countCategories(t, db))
+	})
+
+	t.Run("no answer continues without categories", func(t *testing.T) {
+		db := newDB(t)
+		r, _ := run(db, emptyCategoriesAsk, "")
+		require.NoError(t, handleEmptyCategories(ctx, r))
+		assert.Zero(t, countCategories(t, db))
+	})
+
+	t.Run("existing categories skip the question", func(t *testing.T) {
+		db := newDB(t)
+		_, err := db.CreateCategoryWithType(ctx, "Groceries", "", model.CategoryTypeExpense)
+		require.NoError(t, err)
+		r, out := run(db, emptyCategoriesSeed, "")
+		require.NoError(t, handleEmptyCategories(ctx, r))
+		assert.Empty(t, out.String())
+		assert.Equal(t, 1, countCategories(t, db))
+	})
+
+	t.Run("invalid choice", func(t *testing.T) {
+		r, _ := run(newDB(t), emptyCategoriesAsk, "7\n")
+		assert.ErrorContains(t, handleEmptyCategories(ctx, r), "invalid choice")
+	})
+}
+
+func TestParseEmptyCategories(t *testing.T) {
+	mode, err := parseEmptyCategories("Discover")
+	require.NoError(t, err)
+	assert.Equal(t, emptyCategoriesDiscover, mode)
+
+	_, err = parseEmptyCategories("later")
+	assert.ErrorContains(t, err, "invalid --empty-categories")
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/engine"
+	"github.com/Veraticus/the-spice-must-flow/internal/llm"
+	"github.com/spf13/viper"
+)
+
+// classifyEstimate is the JSON form of a --dry-run estimate.
+type classifyEstimate struct {
+	Providers         []providerEstimate `json:"providers"`
+	TotalTransactions int                `json:"total_transactions"`
+	TotalMerchants    int                `json:"total_merchants"`
+	Refunds           int                `json:"refunds"`
+	RuleMerchants     int                `json:"rule_merchants"`
+	RuleTxns          int                `json:"rule_transactions"`
+	CheckMerchants    int                `json:"check_pattern_merchants"`
+	CheckTxns         int                `json:"check_pattern_transactions"`
+	LLMMerchants      int                `json:"llm_merchants"`
+	LLMTxns           int                `json:"llm_transactions"`
+	llm.TokenEstimate
+}
+
+// providerEstimate is the approximate cost of the estimated run with one
+// provider's model.
+type providerEstimate struct {
+	llm.ModelPrice
+	EstimatedCost float64 `json:"estimated_cost"`
+}
+
+// runClassifyEstimate estimates the LLM calls, tokens and cost of classifying
+// the transactions from fromDate with opts, without calling the LLM.
+func runClassifyEstimate(ctx context.Context, w io.Writer, classificationEngine *engine.ClassificationEngine, fromDate *time.Time, opts engine.BatchClassificationOptions, output string) error {
+	estimate, err := classificationEngine.EstimateClassificationBatch(ctx, fromDate, opts)
+	if err != nil {
+		return fmt.Errorf("failed to estimate classification: %w", err)
+	}
+
+	promptConfig := llm.Config{
+		TopN:           viper.GetInt("llm.top_n"),
+		Language:       viper.GetString("llm.language"),
+		Explain:        opts.Explain,
+		MaxFieldLength: viper.GetInt("llm.max_field_length"),
+		PromptTemplate: expandPath(viper.GetString("llm.prompt_template")),
+	}
+	var tokens llm.TokenEstimate
+	for _, batch := range estimate.Batches {
+		batchTokens, tokenErr := llm.EstimateBatchTokens(promptConfig, batch.Requests, batch.Categories)
+		if tokenErr != nil {
+			return fmt.Errorf("failed to estimate tokens: %w", tokenErr)
+		}
+		tokens = tokens.Add(batchTokens)
+	}
+
+	result := classifyEstimate{
+		TotalTransactions: estimate.TotalTransactions,
+		TotalMerchants:    estimate.TotalMerchants,
+		Refunds:           estimate.RefundCount,
+		RuleMerchants:     estimate.RuleMerchants,
+		RuleTxns:          estimate.RuleTxns,
+		CheckMerchants:    estimate.CheckMerchants,
+		CheckTxns:         estimate.CheckTxns,
+		LLMMerchants:      estimate.LLMMerchants,
+		LLMTxns:           estimate.LLMTxns,
+		TokenEstimate:     tokens,
+	}
+	for _, price := range modelPrices() {
+		result.Providers = append(result.Providers, providerEstimate{ModelPrice: price, EstimatedCost: price.Cost(tokens)})
+	}
+
+	if output == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+	return writeClassifyEstimateTable(w, result)
+}
+
+// modelPrices returns the built-in model prices with any overrides from
+// llm.pricing.<provider> applied.
+func modelPrices() []llm.ModelPrice {
+	prices := make([]llm.ModelPrice, 0, len(llm.DefaultModelPrices))
+	for _, price := range llm.DefaultModelPrices {
+		key := "llm.pricing." + price.Provider
+		if model := viper.GetString(key + ".model"); model != "" {
+			price.Model = model
+		}
+		if viper.IsSet(key + ".input_per_million") {
+			price.InputPerMillion = viper.GetFloat64(key + ".input_per_million")
+		}
+		if viper.IsSet(key + ".output_per_million") {
+			price.OutputPerMillion = viper.GetFloat64(key + ".output_per_million")
+		}
+		prices = append(prices, price)
+	}
+	return prices
+}
+
+func writeClassifyEstimateTable(w io.Writer, estimate classifyEstimate) error {
+	if estimate.TotalTransactions == 0 && estimate.Refunds == 0 {
+		_, _ = fmt.Fprintln(w, "No transactions to classify")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "Dry run: nothing was classified and the AI wasn't called")
+	_, _ = fmt.Fprintf(tw, "Transactions\t%d (%d merchants)\n", estimate.TotalTransactions, estimate.TotalMerchants)
+	if estimate.Refunds > 0 {
+		_, _ = fmt.Fprintf(tw, "Refunds\t%d inherit their purchase's category\n", estimate.Refunds)
+	}
+	_, _ = fmt.Fprintf(tw, "Vendor/pattern rules\t%d merchants, %d transactions\n", estimate.RuleMerchants, estimate.RuleTxns)
+	_, _ = fmt.Fprintf(tw, "Check patterns\t%d merchants, %d transactions\n", estimate.CheckMerchants, estimate.CheckTxns)
+	_, _ = fmt.Fprintf(tw, "AI\t%d merchants, %d transactions in %d calls\n", estimate.LLMMerchants, estimate.LLMTxns, estimate.Calls)
+	_, _ = fmt.Fprintf(tw, "Tokens\t~%d prompt, ~%d completion\n", estimate.PromptTokens, estimate.CompletionTokens)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintln(tw, "PROVIDER\tMODEL\t$/1M IN\t$/1M OUT\tEST. COST")
+	for _, provider := range estimate.Providers {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%.3f\t%.3f\t$%.2f\n",
+			provider.Provider, provider.Model, provider.InputPerMillion, provider.OutputPerMillion, provider.EstimatedCost)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintln(w, "\nEstimates are rough: about 4 characters per token, list prices that change, and")
+	_, _ = fmt.Fprintln(w, "cached or embedding-matched merchants counted as AI calls. Override prices with llm.pricing.")
+	return nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/cli"
+	"github.com/Veraticus/the-spice-must-flow/internal/config"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
+	"github.com/spf13/viper"
+)
+
+// Report formats classify --report can write.
+const (
+	reportFormatSheets = "sheets"
+	reportFormatCSV    = "csv"
+	reportFormatXLSX   = "xlsx"
+)
+
+// parseReportFormat validates a --report-format.
+func parseReportFormat(format string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case reportFormatSheets, "":
+		return reportFormatSheets, nil
+	case reportFormatCSV:
+		return reportFormatCSV, nil
+	case reportFormatXLSX:
+		return reportFormatXLSX, nil
+	default:
+		return "", fmt.Errorf("invalid --report-format %q (use sheets, csv or xlsx)", format)
+	}
+}
+
+// reportPeriod returns the whole months spanning the transactions' dates.
+// It reports false when there are no transactions.
+func reportPeriod(transactions []model.Transaction) (time.Time, time.Time, bool) {
+	if len(transactions) == 0 {
+		return time.Time{}, time.Time{}, false
+	}
+
+	first, last := transactions[0].Date, transactions[0].Date
+	for _, txn := range transactions[1:] {
+		if txn.Date.Before(first) {
+			first = txn.Date
+		}
+		if txn.Date.After(last) {
+			last = txn.Date
+		}
+	}
+
+	start := time.Date(first.Year(), first.Month(), 1, 0, 0, 0, 0, time.Local)
+	end := time.Date(last.Year(), last.Month()+1, 1, 0, 0, 0, 0, time.Local).Add(-time.Nanosecond)
+	return start, end, true
+}
+
+// writeClassifyReport writes the report for start to end in format, the way
+// 'spice flow' would: with the flow account filters, the report settings and
+// the Sheets, CSV or Excel destination from the config. Unlike flow, still
+// unclassified transactions don't stop the report; they are left out.
+func writeClassifyReport(ctx context.Context, db service.Storage, format string, start, end time.Time) error {
+	accounts := newAccountFilter(viper.GetStringSlice("flow.accounts"), viper.GetStringSlice("flow.exclude_accounts"))
+
+	classifications, err := db.GetClassificationsByDateRange(ctx, start, end)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve classifications: %w", err)
+	}
+	classifications = accounts.filterClassifications(classifications)
+
+	categories, err := db.GetCategories(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve categories: %w", err)
+	}
+
+	unclassified, err := db.GetTransactionsToClassify(ctx, &start)
+	if err != nil {
+		return fmt.Errorf("failed to check for unclassified transactions: %w", err)
+	}
+	left := 0
+	for _, txn := range unclassified {
+		if !txn.Date.After(end) && accounts.allows(txn.AccountID) {
+			left++
+		}
+	}
+	if left > 0 {
+		slog.Warn(fmt.Sprintf("%d transactions in the report period are still unclassified and left out of the report", left))
+	}
+
+	summary := generateReportSummary(classifications, start, end)
+	period := fmt.Sprintf("%s to %s", start.Format("2006-01"), end.Format("2006-01"))
+
+	switch format {
+	case reportFormatCSV:
+		dir := config.ExpandPath(viper.GetString("flow.output"))
+		if err := exportToCSV(ctx, dir, classifications, summary, categories); err != nil {
+			return fmt.Errorf("failed to export to CSV: %w", err)
+		}
+		slog.Info(cli.FormatSuccess(fmt.Sprintf("Exported the %s report as CSV to %s", period, dir)))
+	case reportFormatXLSX:
+		path := config.ExpandPath(viper.GetString("flow.xlsx_file"))
+		if err := exportToXLSX(ctx, path, classifications, summary, categories); err != nil {
+			return fmt.Errorf("failed to export to Excel: %w", err)
+		}
+		slog.Info(cli.FormatSuccess(fmt.Sprintf("Exported the %s report to %s", period, path)))
+	default:
+		if err := exportToSheets(ctx, classifications, summary, categories); err != nil {
+			return fmt.Errorf("failed to export to Google Sheets: %w", err)
+		}
+		slog.Info(cli.FormatSuccess(fmt.Sprintf("Exported the %s report to Google Sheets", period)))
+	}
+	return nil
+}
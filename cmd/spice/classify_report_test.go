@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/csvreport"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportPeriod(t *testing.T) {
+	_, _, ok := reportPeriod(nil)
+	assert.False(t, ok)
+
+	start, end, ok := reportPeriod([]model.Transaction{
+		{Date: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{Date: time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)},
+		{Date: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+	})
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local), start)
+	assert.Equal(t, time.Date(2024, 3, 31, 23, 59, 59, 999999999, time.Local), end)
+}
+
+func TestParseReportFormat(t *testing.T) {
+	format, err := parseReportFormat("XLSX")
+	require.NoError(t, err)
+	assert.Equal(t, reportFormatXLSX, format)
+
+	format, err = parseReportFormat("")
+	require.NoError(t, err)
+	assert.Equal(t, reportFormatSheets, format)
+
+	_, err = parseReportFormat("pdf")
+	assert.ErrorContains(t, err, "invalid --report-format")
+}
+
+func TestWriteClassifyReport(t *testing.T) {
+	ctx := context.Background()
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+	defer func() { _ = db.Close() }()
+
+	_, err = db.CreateCategoryWithType(ctx, "Groceries", "", model.CategoryTypeExpense)
+	require.NoError(t, err)
+	date := time.Date(2024, 3, 10, 12, 0, 0, 0, time.Local)
+	transactions := []model.Transaction{
+		{ID: "tx1", Hash: "hash1", Name: "KROGER", MerchantName: "Kroger", Amount: 40, Type: "DEBIT", Date: date, AccountID: "acc1"},
+		{ID: "tx2", Hash: "hash2", Name: "MYSTERY", MerchantName: "Mystery", Amount: 10, Type: "DEBIT", Date: date, AccountID: "acc1"},
+	}
+	require.NoError(t, db.SaveTransactions(ctx, transactions))
+	require.NoError(t, db.SaveClassification(ctx, &model.Classification{
+		Transaction: transactions[0], Category: "Groceries", Status: model.StatusClassifiedByAI, Confidence: 0.97,
+	}))
+
+	dir := t.TempDir()
+	viper.Set("flow.output", dir)
+	defer viper.Set("flow.output", nil)
+
+	start, end, ok := reportPeriod(transactions)
+	require.True(t, ok)
+	require.NoError(t, writeClassifyReport(ctx, db, reportFormatCSV, start, end),
+		"the unclassified transaction only warns")
+
+	expenses, err := os.ReadFile(filepath.Join(dir, csvreport.ExpensesFile))
+	require.NoError(t, err)
+	assert.Contains(t, string(expenses), "Kroger")
+	assert.NotContains(t, string(expenses), "Mystery")
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"text/tabwriter"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func classifySimulateCmd() *cobra.Command {
+	var (
+		threshold float64
+		output    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "simulate",
+		Short: "Show what a different auto-accept threshold would have done",
+		Long: `Replay the AI classifications in the classification history against a
+proposed auto-accept threshold: how many would have been auto-accepted and how
+many sent to review, next to the current threshold.
+
+The likely error rate is the share of would-be auto-accepted transactions
+whose category was later changed, for example in review, by 'spice recategorize'
+or by a rerank. Merchants you gave another category in their first review
+were never saved with the AI's confidence, so they aren't counted. Nothing
+is changed.
+
+Examples:
+  # Would 90% have auto-accepted much more, and how much of it was wrong?
+  spice classify simulate --threshold 0.9
+
+  # As JSON
+  spice classify simulate --threshold 0.9 --output json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			if threshold < 0 || threshold > 1 {
+				return fmt.Errorf("--threshold must be between 0.0 and 1.0")
+			}
+			if output != "table" && output != "json" {
+				return fmt.Errorf("invalid output format %q (use table or json)", output)
+			}
+
+			store, err := initReadOnlyStorage(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+			defer func() {
+				if closeErr := store.Close(); closeErr != nil {
+					slog.Error("failed to close storage", "error", closeErr)
+				}
+			}()
+
+			outcomes, err := store.GetConfidenceOutcomes(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get classification history: %w", err)
+			}
+
+			proposed := simulateThreshold(outcomes, threshold)
+			current := simulateThreshold(outcomes, viper.GetFloat64("classification.auto_accept_threshold"))
+			if output == "json" {
+				return writeThresholdSimulationJSON(cmd.OutOrStdout(), proposed, current)
+			}
+			return writeThresholdSimulationTable(cmd.OutOrStdout(), proposed, current)
+		},
+	}
+
+	cmd.Flags().Float64Var(&threshold, "threshold", 0, "Proposed auto-accept threshold (0.0-1.0)")
+	cmd.Flags().StringVar(&output, "output", "table", "Output format (table, json)")
+	_ = cmd.MarkFlagRequired("threshold")
+
+	return cmd
+}
+
+// thresholdSimulation is what an auto-accept threshold would have done to the
+// AI classifications in the history.
+type thresholdSimulation struct {
+	Threshold    float64 `json:"threshold"`
+	Total        int     `json:"total"`
+	AutoAccepted int     `json:"auto_accepted"`
+	Review       int     `json:"review"`
+	// Errors counts auto-accepted transactions whose category was later changed
+	Errors int `json:"errors"`
+}
+
+// simulateThreshold counts the outcomes a threshold would have auto-accepted,
+// the same way classify does: at or above it.
+func simulateThreshold(outcomes []model.ConfidenceOutcome, threshold float64) thresholdSimulation {
+	simulation := thresholdSimulation{Threshold: threshold, Total: len(outcomes)}
+	for _, outcome := range outcomes {
+		if outcome.Confidence < threshold {
+			simulation.Review++
+			continue
+		}
+		simulation.AutoAccepted++
+		if !outcome.Correct() {
+			simulation.Errors++
+		}
+	}
+	return simulation
+}
+
+// ErrorRate is the share of auto-accepted transactions that were wrong.
+func (s thresholdSimulation) ErrorRate() float64 {
+	if s.AutoAccepted == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(s.AutoAccepted)
+}
+
+// shareOf formats count as a percentage of total.
+func shareOf(count, total int) string {
+	if total == 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%.1f%%", float64(count)/float64(total)*100)
+}
+
+func writeThresholdSimulationTable(w io.Writer, proposed, current thresholdSimulation) error {
+	if proposed.Total == 0 {
+		_, _ = fmt.Fprintln(w, "No AI classifications in the history to simulate")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "\tPROPOSED (%.0f%%)\tCURRENT (%.0f%%)\n", proposed.Threshold*100, current.Threshold*100)
+	_, _ = fmt.Fprintf(tw, "Auto-accepted\t%d (%s)\t%d (%s)\n",
+		proposed.AutoAccepted, shareOf(proposed.AutoAccepted, proposed.Total),
+		current.AutoAccepted, shareOf(current.AutoAccepted, current.Total))
+	_, _ = fmt.Fprintf(tw, "Sent to review\t%d (%s)\t%d (%s)\n",
+		proposed.Review, shareOf(proposed.Review, proposed.Total),
+		current.Review, shareOf(current.Review, current.Total))
+	_, _ = fmt.Fprintf(tw, "Likely errors\t%d (%s)\t%d (%s)\n",
+		proposed.Errors, shareOf(proposed.Errors, proposed.AutoAccepted),
+		current.Errors, shareOf(current.Errors, current.AutoAccepted))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(w, "\nBased on %d AI classifications; errors are auto-accepted transactions later given another category\n", proposed.Total)
+	return nil
+}
+
+func writeThresholdSimulationJSON(w io.Writer, proposed, current thresholdSimulation) error {
+	type simulationJSON struct {
+		thresholdSimulation
+		ErrorRate float64 `json:"error_rate"`
+	}
+	out := struct {
+		Proposed simulationJSON `json:"proposed"`
+		Current  simulationJSON `json:"current"`
+	}{
+		Proposed: simulationJSON{proposed, proposed.ErrorRate()},
+		Current:  simulationJSON{current, current.ErrorRate()},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(out); err != nil {
+		return fmt.Errorf("failed to encode simulation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulateThreshold(t *testing.T) {
+	outcomes := []model.ConfidenceOutcome{
+		{TransactionID: "1", AICategory: "Food", FinalCategory: "Food", Confidence: 0.99},
+		{TransactionID: "2", AICategory: "Food", FinalCategory: "Food", Confidence: 0.95},
+		{TransactionID: "3", AICategory: "Food", FinalCategory: "Shopping", Confidence: 0.92},
+		{TransactionID: "4", AICategory: "Travel", FinalCategory: "Travel", Confidence: 0.9},
+		{TransactionID: "5", AICategory: "Food", FinalCategory: "Travel", Confidence: 0.6},
+	}
+
+	current := simulateThreshold(outcomes, 0.95)
+	assert.Equal(t, thresholdSimulation{Threshold: 0.95, Total: 5, AutoAccepted: 2, Review: 3}, current)
+	assert.Zero(t, current.ErrorRate())
+
+	proposed := simulateThreshold(outcomes, 0.9)
+	assert.Equal(t, thresholdSimulation{Threshold: 0.9, Total: 5, AutoAccepted: 4, Review: 1, Errors: 1}, proposed)
+	assert.InDelta(t, 0.25, proposed.ErrorRate(), 1e-9)
+
+	var table bytes.Buffer
+	require.NoError(t, writeThresholdSimulationTable(&table, proposed, current))
+	assert.Contains(t, table.String(), "PROPOSED (90%)")
+	assert.Contains(t, table.String(), "4 (80.0%)")
+	assert.Contains(t, table.String(), "1 (25.0%)")
+
+	var out bytes.Buffer
+	require.NoError(t, writeThresholdSimulationJSON(&out, proposed, current))
+	var decoded map[string]map[string]float64
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.InDelta(t, 0.25, decoded["proposed"]["error_rate"], 1e-9)
+	assert.InDelta(t, 2, decoded["current"]["auto_accepted"], 1e-9)
+
+	var empty bytes.Buffer
+	require.NoError(t, writeThresholdSimulationTable(&empty, simulateThreshold(nil, 0.9), current))
+	assert.Contains(t, empty.String(), "No AI classifications")
+}
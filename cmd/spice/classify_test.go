@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseClassifyDateRange(t *testing.T) {
+	since, until, err := parseClassifyDateRange("2024-01-01", "2024-03-31")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), *since)
+	assert.True(t, until.After(time.Date(2024, 3, 31, 23, 59, 59, 0, time.UTC)), "until covers the whole day")
+	assert.True(t, until.Before(time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)))
+
+	since, until, err = parseClassifyDateRange("", "2024-03-31")
+	require.NoError(t, err)
+	assert.Nil(t, since)
+	assert.NotNil(t, until)
+
+	since, until, err = parseClassifyDateRange("2024-01-01", "")
+	require.NoError(t, err)
+	assert.NotNil(t, since)
+	assert.Nil(t, until)
+
+	since, until, err = parseClassifyDateRange("", "")
+	require.NoError(t, err)
+	assert.Nil(t, since)
+	assert.Nil(t, until)
+
+	_, _, err = parseClassifyDateRange("01/01/2024", "")
+	assert.ErrorContains(t, err, `invalid --since date "01/01/2024", expected YYYY-MM-DD`)
+
+	_, _, err = parseClassifyDateRange("", "2024-02-30")
+	assert.ErrorContains(t, err, `invalid --until date "2024-02-30"`)
+
+	_, _, err = parseClassifyDateRange("2024-04-01", "2024-03-31")
+	assert.ErrorContains(t, err, "--since 2024-04-01 is after --until 2024-03-31")
+
+	// A single day is a valid range
+	_, _, err = parseClassifyDateRange("2024-03-31", "2024-03-31")
+	assert.NoError(t, err)
+}
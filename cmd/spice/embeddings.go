@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/engine"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func embeddingsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "embeddings",
+		Short: "Manage merchant embeddings for nearest-neighbor classification",
+		Long: `Merchant embeddings let classify reuse the category of a similar, already
+classified merchant without an LLM call (llm.embeddings.enabled). Embeddings
+come from OpenAI and are stored per embedding model.`,
+	}
+
+	cmd.AddCommand(embeddingsBuildCmd())
+
+	return cmd
+}
+
+func embeddingsBuildCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "build",
+		Short: "Embed every classified merchant that has no embedding yet",
+		Long: `Embed the classified merchants that have no embedding for the configured
+model, in batches sent by several workers at once. Requests count against the
+llm.openai requests_per_minute and tokens_per_minute limits together with
+OpenAI classification, and failed requests are retried like classification.
+
+Each batch is saved as soon as it is embedded, so an interrupted or failed
+build picks up where it stopped when run again, and merchants that already
+have an embedding are never sent twice. classify does the same backfill on
+its first batch; building ahead of time keeps that first batch fast.
+
+Examples:
+  # Embed everything with the configured settings
+  spice embeddings build
+
+  # Larger requests, more of them in flight
+  spice embeddings build --batch-size 500 --workers 8`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			embedder, opts, err := createEmbedder()
+			if err != nil {
+				return err
+			}
+			if closer, ok := embedder.(interface{ Close() error }); ok {
+				defer func() { _ = closer.Close() }()
+			}
+
+			db, cleanup, err := getDatabase()
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			summary, err := engine.BuildMerchantEmbeddings(ctx, db, embedder, opts)
+			if err != nil {
+				slog.Info(fmt.Sprintf("Embedded %d of %d merchants before stopping; run again to resume", summary.Embedded, summary.Missing))
+				return fmt.Errorf("failed to build embeddings: %w", err)
+			}
+
+			if summary.Missing == 0 {
+				slog.Info("✓ Every classified merchant already has an embedding", "model", opts.Model)
+				return nil
+			}
+			slog.Info(fmt.Sprintf("✓ Embedded %d merchants in %d batches", summary.Embedded, summary.Batches), "model", opts.Model)
+			return nil
+		},
+	}
+
+	cmd.Flags().Int("batch-size", 0, "Merchants per embedding request (default 100)")
+	cmd.Flags().Int("workers", 0, "Embedding requests in flight at once (default 4)")
+	_ = viper.BindPFlag("llm.embeddings.batch_size", cmd.Flags().Lookup("batch-size"))
+	_ = viper.BindPFlag("llm.embeddings.workers", cmd.Flags().Lookup("workers"))
+
+	return cmd
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/sheets"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func exportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export report data for use outside of spice",
+		Long: `Export the numbers behind spice's reports as plain files, for dashboards,
+notebooks or other tools.`,
+	}
+
+	cmd.AddCommand(exportTimeSeriesCmd())
+
+	return cmd
+}
+
+func exportTimeSeriesCmd() *cobra.Command {
+	var (
+		from       string
+		to         string
+		format     string
+		byCategory bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "timeseries",
+		Short: "Export monthly cash flow as a time series",
+		Long: `Export the Monthly Flow data as a time series: one entry per month with
+income, expenses, net flow and running balance. Amounts are positive; net is
+income minus expenses. Months without transactions are included with zeros so
+the series is continuous.
+
+With --categories, each month also has the total of every category. In CSV
+these are extra columns after running_balance, one per category.
+
+--from and --to take a month (2024-01) or a date (2024-01-15, whose month is
+used). They default to the last 12 months.
+
+Examples:
+  # Last 12 months as JSON
+  spice export timeseries
+
+  # 2024 as CSV, with a column per category
+  spice export timeseries --from 2024-01 --to 2024-12 --format csv --categories > flow.csv`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			if format != "json" && format != "csv" {
+				return fmt.Errorf("invalid format %q: must be json or csv", format)
+			}
+
+			now := time.Now()
+			start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local).AddDate(0, -11, 0)
+			end := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+			var err error
+			if from != "" {
+				if start, err = parseExportMonth(from); err != nil {
+					return fmt.Errorf("invalid --from: %w", err)
+				}
+			}
+			if to != "" {
+				if end, err = parseExportMonth(to); err != nil {
+					return fmt.Errorf("invalid --to: %w", err)
+				}
+			}
+			if end.Before(start) {
+				return fmt.Errorf("--to must not be before --from")
+			}
+
+			store, err := initReadOnlyStorage(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+			defer func() {
+				if closeErr := store.Close(); closeErr != nil {
+					slog.Error("failed to close storage", "error", closeErr)
+				}
+			}()
+
+			// Through the last instant of the --to month
+			classifications, err := store.GetClassificationsByDateRange(ctx, start, end.AddDate(0, 1, 0).Add(-time.Nanosecond))
+			if err != nil {
+				return fmt.Errorf("failed to retrieve classifications: %w", err)
+			}
+			categories, err := store.GetCategories(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to retrieve categories: %w", err)
+			}
+
+			points := sheets.BuildTimeSeries(classifications, categories, sheets.TimeSeriesOptions{
+				From:                   start,
+				To:                     end,
+				ByCategory:             byCategory,
+				AggregationConcurrency: viper.GetInt("sheets.aggregation_concurrency"),
+			})
+
+			if format == "csv" {
+				return writeTimeSeriesCSV(os.Stdout, points)
+			}
+			return writeTimeSeriesJSON(os.Stdout, points)
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "First month to export (2024-01 or 2024-01-15)")
+	cmd.Flags().StringVar(&to, "to", "", "Last month to export (2024-12 or 2024-12-31)")
+	cmd.Flags().StringVar(&format, "format", "json", "Output format (json, csv)")
+	cmd.Flags().BoolVar(&byCategory, "categories", false, "Include each category's monthly total")
+
+	return cmd
+}
+
+// parseExportMonth parses a month (2006-01) or a date (2006-01-02) into the
+// first day of its month.
+func parseExportMonth(value string) (time.Time, error) {
+	for _, layout := range []string{"2006-01", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.Local), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a month (2006-01) or date (2006-01-02)", value)
+}
+
+// timeSeriesJSON is one month of the JSON export. Amounts are JSON numbers
+// with two decimals.
+type timeSeriesJSON struct {
+	Categories     map[string]json.Number `json:"categories,omitempty"`
+	Month          string                 `json:"month"`
+	Income         json.Number            `json:"income"`
+	Expenses       json.Number            `json:"expenses"`
+	Net            json.Number            `json:"net"`
+	RunningBalance json.Number            `json:"running_balance"`
+}
+
+func writeTimeSeriesJSON(w io.Writer, points []sheets.TimeSeriesPoint) error {
+	out := make([]timeSeriesJSON, 0, len(points))
+	for _, point := range points {
+		entry := timeSeriesJSON{
+			Month:          point.Month.Format("2006-01"),
+			Income:         amountNumber(point.Income),
+			Expenses:       amountNumber(point.Expenses),
+			Net:            amountNumber(point.Net),
+			RunningBalance: amountNumber(point.RunningBalance),
+		}
+		if point.Categories != nil {
+			entry.Categories = make(map[string]json.Number, len(point.Categories))
+			for name, amount := range point.Categories {
+				entry.Categories[name] = amountNumber(amount)
+			}
+		}
+		out = append(out, entry)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(out); err != nil {
+		return fmt.Errorf("failed to encode time series: %w", err)
+	}
+	return nil
+}
+
+func writeTimeSeriesCSV(w io.Writer, points []sheets.TimeSeriesPoint) error {
+	var categories []string
+	if len(points) > 0 {
+		for name := range points[0].Categories {
+			categories = append(categories, name)
+		}
+		sort.Strings(categories)
+	}
+
+	writer := csv.NewWriter(w)
+	header := append([]string{"month", "income", "expenses", "net", "running_balance"}, categories...)
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write time series header: %w", err)
+	}
+	for _, point := range points {
+		record := []string{
+			point.Month.Format("2006-01"),
+			point.Income.StringFixed(2),
+			point.Expenses.StringFixed(2),
+			point.Net.StringFixed(2),
+			point.RunningBalance.StringFixed(2),
+		}
+		for _, name := range categories {
+			record = append(record, point.Categories[name].StringFixed(2))
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write time series row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func amountNumber(amount decimal.Decimal) json.Number {
+	return json.Number(amount.StringFixed(2))
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/sheets"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExportMonth(t *testing.T) {
+	got, err := parseExportMonth("2024-03")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local), got)
+
+	got, err = parseExportMonth("2024-03-17")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local), got)
+
+	_, err = parseExportMonth("March")
+	assert.Error(t, err)
+}
+
+func TestWriteTimeSeries(t *testing.T) {
+	points := []sheets.TimeSeriesPoint{{
+		Month:          time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Income:         decimal.NewFromInt(1000),
+		Expenses:       decimal.RequireFromString("80.5"),
+		Net:            decimal.RequireFromString("919.5"),
+		RunningBalance: decimal.RequireFromString("919.5"),
+		Categories: map[string]decimal.Decimal{
+			"Salary":    decimal.NewFromInt(1000),
+			"Groceries": decimal.RequireFromString("80.5"),
+		},
+	}}
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeTimeSeriesJSON(&buf, points))
+
+		var out []map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+		require.Len(t, out, 1)
+		assert.Equal(t, "2024-01", out[0]["month"])
+		assert.InDelta(t, 919.5, out[0]["net"], 0.001, "amounts are numbers")
+		assert.InDelta(t, 80.5, out[0]["categories"].(map[string]any)["Groceries"], 0.001)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeTimeSeriesCSV(&buf, points))
+		assert.Equal(t,
+			"month,income,expenses,net,running_balance,Groceries,Salary\n"+
+				"2024-01,1000.00,80.50,919.50,919.50,80.50,1000.00\n",
+			buf.String())
+	})
+
+	t.Run("empty json is an array", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeTimeSeriesJSON(&buf, nil))
+		assert.Equal(t, "[]\n", buf.String())
+	})
+}
@@ -6,15 +6,18 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/Veraticus/the-spice-must-flow/internal/cli"
 	"github.com/Veraticus/the-spice-must-flow/internal/config"
+	"github.com/Veraticus/the-spice-must-flow/internal/csvreport"
 	"github.com/Veraticus/the-spice-must-flow/internal/model"
 	"github.com/Veraticus/the-spice-must-flow/internal/service"
 	"github.com/Veraticus/the-spice-must-flow/internal/sheets"
 	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/Veraticus/the-spice-must-flow/internal/xlsx"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -27,23 +30,74 @@ func flowCmd() *cobra.Command {
 		Long: `Analyze and visualize your financial flow with category breakdowns.
 		
 This command generates reports showing where your money flows,
-with options to export to Google Sheets.`,
-		RunE: runFlow,
+with options to export to Google Sheets, CSV files or an Excel workbook.
+
+Use --format csv to write the report as CSV files instead, one per Google
+Sheets tab (expenses.csv, income.csv, vendor_summary.csv, ...), into the
+--output directory. The numbers are the same as the Sheets export, amounts
+keep their full precision, and no Google credentials are needed.
+
+Use --format xlsx to write the report as a single Excel workbook, the
+--xlsx-file, with the same tabs, number formats and formulas as the Google
+Sheets export. Editing the Vendor Lookup, Category Lookup or Business Rules
+tabs still changes the categories and business percentages of the expenses.
+
+Use --read-only to open the database without write access. This guarantees
+the report can't modify anything and allows it to run alongside a classify run.
+
+Use --merge-db to combine the report with other spice databases, for example
+separate personal and business files. Extra databases are always opened
+read-only. Each source is named after its file, transaction IDs are prefixed
+with that name, and categories are merged by name.
+
+Use --account to report only on some accounts and --exclude-account to leave
+accounts out, such as a joint account in a personal report. Both take account
+IDs and apply to the summary and the export. With both, the report holds the
+included accounts minus the excluded ones: an account in both lists is left out.
+
+Use --snapshot to save the category set (names, types and business
+percentages) the report was built with, and --from-snapshot with the printed
+ID to build the report for the same period with those categories later, after
+categories were renamed, retyped or deleted. 'spice categories snapshots'
+lists saved snapshots. Only the categories are kept; transactions classified
+since, or moved by merging categories, still show up as they are now.`,
+		PreRunE: bindFlowFlags,
+		RunE:    runFlow,
 	}
 
 	// Flags
 	cmd.Flags().IntP("year", "y", time.Now().Year(), "Year to analyze")
 	cmd.Flags().StringP("month", "m", "", "Specific month to analyze (format: 2024-01)")
 	cmd.Flags().Bool("export", false, "Export to Google Sheets")
-	cmd.Flags().String("format", "table", "Output format (table, json, csv)")
+	cmd.Flags().String("format", "table", "Output format (table, json, csv, xlsx)")
+	cmd.Flags().String("output", ".", "Directory for the CSV report files (with --format csv)")
+	cmd.Flags().String("xlsx-file", "spice-report.xlsx", "Path of the Excel workbook (with --format xlsx)")
+	cmd.Flags().Bool("read-only", false, "Open the database in read-only mode")
+	cmd.Flags().StringSlice("merge-db", nil, "Additional database to include in the report (repeatable)")
+	cmd.Flags().StringSlice("account", nil, "Only include transactions from this account ID (repeatable)")
+	cmd.Flags().StringSlice("exclude-account", nil, "Leave out transactions from this account ID (repeatable, wins over --account)")
+	cmd.Flags().Bool("snapshot", false, "Save the category set used for the report so it can be regenerated with --from-snapshot")
+	cmd.Flags().Int64("from-snapshot", 0, "Build the report for a snapshot's period with its saved categories")
 
-	// Bind to viper
+	return cmd
+}
+
+// bindFlowFlags binds the flags of the flow report being run to viper. The
+// report is both 'spice flow' and 'spice report flow', so the binding waits
+// until it's known which of the two was run.
+func bindFlowFlags(cmd *cobra.Command, _ []string) error {
 	_ = viper.BindPFlag("flow.year", cmd.Flags().Lookup("year"))
 	_ = viper.BindPFlag("flow.month", cmd.Flags().Lookup("month"))
 	_ = viper.BindPFlag("flow.export", cmd.Flags().Lookup("export"))
 	_ = viper.BindPFlag("flow.format", cmd.Flags().Lookup("format"))
-
-	return cmd
+	_ = viper.BindPFlag("flow.output", cmd.Flags().Lookup("output"))
+	_ = viper.BindPFlag("flow.xlsx_file", cmd.Flags().Lookup("xlsx-file"))
+	_ = viper.BindPFlag("flow.read_only", cmd.Flags().Lookup("read-only"))
+	_ = viper.BindPFlag("flow.merge_dbs", cmd.Flags().Lookup("merge-db"))
+	_ = viper.BindPFlag("flow.accounts", cmd.Flags().Lookup("account"))
+	_ = viper.BindPFlag("flow.exclude_accounts", cmd.Flags().Lookup("exclude-account"))
+	_ = viper.BindPFlag("flow.snapshot", cmd.Flags().Lookup("snapshot"))
+	return nil
 }
 
 func runFlow(cmd *cobra.Command, _ []string) error {
@@ -52,6 +106,28 @@ func runFlow(cmd *cobra.Command, _ []string) error {
 	month := viper.GetString("flow.month")
 	export := viper.GetBool("flow.export")
 	format := viper.GetString("flow.format")
+	csvExport := format == "csv"
+	outputDir := viper.GetString("flow.output")
+	xlsxExport := format == "xlsx"
+	xlsxFile := viper.GetString("flow.xlsx_file")
+	readOnly := viper.GetBool("flow.read_only")
+	mergeDBs := viper.GetStringSlice("flow.merge_dbs")
+	accounts := newAccountFilter(viper.GetStringSlice("flow.accounts"), viper.GetStringSlice("flow.exclude_accounts"))
+	snapshot := viper.GetBool("flow.snapshot")
+	fromSnapshot, _ := cmd.Flags().GetInt64("from-snapshot")
+
+	if fromSnapshot < 0 {
+		return fmt.Errorf("--from-snapshot must be a snapshot ID")
+	}
+	if fromSnapshot > 0 && (cmd.Flags().Changed("year") || cmd.Flags().Changed("month")) {
+		return fmt.Errorf("--from-snapshot uses the snapshot's period; don't combine it with --year or --month")
+	}
+	// A rebuilt report already has its snapshot
+	snapshot = snapshot && fromSnapshot == 0
+	if snapshot && readOnly {
+		slog.Warn("Not saving a category snapshot: the database is open read-only")
+		snapshot = false
+	}
 
 	slog.Info(cli.FormatTitle("Analyzing your financial flow..."))
 
@@ -72,22 +148,56 @@ func runFlow(cmd *cobra.Command, _ []string) error {
 	}
 
 	// Initialize storage
-	storageService, err := initStorage(ctx)
+	var primary service.Storage
+	var err error
+	if readOnly {
+		primary, err = initReadOnlyStorage(ctx)
+	} else {
+		primary, err = initStorage(ctx)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to initialize storage: %w", err)
 	}
 
+	storageService, err := withMergedDatabases(ctx, primary, mergeDBs)
+	if err != nil {
+		_ = primary.Close()
+		return err
+	}
+	defer func() {
+		if closeErr := storageService.Close(); closeErr != nil {
+			slog.Error("failed to close storage", "error", closeErr)
+		}
+	}()
+
+	var saved *model.CategorySnapshot
+	if fromSnapshot > 0 {
+		saved, err = primary.GetCategorySnapshot(ctx, fromSnapshot)
+		if err != nil {
+			return fmt.Errorf("failed to get category snapshot: %w", err)
+		}
+		start, end = saved.PeriodStart.Local(), saved.PeriodEnd.Local()
+		slog.Info("Using category snapshot",
+			"id", saved.ID,
+			"taken", saved.CreatedAt.Format("2006-01-02 15:04"),
+			"categories", len(saved.Categories))
+	}
+
 	// Fetch classifications
 	classifications, err := storageService.GetClassificationsByDateRange(ctx, start, end)
 	if err != nil {
 		return fmt.Errorf("failed to retrieve classifications: %w", err)
 	}
+	classifications = accounts.filterClassifications(classifications)
 
 	// Fetch all categories to determine their types
 	categories, err := storageService.GetCategories(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to retrieve categories: %w", err)
 	}
+	if saved != nil {
+		categories = saved.Categories
+	}
 
 	// Build category type map
 	categoryTypes := make(map[string]model.CategoryType)
@@ -96,7 +206,7 @@ func runFlow(cmd *cobra.Command, _ []string) error {
 	}
 
 	// Check data coverage and classification status if exporting
-	if export {
+	if export || csvExport || xlsxExport {
 		// Get unclassified transactions to check completeness
 		unclassifiedTxns, err := storageService.GetTransactionsToClassify(ctx, nil)
 		if err != nil {
@@ -106,7 +216,7 @@ func runFlow(cmd *cobra.Command, _ []string) error {
 		// Filter unclassified transactions to our date range
 		var unclassifiedInRange []model.Transaction
 		for _, tx := range unclassifiedTxns {
-			if !tx.Date.Before(start) && !tx.Date.After(end) {
+			if !tx.Date.Before(start) && !tx.Date.After(end) && accounts.allows(tx.AccountID) {
 				unclassifiedInRange = append(unclassifiedInRange, tx)
 			}
 		}
@@ -142,7 +252,10 @@ func runFlow(cmd *cobra.Command, _ []string) error {
 
 	// Display period
 	period := fmt.Sprintf("%d", year)
-	if month != "" {
+	switch {
+	case saved != nil:
+		period = formatSnapshotPeriod(start, end)
+	case month != "":
 		period = month
 	}
 
@@ -160,14 +273,98 @@ func runFlow(cmd *cobra.Command, _ []string) error {
 		slog.Info(cli.FormatSuccess("Successfully exported to Google Sheets!"))
 	}
 
+	// Handle export to CSV files
+	if csvExport {
+		dir := config.ExpandPath(outputDir)
+		if err := exportToCSV(ctx, dir, classifications, summary, categories); err != nil {
+			return fmt.Errorf("failed to export to CSV: %w", err)
+		}
+		slog.Info(cli.FormatSuccess(fmt.Sprintf("Successfully exported CSV reports to %s", dir)))
+	}
+
+	// Handle export to an Excel workbook
+	if xlsxExport {
+		path := config.ExpandPath(xlsxFile)
+		if err := exportToXLSX(ctx, path, classifications, summary, categories); err != nil {
+			return fmt.Errorf("failed to export to Excel: %w", err)
+		}
+		slog.Info(cli.FormatSuccess(fmt.Sprintf("Successfully exported Excel report to %s", path)))
+	}
+
+	if snapshot {
+		taken := &model.CategorySnapshot{Report: "flow", PeriodStart: start, PeriodEnd: end, Categories: categories}
+		if err := primary.SaveCategorySnapshot(ctx, taken); err != nil {
+			return fmt.Errorf("failed to save category snapshot: %w", err)
+		}
+		slog.Info(cli.FormatSuccess(fmt.Sprintf("Saved category snapshot %d; regenerate this report with --from-snapshot %d", taken.ID, taken.ID)))
+	}
+
 	// Handle other formats
-	if format != "table" && !export {
+	if format != "table" && !csvExport && !xlsxExport && !export {
 		slog.Warn(cli.FormatWarning(fmt.Sprintf("Output format '%s' not yet implemented", format)))
 	}
 
 	return nil
 }
 
+// formatSnapshotPeriod names a report period: a year, a month, or a date range.
+func formatSnapshotPeriod(start, end time.Time) string {
+	if start.Month() == time.January && start.Day() == 1 && end.Month() == time.December && end.Day() == 31 && start.Year() == end.Year() {
+		return fmt.Sprintf("%d", start.Year())
+	}
+	if start.Day() == 1 && start.Year() == end.Year() && start.Month() == end.Month() && end.AddDate(0, 0, 1).Month() != end.Month() {
+		return start.Format("2006-01")
+	}
+	return fmt.Sprintf("%s to %s", start.Format("2006-01-02"), end.Format("2006-01-02"))
+}
+
+// accountFilter decides which accounts a report covers.
+type accountFilter struct {
+	include map[string]bool // Empty includes every account
+	exclude map[string]bool
+}
+
+func newAccountFilter(include, exclude []string) accountFilter {
+	filter := accountFilter{include: make(map[string]bool), exclude: make(map[string]bool)}
+	for _, id := range include {
+		if id = strings.TrimSpace(id); id != "" {
+			filter.include[id] = true
+		}
+	}
+	for _, id := range exclude {
+		if id = strings.TrimSpace(id); id != "" {
+			filter.exclude[id] = true
+		}
+	}
+	return filter
+}
+
+// allows reports whether an account is in the report. Exclusion wins over inclusion.
+func (f accountFilter) allows(accountID string) bool {
+	if f.exclude[accountID] {
+		return false
+	}
+	return len(f.include) == 0 || f.include[accountID]
+}
+
+// filterClassifications drops the classifications of accounts outside the report.
+func (f accountFilter) filterClassifications(classifications []model.Classification) []model.Classification {
+	if len(f.include) == 0 && len(f.exclude) == 0 {
+		return classifications
+	}
+
+	filtered := make([]model.Classification, 0, len(classifications))
+	for _, c := range classifications {
+		if f.allows(c.Transaction.AccountID) {
+			filtered = append(filtered, c)
+		}
+	}
+	slog.Info("Filtered report by account",
+		"kept", len(filtered),
+		"left_out", len(classifications)-len(filtered))
+	return filtered
+}
+
 func initStorage(ctx context.Context) (service.Storage, error) {
 	// Get database path from config
 	dbPath := viper.GetString("storage.database_path")
@@ -179,19 +376,114 @@ func initStorage(ctx context.Context) (service.Storage, error) {
 	dbPath = os.ExpandEnv(dbPath)
 
 	// Initialize storage
-	store, err := storage.NewSQLiteStorage(dbPath)
+	store, err := openStorage(dbPath)
 	if err != nil {
 		return nil, err
 	}
 
 	// Run migrations
 	if err := store.Migrate(ctx); err != nil {
+		_ = store.Close()
 		return nil, fmt.Errorf("failed to run migrations: %w", err)
 	}
 
 	return store, nil
 }
 
+// initReadOnlyStorage opens the database without write access for reporting.
+// PostgreSQL has no read-only mode here, so it is opened as usual.
+func initReadOnlyStorage(ctx context.Context) (service.Storage, error) {
+	postgres, err := usePostgres()
+	if err != nil {
+		return nil, err
+	}
+	if postgres {
+		return initStorage(ctx)
+	}
+	return openReadOnlyStorage(ctx, primaryDatabasePath())
+}
+
+// primaryDatabasePath returns the configured database path with variables expanded.
+func primaryDatabasePath() string {
+	dbPath := viper.GetString("storage.database_path")
+	if dbPath == "" {
+		dbPath = "$HOME/.local/share/spice/spice.db"
+	}
+	return os.ExpandEnv(dbPath)
+}
+
+// openReadOnlyStorage opens the database at dbPath without write access.
+func openReadOnlyStorage(ctx context.Context, dbPath string) (*storage.SQLiteStorage, error) {
+	store, err := storage.NewSQLiteStorageReadOnly(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Migrations can't run read-only; this only verifies the schema is current
+	if err := store.Migrate(ctx); err != nil {
+		_ = store.Close()
+		return nil, fmt.Errorf("database is not ready for read-only access (run 'spice migrate' first): %w", err)
+	}
+
+	return store, nil
+}
+
+// withMergedDatabases returns primary unchanged when there is nothing to merge.
+// Otherwise it opens each extra database read-only and unions them with primary.
+func withMergedDatabases(ctx context.Context, primary service.Storage, paths []string) (service.ReportStorage, error) {
+	if len(paths) == 0 {
+		return primary, nil
+	}
+
+	names := sourceNames(append([]string{primaryDatabasePath()}, paths...))
+	sources := []storage.NamedSource{{Name: names[0], Storage: primary}}
+	closeOpened := func() {
+		for _, src := range sources[1:] {
+			_ = src.Storage.Close()
+		}
+	}
+
+	for i, path := range paths {
+		store, err := openReadOnlyStorage(ctx, os.ExpandEnv(path))
+		if err != nil {
+			closeOpened()
+			return nil, fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		sources = append(sources, storage.NamedSource{Name: names[i+1], Storage: store})
+	}
+
+	merged, err := storage.NewMultiStorage(sources...)
+	if err != nil {
+		closeOpened()
+		return nil, err
+	}
+
+	slog.Info("Merging report across databases", "sources", names)
+	return merged, nil
+}
+
+// sourceNames derives a unique source name from each database file name,
+// adding a numeric suffix when two files share a name.
+func sourceNames(paths []string) []string {
+	names := make([]string, len(paths))
+	used := make(map[string]int)
+	for i, path := range paths {
+		base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		base = strings.ReplaceAll(base, storage.SourceSeparator, "_")
+		if base == "" || base == "." {
+			base = "db"
+		}
+		name := base
+		for used[name] > 0 {
+			used[base]++
+			name = fmt.Sprintf("%s-%d", base, used[base])
+		}
+		used[name]++
+		names[i] = name
+	}
+	return names
+}
+
 func generateReportSummary(classifications []model.Classification, start, end time.Time) *service.ReportSummary {
 	summary := &service.ReportSummary{
 		DateRange: service.DateRange{
@@ -282,6 +574,44 @@ func exportToSheets(ctx context.Context, classifications []model.Classification,
 	return nil
 }
 
+func exportToCSV(ctx context.Context, dir string, classifications []model.Classification, summary *service.ReportSummary, categories []model.Category) error {
+	// Only the report settings apply; CSV files need no Google credentials
+	reportConfig, err := config.LoadReportConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load report config: %w", err)
+	}
+
+	writer, err := csvreport.NewWriter(dir, *reportConfig, slog.Default())
+	if err != nil {
+		return fmt.Errorf("failed to create CSV writer: %w", err)
+	}
+
+	if err := writer.Write(ctx, classifications, summary, categories); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	return nil
+}
+
+func exportToXLSX(ctx context.Context, path string, classifications []model.Classification, summary *service.ReportSummary, categories []model.Category) error {
+	// Like CSV, the workbook needs only the report settings
+	reportConfig, err := config.LoadReportConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load report config: %w", err)
+	}
+
+	writer, err := xlsx.NewWriter(path, *reportConfig, slog.Default())
+	if err != nil {
+		return fmt.Errorf("failed to create Excel writer: %w", err)
+	}
+
+	if err := writer.Write(ctx, classifications, summary, categories); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	return nil
+}
+
 // validateDataCoverageFromClassifications ensures we have sufficient transaction data for the requested period
 // Note: This uses classifications as a proxy for transaction coverage. The assumption is that
 // if we have classified transactions, we have imported data for that period.
@@ -267,3 +267,58 @@ func TestUnclassifiedTransactionValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestSourceNames(t *testing.T) {
+	names := sourceNames([]string{
+		"/home/me/.local/share/spice/spice.db",
+		"/tmp/business.db",
+		"/backup/spice.db",
+		"/odd/a:b.sqlite",
+	})
+	assert.Equal(t, []string{"spice", "business", "spice-2", "a_b"}, names)
+}
+
+func TestAccountFilter(t *testing.T) {
+	classifications := []model.Classification{
+		{Transaction: model.Transaction{ID: "1", AccountID: "checking"}},
+		{Transaction: model.Transaction{ID: "2", AccountID: "joint"}},
+		{Transaction: model.Transaction{ID: "3", AccountID: "savings"}},
+	}
+	ids := func(filtered []model.Classification) []string {
+		var result []string
+		for _, c := range filtered {
+			result = append(result, c.Transaction.ID)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		want    []string
+	}{
+		{name: "no filter", want: []string{"1", "2", "3"}},
+		{name: "exclude", exclude: []string{"joint"}, want: []string{"1", "3"}},
+		{name: "include", include: []string{"checking", "joint"}, want: []string{"1", "2"}},
+		{name: "exclude wins over include", include: []string{"checking", "joint"}, exclude: []string{" joint "}, want: []string{"1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := newAccountFilter(tt.include, tt.exclude)
+			assert.Equal(t, tt.want, ids(filter.filterClassifications(classifications)))
+		})
+	}
+}
+
+func TestFormatSnapshotPeriod(t *testing.T) {
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 0, 0, 0, 0, time.Local)
+	}
+
+	assert.Equal(t, "2024", formatSnapshotPeriod(day(2024, time.January, 1), day(2024, time.December, 31).Add(24*time.Hour-time.Nanosecond)))
+	assert.Equal(t, "2024-02", formatSnapshotPeriod(day(2024, time.February, 1), day(2024, time.February, 29).Add(23*time.Hour)))
+	assert.Equal(t, "2024-02-01 to 2024-02-10", formatSnapshotPeriod(day(2024, time.February, 1), day(2024, time.February, 10)))
+	assert.Equal(t, "2024-02-10 to 2024-03-09", formatSnapshotPeriod(day(2024, time.February, 10), day(2024, time.March, 9)))
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/cli"
+	"github.com/Veraticus/the-spice-must-flow/internal/engine"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func forecastCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "forecast",
+		Short: "Estimate next month's spending per category",
+		Long: `Project spending per category for an upcoming month.
+
+The forecast combines detected recurring merchants (charged once a month in
+most months at a steady price, as in "spice recurring"), projected at their
+latest price, with trailing averages of everything else. Monthly totals are
+the same as on the Monthly Flow sheet, so refunds offset their category's
+spending. It is an ESTIMATE based on your classified history, not a budget.
+
+Examples:
+  # Forecast next month using the last 6 months
+  spice forecast
+
+  # Use a longer lookback window
+  spice forecast --lookback 12
+
+  # Forecast a specific month
+  spice forecast --month 2024-07`,
+		RunE: runForecast,
+	}
+
+	cmd.Flags().Int("lookback", engine.DefaultForecastLookbackMonths, "Number of months of history to base the forecast on")
+	cmd.Flags().StringP("month", "m", "", "Month to forecast (format: 2024-01, default: next month)")
+
+	_ = viper.BindPFlag("forecast.lookback", cmd.Flags().Lookup("lookback"))
+	_ = viper.BindPFlag("forecast.month", cmd.Flags().Lookup("month"))
+
+	return cmd
+}
+
+func runForecast(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+	lookback := viper.GetInt("forecast.lookback")
+	month := viper.GetString("forecast.month")
+
+	if lookback <= 0 {
+		return fmt.Errorf("lookback must be a positive number of months")
+	}
+
+	opts := engine.ForecastOptions{LookbackMonths: lookback}
+	if month != "" {
+		parsed, err := time.ParseInLocation("2006-01", month, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid month format '%s', expected YYYY-MM: %w", month, err)
+		}
+		opts.Month = parsed
+	}
+
+	store, err := initStorage(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer func() {
+		if closeErr := store.Close(); closeErr != nil {
+			slog.Error("Failed to close storage", "error", closeErr)
+		}
+	}()
+
+	forecast, err := engine.Forecast(ctx, store, opts)
+	if err != nil {
+		return fmt.Errorf("failed to build forecast: %w", err)
+	}
+
+	title := fmt.Sprintf("%s Spending Forecast (estimate)", forecast.Month.Format("January 2006"))
+	slog.Info(cli.RenderBox(title, formatForecastContent(forecast)))
+
+	return nil
+}
+
+func formatForecastContent(forecast *engine.SpendingForecast) string {
+	if len(forecast.Categories) == 0 {
+		return fmt.Sprintf(`No expense history in the last %d months.
+Run 'spice classify' to categorize transactions first.`, forecast.LookbackMonths)
+	}
+
+	content := fmt.Sprintf("Projected total: $%.2f\nBased on: last %d months\n\n", forecast.Total, forecast.LookbackMonths)
+	content += fmt.Sprintf("  %-24s %12s %12s %12s  %s", "Category", "Projected", "Recurring", "± Std Dev", "Confidence")
+
+	for _, cat := range forecast.Categories {
+		content += fmt.Sprintf("\n  %-24s $%11.2f $%11.2f $%11.2f  %s",
+			cat.Category, cat.Projected, cat.Recurring, cat.StdDev, cat.Confidence)
+	}
+
+	content += "\n\nThese figures are estimates based on past spending, not guarantees."
+
+	return content
+}
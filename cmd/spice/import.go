@@ -4,8 +4,6 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"os"
-	"path/filepath"
 	"time"
 
 	"github.com/Veraticus/the-spice-must-flow/internal/cli"
@@ -23,10 +21,15 @@ func importCmd() *cobra.Command {
 		Long: `Import financial transactions from your connected Plaid accounts.
 		
 This command fetches transactions from Plaid and stores them in the local database
-for later categorization. Transactions are deduplicated automatically.`,
+for later categorization. Transactions are deduplicated automatically.
+
+To import categories decided in another tool for transactions already in the
+database, use 'spice import classifications'.`,
 		RunE: runImport,
 	}
 
+	cmd.AddCommand(importClassificationsCmd())
+
 	// Date range flags
 	cmd.Flags().StringP("start-date", "s", "", "Start date for transaction import (format: 2006-01-02)")
 	cmd.Flags().StringP("end-date", "e", "", "End date for transaction import (format: 2006-01-02)")
@@ -134,11 +137,22 @@ func runImport(cmd *cobra.Command, _ []string) error {
 		return nil
 	}
 
-	// Initialize storage
-	dbPath := filepath.Join(os.Getenv("HOME"), ".config", "spice", "spice.db")
-	store, err := storage.NewSQLiteStorage(dbPath)
+	if err := saveImportedTransactions(ctx, transactions); err != nil {
+		return err
+	}
+
+	slog.Info(cli.FormatSuccess("✓ Import complete!"))
+	displayTransactionSummary(transactions)
+
+	return nil
+}
+
+// saveImportedTransactions saves transactions to the storage selected by
+// storage.driver, checkpointing a SQLite database first unless disabled.
+func saveImportedTransactions(ctx context.Context, transactions []model.Transaction) error {
+	store, err := initStorage(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to open database: %w", err)
+		return fmt.Errorf("failed to initialize storage: %w", err)
 	}
 	defer func() {
 		if err := store.Close(); err != nil {
@@ -148,17 +162,21 @@ func runImport(cmd *cobra.Command, _ []string) error {
 
 	// Create auto-checkpoint unless disabled
 	if !viper.GetBool("import.no_checkpoint") && !viper.GetBool("checkpoint.auto_checkpoint_disabled") {
-		slog.Info("🗄️  Creating automatic checkpoint before import...")
-
-		// Create checkpoint manager
-		manager, err := store.NewCheckpointManager()
-		if err != nil {
-			slog.Warn("Failed to create checkpoint manager", "error", err)
+		if sqliteStore, ok := store.(*storage.SQLiteStorage); !ok {
+			slog.Info("Skipping automatic checkpoint: checkpoints need SQLite storage")
 		} else {
-			if err := manager.AutoCheckpoint(ctx, "import"); err != nil {
-				slog.Warn("Failed to create auto-checkpoint", "error", err)
+			slog.Info("🗄️  Creating automatic checkpoint before import...")
+
+			// Create checkpoint manager
+			manager, err := sqliteStore.NewCheckpointManager()
+			if err != nil {
+				slog.Warn("Failed to create checkpoint manager", "error", err)
 			} else {
-				slog.Info(cli.FormatSuccess("✓ Checkpoint created"))
+				if err := manager.AutoCheckpoint(ctx, "import"); err != nil {
+					slog.Warn("Failed to create auto-checkpoint", "error", err)
+				} else {
+					slog.Info(cli.FormatSuccess("✓ Checkpoint created"))
+				}
 			}
 		}
 	}
@@ -168,10 +186,6 @@ func runImport(cmd *cobra.Command, _ []string) error {
 	if err := store.SaveTransactions(ctx, transactions); err != nil {
 		return fmt.Errorf("failed to save transactions: %w", err)
 	}
-
-	slog.Info(cli.FormatSuccess("✓ Import complete!"))
-	displayTransactionSummary(transactions)
-
 	return nil
 }
 
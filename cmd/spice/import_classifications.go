@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/common"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
+	"github.com/spf13/cobra"
+)
+
+// maxUnmatchedShown caps how many unmatched rows are listed after an import.
+const maxUnmatchedShown = 10
+
+func importClassificationsCmd() *cobra.Command {
+	var (
+		createCategories bool
+		dryRun           bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "classifications <file>",
+		Short: "Import categories decided in another tool",
+		Long: `Classify transactions from a CSV file of categorization decisions made
+elsewhere, without asking the AI. Each row is saved as a manual classification.
+
+The file needs a header row with a category column and an id column
+(transaction_id also works), a hash column, or both. Rows are matched by
+transaction ID first and by hash when the ID isn't found. Rows that match no
+transaction are reported and skipped.
+
+Every category must already exist unless --create-categories is given; if any
+row names an unknown category, nothing is imported.
+
+Examples:
+  # Check the file without saving anything
+  spice import classifications reviewed.csv --dry-run
+
+  # Import, creating any categories that don't exist yet
+  spice import classifications reviewed.csv --create-categories`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			file, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open classifications file: %w", err)
+			}
+			defer func() { _ = file.Close() }()
+
+			rows, err := readClassificationImport(file)
+			if err != nil {
+				return err
+			}
+
+			db, cleanup, err := getDatabase()
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			result, err := importClassifications(ctx, db, rows, createCategories, dryRun)
+			if err != nil {
+				return err
+			}
+
+			verb := "Imported"
+			if dryRun {
+				verb = "Would import"
+			}
+			slog.Info(fmt.Sprintf("✓ %s %d of %d classifications", verb, result.Matched, len(rows)))
+			if len(result.CreatedCategories) > 0 {
+				verb = "Created"
+				if dryRun {
+					verb = "Would create"
+				}
+				slog.Info(fmt.Sprintf("%s categories: %s", verb, strings.Join(result.CreatedCategories, ", ")))
+			}
+			if len(result.Unmatched) > 0 {
+				slog.Warn(fmt.Sprintf("%d rows matched no transaction and were skipped", len(result.Unmatched)))
+				for i, row := range result.Unmatched {
+					if i == maxUnmatchedShown {
+						slog.Warn(fmt.Sprintf("  ... and %d more", len(result.Unmatched)-maxUnmatchedShown))
+						break
+					}
+					slog.Warn(fmt.Sprintf("  line %d: %s", row.Line, row.identifier()))
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&createCategories, "create-categories", false, "Create categories named in the file that don't exist yet")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Validate the file and show counts without saving")
+
+	return cmd
+}
+
+// classificationImportRow is one decision read from a classifications file.
+type classificationImportRow struct {
+	ID       string
+	Hash     string
+	Category string
+	Line     int
+}
+
+// identifier describes which transaction the row refers to.
+func (r classificationImportRow) identifier() string {
+	switch {
+	case r.ID != "" && r.Hash != "":
+		return fmt.Sprintf("id %s, hash %s", r.ID, r.Hash)
+	case r.ID != "":
+		return "id " + r.ID
+	default:
+		return "hash " + r.Hash
+	}
+}
+
+// classificationImportResult reports what an import matched.
+type classificationImportResult struct {
+	CreatedCategories []string
+	Unmatched         []classificationImportRow // Rows whose transaction wasn't found
+	Matched           int
+}
+
+// readClassificationImport reads a classifications CSV. Columns are found by
+// header name, so other columns are ignored.
+func readClassificationImport(r io.Reader) ([]classificationImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // Spreadsheets drop trailing empty cells
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read classifications header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["transaction_id"]; ok {
+		if _, ok := columns["id"]; !ok {
+			columns["id"] = columns["transaction_id"]
+		}
+	}
+	if _, ok := columns["category"]; !ok {
+		return nil, errors.New("classifications file is missing the category column")
+	}
+	_, hasID := columns["id"]
+	_, hasHash := columns["hash"]
+	if !hasID && !hasHash {
+		return nil, errors.New("classifications file needs an id or hash column")
+	}
+
+	cell := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []classificationImportRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read classifications line %d: %w", line, err)
+		}
+
+		row := classificationImportRow{
+			Line:     line,
+			ID:       cell(record, "id"),
+			Hash:     cell(record, "hash"),
+			Category: cell(record, "category"),
+		}
+		if row.ID == "" && row.Hash == "" {
+			if row.Category == "" {
+				continue // Blank line
+			}
+			return nil, fmt.Errorf("classifications line %d has a category but no transaction id or hash", line)
+		}
+		if row.Category == "" {
+			return nil, fmt.Errorf("classifications line %d has no category", line)
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// importClassifications saves each row's category for its transaction as a
+// manual classification. Categories are validated (or created) up front, so an
+// unknown category fails the import before anything is saved.
+func importClassifications(ctx context.Context, store service.Storage, rows []classificationImportRow, createCategories, dryRun bool) (classificationImportResult, error) {
+	var result classificationImportResult
+
+	categories, err := store.GetCategories(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to get categories: %w", err)
+	}
+	byName := make(map[string]string, len(categories))
+	for _, category := range categories {
+		byName[strings.ToLower(category.Name)] = category.Name
+	}
+
+	var unknown []string
+	for i := range rows {
+		key := strings.ToLower(rows[i].Category)
+		if name, ok := byName[key]; ok {
+			rows[i].Category = name
+			continue
+		}
+		if createCategories {
+			byName[key] = rows[i].Category
+			result.CreatedCategories = append(result.CreatedCategories, rows[i].Category)
+			continue
+		}
+		unknown = append(unknown, fmt.Sprintf("%q (line %d)", rows[i].Category, rows[i].Line))
+	}
+	if len(unknown) > 0 {
+		return result, fmt.Errorf("unknown categories, nothing was imported (use --create-categories to create them): %s", strings.Join(unknown, ", "))
+	}
+
+	if !dryRun {
+		for _, name := range result.CreatedCategories {
+			if _, err := store.CreateCategory(ctx, name, ""); err != nil {
+				return result, fmt.Errorf("failed to create category %q: %w", name, err)
+			}
+		}
+	}
+
+	for _, row := range rows {
+		txn, err := findImportedTransaction(ctx, store, row)
+		if errors.Is(err, common.ErrNotFound) {
+			result.Unmatched = append(result.Unmatched, row)
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		result.Matched++
+		if dryRun {
+			continue
+		}
+
+		classification := model.Classification{
+			Transaction:  *txn,
+			Category:     row.Category,
+			Status:       model.StatusUserModified,
+			Confidence:   1.0,
+			ClassifiedAt: time.Now(),
+		}
+		if err := store.SaveClassification(ctx, &classification); err != nil {
+			return result, fmt.Errorf("failed to save classification for line %d: %w", row.Line, err)
+		}
+	}
+
+	return result, nil
+}
+
+// findImportedTransaction looks a row's transaction up by ID, then by hash.
+// It returns common.ErrNotFound when neither matches.
+func findImportedTransaction(ctx context.Context, store service.Storage, row classificationImportRow) (*model.Transaction, error) {
+	if row.ID != "" {
+		txn, err := store.GetTransactionByID(ctx, row.ID)
+		if err == nil {
+			return txn, nil
+		}
+		if !errors.Is(err, common.ErrNotFound) {
+			return nil, fmt.Errorf("failed to get transaction %s: %w", row.ID, err)
+		}
+	}
+	if row.Hash != "" {
+		txn, err := store.GetTransactionByHash(ctx, row.Hash)
+		if err == nil {
+			return txn, nil
+		}
+		if !errors.Is(err, common.ErrNotFound) {
+			return nil, fmt.Errorf("failed to get transaction with hash %s: %w", row.Hash, err)
+		}
+	}
+	return nil, common.ErrNotFound
+}
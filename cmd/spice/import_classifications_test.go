@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadClassificationImport(t *testing.T) {
+	rows, err := readClassificationImport(strings.NewReader(
+		"\ufeffTransaction_ID,Hash,Merchant,Category\n" +
+			"t1,,ACME LLC,Hosting\n" +
+			",h2,Corner Shop, Snacks \n" +
+			",,,\n"))
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, classificationImportRow{ID: "t1", Category: "Hosting", Line: 2}, rows[0])
+	assert.Equal(t, classificationImportRow{Hash: "h2", Category: "Snacks", Line: 3}, rows[1])
+
+	_, err = readClassificationImport(strings.NewReader("id,merchant\nt1,ACME\n"))
+	require.ErrorContains(t, err, "missing the category column")
+
+	_, err = readClassificationImport(strings.NewReader("merchant,category\nACME,Hosting\n"))
+	require.ErrorContains(t, err, "needs an id or hash column")
+
+	_, err = readClassificationImport(strings.NewReader("id,category\nt1,\n"))
+	require.ErrorContains(t, err, "line 2 has no category")
+}
+
+func TestImportClassifications(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	defer func() {
+		if closeErr := store.Close(); closeErr != nil {
+			t.Logf("Failed to close store: %v", closeErr)
+		}
+	}()
+	require.NoError(t, store.Migrate(ctx))
+
+	_, err = store.CreateCategory(ctx, "Hosting", "Web hosting")
+	require.NoError(t, err)
+	require.NoError(t, store.SaveTransactions(ctx, []model.Transaction{
+		{ID: "t1", Hash: "h1", Date: time.Now(), Name: "ACME LLC", MerchantName: "ACME LLC", Amount: 20, AccountID: "acc"},
+		{ID: "t2", Hash: "h2", Date: time.Now(), Name: "Corner Shop", MerchantName: "Corner Shop", Amount: 5, AccountID: "acc"},
+	}))
+
+	manual := func() map[string]string {
+		classifications, err := store.QueryTransactions(ctx, model.TransactionQuery{Status: model.StatusUserModified})
+		require.NoError(t, err)
+		categories := make(map[string]string, len(classifications))
+		for _, c := range classifications {
+			categories[c.Transaction.ID] = c.Category
+		}
+		return categories
+	}
+
+	rows := func() []classificationImportRow {
+		return []classificationImportRow{
+			{ID: "t1", Category: "hosting", Line: 2},
+			{ID: "stale-id", Hash: "h2", Category: "Snacks", Line: 3},
+			{ID: "gone", Category: "Hosting", Line: 4},
+		}
+	}
+
+	t.Run("unknown category imports nothing", func(t *testing.T) {
+		_, err := importClassifications(ctx, store, rows(), false, false)
+		require.ErrorContains(t, err, `"Snacks" (line 3)`)
+		assert.Empty(t, manual())
+	})
+
+	t.Run("dry run only counts", func(t *testing.T) {
+		result, err := importClassifications(ctx, store, rows(), true, true)
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Matched)
+		assert.Equal(t, []string{"Snacks"}, result.CreatedCategories)
+		assert.Empty(t, manual())
+
+		_, err = store.GetCategoryByName(ctx, "Snacks")
+		assert.Error(t, err, "dry run must not create categories")
+	})
+
+	t.Run("import", func(t *testing.T) {
+		result, err := importClassifications(ctx, store, rows(), true, false)
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Matched)
+		require.Len(t, result.Unmatched, 1)
+		assert.Equal(t, 4, result.Unmatched[0].Line)
+		assert.Equal(t, map[string]string{"t1": "Hosting", "t2": "Snacks"}, manual())
+	})
+}
@@ -3,17 +3,21 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"log/slog"
 
 	"github.com/Veraticus/the-spice-must-flow/internal/engine"
 	"github.com/Veraticus/the-spice-must-flow/internal/llm"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
 	"github.com/spf13/viper"
 )
 
 // createLLMClient creates an LLM client based on configuration.
 // This function is shared by multiple commands that need LLM functionality.
+// When llm.tiered.enabled is set, a tiered classifier wrapping a cheap and a
+// strong model is returned instead.
 func createLLMClient() (engine.Classifier, error) {
 	// Read LLM configuration from viper
 	provider := viper.GetString("llm.provider")
@@ -21,10 +25,131 @@ func createLLMClient() (engine.Classifier, error) {
 		provider = "openai" // default provider
 	}
 
+	if viper.GetBool("llm.tiered.enabled") {
+		return createTieredLLMClient(provider)
+	}
+
+	return newLLMClassifier(provider, viper.GetString("llm.model"))
+}
+
+// createTieredLLMClient creates a classifier that escalates low-confidence
+// merchants from the cheap model to the strong model.
+func createTieredLLMClient(defaultProvider string) (*engine.TieredClassifier, error) {
+	threshold := viper.GetFloat64("llm.tiered.escalation_threshold")
+	if threshold < 0 || threshold > 1 {
+		return nil, fmt.Errorf("llm.tiered.escalation_threshold must be between 0 and 1, got %v", threshold)
+	}
+
+	cheapProvider := viper.GetString("llm.tiered.cheap_provider")
+	if cheapProvider == "" {
+		cheapProvider = defaultProvider
+	}
+	strongProvider := viper.GetString("llm.tiered.strong_provider")
+	if strongProvider == "" {
+		strongProvider = defaultProvider
+	}
+
+	cheap, err := newLLMClassifier(cheapProvider, viper.GetString("llm.tiered.cheap_model"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cheap tier classifier: %w", err)
+	}
+
+	strongModel := viper.GetString("llm.tiered.strong_model")
+	if strongModel == "" {
+		strongModel = viper.GetString("llm.model")
+	}
+	strong, err := newLLMClassifier(strongProvider, strongModel)
+	if err != nil {
+		if closeErr := cheap.Close(); closeErr != nil {
+			slog.Error("failed to close cheap tier classifier", "error", closeErr)
+		}
+		return nil, fmt.Errorf("failed to create strong tier classifier: %w", err)
+	}
+
+	return engine.NewTieredClassifier(cheap, strong, engine.TieredClassifierConfig{
+		EscalationThreshold:   threshold,
+		CheapCostPerMerchant:  viper.GetFloat64("llm.tiered.cheap_cost_per_merchant"),
+		StrongCostPerMerchant: viper.GetFloat64("llm.tiered.strong_cost_per_merchant"),
+	}), nil
+}
+
+// withEmbeddings puts a nearest-neighbor classifier in front of classifier
+// when llm.embeddings.enabled is set. Embeddings always come from OpenAI.
+func withEmbeddings(classifier engine.Classifier, store service.Storage) (engine.Classifier, error) {
+	if !viper.GetBool("llm.embeddings.enabled") {
+		return classifier, nil
+	}
+
+	minSimilarity := viper.GetFloat64("llm.embeddings.min_similarity")
+	if minSimilarity < 0 || minSimilarity > 1 {
+		return nil, fmt.Errorf("llm.embeddings.min_similarity must be between 0 and 1, got %v", minSimilarity)
+	}
+
+	embedder, buildOpts, err := createEmbedder()
+	if err != nil {
+		return nil, err
+	}
+
+	return engine.NewEmbeddingClassifier(classifier, embedder, store, engine.EmbeddingClassifierConfig{
+		Model:         buildOpts.Model,
+		MinSimilarity: minSimilarity,
+		BatchSize:     buildOpts.BatchSize,
+		Workers:       buildOpts.Workers,
+	}), nil
+}
+
+// createEmbedder creates the OpenAI embedder and the backfill options from
+// the llm.embeddings settings. Requests share the llm.openai rate limits with
+// OpenAI classifiers and are retried per llm.max_retries and llm.retry_delay.
+func createEmbedder() (llm.Embedder, engine.EmbeddingBuildOptions, error) {
+	opts := engine.EmbeddingBuildOptions{
+		Model:     viper.GetString("llm.embeddings.model"),
+		BatchSize: viper.GetInt("llm.embeddings.batch_size"),
+		Workers:   viper.GetInt("llm.embeddings.workers"),
+	}
+	if opts.Model == "" {
+		opts.Model = llm.DefaultEmbeddingModel
+	}
+	if opts.BatchSize < 0 {
+		return nil, opts, fmt.Errorf("llm.embeddings.batch_size must be positive, got %d", opts.BatchSize)
+	}
+	if opts.Workers < 0 {
+		return nil, opts, fmt.Errorf("llm.embeddings.workers must be positive, got %d", opts.Workers)
+	}
+
+	apiKey := viper.GetString("llm.openai_api_key")
+	if apiKey == "" {
+		apiKey = os.Getenv("OPENAI_API_KEY")
+	}
+	if apiKey == "" {
+		return nil, opts, fmt.Errorf("embeddings need an OpenAI API key in config or OPENAI_API_KEY environment variable")
+	}
+
+	config := llm.Config{
+		Provider:   "openai",
+		APIKey:     apiKey,
+		Model:      opts.Model,
+		MaxRetries: viper.GetInt("llm.max_retries"),
+		RetryDelay: viper.GetDuration("llm.retry_delay"),
+	}
+	if err := applyProviderRateLimits(&config); err != nil {
+		return nil, opts, err
+	}
+
+	embedder, err := llm.NewEmbedder(config)
+	if err != nil {
+		return nil, opts, fmt.Errorf("failed to create embedder: %w", err)
+	}
+	return embedder, opts, nil
+}
+
+// newLLMClassifier creates an LLM classifier for the given provider and model,
+// reading the remaining settings from configuration.
+func newLLMClassifier(provider, model string) (*llm.Classifier, error) {
 	// Build config from viper settings
 	config := llm.Config{
 		Provider:       provider,
-		Model:          viper.GetString("llm.model"),
+		Model:          model,
 		Temperature:    viper.GetFloat64("llm.temperature"),
 		MaxTokens:      viper.GetInt("llm.max_tokens"),
 		MaxRetries:     viper.GetInt("llm.max_retries"),
@@ -33,12 +158,26 @@ func createLLMClient() (engine.Classifier, error) {
 		RateLimit:      viper.GetInt("llm.rate_limit"),
 		ClaudeCodePath: viper.GetString("llm.claude_code_path"),
 		MaxTurns:       viper.GetInt("llm.max_turns"),
+		TopN:           viper.GetInt("llm.top_n"),
+		Language:       viper.GetString("llm.language"),
+		Explain:        viper.GetBool("classification.explain"),
+		MaxFieldLength: viper.GetInt("llm.max_field_length"),
+		PromptTemplate: expandPath(viper.GetString("llm.prompt_template")),
 	}
 
 	// Set defaults if not specified
 	if config.MaxRetries == 0 {
 		config.MaxRetries = 3
 	}
+	if config.TopN < 0 {
+		return nil, fmt.Errorf("llm.top_n must be positive, got %d", config.TopN)
+	}
+	if config.TopN == 0 {
+		config.TopN = llm.DefaultTopN
+	}
+	if config.MaxFieldLength < 0 {
+		return nil, fmt.Errorf("llm.max_field_length must be positive, got %d", config.MaxFieldLength)
+	}
 	if config.RetryDelay == 0 {
 		config.RetryDelay = time.Second
 	}
@@ -48,6 +187,9 @@ func createLLMClient() (engine.Classifier, error) {
 	if config.RateLimit == 0 {
 		config.RateLimit = 1000 // requests per minute
 	}
+	if err := applyProviderRateLimits(&config); err != nil {
+		return nil, err
+	}
 
 	// Get API key based on provider
 	switch provider {
@@ -83,6 +225,22 @@ func createLLMClient() (engine.Classifier, error) {
 			config.Model = "claude-3-opus-20240229"
 		}
 
+	case "gemini":
+		// Check viper first, then environment variable
+		apiKey := viper.GetString("llm.gemini_api_key")
+		if apiKey == "" {
+			apiKey = os.Getenv("SPICE_GEMINI_API_KEY")
+		}
+		if apiKey == "" {
+			return nil, fmt.Errorf("gemini API key not found in config or SPICE_GEMINI_API_KEY environment variable")
+		}
+		config.APIKey = apiKey
+
+		// Set default model if not specified
+		if config.Model == "" {
+			config.Model = "gemini-1.5-flash"
+		}
+
 	case "claudecode":
 		// Claude Code doesn't need an API key
 		config.APIKey = ""
@@ -92,6 +250,11 @@ func createLLMClient() (engine.Classifier, error) {
 			config.Model = "opus"
 		}
 
+	case "ollama":
+		if err := applyOllamaConfig(&config); err != nil {
+			return nil, err
+		}
+
 	default:
 		return nil, fmt.Errorf("unsupported LLM provider: %s", provider)
 	}
@@ -141,6 +304,9 @@ func createAnalysisLLMClient() (llm.Client, error) {
 	if config.RateLimit == 0 {
 		config.RateLimit = 1000 // requests per minute
 	}
+	if err := applyProviderRateLimits(&config); err != nil {
+		return nil, err
+	}
 
 	// Override max tokens for analysis (needs more for complex responses)
 	if config.MaxTokens < 4000 {
@@ -181,6 +347,22 @@ func createAnalysisLLMClient() (llm.Client, error) {
 			config.Model = "claude-3-opus-20240229"
 		}
 
+	case "gemini":
+		// Check viper first, then environment variable
+		apiKey := viper.GetString("llm.gemini_api_key")
+		if apiKey == "" {
+			apiKey = os.Getenv("SPICE_GEMINI_API_KEY")
+		}
+		if apiKey == "" {
+			return nil, fmt.Errorf("gemini API key not found in config or SPICE_GEMINI_API_KEY environment variable")
+		}
+		config.APIKey = apiKey
+
+		// Set default model if not specified
+		if config.Model == "" {
+			config.Model = "gemini-1.5-flash"
+		}
+
 	case "claudecode":
 		// Claude Code doesn't need an API key
 		config.APIKey = ""
@@ -191,6 +373,11 @@ func createAnalysisLLMClient() (llm.Client, error) {
 		// Set unlimited max turns for analysis (-1 means no limit)
 		config.MaxTurns = -1
 
+	case "ollama":
+		if err := applyOllamaConfig(&config); err != nil {
+			return nil, err
+		}
+
 	default:
 		return nil, fmt.Errorf("unsupported LLM provider: %s", provider)
 	}
@@ -198,3 +385,52 @@ func createAnalysisLLMClient() (llm.Client, error) {
 	// Create the raw client using the factory function
 	return llm.NewClient(config)
 }
+
+// applyProviderRateLimits reads the provider's own rate limits from
+// llm.<provider>, such as llm.openai.requests_per_minute, so each provider can
+// be held to the limits of its plan.
+func applyProviderRateLimits(config *llm.Config) error {
+	key := "llm." + strings.ToLower(config.Provider)
+	config.RequestsPerMinute = viper.GetInt(key + ".requests_per_minute")
+	config.TokensPerMinute = viper.GetInt(key + ".tokens_per_minute")
+
+	if config.RequestsPerMinute < 0 {
+		return fmt.Errorf("%s.requests_per_minute must be positive, got %d", key, config.RequestsPerMinute)
+	}
+	if config.TokensPerMinute < 0 {
+		return fmt.Errorf("%s.tokens_per_minute must be positive, got %d", key, config.TokensPerMinute)
+	}
+	return nil
+}
+
+// applyOllamaConfig points config at the Ollama server from the llm.ollama
+// settings. Local models are much slower than the cloud providers, so the
+// timeout and retries come from llm.ollama rather than llm.max_retries and
+// llm.retry_delay.
+func applyOllamaConfig(config *llm.Config) error {
+	// Ollama runs locally and doesn't need an API key
+	config.APIKey = ""
+	config.BaseURL = viper.GetString("llm.ollama.base_url")
+	config.Timeout = viper.GetDuration("llm.ollama.timeout")
+	config.MaxRetries = viper.GetInt("llm.ollama.max_retries")
+	config.RetryDelay = viper.GetDuration("llm.ollama.retry_delay")
+
+	if config.Timeout < 0 {
+		return fmt.Errorf("llm.ollama.timeout must be positive, got %s", config.Timeout)
+	}
+	if config.MaxRetries < 0 {
+		return fmt.Errorf("llm.ollama.max_retries must be positive, got %d", config.MaxRetries)
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = 2
+	}
+	if config.RetryDelay == 0 {
+		config.RetryDelay = 5 * time.Second
+	}
+
+	// Set default model if not specified
+	if config.Model == "" {
+		config.Model = llm.DefaultOllamaModel
+	}
+	return nil
+}
@@ -37,17 +37,29 @@ func init() {
 	// Add commands
 	rootCmd.AddCommand(analyzeCmd())
 	rootCmd.AddCommand(authCmd())
+	rootCmd.AddCommand(backfillCmd())
+	rootCmd.AddCommand(businessCmd())
 	rootCmd.AddCommand(categoriesCmd())
 	rootCmd.AddCommand(checkpointCmd())
 	rootCmd.AddCommand(checksCmd())
 	rootCmd.AddCommand(classifyCmd())
+	rootCmd.AddCommand(embeddingsCmd())
+	rootCmd.AddCommand(exportCmd())
 	rootCmd.AddCommand(importCmd())
 	rootCmd.AddCommand(vendorsCmd())
 	rootCmd.AddCommand(patternsCmd())
 	rootCmd.AddCommand(flowCmd())
+	rootCmd.AddCommand(forecastCmd())
 	rootCmd.AddCommand(migrateCmd())
 	rootCmd.AddCommand(institutionsCmd())
 	rootCmd.AddCommand(recategorizeCmd())
+	rootCmd.AddCommand(reconcileCmd())
+	rootCmd.AddCommand(recurringCmd())
+	rootCmd.AddCommand(reportCmd())
+	rootCmd.AddCommand(reviewCmd())
+	rootCmd.AddCommand(rulesCmd())
+	rootCmd.AddCommand(serveCmd())
+	rootCmd.AddCommand(transactionsCmd())
 	rootCmd.AddCommand(versionCmd())
 }
 
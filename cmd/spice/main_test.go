@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRootCommandResolution(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"report", "coverage"}, want: "spice report coverage"},
+		{args: []string{"report", "paychecks", "--splits"}, want: "spice report paychecks"},
+		{args: []string{"report", "flow", "--read-only"}, want: "spice report flow"},
+		{args: []string{"report"}, want: "spice report"},
+		{args: []string{"flow", "--read-only"}, want: "spice flow"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			cmd, _, err := rootCmd.Find(tt.args)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cmd.CommandPath())
+		})
+	}
+}
+
+func TestFlowFlagsBindToTheCommandRun(t *testing.T) {
+	for _, args := range [][]string{
+		{"report", "flow", "--read-only", "--year", "2021"},
+		{"flow", "--read-only", "--year", "2022"},
+	} {
+		cmd, flags, err := rootCmd.Find(args)
+		require.NoError(t, err)
+		require.NoError(t, cmd.ParseFlags(flags))
+		require.NoError(t, cmd.PreRunE(cmd, nil))
+
+		year, _ := cmd.Flags().GetInt("year")
+		assert.True(t, viper.GetBool("flow.read_only"), cmd.CommandPath())
+		assert.Equal(t, year, viper.GetInt("flow.year"), cmd.CommandPath())
+	}
+}
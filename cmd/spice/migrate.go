@@ -19,21 +19,77 @@ func migrateCmd() *cobra.Command {
 		Long: `Initialize or update the database schema to the latest version.
 		
 This command ensures your local database has all the required
-tables and indexes for the application to function properly.`,
+tables and indexes for the application to function properly.
+
+Use --to to move to a specific version instead, including rolling back to an
+earlier one. Only migrations that define how to undo themselves can be rolled
+back. Other commands upgrade the database again when they open it.
+
+Examples:
+  # Roll back the last two migrations
+  spice migrate --to 30
+
+  # Then return to the latest version
+  spice migrate`,
 		RunE: runMigrate,
 	}
 
 	// Flags
 	cmd.Flags().Bool("force", false, "Force migration even if already at latest version")
 	cmd.Flags().Bool("status", false, "Show current migration status without applying changes")
+	cmd.Flags().Int("to", 0, "Migrate to this schema version, rolling back if it is older")
+
+	cmd.AddCommand(migrateVerifyCmd())
 
 	return cmd
 }
 
+func migrateVerifyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify",
+		Short: "Check the migration chain against the expected schema",
+		Long: `Apply every migration to a throwaway in-memory database and check that the
+resulting tables, columns and indexes match the schema expected at the latest
+version. The upgrade is also replayed from every older version to catch
+migrations that only work on a fresh database.
+
+Your own database is never opened.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			result, err := storage.VerifyMigrations(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to verify migrations: %w", err)
+			}
+
+			if !result.OK() {
+				for _, problem := range result.Problems {
+					slog.Error("Schema drift", "problem", problem)
+				}
+				return fmt.Errorf("migration verification found %d problems", len(result.Problems))
+			}
+
+			slog.Info("✅ Migrations verified",
+				"version", result.Version,
+				"tables", result.Tables,
+				"indexes", result.Indexes,
+				"upgrade_paths", result.PathsChecked)
+			return nil
+		},
+	}
+}
+
 func runMigrate(cmd *cobra.Command, _ []string) error {
 	force, _ := cmd.Flags().GetBool("force")
 	status, _ := cmd.Flags().GetBool("status")
 
+	postgres, err := usePostgres()
+	if err != nil {
+		return err
+	}
+	if postgres {
+		return runMigratePostgres(cmd, status)
+	}
+
 	// Get database path from config
 	dbPath := viper.GetString("database.path")
 	if dbPath == "" {
@@ -87,8 +143,17 @@ func runMigrate(cmd *cobra.Command, _ []string) error {
 	slog.Info("🗄️  Running database migrations...")
 	slog.Info("Database", "path", dbPath)
 
-	// Run migrations
 	ctx := cmd.Context()
+	if cmd.Flags().Changed("to") {
+		target, _ := cmd.Flags().GetInt("to")
+		if err := store.MigrateTo(ctx, target); err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+		slog.Info("✅ Database migrated", "version", target)
+		return nil
+	}
+
+	// Run migrations
 	if err := store.Migrate(ctx); err != nil {
 		return fmt.Errorf("migration failed: %w", err)
 	}
@@ -97,3 +162,53 @@ func runMigrate(cmd *cobra.Command, _ []string) error {
 
 	return nil
 }
+
+// runMigratePostgres migrates the PostgreSQL database at storage.dsn.
+// Its migrations cannot be rolled back, so --to is refused.
+func runMigratePostgres(cmd *cobra.Command, status bool) error {
+	if cmd.Flags().Changed("to") {
+		return fmt.Errorf("--to is not supported with storage.driver postgres")
+	}
+
+	dsn := viper.GetString("storage.dsn")
+	if dsn == "" {
+		return fmt.Errorf("storage.dsn is required when storage.driver is postgres")
+	}
+	store, err := storage.NewPostgresStorage(dsn)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := cmd.Context()
+	if status {
+		currentVersion, err := store.SchemaVersion(ctx)
+		if err != nil {
+			return err
+		}
+
+		slog.Info("📊 Database Migration Status")
+		slog.Info("Database", "driver", "postgres")
+		slog.Info("Current version", "version", currentVersion)
+		slog.Info("Latest version", "version", storage.ExpectedSchemaVersion)
+		switch {
+		case currentVersion == storage.ExpectedSchemaVersion:
+			slog.Info("✅ Database is up to date")
+		case currentVersion < storage.ExpectedSchemaVersion:
+			slog.Warn("Database needs migration")
+		default:
+			slog.Error("Database version is newer than expected",
+				"database_version", currentVersion,
+				"expected_version", storage.ExpectedSchemaVersion)
+		}
+		return nil
+	}
+
+	slog.Info("🗄️  Running database migrations...")
+	if err := store.Migrate(ctx); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+
+	slog.Info("✅ Database migrations completed successfully!")
+	return nil
+}
@@ -29,6 +29,7 @@ direction, amount conditions, and merchant patterns for accurate categorization.
 	cmd.AddCommand(patternsEditCmd())
 	cmd.AddCommand(patternsDeleteCmd())
 	cmd.AddCommand(patternsTestCmd())
+	cmd.AddCommand(patternsSuggestCmd())
 
 	return cmd
 }
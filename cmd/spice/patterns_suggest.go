@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/cli"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/pattern"
+	"github.com/spf13/cobra"
+)
+
+func patternsSuggestCmd() *cobra.Command {
+	var minSupport int
+	var minAgreement float64
+	var since string
+	var acceptAll, dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "suggest",
+		Short: "Propose pattern rules learned from your manual classifications",
+		Long: `Look through the transactions you categorized by hand and propose pattern
+rules for what they have in common: a merchant you always put in one category,
+an amount range within a merchant (small Amazon orders versus large ones), or
+a merchant name prefix shared by several store locations.
+
+Each proposal shows how many of your classifications support it and how many
+contradict it. Accepted proposals are saved as pattern rules and apply from
+the next 'spice classify'.
+
+Examples:
+  # Review proposals one by one
+  spice patterns suggest
+
+  # Only learn from the last three months, and require 5 agreeing classifications
+  spice patterns suggest --since 2024-04-01 --min-support 5
+
+  # Show proposals without saving anything
+  spice patterns suggest --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			if minSupport < 1 {
+				return fmt.Errorf("--min-support must be at least 1")
+			}
+			if minAgreement <= 0 || minAgreement > 1 {
+				return fmt.Errorf("--min-agreement must be between 0 and 1")
+			}
+			if acceptAll && dryRun {
+				return fmt.Errorf("cannot use both --yes and --dry-run")
+			}
+
+			query := model.TransactionQuery{Status: model.StatusUserModified}
+			if since != "" {
+				start, err := time.Parse("2006-01-02", since)
+				if err != nil {
+					return fmt.Errorf("invalid --since date %q, expected YYYY-MM-DD: %w", since, err)
+				}
+				query.StartDate = &start
+			}
+
+			db, cleanup, err := getDatabase()
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			corrections, err := db.QueryTransactions(ctx, query)
+			if err != nil {
+				return fmt.Errorf("failed to get manual classifications: %w", err)
+			}
+			existing, err := db.GetActivePatternRules(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get pattern rules: %w", err)
+			}
+
+			proposals := pattern.LearnRules(corrections, existing, pattern.LearnOptions{
+				MinSupport:   minSupport,
+				MinAgreement: minAgreement,
+			})
+
+			out := cmd.OutOrStdout()
+			if len(proposals) == 0 {
+				_, _ = fmt.Fprintf(out, "No new patterns found in %d manual classifications.\n", len(corrections))
+				return nil
+			}
+			_, _ = fmt.Fprintf(out, "Found %d possible pattern rules in %d manual classifications.\n", len(proposals), len(corrections))
+
+			reader := bufio.NewReader(cmd.InOrStdin())
+			created := 0
+			for i, p := range proposals {
+				writeProposedRule(out, i+1, p)
+				if dryRun {
+					continue
+				}
+				if !acceptAll {
+					accept, err := promptYesNo(reader, "Create this rule?")
+					if err != nil {
+						return fmt.Errorf("failed to read answer: %w", err)
+					}
+					if !accept {
+						continue
+					}
+				}
+
+				rule := p.Rule
+				if err := db.CreatePatternRule(ctx, &rule); err != nil {
+					return fmt.Errorf("failed to create pattern rule %q: %w", rule.Name, err)
+				}
+				created++
+			}
+
+			if !dryRun {
+				slog.Info(cli.FormatSuccess(fmt.Sprintf("Created %d of %d proposed pattern rules", created, len(proposals))))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&minSupport, "min-support", pattern.DefaultMinSupport, "Matching manual classifications needed before a rule is proposed")
+	cmd.Flags().Float64Var(&minAgreement, "min-agreement", pattern.DefaultMinAgreement, "Share of matching classifications that must agree on the category (0-1)")
+	cmd.Flags().StringVar(&since, "since", "", "Only learn from transactions on or after this date (YYYY-MM-DD)")
+	cmd.Flags().BoolVarP(&acceptAll, "yes", "y", false, "Create every proposed rule without asking")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show proposals without creating rules")
+
+	return cmd
+}
+
+func writeProposedRule(w io.Writer, n int, p pattern.ProposedRule) {
+	rule := p.Rule
+	merchant := rule.MerchantPattern
+	if rule.IsRegex {
+		merchant = "/" + merchant + "/"
+	}
+	direction := "any"
+	if rule.Direction != nil {
+		direction = string(*rule.Direction)
+	}
+
+	_, _ = fmt.Fprintf(w, "\n%d. %s\n", n, rule.Name)
+	_, _ = fmt.Fprintf(w, "   Merchant:   %s\n", merchant)
+	_, _ = fmt.Fprintf(w, "   Amount:     %s\n", formatAmountCondition(rule))
+	_, _ = fmt.Fprintf(w, "   Direction:  %s\n", direction)
+	_, _ = fmt.Fprintf(w, "   Category:   %s\n", rule.DefaultCategory)
+	_, _ = fmt.Fprintf(w, "   Confidence: %.0f%%\n", rule.Confidence*100)
+	_, _ = fmt.Fprintf(w, "   Evidence:   %d agree, %d disagree\n", p.Support, p.Conflicts)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/pattern"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteProposedRule(t *testing.T) {
+	low, high := 8.0, 15.0
+	expense := model.DirectionExpense
+
+	var buf bytes.Buffer
+	writeProposedRule(&buf, 2, pattern.ProposedRule{
+		Rule: model.PatternRule{
+			Name:            "amazon $8.00-$15.00 → Books",
+			MerchantPattern: "amazon",
+			AmountCondition: "range",
+			AmountMin:       &low,
+			AmountMax:       &high,
+			Direction:       &expense,
+			DefaultCategory: "Books",
+			Confidence:      0.75,
+		},
+		Support:   3,
+		Conflicts: 1,
+	})
+
+	out := buf.String()
+	assert.Contains(t, out, "2. amazon $8.00-$15.00 → Books")
+	assert.Contains(t, out, "Amount:     8.00-15.00")
+	assert.Contains(t, out, "Direction:  expense")
+	assert.Contains(t, out, "Confidence: 75%")
+	assert.Contains(t, out, "Evidence:   3 agree, 1 disagree")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sort"
 	"strings"
 	"time"
 
@@ -12,17 +13,22 @@ import (
 	"github.com/Veraticus/the-spice-must-flow/internal/model"
 	"github.com/Veraticus/the-spice-must-flow/internal/service"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
 )
 
 func recategorizeCmd() *cobra.Command {
 	var (
-		fromDate  string
-		toDate    string
-		category  string
-		merchant  string
-		force     bool
-		dryRun    bool
-		batchSize int
+		fromDate        string
+		toDate          string
+		category        string
+		merchant        string
+		force           bool
+		overwrite       bool
+		dryRun          bool
+		batchSize       int
+		autoAccept      float64
+		parallelWorkers int
 	)
 
 	cmd := &cobra.Command{
@@ -31,24 +37,36 @@ func recategorizeCmd() *cobra.Command {
 		Long: `Recategorize existing transactions based on various criteria.
 This command allows you to re-run categorization on already classified transactions.
 
+Auto-accepting and parallel workers follow the classification settings
+'spice classify' uses, such as classification.auto_accept_threshold, unless
+--auto-accept-threshold or --parallel-workers is given.
+
 Examples:
   # Recategorize all transactions from 2024
   spice recategorize --from 2024-01-01 --to 2024-12-31
-  
-  # Recategorize only Miscellaneous transactions
-  spice recategorize --category "Miscellaneous"
-  
+
+  # Re-run the AI on a category that was being misused
+  spice recategorize --from-category "Shopping"
+
   # Recategorize all Amazon transactions
   spice recategorize --merchant "AMAZON"
-  
-  # Dry run to see what would be recategorized
-  spice recategorize --category "Other" --dry-run
-  
-  # Force recategorization without confirmation
-  spice recategorize --from 2024-01-01 --force`,
-		RunE: func(_ *cobra.Command, _ []string) error {
+
+  # Dry run to preview the category changes without saving
+  spice recategorize --from-category "Other" --dry-run
+
+  # Recategorize without confirmation, for scripts
+  spice recategorize --from 2024-01-01 --yes
+
+  # Include transactions you categorized by hand, which are skipped otherwise
+  spice recategorize --merchant "AMAZON" --force-overwrite`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
 			ctx := context.Background()
 
+			batchOpts, err := recategorizeBatchOptions(cmd.Flags(), batchSize)
+			if err != nil {
+				return err
+			}
+
 			// Validate date inputs
 			var fromTime, toTime *time.Time
 			if fromDate != "" {
@@ -90,6 +108,23 @@ Examples:
 				return fmt.Errorf("failed to find transactions: %w", err)
 			}
 
+			// Classifications made by hand stay unless overwriting them is asked for
+			overwrite = overwrite || viper.GetBool("classification.force_overwrite")
+			if overwrite {
+				if guarded, ok := store.(interface{ SetOverwriteUserModified(bool) }); ok {
+					guarded.SetOverwriteUserModified(true)
+				}
+			} else {
+				var skipped int
+				transactions, skipped, err = withoutUserModified(ctx, store, transactions)
+				if err != nil {
+					return err
+				}
+				if skipped > 0 {
+					fmt.Printf("Skipping %d transactions you categorized by hand (use --force-overwrite to include them)\n", skipped) //nolint:forbidigo // User-facing output
+				}
+			}
+
 			if len(transactions) == 0 {
 				fmt.Println(cli.InfoStyle.Render("No transactions found matching criteria")) //nolint:forbidigo // User-facing output
 				return nil
@@ -100,13 +135,8 @@ Examples:
 			// Show summary
 			showRecategorizationSummary(transactions)
 
-			if dryRun {
-				fmt.Println(cli.InfoStyle.Render("\n🔍 Dry run complete - no changes made")) //nolint:forbidigo // User-facing output
-				return nil
-			}
-
 			// Confirm action
-			if !force {
+			if !force && !dryRun {
 				fmt.Printf("\nAre you sure you want to recategorize %d transactions? (y/N): ", len(transactions)) //nolint:forbidigo // User prompt
 				var response string
 				if _, scanErr := fmt.Scanln(&response); scanErr != nil {
@@ -133,6 +163,7 @@ Examples:
 
 			// Initialize prompter
 			prompter := cli.NewCLIPrompter(nil, nil)
+			prompter.SetMinSuggestionConfidence(viper.GetFloat64("classification.min_suggestion_confidence"))
 
 			// Create classification engine with custom batch size
 			engineConfig := engine.DefaultConfig()
@@ -141,6 +172,10 @@ Examples:
 			}
 			classificationEngine := engine.NewWithConfig(store, classifier, prompter, engineConfig)
 
+			if dryRun {
+				return runRecategorizeDryRun(ctx, store, classificationEngine, transactions, batchOpts)
+			}
+
 			// Process transactions
 			fmt.Println(cli.InfoStyle.Render("\n🔄 Starting recategorization...")) //nolint:forbidigo // User-facing output
 
@@ -164,14 +199,6 @@ Examples:
 			// Run classification engine on ONLY these specific transactions
 			fmt.Println(cli.InfoStyle.Render("\n🤖 Running AI classification...")) //nolint:forbidigo // User-facing output
 
-			// Use batch classification options
-			batchOpts := engine.BatchClassificationOptions{
-				AutoAcceptThreshold: 0.95, // High threshold for auto-acceptance in recategorization
-				BatchSize:           batchSize,
-				ParallelWorkers:     2,
-				SkipManualReview:    false, // Always allow manual review for recategorization
-			}
-
 			summary, err := classificationEngine.ClassifySpecificTransactions(ctx, transactions, batchOpts)
 			if err != nil {
 				return fmt.Errorf("classification failed: %w", err)
@@ -194,15 +221,52 @@ Examples:
 
 	cmd.Flags().StringVar(&fromDate, "from", "", "Start date (YYYY-MM-DD)")
 	cmd.Flags().StringVar(&toDate, "to", "", "End date (YYYY-MM-DD)")
-	cmd.Flags().StringVar(&category, "category", "", "Recategorize only transactions in this category")
+	cmd.Flags().StringVar(&category, "from-category", "", "Recategorize only transactions currently in this category")
+	cmd.Flags().StringVar(&category, "category", "", "Same as --from-category")
 	cmd.Flags().StringVar(&merchant, "merchant", "", "Recategorize only transactions from this merchant")
-	cmd.Flags().BoolVar(&force, "force", false, "Skip confirmation prompt")
-	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview changes without applying them")
+	cmd.Flags().BoolVarP(&force, "yes", "y", false, "Skip confirmation prompt")
+	cmd.Flags().BoolVar(&force, "force", false, "Same as --yes")
+	cmd.Flags().BoolVar(&overwrite, "force-overwrite", false, "Also recategorize transactions you categorized by hand")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Classify and show old -> new category changes without saving")
 	cmd.Flags().IntVar(&batchSize, "batch-size", 50, "Number of transactions to process at once")
+	cmd.Flags().Float64Var(&autoAccept, "auto-accept-threshold", 0, "Auto-accept classifications above this confidence (default: classification.auto_accept_threshold)")
+	cmd.Flags().IntVar(&parallelWorkers, "parallel-workers", 0, "Number of parallel workers (default: classification.parallel_workers)")
+	_ = cmd.Flags().MarkHidden("category")
+	_ = cmd.Flags().MarkHidden("force")
 
 	return cmd
 }
 
+// recategorizeBatchOptions returns the batch options recategorizing runs with:
+// the classification settings classify uses, overridden by this command's
+// --auto-accept-threshold and --parallel-workers when they're given.
+func recategorizeBatchOptions(flags *pflag.FlagSet, batchSize int) (engine.BatchClassificationOptions, error) {
+	opts := engine.BatchClassificationOptions{
+		AutoAcceptThreshold: viper.GetFloat64("classification.auto_accept_threshold"),
+		BatchSize:           batchSize,
+		ParallelWorkers:     viper.GetInt("classification.parallel_workers"),
+		MaxInflightLLM:      viper.GetInt("classification.max_inflight_llm"),
+		SkipManualReview:    false, // Always allow manual review for recategorization
+		CategoryRetries:     viper.GetInt("classification.category_retries"),
+		RecordCalibration:   viper.GetBool("classification.record_calibration"),
+	}
+
+	if flags.Changed("auto-accept-threshold") {
+		opts.AutoAcceptThreshold, _ = flags.GetFloat64("auto-accept-threshold")
+	}
+	if flags.Changed("parallel-workers") {
+		opts.ParallelWorkers, _ = flags.GetInt("parallel-workers")
+	}
+
+	if opts.AutoAcceptThreshold < 0 || opts.AutoAcceptThreshold > 1 {
+		return opts, fmt.Errorf("auto-accept threshold must be between 0 and 1, got %.2f", opts.AutoAcceptThreshold)
+	}
+	if opts.ParallelWorkers < 1 {
+		return opts, fmt.Errorf("parallel workers must be at least 1")
+	}
+	return opts, nil
+}
+
 func findTransactionsToRecategorize(ctx context.Context, store service.Storage, fromDate, toDate *time.Time, category, merchant string) ([]model.Transaction, error) {
 	var transactions []model.Transaction
 
@@ -317,3 +381,222 @@ func showRecategorizationSummary(transactions []model.Transaction) {
 		}
 	}
 }
+
+// recategorizeChange describes a single transaction's proposed category change.
+type recategorizeChange struct {
+	Transaction model.Transaction
+	OldCategory string
+	NewCategory string
+	Confidence  float64
+}
+
+// recategorizeMerchantDiff holds the proposed changes for one merchant.
+type recategorizeMerchantDiff struct {
+	Merchant  string
+	Changes   []recategorizeChange
+	Unchanged int
+}
+
+// recategorizeMovement summarizes transactions moving from one category to another.
+type recategorizeMovement struct {
+	From   string
+	To     string
+	Count  int
+	Amount float64
+}
+
+// recategorizeDiff is the full dry-run result for a recategorization.
+type recategorizeDiff struct {
+	Merchants       []recategorizeMerchantDiff
+	Movements       []recategorizeMovement
+	NetByCategory   map[string]int
+	FailedMerchants []string
+	Unchanged       int
+}
+
+// runRecategorizeDryRun classifies the transactions without saving and prints the resulting diff.
+func runRecategorizeDryRun(ctx context.Context, store service.Storage, classificationEngine *engine.ClassificationEngine, transactions []model.Transaction, opts engine.BatchClassificationOptions) error {
+	previous, err := currentClassifications(ctx, store, transactions)
+	if err != nil {
+		return err
+	}
+
+	var results []engine.BatchResult
+	opts.DryRun = true
+	opts.ResultCollector = func(result engine.BatchResult) {
+		results = append(results, result)
+	}
+
+	fmt.Println(cli.InfoStyle.Render("\n🤖 Running AI classification (dry run)...")) //nolint:forbidigo // User-facing output
+
+	if _, err := classificationEngine.ClassifySpecificTransactions(ctx, transactions, opts); err != nil {
+		return fmt.Errorf("classification failed: %w", err)
+	}
+
+	printRecategorizeDiff(buildRecategorizeDiff(results, previous))
+
+	fmt.Println(cli.InfoStyle.Render("\n🔍 Dry run complete - no changes made")) //nolint:forbidigo // User-facing output
+	return nil
+}
+
+// currentClassifications returns the existing classification for each transaction, keyed by ID.
+func currentClassifications(ctx context.Context, store service.Storage, transactions []model.Transaction) (map[string]model.Classification, error) {
+	var start, end time.Time
+	for i, txn := range transactions {
+		if i == 0 || txn.Date.Before(start) {
+			start = txn.Date
+		}
+		if i == 0 || txn.Date.After(end) {
+			end = txn.Date
+		}
+	}
+
+	classifications, err := store.GetClassificationsByDateRange(ctx, start, end.Add(24*time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current classifications: %w", err)
+	}
+
+	previous := make(map[string]model.Classification, len(classifications))
+	for _, c := range classifications {
+		previous[c.Transaction.ID] = c
+	}
+	return previous, nil
+}
+
+// withoutUserModified drops the transactions whose classification the user
+// made or corrected, returning the rest and how many were dropped.
+func withoutUserModified(ctx context.Context, store service.Storage, transactions []model.Transaction) ([]model.Transaction, int, error) {
+	if len(transactions) == 0 {
+		return transactions, 0, nil
+	}
+	previous, err := currentClassifications(ctx, store, transactions)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	kept := make([]model.Transaction, 0, len(transactions))
+	for _, txn := range transactions {
+		if previous[txn.ID].Status != model.StatusUserModified {
+			kept = append(kept, txn)
+		}
+	}
+	return kept, len(transactions) - len(kept), nil
+}
+
+// buildRecategorizeDiff compares dry-run results against the current classifications.
+func buildRecategorizeDiff(results []engine.BatchResult, previous map[string]model.Classification) recategorizeDiff {
+	diff := recategorizeDiff{NetByCategory: make(map[string]int)}
+	movements := make(map[[2]string]*recategorizeMovement)
+
+	for _, result := range results {
+		if result.Error != nil || result.Suggestion == nil {
+			diff.FailedMerchants = append(diff.FailedMerchants, result.Merchant)
+			continue
+		}
+
+		merchantDiff := recategorizeMerchantDiff{Merchant: result.Merchant}
+		for _, txn := range result.Transactions {
+			oldCategory := previous[txn.ID].Category
+			newCategory := result.Suggestion.Category
+			if oldCategory == newCategory {
+				merchantDiff.Unchanged++
+				continue
+			}
+
+			merchantDiff.Changes = append(merchantDiff.Changes, recategorizeChange{
+				Transaction: txn,
+				OldCategory: oldCategory,
+				NewCategory: newCategory,
+				Confidence:  result.Suggestion.Score,
+			})
+
+			key := [2]string{oldCategory, newCategory}
+			if movements[key] == nil {
+				movements[key] = &recategorizeMovement{From: oldCategory, To: newCategory}
+			}
+			movements[key].Count++
+			movements[key].Amount += txn.Amount
+			diff.NetByCategory[oldCategory]--
+			diff.NetByCategory[newCategory]++
+		}
+
+		diff.Unchanged += merchantDiff.Unchanged
+		if len(merchantDiff.Changes) > 0 {
+			diff.Merchants = append(diff.Merchants, merchantDiff)
+		}
+	}
+
+	for _, m := range movements {
+		diff.Movements = append(diff.Movements, *m)
+	}
+
+	sort.Slice(diff.Merchants, func(i, j int) bool {
+		return diff.Merchants[i].Merchant < diff.Merchants[j].Merchant
+	})
+	sort.Slice(diff.Movements, func(i, j int) bool {
+		if diff.Movements[i].Count != diff.Movements[j].Count {
+			return diff.Movements[i].Count > diff.Movements[j].Count
+		}
+		if diff.Movements[i].From != diff.Movements[j].From {
+			return diff.Movements[i].From < diff.Movements[j].From
+		}
+		return diff.Movements[i].To < diff.Movements[j].To
+	})
+	sort.Strings(diff.FailedMerchants)
+
+	return diff
+}
+
+// printRecategorizeDiff renders the dry-run diff grouped by merchant.
+func printRecategorizeDiff(diff recategorizeDiff) {
+	if len(diff.Merchants) == 0 {
+		fmt.Println(cli.InfoStyle.Render("\nNo category changes proposed")) //nolint:forbidigo // User-facing output
+	}
+
+	for _, m := range diff.Merchants {
+		fmt.Printf("\n%s (%d changed, %d unchanged)\n", cli.BoldStyle.Render(m.Merchant), len(m.Changes), m.Unchanged) //nolint:forbidigo // User-facing output
+		for _, c := range m.Changes {
+			fmt.Printf("  %s  $%-10.2f %s -> %s (%.0f%%)\n", //nolint:forbidigo // User-facing output
+				c.Transaction.Date.Format("2006-01-02"),
+				c.Transaction.Amount,
+				recategorizeLabel(c.OldCategory),
+				c.NewCategory,
+				c.Confidence*100)
+		}
+	}
+
+	if len(diff.Movements) > 0 {
+		fmt.Println("\n📊 Net movements:") //nolint:forbidigo // User-facing output
+		for _, mv := range diff.Movements {
+			fmt.Printf("  %s -> %s: %d transactions ($%.2f)\n", recategorizeLabel(mv.From), mv.To, mv.Count, mv.Amount) //nolint:forbidigo // User-facing output
+		}
+
+		categories := make([]string, 0, len(diff.NetByCategory))
+		for cat, net := range diff.NetByCategory {
+			if net != 0 {
+				categories = append(categories, cat)
+			}
+		}
+		sort.Strings(categories)
+
+		fmt.Println("\n  Net change by category:") //nolint:forbidigo // User-facing output
+		for _, cat := range categories {
+			fmt.Printf("    %s: %+d\n", recategorizeLabel(cat), diff.NetByCategory[cat]) //nolint:forbidigo // User-facing output
+		}
+	}
+
+	if diff.Unchanged > 0 {
+		fmt.Printf("\n  %d transactions would keep their current category\n", diff.Unchanged) //nolint:forbidigo // User-facing output
+	}
+	if len(diff.FailedMerchants) > 0 {
+		fmt.Printf("  %d merchants could not be classified: %s\n", len(diff.FailedMerchants), strings.Join(diff.FailedMerchants, ", ")) //nolint:forbidigo // User-facing output
+	}
+}
+
+// recategorizeLabel returns a display name for a category, marking unclassified transactions.
+func recategorizeLabel(category string) string {
+	if category == "" {
+		return "(unclassified)"
+	}
+	return category
+}
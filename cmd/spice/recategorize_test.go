@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/engine"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildRecategorizeDiff(t *testing.T) {
+	previous := map[string]model.Classification{
+		"a1": {Category: "Shopping"},
+		"a2": {Category: "Groceries"},
+		"s1": {Category: "Shopping"},
+		"s2": {Category: "Shopping"},
+	}
+
+	results := []engine.BatchResult{
+		{
+			Merchant:     "Safeway",
+			Suggestion:   &model.CategoryRanking{Category: "Groceries", Score: 0.9},
+			Transactions: []model.Transaction{{ID: "s1", Amount: 30}, {ID: "s2", Amount: 20}},
+		},
+		{
+			Merchant:     "Amazon",
+			Suggestion:   &model.CategoryRanking{Category: "Groceries", Score: 0.6},
+			Transactions: []model.Transaction{{ID: "a1", Amount: 10}, {ID: "a2", Amount: 15}, {ID: "a3", Amount: 5}},
+		},
+		{
+			Merchant: "Broken",
+			Error:    errors.New("llm failure"),
+		},
+	}
+
+	diff := buildRecategorizeDiff(results, previous)
+
+	// Merchants are sorted by name and unchanged transactions are counted separately
+	require.Len(t, diff.Merchants, 2)
+	assert.Equal(t, "Amazon", diff.Merchants[0].Merchant)
+	assert.Len(t, diff.Merchants[0].Changes, 2)
+	assert.Equal(t, 1, diff.Merchants[0].Unchanged)
+	assert.Equal(t, "Safeway", diff.Merchants[1].Merchant)
+	assert.Equal(t, 0.9, diff.Merchants[1].Changes[0].Confidence)
+	assert.Equal(t, 1, diff.Unchanged)
+
+	require.Len(t, diff.Movements, 2)
+	assert.Equal(t, recategorizeMovement{From: "Shopping", To: "Groceries", Count: 3, Amount: 60}, diff.Movements[0])
+	assert.Equal(t, recategorizeMovement{From: "", To: "Groceries", Count: 1, Amount: 5}, diff.Movements[1])
+
+	assert.Equal(t, -3, diff.NetByCategory["Shopping"])
+	assert.Equal(t, 4, diff.NetByCategory["Groceries"])
+	assert.Equal(t, []string{"Broken"}, diff.FailedMerchants)
+}
+
+func TestWithoutUserModified(t *testing.T) {
+	ctx := context.Background()
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+	defer func() { _ = db.Close() }()
+
+	_, err = db.CreateCategoryWithType(ctx, "Groceries", "", model.CategoryTypeExpense)
+	require.NoError(t, err)
+	date := time.Date(2024, 3, 10, 12, 0, 0, 0, time.Local)
+	transactions := []model.Transaction{
+		{ID: "tx1", Hash: "hash1", Name: "KROGER", MerchantName: "Kroger", Amount: 40, Type: "DEBIT", Date: date, AccountID: "acc1"},
+		{ID: "tx2", Hash: "hash2", Name: "SAFEWAY", MerchantName: "Safeway", Amount: 10, Type: "DEBIT", Date: date, AccountID: "acc1"},
+		{ID: "tx3", Hash: "hash3", Name: "ALDI", MerchantName: "Aldi", Amount: 25, Type: "DEBIT", Date: date, AccountID: "acc1"},
+	}
+	require.NoError(t, db.SaveTransactions(ctx, transactions))
+	require.NoError(t, db.SaveClassification(ctx, &model.Classification{
+		Transaction: transactions[0], Category: "Groceries", Status: model.StatusClassifiedByAI, Confidence: 0.9,
+	}))
+	require.NoError(t, db.SaveClassification(ctx, &model.Classification{
+		Transaction: transactions[1], Category: "Groceries", Status: model.StatusUserModified, Confidence: 1.0,
+	}))
+
+	kept, skipped, err := withoutUserModified(ctx, db, transactions)
+	require.NoError(t, err)
+	assert.Equal(t, 1, skipped)
+	require.Len(t, kept, 2)
+	assert.Equal(t, "tx1", kept[0].ID)
+	assert.Equal(t, "tx3", kept[1].ID, "unclassified transactions are kept")
+}
+
+func TestRecategorizeBatchOptions(t *testing.T) {
+	viper.Set("classification.auto_accept_threshold", 0.8)
+	viper.Set("classification.parallel_workers", 4)
+	viper.Set("classification.category_retries", 2)
+	defer func() {
+		viper.Set("classification.auto_accept_threshold", nil)
+		viper.Set("classification.parallel_workers", nil)
+		viper.Set("classification.category_retries", nil)
+	}()
+
+	// The classification settings apply by default
+	cmd := recategorizeCmd()
+	require.NoError(t, cmd.ParseFlags([]string{"--from-category", "Shopping", "--yes"}))
+	opts, err := recategorizeBatchOptions(cmd.Flags(), 50)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.8, opts.AutoAcceptThreshold, 0.001)
+	assert.Equal(t, 4, opts.ParallelWorkers)
+	assert.Equal(t, 2, opts.CategoryRetries)
+	assert.Equal(t, 50, opts.BatchSize)
+
+	// Flags override them
+	cmd = recategorizeCmd()
+	require.NoError(t, cmd.ParseFlags([]string{"--auto-accept-threshold", "0.9", "--parallel-workers", "1"}))
+	opts, err = recategorizeBatchOptions(cmd.Flags(), 50)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.9, opts.AutoAcceptThreshold, 0.001)
+	assert.Equal(t, 1, opts.ParallelWorkers)
+
+	cmd = recategorizeCmd()
+	require.NoError(t, cmd.ParseFlags([]string{"--auto-accept-threshold", "1.5"}))
+	_, err = recategorizeBatchOptions(cmd.Flags(), 50)
+	assert.Error(t, err)
+}
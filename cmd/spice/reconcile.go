@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/cli"
+	"github.com/Veraticus/the-spice-must-flow/internal/engine"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func reconcileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reconcile",
+		Short: "Reconcile classifications against other sources",
+		Long:  `Compare your classifications with other sources of categories, such as the categories your bank assigns.`,
+	}
+
+	cmd.AddCommand(reconcileBankCategoriesCmd())
+
+	return cmd
+}
+
+func reconcileBankCategoriesCmd() *cobra.Command {
+	var (
+		seed   bool
+		dryRun bool
+		limit  int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "bank-categories",
+		Short: "Compare classifications with the bank's categories",
+		Long: `Compare your classifications with the category the bank assigned each transaction
+and report where they disagree.
+
+A bank category is mapped onto your categories through classification.bank_category_mapping
+in the config, keyed by any level of the bank's category path (the most specific level
+wins). Levels without a mapping match a category by name or alias.
+
+With --seed, unclassified transactions whose bank category maps to one of your categories
+are classified with it at low confidence, so 'spice classify --rerank' can revisit them.
+
+Examples:
+  # Report disagreements
+  spice reconcile bank-categories
+
+  # See what seeding would classify, then do it
+  spice reconcile bank-categories --seed --dry-run
+  spice reconcile bank-categories --seed`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			if dryRun && !seed {
+				return fmt.Errorf("--dry-run requires --seed")
+			}
+
+			store, err := initStorage(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+			defer func() {
+				if closeErr := store.Close(); closeErr != nil {
+					slog.Error("failed to close storage", "error", closeErr)
+				}
+			}()
+
+			categories, err := store.GetCategories(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get categories: %w", err)
+			}
+			mapping := viper.GetStringMapString("classification.bank_category_mapping")
+
+			classifications, err := store.GetClassificationsByDateRange(ctx, time.Time{}, time.Now().AddDate(100, 0, 0))
+			if err != nil {
+				return fmt.Errorf("failed to get classifications: %w", err)
+			}
+
+			report := engine.ReconcileBankCategories(classifications, categories, mapping)
+			if err := printBankCategoryReport(report, limit); err != nil {
+				return err
+			}
+
+			if !seed {
+				return nil
+			}
+
+			unclassified, err := store.GetTransactionsToClassify(ctx, nil)
+			if err != nil {
+				return fmt.Errorf("failed to get unclassified transactions: %w", err)
+			}
+
+			seeded := 0
+			for _, txn := range unclassified {
+				category, ok := engine.MapBankCategory(txn.Category, categories, mapping)
+				if !ok {
+					continue
+				}
+				seeded++
+				if dryRun {
+					continue
+				}
+
+				classification := &model.Classification{
+					Transaction:  txn,
+					Category:     category,
+					Status:       model.StatusClassifiedByRule,
+					Confidence:   engine.BankCategorySeedConfidence,
+					ClassifiedAt: time.Now(),
+					Notes:        "Seeded from bank category",
+				}
+				if err := store.SaveClassification(ctx, classification); err != nil {
+					return fmt.Errorf("failed to save classification for %s: %w", txn.ID, err)
+				}
+			}
+
+			if dryRun {
+				slog.Info(fmt.Sprintf("Would seed %d of %d unclassified transactions from their bank category", seeded, len(unclassified)))
+				return nil
+			}
+			slog.Info(fmt.Sprintf("✓ Seeded %d of %d unclassified transactions from their bank category", seeded, len(unclassified)))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&seed, "seed", false, "Classify unclassified transactions with the category their bank category maps to")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "With --seed, report what would be seeded without saving")
+	cmd.Flags().IntVar(&limit, "limit", 50, "Maximum number of disagreements to list (0 for all)")
+
+	return cmd
+}
+
+// printBankCategoryReport renders the reconciliation summary and disagreements.
+func printBankCategoryReport(report engine.BankCategoryReport, limit int) error {
+	compared := report.Agreements + len(report.Disagreements)
+
+	fmt.Println(cli.TitleStyle.Render("Bank Category Reconciliation"))      //nolint:forbidigo // User-facing output
+	fmt.Printf("  Agree: %d of %d compared\n", report.Agreements, compared) //nolint:forbidigo // User-facing output
+	fmt.Printf("  Disagree: %d\n", len(report.Disagreements))               //nolint:forbidigo // User-facing output
+	fmt.Printf("  Bank category not mapped: %d\n", report.Unmapped)         //nolint:forbidigo // User-facing output
+	fmt.Printf("  No bank category: %d\n", report.NoBankCategory)           //nolint:forbidigo // User-facing output
+
+	if len(report.Disagreements) == 0 {
+		if compared > 0 {
+			fmt.Println(cli.FormatSuccess("\n✓ Every compared classification matches its bank category")) //nolint:forbidigo // User-facing output
+		}
+		return nil
+	}
+
+	disagreements := report.Disagreements
+	if limit > 0 && len(disagreements) > limit {
+		disagreements = disagreements[:limit]
+	}
+
+	fmt.Println("\nDisagreements:") //nolint:forbidigo // User-facing output
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "  DATE\tMERCHANT\tAMOUNT\tCLASSIFIED\tBANK\tMAPS TO")
+	for _, d := range disagreements {
+		txn := d.Classification.Transaction
+		merchant := txn.MerchantName
+		if merchant == "" {
+			merchant = txn.Name
+		}
+		_, _ = fmt.Fprintf(w, "  %s\t%s\t$%.2f\t%s\t%s\t%s\n",
+			txn.Date.Format("2006-01-02"), merchant, txn.Amount, d.Classification.Category, d.BankCategory, d.Mapped)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(disagreements) < len(report.Disagreements) {
+		fmt.Printf("  ... and %d more (use --limit 0 to list all)\n", len(report.Disagreements)-len(disagreements)) //nolint:forbidigo // User-facing output
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/cli"
+	"github.com/Veraticus/the-spice-must-flow/internal/engine"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func recurringCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "recurring",
+		Short: "List recurring charges and their price changes",
+		Long: `List merchants that charge you about once a month, like subscriptions, with
+their current price.
+
+A merchant counts as recurring when it charged at most once a month, in most
+months of the lookback window, at a steady price. A price is steady once it
+was charged twice in a row; only the latest price may have been charged once,
+so a fresh price increase shows up right away. Refunds are not charges.
+"spice forecast" uses the same detection and projects recurring merchants at
+their current price.
+
+With --changes, list every price change instead: the old and new amount and
+the date of the first charge at the new amount. A price change may also
+change how much of a charge is deductible. Nothing is changed in the database.
+
+Examples:
+  # Recurring charges over the last 12 months
+  spice recurring
+
+  # Price changes, treating charges within 1% as the same price
+  spice recurring --changes --tolerance 1`,
+		Args: cobra.NoArgs,
+		RunE: runRecurring,
+	}
+
+	cmd.Flags().Bool("changes", false, "List price changes instead of recurring charges")
+	cmd.Flags().Int("lookback", engine.DefaultRecurringLookbackMonths, "Number of months of history to search")
+	cmd.Flags().Float64("tolerance", 0, "Percent two charges may differ and still be the same price (0 = exact)")
+
+	_ = viper.BindPFlag("recurring.lookback", cmd.Flags().Lookup("lookback"))
+	_ = viper.BindPFlag("recurring.price_tolerance", cmd.Flags().Lookup("tolerance"))
+
+	return cmd
+}
+
+func runRecurring(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+	changesOnly, _ := cmd.Flags().GetBool("changes")
+	lookback := viper.GetInt("recurring.lookback")
+	tolerance := viper.GetFloat64("recurring.price_tolerance")
+
+	if lookback <= 0 {
+		return fmt.Errorf("lookback must be a positive number of months")
+	}
+	if tolerance < 0 || tolerance >= 100 {
+		return fmt.Errorf("--tolerance must be a percentage from 0 up to 100")
+	}
+
+	store, err := initReadOnlyStorage(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer func() {
+		if closeErr := store.Close(); closeErr != nil {
+			slog.Error("Failed to close storage", "error", closeErr)
+		}
+	}()
+
+	series, err := engine.DetectRecurring(ctx, store, engine.RecurringOptions{
+		LookbackMonths: lookback,
+		Tolerance:      tolerance / 100,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to detect recurring charges: %w", err)
+	}
+
+	if changesOnly {
+		slog.Info(cli.RenderBox("Recurring Price Changes", formatPriceChanges(series, lookback)))
+		return nil
+	}
+	slog.Info(cli.RenderBox("Recurring Charges", formatRecurring(series, lookback)))
+	return nil
+}
+
+func formatRecurring(series []engine.RecurringSeries, lookback int) string {
+	if len(series) == 0 {
+		return fmt.Sprintf("No recurring charges in the last %d months.", lookback)
+	}
+
+	content := fmt.Sprintf("  %-28s %-20s %10s %8s  %-10s %s", "Merchant", "Category", "Amount", "Charges", "Last", "Changes")
+	for _, s := range series {
+		content += fmt.Sprintf("\n  %-28s %-20s $%9.2f %8d  %-10s %d",
+			truncateString(s.Merchant, 28), truncateString(s.Category, 20), s.Amount, s.Charges, s.Last.Format("2006-01-02"), len(s.Changes))
+	}
+	return content
+}
+
+func formatPriceChanges(series []engine.RecurringSeries, lookback int) string {
+	content := fmt.Sprintf("  %-28s %-20s %10s %10s %8s  %s", "Merchant", "Category", "Old", "New", "Change", "Effective")
+	var changes int
+	for _, s := range series {
+		for _, change := range s.Changes {
+			content += fmt.Sprintf("\n  %-28s %-20s $%9.2f $%9.2f %+7.1f%%  %s",
+				truncateString(s.Merchant, 28), truncateString(s.Category, 20), change.OldAmount, change.NewAmount, change.Percent(), change.Date.Format("2006-01-02"))
+			changes++
+		}
+	}
+
+	if changes == 0 {
+		return fmt.Sprintf("No price changes in %d recurring charges over the last %d months.", len(series), lookback)
+	}
+	return content
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/cli"
+	"github.com/Veraticus/the-spice-must-flow/internal/engine"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func reportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Reports about the state of your data",
+		Long: `Read-only reports that describe your transactions and how well they are categorized.
+
+'spice report flow' is the spending flow report, the same as 'spice flow';
+use 'spice report flow --read-only' to be sure the report changes nothing.`,
+	}
+
+	cmd.AddCommand(flowCmd())
+	cmd.AddCommand(reportCoverageCmd())
+	cmd.AddCommand(reportPaychecksCmd())
+
+	return cmd
+}
+
+func reportCoverageCmd() *cobra.Command {
+	var year, top int
+
+	cmd := &cobra.Command{
+		Use:   "coverage",
+		Short: "Show how many transactions are still unclassified, and where",
+		Long: `Show how complete your categorization is: classified versus unclassified
+transactions, coverage per month, and the merchants with the most
+unclassified transactions.
+
+Unclassified transactions are split by reason:
+  pending  never classified; the next 'spice classify' will pick them up
+  skipped  skipped during review and left without a category
+
+Transactions that 'spice classify --escalate-below' filed under the escalate
+category count as classified but are listed separately, since they still need
+a human to pick their real category. So do transactions in the catch-all
+category (classification.catch_all_category, "Other" by default): a large
+count there suggests more specific categories are worth creating.
+The fallback categories (classification.fallback_expense_category and
+classification.fallback_income_category), when set, are listed the same way.
+
+Examples:
+  # Coverage across all transactions
+  spice report coverage
+
+  # Coverage for 2024, listing the 20 worst merchants
+  spice report coverage --year 2024 --top 20`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			if top <= 0 {
+				return fmt.Errorf("--top must be a positive number")
+			}
+
+			var start, end time.Time
+			if year != 0 {
+				start = time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+				end = time.Date(year, 12, 31, 23, 59, 59, 0, time.UTC)
+			}
+
+			store, err := initReadOnlyStorage(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+			defer func() {
+				if closeErr := store.Close(); closeErr != nil {
+					slog.Error("failed to close storage", "error", closeErr)
+				}
+			}()
+
+			months, err := store.GetClassificationCoverage(ctx, start, end)
+			if err != nil {
+				return fmt.Errorf("failed to get coverage: %w", err)
+			}
+			merchants, err := store.GetUnclassifiedMerchants(ctx, start, end, top)
+			if err != nil {
+				return fmt.Errorf("failed to get unclassified merchants: %w", err)
+			}
+
+			flagged := []coverageCategory{
+				{Label: "Escalated", Category: viper.GetString("classification.escalate_category"), Note: "waiting for a human"},
+				{Label: "Catch-all", Category: viper.GetString("classification.catch_all_category"), Note: "worth more specific categories"},
+			}
+			if flagged[0].Category == "" {
+				flagged[0].Category = engine.DefaultEscalateCategory
+			}
+			if flagged[1].Category == "" {
+				flagged[1].Category = engine.DefaultCatchAllCategory
+			}
+			for _, key := range []string{"classification.fallback_expense_category", "classification.fallback_income_category"} {
+				if category := strings.TrimSpace(viper.GetString(key)); category != "" {
+					flagged = append(flagged, coverageCategory{Label: "Fallback", Category: category, Note: "failed to classify"})
+				}
+			}
+			for i := range flagged {
+				query := model.TransactionQuery{Category: flagged[i].Category}
+				if year != 0 {
+					query.StartDate, query.EndDate = &start, &end
+				}
+				transactions, err := store.QueryTransactions(ctx, query)
+				if err != nil {
+					return fmt.Errorf("failed to get %s transactions: %w", strings.ToLower(flagged[i].Label), err)
+				}
+				flagged[i].Count = len(transactions)
+			}
+
+			content := formatCoverageContent(months, merchants, flagged)
+			fmt.Println(cli.RenderBox("Classification Coverage", content)) //nolint:forbidigo // User-facing output
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&year, "year", "y", 0, "Only include transactions from this year")
+	cmd.Flags().IntVar(&top, "top", 10, "Number of unclassified merchants to list")
+
+	return cmd
+}
+
+// coverageCategory is a category whose transactions count as classified but
+// are called out in the coverage report.
+type coverageCategory struct {
+	Label    string
+	Category string
+	Note     string
+	Count    int
+}
+
+func formatCoverageContent(months []model.CoverageMonth, merchants []model.UnclassifiedMerchant, flagged []coverageCategory) string {
+	var total model.CoverageMonth
+	for _, m := range months {
+		total.Total += m.Total
+		total.Classified += m.Classified
+		total.Pending += m.Pending
+		total.Skipped += m.Skipped
+	}
+	if total.Total == 0 {
+		return "No transactions found.\nRun 'spice import' to add some first."
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "  %-14s %8d\n", "Transactions", total.Total)
+	fmt.Fprintf(&b, "  %-14s %8d  (%.1f%%)\n", "Classified", total.Classified, total.Percent())
+	fmt.Fprintf(&b, "  %-14s %8d  (pending %d, skipped %d)\n", "Unclassified", total.Unclassified(), total.Pending, total.Skipped)
+	for _, f := range flagged {
+		if f.Count > 0 {
+			fmt.Fprintf(&b, "  %-14s %8d  (in %q, %s)\n", f.Label, f.Count, f.Category, f.Note)
+		}
+	}
+
+	percents := make([]float64, len(months))
+	for i, m := range months {
+		percents[i] = m.Percent()
+	}
+	fmt.Fprintf(&b, "\nMonthly coverage  %s\n", cli.Sparkline(percents))
+	fmt.Fprintf(&b, "  %-8s %8s %8s %8s %8s", "Month", "Total", "Pending", "Skipped", "Coverage")
+	for _, m := range months {
+		fmt.Fprintf(&b, "\n  %-8s %8d %8d %8d %7.1f%%", m.Month, m.Total, m.Pending, m.Skipped, m.Percent())
+	}
+
+	if len(merchants) == 0 {
+		b.WriteString("\n\nEvery transaction is classified.")
+		return b.String()
+	}
+
+	b.WriteString("\n\nTop unclassified merchants\n")
+	fmt.Fprintf(&b, "  %-28s %6s %8s %8s %12s", "Merchant", "Count", "Pending", "Skipped", "Amount")
+	for _, m := range merchants {
+		fmt.Fprintf(&b, "\n  %-28s %6d %8d %8d $%11.2f",
+			truncateString(m.Merchant, 28), m.Count, m.Pending, m.Skipped, m.Amount)
+	}
+
+	return b.String()
+}
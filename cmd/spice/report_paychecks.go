@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/cli"
+	"github.com/Veraticus/the-spice-must-flow/internal/engine"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func reportPaychecksCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "paychecks",
+		Short: "Combine paychecks split across accounts",
+		Long: `Show your paychecks as whole paychecks, even when each one is split across
+accounts and arrives as several income transactions.
+
+Income from the same source on the same day is one paycheck. Sources are
+regular expressions, matched case-insensitively against the merchant name or
+description, set with --source or report.paychecks.sources. All income from
+a matching source counts as a paycheck, split or not. Without sources, only
+income deposited into more than one account on the same day counts.
+
+The summary shows each source's total pay next to how it was split across
+accounts. With --splits, every paycheck is listed with its total and the
+deposit into each account. The total is what reached your accounts, so it is
+your net pay. Nothing is changed in the database.
+
+Examples:
+  # Paychecks split across accounts this year
+  spice report paychecks
+
+  # Every paycheck from your employer in 2024, with its deposits
+  spice report paychecks --source "acme corp|acme payroll" --year 2024 --splits`,
+		Args: cobra.NoArgs,
+		RunE: runReportPaychecks,
+	}
+
+	cmd.Flags().IntP("year", "y", time.Now().Year(), "Year to report on (0 for all years)")
+	cmd.Flags().StringSlice("source", nil, "Regular expression matching a paycheck source (repeatable)")
+	cmd.Flags().Bool("splits", false, "List each paycheck with its deposit into every account")
+
+	_ = viper.BindPFlag("report.paychecks.sources", cmd.Flags().Lookup("source"))
+
+	return cmd
+}
+
+func runReportPaychecks(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+	year, _ := cmd.Flags().GetInt("year")
+	splits, _ := cmd.Flags().GetBool("splits")
+	sources := viper.GetStringSlice("report.paychecks.sources")
+
+	opts := engine.PaycheckOptions{Sources: sources}
+	if year != 0 {
+		opts.Start = time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+		opts.End = time.Date(year, 12, 31, 23, 59, 59, 0, time.UTC)
+	}
+
+	store, err := initReadOnlyStorage(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer func() {
+		if closeErr := store.Close(); closeErr != nil {
+			slog.Error("failed to close storage", "error", closeErr)
+		}
+	}()
+
+	paychecks, err := engine.DetectPaychecks(ctx, store, opts)
+	if err != nil {
+		return fmt.Errorf("failed to detect paychecks: %w", err)
+	}
+
+	content := formatPaycheckSources(engine.SummarizePaychecks(paychecks), len(sources) > 0)
+	if splits && len(paychecks) > 0 {
+		content += "\n\n" + formatPaycheckSplits(paychecks)
+	}
+	fmt.Println(cli.RenderBox("Paychecks", content)) //nolint:forbidigo // User-facing output
+	return nil
+}
+
+func formatPaycheckSources(sources []engine.PaycheckSource, matched bool) string {
+	if len(sources) == 0 {
+		if matched {
+			return "No income from the paycheck sources."
+		}
+		return "No income split across accounts. Use --source to name your paycheck sources."
+	}
+
+	content := fmt.Sprintf("  %-28s %9s %12s  %s", "Source", "Paychecks", "Total", "Split")
+	for _, source := range sources {
+		shares := make([]string, 0, len(source.Accounts))
+		for _, account := range source.Accounts {
+			shares = append(shares, fmt.Sprintf("%s %.0f%%", account.AccountID, source.Share(account)*100))
+		}
+		content += fmt.Sprintf("\n  %-28s %9d $%11.2f  %s",
+			truncateString(source.Source, 28), source.Paychecks, source.Amount, strings.Join(shares, ", "))
+	}
+	return content
+}
+
+func formatPaycheckSplits(paychecks []engine.Paycheck) string {
+	content := fmt.Sprintf("  %-10s  %-28s %12s  %s", "Date", "Source", "Total", "Deposits")
+	for _, paycheck := range paychecks {
+		deposits := make([]string, 0, len(paycheck.Deposits))
+		for _, deposit := range paycheck.Deposits {
+			deposits = append(deposits, fmt.Sprintf("%s $%.2f", deposit.AccountID, deposit.Amount))
+		}
+		content += fmt.Sprintf("\n  %-10s  %-28s $%11.2f  %s",
+			paycheck.Date.Format("2006-01-02"), truncateString(paycheck.Source, 28), paycheck.Amount, strings.Join(deposits, ", "))
+	}
+	return content
+}
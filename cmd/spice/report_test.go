@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatCoverageContent(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		assert.Contains(t, formatCoverageContent(nil, nil, nil), "No transactions found")
+	})
+
+	t.Run("fully classified", func(t *testing.T) {
+		content := formatCoverageContent([]model.CoverageMonth{{Month: "2024-01", Total: 4, Classified: 4}}, nil,
+			[]coverageCategory{{Label: "Escalated", Category: "Escalate", Note: "waiting for a human"}})
+		assert.Contains(t, content, "(100.0%)")
+		assert.Contains(t, content, "Every transaction is classified.")
+		assert.NotContains(t, content, "Escalated")
+	})
+
+	t.Run("gaps", func(t *testing.T) {
+		content := formatCoverageContent(
+			[]model.CoverageMonth{
+				{Month: "2024-01", Total: 4, Classified: 3, Pending: 1},
+				{Month: "2024-02", Total: 4, Classified: 1, Pending: 2, Skipped: 1},
+			},
+			[]model.UnclassifiedMerchant{{Merchant: "Mystery Shop", Count: 3, Pending: 2, Skipped: 1, Amount: 42.5}},
+			[]coverageCategory{
+				{Label: "Escalated", Category: "Escalate", Note: "waiting for a human", Count: 2},
+				{Label: "Catch-all", Category: "Other", Note: "worth more specific categories", Count: 1},
+			},
+		)
+		assert.Contains(t, content, "(50.0%)")
+		assert.Contains(t, content, "(pending 3, skipped 1)")
+		assert.Contains(t, content, `Escalated             2  (in "Escalate", waiting for a human)`)
+		assert.Contains(t, content, `Catch-all             1  (in "Other", worth more specific categories)`)
+		assert.Contains(t, content, "2024-02         4        2        1    25.0%")
+		assert.Contains(t, content, "Mystery Shop")
+		assert.Contains(t, content, "$      42.50")
+	})
+}
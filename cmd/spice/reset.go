@@ -8,7 +8,7 @@ import (
 	"time"
 
 	"github.com/Veraticus/the-spice-must-flow/internal/model"
-	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
 	"github.com/spf13/cobra"
 )
 
@@ -120,7 +120,7 @@ func runReset(_ *cobra.Command, _ []string) error {
 	return nil
 }
 
-func getClassificationCount(ctx context.Context, store *storage.SQLiteStorage) (int, error) {
+func getClassificationCount(ctx context.Context, store service.Storage) (int, error) {
 	// Get all classifications using a wide date range
 	startDate := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
 	endDate := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
@@ -140,7 +140,7 @@ func getClassificationCount(ctx context.Context, store *storage.SQLiteStorage) (
 	return count, nil
 }
 
-func getVendorCount(_ context.Context, _ *storage.SQLiteStorage) (int, error) {
+func getVendorCount(_ context.Context, _ service.Storage) (int, error) {
 	// Get all vendors by using GetVendors method if available,
 	// or count them indirectly through transactions
 	// For now, we'll return -1 to indicate vendor count is not available
@@ -148,12 +148,12 @@ func getVendorCount(_ context.Context, _ *storage.SQLiteStorage) (int, error) {
 	return -1, nil
 }
 
-func clearClassifications(ctx context.Context, store *storage.SQLiteStorage) error {
+func clearClassifications(ctx context.Context, store service.Storage) error {
 	// Use the built-in method to clear all classifications
 	return store.ClearAllClassifications(ctx)
 }
 
-func clearVendors(ctx context.Context, store *storage.SQLiteStorage) error {
+func clearVendors(ctx context.Context, store service.Storage) error {
 	// Delete all vendor rules by source
 	// First delete auto-generated vendors
 	if err := store.DeleteVendorsBySource(ctx, model.SourceAuto); err != nil {
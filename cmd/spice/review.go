@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/cli"
+	"github.com/Veraticus/the-spice-must-flow/internal/common"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
+	"github.com/spf13/cobra"
+)
+
+func reviewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "review",
+		Short: "Work with the review queue offline",
+		Long: `Classify the merchants that need review in a spreadsheet instead of
+interactively.
+
+Export the review queue with 'spice classify --review-export review.csv', fill
+in the my_category column in Excel or any spreadsheet app, and apply your
+choices with 'spice review import review.csv'.`,
+	}
+
+	cmd.AddCommand(reviewImportCmd())
+
+	return cmd
+}
+
+func reviewImportCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Apply categories chosen in an exported review queue",
+		Long: `Apply the categories entered in the my_category column of a review queue
+exported by 'spice classify --review-export'. Each category is applied to all
+of its merchant's transactions as a manual classification. Rows with an empty
+my_category are left for a later review.
+
+Every category must already exist; if any row names an unknown category,
+nothing is applied.
+
+Examples:
+  # Check the file without saving anything
+  spice review import reviewed.csv --dry-run
+
+  # Apply the categories
+  spice review import reviewed.csv`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			file, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open review file: %w", err)
+			}
+			defer func() { _ = file.Close() }()
+
+			rows, err := cli.ReadReviewCSV(file)
+			if err != nil {
+				return err
+			}
+
+			db, cleanup, err := getDatabase()
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			result, err := applyReviewRows(ctx, db, rows, dryRun)
+			if err != nil {
+				return err
+			}
+
+			verb := "Applied"
+			if dryRun {
+				verb = "Would apply"
+			}
+			slog.Info(fmt.Sprintf("✓ %s categories to %d merchants (%d transactions)", verb, result.Merchants, result.Transactions))
+			if result.Undecided > 0 {
+				slog.Info(fmt.Sprintf("%d merchants have no category yet and were left for review", result.Undecided))
+			}
+			if result.Missing > 0 {
+				slog.Warn(fmt.Sprintf("%d transactions in the file no longer exist and were skipped", result.Missing))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Validate the file and show counts without saving")
+
+	return cmd
+}
+
+// reviewImportResult counts what an import applied.
+type reviewImportResult struct {
+	Merchants    int // Rows whose category was applied
+	Transactions int // Transactions classified
+	Undecided    int // Rows left without a category
+	Missing      int // Transaction IDs not found in the database
+}
+
+// applyReviewRows saves each row's chosen category for its transactions as a
+// manual classification. Categories are validated up front, so an unknown
+// category fails the import before anything is saved.
+func applyReviewRows(ctx context.Context, store service.Storage, rows []cli.ReviewRow, dryRun bool) (reviewImportResult, error) {
+	var result reviewImportResult
+
+	categories, err := store.GetCategories(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to get categories: %w", err)
+	}
+	byName := make(map[string]string, len(categories))
+	for _, category := range categories {
+		byName[strings.ToLower(category.Name)] = category.Name
+	}
+
+	var unknown []string
+	for i := range rows {
+		if rows[i].MyCategory == "" {
+			continue
+		}
+		name, ok := byName[strings.ToLower(rows[i].MyCategory)]
+		if !ok {
+			unknown = append(unknown, fmt.Sprintf("%q (%s)", rows[i].MyCategory, rows[i].Merchant))
+			continue
+		}
+		rows[i].MyCategory = name
+	}
+	if len(unknown) > 0 {
+		return result, fmt.Errorf("unknown categories, nothing was applied: %s", strings.Join(unknown, ", "))
+	}
+
+	for _, row := range rows {
+		if row.MyCategory == "" {
+			result.Undecided++
+			continue
+		}
+
+		applied := 0
+		for _, id := range row.TransactionIDs {
+			txn, err := store.GetTransactionByID(ctx, id)
+			if errors.Is(err, common.ErrNotFound) {
+				result.Missing++
+				continue
+			}
+			if err != nil {
+				return result, fmt.Errorf("failed to get transaction %s: %w", id, err)
+			}
+			applied++
+			if dryRun {
+				continue
+			}
+
+			classification := model.Classification{
+				Transaction:  *txn,
+				Category:     row.MyCategory,
+				Status:       model.StatusUserModified,
+				Confidence:   1.0,
+				ClassifiedAt: time.Now(),
+			}
+			if err := store.SaveClassification(ctx, &classification); err != nil {
+				return result, fmt.Errorf("failed to save classification for transaction %s: %w", id, err)
+			}
+		}
+
+		if applied > 0 {
+			result.Merchants++
+			result.Transactions += applied
+		}
+	}
+
+	return result, nil
+}
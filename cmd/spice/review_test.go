@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/cli"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyReviewRows(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	defer func() {
+		if closeErr := store.Close(); closeErr != nil {
+			t.Logf("Failed to close store: %v", closeErr)
+		}
+	}()
+	require.NoError(t, store.Migrate(ctx))
+
+	_, err = store.CreateCategory(ctx, "Hosting", "Web hosting")
+	require.NoError(t, err)
+	require.NoError(t, store.SaveTransactions(ctx, []model.Transaction{
+		{ID: "t1", Hash: "h1", Date: time.Now(), Name: "ACME LLC", MerchantName: "ACME LLC", Amount: 20, AccountID: "acc"},
+		{ID: "t2", Hash: "h2", Date: time.Now(), Name: "ACME LLC", MerchantName: "ACME LLC", Amount: 25, AccountID: "acc"},
+		{ID: "t3", Hash: "h3", Date: time.Now(), Name: "Corner Shop", MerchantName: "Corner Shop", Amount: 5, AccountID: "acc"},
+	}))
+
+	manual := func() map[string]string {
+		classifications, err := store.QueryTransactions(ctx, model.TransactionQuery{Status: model.StatusUserModified})
+		require.NoError(t, err)
+		categories := make(map[string]string, len(classifications))
+		for _, c := range classifications {
+			categories[c.Transaction.ID] = c.Category
+		}
+		return categories
+	}
+
+	rows := func() []cli.ReviewRow {
+		return []cli.ReviewRow{
+			{Merchant: "ACME LLC", MyCategory: "hosting", TransactionIDs: []string{"t1", "t2", "gone"}},
+			{Merchant: "Corner Shop", TransactionIDs: []string{"t3"}},
+		}
+	}
+
+	t.Run("unknown category applies nothing", func(t *testing.T) {
+		bad := rows()
+		bad[1].MyCategory = "Snacks"
+		_, err := applyReviewRows(ctx, store, bad, false)
+		require.ErrorContains(t, err, `"Snacks" (Corner Shop)`)
+		assert.Empty(t, manual())
+	})
+
+	t.Run("dry run only counts", func(t *testing.T) {
+		result, err := applyReviewRows(ctx, store, rows(), true)
+		require.NoError(t, err)
+		assert.Equal(t, reviewImportResult{Merchants: 1, Transactions: 2, Undecided: 1, Missing: 1}, result)
+		assert.Empty(t, manual())
+	})
+
+	t.Run("applies categories as manual classifications", func(t *testing.T) {
+		result, err := applyReviewRows(ctx, store, rows(), false)
+		require.NoError(t, err)
+		assert.Equal(t, reviewImportResult{Merchants: 1, Transactions: 2, Undecided: 1, Missing: 1}, result)
+
+		assert.Equal(t, map[string]string{"t1": "Hosting", "t2": "Hosting"}, manual())
+	})
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/cli"
+	"github.com/Veraticus/the-spice-must-flow/internal/rulepack"
+	"github.com/spf13/cobra"
+)
+
+func rulesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rules",
+		Short: "Share categories and classification rules as a single pack",
+		Long: `Bundle categories, vendor rules, pattern rules and check patterns into one
+JSON file, or load such a file into the database. Useful for setting up a new
+machine or sharing a curated starter pack.`,
+	}
+
+	cmd.AddCommand(rulesExportCmd())
+	cmd.AddCommand(rulesImportCmd())
+
+	return cmd
+}
+
+func rulesExportCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "export <pack.json>",
+		Short: "Write all categories and rules to a pack file",
+		Long: `Write all active categories, vendor rules, pattern rules and check patterns
+to a pack file. Use "-" to write to standard output.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			store, err := initReadOnlyStorage(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+			defer func() {
+				if closeErr := store.Close(); closeErr != nil {
+					slog.Error("failed to close storage", "error", closeErr)
+				}
+			}()
+
+			pack, err := rulepack.Export(ctx, store)
+			if err != nil {
+				return err
+			}
+
+			if args[0] == "-" {
+				return pack.Write(cmd.OutOrStdout())
+			}
+
+			file, err := os.Create(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", args[0], err)
+			}
+			if err := pack.Write(file); err != nil {
+				_ = file.Close()
+				return err
+			}
+			if err := file.Close(); err != nil {
+				return fmt.Errorf("failed to write %s: %w", args[0], err)
+			}
+
+			slog.Info(cli.FormatSuccess(fmt.Sprintf(
+				"Exported %d categories, %d vendor rules, %d pattern rules and %d check patterns to %s",
+				len(pack.Categories), len(pack.Vendors), len(pack.PatternRules), len(pack.CheckPatterns), args[0])))
+			return nil
+		},
+	}
+}
+
+func rulesImportCmd() *cobra.Command {
+	var replace, dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "import <pack.json>",
+		Short: "Load categories and rules from a pack file",
+		Long: `Load categories, vendor rules, pattern rules and check patterns from a pack.
+
+The whole pack is validated before anything is written: regexes must compile,
+rules must reference a category from the pack or the database, and names
+must be unique. The import runs in one transaction, so if any of it fails
+the database is left as it was.
+
+By default the pack is merged: missing categories and rules are added and
+anything that already exists is left alone. With --replace, existing vendor
+rules, pattern rules (inactive ones too) and check patterns are removed
+first and existing
+categories take the pack's description and business percentage. Categories
+are never deleted because classifications reference them.
+
+Examples:
+  # Check a pack without changing anything
+  spice rules import starter.json --dry-run
+
+  # Add a shared starter pack to your rules
+  spice rules import starter.json
+
+  # Make this machine's rules match a pack exactly
+  spice rules import pack.json --replace`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			pack, err := readRulesPack(args[0], cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+
+			store, err := initStorage(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+			defer func() {
+				if closeErr := store.Close(); closeErr != nil {
+					slog.Error("failed to close storage", "error", closeErr)
+				}
+			}()
+
+			if dryRun {
+				existing, err := store.GetCategories(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to get categories: %w", err)
+				}
+				if err := pack.Validate(existing); err != nil {
+					return err
+				}
+				slog.Info(cli.FormatSuccess(fmt.Sprintf(
+					"Pack is valid: %d categories, %d vendor rules, %d pattern rules, %d check patterns",
+					len(pack.Categories), len(pack.Vendors), len(pack.PatternRules), len(pack.CheckPatterns))))
+				return nil
+			}
+
+			mode := rulepack.ModeMerge
+			if replace {
+				mode = rulepack.ModeReplace
+			}
+
+			result, err := rulepack.Import(ctx, store, pack, mode)
+			if err != nil {
+				return fmt.Errorf("failed to import rules pack: %w", err)
+			}
+
+			writeRulesImportResult(cmd.OutOrStdout(), result, mode)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&replace, "replace", false, "Replace existing rules instead of merging")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Validate the pack without changing the database")
+
+	return cmd
+}
+
+// readRulesPack reads a pack from path, or from stdin when path is "-".
+func readRulesPack(path string, stdin io.Reader) (*rulepack.Pack, error) {
+	if path == "-" {
+		return rulepack.Read(stdin)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	return rulepack.Read(file)
+}
+
+func writeRulesImportResult(w io.Writer, result *rulepack.ImportResult, mode rulepack.Mode) {
+	_, _ = fmt.Fprintf(w, "Imported rules pack (%s):\n", mode)
+	_, _ = fmt.Fprintf(w, "  Categories created: %d\n", result.CategoriesCreated)
+	if mode == rulepack.ModeReplace {
+		_, _ = fmt.Fprintf(w, "  Categories updated: %d\n", result.CategoriesUpdated)
+		_, _ = fmt.Fprintf(w, "  Existing rules removed: %d\n", result.Removed)
+	}
+	_, _ = fmt.Fprintf(w, "  Vendor rules:       %d\n", result.VendorsImported)
+	_, _ = fmt.Fprintf(w, "  Pattern rules:      %d\n", result.PatternRulesImported)
+	_, _ = fmt.Fprintf(w, "  Check patterns:     %d\n", result.CheckPatternsImported)
+	if result.Skipped > 0 {
+		_, _ = fmt.Fprintf(w, "  Already present:    %d\n", result.Skipped)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/cli"
+	"github.com/Veraticus/the-spice-must-flow/internal/engine"
+	"github.com/Veraticus/the-spice-must-flow/internal/server"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func serveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the classifier over an HTTP API",
+		Long: `Expose spice's classifier to other tools over a small HTTP API.
+
+Every request must send the configured token as "Authorization: Bearer <token>".
+Set it with serve.token in the config file or the SPICE_API_TOKEN environment
+variable; the server refuses to start without one. Classification requests
+share the LLM rate limit configured under llm.rate_limit.
+
+Endpoints:
+  POST /classify    Rank categories for a transaction
+  GET  /categories  List categories
+
+Examples:
+  # Listen on the default local address
+  SPICE_API_TOKEN=secret spice serve
+
+  # Classify a transaction
+  curl -H "Authorization: Bearer secret" -d '{"merchant_name":"Whole Foods","amount":85.20,"direction":"expense"}' \
+    http://127.0.0.1:8080/classify`,
+		RunE: runServe,
+	}
+
+	cmd.Flags().String("addr", "127.0.0.1:8080", "Address to listen on")
+	_ = viper.BindPFlag("serve.addr", cmd.Flags().Lookup("addr"))
+
+	return cmd
+}
+
+func runServe(cmd *cobra.Command, _ []string) error {
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	token := viper.GetString("serve.token")
+	if token == "" {
+		token = os.Getenv("SPICE_API_TOKEN")
+	}
+	if token == "" {
+		return fmt.Errorf("an api token is required: set serve.token or SPICE_API_TOKEN")
+	}
+
+	store, err := initReadOnlyStorage(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer func() {
+		if closeErr := store.Close(); closeErr != nil {
+			slog.Error("failed to close storage", "error", closeErr)
+		}
+	}()
+
+	classifier, err := createLLMClient()
+	if err != nil {
+		return fmt.Errorf("failed to initialize LLM: %w", err)
+	}
+	if closer, ok := classifier.(interface{ Close() error }); ok {
+		defer func() {
+			if closeErr := closer.Close(); closeErr != nil {
+				slog.Error("failed to close LLM client", "error", closeErr)
+			}
+		}()
+	}
+
+	srv, err := server.New(engine.New(store, classifier, nil), token)
+	if err != nil {
+		return err
+	}
+
+	addr := viper.GetString("serve.addr")
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	fmt.Println(cli.InfoStyle.Render(fmt.Sprintf("Serving classification API on http://%s (Ctrl+C to stop)", addr))) //nolint:forbidigo // User-facing output
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("server failed: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down server: %w", err)
+	}
+	slog.Info("Classification API stopped")
+	return nil
+}
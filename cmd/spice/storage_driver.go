@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/spf13/viper"
+)
+
+// configurableStorage is storage whose vendor matching and protection of user
+// classifications can be configured, as both SQLite and PostgreSQL storage can.
+type configurableStorage interface {
+	service.Storage
+	SetVendorCaseSensitive(caseSensitive bool)
+	SetRegexVendorPrecedence(precedence model.RegexVendorPrecedence) error
+	SetOverwriteUserModified(overwrite bool)
+}
+
+// usePostgres reports whether storage.driver selects PostgreSQL instead of
+// the default SQLite.
+func usePostgres() (bool, error) {
+	switch driver := viper.GetString("storage.driver"); driver {
+	case "", "sqlite":
+		return false, nil
+	case "postgres":
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown storage.driver %q (use sqlite or postgres)", driver)
+	}
+}
+
+// openStorage opens the backend selected by storage.driver: the SQLite
+// database at dbPath, or the PostgreSQL database at storage.dsn. Vendor
+// matching is configured; migrations are left to the caller.
+func openStorage(dbPath string) (configurableStorage, error) {
+	postgres, err := usePostgres()
+	if err != nil {
+		return nil, err
+	}
+
+	var store configurableStorage
+	if postgres {
+		dsn := viper.GetString("storage.dsn")
+		if dsn == "" {
+			return nil, fmt.Errorf("storage.dsn is required when storage.driver is postgres")
+		}
+		store, err = storage.NewPostgresStorage(dsn)
+	} else {
+		store, err = storage.NewSQLiteStorage(dbPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := configureVendorMatching(store); err != nil {
+		_ = store.Close()
+		return nil, err
+	}
+	return store, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setStorageConfig points storage.* at the given settings for one test.
+func setStorageConfig(t *testing.T, settings map[string]string) {
+	t.Helper()
+	for key, value := range settings {
+		viper.Set(key, value)
+	}
+	t.Cleanup(func() {
+		for key := range settings {
+			viper.Set(key, nil)
+		}
+	})
+}
+
+func TestSaveImportedTransactions_StorageDriver(t *testing.T) {
+	ctx := context.Background()
+	transactions := []model.Transaction{{
+		ID: "txn1", Hash: "hash1", Date: time.Now(), Name: "Coffee Shop", MerchantName: "Coffee Shop",
+		Amount: 4.5, AccountID: "acc1", Direction: model.DirectionExpense,
+	}}
+
+	t.Run("sqlite", func(t *testing.T) {
+		dbPath := filepath.Join(t.TempDir(), "spice.db")
+		setStorageConfig(t, map[string]string{"storage.driver": "sqlite", "storage.database_path": dbPath})
+		viper.Set("import.no_checkpoint", true)
+		t.Cleanup(func() { viper.Set("import.no_checkpoint", nil) })
+
+		require.NoError(t, saveImportedTransactions(ctx, transactions))
+
+		store, err := storage.NewSQLiteStorage(dbPath)
+		require.NoError(t, err)
+		defer func() { _ = store.Close() }()
+		saved, err := store.GetTransactionsToClassify(ctx, nil)
+		require.NoError(t, err)
+		assert.Len(t, saved, 1, "transactions land in storage.database_path")
+	})
+
+	t.Run("postgres", func(t *testing.T) {
+		setStorageConfig(t, map[string]string{"storage.driver": "postgres", "storage.dsn": ""})
+
+		err := saveImportedTransactions(ctx, transactions)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "storage.dsn is required")
+	})
+}
+
+func TestOpenAnalysisStorage_StorageDriver(t *testing.T) {
+	setStorageConfig(t, map[string]string{"storage.driver": "postgres", "storage.dsn": "postgres://localhost/spice"})
+
+	_, err := openAnalysisStorage()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not support storage.driver postgres")
+}
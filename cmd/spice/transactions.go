@@ -0,0 +1,266 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/spf13/cobra"
+)
+
+// transactionListOptions holds the raw flag values for transactions list.
+type transactionListOptions struct {
+	from      string
+	to        string
+	category  string
+	merchant  string
+	direction string
+	status    string
+	sortBy    string
+	order     string
+	output    string
+	minAmount float64
+	maxAmount float64
+	limit     int
+}
+
+// transactionStatusAliases maps short status names accepted on the command line.
+var transactionStatusAliases = map[string]model.ClassificationStatus{
+	"unclassified": model.StatusUnclassified,
+	"rule":         model.StatusClassifiedByRule,
+	"ai":           model.StatusClassifiedByAI,
+	"user":         model.StatusUserModified,
+}
+
+func transactionsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "transactions",
+		Aliases: []string{"txns"},
+		Short:   "Browse imported transactions",
+		Long:    `View transactions and their classifications without changing anything.`,
+	}
+
+	cmd.AddCommand(transactionsListCmd())
+
+	return cmd
+}
+
+func transactionsListCmd() *cobra.Command {
+	var opts transactionListOptions
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List transactions matching filters",
+		Long: `List transactions with optional filters, sorting and a row limit.
+
+Amount filters compare absolute amounts, so --min-amount 100 matches both
+a $100 purchase and a $100 refund.
+
+Examples:
+  # The 50 most recent transactions
+  spice transactions list
+
+  # Unclassified Amazon transactions from 2024
+  spice transactions list --merchant amazon --status unclassified --from 2024-01-01 --to 2024-12-31
+
+  # Largest dining expenses as JSON
+  spice transactions list --category "Dining" --sort amount --output json`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			query, err := transactionQueryFromOptions(opts, cmd.Flags().Changed("min-amount"), cmd.Flags().Changed("max-amount"))
+			if err != nil {
+				return err
+			}
+			if opts.output != "table" && opts.output != "json" {
+				return fmt.Errorf("invalid output format %q (use table or json)", opts.output)
+			}
+
+			store, err := initStorage(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+			defer func() {
+				if closeErr := store.Close(); closeErr != nil {
+					slog.Error("failed to close storage", "error", closeErr)
+				}
+			}()
+
+			results, err := store.QueryTransactions(ctx, query)
+			if err != nil {
+				return fmt.Errorf("failed to query transactions: %w", err)
+			}
+
+			if opts.output == "json" {
+				return writeTransactionsJSON(cmd.OutOrStdout(), results)
+			}
+			return writeTransactionsTable(cmd.OutOrStdout(), results)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.from, "from", "", "Start date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&opts.to, "to", "", "End date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&opts.category, "category", "", "Only transactions classified in this category")
+	cmd.Flags().StringVar(&opts.merchant, "merchant", "", "Merchant or description contains this text (case-insensitive)")
+	cmd.Flags().Float64Var(&opts.minAmount, "min-amount", 0, "Minimum absolute amount")
+	cmd.Flags().Float64Var(&opts.maxAmount, "max-amount", 0, "Maximum absolute amount")
+	cmd.Flags().StringVar(&opts.direction, "direction", "", "Transaction direction (income, expense, transfer)")
+	cmd.Flags().StringVar(&opts.status, "status", "", "Classification status (unclassified, rule, ai, user)")
+	cmd.Flags().StringVar(&opts.sortBy, "sort", "date", "Sort field (date, amount, merchant, category)")
+	cmd.Flags().StringVar(&opts.order, "order", "desc", "Sort order (asc, desc)")
+	cmd.Flags().IntVar(&opts.limit, "limit", 50, "Maximum number of transactions to show (0 for all)")
+	cmd.Flags().StringVar(&opts.output, "output", "table", "Output format (table, json)")
+
+	return cmd
+}
+
+// transactionQueryFromOptions converts flag values into a storage query.
+// Amount bounds are only applied when their flags were set explicitly.
+func transactionQueryFromOptions(opts transactionListOptions, minSet, maxSet bool) (model.TransactionQuery, error) {
+	query := model.TransactionQuery{
+		Category:  opts.category,
+		Merchant:  opts.merchant,
+		Direction: model.TransactionDirection(strings.ToLower(opts.direction)),
+		SortBy:    model.TransactionSortField(strings.ToLower(opts.sortBy)),
+		Limit:     opts.limit,
+	}
+
+	if opts.from != "" {
+		parsed, err := time.Parse("2006-01-02", opts.from)
+		if err != nil {
+			return query, fmt.Errorf("invalid from date format (use YYYY-MM-DD): %w", err)
+		}
+		query.StartDate = &parsed
+	}
+	if opts.to != "" {
+		parsed, err := time.Parse("2006-01-02", opts.to)
+		if err != nil {
+			return query, fmt.Errorf("invalid to date format (use YYYY-MM-DD): %w", err)
+		}
+		// Set to end of day
+		endOfDay := parsed.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+		query.EndDate = &endOfDay
+	}
+
+	if minSet {
+		minAmount := opts.minAmount
+		query.MinAmount = &minAmount
+	}
+	if maxSet {
+		maxAmount := opts.maxAmount
+		query.MaxAmount = &maxAmount
+	}
+
+	if opts.status != "" {
+		if status, ok := transactionStatusAliases[strings.ToLower(opts.status)]; ok {
+			query.Status = status
+		} else {
+			query.Status = model.ClassificationStatus(strings.ToUpper(opts.status))
+		}
+	}
+
+	switch strings.ToLower(opts.order) {
+	case "desc":
+		query.Descending = true
+	case "asc":
+	default:
+		return query, fmt.Errorf("invalid sort order %q (use asc or desc)", opts.order)
+	}
+
+	if err := query.Validate(); err != nil {
+		return query, err
+	}
+
+	return query, nil
+}
+
+// transactionListItem is the JSON representation of a listed transaction.
+type transactionListItem struct {
+	ID         string  `json:"id"`
+	Date       string  `json:"date"`
+	Merchant   string  `json:"merchant"`
+	Name       string  `json:"name"`
+	AccountID  string  `json:"account_id"`
+	Direction  string  `json:"direction,omitempty"`
+	Category   string  `json:"category,omitempty"`
+	Status     string  `json:"status"`
+	Amount     float64 `json:"amount"`
+	Confidence float64 `json:"confidence,omitempty"`
+}
+
+func writeTransactionsJSON(w io.Writer, results []model.Classification) error {
+	items := make([]transactionListItem, 0, len(results))
+	for _, c := range results {
+		items = append(items, transactionListItem{
+			ID:         c.Transaction.ID,
+			Date:       c.Transaction.Date.Format("2006-01-02"),
+			Merchant:   c.Transaction.MerchantName,
+			Name:       c.Transaction.Name,
+			AccountID:  c.Transaction.AccountID,
+			Direction:  string(c.Transaction.Direction),
+			Category:   c.Category,
+			Status:     string(c.Status),
+			Amount:     c.Transaction.Amount,
+			Confidence: c.Confidence,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(items); err != nil {
+		return fmt.Errorf("failed to encode transactions as JSON: %w", err)
+	}
+	return nil
+}
+
+func writeTransactionsTable(w io.Writer, results []model.Classification) error {
+	if len(results) == 0 {
+		_, _ = fmt.Fprintln(w, "No transactions found matching criteria")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "DATE\tMERCHANT\tAMOUNT\tDIRECTION\tCATEGORY\tSTATUS")
+	for _, c := range results {
+		merchant := c.Transaction.MerchantName
+		if merchant == "" {
+			merchant = c.Transaction.Name
+		}
+		category := c.Category
+		if category == "" {
+			category = "-"
+		}
+		direction := string(c.Transaction.Direction)
+		if direction == "" {
+			direction = "-"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%.2f\t%s\t%s\t%s\n",
+			c.Transaction.Date.Format("2006-01-02"),
+			truncateString(merchant, 40),
+			c.Transaction.Amount,
+			direction,
+			category,
+			transactionStatusLabel(c.Status))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(w, "\n%d transactions\n", len(results))
+	return nil
+}
+
+// transactionStatusLabel returns the short command-line name for a status.
+func transactionStatusLabel(status model.ClassificationStatus) string {
+	for alias, s := range transactionStatusAliases {
+		if s == status {
+			return alias
+		}
+	}
+	return strings.ToLower(string(status))
+}
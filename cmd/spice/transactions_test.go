@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionQueryFromOptions(t *testing.T) {
+	defaults := transactionListOptions{sortBy: "date", order: "desc", limit: 50}
+
+	t.Run("defaults", func(t *testing.T) {
+		query, err := transactionQueryFromOptions(defaults, false, false)
+		require.NoError(t, err)
+		assert.Equal(t, model.SortByDate, query.SortBy)
+		assert.True(t, query.Descending)
+		assert.Equal(t, 50, query.Limit)
+		assert.Nil(t, query.StartDate)
+		assert.Nil(t, query.MinAmount)
+		assert.Nil(t, query.MaxAmount)
+	})
+
+	t.Run("parses dates and amounts", func(t *testing.T) {
+		opts := defaults
+		opts.from = "2024-01-01"
+		opts.to = "2024-01-31"
+		opts.minAmount = 25
+		opts.maxAmount = 100
+
+		query, err := transactionQueryFromOptions(opts, true, true)
+		require.NoError(t, err)
+		require.NotNil(t, query.StartDate)
+		require.NotNil(t, query.EndDate)
+		assert.Equal(t, "2024-01-01", query.StartDate.Format("2006-01-02"))
+		assert.Equal(t, 23, query.EndDate.Hour(), "end date should cover the whole day")
+		require.NotNil(t, query.MinAmount)
+		assert.InDelta(t, 25.0, *query.MinAmount, 0.001)
+		require.NotNil(t, query.MaxAmount)
+		assert.InDelta(t, 100.0, *query.MaxAmount, 0.001)
+	})
+
+	t.Run("status aliases", func(t *testing.T) {
+		for alias, want := range transactionStatusAliases {
+			opts := defaults
+			opts.status = alias
+			query, err := transactionQueryFromOptions(opts, false, false)
+			require.NoError(t, err)
+			assert.Equal(t, want, query.Status)
+		}
+
+		opts := defaults
+		opts.status = "classified_by_ai"
+		query, err := transactionQueryFromOptions(opts, false, false)
+		require.NoError(t, err)
+		assert.Equal(t, model.StatusClassifiedByAI, query.Status)
+	})
+
+	errorCases := []struct {
+		modify func(*transactionListOptions)
+		name   string
+	}{
+		{name: "bad from date", modify: func(o *transactionListOptions) { o.from = "01/02/2024" }},
+		{name: "from after to", modify: func(o *transactionListOptions) { o.from = "2024-02-01"; o.to = "2024-01-01" }},
+		{name: "bad direction", modify: func(o *transactionListOptions) { o.direction = "sideways" }},
+		{name: "bad status", modify: func(o *transactionListOptions) { o.status = "pending" }},
+		{name: "bad sort", modify: func(o *transactionListOptions) { o.sortBy = "color" }},
+		{name: "bad order", modify: func(o *transactionListOptions) { o.order = "random" }},
+		{name: "negative limit", modify: func(o *transactionListOptions) { o.limit = -1 }},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := defaults
+			tc.modify(&opts)
+			_, err := transactionQueryFromOptions(opts, false, false)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestWriteTransactions(t *testing.T) {
+	results := []model.Classification{
+		{
+			Transaction: model.Transaction{ID: "1", Date: time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), MerchantName: "Whole Foods", Amount: 85.2, Direction: model.DirectionExpense},
+			Category:    "Groceries",
+			Status:      model.StatusClassifiedByAI,
+			Confidence:  0.92,
+		},
+		{
+			Transaction: model.Transaction{ID: "2", Date: time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC), Name: "MYSTERY SHOP", Amount: 19.99},
+			Status:      model.StatusUnclassified,
+		},
+	}
+
+	t.Run("table", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeTransactionsTable(&buf, results))
+		out := buf.String()
+		assert.Contains(t, out, "Whole Foods")
+		assert.Contains(t, out, "Groceries")
+		assert.Contains(t, out, "ai")
+		assert.Contains(t, out, "MYSTERY SHOP", "falls back to the description without a merchant")
+		assert.Contains(t, out, "unclassified")
+		assert.Contains(t, out, "2 transactions")
+	})
+
+	t.Run("empty table", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeTransactionsTable(&buf, nil))
+		assert.Contains(t, buf.String(), "No transactions found")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeTransactionsJSON(&buf, results))
+
+		var items []transactionListItem
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &items))
+		require.Len(t, items, 2)
+		assert.Equal(t, "2024-03-05", items[0].Date)
+		assert.Equal(t, "Groceries", items[0].Category)
+		assert.Equal(t, "CLASSIFIED_BY_AI", items[0].Status)
+		assert.Equal(t, "UNCLASSIFIED", items[1].Status)
+		assert.Empty(t, items[1].Category)
+	})
+
+	t.Run("empty json is an array", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeTransactionsJSON(&buf, nil))
+		assert.JSONEq(t, "[]", buf.String())
+	})
+}
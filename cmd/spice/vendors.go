@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -8,6 +9,7 @@ import (
 	"text/tabwriter"
 	"time"
 
+	"github.com/Veraticus/the-spice-must-flow/internal/common"
 	"github.com/Veraticus/the-spice-must-flow/internal/model"
 	"github.com/spf13/cobra"
 )
@@ -26,6 +28,10 @@ func vendorsCmd() *cobra.Command {
 	cmd.AddCommand(vendorsEditCmd())
 	cmd.AddCommand(vendorsDeleteCmd())
 	cmd.AddCommand(vendorsDeleteAllCmd())
+	cmd.AddCommand(vendorsRecountCmd())
+	cmd.AddCommand(vendorsHintCmd())
+	cmd.AddCommand(vendorsMarkMultiCmd())
+	cmd.AddCommand(vendorsDedupCmd())
 
 	return cmd
 }
@@ -87,9 +93,15 @@ func vendorsListCmd() *cobra.Command {
 				if vendor.IsRegex {
 					vendorType = "regex"
 				}
+				category := vendor.Category
+				if vendor.MultiCategory {
+					// Listed for the mark; the category, if any, isn't applied
+					vendorType += ", multi-category"
+					category = "-"
+				}
 				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n",
 					vendor.Name,
-					vendor.Category,
+					category,
 					vendor.Source,
 					vendorType,
 					vendor.UseCount,
@@ -191,10 +203,15 @@ func vendorsCreateCmd() *cobra.Command {
 			}
 			defer cleanup()
 
-			// Check if vendor already exists
-			existing, _ := db.GetVendor(ctx, merchant)
+			// Check if vendor already exists, under any spelling when vendor
+			// matching ignores case; saving would overwrite that rule
+			lookup := db.GetVendorRule
+			if isRegex {
+				lookup = db.GetVendor
+			}
+			existing, _ := lookup(ctx, merchant)
 			if existing != nil {
-				return fmt.Errorf("vendor rule for '%s' already exists with category '%s'", merchant, existing.Category)
+				return fmt.Errorf("vendor rule for '%s' already exists with category '%s'", existing.Name, existing.Category)
 			}
 
 			// Create new vendor with manual source
@@ -250,8 +267,9 @@ func vendorsEditCmd() *cobra.Command {
 			}
 			defer cleanup()
 
-			// Check if vendor exists
-			vendor, err := db.GetVendor(ctx, merchant)
+			// Check if vendor exists, under any spelling when vendor matching
+			// ignores case
+			vendor, err := db.GetVendorRule(ctx, merchant)
 			if err != nil {
 				return fmt.Errorf("vendor '%s' not found", merchant)
 			}
@@ -287,7 +305,7 @@ func vendorsEditCmd() *cobra.Command {
 			}
 
 			slog.Info("✓ Vendor rule updated successfully",
-				"merchant", merchant,
+				"merchant", vendor.Name,
 				"new_category", newCategory)
 
 			return nil
@@ -315,8 +333,9 @@ func vendorsDeleteCmd() *cobra.Command {
 			}
 			defer cleanup()
 
-			// Check if vendor exists
-			vendor, err := db.GetVendor(ctx, merchant)
+			// Check if vendor exists, under any spelling when vendor matching
+			// ignores case
+			vendor, err := db.GetVendorRule(ctx, merchant)
 			if err != nil {
 				return fmt.Errorf("vendor '%s' not found", merchant)
 			}
@@ -348,11 +367,11 @@ func vendorsDeleteCmd() *cobra.Command {
 			}
 
 			// Delete vendor
-			if err := db.DeleteVendor(ctx, merchant); err != nil {
+			if err := db.DeleteVendor(ctx, vendor.Name); err != nil {
 				return fmt.Errorf("failed to delete vendor: %w", err)
 			}
 
-			slog.Info("Vendor rule deleted successfully", "merchant", merchant)
+			slog.Info("Vendor rule deleted successfully", "merchant", vendor.Name)
 			return nil
 		},
 	}
@@ -439,3 +458,95 @@ func vendorsDeleteAllCmd() *cobra.Command {
 	}
 	return cmd
 }
+
+func vendorsRecountCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "recount",
+		Short: "Recompute vendor use counts from classifications",
+		Long: `Recompute each vendor's use count from the classifications it accounts for
+and store the corrected value.
+
+Use counts are incremented as transactions are classified and can drift after
+merges, recategorizations or manual database edits. A classification counts
+toward the vendor its merchant matches when it has that vendor's category.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			db, cleanup, err := getDatabase()
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			changes, err := db.RecomputeVendorUseCounts(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to recompute vendor use counts: %w", err)
+			}
+
+			if len(changes) == 0 {
+				slog.Info("✓ All vendor use counts are up to date")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "MERCHANT\tOLD COUNT\tNEW COUNT")
+			_, _ = fmt.Fprintln(w, "────────\t─────────\t─────────")
+			for _, change := range changes {
+				_, _ = fmt.Fprintf(w, "%s\t%d\t%d\n", change.Name, change.OldCount, change.NewCount)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			slog.Info(fmt.Sprintf("✓ Updated use counts for %d vendors", len(changes)))
+			return nil
+		},
+	}
+}
+
+func vendorsMarkMultiCmd() *cobra.Command {
+	var unset bool
+
+	cmd := &cobra.Command{
+		Use:   "mark-multi <merchant>",
+		Short: "Mark a merchant as spanning many categories",
+		Long: `Mark a merchant that legitimately spans many categories, like Target or
+Amazon, so it never gets a vendor rule. Its transactions are classified and
+reviewed one by one instead of as a group, and no rule is created from them.
+A rule the merchant already has stays stored but isn't applied while it is
+marked; --unset brings it back.
+
+Examples:
+  spice vendors mark-multi "TARGET"
+  spice vendors mark-multi "TARGET" --unset`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			merchant := strings.TrimSpace(args[0])
+
+			db, cleanup, err := getDatabase()
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			if err := db.SetVendorMultiCategory(ctx, merchant, !unset); err != nil {
+				if errors.Is(err, common.ErrNotFound) {
+					return fmt.Errorf("merchant '%s' is not marked multi-category", merchant)
+				}
+				return fmt.Errorf("failed to mark vendor: %w", err)
+			}
+
+			if unset {
+				slog.Info(fmt.Sprintf("✓ %s is no longer multi-category", merchant))
+			} else {
+				slog.Info(fmt.Sprintf("✓ %s marked multi-category; its transactions will be classified one by one", merchant))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&unset, "unset", false, "Remove the multi-category mark")
+	return cmd
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/engine"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/spf13/cobra"
+)
+
+func vendorsDedupCmd() *cobra.Command {
+	var threshold float64
+	var pick bool
+
+	cmd := &cobra.Command{
+		Use:   "dedup",
+		Short: "Find vendor rules that look like the same merchant",
+		Long: `Find exact vendor rules whose names are spellings of the same merchant, such
+as "AMAZON.COM" and "Amazon Com", and print them in clusters with their
+categories and use counts. Names are compared upper cased with punctuation
+stripped, by edit distance; --threshold sets how similar they must be.
+
+The most used vendor of each cluster is suggested as its canonical name. With
+--pick you choose the canonical name of each cluster yourself, and get the
+'spice vendors edit' commands that give its duplicates the same category.
+Nothing is changed.
+
+Examples:
+  spice vendors dedup
+  spice vendors dedup --threshold 0.75 --pick`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			if threshold <= 0 || threshold > 1 {
+				return fmt.Errorf("--threshold must be between 0 and 1")
+			}
+
+			db, cleanup, err := getDatabase()
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			vendors, err := db.GetAllVendors(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get vendors: %w", err)
+			}
+
+			out := cmd.OutOrStdout()
+			clusters := engine.FindDuplicateVendors(vendors, threshold)
+			if len(clusters) == 0 {
+				_, _ = fmt.Fprintf(out, "No likely duplicates among %d vendor rules.\n", len(vendors))
+				return nil
+			}
+			_, _ = fmt.Fprintf(out, "Found %d clusters of likely duplicate vendor rules.\n", len(clusters))
+
+			reader := bufio.NewReader(cmd.InOrStdin())
+			for i, cluster := range clusters {
+				if err := writeVendorCluster(out, i+1, cluster); err != nil {
+					return err
+				}
+				if !pick {
+					continue
+				}
+
+				choice, err := promptInt(reader, fmt.Sprintf("Canonical name (1-%d, 0 to skip)", len(cluster.Vendors)), 0, len(cluster.Vendors))
+				if err != nil {
+					return fmt.Errorf("failed to read answer: %w", err)
+				}
+				if choice == 0 {
+					continue
+				}
+				writeVendorMergePlan(out, cluster.Vendors[choice-1], cluster.Vendors)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().Float64Var(&threshold, "threshold", engine.DefaultVendorSimilarity, "Similarity two names need to be suggested as duplicates (0-1)")
+	cmd.Flags().BoolVar(&pick, "pick", false, "Choose the canonical name of each cluster")
+	return cmd
+}
+
+// writeVendorCluster prints a cluster with its vendors numbered; the
+// suggested canonical vendor is starred.
+func writeVendorCluster(w io.Writer, n int, cluster engine.VendorCluster) error {
+	_, _ = fmt.Fprintf(w, "\n%d. %d vendors, %.0f%% similar\n", n, len(cluster.Vendors), cluster.Similarity*100)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "   #\tMERCHANT\tCATEGORY\tSOURCE\tUSE COUNT")
+	for i, vendor := range cluster.Vendors {
+		marker := " "
+		if i == 0 {
+			marker = "*"
+		}
+		_, _ = fmt.Fprintf(tw, " %s %d\t%s\t%s\t%s\t%d\n",
+			marker, i+1, vendor.Name, vendor.Category, vendor.Source, vendor.UseCount)
+	}
+	return tw.Flush()
+}
+
+// writeVendorMergePlan prints the commands that give every other vendor of a
+// cluster the canonical vendor's category.
+func writeVendorMergePlan(w io.Writer, canonical model.Vendor, vendors []model.Vendor) {
+	var edits []string
+	for _, vendor := range vendors {
+		if vendor.Name == canonical.Name || vendor.Category == canonical.Category {
+			continue
+		}
+		edits = append(edits, fmt.Sprintf("   spice vendors edit %q --category %q", vendor.Name, canonical.Category))
+	}
+
+	if len(edits) == 0 {
+		_, _ = fmt.Fprintf(w, "   Canonical: %s. Every vendor already uses %s.\n", canonical.Name, canonical.Category)
+		return
+	}
+	_, _ = fmt.Fprintf(w, "   Canonical: %s. To use %s for all of them:\n", canonical.Name, canonical.Category)
+	for _, edit := range edits {
+		_, _ = fmt.Fprintln(w, edit)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/engine"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteVendorCluster(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeVendorCluster(&buf, 3, engine.VendorCluster{
+		Vendors: []model.Vendor{
+			{Name: "Amazon Com", Category: "Shopping", Source: model.SourceManual, UseCount: 12},
+			{Name: "AMAZON.COM", Category: "Books", Source: model.SourceAuto, UseCount: 3},
+		},
+		Similarity: 0.9,
+	})
+	require.NoError(t, err)
+
+	out := buf.String()
+	assert.Contains(t, out, "3. 2 vendors, 90% similar")
+	assert.Regexp(t, `\* 1\s+Amazon Com\s+Shopping\s+\S+\s+12`, out)
+	assert.Regexp(t, `  2\s+AMAZON\.COM\s+Books\s+\S+\s+3`, out)
+}
+
+func TestWriteVendorMergePlan(t *testing.T) {
+	vendors := []model.Vendor{
+		{Name: "Amazon Com", Category: "Shopping"},
+		{Name: "AMAZON.COM", Category: "Books"},
+		{Name: "AMAZON COM", Category: "Shopping"},
+	}
+
+	var buf bytes.Buffer
+	writeVendorMergePlan(&buf, vendors[0], vendors)
+	assert.Contains(t, buf.String(), `spice vendors edit "AMAZON.COM" --category "Shopping"`)
+	assert.NotContains(t, buf.String(), `"AMAZON COM"`)
+
+	buf.Reset()
+	writeVendorMergePlan(&buf, vendors[0], []model.Vendor{vendors[0], vendors[2]})
+	assert.Contains(t, buf.String(), "Every vendor already uses Shopping")
+}
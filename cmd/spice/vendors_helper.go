@@ -7,12 +7,13 @@ import (
 	"os"
 	"strings"
 
-	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
 	"github.com/spf13/viper"
 )
 
 // getDatabase returns a database connection and a cleanup function.
-func getDatabase() (*storage.SQLiteStorage, func(), error) {
+func getDatabase() (service.Storage, func(), error) {
 	// Get database path from config
 	dbPath := viper.GetString("database.path")
 	if dbPath == "" {
@@ -29,7 +30,7 @@ func getDatabase() (*storage.SQLiteStorage, func(), error) {
 	}
 
 	// Open database
-	db, err := storage.NewSQLiteStorage(dbPath)
+	db, err := openStorage(dbPath)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -49,3 +50,12 @@ func getDatabase() (*storage.SQLiteStorage, func(), error) {
 
 	return db, cleanup, nil
 }
+
+// configureVendorMatching applies classification.vendor_regex_precedence, which
+// decides which regex vendor wins when several match a merchant, and
+// classification.vendor_case_sensitive.
+func configureVendorMatching(store configurableStorage) error {
+	store.SetVendorCaseSensitive(viper.GetBool("classification.vendor_case_sensitive"))
+	precedence := model.RegexVendorPrecedence(viper.GetString("classification.vendor_regex_precedence"))
+	return store.SetRegexVendorPrecedence(precedence)
+}
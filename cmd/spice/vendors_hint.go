@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/common"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/spf13/cobra"
+)
+
+func vendorsHintCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hint",
+		Short: "Manage merchant hints for AI classification",
+		Long: `Merchant hints are notes passed to the AI whenever it classifies a merchant,
+such as "ACME LLC is my web hosting provider". Unlike a vendor rule, a hint
+doesn't fix the category: the AI still decides, but is strongly biased toward
+the answer the hint describes. Use hints for ambiguous merchants whose name
+alone doesn't say what they are.`,
+	}
+
+	cmd.AddCommand(vendorsHintSetCmd())
+	cmd.AddCommand(vendorsHintListCmd())
+	cmd.AddCommand(vendorsHintDeleteCmd())
+
+	return cmd
+}
+
+func vendorsHintSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <merchant> <hint>",
+		Short: "Set the AI hint for a merchant",
+		Long: `Set the hint passed to the AI when classifying a merchant, replacing any
+existing hint. Merchant names are matched case-insensitively.
+
+Examples:
+  spice vendors hint set "ACME LLC" "My web hosting provider, always Business/Hosting"
+  spice vendors hint set "SQ *JOES" Coffee with clients, usually a business meal`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			hint := &model.MerchantHint{
+				MerchantName: strings.TrimSpace(args[0]),
+				Hint:         strings.TrimSpace(strings.Join(args[1:], " ")),
+			}
+
+			db, cleanup, err := getDatabase()
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			if err := db.SaveMerchantHint(ctx, hint); err != nil {
+				return fmt.Errorf("failed to save merchant hint: %w", err)
+			}
+
+			slog.Info(fmt.Sprintf("✓ Hint set for %s", hint.MerchantName))
+			return nil
+		},
+	}
+}
+
+func vendorsHintListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List merchant hints",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+
+			db, cleanup, err := getDatabase()
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			hints, err := db.GetMerchantHints(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get merchant hints: %w", err)
+			}
+
+			if len(hints) == 0 {
+				slog.Info("No merchant hints found")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "MERCHANT\tHINT\tUPDATED")
+			_, _ = fmt.Fprintln(w, "────────\t────\t───────")
+			for _, hint := range hints {
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", hint.MerchantName, hint.Hint, hint.UpdatedAt.Format("2006-01-02"))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func vendorsHintDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <merchant>",
+		Short: "Delete the AI hint for a merchant",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			db, cleanup, err := getDatabase()
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			if err := db.DeleteMerchantHint(ctx, args[0]); err != nil {
+				if errors.Is(err, common.ErrNotFound) {
+					return fmt.Errorf("no hint found for merchant '%s'", args[0])
+				}
+				return fmt.Errorf("failed to delete merchant hint: %w", err)
+			}
+
+			slog.Info(fmt.Sprintf("✓ Hint deleted for %s", args[0]))
+			return nil
+		},
+	}
+}
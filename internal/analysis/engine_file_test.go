@@ -360,6 +360,9 @@ func (m *fileTestStorage) GetTransactionsToClassify(_ context.Context, _ *time.T
 func (m *fileTestStorage) GetTransactionByID(_ context.Context, _ string) (*model.Transaction, error) {
 	return &model.Transaction{}, nil // Return empty transaction for test stub
 }
+func (m *fileTestStorage) GetTransactionByHash(_ context.Context, _ string) (*model.Transaction, error) {
+	return &model.Transaction{}, nil // Return empty transaction for test stub
+}
 func (m *fileTestStorage) GetTransactionsByCategory(_ context.Context, _ string) ([]model.Transaction, error) {
 	return nil, nil
 }
@@ -385,14 +388,32 @@ func (m *fileTestStorage) GetLatestTransactionDate(_ context.Context) (time.Time
 func (m *fileTestStorage) GetCategorySummary(_ context.Context, _, _ time.Time) (map[string]float64, error) {
 	return map[string]float64{}, nil // Return empty map for test stub
 }
+func (m *fileTestStorage) QueryTransactions(_ context.Context, _ model.TransactionQuery) ([]model.Classification, error) {
+	return nil, nil
+}
 func (m *fileTestStorage) GetMerchantSummary(_ context.Context, _, _ time.Time) (map[string]float64, error) {
 	return map[string]float64{}, nil // Return empty map for test stub
 }
+func (m *fileTestStorage) GetClassificationCoverage(_ context.Context, _, _ time.Time) ([]model.CoverageMonth, error) {
+	return nil, nil
+}
+func (m *fileTestStorage) GetUnclassifiedMerchants(_ context.Context, _, _ time.Time, _ int) ([]model.UnclassifiedMerchant, error) {
+	return nil, nil
+}
+func (m *fileTestStorage) GetCategoryAmountStats(_ context.Context) (map[string]model.CategoryAmountStats, error) {
+	return nil, nil
+}
 func (m *fileTestStorage) GetVendor(_ context.Context, _ string) (*model.Vendor, error) {
 	return &model.Vendor{}, nil // Return empty vendor for test stub
 }
+func (m *fileTestStorage) GetVendorRule(_ context.Context, _ string) (*model.Vendor, error) {
+	return &model.Vendor{}, nil // Return empty vendor for test stub
+}
 func (m *fileTestStorage) SaveVendor(_ context.Context, _ *model.Vendor) error { return nil }
 func (m *fileTestStorage) DeleteVendor(_ context.Context, _ string) error      { return nil }
+func (m *fileTestStorage) SetVendorMultiCategory(_ context.Context, _ string, _ bool) error {
+	return nil
+}
 func (m *fileTestStorage) GetAllVendors(_ context.Context) ([]model.Vendor, error) {
 	return []model.Vendor{}, nil
 }
@@ -417,13 +438,80 @@ func (m *fileTestStorage) UpdateVendorCategoriesByID(_ context.Context, _, _ int
 func (m *fileTestStorage) FindVendorMatch(_ context.Context, _ string) (*model.Vendor, error) {
 	return &model.Vendor{}, nil
 }
+func (m *fileTestStorage) RecomputeVendorUseCounts(_ context.Context) ([]model.VendorRecount, error) {
+	return nil, nil
+}
+func (m *fileTestStorage) SaveMerchantHint(_ context.Context, _ *model.MerchantHint) error {
+	return nil
+}
+func (m *fileTestStorage) GetMerchantHints(_ context.Context) ([]model.MerchantHint, error) {
+	return nil, nil
+}
+func (m *fileTestStorage) DeleteMerchantHint(_ context.Context, _ string) error {
+	return nil
+}
+func (m *fileTestStorage) SaveMerchantEmbeddings(_ context.Context, _ []model.MerchantEmbedding) error {
+	return nil
+}
+func (m *fileTestStorage) GetMerchantEmbeddings(_ context.Context, _ string) ([]model.MerchantEmbedding, error) {
+	return nil, nil
+}
+func (m *fileTestStorage) GetMerchantsWithoutEmbedding(_ context.Context, _ string) ([]string, error) {
+	return nil, nil
+}
+func (m *fileTestStorage) SaveCategoryAlias(_ context.Context, _, _ string) error {
+	return nil
+}
+func (m *fileTestStorage) GetCategoryAliases(_ context.Context) ([]model.CategoryAlias, error) {
+	return nil, nil
+}
+func (m *fileTestStorage) SaveCategorySnapshot(_ context.Context, _ *model.CategorySnapshot) error {
+	return nil
+}
+func (m *fileTestStorage) GetCategorySnapshot(_ context.Context, _ int64) (*model.CategorySnapshot, error) {
+	return nil, nil
+}
+func (m *fileTestStorage) GetCategorySnapshots(_ context.Context) ([]model.CategorySnapshot, error) {
+	return nil, nil
+}
+func (m *fileTestStorage) DeleteCategoryAlias(_ context.Context, _ string) error {
+	return nil
+}
 func (m *fileTestStorage) SaveClassification(_ context.Context, _ *model.Classification) error {
 	return nil
 }
+func (m *fileTestStorage) SaveClassifications(_ context.Context, _ []model.Classification) error {
+	return nil
+}
+func (m *fileTestStorage) GetCategoryChanges(_ context.Context, _ time.Time) ([]model.CategoryChange, error) {
+	return nil, nil
+}
+func (m *fileTestStorage) SaveClassificationHistory(_ context.Context, _ []model.Classification) error {
+	return nil
+}
+func (m *fileTestStorage) GetConfidenceOutcomes(_ context.Context) ([]model.ConfidenceOutcome, error) {
+	return nil, nil
+}
 func (m *fileTestStorage) GetClassificationsByConfidence(_ context.Context, _ float64, _ bool) ([]model.Classification, error) {
 	return []model.Classification{}, nil
 }
 func (m *fileTestStorage) ClearAllClassifications(_ context.Context) error { return nil }
+func (m *fileTestStorage) UpdateBusinessPercentByCategory(_ context.Context, _ string, _ int) (int64, error) {
+	return 0, nil
+}
+func (m *fileTestStorage) FindRefundedPurchase(_ context.Context, _ model.Transaction, _ time.Duration) (*model.Classification, error) {
+	return nil, nil
+}
+func (m *fileTestStorage) MarkTransactionRefund(_ context.Context, _, _ string) error { return nil }
+func (m *fileTestStorage) UpdateTransactionDirections(_ context.Context, _ map[string]model.TransactionDirection) error {
+	return nil
+}
+func (m *fileTestStorage) HasClassificationHistory(_ context.Context, _ string) (bool, error) {
+	return false, nil
+}
+func (m *fileTestStorage) UpdateBusinessPercentByVendor(_ context.Context, _ string, _ int) (int64, error) {
+	return 0, nil
+}
 func (m *fileTestStorage) GetCategoryByName(_ context.Context, _ string) (*model.Category, error) {
 	return &model.Category{}, nil
 }
@@ -437,6 +525,9 @@ func (m *fileTestStorage) UpdateCategory(_ context.Context, _ int, _, _ string)
 	return nil
 }
 func (m *fileTestStorage) DeleteCategory(_ context.Context, _ int) error { return nil }
+func (m *fileTestStorage) MergeCategories(_ context.Context, _, _ int) (*model.CategoryMergeResult, error) {
+	return nil, nil
+}
 func (m *fileTestStorage) CreateCheckPattern(_ context.Context, _ *model.CheckPattern) error {
 	return nil
 }
@@ -459,6 +550,9 @@ func (m *fileTestStorage) CreatePatternRule(_ context.Context, _ *model.PatternR
 func (m *fileTestStorage) GetPatternRule(_ context.Context, _ int) (*model.PatternRule, error) {
 	return &model.PatternRule{}, nil
 }
+func (m *fileTestStorage) GetAllPatternRules(_ context.Context) ([]model.PatternRule, error) {
+	return nil, nil
+}
 func (m *fileTestStorage) UpdatePatternRule(_ context.Context, _ *model.PatternRule) error {
 	return nil
 }
@@ -475,6 +569,12 @@ func (m *fileTestStorage) BeginTx(_ context.Context) (service.Transaction, error
 func (m *fileTestStorage) UpdateCategoryBusinessPercent(_ context.Context, _ int, _ int) error {
 	return nil
 }
+func (m *fileTestStorage) SetCategoryAlwaysReview(_ context.Context, _ int, _ bool) error {
+	return nil
+}
+func (m *fileTestStorage) SetCategoryVendorRuleThreshold(_ context.Context, _ int, _ float64) error {
+	return nil
+}
 func (m *fileTestStorage) Close() error { return nil }
 
 type fileTestValidator struct{}
@@ -235,6 +235,16 @@ func (m *engineMockStorage) ClearAllClassifications(ctx context.Context) error {
 	return args.Error(0)
 }
 
+func (m *engineMockStorage) UpdateBusinessPercentByCategory(ctx context.Context, categoryName string, businessPercent int) (int64, error) {
+	args := m.Called(ctx, categoryName, businessPercent)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *engineMockStorage) UpdateBusinessPercentByVendor(ctx context.Context, merchantName string, businessPercent int) (int64, error) {
+	args := m.Called(ctx, merchantName, businessPercent)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *engineMockStorage) CreateCategory(ctx context.Context, name, description string) (*model.Category, error) {
 	args := m.Called(ctx, name, description)
 	if args.Get(0) == nil {
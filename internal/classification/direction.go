@@ -0,0 +1,62 @@
+package classification
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+// DirectionConfidenceThreshold is the minimum pattern confidence trusted to set a direction.
+const DirectionConfidenceThreshold = 0.75
+
+// InferDirection works out the direction of a transaction that has none.
+// Signals are tried in order of reliability:
+//  1. a direction pattern match with at least DirectionConfidenceThreshold confidence
+//  2. the OFX-style transaction type (DEP, POS, ...)
+//  3. the type of the category the transaction is already classified in
+//  4. the sign of the amount, where negative means money in as in the classify flow
+//
+// An empty direction is returned when no signal applies, e.g. a zero amount.
+func InferDirection(ctx context.Context, detector *PatternDetector, txn model.Transaction, categoryType model.CategoryType) (model.TransactionDirection, error) {
+	if detector != nil {
+		match, err := detector.Classify(ctx, txn)
+		if err != nil {
+			return "", err
+		}
+		if match != nil && match.Confidence >= DirectionConfidenceThreshold {
+			switch match.Type {
+			case PatternTypeIncome:
+				return model.DirectionIncome, nil
+			case PatternTypeExpense:
+				return model.DirectionExpense, nil
+			case PatternTypeTransfer:
+				return model.DirectionTransfer, nil
+			}
+		}
+	}
+
+	switch strings.ToUpper(txn.Type) {
+	case "CREDIT", "DEP", "DIRECTDEP", "INT", "DIV":
+		return model.DirectionIncome, nil
+	case "DEBIT", "CHECK", "FEE", "SRVCHG", "PAYMENT", "ATM", "POS", "DIRECTDEBIT":
+		return model.DirectionExpense, nil
+	}
+
+	switch categoryType {
+	case model.CategoryTypeIncome:
+		return model.DirectionIncome, nil
+	case model.CategoryTypeExpense:
+		return model.DirectionExpense, nil
+	case model.CategoryTypeSystem:
+		return model.DirectionTransfer, nil
+	}
+
+	switch {
+	case txn.Amount < 0:
+		return model.DirectionIncome, nil
+	case txn.Amount > 0:
+		return model.DirectionExpense, nil
+	}
+	return "", nil
+}
@@ -0,0 +1,68 @@
+package classification
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInferDirection(t *testing.T) {
+	detector, err := NewPatternDetector(DefaultPatterns())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		categoryType model.CategoryType
+		want         model.TransactionDirection
+		txn          model.Transaction
+	}{
+		{
+			name: "pattern match wins",
+			txn:  model.Transaction{Name: "ACME CORP PAYROLL", Amount: 2500, Type: "DEBIT"},
+			want: model.DirectionIncome,
+		},
+		{
+			name: "transaction type",
+			txn:  model.Transaction{Name: "CORNER STORE", Amount: -12, Type: "POS"},
+			want: model.DirectionExpense,
+		},
+		{
+			name:         "category type",
+			txn:          model.Transaction{Name: "CLIENT ABC", Amount: 12},
+			categoryType: model.CategoryTypeIncome,
+			want:         model.DirectionIncome,
+		},
+		{
+			name:         "system category is a transfer",
+			txn:          model.Transaction{Name: "ONLINE XYZ", Amount: 500},
+			categoryType: model.CategoryTypeSystem,
+			want:         model.DirectionTransfer,
+		},
+		{
+			name: "positive amount is an expense",
+			txn:  model.Transaction{Name: "CORNER STORE", Amount: 12},
+			want: model.DirectionExpense,
+		},
+		{
+			name: "negative amount is income",
+			txn:  model.Transaction{Name: "CORNER STORE", Amount: -12},
+			want: model.DirectionIncome,
+		},
+		{
+			name: "zero amount stays unknown",
+			txn:  model.Transaction{Name: "CORNER STORE"},
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := InferDirection(context.Background(), detector, tt.txn, tt.categoryType)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// businessUseOption is one answer to a business questionnaire question.
+type businessUseOption struct {
+	key     string
+	label   string
+	percent int
+}
+
+var (
+	// businessUseOptions answer "how is this category used?".
+	businessUseOptions = []businessUseOption{
+		{key: "f", label: "Fully business (work-only software, supplies, client travel)", percent: 100},
+		{key: "p", label: "Partly business (phone, internet, car, home office)"},
+		{key: "n", label: "Never business (personal spending)", percent: 0},
+	}
+
+	// businessShareOptions answer "how much of it is business?" for partly business categories.
+	businessShareOptions = []businessUseOption{
+		{key: "1", label: "Mostly business", percent: 75},
+		{key: "2", label: "About half, or business meals (usually 50% deductible)", percent: 50},
+		{key: "3", label: "Occasionally business", percent: 25},
+	}
+)
+
+// SetBusinessQuestionnaire makes new expense categories created during review
+// ask a few questions about business use instead of for a bare percentage.
+func (p *Prompter) SetBusinessQuestionnaire(enabled bool) {
+	p.businessQuestionnaire = enabled
+}
+
+// AskBusinessUse walks through a short questionnaire about how an expense
+// category is used and returns the default business percentage it suggests.
+// ok is false when the user skips the questionnaire.
+func (p *Prompter) AskBusinessUse(ctx context.Context, categoryName string) (percent int, ok bool, err error) {
+	if _, err := fmt.Fprintln(p.writer); err != nil {
+		return 0, false, fmt.Errorf("failed to write newline: %w", err)
+	}
+	if _, err := fmt.Fprintln(p.writer, FormatInfo(fmt.Sprintf("How is %q used?", categoryName))); err != nil {
+		return 0, false, fmt.Errorf("failed to write business use question: %w", err)
+	}
+
+	choice, ok, err := p.promptBusinessOptions(ctx, businessUseOptions, "  [S] Skip, I'll enter a percentage")
+	if err != nil || !ok {
+		return 0, false, err
+	}
+	if choice.key != "p" {
+		return choice.percent, true, nil
+	}
+
+	if _, err := fmt.Fprintln(p.writer, FormatInfo("How much of it is business?")); err != nil {
+		return 0, false, fmt.Errorf("failed to write business share question: %w", err)
+	}
+	choice, ok, err = p.promptBusinessOptions(ctx, businessShareOptions, "  [S] Skip, I'll enter an exact percentage")
+	if err != nil || !ok {
+		return 0, false, err
+	}
+	return choice.percent, true, nil
+}
+
+// promptBusinessOptions lists options and a skip line and returns the chosen
+// option. ok is false when the user skips.
+func (p *Prompter) promptBusinessOptions(ctx context.Context, options []businessUseOption, skipLine string) (choice businessUseOption, ok bool, err error) {
+	keys := make([]string, 0, len(options)+1)
+	for _, option := range options {
+		if _, err := fmt.Fprintf(p.writer, "  [%s] %s\n", strings.ToUpper(option.key), option.label); err != nil {
+			return choice, false, fmt.Errorf("failed to write business option: %w", err)
+		}
+		keys = append(keys, option.key)
+	}
+	if _, err := fmt.Fprintln(p.writer, skipLine); err != nil {
+		return choice, false, fmt.Errorf("failed to write skip option: %w", err)
+	}
+	keys = append(keys, "s")
+
+	key, err := p.promptChoice(ctx, "Choice", keys)
+	if err != nil {
+		return choice, false, err
+	}
+	for _, option := range options {
+		if option.key == key {
+			return option, true, nil
+		}
+	}
+	return choice, false, nil
+}
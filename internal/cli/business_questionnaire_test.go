@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrompter_AskBusinessUse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		percent int
+		ok      bool
+	}{
+		{"fully business", "f\n", 100, true},
+		{"never business", "N\n", 0, true},
+		{"partly, mostly business", "p\n1\n", 75, true},
+		{"partly, about half", "p\n2\n", 50, true},
+		{"invalid answer is asked again", "x\np\n3\n", 25, true},
+		{"skipped", "s\n", 0, false},
+		{"skipped after partly", "p\ns\n", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			prompter := NewCLIPrompter(strings.NewReader(tt.input), &out)
+
+			percent, ok, err := prompter.AskBusinessUse(context.Background(), "Phone")
+			require.NoError(t, err)
+			assert.Equal(t, tt.percent, percent)
+			assert.Equal(t, tt.ok, ok)
+			assert.Contains(t, out.String(), `How is "Phone" used?`)
+		})
+	}
+}
+
+func TestPrompter_NewCategoryBusinessQuestionnaire(t *testing.T) {
+	t.Run("answers set the business percentage", func(t *testing.T) {
+		prompter := NewCLIPrompter(strings.NewReader("\np\n2\n"), &bytes.Buffer{})
+		prompter.SetBusinessQuestionnaire(true)
+
+		request := &newCategoryRequest{name: "Phone"}
+		require.NoError(t, prompter.promptNewCategoryDetails(context.Background(), request))
+		assert.Equal(t, model.CategoryTypeExpense, request.categoryType)
+		assert.Equal(t, 50, request.businessPercent)
+	})
+
+	t.Run("skipping falls back to the percentage prompt", func(t *testing.T) {
+		var out bytes.Buffer
+		prompter := NewCLIPrompter(strings.NewReader("\ns\n40\n"), &out)
+		prompter.SetBusinessQuestionnaire(true)
+
+		request := &newCategoryRequest{name: "Car"}
+		require.NoError(t, prompter.promptNewCategoryDetails(context.Background(), request))
+		assert.Equal(t, 40, request.businessPercent)
+		assert.Contains(t, out.String(), "Default business %")
+	})
+
+	t.Run("income categories aren't asked", func(t *testing.T) {
+		var out bytes.Buffer
+		prompter := NewCLIPrompter(strings.NewReader("i\n"), &out)
+		prompter.SetBusinessQuestionnaire(true)
+
+		request := &newCategoryRequest{name: "Consulting"}
+		require.NoError(t, prompter.promptNewCategoryDetails(context.Background(), request))
+		assert.Equal(t, model.CategoryTypeIncome, request.categoryType)
+		assert.NotContains(t, out.String(), "used?")
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"log/slog"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -28,10 +29,17 @@ type Prompter struct {
 	categoryHistory   map[string][]string
 	recentCategories  []string
 	stats             service.CompletionStats
+	statsFilter       StatsFilter
 	totalTransactions int
 	processedCount    int
 	statsMutex        sync.RWMutex
 	historyMutex      sync.RWMutex
+	// businessQuestionnaire asks about business use for new expense categories
+	businessQuestionnaire bool
+	// minSuggestionConfidence hides AI suggestions scoring below it
+	minSuggestionConfidence float64
+	// explain shows why the AI suggested a category and what else it ranked
+	explain bool
 }
 
 // NewCLIPrompter creates a new CLI prompter with the given reader and writer.
@@ -70,11 +78,15 @@ func (p *Prompter) ConfirmClassification(ctx context.Context, pending model.Pend
 		return model.Classification{}, fmt.Errorf("failed to write category options: %w", err)
 	}
 
-	if pending.IsNewCategory {
+	showSuggestion := p.showsSuggestion(pending)
+	switch {
+	case !showSuggestion:
+		// Too weak to offer; the user picks from the full list
+	case pending.IsNewCategory:
 		if _, err := fmt.Fprintf(p.writer, "  [A] Create and use new category: %s\n", WarningStyle.Render(pending.SuggestedCategory)); err != nil {
 			return model.Classification{}, fmt.Errorf("failed to write new category option: %w", err)
 		}
-	} else {
+	default:
 		if _, err := fmt.Fprintf(p.writer, "  [A] Accept AI suggestion: %s\n", SuccessStyle.Render(pending.SuggestedCategory)); err != nil {
 			return model.Classification{}, fmt.Errorf("failed to write AI suggestion: %w", err)
 		}
@@ -91,6 +103,9 @@ func (p *Prompter) ConfirmClassification(ctx context.Context, pending model.Pend
 	}
 
 	var validChoices = []string{"a", "e", "s"}
+	if !showSuggestion {
+		validChoices = []string{"e", "s"}
+	}
 
 	choice, err := p.promptChoice(ctx, "Choice", validChoices)
 	if err != nil {
@@ -108,7 +123,7 @@ func (p *Prompter) ConfirmClassification(ctx context.Context, pending model.Pend
 		classification.Category = pending.SuggestedCategory
 		classification.Status = model.StatusClassifiedByAI
 		p.trackCategorization(pending.Transaction.MerchantName, pending.SuggestedCategory)
-		p.incrementStats(false, false)
+		p.incrementStats(pending.Transaction, pending.SuggestedCategory, false, false)
 		if pending.IsNewCategory {
 			if _, err := fmt.Fprintf(p.writer, FormatSuccess("✓ Will create new category: %s\n"), pending.SuggestedCategory); err != nil {
 				slog.Warn("Failed to write new category confirmation", "error", err)
@@ -116,15 +131,18 @@ func (p *Prompter) ConfirmClassification(ctx context.Context, pending model.Pend
 		}
 	case "e":
 		// Show all categories for selection
-		category, err := p.promptCategorySelection(ctx, pending.CategoryRankings, pending.AllCategories, pending.CheckPatterns)
+		category, request, err := p.promptCategorySelection(ctx, p.visibleRankings(pending), pending.AllCategories, pending.CheckPatterns)
 		if err != nil {
 			return model.Classification{}, err
 		}
 		classification.Category = category
 		classification.Status = model.StatusUserModified
 		classification.Confidence = 1.0
+		if request != nil {
+			request.applyTo(&classification)
+		}
 		p.trackCategorization(pending.Transaction.MerchantName, category)
-		p.incrementStats(true, false)
+		p.incrementStats(pending.Transaction, category, true, false)
 	case "s":
 		classification.Status = model.StatusUnclassified
 	}
@@ -159,12 +177,16 @@ func (p *Prompter) BatchConfirmClassifications(ctx context.Context, pending []mo
 		slog.Warn("Failed to write options prompt", "error", err)
 	}
 
-	if pending[0].IsNewCategory {
+	showSuggestion := p.showsSuggestion(pending[0])
+	switch {
+	case !showSuggestion:
+		// Too weak to offer; the user picks from the full list
+	case pending[0].IsNewCategory:
 		if _, err := fmt.Fprintf(p.writer, "  [A] Create and use new category '%s' for all %d transactions\n",
 			pending[0].SuggestedCategory, len(pending)); err != nil {
 			slog.Warn("Failed to write new category accept option", "error", err)
 		}
-	} else {
+	default:
 		if _, err := fmt.Fprintf(p.writer, "  [A] Accept for all %d transactions\n", len(pending)); err != nil {
 			slog.Warn("Failed to write batch accept option", "error", err)
 		}
@@ -185,6 +207,10 @@ func (p *Prompter) BatchConfirmClassifications(ctx context.Context, pending []mo
 
 	var validChoices = []string{"a", "e", "r", "s"}
 	var promptText = "Choice [A/E/R/S]"
+	if !showSuggestion {
+		validChoices = []string{"e", "r", "s"}
+		promptText = "Choice [E/R/S]"
+	}
 
 	choice, err := p.promptChoice(ctx, promptText, validChoices)
 	if err != nil {
@@ -211,6 +237,34 @@ func (p *Prompter) BatchConfirmClassifications(ctx context.Context, pending []mo
 	return nil, fmt.Errorf("invalid selection '%s'. Please choose from the available options", choice)
 }
 
+// ConfirmContinueReview asks whether to keep reviewing after a chunk of merchants.
+// Everything reviewed so far is already saved.
+func (p *Prompter) ConfirmContinueReview(ctx context.Context, reviewed, remaining int) (bool, error) {
+	message := fmt.Sprintf("Reviewed %d merchants, %d remaining. Progress is saved; stopping now lets you resume with another classify run.", reviewed, remaining)
+	if _, err := fmt.Fprintln(p.writer, "\n"+InfoStyle.Render(message)); err != nil {
+		return false, fmt.Errorf("failed to write review checkpoint: %w", err)
+	}
+
+	choice, err := p.promptChoice(ctx, "Continue reviewing? [y/n]", []string{"y", "n"})
+	if err != nil {
+		return false, err
+	}
+	return choice == "y", nil
+}
+
+// ReportCategoryError explains that the category chosen for a merchant couldn't
+// be created, before the merchant is shown again.
+func (p *Prompter) ReportCategoryError(_ context.Context, category string, err error) error {
+	message := fmt.Sprintf("Couldn't use category %q: %v", category, err)
+	if _, writeErr := fmt.Fprintln(p.writer, "\n"+FormatError(message)); writeErr != nil {
+		return fmt.Errorf("failed to write category error: %w", writeErr)
+	}
+	if _, writeErr := fmt.Fprintln(p.writer, FormatInfo("Pick an existing category or a different name for this merchant.")); writeErr != nil {
+		return fmt.Errorf("failed to write category error: %w", writeErr)
+	}
+	return nil
+}
+
 // GetCompletionStats returns statistics about the classification session.
 func (p *Prompter) GetCompletionStats() service.CompletionStats {
 	p.statsMutex.RLock()
@@ -221,6 +275,13 @@ func (p *Prompter) GetCompletionStats() service.CompletionStats {
 	return stats
 }
 
+// SetStatsFilter sets which transactions are left out of the completion stats.
+func (p *Prompter) SetStatsFilter(filter StatsFilter) {
+	p.statsMutex.Lock()
+	defer p.statsMutex.Unlock()
+	p.statsFilter = filter
+}
+
 // SetTotalTransactions sets the total number of transactions to be processed.
 func (p *Prompter) SetTotalTransactions(total int) {
 	p.totalTransactions = total
@@ -249,17 +310,21 @@ func (p *Prompter) ShowCompletion() {
 		AutoClassifiedPct float64 `json:"auto_classified_percent"`
 		UserClassified    int     `json:"user_classified"`
 		NewVendorRules    int     `json:"new_vendor_rules"`
+		Excluded          int     `json:"excluded,omitempty"`
 	}
 
 	data := completionJSON{
 		TotalTransactions: stats.TotalTransactions,
 		AutoClassified:    stats.AutoClassified,
-		AutoClassifiedPct: float64(stats.AutoClassified) / float64(stats.TotalTransactions) * 100,
 		UserClassified:    stats.UserClassified,
 		NewVendorRules:    stats.NewVendorRules,
+		Excluded:          stats.Excluded,
 		Duration:          stats.Duration.Round(time.Second).String(),
 		TimeSaved:         timeSaved,
 	}
+	if stats.TotalTransactions > 0 {
+		data.AutoClassifiedPct = float64(stats.AutoClassified) / float64(stats.TotalTransactions) * 100
+	}
 
 	bytes, err := json.Marshal(data)
 	if err != nil {
@@ -308,6 +373,33 @@ func (p *Prompter) updateProgressBy(count int) {
 	}
 }
 
+// SetMinSuggestionConfidence hides AI suggestions scoring below minConfidence:
+// instead of a weak guess to accept, the user picks from the category list,
+// which is then shown alphabetically without match scores. 0 always shows them.
+func (p *Prompter) SetMinSuggestionConfidence(minConfidence float64) {
+	p.minSuggestionConfidence = minConfidence
+}
+
+// SetExplain turns on learning mode: each review also shows the AI's reason
+// for its suggestion and the other categories it ranked, with their reasons.
+func (p *Prompter) SetExplain(explain bool) {
+	p.explain = explain
+}
+
+// showsSuggestion reports whether the AI suggestion is confident enough to offer.
+func (p *Prompter) showsSuggestion(pending model.PendingClassification) bool {
+	return pending.Confidence >= p.minSuggestionConfidence
+}
+
+// visibleRankings returns the rankings to order the category list by, or nil
+// when the suggestion is hidden so the list doesn't lead with the AI's guess.
+func (p *Prompter) visibleRankings(pending model.PendingClassification) model.CategoryRankings {
+	if !p.showsSuggestion(pending) {
+		return nil
+	}
+	return pending.CategoryRankings
+}
+
 func (p *Prompter) formatSingleTransaction(pending model.PendingClassification) string {
 	t := pending.Transaction
 
@@ -319,13 +411,16 @@ func (p *Prompter) formatSingleTransaction(pending model.PendingClassification)
 		fmt.Sprintf("  Description: %s\n", t.Name)
 
 	var suggestion string
-	if pending.IsNewCategory {
+	switch {
+	case !p.showsSuggestion(pending):
+		suggestion = fmt.Sprintf("\n%s No confident AI suggestion; choose a category", RobotIcon)
+	case pending.IsNewCategory:
 		suggestion = fmt.Sprintf("\n%s AI suggests NEW category: %s (%.0f%% confidence)",
 			RobotIcon,
 			WarningStyle.Render(pending.SuggestedCategory),
 			pending.Confidence*100)
 		suggestion += fmt.Sprintf("\n  %s This is a new category suggestion", InfoIcon)
-	} else {
+	default:
 		suggestion = fmt.Sprintf("\n%s AI Suggestion: %s (%.0f%% confidence)",
 			RobotIcon,
 			SuccessStyle.Render(pending.SuggestedCategory),
@@ -336,7 +431,43 @@ func (p *Prompter) formatSingleTransaction(pending model.PendingClassification)
 		suggestion += fmt.Sprintf("\n  %s Similar transactions: %d", InfoIcon, pending.SimilarCount)
 	}
 
-	return header + "\n\n" + details + suggestion
+	return header + "\n\n" + details + suggestion + p.formatExplanation(pending)
+}
+
+// formatExplanation describes how the AI ranked a transaction in learning
+// mode. It is empty unless explain is on and the suggestion is shown.
+func (p *Prompter) formatExplanation(pending model.PendingClassification) string {
+	if !p.explain || !p.showsSuggestion(pending) || len(pending.CategoryRankings) == 0 {
+		return ""
+	}
+
+	var explanation string
+	for _, ranking := range pending.CategoryRankings {
+		if ranking.Category == pending.SuggestedCategory && ranking.Reason != "" {
+			explanation += fmt.Sprintf("\n  Why: %s", ranking.Reason)
+			break
+		}
+	}
+
+	if len(pending.CategoryRankings) < 2 {
+		if explanation == "" {
+			explanation = fmt.Sprintf("\n  %s The AI gave no reason for this suggestion", InfoIcon)
+		}
+		return explanation
+	}
+
+	explanation += fmt.Sprintf("\n\n%s How the AI ranked it:", ChartIcon)
+	for i, ranking := range pending.CategoryRankings {
+		line := fmt.Sprintf("\n  %d. %s (%.0f%%)", i+1, ranking.Category, ranking.Score*100)
+		if ranking.IsNew {
+			line += " [new]"
+		}
+		if ranking.Reason != "" {
+			line += " - " + ranking.Reason
+		}
+		explanation += line
+	}
+	return explanation
 }
 
 func (p *Prompter) formatBatchSummary(pending []model.PendingClassification, pattern string) string {
@@ -366,12 +497,15 @@ func (p *Prompter) formatBatchSummary(pending []model.PendingClassification, pat
 			maxDate.Format("Jan 2, 2006"))
 
 	var suggestion string
-	if pending[0].IsNewCategory {
+	switch {
+	case !p.showsSuggestion(pending[0]):
+		suggestion = fmt.Sprintf("\n%s No confident AI suggestion; choose a category", RobotIcon)
+	case pending[0].IsNewCategory:
 		suggestion = fmt.Sprintf("\n%s AI suggests NEW category: %s",
 			RobotIcon,
 			WarningStyle.Render(suggestedCategory))
 		suggestion += fmt.Sprintf("\n%s This is a new category suggestion", InfoIcon)
-	} else {
+	default:
 		suggestion = fmt.Sprintf("\n%s AI suggests: %s",
 			RobotIcon,
 			SuccessStyle.Render(suggestedCategory))
@@ -381,6 +515,8 @@ func (p *Prompter) formatBatchSummary(pending []model.PendingClassification, pat
 		suggestion += fmt.Sprintf("\n%s Pattern detected: %s", CheckIcon, pattern)
 	}
 
+	suggestion += p.formatExplanation(pending[0])
+
 	samples := p.formatTransactionSamples(pending)
 
 	return header + summary + suggestion + samples
@@ -475,21 +611,20 @@ func (p *Prompter) promptChoice(ctx context.Context, prompt string, validChoices
 	}
 }
 
-func (p *Prompter) promptCategorySelection(ctx context.Context, rankings model.CategoryRankings, allCategories []model.Category, checkPatterns []model.CheckPattern) (string, error) {
+func (p *Prompter) promptCategorySelection(ctx context.Context, rankings model.CategoryRankings, allCategories []model.Category, checkPatterns []model.CheckPattern) (string, *newCategoryRequest, error) {
 	if _, err := fmt.Fprintln(p.writer); err != nil {
-		return "", fmt.Errorf("failed to write newline: %w", err)
+		return "", nil, fmt.Errorf("failed to write newline: %w", err)
 	}
 
 	if _, err := fmt.Fprintln(p.writer, FormatPrompt("Select category:")); err != nil {
-		return "", fmt.Errorf("failed to write selection header: %w", err)
+		return "", nil, fmt.Errorf("failed to write selection header: %w", err)
 	}
 	if _, err := fmt.Fprintln(p.writer); err != nil {
-		return "", fmt.Errorf("failed to write newline: %w", err)
+		return "", nil, fmt.Errorf("failed to write newline: %w", err)
 	}
 
 	// Build category number map and display options
-	categoryMap := make(map[string]string)  // number -> category name
-	categoryByName := make(map[string]bool) // for name-based selection
+	categoryMap := make(map[string]string) // number -> category name
 
 	// Create a map of rankings for quick lookup
 	rankingScores := make(map[string]float64)
@@ -551,7 +686,6 @@ func (p *Prompter) promptCategorySelection(ctx context.Context, rankings model.C
 
 		num := fmt.Sprintf("%d", i+1)
 		categoryMap[num] = cat.name
-		categoryByName[strings.ToLower(cat.name)] = true
 
 		// Build the display line
 		var line string
@@ -574,19 +708,19 @@ func (p *Prompter) promptCategorySelection(ctx context.Context, rankings model.C
 		}
 
 		if _, err := fmt.Fprintln(p.writer, line); err != nil {
-			return "", fmt.Errorf("failed to write category option: %w", err)
+			return "", nil, fmt.Errorf("failed to write category option: %w", err)
 		}
 
 		// Show description if available
 		if cat.description != "" && cat.score >= 0.01 {
 			if _, err := fmt.Fprintf(p.writer, "      %s\n",
 				SubtleStyle.Render(cat.description)); err != nil {
-				return "", fmt.Errorf("failed to write category description: %w", err)
+				return "", nil, fmt.Errorf("failed to write category description: %w", err)
 			}
 		}
 
 		if _, err := fmt.Fprintln(p.writer); err != nil {
-			return "", fmt.Errorf("failed to write spacing: %w", err)
+			return "", nil, fmt.Errorf("failed to write spacing: %w", err)
 		}
 	}
 
@@ -594,19 +728,19 @@ func (p *Prompter) promptCategorySelection(ctx context.Context, rankings model.C
 	if displayCount < len(displayCategories) {
 		if _, err := fmt.Fprintf(p.writer, "  [M] Show %d more categories\n",
 			len(displayCategories)-displayCount); err != nil {
-			return "", fmt.Errorf("failed to write show more option: %w", err)
+			return "", nil, fmt.Errorf("failed to write show more option: %w", err)
 		}
 		if _, err := fmt.Fprintln(p.writer); err != nil {
-			return "", fmt.Errorf("failed to write newline: %w", err)
+			return "", nil, fmt.Errorf("failed to write newline: %w", err)
 		}
 	}
 
 	// Add new category option
 	if _, err := fmt.Fprintln(p.writer, "  [N] Create new category"); err != nil {
-		return "", fmt.Errorf("failed to write new category option: %w", err)
+		return "", nil, fmt.Errorf("failed to write new category option: %w", err)
 	}
 	if _, err := fmt.Fprintln(p.writer); err != nil {
-		return "", fmt.Errorf("failed to write newline: %w", err)
+		return "", nil, fmt.Errorf("failed to write newline: %w", err)
 	}
 
 	showingAll := false
@@ -614,17 +748,17 @@ func (p *Prompter) promptCategorySelection(ctx context.Context, rankings model.C
 	for {
 		select {
 		case <-ctx.Done():
-			return "", ctx.Err()
+			return "", nil, ctx.Err()
 		default:
 		}
 
 		if _, err := fmt.Fprint(p.writer, FormatPrompt("Enter number or category name: ")); err != nil {
-			return "", fmt.Errorf("failed to write selection prompt: %w", err)
+			return "", nil, fmt.Errorf("failed to write selection prompt: %w", err)
 		}
 
 		input, err := p.reader.ReadString('\n')
 		if err != nil {
-			return "", err
+			return "", nil, err
 		}
 
 		choice := strings.TrimSpace(input)
@@ -641,7 +775,7 @@ func (p *Prompter) promptCategorySelection(ctx context.Context, rankings model.C
 			// Show all categories
 			showingAll = true
 			if _, err := fmt.Fprintln(p.writer); err != nil {
-				return "", fmt.Errorf("failed to write newline: %w", err)
+				return "", nil, fmt.Errorf("failed to write newline: %w", err)
 			}
 
 			// Display remaining categories
@@ -649,7 +783,6 @@ func (p *Prompter) promptCategorySelection(ctx context.Context, rankings model.C
 				cat := displayCategories[i]
 				num := fmt.Sprintf("%d", i+1)
 				categoryMap[num] = cat.name
-				categoryByName[strings.ToLower(cat.name)] = true
 
 				var line string
 				if cat.score >= 0.01 {
@@ -660,34 +793,56 @@ func (p *Prompter) promptCategorySelection(ctx context.Context, rankings model.C
 				}
 
 				if _, err := fmt.Fprintln(p.writer, line); err != nil {
-					return "", fmt.Errorf("failed to write category option: %w", err)
+					return "", nil, fmt.Errorf("failed to write category option: %w", err)
 				}
 			}
 
 			if _, err := fmt.Fprintln(p.writer); err != nil {
-				return "", fmt.Errorf("failed to write newline: %w", err)
+				return "", nil, fmt.Errorf("failed to write newline: %w", err)
 			}
 			continue
 		}
 
 		if lowerChoice == "n" {
 			// Prompt for new category name
-			return p.promptNewCategoryName(ctx)
+			request, err := p.promptNewCategoryName(ctx)
+			if err != nil {
+				return "", nil, err
+			}
+			return request.name, request, nil
 		}
 
 		// Check if it's a number selection
 		if category, ok := categoryMap[choice]; ok {
-			return category, nil
+			return category, nil, nil
 		}
 
-		// Check if it's a category name (case-insensitive)
-		if categoryByName[lowerChoice] {
-			// Find the exact category name
-			for _, ranking := range rankings {
-				if strings.ToLower(ranking.Category) == lowerChoice {
-					return ranking.Category, nil
+		// Check if it's a whole or partial category name (case-insensitive)
+		names := make([]string, len(displayCategories))
+		for i, cat := range displayCategories {
+			names[i] = cat.name
+		}
+		matches := matchCategoryNames(names, choice)
+		if len(matches) == 1 {
+			return names[matches[0]], nil, nil
+		}
+
+		if len(matches) > 1 {
+			// Narrow the list to the candidates and let the user pick again
+			if _, err := fmt.Fprintln(p.writer, FormatWarning(fmt.Sprintf("%d categories match %q:", len(matches), choice))); err != nil {
+				return "", nil, fmt.Errorf("failed to write ambiguous match header: %w", err)
+			}
+			for _, i := range matches {
+				num := fmt.Sprintf("%d", i+1)
+				categoryMap[num] = names[i]
+				if _, err := fmt.Fprintf(p.writer, "  [%s] %s\n", num, names[i]); err != nil {
+					return "", nil, fmt.Errorf("failed to write category option: %w", err)
 				}
 			}
+			if _, err := fmt.Fprintln(p.writer); err != nil {
+				return "", nil, fmt.Errorf("failed to write newline: %w", err)
+			}
+			continue
 		}
 
 		if _, err := fmt.Fprintln(p.writer, FormatError("Invalid selection. Please enter a number, category name, or 'N' for new category.")); err != nil {
@@ -696,9 +851,53 @@ func (p *Prompter) promptCategorySelection(ctx context.Context, rankings model.C
 	}
 }
 
-func (p *Prompter) promptNewCategoryName(ctx context.Context) (string, error) {
+// matchCategoryNames returns the indexes of names matching the typed text,
+// ignoring case. An exact match wins outright; otherwise names starting with
+// the text are preferred over names merely containing it.
+func matchCategoryNames(names []string, typed string) []int {
+	query := strings.ToLower(strings.TrimSpace(typed))
+	if query == "" {
+		return nil
+	}
+
+	var prefixMatches, substringMatches []int
+	for i, name := range names {
+		lower := strings.ToLower(name)
+		switch {
+		case lower == query:
+			return []int{i}
+		case strings.HasPrefix(lower, query):
+			prefixMatches = append(prefixMatches, i)
+		case strings.Contains(lower, query):
+			substringMatches = append(substringMatches, i)
+		}
+	}
+
+	if len(prefixMatches) > 0 {
+		return prefixMatches
+	}
+	return substringMatches
+}
+
+// newCategoryRequest holds what the user entered when creating a category mid-review.
+type newCategoryRequest struct {
+	name            string
+	description     string // Empty lets the AI generate one
+	categoryType    model.CategoryType
+	businessPercent int
+}
+
+// applyTo asks the engine to create the requested category before saving c.
+func (r *newCategoryRequest) applyTo(c *model.Classification) {
+	c.CreateNewCategory = true
+	c.NewCategoryDescription = r.description
+	c.NewCategoryType = r.categoryType
+	c.NewCategoryBusinessPercent = r.businessPercent
+}
+
+func (p *Prompter) promptNewCategoryName(ctx context.Context) (*newCategoryRequest, error) {
 	if _, err := fmt.Fprintln(p.writer); err != nil {
-		return "", fmt.Errorf("failed to write newline: %w", err)
+		return nil, fmt.Errorf("failed to write newline: %w", err)
 	}
 
 	// First, get the category name
@@ -706,17 +905,17 @@ func (p *Prompter) promptNewCategoryName(ctx context.Context) (string, error) {
 	for {
 		select {
 		case <-ctx.Done():
-			return "", ctx.Err()
+			return nil, ctx.Err()
 		default:
 		}
 
 		if _, err := fmt.Fprint(p.writer, FormatPrompt("Enter new category name: ")); err != nil {
-			return "", fmt.Errorf("failed to write new category prompt: %w", err)
+			return nil, fmt.Errorf("failed to write new category prompt: %w", err)
 		}
 
 		input, err := p.reader.ReadString('\n')
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 
 		categoryName = strings.TrimSpace(input)
@@ -731,46 +930,136 @@ func (p *Prompter) promptNewCategoryName(ctx context.Context) (string, error) {
 
 	// Then, ask about description
 	if _, err := fmt.Fprintln(p.writer); err != nil {
-		return "", fmt.Errorf("failed to write newline: %w", err)
+		return nil, fmt.Errorf("failed to write newline: %w", err)
 	}
 
 	if _, err := fmt.Fprintln(p.writer, FormatInfo("Would you like to add a description for this category?")); err != nil {
-		return "", fmt.Errorf("failed to write description prompt: %w", err)
+		return nil, fmt.Errorf("failed to write description prompt: %w", err)
 	}
 	if _, err := fmt.Fprintln(p.writer, "  [Y] Yes, I'll provide a description"); err != nil {
-		return "", fmt.Errorf("failed to write yes option: %w", err)
+		return nil, fmt.Errorf("failed to write yes option: %w", err)
 	}
 	if _, err := fmt.Fprintln(p.writer, "  [N] No, let AI generate one"); err != nil {
-		return "", fmt.Errorf("failed to write no option: %w", err)
+		return nil, fmt.Errorf("failed to write no option: %w", err)
 	}
 	if _, err := fmt.Fprintln(p.writer); err != nil {
-		return "", fmt.Errorf("failed to write newline: %w", err)
+		return nil, fmt.Errorf("failed to write newline: %w", err)
 	}
 
 	choice, err := p.promptChoice(ctx, "Choice [Y/N]", []string{"y", "n"})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
+	request := &newCategoryRequest{name: categoryName}
 	if choice == "y" {
 		// Get description from user
 		if _, err := fmt.Fprint(p.writer, FormatPrompt("Enter description: ")); err != nil {
-			return "", fmt.Errorf("failed to write description prompt: %w", err)
+			return nil, fmt.Errorf("failed to write description prompt: %w", err)
 		}
 
 		input, err := p.reader.ReadString('\n')
 		if err != nil {
-			return "", err
+			return nil, err
+		}
+
+		request.description = strings.TrimSpace(input)
+	}
+
+	if err := p.promptNewCategoryDetails(ctx, request); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// promptNewCategoryDetails asks for the new category's type and default business
+// percentage. Pressing enter keeps the defaults: an expense category that is 0% business.
+func (p *Prompter) promptNewCategoryDetails(ctx context.Context, request *newCategoryRequest) error {
+	request.categoryType = model.CategoryTypeExpense
+
+	if _, err := fmt.Fprintln(p.writer); err != nil {
+		return fmt.Errorf("failed to write newline: %w", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		if _, err := fmt.Fprint(p.writer, FormatPrompt("Category type [E]xpense/[I]ncome (enter for expense): ")); err != nil {
+			return fmt.Errorf("failed to write category type prompt: %w", err)
+		}
+
+		input, err := p.reader.ReadString('\n')
+		if err != nil {
+			return err
 		}
 
-		description := strings.TrimSpace(input)
-		// Return category name with description marker
-		// We'll use a special format to indicate it has a description
-		return categoryName + "|DESC|" + description, nil
+		switch strings.ToLower(strings.TrimSpace(input)) {
+		case "", "e", "expense":
+			request.categoryType = model.CategoryTypeExpense
+		case "i", "income":
+			request.categoryType = model.CategoryTypeIncome
+		default:
+			if _, err := fmt.Fprintln(p.writer, FormatError("Invalid type. Enter E for expense or I for income.")); err != nil {
+				slog.Warn("Failed to write invalid type error", "error", err)
+			}
+			continue
+		}
+		break
 	}
 
-	// Return just the category name, AI will generate description
-	return categoryName, nil
+	// Business deductions only apply to spending
+	if request.categoryType != model.CategoryTypeExpense {
+		return nil
+	}
+
+	if p.businessQuestionnaire {
+		percent, ok, err := p.AskBusinessUse(ctx, request.name)
+		if err != nil {
+			return err
+		}
+		if ok {
+			request.businessPercent = percent
+			return nil
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		if _, err := fmt.Fprint(p.writer, FormatPrompt("Default business % 0-100 (enter for 0): ")); err != nil {
+			return fmt.Errorf("failed to write business percent prompt: %w", err)
+		}
+
+		input, err := p.reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+
+		value := strings.TrimSuffix(strings.TrimSpace(input), "%")
+		if value == "" {
+			return nil
+		}
+
+		percent, err := strconv.Atoi(value)
+		if err != nil || percent < 0 || percent > 100 {
+			if _, err := fmt.Fprintln(p.writer, FormatError("Business percentage must be a whole number from 0 to 100.")); err != nil {
+				slog.Warn("Failed to write invalid percentage error", "error", err)
+			}
+			continue
+		}
+
+		request.businessPercent = percent
+		return nil
+	}
 }
 
 func (p *Prompter) acceptAllClassifications(pending []model.PendingClassification) ([]model.Classification, error) {
@@ -787,7 +1076,7 @@ func (p *Prompter) acceptAllClassifications(pending []model.PendingClassificatio
 		p.trackCategorization(pc.Transaction.MerchantName, pc.SuggestedCategory)
 	}
 
-	p.incrementBatchStats(len(pending), false, true)
+	p.incrementBatchStats(pending, pending[0].SuggestedCategory, false, true)
 	p.updateProgressBy(len(pending)) // Update progress by batch size
 	if _, err := fmt.Fprintln(p.writer, FormatSuccess(fmt.Sprintf("✓ Classified %d transactions as %s",
 		len(pending), pending[0].SuggestedCategory))); err != nil {
@@ -803,30 +1092,18 @@ func (p *Prompter) customCategoryForAll(ctx context.Context, pending []model.Pen
 	var allCategories []model.Category
 	var checkPatterns []model.CheckPattern
 	if len(pending) > 0 {
-		rankings = pending[0].CategoryRankings
+		rankings = p.visibleRankings(pending[0])
 		allCategories = pending[0].AllCategories
 		checkPatterns = pending[0].CheckPatterns
 	}
 
-	category, err := p.promptCategorySelection(ctx, rankings, allCategories, checkPatterns)
+	categoryName, request, err := p.promptCategorySelection(ctx, rankings, allCategories, checkPatterns)
 	if err != nil {
 		return nil, err
 	}
 
-	// Check if this is a new category with description
-	var categoryName string
-	var categoryDescription string
-	var isNewCategory bool
-
-	if strings.Contains(category, "|DESC|") {
-		parts := strings.Split(category, "|DESC|")
-		categoryName = parts[0]
-		if len(parts) > 1 {
-			categoryDescription = parts[1]
-		}
-		isNewCategory = true
-	} else {
-		categoryName = category
+	// Check if this is a new category
+	if request == nil {
 		// Check if this category already exists
 		categoryExists := false
 		for _, cat := range allCategories {
@@ -835,13 +1112,11 @@ func (p *Prompter) customCategoryForAll(ctx context.Context, pending []model.Pen
 				break
 			}
 		}
-		isNewCategory = !categoryExists
-
-		// Additional debug logging
-		if isNewCategory {
+		if !categoryExists {
 			slog.Debug("Category not found in existing categories",
 				"category", categoryName,
 				"existingCategoriesCount", len(allCategories))
+			request = &newCategoryRequest{name: categoryName}
 		}
 	}
 
@@ -855,25 +1130,14 @@ func (p *Prompter) customCategoryForAll(ctx context.Context, pending []model.Pen
 			Confidence:   1.0,
 			ClassifiedAt: time.Now(),
 		}
-		// Store metadata to indicate this is a new category that needs creation
-		if isNewCategory && i == 0 {
-			// Use notes field to pass the new category info
-			// This is a temporary way to signal the engine about the new category
-			if categoryDescription != "" {
-				classifications[i].Notes = fmt.Sprintf("NEW_CATEGORY|%s", categoryDescription)
-			} else {
-				classifications[i].Notes = "NEW_CATEGORY|"
-			}
-			slog.Debug("Setting new category signal",
-				"category", categoryName,
-				"description", categoryDescription,
-				"notes", classifications[i].Notes,
-				"isNewCategory", isNewCategory)
+		// Tell the engine this category needs to be created before saving
+		if request != nil {
+			request.applyTo(&classifications[i])
 		}
 		p.trackCategorization(pc.Transaction.MerchantName, categoryName)
 	}
 
-	p.incrementBatchStats(len(pending), true, true)
+	p.incrementBatchStats(pending, categoryName, true, true)
 	p.updateProgressBy(len(pending)) // Update progress by batch size
 	if _, err := fmt.Fprintln(p.writer, FormatSuccess(fmt.Sprintf("✓ Classified %d transactions as %s",
 		len(pending), categoryName))); err != nil {
@@ -926,10 +1190,19 @@ func (p *Prompter) skipAllClassifications(pending []model.PendingClassification)
 	return classifications, nil
 }
 
-func (p *Prompter) incrementStats(userModified bool, isVendorRule bool) {
+func (p *Prompter) incrementStats(txn model.Transaction, category string, userModified bool, isVendorRule bool) {
 	p.statsMutex.Lock()
 	defer p.statsMutex.Unlock()
 
+	if isVendorRule {
+		p.stats.NewVendorRules++
+	}
+
+	if p.statsFilter.Excludes(txn, category) {
+		p.stats.Excluded++
+		return
+	}
+
 	p.stats.TotalTransactions++
 
 	if userModified {
@@ -937,15 +1210,24 @@ func (p *Prompter) incrementStats(userModified bool, isVendorRule bool) {
 	} else {
 		p.stats.AutoClassified++
 	}
+}
+
+func (p *Prompter) incrementBatchStats(pending []model.PendingClassification, category string, userModified bool, isVendorRule bool) {
+	p.statsMutex.Lock()
+	defer p.statsMutex.Unlock()
 
 	if isVendorRule {
 		p.stats.NewVendorRules++
 	}
-}
 
-func (p *Prompter) incrementBatchStats(count int, userModified bool, isVendorRule bool) {
-	p.statsMutex.Lock()
-	defer p.statsMutex.Unlock()
+	count := 0
+	for _, pc := range pending {
+		if p.statsFilter.Excludes(pc.Transaction, category) {
+			p.stats.Excluded++
+			continue
+		}
+		count++
+	}
 
 	p.stats.TotalTransactions += count
 
@@ -954,10 +1236,6 @@ func (p *Prompter) incrementBatchStats(count int, userModified bool, isVendorRul
 	} else {
 		p.stats.AutoClassified += count
 	}
-
-	if isVendorRule {
-		p.stats.NewVendorRules++
-	}
 }
 
 func (p *Prompter) calculateTimeSaved(stats service.CompletionStats) string {
@@ -977,3 +1255,9 @@ func (p *Prompter) calculateTimeSaved(stats service.CompletionStats) string {
 
 // Ensure Prompter implements the engine.Prompter interface.
 var _ engine.Prompter = (*Prompter)(nil)
+
+// Ensure Prompter can pause chunked review sessions.
+var _ engine.ReviewChunkPrompter = (*Prompter)(nil)
+
+// Ensure Prompter explains why a merchant is reviewed again.
+var _ engine.CategoryErrorPrompter = (*Prompter)(nil)
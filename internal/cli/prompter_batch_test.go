@@ -41,12 +41,16 @@ func TestCLIPrompter_PromptBatchClassification(t *testing.T) {
 	}
 
 	tests := []struct {
-		name             string
-		input            string
-		expectedStatus   model.ClassificationStatus
-		expectedCategory string
-		expectedCount    int
-		expectError      bool
+		name                string
+		input               string
+		expectedStatus      model.ClassificationStatus
+		expectedCategory    string
+		expectedDescription string
+		expectedType        model.CategoryType
+		expectedCount       int
+		expectedBusinessPct int
+		expectError         bool
+		expectNewCategory   bool
 	}{
 		{
 			name:             "skip all transactions in batch",
@@ -63,11 +67,51 @@ func TestCLIPrompter_PromptBatchClassification(t *testing.T) {
 			expectedCategory: "Housing",
 		},
 		{
-			name:             "select category for all",
-			input:            "e\nn\nUtilities\nn\n", // Select category -> New -> "Utilities" -> No description
-			expectedCount:    2,
-			expectedStatus:   model.StatusUserModified,
-			expectedCategory: "Utilities",
+			name:              "select category for all",
+			input:             "e\nn\nUtilities\nn\n\n\n", // Select category -> New -> "Utilities" -> No description
+			expectedCount:     2,
+			expectedStatus:    model.StatusUserModified,
+			expectedCategory:  "Utilities",
+			expectedType:      model.CategoryTypeExpense,
+			expectNewCategory: true,
+		},
+		{
+			name:                "new category with description for all",
+			input:               "e\nn\nHobbies\ny\nCraft and hobby supplies\n\n\n",
+			expectedCount:       2,
+			expectedStatus:      model.StatusUserModified,
+			expectedCategory:    "Hobbies",
+			expectedDescription: "Craft and hobby supplies",
+			expectedType:        model.CategoryTypeExpense,
+			expectNewCategory:   true,
+		},
+		{
+			name:              "new income category",
+			input:             "e\nn\nRefunds\nn\ni\n", // Income categories skip the business % prompt
+			expectedCount:     2,
+			expectedStatus:    model.StatusUserModified,
+			expectedCategory:  "Refunds",
+			expectedType:      model.CategoryTypeIncome,
+			expectNewCategory: true,
+		},
+		{
+			name:                "new expense category with business percent",
+			input:               "e\nn\nSoftware\nn\ne\n150\n75%\n", // Out-of-range percent is re-prompted
+			expectedCount:       2,
+			expectedStatus:      model.StatusUserModified,
+			expectedCategory:    "Software",
+			expectedType:        model.CategoryTypeExpense,
+			expectedBusinessPct: 75,
+			expectNewCategory:   true,
+		},
+		{
+			name:              "invalid type is re-prompted",
+			input:             "e\nn\nConsulting\nn\nrevenue\nincome\n",
+			expectedCount:     2,
+			expectedStatus:    model.StatusUserModified,
+			expectedCategory:  "Consulting",
+			expectedType:      model.CategoryTypeIncome,
+			expectNewCategory: true,
 		},
 	}
 
@@ -94,6 +138,11 @@ func TestCLIPrompter_PromptBatchClassification(t *testing.T) {
 			for _, c := range classifications {
 				assert.Equal(t, tt.expectedStatus, c.Status)
 				assert.Equal(t, tt.expectedCategory, c.Category)
+				assert.Equal(t, tt.expectNewCategory, c.CreateNewCategory)
+				assert.Equal(t, tt.expectedDescription, c.NewCategoryDescription)
+				assert.Equal(t, tt.expectedType, c.NewCategoryType)
+				assert.Equal(t, tt.expectedBusinessPct, c.NewCategoryBusinessPercent)
+				assert.Empty(t, c.Notes, "new category intent must not leak into notes")
 
 				// For skip, verify category is explicitly empty
 				if tt.expectedStatus == model.StatusUnclassified {
@@ -130,7 +130,7 @@ func TestCLIPrompter_ConfirmClassification(t *testing.T) {
 				Confidence:        0.85,
 				CategoryRankings:  defaultRankings,
 			},
-			input:            "e\nn\n\nRestaurants\nn\n", // Select category, create new with empty name then valid, no description
+			input:            "e\nn\n\nRestaurants\nn\n\n\n", // Select category, create new with empty name then valid, no description
 			expectedStatus:   model.StatusUserModified,
 			expectedCategory: "Restaurants",
 		},
@@ -259,7 +259,7 @@ func TestCLIPrompter_BatchConfirmClassifications(t *testing.T) {
 		{
 			name:             "custom category for all",
 			pending:          createPendingBatch(3, "Amazon", "Shopping"),
-			input:            "e\nn\nOffice Supplies\nn\n", // Select category, create new "Office Supplies", no description
+			input:            "e\nn\nOffice Supplies\nn\n\n\n", // Select category, create new "Office Supplies", no description
 			expectedStatuses: repeatStatus(model.StatusUserModified, 3),
 			expectedCategory: "Office Supplies",
 		},
@@ -272,7 +272,7 @@ func TestCLIPrompter_BatchConfirmClassifications(t *testing.T) {
 		{
 			name:    "review each individually",
 			pending: createPendingBatch(3, "Target", "Shopping"),
-			input:   "r\na\ne\nn\nGroceries\nn\ns\n", // Review -> Accept first, Select category+New "Groceries" for second, Skip third
+			input:   "r\na\ne\nn\nGroceries\nn\n\n\ns\n", // Review -> Accept first, Select category+New "Groceries" for second, Skip third
 			expectedStatuses: []model.ClassificationStatus{
 				model.StatusClassifiedByAI,
 				model.StatusUserModified,
@@ -359,7 +359,7 @@ func TestCLIPrompter_CompletionStats(t *testing.T) {
 		{Category: "Other", Score: 0.1},
 	}
 
-	reader := strings.NewReader("a\ne\nn\nFood\nn\ns\n") // Accept, Select category+New "Food"+No description, Skip
+	reader := strings.NewReader("a\ne\nn\nFood\nn\n\n\ns\n") // Accept, Select category+New "Food"+No description, Skip
 	var output bytes.Buffer
 	prompter := NewCLIPrompter(reader, &output)
 	prompter.SetTotalTransactions(3)
@@ -668,7 +668,7 @@ func TestCLIPrompter_promptCategorySelection(t *testing.T) {
 		},
 		{
 			name:             "create new category",
-			input:            "n\nBusiness Expenses\nn\n", // Create new, enter name, no description
+			input:            "n\nBusiness Expenses\nn\n\n\n", // Create new, enter name, no description
 			rankings:         createTestRankings(),
 			expectedCategory: "Business Expenses",
 		},
@@ -693,7 +693,7 @@ func TestCLIPrompter_promptCategorySelection(t *testing.T) {
 		},
 		{
 			name:             "create new category with empty name then valid",
-			input:            "n\n\nTravel\nn\n", // Create new, empty name, then valid name, no description
+			input:            "n\n\nTravel\nn\n\n\n", // Create new, empty name, then valid name, no description
 			rankings:         createTestRankings(),
 			expectedCategory: "Travel",
 		},
@@ -720,7 +720,7 @@ func TestCLIPrompter_promptCategorySelection(t *testing.T) {
 
 			// Create empty category list for test
 			allCategories := []model.Category{}
-			category, err := prompter.promptCategorySelection(ctx, tt.rankings, allCategories, tt.checkPatterns)
+			category, _, err := prompter.promptCategorySelection(ctx, tt.rankings, allCategories, tt.checkPatterns)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -799,7 +799,7 @@ func TestCLIPrompter_promptCategorySelection_ManyCategories(t *testing.T) {
 
 			// Create empty category list for test
 			allCategories := []model.Category{}
-			category, err := prompter.promptCategorySelection(context.Background(), rankings, allCategories, nil)
+			category, _, err := prompter.promptCategorySelection(context.Background(), rankings, allCategories, nil)
 
 			require.NoError(t, err)
 			assert.Equal(t, tt.expectedCategory, category)
@@ -811,6 +811,87 @@ func TestCLIPrompter_promptCategorySelection_ManyCategories(t *testing.T) {
 	}
 }
 
+func TestCLIPrompter_promptCategorySelection_PartialNames(t *testing.T) {
+	rankings := model.CategoryRankings{
+		{Category: "Food & Dining", Score: 0.40},
+		{Category: "Transportation", Score: 0.20},
+		{Category: "Home Services", Score: 0.10},
+		{Category: "Healthcare", Score: 0.05},
+		{Category: "Personal Care", Score: 0.02},
+	}
+
+	tests := []struct {
+		name             string
+		input            string
+		expectedCategory string
+		expectedOutput   []string
+	}{
+		{
+			name:             "unique prefix",
+			input:            "trans\n",
+			expectedCategory: "Transportation",
+		},
+		{
+			name:             "unique substring",
+			input:            "dining\n",
+			expectedCategory: "Food & Dining",
+		},
+		{
+			name:             "prefix preferred over substring",
+			input:            "health\n", // Only a prefix of Healthcare, not of Personal Care
+			expectedCategory: "Healthcare",
+		},
+		{
+			name:             "ambiguous prefix then pick by number",
+			input:            "h\n4\n",
+			expectedCategory: "Healthcare",
+			expectedOutput:   []string{"2 categories match \"h\":", "[3] Home Services", "[4] Healthcare"},
+		},
+		{
+			name:             "ambiguous substring then narrow by name",
+			input:            "care\npers\n",
+			expectedCategory: "Personal Care",
+			expectedOutput:   []string{"2 categories match \"care\":"},
+		},
+		{
+			name:             "no match then valid number",
+			input:            "xyz\n1\n",
+			expectedCategory: "Food & Dining",
+			expectedOutput:   []string{"Invalid selection"},
+		},
+		{
+			name:             "numbers and new category still work",
+			input:            "n\nHobbies\nn\n\n\n",
+			expectedCategory: "Hobbies",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			prompter := NewCLIPrompter(strings.NewReader(tt.input), &output)
+
+			category, _, err := prompter.promptCategorySelection(context.Background(), rankings, nil, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCategory, category)
+			for _, want := range tt.expectedOutput {
+				assert.Contains(t, output.String(), want)
+			}
+		})
+	}
+}
+
+func TestMatchCategoryNames(t *testing.T) {
+	names := []string{"Groceries", "Gas & Fuel", "Gifts", "Office Supplies"}
+
+	assert.Equal(t, []int{0}, matchCategoryNames(names, "GROCERIES"), "exact match wins")
+	assert.Equal(t, []int{0}, matchCategoryNames(names, "gro"), "unique prefix")
+	assert.Equal(t, []int{0, 1, 2}, matchCategoryNames(names, "g"), "ambiguous prefix")
+	assert.Equal(t, []int{3}, matchCategoryNames(names, "supp"), "substring when no prefix matches")
+	assert.Empty(t, matchCategoryNames(names, "travel"))
+	assert.Empty(t, matchCategoryNames(names, "  "))
+}
+
 func TestCLIPrompter_ConfirmClassification_WithRankings(t *testing.T) {
 	// Test the enhanced UI with category rankings
 	rankings := model.CategoryRankings{
@@ -829,11 +910,12 @@ func TestCLIPrompter_ConfirmClassification_WithRankings(t *testing.T) {
 	}
 
 	tests := []struct {
-		name             string
-		input            string
-		expectedCategory string
-		expectedStatus   model.ClassificationStatus
-		pending          model.PendingClassification
+		name              string
+		input             string
+		expectedCategory  string
+		expectedStatus    model.ClassificationStatus
+		pending           model.PendingClassification
+		expectNewCategory bool
 	}{
 		{
 			name: "select existing category by number",
@@ -887,9 +969,10 @@ func TestCLIPrompter_ConfirmClassification_WithRankings(t *testing.T) {
 				CategoryRankings:  rankings,
 				CheckPatterns:     checkPatterns,
 			},
-			input:            "e\nn\nBusiness Services\nn\n", // Select category -> New -> Enter name -> No description
-			expectedCategory: "Business Services",
-			expectedStatus:   model.StatusUserModified,
+			input:             "e\nn\nBusiness Services\nn\n\n50\n", // Select category -> New -> Enter name -> No description -> Expense -> 50%
+			expectedCategory:  "Business Services",
+			expectedStatus:    model.StatusUserModified,
+			expectNewCategory: true,
 		},
 		{
 			name: "new category suggestion - use existing instead",
@@ -924,6 +1007,11 @@ func TestCLIPrompter_ConfirmClassification_WithRankings(t *testing.T) {
 			require.NoError(t, err)
 			assert.Equal(t, tt.expectedCategory, classification.Category)
 			assert.Equal(t, tt.expectedStatus, classification.Status)
+			assert.Equal(t, tt.expectNewCategory, classification.CreateNewCategory)
+			if tt.expectNewCategory {
+				assert.Equal(t, model.CategoryTypeExpense, classification.NewCategoryType)
+				assert.Equal(t, 50, classification.NewCategoryBusinessPercent)
+			}
 
 			// Verify enhanced UI elements appear in output
 			output := writer.String()
@@ -942,3 +1030,164 @@ func TestCLIPrompter_ConfirmClassification_WithRankings(t *testing.T) {
 		})
 	}
 }
+
+func TestCLIPrompter_ConfirmContinueReview(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		want        bool
+		expectError bool
+	}{
+		{name: "continue", input: "y\n", want: true},
+		{name: "stop", input: "n\n", want: false},
+		{name: "retries invalid input", input: "maybe\nY\n", want: true},
+		{name: "eof", input: "", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			prompter := NewCLIPrompter(strings.NewReader(tt.input), &output)
+
+			got, err := prompter.ConfirmContinueReview(context.Background(), 25, 175)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Contains(t, output.String(), "Reviewed 25 merchants, 175 remaining")
+		})
+	}
+}
+
+func TestCLIPrompter_MinSuggestionConfidence(t *testing.T) {
+	rankings := model.CategoryRankings{
+		{Category: "Shopping", Score: 0.2},
+		{Category: "Food & Dining", Score: 0.1},
+	}
+	allCategories := []model.Category{{Name: "Food & Dining"}, {Name: "Shopping"}}
+	pending := model.PendingClassification{
+		Transaction: model.Transaction{
+			ID:           "tx1",
+			Name:         "MYSTERY LLC",
+			MerchantName: "Mystery",
+			Amount:       42,
+			Date:         time.Now(),
+		},
+		SuggestedCategory: "Shopping",
+		Confidence:        0.2,
+		CategoryRankings:  rankings,
+		AllCategories:     allCategories,
+	}
+
+	t.Run("weak suggestion is hidden", func(t *testing.T) {
+		var output bytes.Buffer
+		// "a" is not offered, so it is rejected before the category is picked
+		prompter := NewCLIPrompter(strings.NewReader("a\ne\n1\n"), &output)
+		prompter.SetMinSuggestionConfidence(0.5)
+
+		classification, err := prompter.ConfirmClassification(context.Background(), pending)
+		require.NoError(t, err)
+
+		out := output.String()
+		assert.Contains(t, out, "No confident AI suggestion")
+		assert.NotContains(t, out, "Accept AI suggestion")
+		assert.NotContains(t, out, "% match", "the list is shown without scores")
+		// Alphabetical, so [1] is Food & Dining rather than the AI's pick
+		assert.Equal(t, "Food & Dining", classification.Category)
+		assert.Equal(t, model.StatusUserModified, classification.Status)
+	})
+
+	t.Run("confident suggestion is shown", func(t *testing.T) {
+		var output bytes.Buffer
+		prompter := NewCLIPrompter(strings.NewReader("a\n"), &output)
+		prompter.SetMinSuggestionConfidence(0.1)
+
+		classification, err := prompter.ConfirmClassification(context.Background(), pending)
+		require.NoError(t, err)
+		assert.Contains(t, output.String(), "Accept AI suggestion")
+		assert.Equal(t, "Shopping", classification.Category)
+	})
+
+	t.Run("batch review hides accept", func(t *testing.T) {
+		var output bytes.Buffer
+		prompter := NewCLIPrompter(strings.NewReader("s\n"), &output)
+		prompter.SetMinSuggestionConfidence(0.5)
+
+		classifications, err := prompter.BatchConfirmClassifications(context.Background(), []model.PendingClassification{pending})
+		require.NoError(t, err)
+		require.Len(t, classifications, 1)
+
+		out := output.String()
+		assert.Contains(t, out, "No confident AI suggestion")
+		assert.NotContains(t, out, "[A] Accept")
+		assert.Contains(t, out, "Choice [E/R/S]")
+	})
+}
+
+func TestCLIPrompter_Explain(t *testing.T) {
+	pending := model.PendingClassification{
+		Transaction: model.Transaction{
+			ID:           "tx1",
+			Name:         "WALMART SUPERCENTER",
+			MerchantName: "Walmart",
+			Amount:       156.78,
+			Date:         time.Now(),
+		},
+		SuggestedCategory: "Groceries",
+		Confidence:        0.8,
+		CategoryRankings: model.CategoryRankings{
+			{Category: "Groceries", Score: 0.8, Reason: "Supermarket with weekly grocery amounts"},
+			{Category: "Shopping", Score: 0.3, Reason: "Also sells general merchandise"},
+			{Category: "Home Goods", Score: 0.1, IsNew: true},
+		},
+		AllCategories: []model.Category{{Name: "Groceries"}, {Name: "Shopping"}},
+	}
+
+	t.Run("off by default", func(t *testing.T) {
+		var output bytes.Buffer
+		prompter := NewCLIPrompter(strings.NewReader("a\n"), &output)
+
+		_, err := prompter.ConfirmClassification(context.Background(), pending)
+		require.NoError(t, err)
+		assert.NotContains(t, output.String(), "Why:")
+		assert.NotContains(t, output.String(), "How the AI ranked it")
+	})
+
+	t.Run("single transaction", func(t *testing.T) {
+		var output bytes.Buffer
+		prompter := NewCLIPrompter(strings.NewReader("a\n"), &output)
+		prompter.SetExplain(true)
+
+		_, err := prompter.ConfirmClassification(context.Background(), pending)
+		require.NoError(t, err)
+
+		out := output.String()
+		assert.Contains(t, out, "Why: Supermarket with weekly grocery amounts")
+		assert.Contains(t, out, "How the AI ranked it")
+		assert.Contains(t, out, "2. Shopping (30%) - Also sells general merchandise")
+		assert.Contains(t, out, "3. Home Goods (10%) [new]")
+	})
+
+	t.Run("batch review", func(t *testing.T) {
+		var output bytes.Buffer
+		prompter := NewCLIPrompter(strings.NewReader("a\n"), &output)
+		prompter.SetExplain(true)
+
+		_, err := prompter.BatchConfirmClassifications(context.Background(), []model.PendingClassification{pending, pending})
+		require.NoError(t, err)
+		assert.Contains(t, output.String(), "Why: Supermarket with weekly grocery amounts")
+	})
+
+	t.Run("hidden suggestion is not explained", func(t *testing.T) {
+		var output bytes.Buffer
+		prompter := NewCLIPrompter(strings.NewReader("s\n"), &output)
+		prompter.SetExplain(true)
+		prompter.SetMinSuggestionConfidence(0.9)
+
+		_, err := prompter.ConfirmClassification(context.Background(), pending)
+		require.NoError(t, err)
+		assert.NotContains(t, output.String(), "Why:")
+	})
+}
@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
+)
+
+// reviewCSVHeader is the header row of a review queue export. Only
+// my_category and transaction_ids are read back on import; the other columns
+// are there to classify by.
+var reviewCSVHeader = []string{
+	"merchant", "transactions", "sample", "date", "amount", "direction",
+	"suggested_category", "confidence", "my_category", "transaction_ids",
+}
+
+// transactionIDSeparator joins the transaction IDs of a merchant in one cell.
+const transactionIDSeparator = ";"
+
+// ReviewRow is one merchant of the review queue.
+type ReviewRow struct {
+	Sample            model.Transaction // First transaction of the merchant
+	Merchant          string
+	SuggestedCategory string
+	MyCategory        string // The category chosen offline, empty when left undecided
+	TransactionIDs    []string
+	Confidence        float64
+}
+
+// ReviewExporter is a Prompter that collects the merchants needing review
+// instead of asking about them, so they can be classified offline in a
+// spreadsheet. Every merchant it sees is left unclassified.
+type ReviewExporter struct {
+	startTime time.Time
+	rows      []ReviewRow
+	mu        sync.Mutex
+}
+
+// NewReviewExporter creates an empty review exporter.
+func NewReviewExporter() *ReviewExporter {
+	return &ReviewExporter{startTime: time.Now()}
+}
+
+// ConfirmClassification is not supported; the review queue is only exported
+// one merchant at a time through BatchConfirmClassifications.
+func (r *ReviewExporter) ConfirmClassification(_ context.Context, _ model.PendingClassification) (model.Classification, error) {
+	return model.Classification{}, errors.New("review export only supports batch review")
+}
+
+// BatchConfirmClassifications records a merchant's pending classifications and
+// returns none, leaving its transactions unclassified.
+func (r *ReviewExporter) BatchConfirmClassifications(_ context.Context, pending []model.PendingClassification) ([]model.Classification, error) {
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	first := pending[0]
+	row := ReviewRow{
+		Merchant:          merchantDisplayName(first.Transaction),
+		Sample:            first.Transaction,
+		SuggestedCategory: first.SuggestedCategory,
+		Confidence:        first.Confidence,
+		TransactionIDs:    make([]string, 0, len(pending)),
+	}
+	for _, p := range pending {
+		row.TransactionIDs = append(row.TransactionIDs, p.Transaction.ID)
+	}
+
+	r.mu.Lock()
+	r.rows = append(r.rows, row)
+	r.mu.Unlock()
+
+	return nil, nil
+}
+
+// GetCompletionStats reports the exported transactions as the total; none are classified.
+func (r *ReviewExporter) GetCompletionStats() service.CompletionStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	total := 0
+	for _, row := range r.rows {
+		total += len(row.TransactionIDs)
+	}
+	return service.CompletionStats{
+		TotalTransactions: total,
+		Duration:          time.Since(r.startTime),
+	}
+}
+
+// Rows returns the merchants collected so far.
+func (r *ReviewExporter) Rows() []ReviewRow {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]ReviewRow(nil), r.rows...)
+}
+
+// WriteReviewCSV writes the review queue with a blank my_category column to fill in.
+func WriteReviewCSV(w io.Writer, rows []ReviewRow) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(reviewCSVHeader); err != nil {
+		return fmt.Errorf("failed to write review CSV header: %w", err)
+	}
+
+	for _, row := range rows {
+		record := []string{
+			row.Merchant,
+			strconv.Itoa(len(row.TransactionIDs)),
+			row.Sample.Name,
+			row.Sample.Date.Format("2006-01-02"),
+			strconv.FormatFloat(row.Sample.Amount, 'f', 2, 64),
+			string(row.Sample.Direction),
+			row.SuggestedCategory,
+			strconv.FormatFloat(row.Confidence, 'f', 2, 64),
+			row.MyCategory,
+			strings.Join(row.TransactionIDs, transactionIDSeparator),
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write review CSV row for %s: %w", row.Merchant, err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ReadReviewCSV reads a review queue export back. Columns are found by header
+// name, so they may be reordered or added to in the spreadsheet; only
+// my_category and transaction_ids are required.
+func ReadReviewCSV(r io.Reader) ([]ReviewRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // Spreadsheets drop trailing empty cells
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read review CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"my_category", "transaction_ids"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("review CSV is missing the %s column", required)
+		}
+	}
+
+	cell := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []ReviewRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read review CSV line %d: %w", line, err)
+		}
+
+		row := ReviewRow{
+			Merchant:          cell(record, "merchant"),
+			SuggestedCategory: cell(record, "suggested_category"),
+			MyCategory:        cell(record, "my_category"),
+		}
+		for _, id := range strings.Split(cell(record, "transaction_ids"), transactionIDSeparator) {
+			if id = strings.TrimSpace(id); id != "" {
+				row.TransactionIDs = append(row.TransactionIDs, id)
+			}
+		}
+		if row.MyCategory != "" && len(row.TransactionIDs) == 0 {
+			return nil, fmt.Errorf("review CSV line %d has a category but no transaction IDs", line)
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func merchantDisplayName(txn model.Transaction) string {
+	if txn.MerchantName != "" {
+		return txn.MerchantName
+	}
+	return txn.Name
+}
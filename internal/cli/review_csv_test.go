@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReviewExporter_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	exporter := NewReviewExporter()
+
+	date := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	pending := []model.PendingClassification{
+		{
+			Transaction:       model.Transaction{ID: "t1", Name: "ACME LLC 0042", MerchantName: "ACME LLC", Amount: 19.5, Date: date, Direction: model.DirectionExpense},
+			SuggestedCategory: "Shopping",
+			Confidence:        0.62,
+		},
+		{Transaction: model.Transaction{ID: "t2", MerchantName: "ACME LLC"}},
+	}
+
+	classifications, err := exporter.BatchConfirmClassifications(ctx, pending)
+	require.NoError(t, err)
+	assert.Empty(t, classifications, "exported merchants are left unclassified")
+	assert.Equal(t, 2, exporter.GetCompletionStats().TotalTransactions)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteReviewCSV(&buf, exporter.Rows()))
+	assert.Equal(t,
+		"merchant,transactions,sample,date,amount,direction,suggested_category,confidence,my_category,transaction_ids\n"+
+			"ACME LLC,2,ACME LLC 0042,2024-03-05,19.50,expense,Shopping,0.62,,t1;t2\n",
+		buf.String())
+
+	// Fill in my_category the way a spreadsheet would
+	edited := strings.Replace(buf.String(), "0.62,,t1;t2", "0.62, Hosting ,t1;t2", 1)
+	rows, err := ReadReviewCSV(strings.NewReader(edited))
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "ACME LLC", rows[0].Merchant)
+	assert.Equal(t, "Hosting", rows[0].MyCategory)
+	assert.Equal(t, []string{"t1", "t2"}, rows[0].TransactionIDs)
+}
+
+func TestReadReviewCSV(t *testing.T) {
+	t.Run("columns are found by name", func(t *testing.T) {
+		rows, err := ReadReviewCSV(strings.NewReader("\ufefftransaction_ids,notes,My_Category\nx;y,mine,Travel\nz\n"))
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		assert.Equal(t, ReviewRow{MyCategory: "Travel", TransactionIDs: []string{"x", "y"}}, rows[0])
+		assert.Equal(t, ReviewRow{TransactionIDs: []string{"z"}}, rows[1])
+	})
+
+	t.Run("missing required column", func(t *testing.T) {
+		_, err := ReadReviewCSV(strings.NewReader("merchant,my_category\nACME,Hosting\n"))
+		assert.ErrorContains(t, err, "transaction_ids")
+	})
+
+	t.Run("category without transactions", func(t *testing.T) {
+		_, err := ReadReviewCSV(strings.NewReader("my_category,transaction_ids\nHosting,\n"))
+		assert.ErrorContains(t, err, "line 2")
+	})
+}
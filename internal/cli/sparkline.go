@@ -0,0 +1,27 @@
+package cli
+
+import "strings"
+
+// sparkBlocks are the bar glyphs used by Sparkline, lowest first.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders values as a row of unicode bars scaled to the largest value.
+// Zero and negative values render as the lowest bar.
+func Sparkline(values []float64) string {
+	var maxValue float64
+	for _, v := range values {
+		if v > maxValue {
+			maxValue = v
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		idx := 0
+		if maxValue > 0 && v > 0 {
+			idx = int(v / maxValue * float64(len(sparkBlocks)-1))
+		}
+		b.WriteRune(sparkBlocks[idx])
+	}
+	return b.String()
+}
@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   string
+		values []float64
+	}{
+		{name: "empty", values: nil, want: ""},
+		{name: "all zero", values: []float64{0, 0, 0}, want: "▁▁▁"},
+		{name: "scaled to max", values: []float64{0, 50, 100}, want: "▁▄█"},
+		{name: "negative treated as zero", values: []float64{-20, 10}, want: "▁█"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Sparkline(tt.values))
+		})
+	}
+}
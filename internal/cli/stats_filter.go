@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+// StatsFilter leaves transactions that take no real categorization effort,
+// such as transfers between your own accounts, out of the completion stats
+// and the time saved estimate. They are still classified as usual.
+type StatsFilter struct {
+	Directions []model.TransactionDirection // Directions to exclude
+	Categories []string                     // Categories to exclude, matched case-insensitively
+	Refunds    bool                         // Exclude refunds
+}
+
+// ParseStatsFilter builds a StatsFilter from configuration values.
+func ParseStatsFilter(directions, categories []string, refunds bool) (StatsFilter, error) {
+	filter := StatsFilter{Categories: categories, Refunds: refunds}
+	for _, d := range directions {
+		direction := model.TransactionDirection(strings.ToLower(strings.TrimSpace(d)))
+		switch direction {
+		case model.DirectionIncome, model.DirectionExpense, model.DirectionTransfer:
+			filter.Directions = append(filter.Directions, direction)
+		default:
+			return StatsFilter{}, fmt.Errorf("invalid stats exclude direction %q (use income, expense or transfer)", d)
+		}
+	}
+	return filter, nil
+}
+
+// Excludes reports whether a transaction classified as category should be left out of the stats.
+func (f StatsFilter) Excludes(txn model.Transaction, category string) bool {
+	if f.Refunds && txn.IsRefund {
+		return true
+	}
+	for _, direction := range f.Directions {
+		if txn.Direction == direction {
+			return true
+		}
+	}
+	for _, excluded := range f.Categories {
+		if strings.EqualFold(excluded, category) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStatsFilter(t *testing.T) {
+	filter, err := ParseStatsFilter([]string{"Transfer", " income "}, []string{"Transfers"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, []model.TransactionDirection{model.DirectionTransfer, model.DirectionIncome}, filter.Directions)
+	assert.Equal(t, []string{"Transfers"}, filter.Categories)
+	assert.True(t, filter.Refunds)
+
+	_, err = ParseStatsFilter([]string{"sideways"}, nil, false)
+	assert.Error(t, err)
+}
+
+func TestStatsFilter_Excludes(t *testing.T) {
+	filter := StatsFilter{
+		Directions: []model.TransactionDirection{model.DirectionTransfer},
+		Categories: []string{"Credit Card Payments"},
+		Refunds:    true,
+	}
+
+	tests := []struct {
+		name     string
+		txn      model.Transaction
+		category string
+		want     bool
+	}{
+		{name: "transfer direction", txn: model.Transaction{Direction: model.DirectionTransfer}, category: "Savings", want: true},
+		{name: "excluded category ignores case", txn: model.Transaction{Direction: model.DirectionExpense}, category: "credit card payments", want: true},
+		{name: "refund", txn: model.Transaction{Direction: model.DirectionIncome, IsRefund: true}, category: "Shopping", want: true},
+		{name: "ordinary expense", txn: model.Transaction{Direction: model.DirectionExpense}, category: "Groceries", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, filter.Excludes(tt.txn, tt.category))
+		})
+	}
+
+	assert.False(t, StatsFilter{}.Excludes(model.Transaction{Direction: model.DirectionTransfer}, "Transfers"),
+		"the zero filter excludes nothing")
+}
+
+func TestPrompter_StatsFilterExcludesFromCompletionStats(t *testing.T) {
+	prompter := NewCLIPrompter(nil, nil)
+	prompter.SetStatsFilter(StatsFilter{Directions: []model.TransactionDirection{model.DirectionTransfer}})
+
+	pending := []model.PendingClassification{
+		{Transaction: model.Transaction{ID: "1", Direction: model.DirectionTransfer}},
+		{Transaction: model.Transaction{ID: "2", Direction: model.DirectionTransfer}},
+		{Transaction: model.Transaction{ID: "3", Direction: model.DirectionExpense}},
+	}
+	prompter.incrementBatchStats(pending, "Shopping", false, true)
+	prompter.incrementStats(model.Transaction{ID: "4", Direction: model.DirectionTransfer}, "Savings", true, false)
+	prompter.incrementStats(model.Transaction{ID: "5", Direction: model.DirectionExpense}, "Groceries", true, false)
+
+	stats := prompter.GetCompletionStats()
+	assert.Equal(t, 2, stats.TotalTransactions)
+	assert.Equal(t, 1, stats.AutoClassified)
+	assert.Equal(t, 1, stats.UserClassified)
+	assert.Equal(t, 3, stats.Excluded)
+	assert.Equal(t, 1, stats.NewVendorRules)
+	assert.Equal(t, "5 seconds", prompter.calculateTimeSaved(stats))
+}
@@ -17,6 +17,9 @@ var (
 	ErrMaxRetries = errors.New("max retries exceeded")
 )
 
+// MaxRetryAfter caps the wait a server can ask for with a Retry-After header.
+const MaxRetryAfter = 2 * time.Minute
+
 // RetryableError wraps an error with retry-specific metadata.
 type RetryableError struct {
 	Err       error
@@ -27,6 +30,26 @@ func (e *RetryableError) Error() string {
 	return e.Err.Error()
 }
 
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfterError is an error from a server that said how long to wait before
+// retrying, as with a Retry-After header. WithRetry waits that long instead of
+// its own backoff.
+type RetryAfterError struct {
+	Err   error
+	After time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
 // WithRetry executes an operation with configurable retry behavior.
 func WithRetry(ctx context.Context, operation func() error, opts service.RetryOptions) error {
 	if opts.MaxAttempts <= 0 {
@@ -65,16 +88,23 @@ func WithRetry(ctx context.Context, operation func() error, opts service.RetryOp
 			return fmt.Errorf("%w after %d attempts: %v", ErrMaxRetries, opts.MaxAttempts, err)
 		}
 
+		// The server knows best how long to wait
+		wait := delay
+		var retryAfterErr *RetryAfterError
+		if errors.As(err, &retryAfterErr) {
+			wait = min(max(retryAfterErr.After, 0), MaxRetryAfter)
+		}
+
 		slog.Warn("Operation failed, retrying",
 			"attempt", attempt,
 			"max_attempts", opts.MaxAttempts,
-			"delay", delay,
+			"delay", wait,
 			"error", err)
 
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case <-time.After(delay):
+		case <-time.After(wait):
 			// Exponential backoff with jitter
 			delay = time.Duration(float64(delay) * opts.Multiplier)
 			if delay > opts.MaxDelay {
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"os"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/notify"
+	"github.com/spf13/viper"
+)
+
+// LoadSMTPConfig loads the SMTP notifier configuration from Viper.
+// The boolean result is false when no SMTP host is configured, meaning email is
+// disabled. The password falls back to the SPICE_SMTP_PASSWORD environment variable.
+func LoadSMTPConfig() (notify.SMTPConfig, bool, error) {
+	host := viper.GetString("notify.smtp.host")
+	if host == "" {
+		return notify.SMTPConfig{}, false, nil
+	}
+
+	config := notify.SMTPConfig{
+		Host:          host,
+		Port:          viper.GetInt("notify.smtp.port"),
+		Username:      viper.GetString("notify.smtp.username"),
+		Password:      viper.GetString("notify.smtp.password"),
+		From:          viper.GetString("notify.smtp.from"),
+		To:            viper.GetStringSlice("notify.smtp.to"),
+		SubjectPrefix: viper.GetString("notify.smtp.subject_prefix"),
+	}
+
+	if config.Password == "" {
+		config.Password = os.Getenv("SPICE_SMTP_PASSWORD")
+	}
+
+	if err := config.Validate(); err != nil {
+		return notify.SMTPConfig{}, true, err
+	}
+
+	return config, true, nil
+}
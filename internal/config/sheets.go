@@ -3,6 +3,7 @@ package config
 
 import (
 	"os"
+	"strings"
 
 	"github.com/Veraticus/the-spice-must-flow/internal/sheets"
 	"github.com/spf13/viper"
@@ -35,6 +36,19 @@ func LoadSheetsConfig() (*sheets.Config, error) {
 	if v := viper.GetString("sheets.spreadsheet_name"); v != "" {
 		config.SpreadsheetName = v
 	}
+	if v := viper.GetInt("sheets.formatting_batch_size"); v != 0 {
+		config.FormattingBatchSize = v
+	}
+	if v := viper.GetInt("sheets.formatting_concurrency"); v != 0 {
+		config.FormattingConcurrency = v
+	}
+	if v := viper.GetInt("sheets.write_concurrency"); v != 0 {
+		config.WriteConcurrency = v
+	}
+	if viper.GetBool("sheets.incremental") {
+		config.Incremental = true
+	}
+	loadReportSettings(&config)
 
 	// Override with direct environment variables if not set
 	if config.ServiceAccountPath == "" {
@@ -67,3 +81,34 @@ func LoadSheetsConfig() (*sheets.Config, error) {
 
 	return &config, nil
 }
+
+// LoadReportConfig loads the settings that shape report data, like deductible
+// rounding and the sign convention, from the sheets section of the
+// configuration. Unlike LoadSheetsConfig it needs no Google credentials, so
+// report writers other than Google Sheets produce the same numbers.
+func LoadReportConfig() (*sheets.Config, error) {
+	config := sheets.DefaultConfig()
+	loadReportSettings(&config)
+
+	if err := config.ValidateReport(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// loadReportSettings reads the report settings from Viper into config.
+func loadReportSettings(config *sheets.Config) {
+	if v := viper.GetInt("sheets.aggregation_concurrency"); v != 0 {
+		config.AggregationConcurrency = v
+	}
+	if v := viper.GetString("sheets.deductible_rounding"); v != "" {
+		config.DeductibleRounding = sheets.DeductibleRounding(strings.ToLower(v))
+	}
+	if viper.GetBool("sheets.vendor_confidence") {
+		config.VendorConfidence = true
+	}
+	if v := viper.GetString("sheets.sign_convention"); v != "" {
+		config.SignConvention = sheets.SignConvention(strings.ToLower(v))
+	}
+}
@@ -0,0 +1,349 @@
+// Package csvreport writes reports as CSV files, an alternative to Google Sheets.
+package csvreport
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
+	"github.com/Veraticus/the-spice-must-flow/internal/sheets"
+	"github.com/shopspring/decimal"
+)
+
+// Report file names, one per Google Sheets tab.
+const (
+	ExpensesFile         = "expenses.csv"
+	IncomeFile           = "income.csv"
+	VendorSummaryFile    = "vendor_summary.csv"
+	CategorySummaryFile  = "category_summary.csv"
+	BusinessExpensesFile = "business_expenses.csv"
+	MonthlyFlowFile      = "monthly_flow.csv"
+	ForeignCurrencyFile  = "foreign_currency.csv"
+	VendorLookupFile     = "vendor_lookup.csv"
+	CategoryLookupFile   = "category_lookup.csv"
+	BusinessRulesFile    = "business_rules.csv"
+)
+
+// Writer implements the ReportWriter interface with one CSV file per report
+// tab in a directory. The numbers come from the same aggregation as the Google
+// Sheets report. Amounts are written with full decimal precision, and the
+// values the spreadsheet computes with formulas are written as values.
+type Writer struct {
+	logger *slog.Logger
+	dir    string
+	config sheets.Config
+}
+
+// NewWriter creates a CSV report writer for dir. Only the report settings of
+// config are used, like deductible rounding and the sign convention.
+func NewWriter(dir string, config sheets.Config, logger *slog.Logger) (*Writer, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("output directory is required")
+	}
+	if err := config.ValidateReport(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return &Writer{
+		dir:    dir,
+		config: config,
+		logger: logger,
+	}, nil
+}
+
+// Write implements the ReportWriter interface. The directory is created if
+// needed and existing report files are replaced. The foreign currency file is
+// only written when the report has foreign-currency transactions.
+func (w *Writer) Write(ctx context.Context, classifications []model.Classification, summary *service.ReportSummary, categories []model.Category) error {
+	w.logger.Info("starting CSV report generation",
+		"classifications", len(classifications),
+		"directory", w.dir)
+
+	data, err := sheets.Aggregate(classifications, summary, categories, w.config)
+	if err != nil {
+		return fmt.Errorf("failed to aggregate data: %w", err)
+	}
+
+	if err := os.MkdirAll(w.dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	files := []struct {
+		name string
+		rows [][]string
+	}{
+		{ExpensesFile, expenseRecords(data.Expenses)},
+		{IncomeFile, incomeRecords(data.Income)},
+		{VendorSummaryFile, vendorSummaryRecords(data.VendorSummary, w.config.VendorConfidence)},
+		{CategorySummaryFile, categorySummaryRecords(data.CategorySummary)},
+		{BusinessExpensesFile, w.businessExpenseRecords(data.BusinessExpenses)},
+		{MonthlyFlowFile, monthlyFlowRecords(data.MonthlyFlow)},
+		{VendorLookupFile, vendorLookupRecords(data.VendorLookup)},
+		{CategoryLookupFile, categoryLookupRecords(data.CategoryLookup)},
+		{BusinessRulesFile, businessRuleRecords(data.BusinessRulesLookup)},
+	}
+	if len(data.ForeignCurrency) > 0 {
+		files = append(files, struct {
+			name string
+			rows [][]string
+		}{ForeignCurrencyFile, foreignCurrencyRecords(data.ForeignCurrency, data.TotalFXGainLoss)})
+	}
+
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := writeFile(filepath.Join(w.dir, file.name), file.rows); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.name, err)
+		}
+	}
+
+	w.logger.Info("CSV report generation completed",
+		"directory", w.dir,
+		"files", len(files),
+		"total_income", data.TotalIncome,
+		"total_expenses", data.TotalExpenses,
+		"net_flow", data.TotalIncome.Sub(data.TotalExpenses))
+
+	return nil
+}
+
+// writeFile writes records to a CSV file at path, replacing it.
+func writeFile(path string, records [][]string) error {
+	file, err := os.Create(path) //nolint:gosec // Path is built from the chosen output directory
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(file)
+	if err := writer.WriteAll(records); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// amount formats a decimal with all of its digits.
+func amount(d decimal.Decimal) string {
+	return d.String()
+}
+
+func expenseRecords(expenses []sheets.ExpenseRow) [][]string {
+	records := [][]string{{"Date", "Amount", "Vendor", "Category", "Business %", "Notes"}}
+	for _, expense := range expenses {
+		records = append(records, []string{
+			expense.Date.Format("2006-01-02"),
+			amount(expense.Amount),
+			expense.Vendor,
+			expense.Category,
+			strconv.Itoa(expense.BusinessPct),
+			expense.Notes,
+		})
+	}
+	return records
+}
+
+func incomeRecords(income []sheets.IncomeRow) [][]string {
+	records := [][]string{{"Date", "Amount", "Source", "Category", "Notes"}}
+	for _, inc := range income {
+		records = append(records, []string{
+			inc.Date.Format("2006-01-02"),
+			amount(inc.Amount),
+			inc.Source,
+			inc.Category,
+			inc.Notes,
+		})
+	}
+	return records
+}
+
+// vendorSummaryRecords lists vendors. As in the spreadsheet, consistency is
+// n/a for a vendor with a single transaction.
+func vendorSummaryRecords(vendors []sheets.VendorSummaryRow, withConfidence bool) [][]string {
+	header := []string{"Vendor Name", "Category", "Total Amount", "Transaction Count"}
+	if withConfidence {
+		header = append(header, "Avg Confidence", "Consistency")
+	}
+
+	records := [][]string{header}
+	for _, vendor := range vendors {
+		record := []string{
+			vendor.VendorName,
+			vendor.AssociatedCategory,
+			amount(vendor.TotalAmount),
+			strconv.Itoa(vendor.TransactionCount),
+		}
+		if withConfidence {
+			consistency := "n/a"
+			if vendor.TransactionCount > 1 {
+				consistency = strconv.FormatFloat(vendor.Consistency, 'f', -1, 64)
+			}
+			record = append(record, strconv.FormatFloat(vendor.AverageConfidence, 'f', -1, 64), consistency)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// categorySummaryRecords lists income categories, then expense categories,
+// with their monthly totals. Income categories have no business percentage.
+func categorySummaryRecords(categories []sheets.CategorySummaryRow) [][]string {
+	records := [][]string{{
+		"Category", "Type", "Total Amount", "Count", "Avg Business %",
+		"Jan", "Feb", "Mar", "Apr", "May", "Jun",
+		"Jul", "Aug", "Sep", "Oct", "Nov", "Dec",
+	}}
+
+	var incomeCategories, expenseCategories []sheets.CategorySummaryRow
+	for _, cat := range categories {
+		if cat.Type == "Income" {
+			incomeCategories = append(incomeCategories, cat)
+		} else {
+			expenseCategories = append(expenseCategories, cat)
+		}
+	}
+
+	for _, group := range [][]sheets.CategorySummaryRow{incomeCategories, expenseCategories} {
+		for _, cat := range group {
+			businessPct := ""
+			if cat.Type != "Income" {
+				businessPct = strconv.Itoa(cat.BusinessPct)
+			}
+			record := []string{
+				cat.CategoryName,
+				cat.Type,
+				amount(cat.TotalAmount),
+				strconv.Itoa(cat.TransactionCount),
+				businessPct,
+			}
+			for _, monthly := range cat.MonthlyAmounts {
+				record = append(record, amount(monthly))
+			}
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// businessExpenseRecords lists business expenses by category, with a deductible
+// subtotal after each category and the grand total last, rounded like the
+// spreadsheet's.
+func (w *Writer) businessExpenseRecords(expenses []sheets.BusinessExpenseRow) [][]string {
+	records := [][]string{{"Date", "Vendor", "Category", "Amount", "Business %", "Deductible", "Notes"}}
+
+	subtotal := func(category string, total decimal.Decimal) []string {
+		return []string{"", "", fmt.Sprintf("Subtotal - %s", category), "", "", amount(w.config.RoundDeductibleTotal(total)), ""}
+	}
+
+	currentCategory := ""
+	categoryTotal := decimal.Zero
+	grandTotal := decimal.Zero
+	for _, expense := range expenses {
+		if expense.Category != currentCategory {
+			if currentCategory != "" && !categoryTotal.IsZero() {
+				records = append(records, subtotal(currentCategory, categoryTotal))
+			}
+			currentCategory = expense.Category
+			categoryTotal = decimal.Zero
+		}
+
+		records = append(records, []string{
+			expense.Date.Format("2006-01-02"),
+			expense.Vendor,
+			expense.Category,
+			amount(expense.OriginalAmount),
+			strconv.Itoa(expense.BusinessPct),
+			amount(expense.DeductibleAmount),
+			expense.Notes,
+		})
+		categoryTotal = categoryTotal.Add(expense.DeductibleAmount)
+		grandTotal = grandTotal.Add(expense.DeductibleAmount)
+	}
+	if currentCategory != "" && !categoryTotal.IsZero() {
+		records = append(records, subtotal(currentCategory, categoryTotal))
+	}
+
+	if !grandTotal.IsZero() {
+		records = append(records, []string{"", "", "GRAND TOTAL (Schedule C)", "", "", amount(w.config.RoundDeductibleTotal(grandTotal)), ""})
+	}
+	return records
+}
+
+// monthlyFlowRecords lists each month's cash flow, then the totals and the
+// monthly averages.
+func monthlyFlowRecords(monthlyFlow []sheets.MonthlyFlowRow) [][]string {
+	records := [][]string{{"Month", "Total Income", "Total Expenses", "Net Flow", "Running Balance"}}
+	if len(monthlyFlow) == 0 {
+		return records
+	}
+
+	// Sum net flow rather than derive it, since the columns may be signed
+	var totalIncome, totalExpenses, netFlow decimal.Decimal
+	for _, month := range monthlyFlow {
+		records = append(records, []string{
+			month.Month,
+			amount(month.TotalIncome),
+			amount(month.TotalExpenses),
+			amount(month.NetFlow),
+			amount(month.RunningBalance),
+		})
+		totalIncome = totalIncome.Add(month.TotalIncome)
+		totalExpenses = totalExpenses.Add(month.TotalExpenses)
+		netFlow = netFlow.Add(month.NetFlow)
+	}
+
+	monthCount := decimal.NewFromInt(int64(len(monthlyFlow)))
+	records = append(records,
+		[]string{"YEARLY TOTALS", amount(totalIncome), amount(totalExpenses), amount(netFlow), ""},
+		[]string{"MONTHLY AVERAGES", amount(totalIncome.Div(monthCount)), amount(totalExpenses.Div(monthCount)), amount(netFlow.Div(monthCount)), ""},
+	)
+	return records
+}
+
+func foreignCurrencyRecords(rows []sheets.ForeignCurrencyRow, totalGainLoss decimal.Decimal) [][]string {
+	records := [][]string{{"Date", "Vendor", "Category", "Currency", "Original Amount", "Posted Amount", "Effective Rate", "Average Rate", "FX Gain/Loss"}}
+	for _, row := range rows {
+		records = append(records, []string{
+			row.Date.Format("2006-01-02"),
+			row.Vendor,
+			row.Category,
+			row.OriginalCurrency,
+			amount(row.OriginalAmount),
+			amount(row.PostedAmount),
+			amount(row.EffectiveRate),
+			amount(row.AverageRate),
+			amount(row.FXGainLoss),
+		})
+	}
+	return append(records, []string{"TOTAL FX GAIN/LOSS", "", "", "", "", "", "", "", amount(totalGainLoss)})
+}
+
+func vendorLookupRecords(vendors []sheets.VendorLookupRow) [][]string {
+	records := [][]string{{"Vendor Name", "Category"}}
+	for _, vendor := range vendors {
+		records = append(records, []string{vendor.VendorName, vendor.Category})
+	}
+	return records
+}
+
+func categoryLookupRecords(categories []sheets.CategoryLookupRow) [][]string {
+	records := [][]string{{"Category Name", "Type", "Description", "Default Business %"}}
+	for _, cat := range categories {
+		records = append(records, []string{cat.CategoryName, cat.Type, cat.Description, strconv.Itoa(cat.DefaultBusinessPct)})
+	}
+	return records
+}
+
+func businessRuleRecords(rules []sheets.BusinessRuleLookupRow) [][]string {
+	records := [][]string{{"Vendor Pattern", "Category", "Business %", "Notes"}}
+	for _, rule := range rules {
+		records = append(records, []string{rule.VendorPattern, rule.Category, strconv.Itoa(rule.BusinessPct), rule.Notes})
+	}
+	return records
+}
@@ -0,0 +1,154 @@
+package csvreport
+
+import (
+	"context"
+	"encoding/csv"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
+	"github.com/Veraticus/the-spice-must-flow/internal/sheets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testReport() ([]model.Classification, *service.ReportSummary, []model.Category) {
+	classifications := []model.Classification{
+		{
+			Transaction: model.Transaction{ID: "1", Hash: "h1", Date: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), MerchantName: "Grocery Store", Amount: 50.10},
+			Category:    "Groceries",
+			Status:      model.StatusClassifiedByAI,
+			Confidence:  0.95,
+			Notes:       "Weekly, \"big\" shop",
+		},
+		{
+			Transaction:     model.Transaction{ID: "2", Hash: "h2", Date: time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC), MerchantName: "Gas Station", Amount: 40.05},
+			Category:        "Transportation",
+			Status:          model.StatusUserModified,
+			Confidence:      1.0,
+			BusinessPercent: 33,
+		},
+		{
+			Transaction: model.Transaction{ID: "3", Hash: "h3", Date: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), MerchantName: "Salary Deposit", Amount: 1000},
+			Category:    "Income",
+			Status:      model.StatusClassifiedByRule,
+			Confidence:  1.0,
+		},
+	}
+	summary := &service.ReportSummary{
+		DateRange: service.DateRange{
+			Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	categories := []model.Category{
+		{ID: 1, Name: "Groceries", Type: model.CategoryTypeExpense},
+		{ID: 2, Name: "Transportation", Type: model.CategoryTypeExpense, DefaultBusinessPercent: 50},
+		{ID: 3, Name: "Income", Type: model.CategoryTypeIncome},
+	}
+	return classifications, summary, categories
+}
+
+func readCSV(t *testing.T, path string) [][]string {
+	t.Helper()
+	file, err := os.Open(path) //nolint:gosec // Test file
+	require.NoError(t, err)
+	defer func() { _ = file.Close() }()
+	records, err := csv.NewReader(file).ReadAll()
+	require.NoError(t, err)
+	return records
+}
+
+func TestNewWriter(t *testing.T) {
+	logger := slog.Default()
+
+	_, err := NewWriter("", sheets.DefaultConfig(), logger)
+	require.Error(t, err)
+
+	config := sheets.DefaultConfig()
+	config.SignConvention = "sideways"
+	_, err = NewWriter(t.TempDir(), config, logger)
+	require.Error(t, err)
+
+	// Report settings are enough; no Sheets credentials are needed
+	_, err = NewWriter(t.TempDir(), sheets.DefaultConfig(), logger)
+	require.NoError(t, err)
+}
+
+func TestWriter_Write(t *testing.T) {
+	classifications, summary, categories := testReport()
+	dir := filepath.Join(t.TempDir(), "reports")
+
+	writer, err := NewWriter(dir, sheets.DefaultConfig(), slog.Default())
+	require.NoError(t, err)
+	require.NoError(t, writer.Write(context.Background(), classifications, summary, categories))
+
+	for _, name := range []string{ExpensesFile, IncomeFile, VendorSummaryFile, CategorySummaryFile, BusinessExpensesFile, MonthlyFlowFile, VendorLookupFile, CategoryLookupFile, BusinessRulesFile} {
+		assert.FileExists(t, filepath.Join(dir, name))
+	}
+	assert.NoFileExists(t, filepath.Join(dir, ForeignCurrencyFile), "no foreign-currency transactions")
+
+	expenses := readCSV(t, filepath.Join(dir, ExpensesFile))
+	require.Len(t, expenses, 3)
+	assert.Equal(t, []string{"Date", "Amount", "Vendor", "Category", "Business %", "Notes"}, expenses[0])
+	assert.Contains(t, expenses, []string{"2024-01-15", "50.1", "Grocery Store", "Groceries", "0", "Weekly, \"big\" shop"})
+
+	// The deductible amount keeps every digit: 33% of 40.05 is 13.2165
+	business := readCSV(t, filepath.Join(dir, BusinessExpensesFile))
+	require.Len(t, business, 4)
+	assert.Equal(t, []string{"2024-01-20", "Gas Station", "Transportation", "40.05", "33", "13.2165", ""}, business[1])
+	assert.Equal(t, "Subtotal - Transportation", business[2][2])
+	assert.Equal(t, "13.2165", business[2][5])
+	assert.Equal(t, "GRAND TOTAL (Schedule C)", business[3][2])
+
+	flow := readCSV(t, filepath.Join(dir, MonthlyFlowFile))
+	require.Len(t, flow, 5)
+	assert.Equal(t, []string{"YEARLY TOTALS", "1000", "90.15", "909.85", ""}, flow[3])
+	assert.Equal(t, []string{"MONTHLY AVERAGES", "500", "45.075", "454.925", ""}, flow[4])
+
+	categorySummary := readCSV(t, filepath.Join(dir, CategorySummaryFile))
+	require.Len(t, categorySummary, 4)
+	assert.Equal(t, "Income", categorySummary[1][0], "income categories come first")
+	assert.Empty(t, categorySummary[1][4], "income has no business percentage")
+	assert.Len(t, categorySummary[1], 17)
+}
+
+func TestWriter_Write_MatchesSheetsAggregation(t *testing.T) {
+	classifications, summary, categories := testReport()
+	config := sheets.DefaultConfig()
+	config.DeductibleRounding = sheets.DeductibleRoundingTotal
+	config.SignConvention = sheets.SignConventionExpensesNegative
+	config.VendorConfidence = true
+
+	data, err := sheets.Aggregate(classifications, summary, categories, config)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	writer, err := NewWriter(dir, config, slog.Default())
+	require.NoError(t, err)
+	require.NoError(t, writer.Write(context.Background(), classifications, summary, categories))
+
+	expenses := readCSV(t, filepath.Join(dir, ExpensesFile))
+	require.Len(t, expenses, len(data.Expenses)+1)
+	for i, expense := range data.Expenses {
+		assert.Equal(t, expense.Amount.String(), expenses[i+1][1])
+	}
+
+	business := readCSV(t, filepath.Join(dir, BusinessExpensesFile))
+	grandTotal := business[len(business)-1]
+	assert.Equal(t, "GRAND TOTAL (Schedule C)", grandTotal[2])
+	assert.Equal(t, data.TotalDeductible.Round(2).String(), grandTotal[5])
+
+	vendors := readCSV(t, filepath.Join(dir, VendorSummaryFile))
+	assert.Equal(t, []string{"Vendor Name", "Category", "Total Amount", "Transaction Count", "Avg Confidence", "Consistency"}, vendors[0])
+	assert.Equal(t, "n/a", vendors[1][5], "single-transaction vendors have no consistency")
+
+	flow := readCSV(t, filepath.Join(dir, MonthlyFlowFile))
+	for i, month := range data.MonthlyFlow {
+		assert.Equal(t, []string{month.Month, month.TotalIncome.String(), month.TotalExpenses.String(), month.NetFlow.String(), month.RunningBalance.String()}, flow[i+1])
+	}
+}
@@ -0,0 +1,49 @@
+package engine
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+// requireReview flags the results suggested for a category marked always
+// review, so they go to manual review however confident the suggestion and
+// whichever rule made it.
+func requireReview(results []BatchResult, categories []model.Category) {
+	review := make(map[string]bool)
+	for _, category := range categories {
+		if category.AlwaysReview {
+			review[strings.ToLower(category.Name)] = true
+		}
+	}
+	if len(review) == 0 {
+		return
+	}
+
+	for i := range results {
+		result := &results[i]
+		if result.Error != nil || result.Suggestion == nil || !review[strings.ToLower(result.Suggestion.Category)] {
+			continue
+		}
+		result.AlwaysReview = true
+		result.AutoAccepted = false
+		slog.Info("category always needs review",
+			"merchant", result.Merchant,
+			"category", result.Suggestion.Category,
+			"confidence", result.Suggestion.Score)
+	}
+}
+
+// withoutReviewOnly drops the results that must never be saved without a
+// review: categories that always need one, suggestions that failed
+// validation and rule matches the LLM didn't confirm.
+func withoutReviewOnly(results []BatchResult) []BatchResult {
+	kept := make([]BatchResult, 0, len(results))
+	for _, result := range results {
+		if !result.AlwaysReview && len(result.ValidationIssues) == 0 && result.RuleConfirmation.trusted() {
+			kept = append(kept, result)
+		}
+	}
+	return kept
+}
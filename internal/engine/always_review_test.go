@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyTransactionsBatch_AlwaysReview(t *testing.T) {
+	setup := func(t *testing.T) *storage.SQLiteStorage {
+		t.Helper()
+		ctx := context.Background()
+		db, err := storage.NewSQLiteStorage(":memory:")
+		require.NoError(t, err)
+		require.NoError(t, db.Migrate(ctx))
+		t.Cleanup(func() { _ = db.Close() })
+
+		medical, err := db.CreateCategory(ctx, "Medical", "")
+		require.NoError(t, err)
+		require.NoError(t, db.SetCategoryAlwaysReview(ctx, medical.ID, true))
+		_, err = db.CreateCategory(ctx, "Groceries", "")
+		require.NoError(t, err)
+
+		date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+		require.NoError(t, db.SaveTransactions(ctx, []model.Transaction{
+			{ID: "cvs", Hash: "hash-cvs", Name: "CVS PHARMACY", MerchantName: "CVS", Amount: 25, Type: "DEBIT", Direction: model.DirectionExpense, Date: date, AccountID: "acc1"},
+			{ID: "clinic", Hash: "hash-clinic", Name: "CITY CLINIC", MerchantName: "City Clinic", Amount: 150, Type: "DEBIT", Direction: model.DirectionExpense, Date: date, AccountID: "acc1"},
+			{ID: "food", Hash: "hash-food", Name: "WHOLE FOODS", MerchantName: "Whole Foods", Amount: 80, Type: "DEBIT", Direction: model.DirectionExpense, Date: date, AccountID: "acc1"},
+		}))
+		// The vendor rule would auto-accept CVS without asking the LLM
+		require.NoError(t, db.SaveVendor(ctx, &model.Vendor{Name: "CVS", Category: "Medical", Source: model.SourceManual, LastUpdated: time.Now()}))
+		return db
+	}
+	newClassifier := func() *MockClassifier {
+		classifier := NewMockClassifier()
+		classifier.SetBatchResponse(map[string]model.CategoryRankings{
+			"City Clinic": {{Category: "Medical", Score: 0.99}},
+			"Whole Foods": {{Category: "Groceries", Score: 0.99}},
+		})
+		return classifier
+	}
+	opts := BatchClassificationOptions{AutoAcceptThreshold: 0.95, BatchSize: 5, ParallelWorkers: 1}
+
+	t.Run("confident suggestions and vendor rules are reviewed", func(t *testing.T) {
+		ctx := context.Background()
+		db := setup(t)
+		prompter := NewMockPrompter(true)
+		engine := &ClassificationEngine{storage: db, classifier: newClassifier(), prompter: prompter}
+
+		summary, err := engine.ClassifyTransactionsBatch(ctx, nil, opts)
+		require.NoError(t, err)
+		assert.Equal(t, 1, summary.AutoAcceptedCount)
+		assert.Equal(t, 2, summary.NeedsReviewCount)
+
+		var reviewed []string
+		for _, call := range prompter.GetBatchConfirmCalls() {
+			for _, pending := range call.Pending {
+				reviewed = append(reviewed, pending.Transaction.ID)
+			}
+		}
+		assert.ElementsMatch(t, []string{"cvs", "clinic"}, reviewed)
+	})
+
+	t.Run("without review they stay unclassified", func(t *testing.T) {
+		ctx := context.Background()
+		db := setup(t)
+		skipOpts := opts
+		skipOpts.SkipManualReview = true
+		engine := &ClassificationEngine{storage: db, classifier: newClassifier(), prompter: NewMockPrompter(true)}
+
+		_, err := engine.ClassifyTransactionsBatch(ctx, nil, skipOpts)
+		require.NoError(t, err)
+
+		unclassified, err := db.GetTransactionsToClassify(ctx, nil)
+		require.NoError(t, err)
+		ids := make([]string, 0, len(unclassified))
+		for _, txn := range unclassified {
+			ids = append(ids, txn.ID)
+		}
+		assert.ElementsMatch(t, []string{"cvs", "clinic"}, ids)
+	})
+}
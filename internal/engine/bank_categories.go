@@ -0,0 +1,142 @@
+package engine
+
+import (
+	"strings"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+// BankCategorySeedConfidence is the confidence of classifications seeded from
+// a bank category. It's low enough for 'spice classify --rerank' to revisit
+// them.
+const BankCategorySeedConfidence = 0.5
+
+// MapBankCategory returns the category a bank-provided category path, like
+// ["Food and Drink", "Restaurants"], maps to. Levels are tried from the most
+// specific to the least; a level maps through mapping, keyed ignoring case,
+// or else matches a category name or alias. Mapped categories must exist.
+func MapBankCategory(bankCategory []string, categories []model.Category, mapping map[string]string) (string, bool) {
+	canonical := make(map[string]string, len(categories))
+	for _, cat := range categories {
+		canonical[strings.ToLower(cat.Name)] = cat.Name
+	}
+	for _, cat := range categories {
+		for _, alias := range cat.Aliases {
+			if _, exists := canonical[strings.ToLower(alias)]; !exists {
+				canonical[strings.ToLower(alias)] = cat.Name
+			}
+		}
+	}
+
+	lowerMapping := make(map[string]string, len(mapping))
+	for bank, category := range mapping {
+		lowerMapping[strings.ToLower(strings.TrimSpace(bank))] = category
+	}
+
+	for i := len(bankCategory) - 1; i >= 0; i-- {
+		level := strings.ToLower(strings.TrimSpace(bankCategory[i]))
+		if level == "" {
+			continue
+		}
+		if category, ok := lowerMapping[level]; ok {
+			if name, exists := canonical[strings.ToLower(category)]; exists {
+				return name, true
+			}
+			continue
+		}
+		if name, exists := canonical[level]; exists {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// bankCategoryOf returns the category most of txns' bank categories map to.
+func bankCategoryOf(txns []model.Transaction, categories []model.Category, mapping map[string]string) (string, bool) {
+	counts := make(map[string]int)
+	best := ""
+	for _, txn := range txns {
+		category, ok := MapBankCategory(txn.Category, categories, mapping)
+		if !ok {
+			continue
+		}
+		counts[category]++
+		if best == "" || counts[category] > counts[best] {
+			best = category
+		}
+	}
+	return best, best != ""
+}
+
+// applyBankCategoryPrior raises the score of the category a merchant's bank
+// categories map to by opts.BankCategoryWeight, adding it to the rankings if
+// the LLM didn't rank it. The bank's category is a weak hint, so the weight
+// should be small.
+func applyBankCategoryPrior(rankings model.CategoryRankings, txns []model.Transaction, categories []model.Category, opts BatchClassificationOptions) model.CategoryRankings {
+	if opts.BankCategoryWeight <= 0 {
+		return rankings
+	}
+	category, ok := bankCategoryOf(txns, categories, opts.BankCategoryMapping)
+	if !ok {
+		return rankings
+	}
+
+	boosted := make(model.CategoryRankings, len(rankings), len(rankings)+1)
+	copy(boosted, rankings)
+	found := false
+	for i := range boosted {
+		if boosted[i].Category == category && !boosted[i].IsNew {
+			boosted[i].Score = min(1.0, boosted[i].Score+opts.BankCategoryWeight)
+			found = true
+		}
+	}
+	if !found {
+		boosted = append(boosted, model.CategoryRanking{Category: category, Score: min(1.0, opts.BankCategoryWeight)})
+	}
+	boosted.Sort()
+	return boosted
+}
+
+// BankCategoryDisagreement is a classified transaction whose category differs
+// from the one its bank category maps to.
+type BankCategoryDisagreement struct {
+	Classification model.Classification
+	BankCategory   string // The bank's category path, levels joined with " > "
+	Mapped         string // The category the bank category maps to
+}
+
+// BankCategoryReport compares classifications with the bank's categories.
+type BankCategoryReport struct {
+	Disagreements  []BankCategoryDisagreement
+	Agreements     int // Classifications matching their bank category
+	Unmapped       int // Classifications whose bank category maps to no category
+	NoBankCategory int // Classifications of transactions without a bank category
+}
+
+// ReconcileBankCategories compares classifications with the categories their
+// transactions' bank categories map to.
+func ReconcileBankCategories(classifications []model.Classification, categories []model.Category, mapping map[string]string) BankCategoryReport {
+	var report BankCategoryReport
+	for _, classification := range classifications {
+		bankCategory := classification.Transaction.Category
+		if len(bankCategory) == 0 {
+			report.NoBankCategory++
+			continue
+		}
+
+		mapped, ok := MapBankCategory(bankCategory, categories, mapping)
+		switch {
+		case !ok:
+			report.Unmapped++
+		case strings.EqualFold(mapped, classification.Category):
+			report.Agreements++
+		default:
+			report.Disagreements = append(report.Disagreements, BankCategoryDisagreement{
+				Classification: classification,
+				BankCategory:   strings.Join(bankCategory, " > "),
+				Mapped:         mapped,
+			})
+		}
+	}
+	return report
+}
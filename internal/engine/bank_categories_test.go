@@ -0,0 +1,113 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapBankCategory(t *testing.T) {
+	categories := []model.Category{
+		{Name: "Dining", Aliases: []string{"Restaurants"}},
+		{Name: "Groceries"},
+		{Name: "Travel"},
+	}
+	mapping := map[string]string{
+		"Food and Drink": "Dining",
+		"Supermarkets":   "groceries",
+		"Airlines":       "Flights", // No such category
+		" Coffee Shop ":  "Dining",
+	}
+
+	tests := []struct {
+		name         string
+		want         string
+		bankCategory []string
+		wantOK       bool
+	}{
+		{name: "most specific level wins", bankCategory: []string{"Food and Drink", "Supermarkets"}, want: "Groceries", wantOK: true},
+		{name: "falls back to a less specific level", bankCategory: []string{"Food and Drink", "Bakeries"}, want: "Dining", wantOK: true},
+		{name: "mapping ignores case and whitespace", bankCategory: []string{"coffee shop"}, want: "Dining", wantOK: true},
+		{name: "matches a category name", bankCategory: []string{"travel"}, want: "Travel", wantOK: true},
+		{name: "matches an alias", bankCategory: []string{"Shops", "Restaurants"}, want: "Dining", wantOK: true},
+		{name: "mapping to a missing category is skipped", bankCategory: []string{"Travel", "Airlines"}, want: "Travel", wantOK: true},
+		{name: "unmapped", bankCategory: []string{"Shops", "Hardware"}, wantOK: false},
+		{name: "empty", bankCategory: nil, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := MapBankCategory(tt.bankCategory, categories, mapping)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestApplyBankCategoryPrior(t *testing.T) {
+	categories := []model.Category{{Name: "Dining"}, {Name: "Groceries"}, {Name: "Shopping"}}
+	txns := []model.Transaction{
+		{ID: "1", Category: []string{"Food and Drink", "Groceries"}},
+		{ID: "2", Category: []string{"Food and Drink", "Groceries"}},
+		{ID: "3", Category: []string{"Food and Drink", "Restaurants"}},
+	}
+	rankings := model.CategoryRankings{
+		{Category: "Shopping", Score: 0.62},
+		{Category: "Groceries", Score: 0.58},
+	}
+	opts := BatchClassificationOptions{BankCategoryMapping: map[string]string{"Restaurants": "Dining"}}
+
+	t.Run("disabled without a weight", func(t *testing.T) {
+		assert.Equal(t, rankings, applyBankCategoryPrior(rankings, txns, categories, opts))
+	})
+
+	opts.BankCategoryWeight = 0.1
+
+	t.Run("boosts the majority bank category", func(t *testing.T) {
+		boosted := applyBankCategoryPrior(rankings, txns, categories, opts)
+		require.Len(t, boosted, 2)
+		assert.Equal(t, "Groceries", boosted[0].Category)
+		assert.InDelta(t, 0.68, boosted[0].Score, 0.0001)
+		assert.InDelta(t, 0.58, rankings[1].Score, 0.0001, "input rankings must not change")
+	})
+
+	t.Run("adds an unranked category", func(t *testing.T) {
+		boosted := applyBankCategoryPrior(model.CategoryRankings{{Category: "Shopping", Score: 0.9}}, txns, categories, opts)
+		require.Len(t, boosted, 2)
+		assert.Equal(t, "Groceries", boosted[1].Category)
+		assert.InDelta(t, 0.1, boosted[1].Score, 0.0001)
+	})
+
+	t.Run("scores are capped", func(t *testing.T) {
+		boosted := applyBankCategoryPrior(model.CategoryRankings{{Category: "Groceries", Score: 0.95}}, txns, categories, opts)
+		assert.InDelta(t, 1.0, boosted[0].Score, 0.0001)
+	})
+
+	t.Run("no bank category", func(t *testing.T) {
+		boosted := applyBankCategoryPrior(rankings, []model.Transaction{{ID: "4"}}, categories, opts)
+		assert.Equal(t, rankings, boosted)
+	})
+}
+
+func TestReconcileBankCategories(t *testing.T) {
+	categories := []model.Category{{Name: "Dining"}, {Name: "Groceries"}}
+	mapping := map[string]string{"Restaurants": "Dining", "Supermarkets": "Groceries"}
+	classifications := []model.Classification{
+		{Category: "Dining", Transaction: model.Transaction{ID: "1", Category: []string{"Food and Drink", "Restaurants"}}},
+		{Category: "groceries", Transaction: model.Transaction{ID: "2", Category: []string{"Shops", "Supermarkets"}}},
+		{Category: "Dining", Transaction: model.Transaction{ID: "3", Category: []string{"Shops", "Supermarkets"}}},
+		{Category: "Dining", Transaction: model.Transaction{ID: "4", Category: []string{"Shops", "Hardware"}}},
+		{Category: "Dining", Transaction: model.Transaction{ID: "5"}},
+	}
+
+	report := ReconcileBankCategories(classifications, categories, mapping)
+	assert.Equal(t, 2, report.Agreements)
+	assert.Equal(t, 1, report.Unmapped)
+	assert.Equal(t, 1, report.NoBankCategory)
+	require.Len(t, report.Disagreements, 1)
+	assert.Equal(t, "3", report.Disagreements[0].Classification.Transaction.ID)
+	assert.Equal(t, "Shops > Supermarkets", report.Disagreements[0].BankCategory)
+	assert.Equal(t, "Groceries", report.Disagreements[0].Mapped)
+}
@@ -13,33 +13,124 @@ import (
 
 	"github.com/Veraticus/the-spice-must-flow/internal/llm"
 	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
 	"github.com/Veraticus/the-spice-must-flow/internal/storage"
 )
 
 // BatchClassificationOptions configures batch classification behavior.
 type BatchClassificationOptions struct {
-	AutoAcceptThreshold float64 // Confidence threshold for auto-acceptance (0.0-1.0)
-	BatchSize           int     // Number of merchants to process in each LLM batch
-	ParallelWorkers     int     // Number of parallel workers
-	SkipManualReview    bool    // Skip manual review of low-confidence items
+	AutoAcceptThreshold float64        // Confidence threshold for auto-acceptance (0.0-1.0)
+	BatchSize           int            // Number of merchants to process in each LLM batch
+	ParallelWorkers     int            // Number of parallel workers
+	MaxInflightLLM      int            // Concurrent LLM batch calls; 0 means ParallelWorkers
+	SkipManualReview    bool           // Skip manual review of low-confidence items
+	DryRun              bool           // Classify without saving or prompting for review
+	ReviewNewMerchants  bool           // Send AI suggestions for never-classified merchants to review
+	ReviewChunkSize     int            // Merchants per review chunk, with a chance to stop between chunks; 0 disables
+	CategoryRetries     int            // Times a merchant is reviewed again when its new category can't be created; 0 skips it
+	ReviewCommitEvery   int            // Reviewed transactions saved per commit; 0 commits after every merchant
+	SaveBatchSize       int            // Auto-accepted transactions saved per commit; 0 means DefaultSaveBatchSize, 1 saves them one by one
+	SampleStrategy      SampleStrategy // How to pick the transactions shown to the LLM; empty means SampleFirst
+	SampleCount         int            // Transactions shown to the LLM per merchant; below 1 means 1
+	RefundWindowDays    int            // Days before a refund to look for the purchase it reverses; 0 disables
+	StopOnError         bool           // Cancel remaining merchants and return the first merchant error
+	MaxGroupSize        int            // Split merchants with more transactions into amount bands; 0 disables
+	UntilDate           *time.Time     // Last transaction date to classify; nil leaves the range open
+	// RecordCalibration keeps the AI's suggestion and confidence in the
+	// classification history when review changes a merchant's category, so
+	// calibration reports count the AI's misses as well as its hits.
+	RecordCalibration bool
+	// GroupConfidence decides which confidence auto-accepts a merchant group; empty means AggregateTop.
+	GroupConfidence ConfidenceAggregation
+	// Validate sends auto-accept candidates that fail a sanity check to review.
+	Validate bool
+	// ValidationAmountMultiple is the multiple of a category's average amount that
+	// fails validation; 0 means DefaultValidationAmountMultiple.
+	ValidationAmountMultiple float64
+	// ConfirmRules asks the LLM about merchants matched by a pattern rule, vendor
+	// rule or check pattern, and only auto-accepts them when it agrees.
+	ConfirmRules bool
+	// VendorRuleDecay lowers the confidence of vendor rules that haven't been
+	// updated in a while; the zero value keeps them at 1.0.
+	VendorRuleDecay RuleDecay
+	// EscalateBelow files merchants whose best suggestion scores below it under
+	// EscalateCategory instead of reviewing them; 0 disables.
+	EscalateBelow float64
+	// EscalateCategory is the category for escalated merchants; empty means DefaultEscalateCategory.
+	EscalateCategory string
+	// FallbackExpenseCategory and FallbackIncomeCategory file the transactions
+	// of merchants that fail to classify by direction, so they aren't left
+	// unclassified. Transactions without a direction count as expenses and
+	// transfers are left alone. Empty disables the fallback for that direction.
+	FallbackExpenseCategory string
+	FallbackIncomeCategory  string
+	// CatchAllCategory is the generic category counted in the summary; empty
+	// means DefaultCatchAllCategory.
+	CatchAllCategory string
+	// CatchAllMaxConfidence caps LLM suggestions of CatchAllCategory; capped
+	// suggestions always go to review. 0 disables the cap.
+	CatchAllMaxConfidence float64
+	// ResultCollector, if set, receives every merchant result (including failures).
+	ResultCollector func(BatchResult)
+	// OnProgress, if set, is called as each merchant group finishes
+	// classifying, with how many of the run's groups are done so far. It may
+	// be nil. Calls come from the parallel workers but never overlap, and done
+	// only increases.
+	OnProgress func(done, total int, merchant string)
+	// Decisions, if set, logs the source and outcome of every merchant.
+	Decisions *DecisionLog
+	// PreviousGuesses holds an earlier low-confidence classification per merchant
+	// group. The LLM is shown it and asked to reconsider; used when re-ranking.
+	PreviousGuesses map[string]model.CategoryRanking
+	// Explain keeps every category the LLM ranked, with its reasons, so review
+	// can show why a category was suggested.
+	Explain bool
+	// BankCategoryWeight is added to the LLM's score for the category a
+	// merchant's bank-provided categories map to, as a weak prior; 0 disables.
+	BankCategoryWeight float64
+	// BankCategoryMapping maps bank category levels onto categories; see MapBankCategory.
+	BankCategoryMapping map[string]string
+
+	// llmSlots bounds concurrent LLM batch calls; nil means unbounded.
+	llmSlots chan struct{}
+	// progress counts finished merchant groups for OnProgress; nil means no reporting.
+	progress *batchProgress
 }
 
+// DefaultSaveBatchSize is how many auto-accepted classifications are saved
+// per storage transaction by default.
+const DefaultSaveBatchSize = 500
+
+// DefaultCategoryRetries is how many times a merchant is reviewed again when
+// the new category chosen for it can't be created.
+const DefaultCategoryRetries = 3
+
+// ErrCategoryCaseMismatch is returned when a new category differs from an
+// existing one only in case.
+var ErrCategoryCaseMismatch = errors.New("a category with this name already exists")
+
 // DefaultBatchOptions returns sensible defaults.
 func DefaultBatchOptions() BatchClassificationOptions {
 	return BatchClassificationOptions{
 		AutoAcceptThreshold: 0.95,
 		BatchSize:           5,
 		ParallelWorkers:     2,
+		CategoryRetries:     DefaultCategoryRetries,
 	}
 }
 
 // RerankOptions configures re-ranking behavior for low confidence classifications.
 type RerankOptions struct {
-	ConfidenceThreshold float64 // Max confidence to consider for re-ranking
-	AutoAcceptThreshold float64 // Confidence threshold for auto-acceptance
-	BatchSize           int     // Number of merchants to process in each LLM batch
-	ParallelWorkers     int     // Number of parallel workers
-	SkipManualReview    bool    // Skip manual review of low-confidence items
+	ConfidenceThreshold float64      // Max confidence to consider for re-ranking
+	AutoAcceptThreshold float64      // Confidence threshold for auto-acceptance
+	BatchSize           int          // Number of merchants to process in each LLM batch
+	ParallelWorkers     int          // Number of parallel workers
+	MaxInflightLLM      int          // Concurrent LLM batch calls; 0 means ParallelWorkers
+	SkipManualReview    bool         // Skip manual review of low-confidence items
+	Prompt              RerankPrompt // Prompt for the LLM; empty means RerankPromptReconsider
+	// IncludeUserModified re-ranks classifications the user set too. Saving
+	// over them also needs the storage to allow it.
+	IncludeUserModified bool
 }
 
 // BatchResult contains the classification result for a merchant group.
@@ -47,21 +138,55 @@ type BatchResult struct {
 	Error        error
 	Suggestion   *model.CategoryRanking
 	Merchant     string
+	Source       DecisionSource // What classified the merchant; empty if nothing did
 	Transactions []model.Transaction
 	UsedPatterns []model.CheckPattern
-	AutoAccepted bool
+	// TransactionConfidences holds the score each transaction gives the suggested
+	// category on its own. Only set when GroupConfidence needs it.
+	TransactionConfidences []float64
+	AutoAccepted           bool
+	NewMerchant            bool // No prior classification history; held for review with ReviewNewMerchants
+	// DirectionMismatch is set when the LLM's top pick didn't suit the transaction
+	// direction. These results are always reviewed.
+	DirectionMismatch bool
+	// ValidationIssues lists the problems the validation pass found. Results
+	// with issues are always reviewed.
+	ValidationIssues []string
+	// RuleConfirmation records whether the LLM agreed with the rule that matched
+	// this merchant. Only set with ConfirmRules.
+	RuleConfirmation RuleConfirmation
+	// CatchAllCapped is set when a catch-all suggestion was lowered to
+	// CatchAllMaxConfidence. These results are always reviewed.
+	CatchAllCapped bool
+	// Rankings holds every category the LLM ranked, best first. Only set with Explain.
+	Rankings model.CategoryRankings
+	// AlwaysReview is set when the suggested category is marked always review.
+	// These results are always reviewed.
+	AlwaysReview bool
 }
 
 // BatchClassificationSummary contains statistics about the batch run.
 type BatchClassificationSummary struct {
-	TotalMerchants    int
-	TotalTransactions int
-	AutoAcceptedCount int
-	AutoAcceptedTxns  int
-	NeedsReviewCount  int
-	NeedsReviewTxns   int
-	FailedCount       int
-	ProcessingTime    time.Duration
+	TotalMerchants     int
+	TotalTransactions  int
+	AutoAcceptedCount  int
+	AutoAcceptedTxns   int
+	NeedsReviewCount   int
+	NeedsReviewTxns    int
+	FailedCount        int
+	NewMerchantCount   int // Merchants held for review because they had no history
+	DeferredCount      int // Merchants left unreviewed when a chunked review was stopped
+	RefundCount        int // Refunds that inherited the category of a matched purchase
+	ValidationCount    int // Merchants sent to review because they failed validation
+	RuleAgreedCount    int // Rule matches the LLM agreed with, with ConfirmRules
+	RuleDisagreedCount int // Rule matches sent to review because the LLM disagreed or didn't answer
+	EscalatedCount     int // Merchants filed under the escalate category instead of reviewed
+	EscalatedTxns      int
+	FallbackTxns       int // Transactions of failed merchants filed under a fallback category
+	CatchAllCount      int // Merchants suggested the catch-all category
+	CatchAllTxns       int
+	FailedMerchants    []string
+	ProcessingTime     time.Duration
 }
 
 // RerankSummary contains statistics about the rerank run.
@@ -73,6 +198,10 @@ type RerankSummary struct {
 	NeedsReviewCount   int           // Transactions needing manual review
 	AverageImprovement float64       // Average confidence improvement
 	ProcessingTime     time.Duration // Total processing time
+	Prompt             RerankPrompt  // Prompt the merchants were re-ranked with
+	// PromptStats counts improvements per prompt, so prompts can be compared
+	// with RerankPromptCompare.
+	PromptStats []RerankPromptStats
 }
 
 // ClassifyTransactionsBatch performs batch classification with parallel processing.
@@ -80,18 +209,20 @@ func (e *ClassificationEngine) ClassifyTransactionsBatch(ctx context.Context, fr
 	startTime := time.Now()
 
 	// Get transactions to classify
-	transactions, err := e.GetTransactionsToClassify(ctx, fromDate)
+	transactions, err := e.GetTransactionsToClassifyBetween(ctx, fromDate, opts.UntilDate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get transactions: %w", err)
 	}
 
+	transactions, refunds := e.carryOverRefunds(ctx, transactions, refundWindow(opts), opts.DryRun)
+
 	if len(transactions) == 0 {
 		slog.Info("No transactions to classify")
-		return &BatchClassificationSummary{}, nil
+		return &BatchClassificationSummary{RefundCount: refunds}, nil
 	}
 
 	// Group by merchant
-	merchantGroups := e.groupByMerchant(transactions)
+	merchantGroups := splitLargeGroups(e.splitMultiCategoryGroups(ctx, e.groupByMerchant(transactions)), opts.MaxGroupSize)
 	sortedMerchants := e.sortMerchantsByVolume(merchantGroups)
 
 	slog.Info("Starting batch classification",
@@ -104,49 +235,92 @@ func (e *ClassificationEngine) ClassifyTransactionsBatch(ctx context.Context, fr
 	if err != nil {
 		return nil, fmt.Errorf("failed to get categories: %w", err)
 	}
+	categories = withoutFallbackCategories(withoutEscalateCategory(categories, opts), opts)
 
 	// Process all merchants in parallel
-	results := e.processMerchantsParallel(ctx, sortedMerchants, merchantGroups, categories, opts)
+	results, err := e.processMerchantsParallel(ctx, sortedMerchants, merchantGroups, categories, opts)
+	if err != nil {
+		return nil, fmt.Errorf("batch classification stopped: %w", err)
+	}
 
 	// Build summary and separate results
 	summary := &BatchClassificationSummary{
 		TotalMerchants:    len(merchantGroups),
 		TotalTransactions: len(transactions),
+		RefundCount:       refunds,
+		ValidationCount:   e.validateResults(ctx, results, categories, opts),
 		ProcessingTime:    time.Since(startTime),
 	}
+	summary.RuleAgreedCount, summary.RuleDisagreedCount = countRuleConfirmations(results)
 
 	var autoAccepted []BatchResult
 	var needsReview []BatchResult
+	var escalated []BatchResult
+	var failed []BatchResult
 
 	for _, result := range results {
 		if result.Error != nil {
 			summary.FailedCount++
+			summary.FailedMerchants = append(summary.FailedMerchants, result.Merchant)
+			failed = append(failed, result)
+			opts.Decisions.Record(result, OutcomeFail)
 			slog.Warn("Failed to classify merchant",
 				"merchant", result.Merchant,
 				"error", result.Error)
 			continue
 		}
 
-		if result.Suggestion != nil && result.Suggestion.Score >= opts.AutoAcceptThreshold && !result.Suggestion.IsNew {
+		if result.NewMerchant {
+			summary.NewMerchantCount++
+		}
+		if isCatchAll(result, opts) {
+			summary.CatchAllCount++
+			summary.CatchAllTxns += len(result.Transactions)
+		}
+
+		if escalates(result, opts) {
+			escalated = append(escalated, result)
+			summary.EscalatedCount++
+			summary.EscalatedTxns += len(result.Transactions)
+			opts.Decisions.Record(result, OutcomeEscalate)
+		} else if autoAcceptable(result, opts) {
 			result.AutoAccepted = true
 			autoAccepted = append(autoAccepted, result)
 			summary.AutoAcceptedCount++
 			summary.AutoAcceptedTxns += len(result.Transactions)
+			opts.Decisions.Record(result, OutcomeAuto)
 		} else {
 			needsReview = append(needsReview, result)
 			summary.NeedsReviewCount++
 			summary.NeedsReviewTxns += len(result.Transactions)
+			opts.Decisions.Record(result, reviewOutcome(opts))
 		}
 	}
 
 	// Auto-save high confidence classifications
-	if err := e.saveAutoAcceptedBatch(ctx, autoAccepted); err != nil {
+	if err := e.saveAutoAcceptedBatch(ctx, autoAccepted, opts.SaveBatchSize); err != nil {
 		slog.Error("Failed to save some auto-accepted classifications", "error", err)
 	}
 
+	if len(escalated) > 0 {
+		if err := e.saveEscalated(ctx, escalated, opts); err != nil {
+			slog.Error("Failed to save escalated classifications", "error", err)
+		}
+	}
+
+	if len(failed) > 0 && (opts.FallbackExpenseCategory != "" || opts.FallbackIncomeCategory != "") {
+		saved, err := e.saveFallbacks(ctx, failed, opts)
+		summary.FallbackTxns = saved
+		if err != nil {
+			slog.Error("Failed to save fallback classifications", "error", err)
+		}
+	}
+
 	// Handle manual review for remaining items (unless skipped)
 	if len(needsReview) > 0 && !opts.SkipManualReview {
-		if err := e.handleBatchReview(ctx, needsReview, categories); err != nil {
+		deferred, err := e.handleChunkedReview(ctx, needsReview, categories, opts.ReviewChunkSize, opts.CategoryRetries, opts.ReviewCommitEvery, opts.RecordCalibration)
+		summary.DeferredCount = deferred
+		if err != nil {
 			return summary, fmt.Errorf("batch review failed: %w", err)
 		}
 	} else if len(needsReview) > 0 {
@@ -156,10 +330,13 @@ func (e *ClassificationEngine) ClassifyTransactionsBatch(ctx context.Context, fr
 			"reason", fmt.Sprintf("below %.0f%% confidence threshold", opts.AutoAcceptThreshold*100))
 
 		// Save low-confidence classifications to prevent re-evaluation
-		// This ensures we don't re-process these transactions on every run
+		// This ensures we don't re-process these transactions on every run.
+		// Categories that always need review, suggestions that failed
+		// validation and unconfirmed rule matches stay unclassified until
+		// reviewed.
 		if opts.SkipManualReview {
 			slog.Info("Saving low-confidence classifications to prevent re-evaluation")
-			if err := e.saveAutoAcceptedBatch(ctx, needsReview); err != nil {
+			if err := e.saveAutoAcceptedBatch(ctx, withoutReviewOnly(needsReview), opts.SaveBatchSize); err != nil {
 				slog.Error("Failed to save low-confidence classifications", "error", err)
 			}
 		}
@@ -168,19 +345,32 @@ func (e *ClassificationEngine) ClassifyTransactionsBatch(ctx context.Context, fr
 	return summary, nil
 }
 
+// autoAcceptable reports whether a result can be saved without review. The
+// confidence compared with the threshold depends on opts.GroupConfidence.
+func autoAcceptable(result BatchResult, opts BatchClassificationOptions) bool {
+	if result.Suggestion == nil || result.Suggestion.IsNew || result.NewMerchant || result.DirectionMismatch ||
+		result.CatchAllCapped || result.AlwaysReview || len(result.ValidationIssues) > 0 || !result.RuleConfirmation.trusted() {
+		return false
+	}
+	confidence := opts.GroupConfidence.aggregate(result.Suggestion.Score, result.TransactionConfidences)
+	return confidence >= opts.AutoAcceptThreshold
+}
+
 // ClassifySpecificTransactions performs batch classification on a specific set of transactions.
 // Unlike ClassifyTransactionsBatch, this method does not fetch unclassified transactions
 // but instead processes the exact transactions provided. This is useful for recategorization.
 func (e *ClassificationEngine) ClassifySpecificTransactions(ctx context.Context, transactions []model.Transaction, opts BatchClassificationOptions) (*BatchClassificationSummary, error) {
 	startTime := time.Now()
 
+	transactions, refunds := e.carryOverRefunds(ctx, transactions, refundWindow(opts), opts.DryRun)
+
 	if len(transactions) == 0 {
 		slog.Info("No transactions to classify")
-		return &BatchClassificationSummary{}, nil
+		return &BatchClassificationSummary{RefundCount: refunds}, nil
 	}
 
 	// Group by merchant
-	merchantGroups := e.groupByMerchant(transactions)
+	merchantGroups := splitLargeGroups(e.splitMultiCategoryGroups(ctx, e.groupByMerchant(transactions)), opts.MaxGroupSize)
 	sortedMerchants := e.sortMerchantsByVolume(merchantGroups)
 
 	slog.Info("Starting specific transaction classification",
@@ -193,49 +383,107 @@ func (e *ClassificationEngine) ClassifySpecificTransactions(ctx context.Context,
 	if err != nil {
 		return nil, fmt.Errorf("failed to get categories: %w", err)
 	}
+	categories = withoutFallbackCategories(withoutEscalateCategory(categories, opts), opts)
 
 	// Process all merchants in parallel
-	results := e.processMerchantsParallel(ctx, sortedMerchants, merchantGroups, categories, opts)
+	results, err := e.processMerchantsParallel(ctx, sortedMerchants, merchantGroups, categories, opts)
+	if err != nil {
+		return nil, fmt.Errorf("batch classification stopped: %w", err)
+	}
 
 	// Build summary and separate results
 	summary := &BatchClassificationSummary{
 		TotalMerchants:    len(merchantGroups),
 		TotalTransactions: len(transactions),
+		RefundCount:       refunds,
+		ValidationCount:   e.validateResults(ctx, results, categories, opts),
 		ProcessingTime:    time.Since(startTime),
 	}
+	summary.RuleAgreedCount, summary.RuleDisagreedCount = countRuleConfirmations(results)
 
 	var autoAccepted []BatchResult
 	var needsReview []BatchResult
+	var escalated []BatchResult
+	var failed []BatchResult
 
 	for _, result := range results {
 		if result.Error != nil {
+			if opts.ResultCollector != nil {
+				opts.ResultCollector(result)
+			}
 			summary.FailedCount++
+			summary.FailedMerchants = append(summary.FailedMerchants, result.Merchant)
+			failed = append(failed, result)
+			opts.Decisions.Record(result, OutcomeFail)
 			slog.Warn("Failed to classify merchant",
 				"merchant", result.Merchant,
 				"error", result.Error)
 			continue
 		}
 
-		if result.Suggestion != nil && result.Suggestion.Score >= opts.AutoAcceptThreshold && !result.Suggestion.IsNew {
+		if result.NewMerchant {
+			summary.NewMerchantCount++
+		}
+		if isCatchAll(result, opts) {
+			summary.CatchAllCount++
+			summary.CatchAllTxns += len(result.Transactions)
+		}
+
+		if escalates(result, opts) {
+			escalated = append(escalated, result)
+			summary.EscalatedCount++
+			summary.EscalatedTxns += len(result.Transactions)
+			opts.Decisions.Record(result, OutcomeEscalate)
+		} else if autoAcceptable(result, opts) {
 			result.AutoAccepted = true
 			autoAccepted = append(autoAccepted, result)
 			summary.AutoAcceptedCount++
 			summary.AutoAcceptedTxns += len(result.Transactions)
+			opts.Decisions.Record(result, OutcomeAuto)
 		} else {
 			needsReview = append(needsReview, result)
 			summary.NeedsReviewCount++
 			summary.NeedsReviewTxns += len(result.Transactions)
+			opts.Decisions.Record(result, reviewOutcome(opts))
+		}
+
+		if opts.ResultCollector != nil {
+			opts.ResultCollector(result)
 		}
 	}
 
+	if opts.DryRun {
+		slog.Info("Dry run - skipping save and manual review",
+			"auto_accept_candidates", summary.AutoAcceptedTxns,
+			"review_candidates", summary.NeedsReviewTxns,
+			"escalate_candidates", summary.EscalatedTxns)
+		return summary, nil
+	}
+
 	// Auto-save high confidence classifications
-	if err := e.saveAutoAcceptedBatch(ctx, autoAccepted); err != nil {
+	if err := e.saveAutoAcceptedBatch(ctx, autoAccepted, opts.SaveBatchSize); err != nil {
 		slog.Error("Failed to save some auto-accepted classifications", "error", err)
 	}
 
+	if len(escalated) > 0 {
+		if err := e.saveEscalated(ctx, escalated, opts); err != nil {
+			slog.Error("Failed to save escalated classifications", "error", err)
+		}
+	}
+
+	if len(failed) > 0 && (opts.FallbackExpenseCategory != "" || opts.FallbackIncomeCategory != "") {
+		saved, err := e.saveFallbacks(ctx, failed, opts)
+		summary.FallbackTxns = saved
+		if err != nil {
+			slog.Error("Failed to save fallback classifications", "error", err)
+		}
+	}
+
 	// Handle manual review for remaining items (unless skipped)
 	if len(needsReview) > 0 && !opts.SkipManualReview {
-		if err := e.handleBatchReview(ctx, needsReview, categories); err != nil {
+		deferred, err := e.handleChunkedReview(ctx, needsReview, categories, opts.ReviewChunkSize, opts.CategoryRetries, opts.ReviewCommitEvery, opts.RecordCalibration)
+		summary.DeferredCount = deferred
+		if err != nil {
 			return summary, fmt.Errorf("batch review failed: %w", err)
 		}
 	} else if len(needsReview) > 0 {
@@ -258,7 +506,14 @@ func (e *ClassificationEngine) processMerchantsParallel(
 	merchantGroups map[string][]model.Transaction,
 	categories []model.Category,
 	opts BatchClassificationOptions,
-) []BatchResult {
+) ([]BatchResult, error) {
+	// With StopOnError, the first failure cancels the workers
+	cancel := context.CancelFunc(func() {})
+	if opts.StopOnError {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
 	// Create work channel
 	workChan := make(chan string, len(sortedMerchants))
 	for _, merchant := range sortedMerchants {
@@ -269,6 +524,16 @@ func (e *ClassificationEngine) processMerchantsParallel(
 	// Results channel
 	resultsChan := make(chan BatchResult, len(sortedMerchants))
 
+	// Workers share the LLM call slots
+	maxInflight := opts.MaxInflightLLM
+	if maxInflight <= 0 {
+		maxInflight = opts.ParallelWorkers
+	}
+	opts.llmSlots = make(chan struct{}, maxInflight)
+	if opts.progress == nil {
+		opts.progress = newBatchProgress(len(sortedMerchants), opts.OnProgress)
+	}
+
 	// Start workers
 	var wg sync.WaitGroup
 	wg.Add(opts.ParallelWorkers)
@@ -288,11 +553,64 @@ func (e *ClassificationEngine) processMerchantsParallel(
 
 	// Collect results
 	results := make([]BatchResult, 0, len(sortedMerchants))
+	var firstErr error
 	for result := range resultsChan {
+		if opts.StopOnError && result.Error != nil && firstErr == nil {
+			firstErr = fmt.Errorf("merchant %q: %w", result.Merchant, result.Error)
+			cancel()
+		}
 		results = append(results, result)
 	}
+	requireReview(results, categories)
 
-	return results
+	return results, firstErr
+}
+
+// suggestCategoryBatch asks the LLM to classify a batch once an LLM call slot
+// is free, so no more than MaxInflightLLM calls run at once.
+func (e *ClassificationEngine) suggestCategoryBatch(
+	ctx context.Context,
+	requests []llm.MerchantBatchRequest,
+	categories []model.Category,
+	opts BatchClassificationOptions,
+) (map[string]model.CategoryRankings, error) {
+	if opts.llmSlots != nil {
+		select {
+		case opts.llmSlots <- struct{}{}:
+			defer func() { <-opts.llmSlots }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return e.classifier.SuggestCategoryBatch(ctx, requests, categories)
+}
+
+// batchProgress reports finished merchant groups to OnProgress, one call at a
+// time, from any number of workers.
+type batchProgress struct {
+	mu     sync.Mutex
+	report func(done, total int, merchant string)
+	done   int
+	total  int
+}
+
+// newBatchProgress returns nil when report is nil, which reports nothing.
+func newBatchProgress(total int, report func(done, total int, merchant string)) *batchProgress {
+	if report == nil {
+		return nil
+	}
+	return &batchProgress{report: report, total: total}
+}
+
+// finished reports that merchant's group is done.
+func (p *batchProgress) finished(merchant string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	p.report(p.done, p.total, merchant)
 }
 
 // batchWorker processes merchants from the work channel.
@@ -353,8 +671,15 @@ func (e *ClassificationEngine) processMerchantBatch(
 	opts BatchClassificationOptions,
 ) []BatchResult {
 	results := make([]BatchResult, len(merchants))
+	defer func() {
+		for _, merchant := range merchants {
+			opts.progress.finished(merchant)
+		}
+	}()
 	needsLLM := make([]llm.MerchantBatchRequest, 0, len(merchants))
 	needsLLMIndices := make([]int, 0, len(merchants))
+	var hints map[string]string
+	hintsLoaded := false
 
 	// First pass: check for existing vendor rules and prepare LLM requests
 	for i, merchant := range merchants {
@@ -364,81 +689,13 @@ func (e *ClassificationEngine) processMerchantBatch(
 			Transactions: txns,
 		}
 
-		// Check pattern rules first (if pattern classifier is available)
-		if e.patternClassifier != nil {
-			patternRanking, err := e.patternClassifier.ClassifyWithPatterns(ctx, txns)
-			if err != nil {
-				slog.Warn("pattern classification failed",
-					"merchant", merchant,
-					"error", err)
-			} else if patternRanking != nil {
-				// Use pattern-based classification
-				result.Suggestion = patternRanking
-				// Auto-accept if confidence meets threshold
-				if patternRanking.Score >= opts.AutoAcceptThreshold {
-					result.AutoAccepted = true
-				}
+		if e.applyRules(ctx, &result, opts) {
+			if !opts.ConfirmRules {
 				results[i] = result
-
-				// Log pattern classification
-				slog.Info("merchant classified (pattern rule)",
-					"merchant", merchant,
-					"category", patternRanking.Category,
-					"confidence", fmt.Sprintf("%.2f", patternRanking.Score),
-					"transaction_count", len(txns))
-				continue
-			}
-		}
-
-		// Fall back to vendor rule for backward compatibility
-		// DEPRECATED: Vendor rules don't validate transaction direction.
-		// Pattern rules should be used instead for proper direction validation.
-		vendor, err := e.getVendor(ctx, merchant)
-		if err == nil && vendor != nil {
-			// Use existing vendor rule
-			result.Suggestion = &model.CategoryRanking{
-				Category:    vendor.Category,
-				Score:       1.0, // Vendor rules have 100% confidence
-				IsNew:       false,
-				Description: "", // Vendors don't have descriptions
-			}
-			result.AutoAccepted = true
-			results[i] = result
-
-			// Log vendor rule match
-			slog.Info("merchant classified (vendor rule - DEPRECATED)",
-				"merchant", merchant,
-				"category", vendor.Category,
-				"confidence", "1.00",
-				"transaction_count", len(txns))
-			continue
-		}
-
-		// Check for check patterns (only for check transactions)
-		if len(txns) > 0 && txns[0].Type == "CHECK" {
-			checkPatterns, err := e.storage.GetMatchingCheckPatterns(ctx, txns[0])
-			if err == nil && len(checkPatterns) > 0 {
-				// Use the first matching pattern
-				pattern := checkPatterns[0]
-				result.Suggestion = &model.CategoryRanking{
-					Category:    pattern.Category,
-					Score:       1.0, // Check patterns have 100% confidence
-					IsNew:       false,
-					Description: "", // Check patterns don't have descriptions
-				}
-				result.AutoAccepted = true
-				result.UsedPatterns = []model.CheckPattern{pattern}
-				results[i] = result
-
-				// Log check pattern match
-				slog.Info("check classified (pattern rule)",
-					"merchant", merchant,
-					"pattern", pattern.PatternName,
-					"category", pattern.Category,
-					"confidence", "1.00",
-					"transaction_count", len(txns))
 				continue
 			}
+			// Ask the LLM for a second opinion; the rule is only trusted if it agrees
+			result.RuleConfirmation = RuleUnconfirmed
 		}
 
 		// Need LLM classification
@@ -448,14 +705,16 @@ func (e *ClassificationEngine) processMerchantBatch(
 			continue
 		}
 
-		// Prepare batch request for this merchant
-		req := llm.MerchantBatchRequest{
-			MerchantID:        merchant,
-			MerchantName:      merchant,
-			SampleTransaction: txns[0],
-			TransactionCount:  len(txns),
+		// Hints are only needed once a merchant reaches the LLM
+		if !hintsLoaded {
+			hints = e.merchantHints(ctx)
+			hintsLoaded = true
+		}
+
+		if result.RuleConfirmation == "" {
+			result.Source = SourceLLM
 		}
-		needsLLM = append(needsLLM, req)
+		needsLLM = append(needsLLM, batchRequest(merchant, txns, hints, opts))
 		needsLLMIndices = append(needsLLMIndices, i)
 		results[i] = result
 	}
@@ -485,10 +744,15 @@ func (e *ClassificationEngine) processMerchantBatch(
 		batchIndices := needsLLMIndices[start:end]
 
 		// Get batch classifications from LLM
-		batchRankings, err := e.classifier.SuggestCategoryBatch(ctx, batch, filteredCategories)
+		batchRankings, err := e.suggestCategoryBatch(ctx, batch, filteredCategories, opts)
 		if err != nil {
 			// If batch fails, mark all merchants in batch as failed
 			for j, idx := range batchIndices {
+				// Rule matches keep their category but go to review unconfirmed
+				if results[idx].RuleConfirmation != "" {
+					slog.Warn("could not confirm rule with LLM", "merchant", batch[j].MerchantID, "error", err)
+					continue
+				}
 				results[idx].Error = fmt.Errorf("batch classification failed: %w", err)
 				results[idx].Merchant = batch[j].MerchantID
 				results[idx].Transactions = merchantGroups[batch[j].MerchantID]
@@ -502,6 +766,10 @@ func (e *ClassificationEngine) processMerchantBatch(
 			merchantID := req.MerchantID
 
 			rankings, found := batchRankings[merchantID]
+			if results[idx].RuleConfirmation != "" {
+				e.confirmRule(&results[idx], rankings, categories)
+				continue
+			}
 			if !found || len(rankings) == 0 {
 				results[idx].Error = fmt.Errorf("no rankings returned for merchant")
 				results[idx].Merchant = merchantID
@@ -511,18 +779,40 @@ func (e *ClassificationEngine) processMerchantBatch(
 
 			// Get transactions for this merchant
 			txns := merchantGroups[merchantID]
+			rankings = applyBankCategoryPrior(rankings, txns, categories, opts)
 
-			top := rankings.Top()
+			top, mismatch := e.directionSafeSuggestion(merchantID, rankings, categories, txns)
 			if top == nil {
 				results[idx].Error = fmt.Errorf("no category suggestion returned")
 				results[idx].Merchant = merchantID
 				results[idx].Transactions = txns
 				continue
 			}
+			results[idx].DirectionMismatch = mismatch
 
 			results[idx].Merchant = merchantID
 			results[idx].Transactions = txns
 			results[idx].Suggestion = top
+			if opts.Explain {
+				results[idx].Rankings = rankings
+			}
+			capCatchAll(&results[idx], rankings, opts)
+			if opts.ReviewNewMerchants {
+				results[idx].NewMerchant = !e.hasClassificationHistory(ctx, groupMerchantName(merchantID))
+			}
+
+			// Per-transaction classification costs an LLM call each, so only spend
+			// it on groups that would otherwise be auto-accepted
+			if opts.GroupConfidence.perTransaction() && len(txns) > 1 && !top.IsNew && !mismatch &&
+				!results[idx].NewMerchant && !results[idx].CatchAllCapped && top.Score >= opts.AutoAcceptThreshold {
+				confidences := e.transactionConfidences(ctx, top.Category, txns, filteredCategories)
+				results[idx].TransactionConfidences = confidences
+				slog.Info("merchant group confidence",
+					"merchant", merchantID,
+					"strategy", opts.GroupConfidence,
+					"top", fmt.Sprintf("%.2f", top.Score),
+					"group", fmt.Sprintf("%.2f", opts.GroupConfidence.aggregate(top.Score, confidences)))
+			}
 
 			// Log the classification result for this merchant
 			slog.Info("merchant classified",
@@ -537,9 +827,194 @@ func (e *ClassificationEngine) processMerchantBatch(
 	return results
 }
 
-// saveAutoAcceptedBatch saves all auto-accepted classifications.
-func (e *ClassificationEngine) saveAutoAcceptedBatch(ctx context.Context, results []BatchResult) error {
+// batchRequest prepares the LLM request classifying a merchant's transactions.
+func batchRequest(merchant string, txns []model.Transaction, hints map[string]string, opts BatchClassificationOptions) llm.MerchantBatchRequest {
+	samples := selectSamples(txns, opts.SampleStrategy, opts.SampleCount)
+	req := llm.MerchantBatchRequest{
+		MerchantID:        merchant,
+		MerchantName:      groupMerchantName(merchant),
+		Hint:              hints[strings.ToLower(groupMerchantName(merchant))],
+		SampleTransaction: samples[0],
+		AdditionalSamples: samples[1:],
+		TransactionCount:  len(txns),
+	}
+	if previous, ok := opts.PreviousGuesses[merchant]; ok {
+		req.PreviousCategory = previous.Category
+		req.PreviousConfidence = previous.Score
+	}
+	return req
+}
+
+// applyRules classifies result's merchant with the first matching pattern rule,
+// vendor rule or check pattern. It reports whether any rule matched.
+func (e *ClassificationEngine) applyRules(ctx context.Context, result *BatchResult, opts BatchClassificationOptions) bool {
+	merchant := result.Merchant
+	txns := result.Transactions
+
+	// Check pattern rules first (if pattern classifier is available)
+	if e.patternClassifier != nil {
+		patternRanking, err := e.patternClassifier.ClassifyWithPatterns(ctx, txns)
+		if err != nil {
+			slog.Warn("pattern classification failed",
+				"merchant", merchant,
+				"error", err)
+		} else if patternRanking != nil {
+			// Use pattern-based classification
+			result.Suggestion = patternRanking
+			result.Source = SourcePattern
+			// Auto-accept if confidence meets threshold
+			if patternRanking.Score >= opts.AutoAcceptThreshold {
+				result.AutoAccepted = true
+			}
+
+			// Log pattern classification
+			slog.Info("merchant classified (pattern rule)",
+				"merchant", merchant,
+				"category", patternRanking.Category,
+				"confidence", fmt.Sprintf("%.2f", patternRanking.Score),
+				"transaction_count", len(txns))
+			return true
+		}
+	}
+
+	// Fall back to vendor rule for backward compatibility
+	// DEPRECATED: Vendor rules don't validate transaction direction.
+	// Pattern rules should be used instead for proper direction validation.
+	vendor, err := e.getVendor(ctx, groupMerchantName(merchant))
+	if err == nil && vendor != nil && !vendor.MultiCategory {
+		// Vendor rules have 100% confidence until they go stale
+		confidence := opts.VendorRuleDecay.confidence(vendor.LastUpdated, time.Now())
+		result.Suggestion = &model.CategoryRanking{
+			Category:    vendor.Category,
+			Score:       confidence,
+			IsNew:       false,
+			Description: "", // Vendors don't have descriptions
+		}
+		result.Source = SourceVendor
+		result.AutoAccepted = confidence == 1.0 || confidence >= opts.AutoAcceptThreshold
+
+		if confidence < 1.0 {
+			slog.Info("vendor rule is stale, suggesting at reduced confidence",
+				"merchant", merchant,
+				"category", vendor.Category,
+				"last_updated", vendor.LastUpdated.Format("2006-01-02"),
+				"confidence", fmt.Sprintf("%.2f", confidence))
+		}
+
+		// Log vendor rule match
+		slog.Info("merchant classified (vendor rule - DEPRECATED)",
+			"merchant", merchant,
+			"category", vendor.Category,
+			"confidence", fmt.Sprintf("%.2f", confidence),
+			"transaction_count", len(txns))
+		return true
+	}
+
+	// Check for check patterns (only for check transactions)
+	if len(txns) > 0 && txns[0].Type == "CHECK" {
+		checkPatterns, err := e.storage.GetMatchingCheckPatterns(ctx, txns[0])
+		if err == nil && len(checkPatterns) > 0 {
+			// Use the most confident matching pattern
+			pattern := checkPatterns[0]
+			confidence := pattern.EffectiveConfidence()
+			result.Suggestion = &model.CategoryRanking{
+				Category:    pattern.Category,
+				Score:       confidence,
+				IsNew:       false,
+				Description: "", // Check patterns don't have descriptions
+			}
+			// Weak patterns still suggest, but go to review below the threshold
+			result.AutoAccepted = confidence >= opts.AutoAcceptThreshold
+			result.UsedPatterns = []model.CheckPattern{pattern}
+			result.Source = SourceCheck
+
+			// Log check pattern match
+			slog.Info("check classified (pattern rule)",
+				"merchant", merchant,
+				"pattern", pattern.PatternName,
+				"category", pattern.Category,
+				"confidence", fmt.Sprintf("%.2f", confidence),
+				"transaction_count", len(txns))
+			return true
+		}
+	}
+
+	return false
+}
+
+// directionSafeSuggestion returns the best ranking whose category suits the merchant's
+// transaction direction. If the LLM's top pick is an existing category of the wrong
+// type, the best in-direction ranking is used instead; when none exists the original
+// pick is kept. Either way the mismatch flag is set so the result goes to review.
+func (e *ClassificationEngine) directionSafeSuggestion(merchant string, rankings model.CategoryRankings, categories []model.Category, txns []model.Transaction) (*model.CategoryRanking, bool) {
+	top := rankings.Top() // Also sorts rankings by score
+	if top == nil || top.IsNew {
+		return top, false
+	}
+
+	known := make(map[string]bool, len(categories))
+	for _, cat := range categories {
+		known[cat.Name] = true
+	}
+	allowed := make(map[string]bool, len(categories))
+	for _, cat := range e.filterCategoriesByDirection(categories, txns) {
+		allowed[cat.Name] = true
+	}
+
+	// Unknown categories aren't a direction problem; leave them to the caller
+	if allowed[top.Category] || !known[top.Category] {
+		return top, false
+	}
+
+	direction := "unknown"
+	if len(txns) > 0 && txns[0].Direction != "" {
+		direction = string(txns[0].Direction)
+	}
+
+	for i := range rankings {
+		if rankings[i].IsNew || allowed[rankings[i].Category] {
+			slog.Warn("LLM suggested category with wrong direction, using best matching alternative",
+				"merchant", merchant,
+				"direction", direction,
+				"suggested", top.Category,
+				"fallback", rankings[i].Category,
+				"fallback_confidence", fmt.Sprintf("%.2f", rankings[i].Score))
+			return &rankings[i], true
+		}
+	}
+
+	slog.Warn("LLM suggested category with wrong direction and no alternative, flagging for review",
+		"merchant", merchant,
+		"direction", direction,
+		"suggested", top.Category)
+	return top, true
+}
+
+// DefaultVendorRuleThreshold is the confidence a suggestion needs before a
+// vendor rule is created for its merchant, unless its category sets another.
+const DefaultVendorRuleThreshold = 0.85
+
+// vendorRuleThreshold returns the confidence an auto-accepted suggestion for
+// category needs to create a vendor rule.
+func vendorRuleThreshold(category *model.Category) float64 {
+	if category.VendorRuleThreshold > 0 {
+		return category.VendorRuleThreshold
+	}
+	return DefaultVendorRuleThreshold
+}
+
+// saveAutoAcceptedBatch saves all auto-accepted classifications, batchSize
+// transactions per storage transaction (0 means DefaultSaveBatchSize).
+func (e *ClassificationEngine) saveAutoAcceptedBatch(ctx context.Context, results []BatchResult, batchSize int) error {
+	if batchSize <= 0 {
+		batchSize = DefaultSaveBatchSize
+	}
 	saved := 0
+	batch := make([]model.Classification, 0, batchSize)
+	flush := func() {
+		saved += e.saveClassifications(ctx, batch)
+		batch = batch[:0]
+	}
 
 	for _, result := range results {
 		if result.Suggestion == nil {
@@ -562,30 +1037,25 @@ func (e *ClassificationEngine) saveAutoAcceptedBatch(ctx context.Context, result
 			continue
 		}
 
-		// Apply classifications to all transactions in the group
-		isVendorRule := result.Suggestion.Score == 1.0
+		// Apply classifications to all transactions in the group. Rule matches
+		// are classified by rule whatever their score, which decays for stale
+		// vendor rules and is the pattern's own for check patterns
+		fromRule := result.Source.fromRule()
 		for _, txn := range result.Transactions {
-			// Determine status based on confidence score
 			status := model.StatusClassifiedByAI
-			if isVendorRule {
-				// Score of 1.0 indicates vendor rule
+			if fromRule {
 				status = model.StatusClassifiedByRule
 			}
 
-			classification := model.Classification{
+			batch = append(batch, model.Classification{
 				Transaction:  txn,
 				Category:     result.Suggestion.Category,
 				Status:       status,
 				Confidence:   result.Suggestion.Score,
 				ClassifiedAt: time.Now(),
-			}
-
-			if err := e.storage.SaveClassification(ctx, &classification); err != nil {
-				slog.Error("Failed to save classification",
-					"transaction_id", txn.ID,
-					"error", err)
-			} else {
-				saved++
+			})
+			if len(batch) >= batchSize {
+				flush()
 			}
 		}
 
@@ -599,19 +1069,29 @@ func (e *ClassificationEngine) saveAutoAcceptedBatch(ctx context.Context, result
 			}
 		}
 
-		// Update vendor use count if this was a vendor rule
-		if isVendorRule {
-			// Get existing vendor to update use count
-			vendor, err := e.storage.GetVendor(ctx, result.Merchant)
-			if err == nil && vendor != nil {
-				vendor.UseCount += len(result.Transactions)
+		switch {
+		case result.Source == SourceVendor:
+			// Saving the classifications counts the rule's uses. A fresh rule
+			// stays fresh while it's used; a stale one stays stale until
+			// someone confirms it in review. The vendor may be spelled
+			// differently when vendors match ignoring case
+			if result.Suggestion.Score < 1.0 {
+				break
+			}
+			vendor, err := e.getVendor(ctx, groupMerchantName(result.Merchant))
+			if err == nil && vendor != nil && !vendor.IsRegex && !vendor.MultiCategory {
 				vendor.LastUpdated = time.Now()
 				if err := e.storage.SaveVendor(ctx, vendor); err != nil {
-					slog.Warn("Failed to update vendor use count", "error", err)
+					slog.Warn("Failed to update vendor rule", "error", err)
 				}
 			}
-		} else if result.Suggestion.Score >= 0.85 {
-			// Save new vendor rule if high confidence
+		case fromRule:
+			// Pattern rules and check patterns keep their own confidence; a
+			// vendor rule would override it
+		case result.Suggestion.Score >= vendorRuleThreshold(existingCategory) && e.allowsVendorRule(ctx, result.Merchant):
+			// Save new vendor rule if confident enough for the category; split
+			// and multi-category merchants span several categories, so one rule
+			// for all their transactions would be wrong
 			vendor := &model.Vendor{
 				Name:        result.Merchant,
 				Category:    result.Suggestion.Category,
@@ -624,6 +1104,8 @@ func (e *ClassificationEngine) saveAutoAcceptedBatch(ctx context.Context, result
 		}
 	}
 
+	flush()
+
 	slog.Info("Auto-accepted classifications saved",
 		"count", saved,
 		"merchants", len(results))
@@ -631,226 +1113,497 @@ func (e *ClassificationEngine) saveAutoAcceptedBatch(ctx context.Context, result
 	return nil
 }
 
-// handleBatchReview handles the interactive review of uncertain classifications.
-func (e *ClassificationEngine) handleBatchReview(ctx context.Context, needsReview []BatchResult, categories []model.Category) error {
-	// Sort by confidence (lowest first, so most uncertain are reviewed first)
-	sort.Slice(needsReview, func(i, j int) bool {
+// saveClassifications saves classifications in one storage transaction and
+// returns how many were saved. If the transaction fails, they are saved one
+// at a time so one bad classification doesn't lose the others; those that
+// still fail are logged and skipped.
+func (e *ClassificationEngine) saveClassifications(ctx context.Context, classifications []model.Classification) int {
+	if len(classifications) == 0 {
+		return 0
+	}
+	if len(classifications) > 1 {
+		err := e.storage.SaveClassifications(ctx, classifications)
+		if err == nil {
+			return len(classifications)
+		}
+		if errors.Is(err, storage.ErrUserModified) {
+			slog.Info("Some transactions keep the classification the user set, saving the rest one at a time",
+				"count", len(classifications))
+		} else {
+			slog.Warn("Failed to save classifications together, saving them one at a time",
+				"count", len(classifications),
+				"error", err)
+		}
+	}
+
+	saved := 0
+	for i := range classifications {
+		err := e.storage.SaveClassification(ctx, &classifications[i])
+		switch {
+		case errors.Is(err, storage.ErrUserModified):
+			slog.Info("Kept the classification the user set",
+				"transaction_id", classifications[i].Transaction.ID)
+			continue
+		case err != nil:
+			slog.Error("Failed to save classification",
+				"transaction_id", classifications[i].Transaction.ID,
+				"error", err)
+			continue
+		}
+		saved++
+	}
+	return saved
+}
+
+// handleChunkedReview reviews merchants in chunks of chunkSize, asking the prompter
+// whether to continue between chunks. Each merchant is saved as soon as it is
+// confirmed, so stopping early leaves only the unreviewed merchants unclassified
+// for the next run. It returns the number of merchants left unreviewed.
+func (e *ClassificationEngine) handleChunkedReview(ctx context.Context, needsReview []BatchResult, categories []model.Category, chunkSize, categoryRetries, commitEvery int, recordCalibration bool) (int, error) {
+	chunkPrompter, canPause := e.prompter.(ReviewChunkPrompter)
+	if chunkSize <= 0 || chunkSize >= len(needsReview) || !canPause {
+		return 0, e.handleBatchReview(ctx, needsReview, categories, categoryRetries, commitEvery, recordCalibration)
+	}
+
+	sortByConfidence(needsReview)
+
+	for start := 0; start < len(needsReview); start += chunkSize {
+		end := start + chunkSize
+		if end > len(needsReview) {
+			end = len(needsReview)
+		}
+
+		if start > 0 {
+			proceed, err := chunkPrompter.ConfirmContinueReview(ctx, start, len(needsReview)-start)
+			if err != nil {
+				return len(needsReview) - start, err
+			}
+			if !proceed {
+				slog.Info("Review paused, run classify again to resume",
+					"merchants_reviewed", start,
+					"merchants_remaining", len(needsReview)-start)
+				return len(needsReview) - start, nil
+			}
+
+			// Pick up categories created during the previous chunk
+			if refreshed, err := e.storage.GetCategories(ctx); err == nil {
+				categories = refreshed
+			} else {
+				slog.Warn("Failed to refresh categories between review chunks", "error", err)
+			}
+		}
+
+		if err := e.handleBatchReview(ctx, needsReview[start:end], categories, categoryRetries, commitEvery, recordCalibration); err != nil {
+			return len(needsReview) - start, err
+		}
+	}
+
+	return 0, nil
+}
+
+// sortByConfidence orders results lowest confidence first, so the most uncertain are reviewed first.
+func sortByConfidence(results []BatchResult) {
+	sort.SliceStable(results, func(i, j int) bool {
 		scoreI := float64(0)
 		scoreJ := float64(0)
-		if needsReview[i].Suggestion != nil {
-			scoreI = needsReview[i].Suggestion.Score
+		if results[i].Suggestion != nil {
+			scoreI = results[i].Suggestion.Score
 		}
-		if needsReview[j].Suggestion != nil {
-			scoreJ = needsReview[j].Suggestion.Score
+		if results[j].Suggestion != nil {
+			scoreJ = results[j].Suggestion.Score
 		}
 		return scoreI < scoreJ
 	})
+}
+
+// handleBatchReview handles the interactive review of uncertain classifications.
+// Decisions are saved in transactions of at least commitEvery reviewed
+// transactions (0 = after every merchant), and each merchant's decision is
+// committed whole. Pending decisions are also committed when the review stops
+// early, including on interrupt. With recordCalibration, the AI's suggestion
+// for a merchant given another category is kept in the classification history.
+func (e *ClassificationEngine) handleBatchReview(ctx context.Context, needsReview []BatchResult, categories []model.Category, categoryRetries, commitEvery int, recordCalibration bool) error {
+	sortByConfidence(needsReview)
 
 	// Keep track of the current category list
 	currentCategories := categories
 
+	var pending []reviewDecision
+	pendingTransactions := 0
+	commit := func() {
+		// Commit even when interrupted; these decisions were already made
+		e.commitReviewDecisions(context.WithoutCancel(ctx), pending)
+		pending, pendingTransactions = nil, 0
+	}
+	defer commit()
+
 	// Process each merchant group separately
+merchants:
 	for _, result := range needsReview {
 		if len(result.Transactions) == 0 {
 			continue
 		}
 
-		// Create pending classifications for all transactions in this merchant group
-		pendingClassifications := make([]model.PendingClassification, 0, len(result.Transactions))
-
-		// Get check patterns if this is a check transaction
-		var checkPatterns []model.CheckPattern
-		if result.Transactions[0].Type == "CHECK" {
-			checkPatterns, _ = e.storage.GetMatchingCheckPatterns(ctx, result.Transactions[0])
+		// Parts of a split merchant are reviewed one at a time; say which part this is
+		if isSplitGroup(result.Merchant) {
+			minAmount, maxAmount := amountRange(result.Transactions)
+			slog.Info("Reviewing part of a large merchant",
+				"group", result.Merchant,
+				"transactions", len(result.Transactions),
+				"amount_range", fmt.Sprintf("$%.2f-$%.2f", minAmount, maxAmount))
 		}
 
-		// Get category rankings for display
-		var categoryRankings model.CategoryRankings
-		if result.Suggestion != nil {
-			// Build a simple rankings list from the suggestion
-			categoryRankings = model.CategoryRankings{
-				{
-					Category:    result.Suggestion.Category,
-					Score:       result.Suggestion.Score,
-					IsNew:       result.Suggestion.IsNew,
-					Description: result.Suggestion.Description,
-				},
+		// A merchant whose chosen category can't be created is reviewed again,
+		// up to categoryRetries times, so the decision isn't lost
+		var classification model.Classification
+		for attempt := 0; ; attempt++ {
+			pendingClassifications := e.pendingForReview(ctx, result, currentCategories)
+
+			// Get batch confirmation from user for this merchant group
+			classifications, err := e.prompter.BatchConfirmClassifications(ctx, pendingClassifications)
+			if err != nil {
+				// Check if this is a context cancellation (user interrupt)
+				if errors.Is(err, context.Canceled) {
+					return err
+				}
+				// Log the error but try to continue with the next merchant
+				slog.Error("Batch confirmation failed for merchant",
+					"merchant", result.Merchant,
+					"error", err,
+					"transaction_count", len(result.Transactions))
+				// Skip this merchant and continue with the next one
+				continue merchants
 			}
-		}
+			if len(classifications) == 0 {
+				continue merchants
+			}
+			classification = classifications[0] // Use the first classification as template
 
-		// Create a pending classification for each transaction
-		for _, txn := range result.Transactions {
-			pending := model.PendingClassification{
-				Transaction:      txn,
-				SimilarCount:     len(result.Transactions) - 1,
-				CategoryRankings: categoryRankings,
-				AllCategories:    currentCategories,
-				CheckPatterns:    checkPatterns,
+			// Debug logging to understand the flow
+			slog.Debug("Processing classification",
+				"category", classification.Category,
+				"create_new_category", classification.CreateNewCategory,
+				"status", classification.Status)
+
+			// Create a new category BEFORE saving any classifications
+			currentCategories, err = e.ensureReviewedCategory(ctx, classification, result, currentCategories)
+			if err == nil {
+				break
+			}
+			if attempt >= categoryRetries {
+				slog.Error("Skipping merchant, its category could not be created",
+					"merchant", result.Merchant,
+					"category", classification.Category,
+					"error", err)
+				continue merchants
 			}
 
-			// Add suggestion if available
-			if result.Suggestion != nil {
-				pending.SuggestedCategory = result.Suggestion.Category
-				pending.Confidence = result.Suggestion.Score
-				pending.IsNewCategory = result.Suggestion.IsNew
-				pending.CategoryDescription = result.Suggestion.Description
+			slog.Warn("Reviewing merchant again, its category could not be created",
+				"merchant", result.Merchant,
+				"category", classification.Category,
+				"error", err)
+			if reporter, ok := e.prompter.(CategoryErrorPrompter); ok {
+				if reportErr := reporter.ReportCategoryError(ctx, classification.Category, err); reportErr != nil {
+					return reportErr
+				}
 			}
+		}
 
-			pendingClassifications = append(pendingClassifications, pending)
+		// Create vendor rule if user modified a high-confidence suggestion,
+		// except for parts of a split or multi-category merchant
+		saveVendor := classification.Status == model.StatusUserModified && result.Suggestion != nil && result.Suggestion.Score >= DefaultVendorRuleThreshold && e.allowsVendorRule(ctx, result.Merchant)
+
+		// Keep the suggestion the review replaced, to measure how far its confidence was off
+		recordSuggestion := recordCalibration && result.Suggestion != nil && result.Suggestion.Category != classification.Category
+
+		pending = append(pending, reviewDecision{result: result, classification: classification, saveVendor: saveVendor, recordSuggestion: recordSuggestion})
+		pendingTransactions += len(result.Transactions)
+		if pendingTransactions >= commitEvery {
+			commit()
 		}
+	}
 
-		// Get batch confirmation from user for this merchant group
-		classifications, err := e.prompter.BatchConfirmClassifications(ctx, pendingClassifications)
-		if err != nil {
-			// Check if this is a context cancellation (user interrupt)
-			if errors.Is(err, context.Canceled) {
-				return err
+	return nil
+}
+
+// reviewDecision is a reviewed merchant waiting to be committed.
+type reviewDecision struct {
+	result           BatchResult
+	classification   model.Classification
+	saveVendor       bool
+	recordSuggestion bool // Add the AI's replaced suggestion to the history
+}
+
+// commitReviewDecisions saves reviewed merchants in a single transaction. If
+// that fails, each merchant is retried in a transaction of its own so one bad
+// merchant doesn't lose the others; merchants that still fail are logged and
+// skipped, as the review has already moved on.
+func (e *ClassificationEngine) commitReviewDecisions(ctx context.Context, decisions []reviewDecision) {
+	if len(decisions) == 0 {
+		return
+	}
+
+	err := e.commitReviewTx(ctx, decisions)
+	if err == nil {
+		slog.Debug("Committed reviewed merchants", "merchants", len(decisions))
+		return
+	}
+	if len(decisions) == 1 {
+		slog.Error("Failed to save reviewed merchant",
+			"merchant", decisions[0].result.Merchant,
+			"error", err)
+		return
+	}
+
+	slog.Warn("Failed to save reviewed merchants together, saving them one at a time", "error", err)
+	for _, decision := range decisions {
+		if err := e.commitReviewTx(ctx, []reviewDecision{decision}); err != nil {
+			slog.Error("Failed to save reviewed merchant",
+				"merchant", decision.result.Merchant,
+				"error", err)
+		}
+	}
+}
+
+// commitReviewTx applies reviewed merchants in one transaction, rolling all of
+// them back if any classification can't be saved.
+func (e *ClassificationEngine) commitReviewTx(ctx context.Context, decisions []reviewDecision) error {
+	tx, err := e.storage.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, decision := range decisions {
+		if err := applyReviewDecision(ctx, tx, decision); err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				slog.Warn("Failed to roll back review commit", "error", rollbackErr)
 			}
-			// Log the error but try to continue with the next merchant
-			slog.Error("Batch confirmation failed for merchant",
-				"merchant", result.Merchant,
-				"error", err,
-				"transaction_count", len(result.Transactions))
-			// Skip this merchant and continue with the next one
-			continue
+			return fmt.Errorf("merchant %q: %w", decision.result.Merchant, err)
 		}
+	}
 
-		// Process confirmed classifications
-		if len(classifications) > 0 {
-			classification := classifications[0] // Use the first classification as template
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit reviewed merchants: %w", err)
+	}
+	return nil
+}
 
-			// Debug logging to understand the flow
-			slog.Debug("Processing classification",
-				"category", classification.Category,
-				"notes", classification.Notes,
-				"status", classification.Status)
+// applyReviewDecision saves a reviewed merchant's classifications, check
+// pattern use counts and vendor rule. Only classification failures are
+// returned; the rest are best effort.
+func applyReviewDecision(ctx context.Context, store service.Storage, decision reviewDecision) error {
+	result, classification := decision.result, decision.classification
 
-			// Variable to track if we need to create a new category
-			needsNewCategory := false
-			categoryDescription := ""
-
-			// Check for new category signal in notes field
-			if strings.HasPrefix(classification.Notes, "NEW_CATEGORY|") {
-				needsNewCategory = true
-				// Extract description if provided
-				parts := strings.Split(classification.Notes, "|")
-				if len(parts) > 1 {
-					categoryDescription = parts[1]
-				}
+	// The AI's suggestion goes in the history first, so the review's category is the latest
+	if decision.recordSuggestion {
+		suggestions := make([]model.Classification, 0, len(result.Transactions))
+		for _, txn := range result.Transactions {
+			suggestions = append(suggestions, model.Classification{
+				Transaction: txn,
+				Category:    result.Suggestion.Category,
+				Status:      model.StatusClassifiedByAI,
+				Confidence:  result.Suggestion.Score,
+			})
+		}
+		if err := store.SaveClassificationHistory(ctx, suggestions); err != nil {
+			return fmt.Errorf("failed to save suggestion history: %w", err)
+		}
+	}
 
-				// If description is empty, user chose to let AI generate it
-				if categoryDescription == "" {
-					generatedDesc, _, err := e.classifier.GenerateCategoryDescription(ctx, classification.Category)
-					if err != nil {
-						slog.Warn("Failed to generate category description, using empty description",
-							"category", classification.Category,
-							"error", err)
-					} else {
-						categoryDescription = generatedDesc
-						slog.Debug("Generated category description",
-							"category", classification.Category,
-							"description", categoryDescription)
-					}
-				}
-			} else if result.Suggestion != nil && result.Suggestion.IsNew && classification.Category != "" {
-				// Original logic for AI-suggested new categories
-				needsNewCategory = true
-				categoryDescription = result.Suggestion.Description
+	// Apply classification to all transactions in the group
+	classifications := make([]model.Classification, 0, len(result.Transactions))
+	for _, txn := range result.Transactions {
+		classifications = append(classifications, model.Classification{
+			Transaction:  txn,
+			Category:     classification.Category,
+			Status:       classification.Status,
+			Confidence:   classification.Confidence,
+			ClassifiedAt: time.Now(),
+			Notes:        classification.Notes,
+		})
+	}
+	if err := store.SaveClassifications(ctx, classifications); err != nil {
+		return fmt.Errorf("failed to save classifications: %w", err)
+	}
+
+	// Increment use counts for check patterns that were used if the classification matches
+	for _, pattern := range result.UsedPatterns {
+		if pattern.Category == classification.Category {
+			if err := store.IncrementCheckPatternUseCount(ctx, pattern.ID); err != nil {
+				slog.Warn("Failed to increment check pattern use count",
+					"pattern_id", pattern.ID,
+					"pattern_name", pattern.PatternName,
+					"error", err)
 			}
+		}
+	}
 
-			// If we need to create a new category, do it BEFORE saving any classifications
-			if needsNewCategory {
-				// Check if category exists first
-				_, err := e.storage.GetCategoryByName(ctx, classification.Category)
-				switch {
-				case err != nil && errors.Is(err, storage.ErrCategoryNotFound):
-					// Create the new category
-					_, createErr := e.storage.CreateCategoryWithType(ctx, classification.Category, categoryDescription, model.CategoryTypeExpense)
-					if createErr != nil {
-						slog.Error("Failed to create new category",
-							"category", classification.Category,
-							"description", categoryDescription,
-							"error", createErr)
-						// Skip this entire merchant group if we can't create the category
-						continue
-					}
-					slog.Info("Created new category",
-						"category", classification.Category,
-						"description", categoryDescription)
-
-					// Refresh the category list after creating a new category
-					updatedCategories, refreshErr := e.storage.GetCategories(ctx)
-					if refreshErr != nil {
-						slog.Warn("Failed to refresh categories after creation",
-							"error", refreshErr)
-					} else {
-						currentCategories = updatedCategories
-					}
-				case err != nil:
-					slog.Error("Error checking category existence",
-						"category", classification.Category,
-						"error", err)
-					// Skip this merchant group if we can't check category existence
-					continue
-				default:
-					// Category already exists
-					slog.Debug("Category already exists",
-						"category", classification.Category)
-				}
+	if decision.saveVendor {
+		vendor := &model.Vendor{
+			Name:        result.Merchant,
+			Category:    classification.Category,
+			UseCount:    len(result.Transactions),
+			LastUpdated: time.Now(),
+		}
+		if err := store.SaveVendor(ctx, vendor); err != nil {
+			slog.Warn("Failed to save vendor rule", "error", err)
+		}
+	}
+
+	return nil
+}
+
+// pendingForReview builds the pending classifications shown when reviewing a
+// merchant group.
+func (e *ClassificationEngine) pendingForReview(ctx context.Context, result BatchResult, categories []model.Category) []model.PendingClassification {
+	pendingClassifications := make([]model.PendingClassification, 0, len(result.Transactions))
+
+	// Get check patterns if this is a check transaction
+	var checkPatterns []model.CheckPattern
+	if result.Transactions[0].Type == "CHECK" {
+		checkPatterns, _ = e.storage.GetMatchingCheckPatterns(ctx, result.Transactions[0])
+	}
+
+	// Get category rankings for display
+	var categoryRankings model.CategoryRankings
+	if result.Suggestion != nil {
+		// Build a simple rankings list from the suggestion
+		categoryRankings = model.CategoryRankings{
+			{
+				Category:    result.Suggestion.Category,
+				Score:       result.Suggestion.Score,
+				IsNew:       result.Suggestion.IsNew,
+				Description: result.Suggestion.Description,
+				Reason:      result.Suggestion.Reason,
+			},
+		}
+		// With Explain, the other rankings follow so review can show them too
+		for _, ranking := range result.Rankings {
+			if ranking.Category != result.Suggestion.Category {
+				categoryRankings = append(categoryRankings, ranking)
 			}
+		}
+	}
 
-			// Apply classification to all transactions in the group
-			for _, txn := range result.Transactions {
-				// Create new classification for each transaction
-				txnClassification := model.Classification{
-					Transaction:  txn,
-					Category:     classification.Category,
-					Status:       classification.Status,
-					Confidence:   classification.Confidence,
-					ClassifiedAt: time.Now(),
-					Notes:        "", // Clear notes - we don't want to store the NEW_CATEGORY signal
-				}
+	// Create a pending classification for each transaction
+	for _, txn := range result.Transactions {
+		pending := model.PendingClassification{
+			Transaction:      txn,
+			SimilarCount:     len(result.Transactions) - 1,
+			CategoryRankings: categoryRankings,
+			AllCategories:    categories,
+			CheckPatterns:    checkPatterns,
+		}
 
-				if err := e.storage.SaveClassification(ctx, &txnClassification); err != nil {
-					slog.Error("Failed to save classification",
-						"transaction_id", txn.ID,
-						"error", err)
-				}
+		// Add suggestion if available
+		if result.Suggestion != nil {
+			pending.SuggestedCategory = result.Suggestion.Category
+			pending.Confidence = result.Suggestion.Score
+			pending.IsNewCategory = result.Suggestion.IsNew
+			pending.CategoryDescription = result.Suggestion.Description
+		}
+
+		pendingClassifications = append(pendingClassifications, pending)
+	}
+
+	return pendingClassifications
+}
+
+// ensureReviewedCategory creates the category of a reviewed classification when
+// the user or the AI asked for a new one. It returns categories, refreshed when
+// a category was created, and an error when the category can't be used:
+// creation failed, or it differs only in case from an existing category.
+func (e *ClassificationEngine) ensureReviewedCategory(ctx context.Context, classification model.Classification, result BatchResult, categories []model.Category) ([]model.Category, error) {
+	// Variable to track if we need to create a new category
+	needsNewCategory := false
+	categoryDescription := ""
+
+	// Check whether the user asked for a new category
+	if classification.CreateNewCategory {
+		needsNewCategory = true
+		categoryDescription = classification.NewCategoryDescription
+
+		// If description is empty, user chose to let AI generate it
+		if categoryDescription == "" {
+			generatedDesc, _, err := e.classifier.GenerateCategoryDescription(ctx, classification.Category)
+			if err != nil {
+				slog.Warn("Failed to generate category description, using empty description",
+					"category", classification.Category,
+					"error", err)
+			} else {
+				categoryDescription = generatedDesc
+				slog.Debug("Generated category description",
+					"category", classification.Category,
+					"description", categoryDescription)
 			}
+		}
+	} else if result.Suggestion != nil && result.Suggestion.IsNew && classification.Category != "" {
+		// Original logic for AI-suggested new categories
+		needsNewCategory = true
+		categoryDescription = result.Suggestion.Description
+	}
 
-			// Increment use counts for check patterns that were used if the classification matches
-			for _, pattern := range result.UsedPatterns {
-				if pattern.Category == classification.Category {
-					if err := e.storage.IncrementCheckPatternUseCount(ctx, pattern.ID); err != nil {
-						slog.Warn("Failed to increment check pattern use count",
-							"pattern_id", pattern.ID,
-							"pattern_name", pattern.PatternName,
-							"error", err)
-					}
-				}
+	if !needsNewCategory {
+		return categories, nil
+	}
+
+	// Check if category exists first
+	_, err := e.storage.GetCategoryByName(ctx, classification.Category)
+	switch {
+	case err != nil && errors.Is(err, storage.ErrCategoryNotFound):
+		for _, existing := range categories {
+			if strings.EqualFold(existing.Name, classification.Category) {
+				return categories, fmt.Errorf("%w: %q", ErrCategoryCaseMismatch, existing.Name)
 			}
+		}
 
-			// Create vendor rule if user modified a high-confidence suggestion
-			if classification.Status == model.StatusUserModified && result.Suggestion != nil && result.Suggestion.Score >= 0.85 {
-				vendor := &model.Vendor{
-					Name:        result.Merchant,
-					Category:    classification.Category,
-					UseCount:    len(result.Transactions),
-					LastUpdated: time.Now(),
-				}
-				if err := e.storage.SaveVendor(ctx, vendor); err != nil {
-					slog.Warn("Failed to save vendor rule", "error", err)
-				}
+		// Create the new category
+		categoryType := classification.NewCategoryType
+		if categoryType == "" {
+			categoryType = model.CategoryTypeExpense
+		}
+		created, createErr := e.storage.CreateCategoryWithType(ctx, classification.Category, categoryDescription, categoryType)
+		if createErr != nil {
+			return categories, fmt.Errorf("failed to create category: %w", createErr)
+		}
+		slog.Info("Created new category",
+			"category", classification.Category,
+			"type", categoryType,
+			"description", categoryDescription)
+
+		if classification.NewCategoryBusinessPercent > 0 {
+			if err := e.storage.UpdateCategoryBusinessPercent(ctx, created.ID, classification.NewCategoryBusinessPercent); err != nil {
+				slog.Warn("Failed to set business percentage for new category",
+					"category", classification.Category,
+					"business_percent", classification.NewCategoryBusinessPercent,
+					"error", err)
 			}
 		}
-	}
 
-	return nil
+		// Refresh the category list after creating a new category
+		updatedCategories, refreshErr := e.storage.GetCategories(ctx)
+		if refreshErr != nil {
+			slog.Warn("Failed to refresh categories after creation",
+				"error", refreshErr)
+			return categories, nil
+		}
+		return updatedCategories, nil
+	case err != nil:
+		return categories, fmt.Errorf("failed to check category existence: %w", err)
+	default:
+		// Category already exists
+		slog.Debug("Category already exists",
+			"category", classification.Category)
+		return categories, nil
+	}
 }
 
 // GetDisplay returns a JSON representation of the summary.
 func (s *BatchClassificationSummary) GetDisplay() string {
 	if s.TotalMerchants == 0 {
+		if s.RefundCount > 0 {
+			return fmt.Sprintf(`{"message":"No transactions to classify","refund_count":%d}`, s.RefundCount)
+		}
 		return `{"message":"No transactions to classify"}`
 	}
 
@@ -866,6 +1619,17 @@ func (s *BatchClassificationSummary) GetDisplay() string {
 		NeedsReviewCount    int     `json:"needs_review_count"`
 		NeedsReviewTxns     int     `json:"needs_review_transactions"`
 		FailedCount         int     `json:"failed_count"`
+		NewMerchantCount    int     `json:"new_merchant_count,omitempty"`
+		DeferredCount       int     `json:"deferred_review_count,omitempty"`
+		RefundCount         int     `json:"refund_count,omitempty"`
+		ValidationCount     int     `json:"validation_flagged_count,omitempty"`
+		RuleAgreedCount     int     `json:"rule_agreed_count,omitempty"`
+		RuleDisagreedCount  int     `json:"rule_disagreed_count,omitempty"`
+		EscalatedCount      int     `json:"escalated_count,omitempty"`
+		EscalatedTxns       int     `json:"escalated_transactions,omitempty"`
+		FallbackTxns        int     `json:"fallback_transactions,omitempty"`
+		CatchAllCount       int     `json:"catch_all_count,omitempty"`
+		CatchAllTxns        int     `json:"catch_all_transactions,omitempty"`
 	}
 
 	data := summaryJSON{
@@ -877,6 +1641,17 @@ func (s *BatchClassificationSummary) GetDisplay() string {
 		NeedsReviewCount:    s.NeedsReviewCount,
 		NeedsReviewTxns:     s.NeedsReviewTxns,
 		FailedCount:         s.FailedCount,
+		NewMerchantCount:    s.NewMerchantCount,
+		DeferredCount:       s.DeferredCount,
+		RefundCount:         s.RefundCount,
+		ValidationCount:     s.ValidationCount,
+		RuleAgreedCount:     s.RuleAgreedCount,
+		RuleDisagreedCount:  s.RuleDisagreedCount,
+		EscalatedCount:      s.EscalatedCount,
+		EscalatedTxns:       s.EscalatedTxns,
+		FallbackTxns:        s.FallbackTxns,
+		CatchAllCount:       s.CatchAllCount,
+		CatchAllTxns:        s.CatchAllTxns,
 		ProcessingTime:      s.ProcessingTime.Round(time.Second).String(),
 	}
 
@@ -896,16 +1671,25 @@ func (s *RerankSummary) GetDisplay() string {
 
 	improvedPercent := float64(s.ImprovedCount) / float64(s.TotalEvaluated) * 100
 
+	type promptJSON struct {
+		Prompt          string  `json:"prompt"`
+		Evaluated       int     `json:"evaluated"`
+		Improved        int     `json:"improved"`
+		ImprovedPercent float64 `json:"improved_percent"`
+	}
+
 	type summaryJSON struct {
-		ProcessingTime    string  `json:"processing_time"`
-		Message           string  `json:"message"`
-		TotalEvaluated    int     `json:"total_evaluated"`
-		ImprovedCount     int     `json:"improved_count"`
-		ImprovedPercent   float64 `json:"improved_percent"`
-		UnchangedCount    int     `json:"unchanged_count"`
-		AutoAcceptedCount int     `json:"auto_accepted_count"`
-		NeedsReviewCount  int     `json:"needs_review_count"`
-		AvgImprovement    float64 `json:"average_improvement"`
+		ProcessingTime    string       `json:"processing_time"`
+		Message           string       `json:"message"`
+		TotalEvaluated    int          `json:"total_evaluated"`
+		ImprovedCount     int          `json:"improved_count"`
+		ImprovedPercent   float64      `json:"improved_percent"`
+		UnchangedCount    int          `json:"unchanged_count"`
+		AutoAcceptedCount int          `json:"auto_accepted_count"`
+		NeedsReviewCount  int          `json:"needs_review_count"`
+		AvgImprovement    float64      `json:"average_improvement"`
+		Prompt            string       `json:"prompt,omitempty"`
+		PromptComparison  []promptJSON `json:"prompt_comparison,omitempty"`
 	}
 
 	data := summaryJSON{
@@ -918,6 +1702,20 @@ func (s *RerankSummary) GetDisplay() string {
 		AvgImprovement:    s.AverageImprovement,
 		ProcessingTime:    s.ProcessingTime.Round(time.Second).String(),
 		Message:           fmt.Sprintf("Re-ranked %d transactions, improved %d (%.1f%%)", s.TotalEvaluated, s.ImprovedCount, improvedPercent),
+		Prompt:            string(s.Prompt),
+	}
+
+	// Per-prompt rates only say something when prompts were compared
+	if len(s.PromptStats) > 1 {
+		for _, stats := range s.PromptStats {
+			data.PromptComparison = append(data.PromptComparison, promptJSON{
+				Prompt:          string(stats.Prompt),
+				Evaluated:       stats.Evaluated,
+				Improved:        stats.Improved,
+				ImprovedPercent: stats.ImprovedRate() * 100,
+			})
+			data.Message += fmt.Sprintf("; %s improved %.1f%%", stats.Prompt, stats.ImprovedRate()*100)
+		}
 	}
 
 	bytes, err := json.Marshal(data)
@@ -933,7 +1731,7 @@ func (e *ClassificationEngine) RerankLowConfidenceTransactions(ctx context.Conte
 	startTime := time.Now()
 
 	// Get low confidence classifications
-	classifications, err := e.storage.GetClassificationsByConfidence(ctx, opts.ConfidenceThreshold, true)
+	classifications, err := e.storage.GetClassificationsByConfidence(ctx, opts.ConfidenceThreshold, !opts.IncludeUserModified)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get low confidence classifications: %w", err)
 	}
@@ -949,13 +1747,15 @@ func (e *ClassificationEngine) RerankLowConfidenceTransactions(ctx context.Conte
 	// Extract transactions from classifications
 	transactions := make([]model.Transaction, len(classifications))
 	oldConfidences := make(map[string]float64)
+	oldClassifications := make(map[string]model.Classification, len(classifications))
 	for i, c := range classifications {
 		transactions[i] = c.Transaction
 		oldConfidences[c.Transaction.ID] = c.Confidence
+		oldClassifications[c.Transaction.ID] = c
 	}
 
 	// Group by merchant for batch processing
-	merchantGroups := e.groupByMerchant(transactions)
+	merchantGroups := e.splitMultiCategoryGroups(ctx, e.groupByMerchant(transactions))
 	sortedMerchants := e.sortMerchantsByVolume(merchantGroups)
 
 	// Get categories
@@ -969,16 +1769,46 @@ func (e *ClassificationEngine) RerankLowConfidenceTransactions(ctx context.Conte
 		AutoAcceptThreshold: opts.AutoAcceptThreshold,
 		BatchSize:           opts.BatchSize,
 		ParallelWorkers:     opts.ParallelWorkers,
+		MaxInflightLLM:      opts.MaxInflightLLM,
 		SkipManualReview:    opts.SkipManualReview,
 	}
 
-	results := e.processMerchantsParallel(ctx, sortedMerchants, merchantGroups, categories, batchOpts)
+	prompt := opts.Prompt
+	if prompt == "" {
+		prompt = RerankPromptReconsider
+	}
+	guesses := previousGuesses(merchantGroups, oldClassifications)
 
-	// Process results and calculate improvements
+	// Each prompt re-ranks its own merchants, so batches never mix prompts
+	arms := rerankArms(sortedMerchants, prompt)
 	summary := &RerankSummary{
 		TotalEvaluated: len(transactions),
-		ProcessingTime: time.Since(startTime),
+		Prompt:         prompt,
+		PromptStats:    make([]RerankPromptStats, len(arms)),
+	}
+	var results []BatchResult
+	armStats := make(map[string]*RerankPromptStats)
+	// Progress counts every arm's merchants together
+	batchOpts.progress = newBatchProgress(len(sortedMerchants), batchOpts.OnProgress)
+	for i, arm := range arms {
+		armOpts := batchOpts
+		if arm.prompt == RerankPromptReconsider {
+			armOpts.PreviousGuesses = guesses
+		}
+		armResults, err := e.processMerchantsParallel(ctx, arm.merchants, merchantGroups, categories, armOpts)
+		if err != nil {
+			return nil, fmt.Errorf("rerank stopped: %w", err)
+		}
+		results = append(results, armResults...)
+
+		stats := &summary.PromptStats[i]
+		stats.Prompt = arm.prompt
+		for _, merchant := range arm.merchants {
+			stats.Evaluated += len(merchantGroups[merchant])
+			armStats[merchant] = stats
+		}
 	}
+	summary.ProcessingTime = time.Since(startTime)
 
 	var totalImprovement float64
 	autoAccepted := make([]BatchResult, 0)
@@ -1010,6 +1840,9 @@ func (e *ClassificationEngine) RerankLowConfidenceTransactions(ctx context.Conte
 			for range result.Transactions {
 				summary.ImprovedCount++
 			}
+			if stats := armStats[result.Merchant]; stats != nil {
+				stats.Improved += len(result.Transactions)
+			}
 
 			// Only add to processing lists if improved
 			if result.AutoAccepted {
@@ -1037,7 +1870,7 @@ func (e *ClassificationEngine) RerankLowConfidenceTransactions(ctx context.Conte
 	// Process auto-accepted improvements
 	if len(autoAccepted) > 0 {
 		slog.Info("Auto-accepting improved classifications", "count", len(autoAccepted))
-		if err := e.saveAutoAcceptedBatch(ctx, autoAccepted); err != nil {
+		if err := e.saveAutoAcceptedBatch(ctx, autoAccepted, DefaultSaveBatchSize); err != nil {
 			slog.Error("Failed to save auto-accepted improvements", "error", err)
 		}
 	}
@@ -1050,7 +1883,7 @@ func (e *ClassificationEngine) RerankLowConfidenceTransactions(ctx context.Conte
 		if err != nil {
 			return summary, fmt.Errorf("failed to get categories for review: %w", err)
 		}
-		if err := e.handleBatchReview(ctx, needsReview, categories); err != nil {
+		if err := e.handleBatchReview(ctx, needsReview, categories, DefaultCategoryRetries, 0, false); err != nil {
 			slog.Error("Failed to process manual review improvements", "error", err)
 		}
 	}
@@ -75,15 +75,15 @@ func TestEngine_BatchClassification_AIGeneratedDescription(t *testing.T) {
 
 		// Set up mock prompter that simulates user choosing new category with AI description
 		prompter := NewMockPrompter(false)
-		// The prompter will return a classification with NEW_CATEGORY| signal
+		// The prompter will return a classification requesting a new category
 		prompter.SetBatchResponse([]model.Classification{
 			{
-				Transaction:  txns[0],
-				Category:     "Vacation",
-				Status:       model.StatusUserModified,
-				Confidence:   1.0,
-				ClassifiedAt: time.Now(),
-				Notes:        "NEW_CATEGORY|", // Empty after pipe = AI should generate
+				Transaction:       txns[0],
+				Category:          "Vacation",
+				Status:            model.StatusUserModified,
+				Confidence:        1.0,
+				ClassifiedAt:      time.Now(),
+				CreateNewCategory: true, // No description = AI should generate
 			},
 			{
 				Transaction:  txns[1],
@@ -172,12 +172,13 @@ func TestEngine_BatchClassification_AIGeneratedDescription(t *testing.T) {
 		userDescription := "Expenses for home repairs, renovations, and improvements"
 		prompter.SetBatchResponse([]model.Classification{
 			{
-				Transaction:  txn,
-				Category:     "Home Improvement",
-				Status:       model.StatusUserModified,
-				Confidence:   1.0,
-				ClassifiedAt: time.Now(),
-				Notes:        "NEW_CATEGORY|" + userDescription, // User provided description
+				Transaction:            txn,
+				Category:               "Home Improvement",
+				Status:                 model.StatusUserModified,
+				Confidence:             1.0,
+				ClassifiedAt:           time.Now(),
+				CreateNewCategory:      true,
+				NewCategoryDescription: userDescription, // User provided description
 			},
 		})
 
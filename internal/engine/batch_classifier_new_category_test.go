@@ -81,12 +81,13 @@ func TestBatchClassifier_NewCategoryCreation(t *testing.T) {
 		prompter := NewMockPrompter(false)
 		prompter.SetBatchResponse([]model.Classification{
 			{
-				Transaction:  txns[0],
-				Category:     "Hobbies",
-				Status:       model.StatusUserModified,
-				Confidence:   1.0,
-				ClassifiedAt: time.Now(),
-				Notes:        "NEW_CATEGORY|User provided description for hobbies",
+				Transaction:            txns[0],
+				Category:               "Hobbies",
+				Status:                 model.StatusUserModified,
+				Confidence:             1.0,
+				ClassifiedAt:           time.Now(),
+				CreateNewCategory:      true,
+				NewCategoryDescription: "User provided description for hobbies",
 			},
 		})
 
@@ -141,6 +142,7 @@ func TestBatchClassifier_NewCategoryCreation(t *testing.T) {
 			if c.Transaction.MerchantName == "Specialty Store" {
 				assert.Equal(t, "Hobbies", c.Category)
 				assert.Equal(t, model.StatusUserModified, c.Status)
+				assert.Empty(t, c.Notes, "new category request must not be stored in notes")
 				classifiedCount++
 			}
 		}
@@ -211,7 +213,7 @@ func TestBatchClassifier_NewCategoryCreation(t *testing.T) {
 		require.NoError(t, catErr)
 
 		// Call handleBatchReview directly to test the new category creation
-		err = engine.handleBatchReview(ctx, results, categories)
+		err = engine.handleBatchReview(ctx, results, categories, 0, 0, false)
 		require.NoError(t, err)
 
 		// Verify category was created with AI description
@@ -242,12 +244,12 @@ func TestBatchClassifier_NewCategoryCreation(t *testing.T) {
 		prompter := NewMockPrompter(false)
 		prompter.SetBatchResponse([]model.Classification{
 			{
-				Transaction:  txn,
-				Category:     "Food",
-				Status:       model.StatusUserModified,
-				Confidence:   1.0,
-				ClassifiedAt: time.Now(),
-				Notes:        "NEW_CATEGORY|", // Signal new category but it already exists
+				Transaction:       txn,
+				Category:          "Food",
+				Status:            model.StatusUserModified,
+				Confidence:        1.0,
+				ClassifiedAt:      time.Now(),
+				CreateNewCategory: true, // Request new category but it already exists
 			},
 		})
 
@@ -268,7 +270,7 @@ func TestBatchClassifier_NewCategoryCreation(t *testing.T) {
 		}
 
 		// Should not error even though trying to create existing category
-		err = engine.handleBatchReview(ctx, results, categories)
+		err = engine.handleBatchReview(ctx, results, categories, 0, 0, false)
 		assert.NoError(t, err)
 
 		// Verify transaction was classified
@@ -288,4 +290,155 @@ func TestBatchClassifier_NewCategoryCreation(t *testing.T) {
 		}
 		assert.True(t, found, "Transaction should be classified")
 	})
+
+	t.Run("create new category with type and business percent", func(t *testing.T) {
+		txns := []model.Transaction{
+			{
+				ID:           "typed-cat-income",
+				Hash:         "hash-typed-income",
+				Date:         time.Now(),
+				Name:         "ACME PAYROLL",
+				MerchantName: "Acme Payroll",
+				Amount:       2500.00,
+				AccountID:    "test-account",
+				Direction:    model.DirectionIncome,
+			},
+			{
+				ID:           "typed-cat-expense",
+				Hash:         "hash-typed-expense",
+				Date:         time.Now(),
+				Name:         "WEWORK",
+				MerchantName: "WeWork",
+				Amount:       400.00,
+				AccountID:    "test-account",
+				Direction:    model.DirectionExpense,
+			},
+		}
+		err = db.SaveTransactions(ctx, txns)
+		require.NoError(t, err)
+
+		prompter := NewMockPrompter(false)
+		engine := New(db, NewMockClassifier(), prompter)
+
+		categories, catErr := db.GetCategories(ctx)
+		require.NoError(t, catErr)
+
+		requests := []model.Classification{
+			{
+				Transaction:            txns[0],
+				Category:               "Salary",
+				Status:                 model.StatusUserModified,
+				Confidence:             1.0,
+				CreateNewCategory:      true,
+				NewCategoryDescription: "Paychecks",
+				NewCategoryType:        model.CategoryTypeIncome,
+			},
+			{
+				Transaction:                txns[1],
+				Category:                   "Coworking",
+				Status:                     model.StatusUserModified,
+				Confidence:                 1.0,
+				CreateNewCategory:          true,
+				NewCategoryDescription:     "Shared office space",
+				NewCategoryType:            model.CategoryTypeExpense,
+				NewCategoryBusinessPercent: 60,
+			},
+		}
+		for i, request := range requests {
+			prompter.SetBatchResponse([]model.Classification{request})
+			err = engine.handleBatchReview(ctx, []BatchResult{
+				{Merchant: txns[i].MerchantName, Transactions: []model.Transaction{txns[i]}},
+			}, categories, 0, 0, false)
+			require.NoError(t, err)
+		}
+
+		salary, getErr := db.GetCategoryByName(ctx, "Salary")
+		require.NoError(t, getErr)
+		assert.Equal(t, model.CategoryTypeIncome, salary.Type)
+		assert.Equal(t, 0, salary.DefaultBusinessPercent)
+
+		coworking, getErr := db.GetCategoryByName(ctx, "Coworking")
+		require.NoError(t, getErr)
+		assert.Equal(t, model.CategoryTypeExpense, coworking.Type)
+		assert.Equal(t, 60, coworking.DefaultBusinessPercent)
+	})
+}
+
+// categoryErrorRecorder records the category errors reported to the user.
+type categoryErrorRecorder struct {
+	*MockPrompter
+	reported []string
+}
+
+func (r *categoryErrorRecorder) ReportCategoryError(_ context.Context, category string, _ error) error {
+	r.reported = append(r.reported, category)
+	return nil
+}
+
+func TestHandleBatchReview_CategoryRetry(t *testing.T) {
+	ctx := context.Background()
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+	defer func() { _ = db.Close() }()
+
+	_, err = db.CreateCategory(ctx, "Food", "Dining and groceries")
+	require.NoError(t, err)
+	categories, err := db.GetCategories(ctx)
+	require.NoError(t, err)
+
+	txn := model.Transaction{
+		ID: "retry-1", Hash: "hash-retry-1", Date: time.Now(), Name: "Bistro",
+		MerchantName: "Bistro", Amount: 30, AccountID: "acc", Direction: model.DirectionExpense,
+	}
+	require.NoError(t, db.SaveTransactions(ctx, []model.Transaction{txn}))
+	results := func() []BatchResult {
+		return []BatchResult{{
+			Merchant:     "Bistro",
+			Transactions: []model.Transaction{txn},
+			Suggestion:   &model.CategoryRanking{Category: "Food", Score: 0.7},
+		}}
+	}
+	// A new category that only differs in case from an existing one
+	collision := []model.Classification{{
+		Transaction:       txn,
+		Category:          "food",
+		Status:            model.StatusUserModified,
+		Confidence:        1.0,
+		CreateNewCategory: true,
+	}}
+
+	t.Run("without retries the merchant is skipped", func(t *testing.T) {
+		prompter := NewMockPrompter(true)
+		prompter.SetBatchResponse(collision)
+		engine := &ClassificationEngine{storage: db, classifier: NewMockClassifier(), prompter: prompter}
+
+		require.NoError(t, engine.handleBatchReview(ctx, results(), categories, 0, 0, false))
+		assert.Equal(t, 1, prompter.BatchConfirmCallCount())
+		assert.Empty(t, savedClassifications(t, db), "nothing is saved")
+	})
+
+	t.Run("the merchant is reviewed again", func(t *testing.T) {
+		prompter := &categoryErrorRecorder{MockPrompter: NewMockPrompter(true)}
+		prompter.SetBatchResponse(collision)
+		engine := &ClassificationEngine{storage: db, classifier: NewMockClassifier(), prompter: prompter}
+
+		require.NoError(t, engine.handleBatchReview(ctx, results(), categories, DefaultCategoryRetries, 0, false))
+		assert.Equal(t, 2, prompter.BatchConfirmCallCount())
+		assert.Equal(t, []string{"food"}, prompter.reported)
+
+		// The second review picked the existing category
+		saved := savedClassifications(t, db)
+		require.Len(t, saved, 1)
+		assert.Equal(t, "Food", saved[0].Category)
+		_, err := db.GetCategoryByName(ctx, "food")
+		assert.ErrorIs(t, err, storage.ErrCategoryNotFound)
+	})
+}
+
+func savedClassifications(t *testing.T, db *storage.SQLiteStorage) []model.Classification {
+	t.Helper()
+	classifications, err := db.GetClassificationsByDateRange(context.Background(), time.Now().AddDate(-1, 0, 0), time.Now().AddDate(0, 0, 1))
+	require.NoError(t, err)
+	return classifications
 }
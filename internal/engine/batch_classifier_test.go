@@ -2,9 +2,12 @@ package engine
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/Veraticus/the-spice-must-flow/internal/llm"
 	"github.com/Veraticus/the-spice-must-flow/internal/model"
 	"github.com/Veraticus/the-spice-must-flow/internal/storage"
 	"github.com/stretchr/testify/assert"
@@ -146,6 +149,35 @@ func TestProcessMerchantBatch(t *testing.T) {
 		// The mock classifier should apply check pattern boosting
 		assert.Equal(t, "Rent", results[0].Suggestion.Category)
 	})
+
+	// Test 5: Weak check patterns suggest at their own confidence
+	t.Run("low confidence check pattern", func(t *testing.T) {
+		// The earlier rent pattern (450-550) does not match this amount
+		pattern := &model.CheckPattern{
+			Category:    "Rent",
+			PatternName: "Any large check",
+			AmountMin:   floatPtr(100),
+			AmountMax:   floatPtr(1000),
+			Confidence:  0.7,
+		}
+		require.NoError(t, db.CreateCheckPattern(ctx, pattern))
+
+		checkGroups := map[string][]model.Transaction{
+			"CHECK 2000": {
+				{ID: "tx6", MerchantName: "CHECK 2000", Amount: 300.00, Type: "CHECK", CheckNumber: "2000"},
+			},
+		}
+
+		reviewOpts := opts
+		reviewOpts.AutoAcceptThreshold = 0.95
+		results := engine.processMerchantBatch(ctx, []string{"CHECK 2000"}, checkGroups, categories, reviewOpts)
+
+		require.Len(t, results, 1)
+		require.NotNil(t, results[0].Suggestion)
+		assert.Equal(t, "Rent", results[0].Suggestion.Category)
+		assert.Equal(t, 0.7, results[0].Suggestion.Score)
+		assert.False(t, results[0].AutoAccepted)
+	})
 }
 
 func TestBatchWorker(t *testing.T) {
@@ -263,7 +295,8 @@ func TestProcessMerchantsParallel(t *testing.T) {
 		ParallelWorkers: 2,
 	}
 
-	results := engine.processMerchantsParallel(ctx, merchants, merchantGroups, categories, opts)
+	results, err := engine.processMerchantsParallel(ctx, merchants, merchantGroups, categories, opts)
+	require.NoError(t, err)
 
 	assert.Len(t, results, 5)
 
@@ -280,6 +313,136 @@ func TestProcessMerchantsParallel(t *testing.T) {
 	}
 }
 
+func TestProcessMerchantsParallel_OnProgress(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+
+	engine := &ClassificationEngine{
+		storage:    db,
+		classifier: NewMockClassifier(),
+	}
+
+	var merchants []string
+	merchantGroups := make(map[string][]model.Transaction)
+	for i := 1; i <= 20; i++ {
+		m := fmt.Sprintf("M%d", i)
+		merchants = append(merchants, m)
+		merchantGroups[m] = []model.Transaction{
+			{ID: m + "-tx1", MerchantName: m, Amount: 50.00},
+		}
+	}
+	categories := []model.Category{
+		{Name: "Test", Description: "Test category"},
+	}
+
+	// The callback isn't locked, so the race detector catches overlapping calls
+	var done []int
+	reported := make(map[string]bool)
+	opts := BatchClassificationOptions{
+		BatchSize:       3,
+		ParallelWorkers: 4,
+		OnProgress: func(d, total int, merchant string) {
+			assert.Equal(t, 20, total)
+			done = append(done, d)
+			reported[merchant] = true
+		},
+	}
+
+	_, err = engine.processMerchantsParallel(ctx, merchants, merchantGroups, categories, opts)
+	require.NoError(t, err)
+
+	require.Len(t, done, 20)
+	for i, d := range done {
+		assert.Equal(t, i+1, d)
+	}
+	for _, m := range merchants {
+		assert.True(t, reported[m], "no progress reported for %s", m)
+	}
+
+	// A nil callback reports nothing
+	opts.OnProgress = nil
+	_, err = engine.processMerchantsParallel(ctx, merchants, merchantGroups, categories, opts)
+	require.NoError(t, err)
+}
+
+// inflightClassifier records the most LLM batch calls running at once.
+type inflightClassifier struct {
+	*MockClassifier
+	mu          sync.Mutex
+	inflight    int
+	maxInflight int
+}
+
+func (c *inflightClassifier) SuggestCategoryBatch(ctx context.Context, requests []llm.MerchantBatchRequest, categories []model.Category) (map[string]model.CategoryRankings, error) {
+	c.mu.Lock()
+	c.inflight++
+	c.maxInflight = max(c.maxInflight, c.inflight)
+	c.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	c.mu.Lock()
+	c.inflight--
+	c.mu.Unlock()
+	return c.MockClassifier.SuggestCategoryBatch(ctx, requests, categories)
+}
+
+func TestProcessMerchantsParallel_MaxInflightLLM(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+
+	merchants := []string{"M1", "M2", "M3", "M4", "M5", "M6", "M7", "M8"}
+	merchantGroups := make(map[string][]model.Transaction)
+	for _, m := range merchants {
+		merchantGroups[m] = []model.Transaction{
+			{ID: m + "-tx1", MerchantName: m, Amount: 50.00},
+		}
+	}
+	categories := []model.Category{
+		{Name: "Test", Description: "Test category"},
+	}
+
+	tests := []struct {
+		name           string
+		maxInflightLLM int
+		wantMax        int
+	}{
+		{name: "bounded below workers", maxInflightLLM: 1, wantMax: 1},
+		{name: "defaults to workers", maxInflightLLM: 0, wantMax: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			classifier := &inflightClassifier{MockClassifier: NewMockClassifier()}
+			engine := &ClassificationEngine{
+				storage:    db,
+				classifier: classifier,
+			}
+
+			opts := BatchClassificationOptions{
+				BatchSize:       1,
+				ParallelWorkers: 4,
+				MaxInflightLLM:  tt.maxInflightLLM,
+			}
+
+			results, err := engine.processMerchantsParallel(ctx, merchants, merchantGroups, categories, opts)
+			require.NoError(t, err)
+			assert.Len(t, results, len(merchants))
+			for _, result := range results {
+				assert.NoError(t, result.Error)
+			}
+			assert.LessOrEqual(t, classifier.maxInflight, tt.wantMax)
+			assert.Positive(t, classifier.maxInflight)
+		})
+	}
+}
+
 func TestClassifyTransactionsBatch(t *testing.T) {
 	ctx := context.Background()
 
@@ -367,3 +530,476 @@ func TestClassifyTransactionsBatch(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 0, len(txns)) // All should be classified
 }
+
+func TestClassifySpecificTransactions_DryRun(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+
+	for _, name := range []string{"Groceries", "Shopping"} {
+		_, createErr := db.CreateCategoryWithType(ctx, name, name, model.CategoryTypeExpense)
+		require.NoError(t, createErr)
+	}
+
+	transactions := []model.Transaction{
+		{ID: "tx1", Hash: "hash1", Name: "WALMART", MerchantName: "Walmart", Amount: 50, Type: "DEBIT", Date: time.Now(), AccountID: "acc1"},
+		{ID: "tx2", Hash: "hash2", Name: "TARGET", MerchantName: "Target", Amount: 20, Type: "DEBIT", Date: time.Now(), AccountID: "acc1"},
+	}
+	require.NoError(t, db.SaveTransactions(ctx, transactions))
+
+	engine := &ClassificationEngine{
+		storage:    db,
+		classifier: NewMockClassifier(),
+		prompter:   NewMockPrompter(true),
+	}
+
+	var collected []BatchResult
+	opts := BatchClassificationOptions{
+		AutoAcceptThreshold: 0.80,
+		BatchSize:           5,
+		ParallelWorkers:     1,
+		DryRun:              true,
+		ResultCollector: func(result BatchResult) {
+			collected = append(collected, result)
+		},
+	}
+
+	summary, err := engine.ClassifySpecificTransactions(ctx, transactions, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.TotalMerchants)
+
+	// Every merchant result is reported to the collector
+	require.Len(t, collected, 2)
+	for _, result := range collected {
+		assert.NoError(t, result.Error)
+		assert.NotNil(t, result.Suggestion)
+	}
+
+	// Nothing is saved in dry-run mode
+	txns, err := db.GetTransactionsToClassify(ctx, nil)
+	require.NoError(t, err)
+	assert.Len(t, txns, 2)
+}
+
+func TestClassifySpecificTransactions_ReviewNewMerchants(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+
+	for _, name := range []string{"Groceries", "Gas"} {
+		_, createErr := db.CreateCategoryWithType(ctx, name, name, model.CategoryTypeExpense)
+		require.NoError(t, createErr)
+	}
+
+	// Walmart was classified in an earlier run; Shell has never been seen
+	previous := model.Transaction{ID: "old1", Hash: "old-hash", Name: "WALMART", MerchantName: "Walmart", Amount: 30, Type: "DEBIT", Date: time.Now().AddDate(0, -1, 0), AccountID: "acc1"}
+	require.NoError(t, db.SaveTransactions(ctx, []model.Transaction{previous}))
+	require.NoError(t, db.SaveClassification(ctx, &model.Classification{
+		Transaction:  previous,
+		Category:     "Groceries",
+		Status:       model.StatusUserModified,
+		Confidence:   1.0,
+		ClassifiedAt: time.Now(),
+	}))
+
+	transactions := []model.Transaction{
+		{ID: "tx1", Hash: "hash1", Name: "WALMART", MerchantName: "Walmart", Amount: 50, Type: "DEBIT", Date: time.Now(), AccountID: "acc1"},
+		{ID: "tx2", Hash: "hash2", Name: "SHELL", MerchantName: "Shell", Amount: 40, Type: "DEBIT", Date: time.Now(), AccountID: "acc1"},
+	}
+	require.NoError(t, db.SaveTransactions(ctx, transactions))
+
+	engine := &ClassificationEngine{
+		storage:    db,
+		classifier: NewMockClassifier(),
+		prompter:   NewMockPrompter(true),
+	}
+
+	tests := []struct {
+		name               string
+		wantNewMerchants   []string
+		wantAutoAccepted   int
+		reviewNewMerchants bool
+	}{
+		{name: "disabled auto-accepts confident merchants", reviewNewMerchants: false, wantAutoAccepted: 2},
+		{name: "enabled holds merchants without history", reviewNewMerchants: true, wantAutoAccepted: 1, wantNewMerchants: []string{"Shell"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var newMerchants []string
+			opts := BatchClassificationOptions{
+				AutoAcceptThreshold: 0.80,
+				BatchSize:           5,
+				ParallelWorkers:     1,
+				DryRun:              true,
+				ReviewNewMerchants:  tt.reviewNewMerchants,
+				ResultCollector: func(result BatchResult) {
+					if result.NewMerchant {
+						newMerchants = append(newMerchants, result.Merchant)
+						assert.False(t, result.AutoAccepted)
+					}
+				},
+			}
+
+			summary, err := engine.ClassifySpecificTransactions(ctx, transactions, opts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAutoAccepted, summary.AutoAcceptedCount)
+			assert.Equal(t, len(tt.wantNewMerchants), summary.NewMerchantCount)
+			assert.Equal(t, tt.wantNewMerchants, newMerchants)
+		})
+	}
+}
+
+func TestProcessMerchantBatch_DirectionMismatch(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+
+	_, err = db.CreateCategoryWithType(ctx, "Groceries", "Food", model.CategoryTypeExpense)
+	require.NoError(t, err)
+	_, err = db.CreateCategoryWithType(ctx, "Salary", "Paychecks", model.CategoryTypeIncome)
+	require.NoError(t, err)
+
+	expense := model.Transaction{ID: "tx1", Hash: "hash1", Name: "WHOLE FOODS", MerchantName: "Whole Foods", Amount: 85, Type: "DEBIT", Direction: model.DirectionExpense, Date: time.Now(), AccountID: "acc1"}
+	require.NoError(t, db.SaveTransactions(ctx, []model.Transaction{expense}))
+
+	tests := []struct {
+		name         string
+		rankings     model.CategoryRankings
+		wantCategory string
+	}{
+		{
+			name: "falls back to best expense category",
+			rankings: model.CategoryRankings{
+				{Category: "Salary", Score: 0.97},
+				{Category: "Groceries", Score: 0.91},
+			},
+			wantCategory: "Groceries",
+		},
+		{
+			name: "keeps suggestion for review when nothing matches direction",
+			rankings: model.CategoryRankings{
+				{Category: "Salary", Score: 0.97},
+			},
+			wantCategory: "Salary",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			classifier := NewMockClassifier()
+			classifier.SetBatchResponse(map[string]model.CategoryRankings{"Whole Foods": tt.rankings})
+
+			engine := &ClassificationEngine{
+				storage:    db,
+				classifier: classifier,
+				prompter:   NewMockPrompter(true),
+			}
+
+			var collected []BatchResult
+			summary, err := engine.ClassifySpecificTransactions(ctx, []model.Transaction{expense}, BatchClassificationOptions{
+				AutoAcceptThreshold: 0.80,
+				BatchSize:           5,
+				ParallelWorkers:     1,
+				DryRun:              true,
+				ResultCollector: func(result BatchResult) {
+					collected = append(collected, result)
+				},
+			})
+			require.NoError(t, err)
+
+			require.Len(t, collected, 1)
+			result := collected[0]
+			require.NoError(t, result.Error)
+			require.NotNil(t, result.Suggestion)
+			assert.Equal(t, tt.wantCategory, result.Suggestion.Category)
+			assert.True(t, result.DirectionMismatch)
+			assert.False(t, result.AutoAccepted, "direction mismatches must be reviewed")
+			assert.Equal(t, 0, summary.AutoAcceptedCount)
+			assert.Equal(t, 1, summary.NeedsReviewCount)
+		})
+	}
+}
+
+// chunkStoppingPrompter accepts every suggestion and answers continue prompts in order.
+type chunkStoppingPrompter struct {
+	*MockPrompter
+	answers []bool
+	asked   [][2]int
+}
+
+func (p *chunkStoppingPrompter) ConfirmContinueReview(_ context.Context, reviewed, remaining int) (bool, error) {
+	p.asked = append(p.asked, [2]int{reviewed, remaining})
+	answer := p.answers[0]
+	p.answers = p.answers[1:]
+	return answer, nil
+}
+
+func TestClassifyTransactionsBatch_ReviewChunks(t *testing.T) {
+	merchants := []string{"Walmart", "Shell", "Target", "Costco", "Safeway"}
+
+	setup := func(t *testing.T) (*storage.SQLiteStorage, []model.Transaction) {
+		t.Helper()
+		ctx := context.Background()
+		db, err := storage.NewSQLiteStorage(":memory:")
+		require.NoError(t, err)
+		require.NoError(t, db.Migrate(ctx))
+		_, err = db.CreateCategoryWithType(ctx, "Shopping", "Shopping", model.CategoryTypeExpense)
+		require.NoError(t, err)
+
+		var transactions []model.Transaction
+		for i, merchant := range merchants {
+			transactions = append(transactions, model.Transaction{
+				ID: fmt.Sprintf("tx%d", i), Hash: fmt.Sprintf("hash%d", i), Name: merchant, MerchantName: merchant,
+				Amount: 10, Type: "DEBIT", Date: time.Now(), AccountID: "acc1",
+			})
+		}
+		require.NoError(t, db.SaveTransactions(ctx, transactions))
+		return db, transactions
+	}
+
+	opts := BatchClassificationOptions{
+		AutoAcceptThreshold: 2.0, // Force every merchant into review
+		BatchSize:           5,
+		ParallelWorkers:     1,
+		ReviewChunkSize:     2,
+	}
+
+	t.Run("stopping leaves remaining merchants unclassified", func(t *testing.T) {
+		ctx := context.Background()
+		db, _ := setup(t)
+		prompter := &chunkStoppingPrompter{MockPrompter: NewMockPrompter(true), answers: []bool{true, false}}
+		engine := &ClassificationEngine{storage: db, classifier: NewMockClassifier(), prompter: prompter}
+
+		summary, err := engine.ClassifyTransactionsBatch(ctx, nil, opts)
+		require.NoError(t, err)
+		assert.Equal(t, 1, summary.DeferredCount)
+		assert.Equal(t, [][2]int{{2, 3}, {4, 1}}, prompter.asked)
+
+		remaining, err := db.GetTransactionsToClassify(ctx, nil)
+		require.NoError(t, err)
+		assert.Len(t, remaining, 1, "only the unreviewed merchant should be left to resume")
+	})
+
+	t.Run("continuing reviews everything", func(t *testing.T) {
+		ctx := context.Background()
+		db, _ := setup(t)
+		prompter := &chunkStoppingPrompter{MockPrompter: NewMockPrompter(true), answers: []bool{true, true}}
+		engine := &ClassificationEngine{storage: db, classifier: NewMockClassifier(), prompter: prompter}
+
+		summary, err := engine.ClassifyTransactionsBatch(ctx, nil, opts)
+		require.NoError(t, err)
+		assert.Zero(t, summary.DeferredCount)
+		assert.Len(t, prompter.asked, 2)
+
+		remaining, err := db.GetTransactionsToClassify(ctx, nil)
+		require.NoError(t, err)
+		assert.Empty(t, remaining)
+	})
+
+	t.Run("prompters without pause support review in one session", func(t *testing.T) {
+		ctx := context.Background()
+		db, _ := setup(t)
+		engine := &ClassificationEngine{storage: db, classifier: NewMockClassifier(), prompter: NewMockPrompter(true)}
+
+		summary, err := engine.ClassifyTransactionsBatch(ctx, nil, opts)
+		require.NoError(t, err)
+		assert.Zero(t, summary.DeferredCount)
+
+		remaining, err := db.GetTransactionsToClassify(ctx, nil)
+		require.NoError(t, err)
+		assert.Empty(t, remaining)
+	})
+}
+
+func TestClassifyTransactionsBatch_StopOnError(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+	_, err = db.CreateCategoryWithType(ctx, "Test Category", "Test", model.CategoryTypeExpense)
+	require.NoError(t, err)
+
+	transactions := []model.Transaction{
+		{ID: "tx1", Hash: "hash1", Name: "ALPHA", MerchantName: "Alpha", Amount: 10, Date: time.Now(), AccountID: "acc1"},
+		{ID: "tx2", Hash: "hash2", Name: "BETA", MerchantName: "Beta", Amount: 20, Date: time.Now(), AccountID: "acc1"},
+		{ID: "tx3", Hash: "hash3", Name: "GAMMA", MerchantName: "Gamma", Amount: 30, Date: time.Now(), AccountID: "acc1"},
+	}
+	require.NoError(t, db.SaveTransactions(ctx, transactions))
+
+	opts := BatchClassificationOptions{
+		AutoAcceptThreshold: 0.80,
+		BatchSize:           1,
+		ParallelWorkers:     1,
+		SkipManualReview:    true,
+	}
+
+	t.Run("continues by default", func(t *testing.T) {
+		engine := New(db, &failingClassifier{failCount: 1}, NewMockPrompter(true))
+
+		summary, runErr := engine.ClassifySpecificTransactions(ctx, transactions, opts)
+		require.NoError(t, runErr)
+		assert.Equal(t, 1, summary.FailedCount)
+	})
+
+	t.Run("stops on first error", func(t *testing.T) {
+		require.NoError(t, db.ClearAllClassifications(ctx))
+		engine := New(db, &failingClassifier{failCount: 1}, NewMockPrompter(true))
+
+		stopOpts := opts
+		stopOpts.StopOnError = true
+		summary, runErr := engine.ClassifyTransactionsBatch(ctx, nil, stopOpts)
+		require.Error(t, runErr)
+		assert.Nil(t, summary)
+		assert.Contains(t, runErr.Error(), "temporary failure")
+
+		remaining, getErr := db.GetTransactionsToClassify(ctx, nil)
+		require.NoError(t, getErr)
+		assert.Len(t, remaining, 3, "nothing should be saved after stopping")
+	})
+}
+
+func TestClassifyTransactionsBatch_Explain(t *testing.T) {
+	ctx := context.Background()
+
+	for _, explain := range []bool{false, true} {
+		t.Run(fmt.Sprintf("explain=%t", explain), func(t *testing.T) {
+			db, err := storage.NewSQLiteStorage(":memory:")
+			require.NoError(t, err)
+			defer func() { _ = db.Close() }()
+			require.NoError(t, db.Migrate(ctx))
+
+			for _, name := range []string{"Groceries", "Shopping"} {
+				_, err = db.CreateCategoryWithType(ctx, name, name, model.CategoryTypeExpense)
+				require.NoError(t, err)
+			}
+
+			txns := []model.Transaction{
+				{ID: "tx1", Hash: "hash1", Name: "WALMART", MerchantName: "Walmart", Amount: 85, Type: "DEBIT", Direction: model.DirectionExpense, Date: time.Now(), AccountID: "acc1"},
+				{ID: "tx2", Hash: "hash2", Name: "WALMART", MerchantName: "Walmart", Amount: 40, Type: "DEBIT", Direction: model.DirectionExpense, Date: time.Now(), AccountID: "acc1"},
+			}
+			require.NoError(t, db.SaveTransactions(ctx, txns))
+
+			classifier := NewMockClassifier()
+			classifier.SetBatchResponse(map[string]model.CategoryRankings{"Walmart": {
+				{Category: "Shopping", Score: 0.30, Reason: "Also sells general merchandise"},
+				{Category: "Groceries", Score: 0.80, Reason: "Supermarket with weekly grocery amounts"},
+			}})
+			prompter := NewMockPrompter(true)
+			engine := New(db, classifier, prompter)
+
+			_, err = engine.ClassifyTransactionsBatch(ctx, nil, BatchClassificationOptions{
+				AutoAcceptThreshold: 0.95,
+				BatchSize:           5,
+				ParallelWorkers:     1,
+				Explain:             explain,
+			})
+			require.NoError(t, err)
+
+			calls := prompter.GetBatchConfirmCalls()
+			require.Len(t, calls, 1)
+			require.NotEmpty(t, calls[0].Pending)
+			rankings := calls[0].Pending[0].CategoryRankings
+
+			if !explain {
+				assert.Equal(t, model.CategoryRankings{{Category: "Groceries", Score: 0.80, Reason: "Supermarket with weekly grocery amounts"}}, rankings)
+				return
+			}
+			assert.Equal(t, model.CategoryRankings{
+				{Category: "Groceries", Score: 0.80, Reason: "Supermarket with weekly grocery amounts"},
+				{Category: "Shopping", Score: 0.30, Reason: "Also sells general merchandise"},
+			}, rankings)
+		})
+	}
+}
+
+func TestSaveAutoAcceptedBatch_SaveBatchSize(t *testing.T) {
+	ctx := context.Background()
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+	defer func() { _ = db.Close() }()
+
+	_, err = db.CreateCategory(ctx, "Groceries", "")
+	require.NoError(t, err)
+
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	var transactions []model.Transaction
+	for i := 0; i < 5; i++ {
+		transactions = append(transactions, model.Transaction{
+			ID: fmt.Sprintf("tx%d", i), Hash: fmt.Sprintf("hash%d", i), Name: "KROGER", MerchantName: "Kroger",
+			Amount: float64(10 + i), Type: "DEBIT", Date: date, AccountID: "acc1",
+		})
+	}
+	require.NoError(t, db.SaveTransactions(ctx, transactions))
+
+	engine := &ClassificationEngine{storage: db}
+	require.NoError(t, engine.saveAutoAcceptedBatch(ctx, []BatchResult{{
+		Merchant:     "Kroger",
+		Transactions: transactions,
+		Suggestion:   &model.CategoryRanking{Category: "Groceries", Score: 0.97},
+		AutoAccepted: true,
+	}}, 2))
+
+	remaining, err := db.GetTransactionsToClassify(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, remaining, "every batch, including the partial last one, is saved")
+
+	t.Run("failed batch is saved one by one", func(t *testing.T) {
+		classifications := []model.Classification{
+			{Transaction: transactions[0], Category: "Groceries", Status: model.StatusUserModified, Confidence: 1},
+			{Transaction: transactions[1], Category: "Missing", Status: model.StatusUserModified, Confidence: 1},
+			{Transaction: transactions[2], Category: "Groceries", Status: model.StatusUserModified, Confidence: 1},
+		}
+		assert.Equal(t, 2, engine.saveClassifications(ctx, classifications))
+	})
+}
+
+func TestSaveAutoAcceptedBatch_CheckPattern(t *testing.T) {
+	ctx := context.Background()
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+	defer func() { _ = db.Close() }()
+
+	_, err = db.CreateCategory(ctx, "Rent", "")
+	require.NoError(t, err)
+	require.NoError(t, db.CreateCheckPattern(ctx, &model.CheckPattern{
+		Category:    "Rent",
+		PatternName: "Monthly Rent",
+		AmountMin:   floatPtr(450),
+		AmountMax:   floatPtr(550),
+		Confidence:  0.9,
+	}))
+
+	txn := model.Transaction{
+		ID: "tx1", Hash: "hash1", Name: "CHECK 1234", MerchantName: "CHECK 1234", Amount: 500,
+		Type: "CHECK", CheckNumber: "1234", Date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), AccountID: "acc1",
+	}
+	require.NoError(t, db.SaveTransactions(ctx, []model.Transaction{txn}))
+
+	engine := &ClassificationEngine{storage: db}
+	opts := BatchClassificationOptions{AutoAcceptThreshold: 0.85}
+	result := BatchResult{Merchant: "CHECK 1234", Transactions: []model.Transaction{txn}}
+	require.True(t, engine.applyRules(ctx, &result, opts))
+	require.True(t, result.AutoAccepted)
+	require.InDelta(t, 0.9, result.Suggestion.Score, 0.0001)
+	require.NoError(t, engine.saveAutoAcceptedBatch(ctx, []BatchResult{result}, 0))
+
+	classifications, err := db.GetClassificationsByConfidence(ctx, 1.1, false)
+	require.NoError(t, err)
+	require.Len(t, classifications, 1)
+	assert.Equal(t, model.StatusClassifiedByRule, classifications[0].Status)
+	assert.InDelta(t, 0.9, classifications[0].Confidence, 0.0001)
+
+	vendors, err := db.GetAllVendors(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, vendors, "check pattern matches don't create vendor rules")
+}
@@ -0,0 +1,59 @@
+package engine
+
+import "github.com/Veraticus/the-spice-must-flow/internal/model"
+
+// calibrationBuckets is how many equal confidence ranges calibration splits
+// predictions into: deciles.
+const calibrationBuckets = 10
+
+// CalibrationBucket is the AI classifications whose confidence fell within
+// [Low, High), and how many of them kept the AI's category. The top bucket
+// includes a confidence of exactly 1.
+type CalibrationBucket struct {
+	Low            float64
+	High           float64
+	Predictions    int
+	Correct        int
+	MeanConfidence float64
+}
+
+// Accuracy is the share of the bucket's predictions that kept the AI's
+// category. A well calibrated bucket's accuracy is close to its mean
+// confidence.
+func (b CalibrationBucket) Accuracy() float64 {
+	if b.Predictions == 0 {
+		return 0
+	}
+	return float64(b.Correct) / float64(b.Predictions)
+}
+
+// CalibrateConfidence buckets AI classifications by confidence decile and
+// counts how many of each turned out correct. Every decile is returned, lowest
+// first, including empty ones.
+func CalibrateConfidence(outcomes []model.ConfidenceOutcome) []CalibrationBucket {
+	buckets := make([]CalibrationBucket, calibrationBuckets)
+	for i := range buckets {
+		buckets[i].Low = float64(i) / calibrationBuckets
+		buckets[i].High = float64(i+1) / calibrationBuckets
+	}
+
+	totals := make([]float64, calibrationBuckets)
+	for _, outcome := range outcomes {
+		// The epsilon keeps bounds such as 0.3, whose product falls just short, in the bucket above
+		i := int(outcome.Confidence*calibrationBuckets + 1e-9)
+		i = max(0, min(i, calibrationBuckets-1))
+
+		buckets[i].Predictions++
+		totals[i] += outcome.Confidence
+		if outcome.Correct() {
+			buckets[i].Correct++
+		}
+	}
+
+	for i := range buckets {
+		if buckets[i].Predictions > 0 {
+			buckets[i].MeanConfidence = totals[i] / float64(buckets[i].Predictions)
+		}
+	}
+	return buckets
+}
@@ -0,0 +1,107 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalibrateConfidence(t *testing.T) {
+	outcome := func(confidence float64, correct bool) model.ConfidenceOutcome {
+		final := "Groceries"
+		if !correct {
+			final = "Shopping"
+		}
+		return model.ConfidenceOutcome{AICategory: "Groceries", FinalCategory: final, Confidence: confidence}
+	}
+
+	buckets := CalibrateConfidence([]model.ConfidenceOutcome{
+		outcome(0.95, true),
+		outcome(1.0, true), // The top bucket includes 1
+		outcome(0.91, false),
+		outcome(0.55, false),
+		outcome(0.5, true), // Bounds belong to the bucket above
+		outcome(0.3, true),
+		outcome(0, false),
+	})
+	require.Len(t, buckets, 10)
+
+	top := buckets[9]
+	assert.InDelta(t, 0.9, top.Low, 0.001)
+	assert.InDelta(t, 1.0, top.High, 0.001)
+	assert.Equal(t, 3, top.Predictions)
+	assert.Equal(t, 2, top.Correct)
+	assert.InDelta(t, 2.0/3.0, top.Accuracy(), 0.001)
+	assert.InDelta(t, (0.95+1.0+0.91)/3, top.MeanConfidence, 0.001)
+
+	assert.Equal(t, 2, buckets[5].Predictions)
+	assert.Equal(t, 1, buckets[5].Correct)
+	assert.Equal(t, 1, buckets[3].Predictions)
+	assert.Equal(t, 1, buckets[0].Predictions)
+	assert.Zero(t, buckets[0].Accuracy())
+
+	assert.Zero(t, buckets[7].Predictions)
+	assert.Zero(t, buckets[7].Accuracy(), "an empty bucket has no accuracy")
+}
+
+func TestHandleBatchReview_RecordCalibration(t *testing.T) {
+	for _, record := range []bool{true, false} {
+		t.Run(fmt.Sprintf("record=%v", record), func(t *testing.T) {
+			ctx := context.Background()
+			db, err := storage.NewSQLiteStorage(":memory:")
+			require.NoError(t, err)
+			defer func() { _ = db.Close() }()
+			require.NoError(t, db.Migrate(ctx))
+
+			for _, name := range []string{"Food", "Shopping"} {
+				_, err = db.CreateCategory(ctx, name, name)
+				require.NoError(t, err)
+			}
+			categories, err := db.GetCategories(ctx)
+			require.NoError(t, err)
+
+			var results []BatchResult
+			for _, merchant := range []string{"Cafe", "Bistro"} {
+				txn := model.Transaction{
+					ID: merchant, Hash: "hash-" + merchant, Date: time.Now(), Name: merchant,
+					MerchantName: merchant, Amount: 20, AccountID: "acc", Direction: model.DirectionExpense,
+				}
+				require.NoError(t, db.SaveTransactions(ctx, []model.Transaction{txn}))
+				results = append(results, BatchResult{
+					Merchant:     merchant,
+					Transactions: []model.Transaction{txn},
+					Suggestion:   &model.CategoryRanking{Category: "Food", Score: 0.9},
+				})
+			}
+
+			// The review keeps the AI's category for Bistro but not for Cafe
+			prompter := NewMockPrompter(true)
+			prompter.SetCustomResponse("Cafe", "Shopping")
+			engine := &ClassificationEngine{storage: db, classifier: NewMockClassifier(), prompter: prompter}
+			require.NoError(t, engine.handleBatchReview(ctx, results, categories, 0, 0, record))
+
+			outcomes, err := db.GetConfidenceOutcomes(ctx)
+			require.NoError(t, err)
+			got := map[string]model.ConfidenceOutcome{}
+			for _, outcome := range outcomes {
+				got[outcome.TransactionID] = outcome
+			}
+
+			assert.True(t, got["Bistro"].Correct())
+			cafe, ok := got["Cafe"]
+			assert.Equal(t, record, ok, "the replaced suggestion is only kept when recording")
+			if ok {
+				assert.False(t, cafe.Correct())
+				assert.Equal(t, "Food", cafe.AICategory)
+				assert.Equal(t, "Shopping", cafe.FinalCategory)
+				assert.InDelta(t, 0.9, cafe.Confidence, 0.001)
+			}
+		})
+	}
+}
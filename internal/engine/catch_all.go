@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+// DefaultCatchAllCategory is the generic category suggestions are checked
+// against when BatchClassificationOptions.CatchAllCategory is empty.
+const DefaultCatchAllCategory = "Other"
+
+// catchAllCategory returns the name of the catch-all category.
+func catchAllCategory(opts BatchClassificationOptions) string {
+	if opts.CatchAllCategory == "" {
+		return DefaultCatchAllCategory
+	}
+	return opts.CatchAllCategory
+}
+
+// isCatchAll reports whether a result was suggested the catch-all category.
+func isCatchAll(result BatchResult, opts BatchClassificationOptions) bool {
+	return result.Suggestion != nil && strings.EqualFold(result.Suggestion.Category, catchAllCategory(opts))
+}
+
+// capCatchAll keeps the LLM from settling on the catch-all category with
+// confidence: a catch-all suggestion scoring above CatchAllMaxConfidence is
+// lowered to it and sent to review, where a better category can be picked.
+// The runner-up is logged as a hint.
+func capCatchAll(result *BatchResult, rankings model.CategoryRankings, opts BatchClassificationOptions) {
+	if opts.CatchAllMaxConfidence <= 0 || !isCatchAll(*result, opts) || result.Suggestion.Score <= opts.CatchAllMaxConfidence {
+		return
+	}
+
+	var runnerUp string
+	for _, ranking := range rankings {
+		if !strings.EqualFold(ranking.Category, catchAllCategory(opts)) {
+			runnerUp = fmt.Sprintf("%s (%.2f)", ranking.Category, ranking.Score)
+			break
+		}
+	}
+	slog.Info("catch-all suggestion sent to review",
+		"merchant", result.Merchant,
+		"category", result.Suggestion.Category,
+		"confidence", fmt.Sprintf("%.2f", result.Suggestion.Score),
+		"capped_at", fmt.Sprintf("%.2f", opts.CatchAllMaxConfidence),
+		"runner_up", runnerUp)
+
+	// Copy so the capped score doesn't leak into the caller's rankings
+	capped := *result.Suggestion
+	capped.Score = opts.CatchAllMaxConfidence
+	result.Suggestion = &capped
+	result.CatchAllCapped = true
+}
@@ -0,0 +1,81 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyTransactionsBatch_CatchAll(t *testing.T) {
+	ctx := context.Background()
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+	defer func() { _ = db.Close() }()
+
+	for _, name := range []string{"Gas Stations", "Shopping", "Misc"} {
+		_, err = db.CreateCategoryWithType(ctx, name, "", model.CategoryTypeExpense)
+		require.NoError(t, err)
+	}
+
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, db.SaveTransactions(ctx, []model.Transaction{
+		{ID: "gas", Hash: "hash-gas", Name: "SHELL", MerchantName: "Shell", Amount: 40, Type: "DEBIT", Date: date, AccountID: "acc1"},
+		{ID: "odd1", Hash: "hash-odd1", Name: "XQ*7781", MerchantName: "XQ 7781", Amount: 9, Type: "DEBIT", Date: date, AccountID: "acc1"},
+		{ID: "odd2", Hash: "hash-odd2", Name: "XQ*7781", MerchantName: "XQ 7781", Amount: 11, Type: "DEBIT", Date: date, AccountID: "acc1"},
+	}))
+
+	rankings := map[string]model.CategoryRankings{
+		"Shell":   {{Category: "Gas Stations", Score: 0.97}},
+		"XQ 7781": {{Category: "Misc", Score: 0.98}, {Category: "Shopping", Score: 0.4}},
+	}
+	classifier := NewMockClassifier()
+	classifier.SetBatchResponse(rankings)
+	prompter := NewMockPrompter(true)
+	engine := &ClassificationEngine{storage: db, classifier: classifier, prompter: prompter}
+
+	summary, err := engine.ClassifyTransactionsBatch(ctx, nil, BatchClassificationOptions{
+		AutoAcceptThreshold:   0.95,
+		BatchSize:             5,
+		ParallelWorkers:       1,
+		CatchAllCategory:      "misc",
+		CatchAllMaxConfidence: 0.5,
+		EscalateBelow:         0.6,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, summary.AutoAcceptedCount)
+	assert.Equal(t, 1, summary.NeedsReviewCount, "capped catch-all goes to review, not escalation")
+	assert.Equal(t, 0, summary.EscalatedCount)
+	assert.Equal(t, 1, summary.CatchAllCount)
+	assert.Equal(t, 2, summary.CatchAllTxns)
+	assert.Contains(t, summary.GetDisplay(), `"catch_all_transactions":2`)
+
+	calls := prompter.GetBatchConfirmCalls()
+	require.Len(t, calls, 1)
+	for _, pending := range calls[0].Pending {
+		assert.Equal(t, "XQ 7781", pending.Transaction.MerchantName)
+		assert.InDelta(t, 0.5, pending.Confidence, 0.001)
+	}
+	assert.InDelta(t, 0.98, rankings["XQ 7781"][0].Score, 0.001, "the LLM's rankings are left alone")
+
+	t.Run("without a cap the catch-all is only counted", func(t *testing.T) {
+		result := BatchResult{Suggestion: &model.CategoryRanking{Category: "Other", Score: 0.99}}
+		capCatchAll(&result, nil, BatchClassificationOptions{})
+		assert.False(t, result.CatchAllCapped)
+		assert.True(t, isCatchAll(result, BatchClassificationOptions{}))
+		assert.True(t, autoAcceptable(result, BatchClassificationOptions{AutoAcceptThreshold: 0.95}))
+	})
+
+	t.Run("suggestions under the cap are kept", func(t *testing.T) {
+		result := BatchResult{Suggestion: &model.CategoryRanking{Category: "Other", Score: 0.4}}
+		capCatchAll(&result, nil, BatchClassificationOptions{CatchAllMaxConfidence: 0.5})
+		assert.False(t, result.CatchAllCapped)
+		assert.InDelta(t, 0.4, result.Suggestion.Score, 0.001)
+	})
+}
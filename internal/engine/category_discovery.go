@@ -0,0 +1,217 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/llm"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
+)
+
+// DefaultDiscoveryMerchants is how many of the busiest merchants category
+// discovery shows the LLM.
+const DefaultDiscoveryMerchants = 50
+
+// StarterCategory is a category of the starter taxonomy offered to a database
+// without categories.
+type StarterCategory struct {
+	Name            string
+	Description     string
+	Type            model.CategoryType
+	BusinessPercent int
+}
+
+// StarterCategories is a small general-purpose taxonomy to classify against
+// before any categories exist. It's meant to be renamed, merged and extended.
+var StarterCategories = []StarterCategory{
+	{Name: "Groceries", Description: "Supermarkets and food bought to eat at home", Type: model.CategoryTypeExpense},
+	{Name: "Dining", Description: "Restaurants, cafes, bars and food delivery", Type: model.CategoryTypeExpense},
+	{Name: "Housing", Description: "Rent, mortgage payments, HOA fees and home maintenance", Type: model.CategoryTypeExpense},
+	{Name: "Utilities", Description: "Electricity, gas, water, trash, internet and phone bills", Type: model.CategoryTypeExpense},
+	{Name: "Transportation", Description: "Fuel, public transit, rideshare, parking, tolls and car maintenance", Type: model.CategoryTypeExpense},
+	{Name: "Shopping", Description: "Clothing, electronics, household goods and general retail", Type: model.CategoryTypeExpense},
+	{Name: "Healthcare", Description: "Doctors, dentists, pharmacies and medical bills", Type: model.CategoryTypeExpense},
+	{Name: "Insurance", Description: "Health, auto, home, renters and life insurance premiums", Type: model.CategoryTypeExpense},
+	{Name: "Entertainment", Description: "Movies, concerts, games, hobbies and events", Type: model.CategoryTypeExpense},
+	{Name: "Subscriptions", Description: "Streaming services, software and recurring memberships", Type: model.CategoryTypeExpense},
+	{Name: "Travel", Description: "Flights, hotels, rental cars and vacation expenses", Type: model.CategoryTypeExpense},
+	{Name: "Personal Care", Description: "Haircuts, gyms, cosmetics and personal services", Type: model.CategoryTypeExpense},
+	{Name: "Education", Description: "Tuition, courses, books and school supplies", Type: model.CategoryTypeExpense},
+	{Name: "Gifts & Donations", Description: "Presents and charitable contributions", Type: model.CategoryTypeExpense},
+	{Name: "Fees & Charges", Description: "Bank fees, interest charges and late fees", Type: model.CategoryTypeExpense},
+	{Name: "Taxes", Description: "Income, property and other tax payments", Type: model.CategoryTypeExpense},
+	{Name: "Business Expenses", Description: "Costs of running a business or freelance work", Type: model.CategoryTypeExpense, BusinessPercent: 100},
+	{Name: DefaultCatchAllCategory, Description: "Expenses that fit no other category", Type: model.CategoryTypeExpense},
+	{Name: "Salary", Description: "Paychecks and wages from an employer", Type: model.CategoryTypeIncome},
+	{Name: "Freelance Income", Description: "Payments from clients for contract or freelance work", Type: model.CategoryTypeIncome},
+	{Name: "Interest & Dividends", Description: "Interest, dividends and other investment income", Type: model.CategoryTypeIncome},
+	{Name: "Refunds & Reimbursements", Description: "Money returned for purchases or expenses paid back", Type: model.CategoryTypeIncome},
+	{Name: "Other Income", Description: "Income that fits no other category", Type: model.CategoryTypeIncome},
+	{Name: "Transfers", Description: "Money moved between your own accounts, including credit card payments", Type: model.CategoryTypeSystem},
+}
+
+// SeedStarterCategories creates the starter categories missing from the
+// database, matching names ignoring case, and returns the ones it created.
+func SeedStarterCategories(ctx context.Context, store service.Storage) ([]model.Category, error) {
+	return createMissingCategories(ctx, store, StarterCategories)
+}
+
+// createMissingCategories creates the categories whose names, ignoring case,
+// aren't in the database yet and returns the ones it created.
+func createMissingCategories(ctx context.Context, store service.Storage, categories []StarterCategory) ([]model.Category, error) {
+	existing, err := store.GetCategories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+	names := make(map[string]bool, len(existing))
+	for _, cat := range existing {
+		names[strings.ToLower(cat.Name)] = true
+	}
+
+	var created []model.Category
+	for _, starter := range categories {
+		if names[strings.ToLower(starter.Name)] {
+			continue
+		}
+		category, createErr := store.CreateCategoryWithType(ctx, starter.Name, starter.Description, starter.Type)
+		if createErr != nil {
+			return created, fmt.Errorf("failed to create category %q: %w", starter.Name, createErr)
+		}
+		if starter.BusinessPercent > 0 {
+			if err := store.UpdateCategoryBusinessPercent(ctx, category.ID, starter.BusinessPercent); err != nil {
+				return created, fmt.Errorf("failed to set business percentage of %q: %w", starter.Name, err)
+			}
+			category.DefaultBusinessPercent = starter.BusinessPercent
+		}
+		names[strings.ToLower(starter.Name)] = true
+		created = append(created, *category)
+	}
+	return created, nil
+}
+
+// CategoryProposal is a category the LLM proposed during category discovery.
+type CategoryProposal struct {
+	Name         string
+	Description  string
+	Type         model.CategoryType
+	Merchants    []string // Merchants it was proposed for, busiest first
+	Transactions int
+}
+
+// DiscoverCategories shows the LLM up to maxMerchants of the busiest
+// merchants left to classify from fromDate without offering it any categories,
+// and returns the categories it proposes, the most used first. Proposals of
+// the same name, ignoring case, are merged. Nothing is saved, so the
+// proposals can be approved before any are created.
+func (e *ClassificationEngine) DiscoverCategories(ctx context.Context, fromDate *time.Time, maxMerchants int, opts BatchClassificationOptions) ([]CategoryProposal, error) {
+	transactions, err := e.GetTransactionsToClassifyBetween(ctx, fromDate, opts.UntilDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+	if len(transactions) == 0 {
+		return nil, nil
+	}
+
+	merchantGroups := e.groupByMerchant(transactions)
+	merchants := e.sortMerchantsByVolume(merchantGroups)
+	if maxMerchants > 0 && len(merchants) > maxMerchants {
+		merchants = merchants[:maxMerchants]
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	hints := e.merchantHints(ctx)
+
+	proposals := make(map[string]*CategoryProposal)
+	for start := 0; start < len(merchants); start += batchSize {
+		batch := merchants[start:min(start+batchSize, len(merchants))]
+		requests := make([]llm.MerchantBatchRequest, 0, len(batch))
+		for _, merchant := range batch {
+			requests = append(requests, batchRequest(merchant, merchantGroups[merchant], hints, opts))
+		}
+
+		results, batchErr := e.classifier.SuggestCategoryBatch(ctx, requests, nil)
+		if batchErr != nil {
+			if errors.Is(batchErr, context.Canceled) {
+				return nil, batchErr
+			}
+			return nil, fmt.Errorf("failed to discover categories: %w", batchErr)
+		}
+
+		for _, merchant := range batch {
+			top := results[merchant].Top()
+			if top == nil || strings.TrimSpace(top.Category) == "" {
+				continue
+			}
+			name := strings.TrimSpace(top.Category)
+			txns := merchantGroups[merchant]
+
+			proposal, ok := proposals[strings.ToLower(name)]
+			if !ok {
+				proposal = &CategoryProposal{
+					Name:        name,
+					Description: top.Description,
+					Type:        proposalType(txns),
+				}
+				proposals[strings.ToLower(name)] = proposal
+			}
+			if proposal.Description == "" {
+				proposal.Description = top.Description
+			}
+			proposal.Merchants = append(proposal.Merchants, groupMerchantName(merchant))
+			proposal.Transactions += len(txns)
+		}
+	}
+
+	discovered := make([]CategoryProposal, 0, len(proposals))
+	for _, proposal := range proposals {
+		discovered = append(discovered, *proposal)
+	}
+	sort.Slice(discovered, func(i, j int) bool {
+		if discovered[i].Transactions != discovered[j].Transactions {
+			return discovered[i].Transactions > discovered[j].Transactions
+		}
+		return discovered[i].Name < discovered[j].Name
+	})
+	return discovered, nil
+}
+
+// CreateProposedCategories creates the approved proposals, skipping names that
+// already exist ignoring case, and returns the categories it created.
+func CreateProposedCategories(ctx context.Context, store service.Storage, proposals []CategoryProposal) ([]model.Category, error) {
+	categories := make([]StarterCategory, 0, len(proposals))
+	for _, proposal := range proposals {
+		categories = append(categories, StarterCategory{Name: proposal.Name, Description: proposal.Description, Type: proposal.Type})
+	}
+	return createMissingCategories(ctx, store, categories)
+}
+
+// proposalType is the type of a category proposed for txns: income when most
+// of them are income, as filterCategoriesByDirection decides it, and expense
+// otherwise.
+func proposalType(txns []model.Transaction) model.CategoryType {
+	switch dominantDirection(txns) {
+	case model.DirectionIncome:
+		return model.CategoryTypeIncome
+	case model.DirectionTransfer:
+		return model.CategoryTypeSystem
+	case "":
+		negative := 0
+		for _, txn := range txns {
+			if txn.Amount < 0 {
+				negative++
+			}
+		}
+		if negative > len(txns)-negative {
+			return model.CategoryTypeIncome
+		}
+	}
+	return model.CategoryTypeExpense
+}
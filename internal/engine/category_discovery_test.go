@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeedStarterCategories(t *testing.T) {
+	ctx := context.Background()
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+	defer func() { _ = db.Close() }()
+
+	_, err = db.CreateCategoryWithType(ctx, "groceries", "My groceries", model.CategoryTypeExpense)
+	require.NoError(t, err)
+
+	created, err := SeedStarterCategories(ctx, db)
+	require.NoError(t, err)
+	assert.Len(t, created, len(StarterCategories)-1, "existing names are skipped ignoring case")
+
+	business, err := db.GetCategoryByName(ctx, "Business Expenses")
+	require.NoError(t, err)
+	assert.Equal(t, 100, business.DefaultBusinessPercent)
+	salary, err := db.GetCategoryByName(ctx, "Salary")
+	require.NoError(t, err)
+	assert.Equal(t, model.CategoryTypeIncome, salary.Type)
+
+	again, err := SeedStarterCategories(ctx, db)
+	require.NoError(t, err)
+	assert.Empty(t, again)
+}
+
+func TestDiscoverCategories(t *testing.T) {
+	ctx := context.Background()
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+	defer func() { _ = db.Close() }()
+
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, db.SaveTransactions(ctx, []model.Transaction{
+		{ID: "tx1", Hash: "hash1", Name: "WALMART #1", MerchantName: "Walmart", Amount: 50, Type: "DEBIT", Date: date, AccountID: "acc1"},
+		{ID: "tx2", Hash: "hash2", Name: "WALMART #2", MerchantName: "Walmart", Amount: 75, Type: "DEBIT", Date: date, AccountID: "acc1"},
+		{ID: "tx3", Hash: "hash3", Name: "KROGER", MerchantName: "Kroger", Amount: 40, Type: "DEBIT", Date: date, AccountID: "acc1"},
+		{ID: "tx4", Hash: "hash4", Name: "ACME PAYROLL", MerchantName: "Acme Payroll", Amount: 3000, Type: "CREDIT", Direction: model.DirectionIncome, Date: date, AccountID: "acc1"},
+		{ID: "tx5", Hash: "hash5", Name: "TARGET", MerchantName: "Target", Amount: 30, Type: "DEBIT", Date: date, AccountID: "acc1"},
+	}))
+
+	classifier := NewMockClassifier()
+	classifier.SetBatchResponse(map[string]model.CategoryRankings{
+		"Walmart":      {{Category: "Groceries", Description: "Food for home", Score: 0.9, IsNew: true}},
+		"Kroger":       {{Category: "groceries ", Score: 0.8, IsNew: true}},
+		"Acme Payroll": {{Category: "Salary", Description: "Wages", Score: 0.95, IsNew: true}},
+	})
+	engine := &ClassificationEngine{storage: db, classifier: classifier, prompter: NewMockPrompter(true)}
+
+	proposals, err := engine.DiscoverCategories(ctx, nil, 0, BatchClassificationOptions{BatchSize: 5})
+	require.NoError(t, err)
+	assert.Equal(t, 4, classifier.CallCount())
+
+	require.Len(t, proposals, 2)
+	assert.Equal(t, "Groceries", proposals[0].Name, "names are merged ignoring case")
+	assert.Equal(t, "Food for home", proposals[0].Description)
+	assert.Equal(t, model.CategoryTypeExpense, proposals[0].Type)
+	assert.Equal(t, []string{"Walmart", "Kroger"}, proposals[0].Merchants)
+	assert.Equal(t, 3, proposals[0].Transactions)
+	assert.Equal(t, "Salary", proposals[1].Name)
+	assert.Equal(t, model.CategoryTypeIncome, proposals[1].Type)
+
+	classifier.SetBatchResponse(map[string]model.CategoryRankings{
+		"Walmart": {{Category: "Groceries", Score: 0.9, IsNew: true}},
+	})
+	busiest, err := engine.DiscoverCategories(ctx, nil, 1, BatchClassificationOptions{BatchSize: 5})
+	require.NoError(t, err)
+	assert.Equal(t, 5, classifier.CallCount(), "only the busiest merchants are shown to the AI")
+	require.Len(t, busiest, 1)
+	assert.Equal(t, []string{"Walmart"}, busiest[0].Merchants)
+
+	categories, err := db.GetCategories(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, categories, "discovery saves nothing")
+
+	created, err := CreateProposedCategories(ctx, db, proposals[1:])
+	require.NoError(t, err)
+	require.Len(t, created, 1)
+	assert.Equal(t, "Salary", created[0].Name)
+	assert.Equal(t, model.CategoryTypeIncome, created[0].Type)
+}
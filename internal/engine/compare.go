@@ -0,0 +1,159 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/llm"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+// ConfidenceBuckets are the lower bounds used for confidence distributions.
+var ConfidenceBuckets = []float64{0, 0.5, 0.7, 0.85, 0.95}
+
+// CompareOptions configures a classifier comparison run.
+type CompareOptions struct {
+	BatchSize int // Number of merchants per SuggestCategoryBatch call
+}
+
+// MerchantDivergence records a merchant where the two classifiers disagreed.
+type MerchantDivergence struct {
+	Merchant         string
+	CategoryA        string
+	CategoryB        string
+	ScoreA           float64
+	ScoreB           float64
+	TransactionCount int
+}
+
+// ComparisonReport summarizes how often two classifiers agree.
+type ComparisonReport struct {
+	Divergent         []MerchantDivergence
+	DistributionA     []int // Count of top scores per ConfidenceBuckets entry
+	DistributionB     []int
+	TotalMerchants    int
+	TotalTransactions int
+	Agreed            int
+	FailedA           int
+	FailedB           int
+}
+
+// AgreementRate returns the fraction of merchants classified by both providers that agreed.
+func (r *ComparisonReport) AgreementRate() float64 {
+	compared := r.Agreed + len(r.Divergent)
+	if compared == 0 {
+		return 0
+	}
+	return float64(r.Agreed) / float64(compared)
+}
+
+// CompareClassifiers classifies the same merchants with two classifiers and reports
+// their agreement. Nothing is saved.
+func CompareClassifiers(ctx context.Context, a, b Classifier, transactions []model.Transaction, categories []model.Category, opts CompareOptions) (*ComparisonReport, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchOptions().BatchSize
+	}
+
+	e := &ClassificationEngine{}
+	merchantGroups := e.groupByMerchant(transactions)
+	merchants := e.sortMerchantsByVolume(merchantGroups)
+
+	report := &ComparisonReport{
+		TotalMerchants:    len(merchants),
+		TotalTransactions: len(transactions),
+		DistributionA:     make([]int, len(ConfidenceBuckets)),
+		DistributionB:     make([]int, len(ConfidenceBuckets)),
+	}
+
+	for start := 0; start < len(merchants); start += opts.BatchSize {
+		end := start + opts.BatchSize
+		if end > len(merchants) {
+			end = len(merchants)
+		}
+
+		var requests []llm.MerchantBatchRequest
+		var batchTxns []model.Transaction
+		for _, merchant := range merchants[start:end] {
+			txns := merchantGroups[merchant]
+			requests = append(requests, llm.MerchantBatchRequest{
+				MerchantID:        merchant,
+				MerchantName:      merchant,
+				SampleTransaction: txns[0],
+				TransactionCount:  len(txns),
+			})
+			batchTxns = append(batchTxns, txns...)
+		}
+		filtered := e.filterCategoriesByDirection(categories, batchTxns)
+
+		rankingsA, errA := a.SuggestCategoryBatch(ctx, requests, filtered)
+		if errA != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			slog.Warn("provider A batch failed", "error", errA, "batch_size", len(requests))
+		}
+		rankingsB, errB := b.SuggestCategoryBatch(ctx, requests, filtered)
+		if errB != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			slog.Warn("provider B batch failed", "error", errB, "batch_size", len(requests))
+		}
+
+		for _, req := range requests {
+			topA := rankingsA[req.MerchantID].Top()
+			topB := rankingsB[req.MerchantID].Top()
+
+			if topA == nil {
+				report.FailedA++
+			} else {
+				report.DistributionA[confidenceBucket(topA.Score)]++
+			}
+			if topB == nil {
+				report.FailedB++
+			} else {
+				report.DistributionB[confidenceBucket(topB.Score)]++
+			}
+			if topA == nil || topB == nil {
+				continue
+			}
+
+			if topA.Category == topB.Category {
+				report.Agreed++
+				continue
+			}
+
+			report.Divergent = append(report.Divergent, MerchantDivergence{
+				Merchant:         req.MerchantID,
+				CategoryA:        topA.Category,
+				CategoryB:        topB.Category,
+				ScoreA:           topA.Score,
+				ScoreB:           topB.Score,
+				TransactionCount: req.TransactionCount,
+			})
+		}
+	}
+
+	// Most impactful disagreements first
+	sort.SliceStable(report.Divergent, func(i, j int) bool {
+		return report.Divergent[i].TransactionCount > report.Divergent[j].TransactionCount
+	})
+
+	if report.TotalMerchants > 0 && report.FailedA == report.TotalMerchants && report.FailedB == report.TotalMerchants {
+		return report, fmt.Errorf("both providers failed to classify every merchant")
+	}
+
+	return report, nil
+}
+
+// confidenceBucket returns the index of the ConfidenceBuckets entry for a score.
+func confidenceBucket(score float64) int {
+	for i := len(ConfidenceBuckets) - 1; i > 0; i-- {
+		if score >= ConfidenceBuckets[i] {
+			return i
+		}
+	}
+	return 0
+}
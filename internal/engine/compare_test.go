@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareClassifiers(t *testing.T) {
+	a := NewMockClassifier()
+	b := NewMockClassifier()
+
+	a.SetBatchResponse(map[string]model.CategoryRankings{
+		"Walmart": {{Category: "Groceries", Score: 0.96}},
+		"Amazon":  {{Category: "Shopping", Score: 0.60}},
+		"Shell":   {{Category: "Transportation", Score: 0.90}},
+	})
+	b.SetBatchResponse(map[string]model.CategoryRankings{
+		"Walmart": {{Category: "Groceries", Score: 0.90}},
+		"Amazon":  {{Category: "Office Supplies", Score: 0.75}},
+	})
+
+	transactions := []model.Transaction{
+		{ID: "1", MerchantName: "Walmart", Amount: 10},
+		{ID: "2", MerchantName: "Amazon", Amount: 20},
+		{ID: "3", MerchantName: "Amazon", Amount: 30},
+		{ID: "4", MerchantName: "Shell", Amount: 40},
+	}
+
+	report, err := CompareClassifiers(context.Background(), a, b, transactions, nil, CompareOptions{BatchSize: 10})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, report.TotalMerchants)
+	assert.Equal(t, 4, report.TotalTransactions)
+	assert.Equal(t, 1, report.Agreed)
+	assert.Equal(t, 0, report.FailedA)
+	assert.Equal(t, 1, report.FailedB)
+	assert.InDelta(t, 0.5, report.AgreementRate(), 1e-9)
+
+	require.Len(t, report.Divergent, 1)
+	assert.Equal(t, MerchantDivergence{
+		Merchant:         "Amazon",
+		CategoryA:        "Shopping",
+		CategoryB:        "Office Supplies",
+		ScoreA:           0.60,
+		ScoreB:           0.75,
+		TransactionCount: 2,
+	}, report.Divergent[0])
+
+	// Buckets: 0, 0.5, 0.7, 0.85, 0.95
+	assert.Equal(t, []int{0, 1, 0, 1, 1}, report.DistributionA)
+	assert.Equal(t, []int{0, 0, 1, 1, 0}, report.DistributionB)
+}
+
+func TestConfidenceBucket(t *testing.T) {
+	assert.Equal(t, 0, confidenceBucket(0.1))
+	assert.Equal(t, 1, confidenceBucket(0.5))
+	assert.Equal(t, 3, confidenceBucket(0.94))
+	assert.Equal(t, 4, confidenceBucket(1.0))
+}
@@ -0,0 +1,119 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// DecisionSource is what classified a merchant.
+type DecisionSource string
+
+// Decision sources.
+const (
+	SourcePattern DecisionSource = "pattern" // A pattern rule
+	SourceVendor  DecisionSource = "vendor"  // A vendor rule
+	SourceCheck   DecisionSource = "check"   // A check pattern
+	SourceLLM     DecisionSource = "llm"     // The LLM
+)
+
+// fromRule reports whether the source is a pattern rule, vendor rule or check pattern.
+func (s DecisionSource) fromRule() bool {
+	return s == SourcePattern || s == SourceVendor || s == SourceCheck
+}
+
+// DecisionOutcome is what a run did with a merchant's classification.
+type DecisionOutcome string
+
+// Decision outcomes.
+const (
+	OutcomeAuto     DecisionOutcome = "auto"     // Saved without review
+	OutcomeReview   DecisionOutcome = "review"   // Sent to manual review
+	OutcomeSkip     DecisionOutcome = "skip"     // Needed review, but review was skipped
+	OutcomeEscalate DecisionOutcome = "escalate" // Filed under the escalate category
+	OutcomeFail     DecisionOutcome = "fail"     // Couldn't be classified
+)
+
+// Decision is one line of the decision log: how a merchant group was
+// classified and what the run did with it.
+type Decision struct {
+	Time         time.Time       `json:"time"`
+	Merchant     string          `json:"merchant"`
+	Source       DecisionSource  `json:"source,omitempty"`
+	Category     string          `json:"category,omitempty"`
+	Outcome      DecisionOutcome `json:"outcome"`
+	Error        string          `json:"error,omitempty"`
+	Transactions []string        `json:"transactions"`
+	Confidence   float64         `json:"confidence"`
+	NewCategory  bool            `json:"new_category,omitempty"`
+}
+
+// DecisionLog writes a Decision per merchant as JSON lines. Each line is
+// written as soon as the merchant's outcome is decided, so a run that crashes
+// still leaves the decisions made so far. It is safe for concurrent use.
+type DecisionLog struct {
+	encoder *json.Encoder
+	err     error
+	mu      sync.Mutex
+}
+
+// NewDecisionLog creates a decision log writing to w.
+func NewDecisionLog(w io.Writer) *DecisionLog {
+	return &DecisionLog{encoder: json.NewEncoder(w)}
+}
+
+// Record writes the decision for result. A nil log records nothing. After a
+// write fails, later decisions are dropped; Err returns the failure.
+func (l *DecisionLog) Record(result BatchResult, outcome DecisionOutcome) {
+	if l == nil {
+		return
+	}
+
+	decision := Decision{
+		Time:         time.Now(),
+		Merchant:     result.Merchant,
+		Source:       result.Source,
+		Outcome:      outcome,
+		Transactions: make([]string, 0, len(result.Transactions)),
+	}
+	for _, txn := range result.Transactions {
+		decision.Transactions = append(decision.Transactions, txn.ID)
+	}
+	if result.Suggestion != nil {
+		decision.Category = result.Suggestion.Category
+		decision.Confidence = result.Suggestion.Score
+		decision.NewCategory = result.Suggestion.IsNew
+	}
+	if result.Error != nil {
+		decision.Error = result.Error.Error()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err != nil {
+		return
+	}
+	if err := l.encoder.Encode(decision); err != nil {
+		l.err = fmt.Errorf("failed to write decision for %s: %w", result.Merchant, err)
+	}
+}
+
+// Err returns the first write error, if any.
+func (l *DecisionLog) Err() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// reviewOutcome is the outcome of a result that needs review.
+func reviewOutcome(opts BatchClassificationOptions) DecisionOutcome {
+	if opts.SkipManualReview || opts.DryRun {
+		return OutcomeSkip
+	}
+	return OutcomeReview
+}
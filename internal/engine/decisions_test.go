@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyTransactionsBatch_Decisions(t *testing.T) {
+	ctx := context.Background()
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+	defer func() { _ = db.Close() }()
+
+	for _, name := range []string{"Groceries", "Shopping"} {
+		_, err = db.CreateCategoryWithType(ctx, name, "", model.CategoryTypeExpense)
+		require.NoError(t, err)
+	}
+	require.NoError(t, db.SaveVendor(ctx, &model.Vendor{Name: "Kroger", Category: "Groceries", Source: model.SourceManual, LastUpdated: time.Now()}))
+
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, db.SaveTransactions(ctx, []model.Transaction{
+		{ID: "k1", Hash: "hash-k1", Name: "KROGER", MerchantName: "Kroger", Amount: 40, Type: "DEBIT", Date: date, AccountID: "acc1"},
+		{ID: "k2", Hash: "hash-k2", Name: "KROGER", MerchantName: "Kroger", Amount: 60, Type: "DEBIT", Date: date, AccountID: "acc1"},
+		{ID: "shop", Hash: "hash-shop", Name: "CORNER SHOP", MerchantName: "Corner Shop", Amount: 12, Type: "DEBIT", Date: date, AccountID: "acc1"},
+		{ID: "odd", Hash: "hash-odd", Name: "XQ*7781", MerchantName: "XQ 7781", Amount: 9, Type: "DEBIT", Date: date, AccountID: "acc1"},
+	}))
+
+	classifier := NewMockClassifier()
+	classifier.SetBatchResponse(map[string]model.CategoryRankings{
+		"Corner Shop": {{Category: "Shopping", Score: 0.6}},
+	})
+	engine := &ClassificationEngine{storage: db, classifier: classifier, prompter: NewMockPrompter(true)}
+
+	var out bytes.Buffer
+	_, err = engine.ClassifyTransactionsBatch(ctx, nil, BatchClassificationOptions{
+		AutoAcceptThreshold: 0.95,
+		BatchSize:           5,
+		ParallelWorkers:     1,
+		SkipManualReview:    true,
+		Decisions:           NewDecisionLog(&out),
+	})
+	require.NoError(t, err)
+
+	decisions := make(map[string]Decision)
+	decoder := json.NewDecoder(&out)
+	for decoder.More() {
+		var decision Decision
+		require.NoError(t, decoder.Decode(&decision))
+		decisions[decision.Merchant] = decision
+	}
+	require.Len(t, decisions, 3)
+
+	kroger := decisions["Kroger"]
+	assert.Equal(t, SourceVendor, kroger.Source)
+	assert.Equal(t, OutcomeAuto, kroger.Outcome)
+	assert.Equal(t, "Groceries", kroger.Category)
+	assert.ElementsMatch(t, []string{"k1", "k2"}, kroger.Transactions)
+
+	shop := decisions["Corner Shop"]
+	assert.Equal(t, SourceLLM, shop.Source)
+	assert.Equal(t, OutcomeSkip, shop.Outcome, "review was skipped")
+	assert.InDelta(t, 0.6, shop.Confidence, 0.001)
+
+	odd := decisions["XQ 7781"]
+	assert.Equal(t, OutcomeFail, odd.Outcome)
+	assert.NotEmpty(t, odd.Error)
+	assert.Equal(t, []string{"odd"}, odd.Transactions)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestDecisionLog_Err(t *testing.T) {
+	var nilLog *DecisionLog
+	nilLog.Record(BatchResult{Merchant: "Kroger"}, OutcomeAuto)
+	require.NoError(t, nilLog.Err())
+
+	log := NewDecisionLog(failingWriter{})
+	log.Record(BatchResult{Merchant: "Kroger"}, OutcomeAuto)
+	assert.ErrorContains(t, log.Err(), "disk full")
+}
@@ -0,0 +1,128 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/llm"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
+)
+
+// DefaultEmbeddingWorkers is the number of embedding requests in flight at once.
+const DefaultEmbeddingWorkers = 4
+
+// EmbeddingBuildOptions configures a merchant embedding backfill.
+type EmbeddingBuildOptions struct {
+	Model     string // Embedding model; merchants are embedded once per model
+	BatchSize int    // Merchants per embedding request (0 = llm.DefaultEmbeddingBatchSize)
+	Workers   int    // Concurrent embedding requests (0 = DefaultEmbeddingWorkers)
+}
+
+// EmbeddingBuildSummary reports what a backfill did.
+type EmbeddingBuildSummary struct {
+	Missing  int // Classified merchants without an embedding when the backfill started
+	Embedded int // Merchants embedded and saved
+	Batches  int // Batches saved
+}
+
+// BuildMerchantEmbeddings embeds every classified merchant that has no
+// embedding for the model. Each batch is saved as soon as it is embedded, so
+// an interrupted or failed backfill resumes where it stopped: merchants
+// already in the database are never sent again. The first failure stops the
+// remaining batches and is returned along with what was saved.
+func BuildMerchantEmbeddings(ctx context.Context, store service.Storage, embedder llm.Embedder, opts EmbeddingBuildOptions) (EmbeddingBuildSummary, error) {
+	if opts.Model == "" {
+		opts.Model = llm.DefaultEmbeddingModel
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = llm.DefaultEmbeddingBatchSize
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultEmbeddingWorkers
+	}
+
+	missing, err := store.GetMerchantsWithoutEmbedding(ctx, opts.Model)
+	if err != nil {
+		return EmbeddingBuildSummary{}, fmt.Errorf("failed to find merchants without embedding: %w", err)
+	}
+
+	summary := EmbeddingBuildSummary{Missing: len(missing)}
+	if len(missing) == 0 {
+		return summary, nil
+	}
+	slog.Info("Embedding classified merchants",
+		"count", len(missing),
+		"model", opts.Model,
+		"workers", opts.Workers)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	workChan := make(chan []string, len(missing)/opts.BatchSize+1)
+	for start := 0; start < len(missing); start += opts.BatchSize {
+		workChan <- missing[start:min(start+opts.BatchSize, len(missing))]
+	}
+	close(workChan)
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	wg.Add(opts.Workers)
+	for i := 0; i < opts.Workers; i++ {
+		go func() {
+			defer wg.Done()
+			for batch := range workChan {
+				if ctx.Err() != nil {
+					return
+				}
+
+				_, err := embedAndSave(ctx, store, embedder, opts.Model, batch, opts.BatchSize)
+
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+						cancel()
+					}
+				} else {
+					summary.Embedded += len(batch)
+					summary.Batches++
+					slog.Debug("embedded merchant batch",
+						"merchants", len(batch),
+						"done", summary.Embedded,
+						"total", summary.Missing)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return summary, firstErr
+}
+
+// embedAndSave embeds merchant names in requests of batchSize and stores the
+// vectors.
+func embedAndSave(ctx context.Context, store service.Storage, embedder llm.Embedder, embeddingModel string, names []string, batchSize int) ([]model.MerchantEmbedding, error) {
+	vectors, err := embedder.EmbedBatch(ctx, names, batchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed merchants: %w", err)
+	}
+	if len(vectors) != len(names) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(names), len(vectors))
+	}
+
+	embeddings := make([]model.MerchantEmbedding, len(names))
+	for i, name := range names {
+		embeddings[i] = model.MerchantEmbedding{MerchantName: name, Model: embeddingModel, Vector: vectors[i]}
+	}
+	if err := store.SaveMerchantEmbeddings(ctx, embeddings); err != nil {
+		return nil, fmt.Errorf("failed to save merchant embeddings: %w", err)
+	}
+	return embeddings, nil
+}
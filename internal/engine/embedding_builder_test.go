@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMerchantEmbeddings(t *testing.T) {
+	ctx := context.Background()
+	db, _ := setupEmbeddingTest(t)
+
+	transactions := make([]model.Transaction, 0, 6)
+	for i := 0; i < 6; i++ {
+		transactions = append(transactions, model.Transaction{
+			ID: fmt.Sprintf("bulk%d", i), Hash: fmt.Sprintf("bulk-hash%d", i), Name: fmt.Sprintf("SHOP %d", i),
+			MerchantName: fmt.Sprintf("Shop %d", i), Amount: 10, Date: time.Now().AddDate(0, 0, -1), AccountID: "acc1",
+		})
+	}
+	require.NoError(t, db.SaveTransactions(ctx, transactions))
+	for _, txn := range transactions {
+		require.NoError(t, db.SaveClassification(ctx, &model.Classification{
+			Transaction: txn, Category: "Fuel", Status: model.StatusClassifiedByAI, Confidence: 0.9,
+		}))
+	}
+
+	embedder := &fakeEmbedder{}
+	summary, err := BuildMerchantEmbeddings(ctx, db, embedder, EmbeddingBuildOptions{Model: "m", BatchSize: 2, Workers: 3})
+	require.NoError(t, err)
+	assert.Equal(t, EmbeddingBuildSummary{Missing: 7, Embedded: 7, Batches: 4}, summary)
+	assert.Len(t, embedder.texts, 7)
+
+	stored, err := db.GetMerchantEmbeddings(ctx, "m")
+	require.NoError(t, err)
+	assert.Len(t, stored, 7)
+
+	// A second run has nothing left to embed
+	summary, err = BuildMerchantEmbeddings(ctx, db, embedder, EmbeddingBuildOptions{Model: "m", BatchSize: 2, Workers: 3})
+	require.NoError(t, err)
+	assert.Equal(t, EmbeddingBuildSummary{}, summary)
+	assert.Len(t, embedder.texts, 7)
+}
+
+func TestBuildMerchantEmbeddings_ResumesAfterFailure(t *testing.T) {
+	ctx := context.Background()
+	db, _ := setupEmbeddingTest(t)
+
+	summary, err := BuildMerchantEmbeddings(ctx, db, &fakeEmbedder{err: errors.New("quota exceeded")}, EmbeddingBuildOptions{Model: "m"})
+	require.ErrorContains(t, err, "quota exceeded")
+	assert.Equal(t, EmbeddingBuildSummary{Missing: 1}, summary)
+
+	summary, err = BuildMerchantEmbeddings(ctx, db, &fakeEmbedder{}, EmbeddingBuildOptions{Model: "m"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Embedded)
+}
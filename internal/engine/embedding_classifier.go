@@ -0,0 +1,322 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/llm"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
+)
+
+// DefaultMinSimilarity is the cosine similarity a classified merchant must
+// reach before its category is used without asking the LLM.
+const DefaultMinSimilarity = 0.9
+
+// EmbeddingClassifierConfig configures nearest-neighbor classification.
+type EmbeddingClassifierConfig struct {
+	Model         string  // Embedding model; embeddings of other models are ignored
+	MinSimilarity float64 // Cosine similarity required for a hit
+	BatchSize     int     // Merchants per embedding request (0 = llm.DefaultEmbeddingBatchSize)
+	Workers       int     // Concurrent requests when backfilling the index (0 = DefaultEmbeddingWorkers)
+}
+
+// EmbeddingStats contains hit statistics for a nearest-neighbor classification run.
+type EmbeddingStats struct {
+	Merchants int // Merchants looked up by embedding
+	Hits      int // Merchants classified by their nearest neighbor, without the LLM
+	Embedded  int // Merchant names sent to the embedding model
+}
+
+// HitRate is the share of merchants classified without the LLM.
+func (s EmbeddingStats) HitRate() float64 {
+	if s.Merchants == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Merchants)
+}
+
+// GetDisplay returns a JSON representation of the embedding statistics.
+func (s EmbeddingStats) GetDisplay() string {
+	type statsJSON struct {
+		Merchants int     `json:"embedding_merchants"`
+		Hits      int     `json:"embedding_hits"`
+		HitRate   float64 `json:"embedding_hit_rate"`
+		Embedded  int     `json:"embedded"`
+	}
+
+	bytes, err := json.Marshal(statsJSON{
+		Merchants: s.Merchants,
+		Hits:      s.Hits,
+		HitRate:   s.HitRate(),
+		Embedded:  s.Embedded,
+	})
+	if err != nil {
+		return fmt.Sprintf(`{"error":"Failed to marshal embedding stats: %v"}`, err)
+	}
+
+	return string(bytes)
+}
+
+// EmbeddingClassifier classifies merchants by the category of the most similar
+// classified merchant, and sends the merchants without a close enough
+// neighbor to the wrapped classifier.
+type EmbeddingClassifier struct {
+	next     Classifier
+	embedder llm.Embedder
+	storage  service.Storage
+	config   EmbeddingClassifierConfig
+	index    []model.MerchantEmbedding
+	stats    EmbeddingStats
+	loaded   bool
+	mu       sync.Mutex
+}
+
+// NewEmbeddingClassifier creates a nearest-neighbor classifier in front of next.
+func NewEmbeddingClassifier(next Classifier, embedder llm.Embedder, storage service.Storage, config EmbeddingClassifierConfig) *EmbeddingClassifier {
+	if config.Model == "" {
+		config.Model = llm.DefaultEmbeddingModel
+	}
+	if config.MinSimilarity <= 0 {
+		config.MinSimilarity = DefaultMinSimilarity
+	}
+
+	return &EmbeddingClassifier{
+		next:     next,
+		embedder: embedder,
+		storage:  storage,
+		config:   config,
+	}
+}
+
+// Next returns the classifier that handles misses.
+func (e *EmbeddingClassifier) Next() Classifier {
+	return e.next
+}
+
+// Stats returns a snapshot of the hit statistics.
+func (e *EmbeddingClassifier) Stats() EmbeddingStats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.stats
+}
+
+// SuggestCategoryBatch classifies merchants that have a close classified
+// neighbor from the embedding index and the rest with the wrapped classifier.
+// A hit is ranked with its similarity as the score. When embedding fails, every
+// merchant goes to the wrapped classifier.
+func (e *EmbeddingClassifier) SuggestCategoryBatch(ctx context.Context, requests []llm.MerchantBatchRequest, categories []model.Category) (map[string]model.CategoryRankings, error) {
+	results := make(map[string]model.CategoryRankings)
+	misses := requests
+
+	if vectors, err := e.embedMerchants(ctx, requests); err != nil {
+		slog.Warn("embedding lookup failed, classifying all merchants with the LLM", "error", err)
+	} else {
+		active := make(map[string]bool, len(categories))
+		for _, category := range categories {
+			active[category.Name] = true
+		}
+
+		misses = nil
+		for _, req := range requests {
+			neighbor, similarity := e.nearest(req.MerchantName, vectors[strings.ToLower(req.MerchantName)], active)
+			if neighbor == nil || similarity < e.config.MinSimilarity {
+				misses = append(misses, req)
+				continue
+			}
+			slog.Debug("classified merchant by nearest neighbor",
+				"merchant", req.MerchantName,
+				"neighbor", neighbor.MerchantName,
+				"category", neighbor.Category,
+				"similarity", similarity)
+			results[req.MerchantID] = model.CategoryRankings{{
+				Category: neighbor.Category,
+				Score:    similarity,
+			}}
+		}
+		e.addLookups(len(requests), len(requests)-len(misses))
+	}
+
+	if len(misses) == 0 {
+		return results, nil
+	}
+
+	nextResults, err := e.next.SuggestCategoryBatch(ctx, misses, categories)
+	if err != nil {
+		return nil, err
+	}
+	for id, rankings := range nextResults {
+		results[id] = rankings
+	}
+	return results, nil
+}
+
+// SuggestCategoryRankings delegates to the wrapped classifier.
+func (e *EmbeddingClassifier) SuggestCategoryRankings(ctx context.Context, transaction model.Transaction, categories []model.Category, checkPatterns []model.CheckPattern) (model.CategoryRankings, error) {
+	return e.next.SuggestCategoryRankings(ctx, transaction, categories, checkPatterns)
+}
+
+// SuggestCategory delegates to the wrapped classifier.
+func (e *EmbeddingClassifier) SuggestCategory(ctx context.Context, transaction model.Transaction, categories []string) (string, float64, bool, string, error) {
+	return e.next.SuggestCategory(ctx, transaction, categories)
+}
+
+// BatchSuggestCategories delegates to the wrapped classifier.
+func (e *EmbeddingClassifier) BatchSuggestCategories(ctx context.Context, transactions []model.Transaction, categories []string) ([]service.LLMSuggestion, error) {
+	return e.next.BatchSuggestCategories(ctx, transactions, categories)
+}
+
+// GenerateCategoryDescription delegates to the wrapped classifier.
+func (e *EmbeddingClassifier) GenerateCategoryDescription(ctx context.Context, categoryName string) (string, float64, error) {
+	return e.next.GenerateCategoryDescription(ctx, categoryName)
+}
+
+// Close closes the wrapped classifier and the embedder when they support it,
+// returning the first error.
+func (e *EmbeddingClassifier) Close() error {
+	var firstErr error
+	for _, c := range []any{e.next, e.embedder} {
+		if closer, ok := c.(interface{ Close() error }); ok {
+			if err := closer.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// embedMerchants loads the index on first use, then embeds and stores the
+// requested merchants. Vectors are keyed by lowercased merchant name.
+func (e *EmbeddingClassifier) embedMerchants(ctx context.Context, requests []llm.MerchantBatchRequest) (map[string][]float32, error) {
+	if err := e.loadIndex(ctx); err != nil {
+		return nil, err
+	}
+
+	vectors := make(map[string][]float32)
+	var names []string
+	for _, req := range requests {
+		key := strings.ToLower(req.MerchantName)
+		if _, seen := vectors[key]; seen || req.MerchantName == "" {
+			continue
+		}
+		vectors[key] = nil
+		names = append(names, req.MerchantName)
+	}
+
+	embeddings, err := e.embed(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	for _, embedding := range embeddings {
+		vectors[strings.ToLower(embedding.MerchantName)] = embedding.Vector
+	}
+	return vectors, nil
+}
+
+// loadIndex reads the stored embeddings, first backfilling any classified
+// merchant that has none so earlier classifications count as neighbors.
+func (e *EmbeddingClassifier) loadIndex(ctx context.Context) error {
+	e.mu.Lock()
+	loaded := e.loaded
+	e.mu.Unlock()
+	if loaded {
+		return nil
+	}
+
+	summary, err := BuildMerchantEmbeddings(ctx, e.storage, e.embedder, EmbeddingBuildOptions{
+		Model:     e.config.Model,
+		BatchSize: e.config.BatchSize,
+		Workers:   e.config.Workers,
+	})
+	e.addEmbedded(summary.Embedded)
+	if err != nil {
+		return err
+	}
+
+	index, err := e.storage.GetMerchantEmbeddings(ctx, e.config.Model)
+	if err != nil {
+		return fmt.Errorf("failed to load merchant embeddings: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.index = index
+	e.loaded = true
+	return nil
+}
+
+// embed embeds merchant names and stores the vectors.
+func (e *EmbeddingClassifier) embed(ctx context.Context, names []string) ([]model.MerchantEmbedding, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	embeddings, err := embedAndSave(ctx, e.storage, e.embedder, e.config.Model, names, e.config.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	e.addEmbedded(len(names))
+	return embeddings, nil
+}
+
+// nearest returns the most similar classified merchant whose category is
+// active, skipping the merchant itself.
+func (e *EmbeddingClassifier) nearest(merchantName string, vector []float32, active map[string]bool) (*model.MerchantEmbedding, float64) {
+	if len(vector) == 0 {
+		return nil, 0
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var best *model.MerchantEmbedding
+	bestSimilarity := -1.0
+	for i := range e.index {
+		candidate := &e.index[i]
+		if candidate.Category == "" || !active[candidate.Category] || strings.EqualFold(candidate.MerchantName, merchantName) {
+			continue
+		}
+		if similarity := cosineSimilarity(vector, candidate.Vector); similarity > bestSimilarity {
+			best, bestSimilarity = candidate, similarity
+		}
+	}
+	return best, bestSimilarity
+}
+
+// cosineSimilarity returns the cosine of the angle between two vectors, or 0
+// when their lengths differ or either is zero.
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// addLookups records merchants looked up and how many were hits.
+func (e *EmbeddingClassifier) addLookups(merchants, hits int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stats.Merchants += merchants
+	e.stats.Hits += hits
+}
+
+// addEmbedded records merchant names sent to the embedding model.
+func (e *EmbeddingClassifier) addEmbedded(count int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stats.Embedded += count
+}
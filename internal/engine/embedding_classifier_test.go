@@ -0,0 +1,179 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/llm"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEmbedder returns fixed vectors per merchant name.
+type fakeEmbedder struct {
+	err      error
+	vectors  map[string][]float32
+	texts    []string
+	requests int
+	closed   bool
+	mu       sync.Mutex
+}
+
+func (f *fakeEmbedder) Close() error {
+	f.closed = true
+	return nil
+}
+
+func (f *fakeEmbedder) EmbedBatch(ctx context.Context, texts []string, _ int) ([][]float32, error) {
+	return f.Embed(ctx, texts)
+}
+
+func (f *fakeEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+	f.texts = append(f.texts, texts...)
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = f.vectors[text]
+		if vectors[i] == nil {
+			vectors[i] = []float32{0, 0, 1}
+		}
+	}
+	return vectors, nil
+}
+
+func setupEmbeddingTest(t *testing.T) (*storage.SQLiteStorage, []model.Category) {
+	t.Helper()
+	ctx := context.Background()
+
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	require.NoError(t, db.Migrate(ctx))
+
+	for _, name := range []string{"Coffee", "Fuel"} {
+		_, err = db.CreateCategoryWithType(ctx, name, name, model.CategoryTypeExpense)
+		require.NoError(t, err)
+	}
+
+	txn := model.Transaction{
+		ID: "tx1", Hash: "hash1", Name: "STARBUCKS 123", MerchantName: "Starbucks",
+		Amount: 5, Date: time.Now().AddDate(0, 0, -1), AccountID: "acc1",
+	}
+	require.NoError(t, db.SaveTransactions(ctx, []model.Transaction{txn}))
+	require.NoError(t, db.SaveClassification(ctx, &model.Classification{
+		Transaction: txn, Category: "Coffee", Status: model.StatusUserModified, Confidence: 1,
+	}))
+
+	categories, err := db.GetCategories(ctx)
+	require.NoError(t, err)
+	return db, categories
+}
+
+func TestEmbeddingClassifier_SuggestCategoryBatch(t *testing.T) {
+	ctx := context.Background()
+	db, categories := setupEmbeddingTest(t)
+
+	embedder := &fakeEmbedder{vectors: map[string][]float32{
+		"Starbucks":         {1, 0, 0},
+		"Starbucks Reserve": {0.99, 0.1, 0},
+		"Shell":             {0, 1, 0},
+	}}
+	next := NewMockClassifier()
+	next.SetBatchResponse(map[string]model.CategoryRankings{
+		"shell": {{Category: "Fuel", Score: 0.9}},
+	})
+	classifier := NewEmbeddingClassifier(next, embedder, db, EmbeddingClassifierConfig{Model: "test-model"})
+
+	results, err := classifier.SuggestCategoryBatch(ctx, []llm.MerchantBatchRequest{
+		{MerchantID: "starbucks-reserve", MerchantName: "Starbucks Reserve"},
+		{MerchantID: "shell", MerchantName: "Shell"},
+	}, categories)
+	require.NoError(t, err)
+
+	require.Len(t, results["starbucks-reserve"], 1)
+	assert.Equal(t, "Coffee", results["starbucks-reserve"][0].Category)
+	assert.Greater(t, results["starbucks-reserve"][0].Score, DefaultMinSimilarity)
+	assert.Equal(t, "Fuel", results["shell"].Top().Category)
+	assert.Equal(t, 1, next.CallCount(), "only the miss reaches the LLM")
+
+	// The classified merchant was backfilled before the requested ones
+	assert.Equal(t, []string{"Starbucks", "Starbucks Reserve", "Shell"}, embedder.texts)
+	stats := classifier.Stats()
+	assert.Equal(t, EmbeddingStats{Merchants: 2, Hits: 1, Embedded: 3}, stats)
+	assert.InDelta(t, 0.5, stats.HitRate(), 0.001)
+
+	stored, err := db.GetMerchantEmbeddings(ctx, "test-model")
+	require.NoError(t, err)
+	assert.Len(t, stored, 3)
+}
+
+func TestEmbeddingClassifier_InactiveCategoryIsNotAHit(t *testing.T) {
+	ctx := context.Background()
+	db, categories := setupEmbeddingTest(t)
+
+	var fuelOnly []model.Category
+	for _, category := range categories {
+		if category.Name == "Fuel" {
+			fuelOnly = append(fuelOnly, category)
+		}
+	}
+
+	embedder := &fakeEmbedder{vectors: map[string][]float32{
+		"Starbucks":         {1, 0, 0},
+		"Starbucks Reserve": {1, 0, 0},
+	}}
+	next := NewMockClassifier()
+	classifier := NewEmbeddingClassifier(next, embedder, db, EmbeddingClassifierConfig{Model: "test-model"})
+
+	_, err := classifier.SuggestCategoryBatch(ctx, []llm.MerchantBatchRequest{
+		{MerchantID: "starbucks-reserve", MerchantName: "Starbucks Reserve"},
+	}, fuelOnly)
+	require.NoError(t, err)
+	assert.Equal(t, 1, next.CallCount())
+	assert.Equal(t, 0, classifier.Stats().Hits)
+}
+
+func TestEmbeddingClassifier_EmbedFailureFallsBack(t *testing.T) {
+	ctx := context.Background()
+	db, categories := setupEmbeddingTest(t)
+
+	next := NewMockClassifier()
+	next.SetBatchResponse(map[string]model.CategoryRankings{
+		"starbucks-reserve": {{Category: "Coffee", Score: 0.8}},
+	})
+	classifier := NewEmbeddingClassifier(next, &fakeEmbedder{err: errors.New("rate limited")}, db, EmbeddingClassifierConfig{})
+
+	results, err := classifier.SuggestCategoryBatch(ctx, []llm.MerchantBatchRequest{
+		{MerchantID: "starbucks-reserve", MerchantName: "Starbucks Reserve"},
+	}, categories)
+	require.NoError(t, err)
+	assert.Equal(t, "Coffee", results["starbucks-reserve"].Top().Category)
+	assert.Equal(t, 1, next.CallCount())
+	assert.Equal(t, EmbeddingStats{}, classifier.Stats())
+}
+
+func TestEmbeddingClassifier_CloseClosesEmbedder(t *testing.T) {
+	db, _ := setupEmbeddingTest(t)
+	embedder := &fakeEmbedder{}
+
+	classifier := NewEmbeddingClassifier(NewMockClassifier(), embedder, db, EmbeddingClassifierConfig{})
+	require.NoError(t, classifier.Close())
+	assert.True(t, embedder.closed)
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	assert.InDelta(t, 1.0, cosineSimilarity([]float32{1, 2}, []float32{2, 4}), 0.0001)
+	assert.InDelta(t, 0.0, cosineSimilarity([]float32{1, 0}, []float32{0, 1}), 0.0001)
+	assert.Zero(t, cosineSimilarity([]float32{1, 0}, []float32{1, 0, 0}))
+	assert.Zero(t, cosineSimilarity([]float32{0, 0}, []float32{1, 0}))
+}
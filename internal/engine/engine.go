@@ -93,7 +93,9 @@ func (e *ClassificationEngine) groupByMerchant(transactions []model.Transaction)
 
 	for _, txn := range transactions {
 		merchant := txn.MerchantName
-		if merchant == "" {
+		if payee := txn.CheckPayee(); payee != "" {
+			merchant = payee // Checks carry their payee in the name/memo
+		} else if merchant == "" {
 			merchant = txn.Name // Fallback to raw name
 		}
 		merchant = strings.TrimSpace(merchant)
@@ -139,6 +141,34 @@ func (e *ClassificationEngine) getVendor(ctx context.Context, merchantName strin
 	return e.storage.FindVendorMatch(ctx, merchantName)
 }
 
+// hasClassificationHistory reports whether the merchant has been classified before.
+// Lookup failures count as no history so the merchant is reviewed rather than auto-accepted.
+func (e *ClassificationEngine) hasClassificationHistory(ctx context.Context, merchantName string) bool {
+	exists, err := e.storage.HasClassificationHistory(ctx, merchantName)
+	if err != nil {
+		slog.Warn("failed to check merchant classification history",
+			"merchant", merchantName,
+			"error", err)
+		return false
+	}
+	return exists
+}
+
+// merchantHints returns the user's LLM hints keyed by lowercased merchant name.
+// Hints only improve suggestions, so a failure to load them is not fatal.
+func (e *ClassificationEngine) merchantHints(ctx context.Context) map[string]string {
+	hints, err := e.storage.GetMerchantHints(ctx)
+	if err != nil {
+		slog.Warn("failed to load merchant hints", "error", err)
+		return nil
+	}
+	byMerchant := make(map[string]string, len(hints))
+	for _, hint := range hints {
+		byMerchant[strings.ToLower(hint.MerchantName)] = hint.Hint
+	}
+	return byMerchant
+}
+
 // RefreshPatternRules reloads pattern rules from storage.
 func (e *ClassificationEngine) RefreshPatternRules(ctx context.Context) error {
 	if e.patternClassifier == nil {
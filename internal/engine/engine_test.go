@@ -353,6 +353,24 @@ func TestClassificationEngine_GroupByMerchant(t *testing.T) {
 	assert.Len(t, groups["WHOLE FOODS"], 1) // Falls back to name
 }
 
+func TestClassificationEngine_GroupByMerchant_Checks(t *testing.T) {
+	engine := &ClassificationEngine{}
+
+	transactions := []model.Transaction{
+		{ID: "1", Type: "CHECK", Name: "CHECK #1001 - Jane's Cleaning", CheckNumber: "1001"},
+		{ID: "2", Type: "CHECK", Name: "CHECK #1002 - Jane's Cleaning", CheckNumber: "1002"},
+		{ID: "3", Type: "CHECK", Name: "CHECK #1003", CheckNumber: "1003"}, // No payee in memo
+		{ID: "4", Type: "CHECK", Name: "CHECK #1004", MerchantName: "Landlord LLC"},
+	}
+
+	groups := engine.groupByMerchant(transactions)
+
+	assert.Len(t, groups, 3)
+	assert.Len(t, groups["Jane's Cleaning"], 2) // Payee extracted from memo
+	assert.Len(t, groups["CHECK #1003"], 1)     // Falls back to name
+	assert.Len(t, groups["Landlord LLC"], 1)    // Merchant name wins
+}
+
 func TestClassificationEngine_SortMerchantsByVolume(t *testing.T) {
 	engine := &ClassificationEngine{}
 
@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+)
+
+// DefaultEscalateCategory is the category escalated transactions are filed
+// under when BatchClassificationOptions.EscalateCategory is empty.
+const DefaultEscalateCategory = "Escalate"
+
+// escalateCategory returns the category escalated transactions are filed under.
+func escalateCategory(opts BatchClassificationOptions) string {
+	if opts.EscalateCategory == "" {
+		return DefaultEscalateCategory
+	}
+	return opts.EscalateCategory
+}
+
+// escalates reports whether a result is too uncertain to be worth reviewing
+// now and should be filed under the escalate category instead. Capped
+// catch-all suggestions are always reviewed, never escalated.
+func escalates(result BatchResult, opts BatchClassificationOptions) bool {
+	if opts.EscalateBelow <= 0 || result.Suggestion == nil || result.CatchAllCapped {
+		return false
+	}
+	return result.Suggestion.Score < opts.EscalateBelow && !autoAcceptable(result, opts)
+}
+
+// withoutEscalateCategory drops the escalate category from the categories
+// offered to the LLM and in review, so nothing is classified into it by choice.
+func withoutEscalateCategory(categories []model.Category, opts BatchClassificationOptions) []model.Category {
+	if opts.EscalateBelow <= 0 {
+		return categories
+	}
+	name := escalateCategory(opts)
+	filtered := make([]model.Category, 0, len(categories))
+	for _, category := range categories {
+		if !strings.EqualFold(category.Name, name) {
+			filtered = append(filtered, category)
+		}
+	}
+	return filtered
+}
+
+// saveEscalated files the transactions of each result under the escalate
+// category, creating it as a system category the first time. The AI's
+// suggestion and confidence are kept in the classification notes.
+func (e *ClassificationEngine) saveEscalated(ctx context.Context, results []BatchResult, opts BatchClassificationOptions) error {
+	name := escalateCategory(opts)
+
+	category, err := e.storage.GetCategoryByName(ctx, name)
+	if errors.Is(err, storage.ErrCategoryNotFound) {
+		category, err = e.storage.CreateCategoryWithType(ctx, name,
+			"Transactions the AI was too unsure about to classify; recategorize them by hand", model.CategoryTypeSystem)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get escalate category %q: %w", name, err)
+	}
+
+	saved := 0
+	for _, result := range results {
+		for _, txn := range result.Transactions {
+			classification := model.Classification{
+				Transaction:  txn,
+				Category:     category.Name,
+				Status:       model.StatusClassifiedByAI,
+				Confidence:   result.Suggestion.Score,
+				ClassifiedAt: time.Now(),
+				Notes: fmt.Sprintf("Escalated: AI suggested %s at %.0f%% confidence",
+					result.Suggestion.Category, result.Suggestion.Score*100),
+			}
+			if err := e.storage.SaveClassification(ctx, &classification); err != nil {
+				slog.Error("Failed to save escalated classification",
+					"transaction_id", txn.ID,
+					"error", err)
+				continue
+			}
+			saved++
+		}
+	}
+
+	slog.Info("Escalated uncertain merchants",
+		"category", category.Name,
+		"merchants", len(results),
+		"transactions", saved)
+	return nil
+}
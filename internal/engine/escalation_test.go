@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyTransactionsBatch_Escalation(t *testing.T) {
+	ctx := context.Background()
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+	defer func() { _ = db.Close() }()
+
+	for _, name := range []string{"Gas Stations", "Shopping"} {
+		_, err = db.CreateCategoryWithType(ctx, name, "", model.CategoryTypeExpense)
+		require.NoError(t, err)
+	}
+
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, db.SaveTransactions(ctx, []model.Transaction{
+		{ID: "gas", Hash: "hash-gas", Name: "SHELL", MerchantName: "Shell", Amount: 40, Type: "DEBIT", Date: date, AccountID: "acc1"},
+		{ID: "shop", Hash: "hash-shop", Name: "CORNER SHOP", MerchantName: "Corner Shop", Amount: 12, Type: "DEBIT", Date: date, AccountID: "acc1"},
+		{ID: "odd1", Hash: "hash-odd1", Name: "XQ*7781", MerchantName: "XQ 7781", Amount: 9, Type: "DEBIT", Date: date, AccountID: "acc1"},
+		{ID: "odd2", Hash: "hash-odd2", Name: "XQ*7781", MerchantName: "XQ 7781", Amount: 11, Type: "DEBIT", Date: date, AccountID: "acc1"},
+	}))
+
+	classifier := NewMockClassifier()
+	classifier.SetBatchResponse(map[string]model.CategoryRankings{
+		"Shell":       {{Category: "Gas Stations", Score: 0.97}},
+		"Corner Shop": {{Category: "Shopping", Score: 0.6}},
+		"XQ 7781":     {{Category: "Shopping", Score: 0.15}},
+	})
+	prompter := NewMockPrompter(true)
+	engine := &ClassificationEngine{storage: db, classifier: classifier, prompter: prompter}
+
+	summary, err := engine.ClassifyTransactionsBatch(ctx, nil, BatchClassificationOptions{
+		AutoAcceptThreshold: 0.95,
+		BatchSize:           5,
+		ParallelWorkers:     1,
+		EscalateBelow:       0.3,
+		EscalateCategory:    "Needs Human",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, summary.AutoAcceptedCount)
+	assert.Equal(t, 1, summary.NeedsReviewCount)
+	assert.Equal(t, 1, summary.EscalatedCount)
+	assert.Equal(t, 2, summary.EscalatedTxns)
+	assert.Contains(t, summary.GetDisplay(), `"escalated_count":1`)
+
+	// Only Corner Shop was reviewed
+	for _, call := range prompter.GetBatchConfirmCalls() {
+		for _, pending := range call.Pending {
+			assert.Equal(t, "Corner Shop", pending.Transaction.MerchantName)
+		}
+	}
+
+	category, err := db.GetCategoryByName(ctx, "Needs Human")
+	require.NoError(t, err)
+	assert.Equal(t, model.CategoryTypeSystem, category.Type)
+
+	escalated, err := db.QueryTransactions(ctx, model.TransactionQuery{Category: "Needs Human"})
+	require.NoError(t, err)
+	require.Len(t, escalated, 2)
+	for _, classification := range escalated {
+		assert.Equal(t, "XQ 7781", classification.Transaction.MerchantName)
+		assert.InDelta(t, 0.15, classification.Confidence, 0.001)
+		assert.Contains(t, classification.Notes, "Shopping")
+	}
+
+	t.Run("escalate category is never offered", func(t *testing.T) {
+		categories, err := db.GetCategories(ctx)
+		require.NoError(t, err)
+		opts := BatchClassificationOptions{EscalateBelow: 0.3, EscalateCategory: "needs human"}
+		for _, category := range withoutEscalateCategory(categories, opts) {
+			assert.NotEqual(t, "Needs Human", category.Name)
+		}
+		assert.Len(t, withoutEscalateCategory(categories, BatchClassificationOptions{}), len(categories))
+	})
+}
@@ -0,0 +1,108 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/llm"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+// ClassificationEstimate is the work a batch classification run would do,
+// worked out without calling the LLM. Merchants the embedding tier or the
+// response cache would answer still count as LLM merchants, so it's an upper
+// bound.
+type ClassificationEstimate struct {
+	Batches           []EstimateBatch // The LLM calls the run would make
+	TotalTransactions int
+	TotalMerchants    int
+	RefundCount       int // Refunds that would inherit their purchase's category
+	RuleMerchants     int // Merchants a pattern or vendor rule classifies
+	RuleTxns          int
+	CheckMerchants    int // Check groups a check pattern classifies
+	CheckTxns         int
+	LLMMerchants      int // Merchants sent to the LLM, including rule matches it confirms
+	LLMTxns           int
+}
+
+// EstimateBatch is one LLM call of an estimated run: the merchants it
+// classifies and the categories offered to them.
+type EstimateBatch struct {
+	Requests   []llm.MerchantBatchRequest
+	Categories []model.Category
+}
+
+// EstimateClassificationBatch groups the transactions ClassifyTransactionsBatch
+// would classify the same way it does, applies rules and check patterns, and
+// returns the LLM calls left over. Nothing is saved and the LLM isn't called.
+func (e *ClassificationEngine) EstimateClassificationBatch(ctx context.Context, fromDate *time.Time, opts BatchClassificationOptions) (*ClassificationEstimate, error) {
+	transactions, err := e.GetTransactionsToClassifyBetween(ctx, fromDate, opts.UntilDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+
+	transactions, refunds := e.carryOverRefunds(ctx, transactions, refundWindow(opts), true)
+	estimate := &ClassificationEstimate{
+		TotalTransactions: len(transactions),
+		RefundCount:       refunds,
+	}
+	if len(transactions) == 0 {
+		return estimate, nil
+	}
+
+	merchantGroups := splitLargeGroups(e.splitMultiCategoryGroups(ctx, e.groupByMerchant(transactions)), opts.MaxGroupSize)
+	sortedMerchants := e.sortMerchantsByVolume(merchantGroups)
+	estimate.TotalMerchants = len(merchantGroups)
+
+	categories, err := e.storage.GetCategories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+	categories = withoutFallbackCategories(withoutEscalateCategory(categories, opts), opts)
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	hints := e.merchantHints(ctx)
+
+	// Workers take merchants BatchSize at a time and send the ones no rule
+	// settles to the LLM in one call
+	for start := 0; start < len(sortedMerchants); start += batchSize {
+		end := min(start+batchSize, len(sortedMerchants))
+
+		var batch EstimateBatch
+		var batchTxns []model.Transaction
+		for _, merchant := range sortedMerchants[start:end] {
+			txns := merchantGroups[merchant]
+			if len(txns) == 0 {
+				continue
+			}
+
+			result := BatchResult{Merchant: merchant, Transactions: txns}
+			if e.applyRules(ctx, &result, opts) && !opts.ConfirmRules {
+				if len(result.UsedPatterns) > 0 {
+					estimate.CheckMerchants++
+					estimate.CheckTxns += len(txns)
+				} else {
+					estimate.RuleMerchants++
+					estimate.RuleTxns += len(txns)
+				}
+				continue
+			}
+
+			estimate.LLMMerchants++
+			estimate.LLMTxns += len(txns)
+			batch.Requests = append(batch.Requests, batchRequest(merchant, txns, hints, opts))
+			batchTxns = append(batchTxns, txns...)
+		}
+
+		if len(batch.Requests) > 0 {
+			batch.Categories = e.filterCategoriesByDirection(categories, batchTxns)
+			estimate.Batches = append(estimate.Batches, batch)
+		}
+	}
+
+	return estimate, nil
+}
@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateClassificationBatch(t *testing.T) {
+	ctx := context.Background()
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+	defer func() { _ = db.Close() }()
+
+	for _, name := range []string{"Groceries", "Gas", "Shopping"} {
+		_, createErr := db.CreateCategoryWithType(ctx, name, name+" purchases", model.CategoryTypeExpense)
+		require.NoError(t, createErr)
+	}
+	require.NoError(t, db.SaveVendor(ctx, &model.Vendor{Name: "Shell", Category: "Gas", LastUpdated: time.Now()}))
+
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	transactions := []model.Transaction{
+		{ID: "tx1", Hash: "hash1", Name: "WALMART #1", MerchantName: "Walmart", Amount: 50, Type: "DEBIT", Date: date, AccountID: "acc1"},
+		{ID: "tx2", Hash: "hash2", Name: "WALMART #2", MerchantName: "Walmart", Amount: 75, Type: "DEBIT", Date: date, AccountID: "acc1"},
+		{ID: "tx3", Hash: "hash3", Name: "SHELL", MerchantName: "Shell", Amount: 40, Type: "DEBIT", Date: date, AccountID: "acc1"},
+		{ID: "tx4", Hash: "hash4", Name: "TARGET", MerchantName: "Target", Amount: 30, Type: "DEBIT", Date: date, AccountID: "acc1"},
+	}
+	require.NoError(t, db.SaveTransactions(ctx, transactions))
+
+	classifier := NewMockClassifier()
+	engine := &ClassificationEngine{storage: db, classifier: classifier, prompter: NewMockPrompter(true)}
+
+	t.Run("rules settle merchants before the LLM", func(t *testing.T) {
+		estimate, err := engine.EstimateClassificationBatch(ctx, nil, BatchClassificationOptions{BatchSize: 5, AutoAcceptThreshold: 0.9})
+		require.NoError(t, err)
+
+		assert.Equal(t, 4, estimate.TotalTransactions)
+		assert.Equal(t, 3, estimate.TotalMerchants)
+		assert.Equal(t, 1, estimate.RuleMerchants)
+		assert.Equal(t, 1, estimate.RuleTxns)
+		assert.Equal(t, 2, estimate.LLMMerchants)
+		assert.Equal(t, 3, estimate.LLMTxns)
+		require.Len(t, estimate.Batches, 1)
+		assert.Len(t, estimate.Batches[0].Requests, 2)
+		assert.Len(t, estimate.Batches[0].Categories, 3)
+	})
+
+	t.Run("one call per batch of merchants", func(t *testing.T) {
+		estimate, err := engine.EstimateClassificationBatch(ctx, nil, BatchClassificationOptions{BatchSize: 1, AutoAcceptThreshold: 0.9})
+		require.NoError(t, err)
+		assert.Len(t, estimate.Batches, 2)
+	})
+
+	t.Run("confirmed rules still need the LLM", func(t *testing.T) {
+		estimate, err := engine.EstimateClassificationBatch(ctx, nil, BatchClassificationOptions{BatchSize: 5, AutoAcceptThreshold: 0.9, ConfirmRules: true})
+		require.NoError(t, err)
+		assert.Equal(t, 0, estimate.RuleMerchants)
+		assert.Equal(t, 3, estimate.LLMMerchants)
+	})
+
+	assert.Zero(t, classifier.CallCount())
+	remaining, err := db.GetTransactionsToClassify(ctx, nil)
+	require.NoError(t, err)
+	assert.Len(t, remaining, 4, "nothing should be classified")
+}
@@ -2,6 +2,7 @@ package engine
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/Veraticus/the-spice-must-flow/internal/model"
@@ -13,6 +14,24 @@ func (e *ClassificationEngine) GetTransactionsToClassify(ctx context.Context, fr
 	return e.storage.GetTransactionsToClassify(ctx, fromDate)
 }
 
+// GetTransactionsToClassifyBetween returns the unclassified transactions dated
+// from fromDate through untilDate. Either bound may be nil to leave that end
+// of the range open.
+func (e *ClassificationEngine) GetTransactionsToClassifyBetween(ctx context.Context, fromDate, untilDate *time.Time) ([]model.Transaction, error) {
+	transactions, err := e.storage.GetTransactionsToClassify(ctx, fromDate)
+	if err != nil || untilDate == nil {
+		return transactions, err
+	}
+
+	bounded := make([]model.Transaction, 0, len(transactions))
+	for _, txn := range transactions {
+		if !txn.Date.After(*untilDate) {
+			bounded = append(bounded, txn)
+		}
+	}
+	return bounded, nil
+}
+
 // GroupByMerchant exposes the grouping method.
 func (e *ClassificationEngine) GroupByMerchant(transactions []model.Transaction) map[string][]model.Transaction {
 	return e.groupByMerchant(transactions)
@@ -57,3 +76,23 @@ func (e *ClassificationEngine) SaveClassification(ctx context.Context, classific
 func (e *ClassificationEngine) SaveVendor(ctx context.Context, vendor *model.Vendor) error {
 	return e.storage.SaveVendor(ctx, vendor)
 }
+
+// RankTransaction asks the classifier to rank categories for a single transaction,
+// offering only categories that fit its direction and any matching check patterns.
+func (e *ClassificationEngine) RankTransaction(ctx context.Context, transaction model.Transaction) (model.CategoryRankings, error) {
+	categories, err := e.storage.GetCategories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+	categories = e.filterCategoriesByDirection(categories, []model.Transaction{transaction})
+
+	var checkPatterns []model.CheckPattern
+	if transaction.Type == "CHECK" {
+		checkPatterns, err = e.storage.GetMatchingCheckPatterns(ctx, transaction)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get check patterns: %w", err)
+		}
+	}
+
+	return e.classifier.SuggestCategoryRankings(ctx, transaction, categories, checkPatterns)
+}
@@ -0,0 +1,44 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTransactionsToClassifyBetween(t *testing.T) {
+	ctx := context.Background()
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+	defer func() { _ = db.Close() }()
+
+	day := func(month, d int) time.Time { return time.Date(2024, time.Month(month), d, 0, 0, 0, 0, time.UTC) }
+	require.NoError(t, db.SaveTransactions(ctx, []model.Transaction{
+		{ID: "jan", Hash: "h1", Name: "A", MerchantName: "A", Amount: 10, Type: "DEBIT", Date: day(1, 15), AccountID: "acc"},
+		{ID: "mar", Hash: "h2", Name: "B", MerchantName: "B", Amount: 10, Type: "DEBIT", Date: day(3, 31), AccountID: "acc"},
+		{ID: "apr", Hash: "h3", Name: "C", MerchantName: "C", Amount: 10, Type: "DEBIT", Date: day(4, 1), AccountID: "acc"},
+	}))
+
+	engine := &ClassificationEngine{storage: db, classifier: NewMockClassifier(), prompter: NewMockPrompter(true)}
+	ids := func(from, until *time.Time) []string {
+		txns, err := engine.GetTransactionsToClassifyBetween(ctx, from, until)
+		require.NoError(t, err)
+		var got []string
+		for _, txn := range txns {
+			got = append(got, txn.ID)
+		}
+		return got
+	}
+
+	start, end := day(2, 1), day(3, 31).AddDate(0, 0, 1).Add(-time.Nanosecond)
+	assert.ElementsMatch(t, []string{"mar"}, ids(&start, &end))
+	assert.ElementsMatch(t, []string{"jan", "mar"}, ids(nil, &end), "no start leaves the range open")
+	assert.ElementsMatch(t, []string{"mar", "apr"}, ids(&start, nil), "no end leaves the range open")
+	assert.ElementsMatch(t, []string{"jan", "mar", "apr"}, ids(nil, nil))
+}
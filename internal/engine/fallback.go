@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+)
+
+// fallbackCategory returns the fallback category for a transaction's
+// direction, and its category type. Transactions without a direction count as
+// expenses; transfers have no fallback.
+func fallbackCategory(txn model.Transaction, opts BatchClassificationOptions) (string, model.CategoryType) {
+	switch txn.Direction {
+	case model.DirectionIncome:
+		return opts.FallbackIncomeCategory, model.CategoryTypeIncome
+	case model.DirectionTransfer:
+		return "", ""
+	default:
+		return opts.FallbackExpenseCategory, model.CategoryTypeExpense
+	}
+}
+
+// withoutFallbackCategories drops the fallback categories from the categories
+// offered to the LLM and in review, so nothing is classified into them by choice.
+func withoutFallbackCategories(categories []model.Category, opts BatchClassificationOptions) []model.Category {
+	if opts.FallbackExpenseCategory == "" && opts.FallbackIncomeCategory == "" {
+		return categories
+	}
+	filtered := make([]model.Category, 0, len(categories))
+	for _, category := range categories {
+		if !strings.EqualFold(category.Name, opts.FallbackExpenseCategory) && !strings.EqualFold(category.Name, opts.FallbackIncomeCategory) {
+			filtered = append(filtered, category)
+		}
+	}
+	return filtered
+}
+
+// saveFallbacks files the transactions of merchants that failed to classify
+// under the fallback category for their direction, creating it the first
+// time. Transactions without a fallback are left unclassified. It returns the
+// number of transactions saved.
+func (e *ClassificationEngine) saveFallbacks(ctx context.Context, results []BatchResult, opts BatchClassificationOptions) (int, error) {
+	resolved := make(map[string]string)
+	resolve := func(name string, categoryType model.CategoryType) (string, error) {
+		if category, ok := resolved[name]; ok {
+			return category, nil
+		}
+		category, err := e.storage.GetCategoryByName(ctx, name)
+		if errors.Is(err, storage.ErrCategoryNotFound) {
+			category, err = e.storage.CreateCategoryWithType(ctx, name,
+				"Transactions that couldn't be classified; recategorize them by hand", categoryType)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to get fallback category %q: %w", name, err)
+		}
+		resolved[name] = category.Name
+		return category.Name, nil
+	}
+
+	saved := 0
+	for _, result := range results {
+		for _, txn := range result.Transactions {
+			name, categoryType := fallbackCategory(txn, opts)
+			if name == "" {
+				continue
+			}
+			category, err := resolve(name, categoryType)
+			if err != nil {
+				return saved, err
+			}
+
+			classification := model.Classification{
+				Transaction:  txn,
+				Category:     category,
+				Status:       model.StatusClassifiedByRule,
+				ClassifiedAt: time.Now(),
+				Notes:        fmt.Sprintf("Fallback: classification failed: %v", result.Error),
+			}
+			if err := e.storage.SaveClassification(ctx, &classification); err != nil {
+				slog.Error("Failed to save fallback classification",
+					"transaction_id", txn.ID,
+					"error", err)
+				continue
+			}
+			saved++
+		}
+	}
+
+	slog.Info("Filed failed merchants under fallback categories",
+		"merchants", len(results),
+		"transactions", saved)
+	return saved, nil
+}
@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyTransactionsBatch_Fallbacks(t *testing.T) {
+	ctx := context.Background()
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+	defer func() { _ = db.Close() }()
+
+	_, err = db.CreateCategoryWithType(ctx, "Gas Stations", "", model.CategoryTypeExpense)
+	require.NoError(t, err)
+	// The income fallback already exists and is reused
+	_, err = db.CreateCategoryWithType(ctx, "Uncategorized Income", "", model.CategoryTypeIncome)
+	require.NoError(t, err)
+
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, db.SaveTransactions(ctx, []model.Transaction{
+		{ID: "gas", Hash: "hash-gas", Name: "SHELL", MerchantName: "Shell", Amount: 40, Type: "DEBIT", Direction: model.DirectionExpense, Date: date, AccountID: "acc1"},
+		{ID: "odd", Hash: "hash-odd", Name: "XQ*7781", MerchantName: "XQ 7781", Amount: 9, Type: "DEBIT", Direction: model.DirectionExpense, Date: date, AccountID: "acc1"},
+		{ID: "pay", Hash: "hash-pay", Name: "ZELLE FROM SAM", MerchantName: "Zelle Sam", Amount: 50, Type: "CREDIT", Direction: model.DirectionIncome, Date: date, AccountID: "acc1"},
+		{ID: "move", Hash: "hash-move", Name: "TRANSFER TO SAVINGS", MerchantName: "Savings Transfer", Amount: 500, Type: "DEBIT", Direction: model.DirectionTransfer, Date: date, AccountID: "acc1"},
+	}))
+
+	// Only Shell gets rankings; every other merchant fails to classify
+	classifier := NewMockClassifier()
+	classifier.SetBatchResponse(map[string]model.CategoryRankings{
+		"Shell": {{Category: "Gas Stations", Score: 0.97}},
+	})
+	engine := &ClassificationEngine{storage: db, classifier: classifier, prompter: NewMockPrompter(true)}
+
+	summary, err := engine.ClassifyTransactionsBatch(ctx, nil, BatchClassificationOptions{
+		AutoAcceptThreshold:     0.95,
+		BatchSize:               5,
+		ParallelWorkers:         1,
+		FallbackExpenseCategory: "Uncategorized Expense",
+		FallbackIncomeCategory:  "Uncategorized Income",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, summary.AutoAcceptedCount)
+	assert.Equal(t, 3, summary.FailedCount)
+	assert.Equal(t, 2, summary.FallbackTxns, "transfers have no fallback")
+	assert.Contains(t, summary.GetDisplay(), `"fallback_transactions":2`)
+
+	expense, err := db.GetCategoryByName(ctx, "Uncategorized Expense")
+	require.NoError(t, err)
+	assert.Equal(t, model.CategoryTypeExpense, expense.Type)
+
+	for category, merchant := range map[string]string{"Uncategorized Expense": "XQ 7781", "Uncategorized Income": "Zelle Sam"} {
+		filed, err := db.QueryTransactions(ctx, model.TransactionQuery{Category: category})
+		require.NoError(t, err)
+		require.Len(t, filed, 1, category)
+		assert.Equal(t, merchant, filed[0].Transaction.MerchantName)
+		assert.Equal(t, model.StatusClassifiedByRule, filed[0].Status)
+		assert.Contains(t, filed[0].Notes, "Fallback")
+	}
+
+	unclassified, err := db.QueryTransactions(ctx, model.TransactionQuery{Status: model.StatusUnclassified})
+	require.NoError(t, err)
+	require.Len(t, unclassified, 1)
+	assert.Equal(t, "move", unclassified[0].Transaction.ID)
+
+	t.Run("fallback categories are never offered", func(t *testing.T) {
+		categories, err := db.GetCategories(ctx)
+		require.NoError(t, err)
+		opts := BatchClassificationOptions{FallbackExpenseCategory: "uncategorized expense", FallbackIncomeCategory: "Uncategorized Income"}
+		for _, category := range withoutFallbackCategories(categories, opts) {
+			assert.NotContains(t, category.Name, "Uncategorized")
+		}
+		assert.Len(t, withoutFallbackCategories(categories, BatchClassificationOptions{}), len(categories))
+	})
+}
@@ -0,0 +1,283 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
+	"github.com/Veraticus/the-spice-must-flow/internal/sheets"
+)
+
+const (
+	// DefaultForecastLookbackMonths is the trailing window used when none is given.
+	DefaultForecastLookbackMonths = 6
+
+	// forecastPriceTolerance is how far apart, relative to each other, a
+	// merchant's charges may be and still count as one price in a forecast.
+	// Bills like utilities vary a little from month to month.
+	forecastPriceTolerance = 0.2
+)
+
+// ForecastConfidence is a coarse indicator of how reliable a projection is.
+type ForecastConfidence string
+
+// Forecast confidence levels.
+const (
+	ForecastConfidenceHigh   ForecastConfidence = "high"
+	ForecastConfidenceMedium ForecastConfidence = "medium"
+	ForecastConfidenceLow    ForecastConfidence = "low"
+)
+
+// ForecastOptions configures a spending forecast.
+type ForecastOptions struct {
+	Month          time.Time // Month to forecast; defaults to next month
+	LookbackMonths int       // Number of full months of history to use
+}
+
+// CategoryForecast is the projected spending for a single category.
+// All amounts are estimates derived from history, not commitments.
+type CategoryForecast struct {
+	Category        string
+	Confidence      ForecastConfidence
+	Projected       float64 // Recurring + Variable
+	Recurring       float64 // Sum of detected recurring merchant amounts
+	Variable        float64 // Trailing monthly average of non-recurring spending
+	StdDev          float64 // Standard deviation of monthly category totals
+	RecurringCount  int     // Number of recurring merchants detected
+	MonthsWithSpend int     // Months in the lookback window with any spending
+}
+
+// SpendingForecast is a per-category projection of a month's spending.
+type SpendingForecast struct {
+	Month          time.Time
+	Categories     []CategoryForecast
+	LookbackMonths int
+	Total          float64
+}
+
+// Forecast projects next month's spending per category from classified history.
+// It combines detected recurring merchants with trailing averages of everything else.
+func Forecast(ctx context.Context, store service.Storage, opts ForecastOptions) (*SpendingForecast, error) {
+	month, lookback := normalizeForecastOptions(opts)
+
+	start := month.AddDate(0, -lookback, 0)
+	end := month.Add(-time.Nanosecond)
+
+	classifications, err := store.GetClassificationsByDateRange(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get classifications for forecast: %w", err)
+	}
+
+	categories, err := store.GetCategories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories for forecast: %w", err)
+	}
+
+	return BuildForecast(classifications, categories, month, lookback), nil
+}
+
+// BuildForecast computes a forecast for month from classifications in the
+// lookback window preceding it. Income, transfers and non-expense categories
+// are excluded.
+func BuildForecast(classifications []model.Classification, categories []model.Category, month time.Time, lookbackMonths int) *SpendingForecast {
+	month, lookbackMonths = normalizeForecastOptions(ForecastOptions{Month: month, LookbackMonths: lookbackMonths})
+	windowStart := month.AddDate(0, -lookbackMonths, 0)
+
+	spending := monthlySpending(classifications, categories, windowStart, lookbackMonths)
+
+	// Recurring merchants are projected at their latest price; the rest of
+	// their category's spending is averaged
+	recurringAmounts := make(map[string]float64)
+	recurringTotals := make(map[string]float64)
+	recurringCounts := make(map[string]int)
+	recurring := findRecurring(classifications, categoryTypesOf(categories), windowStart, month.Add(-time.Nanosecond),
+		recurringRequiredMonths(lookbackMonths), forecastPriceTolerance)
+	for _, s := range recurring {
+		recurringAmounts[s.Category] += s.Amount
+		recurringTotals[s.Category] += s.Total
+		recurringCounts[s.Category]++
+	}
+
+	forecast := &SpendingForecast{
+		Month:          month,
+		LookbackMonths: lookbackMonths,
+		Categories:     make([]CategoryForecast, 0, len(spending)),
+	}
+
+	for category, totals := range spending {
+		cf := CategoryForecast{
+			Category:       category,
+			Recurring:      recurringAmounts[category],
+			RecurringCount: recurringCounts[category],
+		}
+
+		var sum float64
+		for _, total := range totals {
+			sum += total
+		}
+		cf.Variable = math.Max(sum-recurringTotals[category], 0) / float64(lookbackMonths)
+		cf.Projected = roundCents(cf.Recurring + cf.Variable)
+		cf.Recurring = roundCents(cf.Recurring)
+		cf.Variable = roundCents(cf.Variable)
+
+		mean, stdDev := meanStdDev(totals)
+		cf.StdDev = roundCents(stdDev)
+		for _, total := range totals {
+			if total > 0 {
+				cf.MonthsWithSpend++
+			}
+		}
+		cf.Confidence = forecastConfidence(mean, stdDev, cf.MonthsWithSpend, lookbackMonths)
+
+		forecast.Total += cf.Projected
+		forecast.Categories = append(forecast.Categories, cf)
+	}
+
+	forecast.Total = roundCents(forecast.Total)
+
+	// Largest projected spending first
+	sort.Slice(forecast.Categories, func(i, j int) bool {
+		if forecast.Categories[i].Projected != forecast.Categories[j].Projected {
+			return forecast.Categories[i].Projected > forecast.Categories[j].Projected
+		}
+		return forecast.Categories[i].Category < forecast.Categories[j].Category
+	})
+
+	return forecast
+}
+
+// monthlySpending totals expense spending per category for each of the months
+// beginning at start, with the aggregation behind the sheets Monthly Flow tab.
+// Refunds offset their category's spending; months without any are zero.
+func monthlySpending(classifications []model.Classification, categories []model.Category, start time.Time, months int) map[string][]float64 {
+	categoryTypes := categoryTypesOf(categories)
+
+	spending := make([]model.Classification, 0, len(classifications))
+	for _, c := range classifications {
+		if idx := monthIndex(start, c.Transaction.Date); idx < 0 || idx >= months {
+			continue
+		}
+		if isExpenseSpending(c, categoryTypes) {
+			spending = append(spending, c)
+		}
+	}
+
+	points := sheets.BuildTimeSeries(spending, categories, sheets.TimeSeriesOptions{
+		From:       start,
+		To:         start.AddDate(0, months-1, 0),
+		ByCategory: true,
+	})
+
+	totals := make(map[string][]float64)
+	for _, point := range points {
+		idx := monthIndex(start, point.Month)
+		for category, amount := range point.Categories {
+			if totals[category] == nil {
+				totals[category] = make([]float64, months)
+			}
+			totals[category][idx] = amount.InexactFloat64()
+		}
+	}
+	return totals
+}
+
+// categoryTypesOf maps category names to their types.
+func categoryTypesOf(categories []model.Category) map[string]model.CategoryType {
+	categoryTypes := make(map[string]model.CategoryType, len(categories))
+	for _, cat := range categories {
+		categoryTypes[cat.Name] = cat.Type
+	}
+	return categoryTypes
+}
+
+// normalizeForecastOptions fills in defaults and truncates the month to its first day.
+func normalizeForecastOptions(opts ForecastOptions) (time.Time, int) {
+	month := opts.Month
+	if month.IsZero() {
+		month = time.Now().AddDate(0, 1, 0)
+	}
+	month = time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+
+	lookback := opts.LookbackMonths
+	if lookback <= 0 {
+		lookback = DefaultForecastLookbackMonths
+	}
+
+	return month, lookback
+}
+
+// isExpenseSpending reports whether a classification counts as spending:
+// classified, not income or a transfer, and not in a non-expense category.
+func isExpenseSpending(c model.Classification, categoryTypes map[string]model.CategoryType) bool {
+	if c.Category == "" || c.Status == model.StatusUnclassified {
+		return false
+	}
+	if c.Transaction.Direction == model.DirectionIncome || c.Transaction.Direction == model.DirectionTransfer {
+		return false
+	}
+	if catType, ok := categoryTypes[c.Category]; ok && catType != model.CategoryTypeExpense && catType != "" {
+		return false
+	}
+	return true
+}
+
+// monthIndex returns the number of whole calendar months between start and date.
+func monthIndex(start, date time.Time) int {
+	return (date.Year()-start.Year())*12 + int(date.Month()) - int(start.Month())
+}
+
+// forecastMerchant returns the grouping key for a transaction's merchant.
+func forecastMerchant(txn model.Transaction) string {
+	merchant := txn.MerchantName
+	if merchant == "" {
+		merchant = txn.Name
+	}
+	return strings.ToLower(strings.TrimSpace(merchant))
+}
+
+// forecastConfidence grades a projection by how stable the monthly totals were.
+func forecastConfidence(mean, stdDev float64, monthsWithSpend, lookbackMonths int) ForecastConfidence {
+	if mean == 0 || monthsWithSpend*2 < lookbackMonths {
+		return ForecastConfidenceLow
+	}
+
+	variation := stdDev / mean
+	switch {
+	case variation <= 0.25:
+		return ForecastConfidenceHigh
+	case variation <= 0.6:
+		return ForecastConfidenceMedium
+	default:
+		return ForecastConfidenceLow
+	}
+}
+
+// meanStdDev returns the mean and population standard deviation of values.
+func meanStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+
+	return mean, math.Sqrt(squares / float64(len(values)))
+}
+
+// roundCents rounds an amount to two decimal places.
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
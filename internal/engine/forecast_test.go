@@ -0,0 +1,120 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func forecastClassification(category, merchant string, date time.Time, amount float64, direction model.TransactionDirection) model.Classification {
+	return model.Classification{
+		Category: category,
+		Status:   model.StatusClassifiedByAI,
+		Transaction: model.Transaction{
+			MerchantName: merchant,
+			Name:         merchant,
+			Date:         date,
+			Amount:       amount,
+			Direction:    direction,
+		},
+	}
+}
+
+func TestBuildForecast(t *testing.T) {
+	month := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	monthDay := func(m time.Month, day int) time.Time {
+		return time.Date(2024, m, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	categories := []model.Category{
+		{Name: "Utilities", Type: model.CategoryTypeExpense},
+		{Name: "Dining", Type: model.CategoryTypeExpense},
+		{Name: "Salary", Type: model.CategoryTypeIncome},
+		{Name: "Transfers", Type: model.CategoryTypeSystem},
+	}
+
+	var classifications []model.Classification
+	for m := time.January; m <= time.June; m++ {
+		// Stable monthly bill: recurring
+		classifications = append(classifications, forecastClassification("Utilities", "Power Co", monthDay(m, 5), 100, model.DirectionExpense))
+		// Income and transfers are never forecast as spending
+		classifications = append(classifications, forecastClassification("Salary", "Employer", monthDay(m, 15), 5000, model.DirectionIncome))
+		classifications = append(classifications, forecastClassification("Transfers", "Savings", monthDay(m, 20), 500, model.DirectionTransfer))
+	}
+	// Irregular dining spending: variable
+	classifications = append(classifications,
+		forecastClassification("Dining", "Bistro", monthDay(time.February, 3), 60, model.DirectionExpense),
+		forecastClassification("Dining", "Cafe", monthDay(time.April, 9), 30, model.DirectionExpense),
+		forecastClassification("Dining", "Diner", monthDay(time.June, 12), 90, model.DirectionExpense),
+		// Outside the lookback window
+		forecastClassification("Dining", "Bistro", monthDay(time.July, 2), 1000, model.DirectionExpense),
+	)
+
+	forecast := BuildForecast(classifications, categories, month, 6)
+
+	assert.Equal(t, month, forecast.Month)
+	assert.Equal(t, 6, forecast.LookbackMonths)
+	require.Len(t, forecast.Categories, 2)
+
+	utilities := forecast.Categories[0]
+	assert.Equal(t, "Utilities", utilities.Category)
+	assert.InDelta(t, 100.0, utilities.Projected, 0.001)
+	assert.InDelta(t, 100.0, utilities.Recurring, 0.001)
+	assert.Equal(t, 1, utilities.RecurringCount)
+	assert.Equal(t, 6, utilities.MonthsWithSpend)
+	assert.Equal(t, ForecastConfidenceHigh, utilities.Confidence)
+
+	dining := forecast.Categories[1]
+	assert.Equal(t, "Dining", dining.Category)
+	assert.InDelta(t, 30.0, dining.Projected, 0.001) // 180 over 6 months
+	assert.Zero(t, dining.Recurring)
+	assert.Equal(t, 3, dining.MonthsWithSpend)
+	assert.Equal(t, ForecastConfidenceLow, dining.Confidence)
+
+	assert.InDelta(t, 130.0, forecast.Total, 0.001)
+}
+
+func TestBuildForecast_SharedAggregation(t *testing.T) {
+	month := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	monthDay := func(m time.Month, day int) time.Time {
+		return time.Date(2024, m, day, 0, 0, 0, 0, time.UTC)
+	}
+	categories := []model.Category{
+		{Name: "Subscriptions", Type: model.CategoryTypeExpense},
+		{Name: "Shopping", Type: model.CategoryTypeExpense},
+	}
+
+	var classifications []model.Classification
+	prices := []float64{10, 10, 10, 10, 12, 12}
+	for i, m := 0, time.January; m <= time.June; i, m = i+1, m+1 {
+		classifications = append(classifications,
+			forecastClassification("Subscriptions", "Streamer", monthDay(m, 3), prices[i], model.DirectionExpense),
+			// Twice a month is not a subscription, however steady
+			forecastClassification("Shopping", "Market", monthDay(m, 2), 20, model.DirectionExpense),
+			forecastClassification("Shopping", "Market", monthDay(m, 16), 20, model.DirectionExpense),
+		)
+	}
+	// A refund offsets its category's spending
+	refund := forecastClassification("Shopping", "Market", monthDay(time.June, 20), 60, model.DirectionExpense)
+	refund.Transaction.IsRefund = true
+	classifications = append(classifications, refund)
+
+	forecast := BuildForecast(classifications, categories, month, 6)
+	require.Len(t, forecast.Categories, 2)
+
+	shopping := forecast.Categories[0]
+	assert.Equal(t, "Shopping", shopping.Category)
+	assert.Zero(t, shopping.RecurringCount)
+	assert.InDelta(t, 30.0, shopping.Variable, 0.001) // (240 - 60) over 6 months
+	assert.Equal(t, 5, shopping.MonthsWithSpend)
+
+	subscriptions := forecast.Categories[1]
+	assert.Equal(t, "Subscriptions", subscriptions.Category)
+	assert.Equal(t, 1, subscriptions.RecurringCount)
+	assert.InDelta(t, 12.0, subscriptions.Recurring, 0.001) // The latest price
+	assert.Zero(t, subscriptions.Variable)
+	assert.InDelta(t, 12.0, subscriptions.Projected, 0.001)
+}
@@ -0,0 +1,86 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+// ConfidenceAggregation controls which confidence decides whether a merchant
+// group is auto-accepted.
+type ConfidenceAggregation string
+
+// Confidence aggregation strategies.
+const (
+	// AggregateTop uses the score of the group's suggestion, which the LLM gives
+	// after seeing only the sampled transactions.
+	AggregateTop ConfidenceAggregation = "top"
+	// AggregateMin classifies every transaction in the group on its own and uses
+	// the lowest score any of them gives the suggested category.
+	AggregateMin ConfidenceAggregation = "min"
+	// AggregateMean classifies every transaction in the group on its own and uses
+	// the average score they give the suggested category.
+	AggregateMean ConfidenceAggregation = "mean"
+)
+
+// ParseConfidenceAggregation validates an aggregation name. An empty name means AggregateTop.
+func ParseConfidenceAggregation(name string) (ConfidenceAggregation, error) {
+	switch ConfidenceAggregation(name) {
+	case "", AggregateTop:
+		return AggregateTop, nil
+	case AggregateMin:
+		return AggregateMin, nil
+	case AggregateMean:
+		return AggregateMean, nil
+	default:
+		return "", fmt.Errorf("invalid group confidence %q (use %s, %s or %s)", name, AggregateTop, AggregateMin, AggregateMean)
+	}
+}
+
+// perTransaction reports whether the strategy needs each transaction classified.
+func (a ConfidenceAggregation) perTransaction() bool {
+	return a == AggregateMin || a == AggregateMean
+}
+
+// aggregate returns the group confidence. Without per-transaction confidences,
+// or with AggregateTop, it is the suggestion's own score.
+func (a ConfidenceAggregation) aggregate(top float64, confidences []float64) float64 {
+	if !a.perTransaction() || len(confidences) == 0 {
+		return top
+	}
+
+	lowest, sum := confidences[0], 0.0
+	for _, c := range confidences {
+		lowest = min(lowest, c)
+		sum += c
+	}
+	if a == AggregateMin {
+		return lowest
+	}
+	return sum / float64(len(confidences))
+}
+
+// transactionConfidences ranks each transaction on its own and returns the score
+// each gives category. A transaction that fails to classify, or doesn't rank the
+// category at all, counts as zero so the group goes to review.
+func (e *ClassificationEngine) transactionConfidences(ctx context.Context, category string, txns []model.Transaction, categories []model.Category) []float64 {
+	confidences := make([]float64, len(txns))
+	for i, txn := range txns {
+		rankings, err := e.classifier.SuggestCategoryRankings(ctx, txn, categories, nil)
+		if err != nil {
+			slog.Warn("Failed to classify transaction for group confidence",
+				"transaction_id", txn.ID,
+				"error", err)
+			continue
+		}
+		for _, r := range rankings {
+			if r.Category == category {
+				confidences[i] = r.Score
+				break
+			}
+		}
+	}
+	return confidences
+}
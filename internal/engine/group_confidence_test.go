@@ -0,0 +1,121 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// perTransactionClassifier scores Groceries per transaction ID.
+type perTransactionClassifier struct {
+	*MockClassifier
+	scores map[string]float64
+	calls  int
+}
+
+func (c *perTransactionClassifier) SuggestCategoryRankings(_ context.Context, txn model.Transaction, _ []model.Category, _ []model.CheckPattern) (model.CategoryRankings, error) {
+	c.calls++
+	return model.CategoryRankings{{Category: "Groceries", Score: c.scores[txn.ID]}}, nil
+}
+
+func TestParseConfidenceAggregation(t *testing.T) {
+	for name, want := range map[string]ConfidenceAggregation{
+		"":     AggregateTop,
+		"top":  AggregateTop,
+		"min":  AggregateMin,
+		"mean": AggregateMean,
+	} {
+		got, err := ParseConfidenceAggregation(name)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	_, err := ParseConfidenceAggregation("median")
+	assert.Error(t, err)
+}
+
+func TestConfidenceAggregation_Aggregate(t *testing.T) {
+	confidences := []float64{0.9, 0.5, 0.7}
+
+	assert.InDelta(t, 0.95, AggregateTop.aggregate(0.95, confidences), 1e-9)
+	assert.InDelta(t, 0.5, AggregateMin.aggregate(0.95, confidences), 1e-9)
+	assert.InDelta(t, 0.7, AggregateMean.aggregate(0.95, confidences), 1e-9)
+	assert.InDelta(t, 0.95, AggregateMin.aggregate(0.95, nil), 1e-9, "falls back to the top score")
+}
+
+func TestClassifyTransactionsBatch_GroupConfidence(t *testing.T) {
+	tests := []struct {
+		name         string
+		strategy     ConfidenceAggregation
+		scores       map[string]float64
+		wantAccepted int
+		wantCalls    int
+	}{
+		{
+			name:         "top score accepts on the easy sample",
+			strategy:     "",
+			scores:       map[string]float64{"tx1": 0.97, "tx2": 0.40, "tx3": 0.95},
+			wantAccepted: 1,
+			wantCalls:    0,
+		},
+		{
+			name:         "min holds the group for one hard transaction",
+			strategy:     AggregateMin,
+			scores:       map[string]float64{"tx1": 0.97, "tx2": 0.40, "tx3": 0.95},
+			wantAccepted: 0,
+			wantCalls:    3,
+		},
+		{
+			name:         "mean accepts a consistently confident group",
+			strategy:     AggregateMean,
+			scores:       map[string]float64{"tx1": 0.97, "tx2": 0.90, "tx3": 0.95},
+			wantAccepted: 1,
+			wantCalls:    3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			db, err := storage.NewSQLiteStorage(":memory:")
+			require.NoError(t, err)
+			require.NoError(t, db.Migrate(ctx))
+			defer func() { _ = db.Close() }()
+
+			_, err = db.CreateCategoryWithType(ctx, "Groceries", "Food", model.CategoryTypeExpense)
+			require.NoError(t, err)
+
+			var txns []model.Transaction
+			for i, id := range []string{"tx1", "tx2", "tx3"} {
+				txns = append(txns, model.Transaction{
+					ID: id, Hash: "hash-" + id, Name: "WHOLE FOODS", MerchantName: "Whole Foods",
+					Amount: float64(10 * (i + 1)), Type: "DEBIT", Date: time.Now(), AccountID: "acc1",
+				})
+			}
+			require.NoError(t, db.SaveTransactions(ctx, txns))
+
+			classifier := &perTransactionClassifier{MockClassifier: NewMockClassifier(), scores: tt.scores}
+			classifier.SetBatchResponse(map[string]model.CategoryRankings{
+				"Whole Foods": {{Category: "Groceries", Score: 0.96}},
+			})
+			engine := &ClassificationEngine{storage: db, classifier: classifier, prompter: NewMockPrompter(true)}
+
+			summary, err := engine.ClassifyTransactionsBatch(ctx, nil, BatchClassificationOptions{
+				AutoAcceptThreshold: 0.90,
+				BatchSize:           5,
+				ParallelWorkers:     1,
+				SkipManualReview:    true,
+				GroupConfidence:     tt.strategy,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAccepted, summary.AutoAcceptedCount)
+			assert.Equal(t, 1-tt.wantAccepted, summary.NeedsReviewCount)
+			assert.Equal(t, tt.wantCalls, classifier.calls)
+		})
+	}
+}
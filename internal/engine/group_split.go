@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+// splitGroupPattern matches the keys splitLargeGroups gives to sub-groups.
+var splitGroupPattern = regexp.MustCompile(`^(.*) \(part \d+ of \d+\)$`)
+
+// splitLargeGroups splits merchant groups larger than maxSize into sub-groups of
+// similar amounts, so a merchant like Amazon with thousands of purchases can get
+// a different category for small and large orders and be reviewed in pieces.
+// Sub-groups are keyed "<merchant> (part N of M)" with the smallest amounts first,
+// and their transactions are in date order. A maxSize below 1 disables splitting.
+func splitLargeGroups(groups map[string][]model.Transaction, maxSize int) map[string][]model.Transaction {
+	if maxSize < 1 {
+		return groups
+	}
+
+	result := make(map[string][]model.Transaction, len(groups))
+	for merchant, txns := range groups {
+		if len(txns) <= maxSize {
+			result[merchant] = txns
+			continue
+		}
+
+		sorted := make([]model.Transaction, len(txns))
+		copy(sorted, txns)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			if sorted[i].Amount != sorted[j].Amount {
+				return sorted[i].Amount < sorted[j].Amount
+			}
+			return sorted[i].Date.Before(sorted[j].Date)
+		})
+
+		// Spread transactions evenly rather than leaving a tiny last part
+		parts := (len(sorted) + maxSize - 1) / maxSize
+		for part := 0; part < parts; part++ {
+			start := part * len(sorted) / parts
+			end := (part + 1) * len(sorted) / parts
+			chunk := make([]model.Transaction, end-start)
+			copy(chunk, sorted[start:end])
+			sort.SliceStable(chunk, func(i, j int) bool {
+				return chunk[i].Date.Before(chunk[j].Date)
+			})
+			result[splitGroupKey(merchant, part+1, parts)] = chunk
+		}
+	}
+
+	return result
+}
+
+// splitGroupKey names one part of a split merchant.
+func splitGroupKey(merchant string, part, parts int) string {
+	return fmt.Sprintf("%s (part %d of %d)", merchant, part, parts)
+}
+
+// groupMerchantName returns the merchant a group key refers to, removing the
+// part suffix added by splitLargeGroups.
+func groupMerchantName(key string) string {
+	if m := splitGroupPattern.FindStringSubmatch(key); m != nil {
+		return m[1]
+	}
+	return key
+}
+
+// isSplitGroup reports whether a group key is one part of a split merchant.
+func isSplitGroup(key string) bool {
+	return splitGroupPattern.MatchString(key)
+}
+
+// amountRange returns the smallest and largest amounts among txns.
+func amountRange(txns []model.Transaction) (float64, float64) {
+	if len(txns) == 0 {
+		return 0, 0
+	}
+	minAmount, maxAmount := txns[0].Amount, txns[0].Amount
+	for _, txn := range txns[1:] {
+		minAmount = min(minAmount, txn.Amount)
+		maxAmount = max(maxAmount, txn.Amount)
+	}
+	return minAmount, maxAmount
+}
@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitLargeGroups(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	amazon := make([]model.Transaction, 0, 7)
+	for i, amount := range []float64{500, 12, 30, 8, 250, 45, 15} {
+		amazon = append(amazon, model.Transaction{
+			ID:           fmt.Sprintf("amz-%d", i),
+			MerchantName: "Amazon",
+			Amount:       amount,
+			Date:         base.AddDate(0, 0, i),
+		})
+	}
+	groups := map[string][]model.Transaction{
+		"Amazon":    amazon,
+		"Starbucks": {{ID: "sb", MerchantName: "Starbucks", Amount: 5}},
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		assert.Equal(t, groups, splitLargeGroups(groups, 0))
+	})
+
+	t.Run("splits by amount", func(t *testing.T) {
+		split := splitLargeGroups(groups, 3)
+		require.Len(t, split, 4)
+		assert.Len(t, split["Starbucks"], 1, "small groups are untouched")
+
+		part1 := split["Amazon (part 1 of 3)"]
+		part2 := split["Amazon (part 2 of 3)"]
+		part3 := split["Amazon (part 3 of 3)"]
+		assert.Len(t, part1, 2)
+		assert.Len(t, part2, 2)
+		assert.Len(t, part3, 3)
+
+		amounts := func(txns []model.Transaction) []float64 {
+			out := make([]float64, 0, len(txns))
+			for _, txn := range txns {
+				out = append(out, txn.Amount)
+			}
+			return out
+		}
+		// Parts hold amount bands, each in date order
+		assert.Equal(t, []float64{12, 8}, amounts(part1))
+		assert.Equal(t, []float64{30, 15}, amounts(part2))
+		assert.Equal(t, []float64{500, 250, 45}, amounts(part3))
+	})
+}
+
+func TestGroupMerchantName(t *testing.T) {
+	assert.Equal(t, "Amazon", groupMerchantName("Amazon (part 2 of 3)"))
+	assert.Equal(t, "Store (Main St)", groupMerchantName("Store (Main St)"))
+	assert.True(t, isSplitGroup("Amazon (part 1 of 2)"))
+	assert.False(t, isSplitGroup("Amazon"))
+}
+
+func TestClassifyTransactionsBatch_MaxGroupSize(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+	_, err = db.CreateCategoryWithType(ctx, "Shopping", "Shopping", model.CategoryTypeExpense)
+	require.NoError(t, err)
+
+	transactions := make([]model.Transaction, 0, 4)
+	for i := 0; i < 4; i++ {
+		transactions = append(transactions, model.Transaction{
+			ID: fmt.Sprintf("tx%d", i), Hash: fmt.Sprintf("hash%d", i), Name: "AMAZON", MerchantName: "Amazon",
+			Amount: float64(10 * (i + 1)), Date: time.Now(), AccountID: "acc1",
+		})
+	}
+	require.NoError(t, db.SaveTransactions(ctx, transactions))
+
+	classifier := NewMockClassifier()
+	classifier.SetBatchResponse(map[string]model.CategoryRankings{
+		"Amazon (part 1 of 2)": {{Category: "Shopping", Score: 0.99}},
+		"Amazon (part 2 of 2)": {{Category: "Shopping", Score: 0.99}},
+	})
+	engine := New(db, classifier, NewMockPrompter(true))
+
+	summary, err := engine.ClassifyTransactionsBatch(ctx, nil, BatchClassificationOptions{
+		AutoAcceptThreshold: 0.95,
+		BatchSize:           5,
+		ParallelWorkers:     1,
+		SkipManualReview:    true,
+		MaxGroupSize:        2,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.TotalMerchants)
+	assert.Equal(t, 2, summary.AutoAcceptedCount)
+
+	for _, call := range classifier.GetCalls() {
+		assert.Equal(t, "Amazon", call.Transaction.MerchantName)
+	}
+
+	_, err = db.GetVendor(ctx, "Amazon")
+	assert.Error(t, err, "split merchants should not get a vendor rule")
+
+	remaining, err := db.GetTransactionsToClassify(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, remaining)
+}
@@ -23,3 +23,16 @@ type Prompter interface {
 	BatchConfirmClassifications(ctx context.Context, pending []model.PendingClassification) ([]model.Classification, error)
 	GetCompletionStats() service.CompletionStats
 }
+
+// ReviewChunkPrompter is implemented by prompters that can pause a long review
+// session between chunks. Prompters without it review every chunk without asking.
+type ReviewChunkPrompter interface {
+	ConfirmContinueReview(ctx context.Context, reviewed, remaining int) (bool, error)
+}
+
+// CategoryErrorPrompter is implemented by prompters that can tell the user why
+// a merchant is being reviewed again: the category chosen for it couldn't be
+// created. Prompters without it review the merchant again without explanation.
+type CategoryErrorPrompter interface {
+	ReportCategoryError(ctx context.Context, category string, err error) error
+}
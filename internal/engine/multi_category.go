@@ -0,0 +1,58 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"sort"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+// isMultiCategory reports whether a merchant is marked as spanning many
+// categories. Lookup failures count as not marked.
+func (e *ClassificationEngine) isMultiCategory(ctx context.Context, merchantName string) bool {
+	vendor, err := e.getVendor(ctx, merchantName)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			slog.Warn("failed to look up vendor",
+				"merchant", merchantName,
+				"error", err)
+		}
+		return false
+	}
+	return vendor != nil && vendor.MultiCategory
+}
+
+// allowsVendorRule reports whether a vendor rule may be created for a merchant
+// group: never for part of a split merchant or a multi-category merchant.
+func (e *ClassificationEngine) allowsVendorRule(ctx context.Context, group string) bool {
+	return !isSplitGroup(group) && !e.isMultiCategory(ctx, group)
+}
+
+// splitMultiCategoryGroups gives each transaction of a multi-category merchant
+// a group of its own, in date order, so every one is classified and reviewed
+// separately. The groups are keyed like the parts of a split merchant.
+func (e *ClassificationEngine) splitMultiCategoryGroups(ctx context.Context, groups map[string][]model.Transaction) map[string][]model.Transaction {
+	result := make(map[string][]model.Transaction, len(groups))
+	for merchant, txns := range groups {
+		if !e.isMultiCategory(ctx, merchant) {
+			result[merchant] = txns
+			continue
+		}
+
+		sorted := make([]model.Transaction, len(txns))
+		copy(sorted, txns)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].Date.Before(sorted[j].Date)
+		})
+		for i, txn := range sorted {
+			result[splitGroupKey(merchant, i+1, len(sorted))] = []model.Transaction{txn}
+		}
+		slog.Debug("classifying multi-category merchant per transaction",
+			"merchant", merchant,
+			"transactions", len(sorted))
+	}
+	return result
+}
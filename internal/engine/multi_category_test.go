@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyTransactionsBatch_MultiCategoryMerchant(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+	for _, name := range []string{"Shopping", "Groceries", "Home"} {
+		_, err = db.CreateCategoryWithType(ctx, name, name, model.CategoryTypeExpense)
+		require.NoError(t, err)
+	}
+
+	// An old blanket rule that the mark must override
+	require.NoError(t, db.SaveVendor(ctx, &model.Vendor{Name: "Target", Category: "Shopping"}))
+	require.NoError(t, db.SetVendorMultiCategory(ctx, "Target", true))
+
+	base := time.Now().AddDate(0, 0, -3)
+	transactions := make([]model.Transaction, 0, 3)
+	for i := 0; i < 3; i++ {
+		transactions = append(transactions, model.Transaction{
+			ID: fmt.Sprintf("tx%d", i), Hash: fmt.Sprintf("hash%d", i), Name: "TARGET", MerchantName: "Target",
+			Amount: float64(10 * (i + 1)), Date: base.AddDate(0, 0, i), AccountID: "acc1",
+		})
+	}
+	require.NoError(t, db.SaveTransactions(ctx, transactions))
+
+	classifier := NewMockClassifier()
+	classifier.SetBatchResponse(map[string]model.CategoryRankings{
+		"Target (part 1 of 3)": {{Category: "Groceries", Score: 0.99}},
+		"Target (part 2 of 3)": {{Category: "Home", Score: 0.99}},
+		"Target (part 3 of 3)": {{Category: "Groceries", Score: 0.99}},
+	})
+	engine := New(db, classifier, NewMockPrompter(true))
+
+	summary, err := engine.ClassifyTransactionsBatch(ctx, nil, BatchClassificationOptions{
+		AutoAcceptThreshold: 0.95,
+		BatchSize:           5,
+		ParallelWorkers:     1,
+		SkipManualReview:    true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, summary.TotalMerchants, "each transaction is classified on its own")
+	assert.Equal(t, 3, summary.AutoAcceptedCount)
+
+	classifications, err := db.GetClassificationsByDateRange(ctx, base.AddDate(0, 0, -1), time.Now())
+	require.NoError(t, err)
+	got := map[string]string{}
+	for _, c := range classifications {
+		got[c.Transaction.ID] = c.Category
+	}
+	assert.Equal(t, map[string]string{"tx0": "Groceries", "tx1": "Home", "tx2": "Groceries"}, got)
+
+	vendor, err := db.GetVendor(ctx, "Target")
+	require.NoError(t, err)
+	assert.True(t, vendor.MultiCategory)
+	assert.Equal(t, "Shopping", vendor.Category, "no rule is created from the results")
+}
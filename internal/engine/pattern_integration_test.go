@@ -35,6 +35,9 @@ func (u UnimplementedStorage) GetTransactionsToClassify(_ context.Context, _ *ti
 func (u UnimplementedStorage) GetTransactionByID(_ context.Context, _ string) (*model.Transaction, error) {
 	panic("unimplemented")
 }
+func (u UnimplementedStorage) GetTransactionByHash(_ context.Context, _ string) (*model.Transaction, error) {
+	panic("unimplemented")
+}
 func (u UnimplementedStorage) GetTransactionsByCategory(_ context.Context, _ string) ([]model.Transaction, error) {
 	panic("unimplemented")
 }
@@ -62,15 +65,33 @@ func (u UnimplementedStorage) GetLatestTransactionDate(_ context.Context) (time.
 func (u UnimplementedStorage) GetCategorySummary(_ context.Context, _, _ time.Time) (map[string]float64, error) {
 	panic("unimplemented")
 }
+func (u UnimplementedStorage) QueryTransactions(_ context.Context, _ model.TransactionQuery) ([]model.Classification, error) {
+	panic("unimplemented")
+}
 func (u UnimplementedStorage) GetMerchantSummary(_ context.Context, _, _ time.Time) (map[string]float64, error) {
 	panic("unimplemented")
 }
+func (u UnimplementedStorage) GetClassificationCoverage(_ context.Context, _, _ time.Time) ([]model.CoverageMonth, error) {
+	panic("unimplemented")
+}
+func (u UnimplementedStorage) GetUnclassifiedMerchants(_ context.Context, _, _ time.Time, _ int) ([]model.UnclassifiedMerchant, error) {
+	panic("unimplemented")
+}
+func (u UnimplementedStorage) GetCategoryAmountStats(_ context.Context) (map[string]model.CategoryAmountStats, error) {
+	panic("unimplemented")
+}
 func (u UnimplementedStorage) GetVendor(_ context.Context, _ string) (*model.Vendor, error) {
 	panic("unimplemented")
 }
+func (u UnimplementedStorage) GetVendorRule(_ context.Context, _ string) (*model.Vendor, error) {
+	panic("unimplemented")
+}
 func (u UnimplementedStorage) SaveVendor(_ context.Context, _ *model.Vendor) error {
 	panic("unimplemented")
 }
+func (u UnimplementedStorage) SetVendorMultiCategory(_ context.Context, _ string, _ bool) error {
+	panic("unimplemented")
+}
 func (u UnimplementedStorage) DeleteVendor(_ context.Context, _ string) error {
 	panic("unimplemented")
 }
@@ -98,18 +119,87 @@ func (u UnimplementedStorage) UpdateVendorCategoriesByID(_ context.Context, _, _
 func (u UnimplementedStorage) FindVendorMatch(_ context.Context, _ string) (*model.Vendor, error) {
 	panic("unimplemented")
 }
+func (u UnimplementedStorage) RecomputeVendorUseCounts(_ context.Context) ([]model.VendorRecount, error) {
+	panic("unimplemented")
+}
+func (u UnimplementedStorage) SaveMerchantHint(_ context.Context, _ *model.MerchantHint) error {
+	panic("unimplemented")
+}
+func (u UnimplementedStorage) GetMerchantHints(_ context.Context) ([]model.MerchantHint, error) {
+	panic("unimplemented")
+}
+func (u UnimplementedStorage) DeleteMerchantHint(_ context.Context, _ string) error {
+	panic("unimplemented")
+}
+func (u UnimplementedStorage) SaveMerchantEmbeddings(_ context.Context, _ []model.MerchantEmbedding) error {
+	panic("unimplemented")
+}
+func (u UnimplementedStorage) GetMerchantEmbeddings(_ context.Context, _ string) ([]model.MerchantEmbedding, error) {
+	panic("unimplemented")
+}
+func (u UnimplementedStorage) GetMerchantsWithoutEmbedding(_ context.Context, _ string) ([]string, error) {
+	panic("unimplemented")
+}
+func (u UnimplementedStorage) SaveCategoryAlias(_ context.Context, _, _ string) error {
+	panic("unimplemented")
+}
+func (u UnimplementedStorage) GetCategoryAliases(_ context.Context) ([]model.CategoryAlias, error) {
+	panic("unimplemented")
+}
+func (u UnimplementedStorage) DeleteCategoryAlias(_ context.Context, _ string) error {
+	panic("unimplemented")
+}
+func (u UnimplementedStorage) SaveCategorySnapshot(_ context.Context, _ *model.CategorySnapshot) error {
+	panic("unimplemented")
+}
+func (u UnimplementedStorage) GetCategorySnapshot(_ context.Context, _ int64) (*model.CategorySnapshot, error) {
+	panic("unimplemented")
+}
+func (u UnimplementedStorage) GetCategorySnapshots(_ context.Context) ([]model.CategorySnapshot, error) {
+	panic("unimplemented")
+}
 func (u UnimplementedStorage) SaveClassification(_ context.Context, _ *model.Classification) error {
 	panic("unimplemented")
 }
+func (u UnimplementedStorage) SaveClassifications(_ context.Context, _ []model.Classification) error {
+	panic("unimplemented")
+}
 func (u UnimplementedStorage) GetClassificationsByDateRange(_ context.Context, _, _ time.Time) ([]model.Classification, error) {
 	panic("unimplemented")
 }
+func (u UnimplementedStorage) GetCategoryChanges(_ context.Context, _ time.Time) ([]model.CategoryChange, error) {
+	panic("unimplemented")
+}
+func (u UnimplementedStorage) SaveClassificationHistory(_ context.Context, _ []model.Classification) error {
+	panic("unimplemented")
+}
+func (u UnimplementedStorage) GetConfidenceOutcomes(_ context.Context) ([]model.ConfidenceOutcome, error) {
+	panic("unimplemented")
+}
 func (u UnimplementedStorage) GetClassificationsByConfidence(_ context.Context, _ float64, _ bool) ([]model.Classification, error) {
 	panic("unimplemented")
 }
 func (u UnimplementedStorage) ClearAllClassifications(_ context.Context) error {
 	panic("unimplemented")
 }
+func (u UnimplementedStorage) UpdateBusinessPercentByCategory(_ context.Context, _ string, _ int) (int64, error) {
+	panic("unimplemented")
+}
+func (u UnimplementedStorage) FindRefundedPurchase(_ context.Context, _ model.Transaction, _ time.Duration) (*model.Classification, error) {
+	panic("unimplemented")
+}
+func (u UnimplementedStorage) MarkTransactionRefund(_ context.Context, _, _ string) error {
+	panic("unimplemented")
+}
+func (u UnimplementedStorage) UpdateTransactionDirections(_ context.Context, _ map[string]model.TransactionDirection) error {
+	panic("unimplemented")
+}
+func (u UnimplementedStorage) HasClassificationHistory(_ context.Context, _ string) (bool, error) {
+	panic("unimplemented")
+}
+func (u UnimplementedStorage) UpdateBusinessPercentByVendor(_ context.Context, _ string, _ int) (int64, error) {
+	panic("unimplemented")
+}
 func (u UnimplementedStorage) GetCategories(_ context.Context) ([]model.Category, error) {
 	panic("unimplemented")
 }
@@ -128,6 +218,9 @@ func (u UnimplementedStorage) UpdateCategory(_ context.Context, _ int, _, _ stri
 func (u UnimplementedStorage) DeleteCategory(_ context.Context, _ int) error {
 	panic("unimplemented")
 }
+func (u UnimplementedStorage) MergeCategories(_ context.Context, _, _ int) (*model.CategoryMergeResult, error) {
+	panic("unimplemented")
+}
 func (u UnimplementedStorage) CreateCheckPattern(_ context.Context, _ *model.CheckPattern) error {
 	panic("unimplemented")
 }
@@ -158,6 +251,9 @@ func (u UnimplementedStorage) GetPatternRule(_ context.Context, _ int) (*model.P
 func (u UnimplementedStorage) GetActivePatternRules(_ context.Context) ([]model.PatternRule, error) {
 	panic("unimplemented")
 }
+func (u UnimplementedStorage) GetAllPatternRules(_ context.Context) ([]model.PatternRule, error) {
+	panic("unimplemented")
+}
 func (u UnimplementedStorage) UpdatePatternRule(_ context.Context, _ *model.PatternRule) error {
 	panic("unimplemented")
 }
@@ -179,6 +275,12 @@ func (u UnimplementedStorage) BeginTx(_ context.Context) (service.Transaction, e
 func (u UnimplementedStorage) UpdateCategoryBusinessPercent(_ context.Context, _ int, _ int) error {
 	panic("unimplemented")
 }
+func (u UnimplementedStorage) SetCategoryAlwaysReview(_ context.Context, _ int, _ bool) error {
+	panic("unimplemented")
+}
+func (u UnimplementedStorage) SetCategoryVendorRuleThreshold(_ context.Context, _ int, _ float64) error {
+	panic("unimplemented")
+}
 func (u UnimplementedStorage) Close() error {
 	panic("unimplemented")
 }
@@ -0,0 +1,240 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
+)
+
+// PaycheckOptions configures paycheck grouping.
+type PaycheckOptions struct {
+	Start time.Time // First day of income to look at; zero means no lower bound
+	End   time.Time // Last day of income to look at; zero means no upper bound
+	// Sources are regular expressions, matched case-insensitively against the
+	// merchant name or description, naming the payers whose income counts as
+	// paychecks. Without sources, only income split across accounts counts.
+	Sources []string
+}
+
+// PaycheckDeposit is one part of a paycheck, deposited into one account.
+type PaycheckDeposit struct {
+	TransactionID string
+	AccountID     string
+	Amount        float64
+}
+
+// Paycheck is the income from one source on one day, however many accounts
+// it was deposited into.
+type Paycheck struct {
+	Date     time.Time
+	Source   string
+	Amount   float64           // All deposits together
+	Deposits []PaycheckDeposit // Largest first
+}
+
+// Split reports whether the paycheck was deposited into more than one account.
+func (p Paycheck) Split() bool {
+	accounts := make(map[string]bool, len(p.Deposits))
+	for _, deposit := range p.Deposits {
+		accounts[deposit.AccountID] = true
+	}
+	return len(accounts) > 1
+}
+
+// PaycheckAccount is the part of a source's paychecks deposited into one account.
+type PaycheckAccount struct {
+	AccountID string
+	Amount    float64
+}
+
+// PaycheckSource sums up the paychecks from one source.
+type PaycheckSource struct {
+	Source    string
+	Accounts  []PaycheckAccount // Largest share first
+	Paychecks int
+	Amount    float64
+}
+
+// Share is the part of the source's paychecks that went to an account, from 0 to 1.
+func (s PaycheckSource) Share(account PaycheckAccount) float64 {
+	if s.Amount == 0 {
+		return 0
+	}
+	return account.Amount / s.Amount
+}
+
+// DetectPaychecks finds paychecks in the income history. It only reads from storage.
+func DetectPaychecks(ctx context.Context, store service.Storage, opts PaycheckOptions) ([]Paycheck, error) {
+	sources, err := compilePaycheckSources(opts.Sources)
+	if err != nil {
+		return nil, err
+	}
+
+	query := model.TransactionQuery{Direction: model.DirectionIncome}
+	if !opts.Start.IsZero() {
+		query.StartDate = &opts.Start
+	}
+	if !opts.End.IsZero() {
+		query.EndDate = &opts.End
+	}
+	classifications, err := store.QueryTransactions(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get income for paychecks: %w", err)
+	}
+
+	transactions := make([]model.Transaction, 0, len(classifications))
+	for _, c := range classifications {
+		transactions = append(transactions, c.Transaction)
+	}
+	return groupPaychecks(transactions, sources), nil
+}
+
+// GroupPaychecks combines income from the same source on the same day into
+// one paycheck. Income from a source matching one of the patterns is always a
+// paycheck; without patterns, only income deposited into more than one
+// account is. Paychecks are ordered by date, then source.
+func GroupPaychecks(transactions []model.Transaction, patterns []string) ([]Paycheck, error) {
+	sources, err := compilePaycheckSources(patterns)
+	if err != nil {
+		return nil, err
+	}
+	return groupPaychecks(transactions, sources), nil
+}
+
+func groupPaychecks(transactions []model.Transaction, sources []*regexp.Regexp) []Paycheck {
+	type paycheckKey struct {
+		source string
+		day    string
+	}
+
+	byKey := make(map[paycheckKey]*Paycheck)
+	for _, txn := range transactions {
+		if txn.Direction != model.DirectionIncome || txn.Amount == 0 {
+			continue
+		}
+		if len(sources) > 0 && !matchesPaycheckSource(txn, sources) {
+			continue
+		}
+
+		key := paycheckKey{source: forecastMerchant(txn), day: txn.Date.Format("2006-01-02")}
+		paycheck, ok := byKey[key]
+		if !ok {
+			name := txn.MerchantName
+			if name == "" {
+				name = txn.Name
+			}
+			paycheck = &Paycheck{
+				Date:   time.Date(txn.Date.Year(), txn.Date.Month(), txn.Date.Day(), 0, 0, 0, 0, txn.Date.Location()),
+				Source: name,
+			}
+			byKey[key] = paycheck
+		}
+
+		amount := math.Abs(txn.Amount)
+		paycheck.Amount += amount
+		paycheck.Deposits = append(paycheck.Deposits, PaycheckDeposit{
+			TransactionID: txn.ID,
+			AccountID:     txn.AccountID,
+			Amount:        amount,
+		})
+	}
+
+	paychecks := make([]Paycheck, 0, len(byKey))
+	for _, paycheck := range byKey {
+		if len(sources) == 0 && !paycheck.Split() {
+			continue
+		}
+		sort.Slice(paycheck.Deposits, func(i, j int) bool {
+			if paycheck.Deposits[i].Amount != paycheck.Deposits[j].Amount {
+				return paycheck.Deposits[i].Amount > paycheck.Deposits[j].Amount
+			}
+			return paycheck.Deposits[i].AccountID < paycheck.Deposits[j].AccountID
+		})
+		paychecks = append(paychecks, *paycheck)
+	}
+
+	sort.Slice(paychecks, func(i, j int) bool {
+		if !paychecks[i].Date.Equal(paychecks[j].Date) {
+			return paychecks[i].Date.Before(paychecks[j].Date)
+		}
+		return paychecks[i].Source < paychecks[j].Source
+	})
+
+	return paychecks
+}
+
+// SummarizePaychecks totals paychecks per source and shows how each source's
+// pay was split across accounts. Sources are ordered by total, largest first.
+func SummarizePaychecks(paychecks []Paycheck) []PaycheckSource {
+	bySource := make(map[string]*PaycheckSource)
+	accounts := make(map[string]map[string]float64)
+	var order []string
+	for _, paycheck := range paychecks {
+		key := forecastMerchant(model.Transaction{MerchantName: paycheck.Source})
+		source, ok := bySource[key]
+		if !ok {
+			source = &PaycheckSource{Source: paycheck.Source}
+			bySource[key] = source
+			accounts[key] = make(map[string]float64)
+			order = append(order, key)
+		}
+		source.Paychecks++
+		source.Amount += paycheck.Amount
+		for _, deposit := range paycheck.Deposits {
+			accounts[key][deposit.AccountID] += deposit.Amount
+		}
+	}
+
+	summaries := make([]PaycheckSource, 0, len(order))
+	for _, key := range order {
+		source := bySource[key]
+		for accountID, amount := range accounts[key] {
+			source.Accounts = append(source.Accounts, PaycheckAccount{AccountID: accountID, Amount: amount})
+		}
+		sort.Slice(source.Accounts, func(i, j int) bool {
+			if source.Accounts[i].Amount != source.Accounts[j].Amount {
+				return source.Accounts[i].Amount > source.Accounts[j].Amount
+			}
+			return source.Accounts[i].AccountID < source.Accounts[j].AccountID
+		})
+		summaries = append(summaries, *source)
+	}
+
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].Amount > summaries[j].Amount
+	})
+	return summaries
+}
+
+// compilePaycheckSources compiles source patterns to case-insensitive regular expressions.
+func compilePaycheckSources(patterns []string) ([]*regexp.Regexp, error) {
+	sources := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		if strings.TrimSpace(pattern) == "" {
+			continue
+		}
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid paycheck source %q: %w", pattern, err)
+		}
+		sources = append(sources, re)
+	}
+	return sources, nil
+}
+
+// matchesPaycheckSource reports whether a transaction's merchant name or description matches a source.
+func matchesPaycheckSource(txn model.Transaction, sources []*regexp.Regexp) bool {
+	for _, source := range sources {
+		if source.MatchString(txn.MerchantName) || source.MatchString(txn.Name) {
+			return true
+		}
+	}
+	return false
+}
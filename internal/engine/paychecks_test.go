@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupPaychecks(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2024, time.March, d, 9, 0, 0, 0, time.UTC)
+	}
+	income := func(id, merchant, account string, date time.Time, amount float64) model.Transaction {
+		return model.Transaction{ID: id, MerchantName: merchant, Name: merchant, AccountID: account, Date: date, Amount: amount, Direction: model.DirectionIncome}
+	}
+
+	transactions := []model.Transaction{
+		// Split into checking and savings
+		income("p1", "ACME Payroll", "checking", day(1), 2400),
+		income("p2", "ACME Payroll", "savings", day(1).Add(time.Hour), -600),
+		income("p3", "ACME Payroll", "checking", day(15), 2400),
+		income("p4", "ACME Payroll", "savings", day(15), 600),
+		// Not split
+		income("i1", "Bank Interest", "savings", day(31), 4.20),
+		// Same source, different days: not one paycheck
+		income("s1", "Side Gig", "checking", day(3), 100),
+		income("s2", "Side Gig", "savings", day(4), 100),
+		{ID: "e1", MerchantName: "ACME Payroll", AccountID: "checking", Date: day(1), Amount: 50, Direction: model.DirectionExpense},
+	}
+
+	t.Run("without sources only splits count", func(t *testing.T) {
+		paychecks, err := GroupPaychecks(transactions, nil)
+		require.NoError(t, err)
+		require.Len(t, paychecks, 2)
+
+		first := paychecks[0]
+		assert.Equal(t, time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), first.Date)
+		assert.Equal(t, "ACME Payroll", first.Source)
+		assert.Equal(t, 3000.0, first.Amount)
+		assert.True(t, first.Split())
+		assert.Equal(t, []PaycheckDeposit{
+			{TransactionID: "p1", AccountID: "checking", Amount: 2400},
+			{TransactionID: "p2", AccountID: "savings", Amount: 600},
+		}, first.Deposits)
+
+		summary := SummarizePaychecks(paychecks)
+		require.Len(t, summary, 1)
+		assert.Equal(t, 2, summary[0].Paychecks)
+		assert.Equal(t, 6000.0, summary[0].Amount)
+		require.Len(t, summary[0].Accounts, 2)
+		assert.Equal(t, "checking", summary[0].Accounts[0].AccountID)
+		assert.InDelta(t, 0.8, summary[0].Share(summary[0].Accounts[0]), 0.001)
+	})
+
+	t.Run("sources count unsplit income too", func(t *testing.T) {
+		paychecks, err := GroupPaychecks(transactions, []string{"side gig", "^acme"})
+		require.NoError(t, err)
+		require.Len(t, paychecks, 4)
+		assert.Equal(t, "Side Gig", paychecks[1].Source)
+		assert.False(t, paychecks[1].Split())
+
+		summary := SummarizePaychecks(paychecks)
+		require.Len(t, summary, 2)
+		assert.Equal(t, "ACME Payroll", summary[0].Source)
+		assert.Equal(t, "Side Gig", summary[1].Source)
+		assert.Equal(t, 2, summary[1].Paychecks)
+	})
+
+	t.Run("invalid source", func(t *testing.T) {
+		_, err := GroupPaychecks(transactions, []string{"acme("})
+		assert.ErrorContains(t, err, "invalid paycheck source")
+	})
+}
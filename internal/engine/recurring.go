@@ -0,0 +1,236 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
+)
+
+// DefaultRecurringLookbackMonths is the history searched for recurring
+// charges when none is given. Price changes are rare, so it is longer than
+// the forecast window.
+const DefaultRecurringLookbackMonths = 12
+
+// recurringMonthRatio is the fraction of lookback months a merchant must
+// appear in to be treated as recurring.
+const recurringMonthRatio = 0.75
+
+// RecurringOptions configures recurring charge detection.
+type RecurringOptions struct {
+	End            time.Time // Last day of history to look at; defaults to now
+	LookbackMonths int       // Full months of history before End's month
+	// Tolerance is the relative difference between two charges still counted
+	// as the same price, e.g. 0.01 for 1%. 0 means amounts must match to the cent.
+	Tolerance float64
+}
+
+// PriceChange is a change in the amount of a recurring charge.
+type PriceChange struct {
+	Date      time.Time // First charge at the new amount
+	OldAmount float64
+	NewAmount float64
+}
+
+// Percent is the change relative to the old amount, in percent.
+func (c PriceChange) Percent() float64 {
+	if c.OldAmount == 0 {
+		return 0
+	}
+	return (c.NewAmount - c.OldAmount) / c.OldAmount * 100
+}
+
+// RecurringSeries is a merchant charged about once a month, like a subscription.
+type RecurringSeries struct {
+	First    time.Time
+	Last     time.Time
+	Merchant string
+	Category string  // Category of the latest charge
+	Amount   float64 // Latest price
+	Total    float64 // Sum of all charges in the window
+	Charges  int
+	Changes  []PriceChange // Oldest first
+}
+
+// recurringCharge is one charge of a candidate series.
+type recurringCharge struct {
+	date     time.Time
+	amount   float64
+	merchant string
+	category string
+}
+
+// DetectRecurring finds recurring charges in classified expense history and
+// the price changes within each. It only reads from storage.
+func DetectRecurring(ctx context.Context, store service.Storage, opts RecurringOptions) ([]RecurringSeries, error) {
+	end, lookback := normalizeRecurringOptions(opts)
+	start := recurringWindowStart(end, lookback)
+
+	classifications, err := store.GetClassificationsByDateRange(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get classifications for recurring charges: %w", err)
+	}
+
+	categories, err := store.GetCategories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories for recurring charges: %w", err)
+	}
+
+	return BuildRecurring(classifications, categories, RecurringOptions{End: end, LookbackMonths: lookback, Tolerance: opts.Tolerance}), nil
+}
+
+// BuildRecurring groups expense classifications by merchant and keeps the
+// merchants charged at most once a month, in most of the months of the
+// window, at a steady price. A price is steady once it was charged twice in a
+// row; only the latest price may have been charged once. Each switch between
+// steady prices is a price change.
+func BuildRecurring(classifications []model.Classification, categories []model.Category, opts RecurringOptions) []RecurringSeries {
+	end, lookback := normalizeRecurringOptions(opts)
+	start := recurringWindowStart(end, lookback)
+
+	// Months are counted over the full months of the window; the current one
+	// may not have been charged yet
+	series := findRecurring(classifications, categoryTypesOf(categories), start, end, recurringRequiredMonths(lookback), opts.Tolerance)
+
+	sort.Slice(series, func(i, j int) bool {
+		if series[i].Amount != series[j].Amount {
+			return series[i].Amount > series[j].Amount
+		}
+		return series[i].Merchant < series[j].Merchant
+	})
+
+	return series
+}
+
+// findRecurring is the recurring charge detector shared by BuildRecurring and
+// BuildForecast. It returns the series among expense charges from start
+// through end that were charged in at least requiredMonths months, in no
+// particular order. Refunds are not charges and never break a series.
+func findRecurring(classifications []model.Classification, categoryTypes map[string]model.CategoryType, start, end time.Time, requiredMonths int, tolerance float64) []RecurringSeries {
+	byMerchant := make(map[string][]recurringCharge)
+	for _, c := range classifications {
+		if !isExpenseSpending(c, categoryTypes) || c.Transaction.Amount <= 0 || c.Transaction.IsRefund {
+			continue
+		}
+		if c.Transaction.Date.Before(start) || c.Transaction.Date.After(end) {
+			continue
+		}
+		merchant := forecastMerchant(c.Transaction)
+		name := c.Transaction.MerchantName
+		if name == "" {
+			name = c.Transaction.Name
+		}
+		byMerchant[merchant] = append(byMerchant[merchant], recurringCharge{
+			date:     c.Transaction.Date,
+			amount:   c.Transaction.Amount,
+			merchant: name,
+			category: c.Category,
+		})
+	}
+
+	series := make([]RecurringSeries, 0)
+	for _, charges := range byMerchant {
+		if s, ok := recurringSeries(charges, start, requiredMonths, tolerance); ok {
+			series = append(series, s)
+		}
+	}
+	return series
+}
+
+// recurringRequiredMonths is how many months of a lookback window a merchant
+// must have charged in to be recurring.
+func recurringRequiredMonths(lookback int) int {
+	required := int(math.Ceil(float64(lookback) * recurringMonthRatio))
+	if required < 2 {
+		required = 2
+	}
+	return required
+}
+
+// recurringSeries checks whether a merchant's charges form a recurring series
+// and finds its price changes.
+func recurringSeries(charges []recurringCharge, start time.Time, requiredMonths int, tolerance float64) (RecurringSeries, bool) {
+	if len(charges) < requiredMonths {
+		return RecurringSeries{}, false
+	}
+
+	sort.Slice(charges, func(i, j int) bool {
+		return charges[i].date.Before(charges[j].date)
+	})
+
+	months := make(map[int]bool, len(charges))
+	for _, charge := range charges {
+		idx := monthIndex(start, charge.date)
+		if months[idx] {
+			return RecurringSeries{}, false
+		}
+		months[idx] = true
+	}
+
+	// Runs of consecutive charges at the same price
+	var runs [][]recurringCharge
+	for _, charge := range charges {
+		if n := len(runs); n > 0 && samePrice(runs[n-1][0].amount, charge.amount, tolerance) {
+			runs[n-1] = append(runs[n-1], charge)
+			continue
+		}
+		runs = append(runs, []recurringCharge{charge})
+	}
+	for _, run := range runs[:len(runs)-1] {
+		if len(run) < 2 {
+			return RecurringSeries{}, false
+		}
+	}
+
+	last := charges[len(charges)-1]
+	s := RecurringSeries{
+		First:    charges[0].date,
+		Last:     last.date,
+		Merchant: last.merchant,
+		Category: last.category,
+		Amount:   last.amount,
+		Charges:  len(charges),
+	}
+	for _, charge := range charges {
+		s.Total += charge.amount
+	}
+	for i := 1; i < len(runs); i++ {
+		previous := runs[i-1]
+		s.Changes = append(s.Changes, PriceChange{
+			Date:      runs[i][0].date,
+			OldAmount: previous[len(previous)-1].amount,
+			NewAmount: runs[i][0].amount,
+		})
+	}
+	return s, true
+}
+
+// samePrice reports whether two amounts differ by no more than tolerance,
+// relative to the first. Amounts always match within half a cent.
+func samePrice(reference, amount, tolerance float64) bool {
+	return math.Abs(amount-reference) <= math.Max(reference*tolerance, 0.005)
+}
+
+// normalizeRecurringOptions fills in the defaults.
+func normalizeRecurringOptions(opts RecurringOptions) (time.Time, int) {
+	end := opts.End
+	if end.IsZero() {
+		end = time.Now()
+	}
+
+	lookback := opts.LookbackMonths
+	if lookback <= 0 {
+		lookback = DefaultRecurringLookbackMonths
+	}
+
+	return end, lookback
+}
+
+// recurringWindowStart is the first day of the month lookback months before end's month.
+func recurringWindowStart(end time.Time, lookback int) time.Time {
+	return time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, end.Location()).AddDate(0, -lookback, 0)
+}
@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildRecurring(t *testing.T) {
+	end := time.Date(2024, 7, 20, 0, 0, 0, 0, time.UTC)
+	monthDay := func(m time.Month, day int) time.Time {
+		return time.Date(2024, m, day, 0, 0, 0, 0, time.UTC)
+	}
+	categories := []model.Category{
+		{Name: "Subscriptions", Type: model.CategoryTypeExpense},
+		{Name: "Groceries", Type: model.CategoryTypeExpense},
+		{Name: "Salary", Type: model.CategoryTypeIncome},
+	}
+
+	var classifications []model.Classification
+	streamer := []float64{15.49, 15.49, 15.49, 17.99, 17.99, 17.99}
+	gym := []float64{40, 40, 40, 40, 40, 40.20}
+	grocer := []float64{80, 95, 62, 110, 70, 88}
+	for i, m := 0, time.February; m <= time.July; i, m = i+1, m+1 {
+		classifications = append(classifications,
+			forecastClassification("Subscriptions", "Streamer", monthDay(m, 3), streamer[i], model.DirectionExpense),
+			forecastClassification("Subscriptions", "Gym", monthDay(m, 1), gym[i], model.DirectionExpense),
+			// Charged monthly, but never the same price twice in a row
+			forecastClassification("Groceries", "Corner Grocer", monthDay(m, 10), grocer[i], model.DirectionExpense),
+			forecastClassification("Salary", "Employer", monthDay(m, 15), 5000, model.DirectionIncome),
+		)
+	}
+	// Charged twice in a month: not a subscription
+	classifications = append(classifications,
+		forecastClassification("Groceries", "Market", monthDay(time.March, 2), 20, model.DirectionExpense),
+		forecastClassification("Groceries", "Market", monthDay(time.March, 20), 20, model.DirectionExpense),
+		forecastClassification("Groceries", "Market", monthDay(time.April, 2), 20, model.DirectionExpense),
+		forecastClassification("Groceries", "Market", monthDay(time.May, 2), 20, model.DirectionExpense),
+		forecastClassification("Groceries", "Market", monthDay(time.June, 2), 20, model.DirectionExpense),
+	)
+
+	t.Run("exact prices", func(t *testing.T) {
+		series := BuildRecurring(classifications, categories, RecurringOptions{End: end, LookbackMonths: 6})
+		require.Len(t, series, 2)
+
+		gymSeries, streamerSeries := series[0], series[1]
+		assert.Equal(t, "Gym", gymSeries.Merchant)
+		assert.Equal(t, 40.20, gymSeries.Amount)
+		assert.Equal(t, []PriceChange{{Date: monthDay(time.July, 1), OldAmount: 40, NewAmount: 40.20}}, gymSeries.Changes)
+
+		assert.Equal(t, "Streamer", streamerSeries.Merchant)
+		assert.Equal(t, "Subscriptions", streamerSeries.Category)
+		assert.Equal(t, 6, streamerSeries.Charges)
+		assert.Equal(t, monthDay(time.February, 3), streamerSeries.First)
+		assert.Equal(t, monthDay(time.July, 3), streamerSeries.Last)
+		require.Len(t, streamerSeries.Changes, 1)
+		assert.Equal(t, monthDay(time.May, 3), streamerSeries.Changes[0].Date)
+		assert.Equal(t, 15.49, streamerSeries.Changes[0].OldAmount)
+		assert.Equal(t, 17.99, streamerSeries.Changes[0].NewAmount)
+		assert.InDelta(t, 16.14, streamerSeries.Changes[0].Percent(), 0.01)
+	})
+
+	t.Run("within tolerance is the same price", func(t *testing.T) {
+		series := BuildRecurring(classifications, categories, RecurringOptions{End: end, LookbackMonths: 6, Tolerance: 0.01})
+		require.Len(t, series, 2)
+		assert.Equal(t, "Gym", series[0].Merchant)
+		assert.Empty(t, series[0].Changes)
+		assert.Len(t, series[1].Changes, 1)
+	})
+}
+
+func TestRecurringDetectorSharedWithForecast(t *testing.T) {
+	monthDay := func(m time.Month, day int) time.Time {
+		return time.Date(2024, m, day, 0, 0, 0, 0, time.UTC)
+	}
+	categories := []model.Category{{Name: "Subscriptions", Type: model.CategoryTypeExpense}}
+
+	var classifications []model.Classification
+	prices := []float64{15.49, 15.49, 15.49, 15.49, 17.99, 17.99}
+	for i, m := 0, time.January; m <= time.June; i, m = i+1, m+1 {
+		classifications = append(classifications,
+			forecastClassification("Subscriptions", "Streamer", monthDay(m, 3), prices[i], model.DirectionExpense))
+	}
+	// A refund in a month that was already charged doesn't break the series
+	refund := forecastClassification("Subscriptions", "Streamer", monthDay(time.March, 10), 15.49, model.DirectionExpense)
+	refund.Transaction.IsRefund = true
+	classifications = append(classifications, refund)
+
+	series := BuildRecurring(classifications, categories, RecurringOptions{End: monthDay(time.June, 30), LookbackMonths: 6})
+	require.Len(t, series, 1)
+	assert.Equal(t, 17.99, series[0].Amount)
+	assert.Equal(t, 6, series[0].Charges)
+	require.Len(t, series[0].Changes, 1)
+
+	forecast := BuildForecast(classifications, categories, monthDay(time.July, 1), 6)
+	require.Len(t, forecast.Categories, 1)
+	assert.Equal(t, 1, forecast.Categories[0].RecurringCount)
+	assert.InDelta(t, series[0].Amount, forecast.Categories[0].Recurring, 0.001)
+	assert.Zero(t, forecast.Categories[0].Variable)
+}
@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+)
+
+// carryOverRefunds classifies incoming transactions that refund an already
+// classified purchase with that purchase's category, so they never reach the LLM.
+// It returns the transactions still needing classification and the number of
+// refunds matched. A zero window disables refund detection.
+func (e *ClassificationEngine) carryOverRefunds(ctx context.Context, transactions []model.Transaction, window time.Duration, dryRun bool) ([]model.Transaction, int) {
+	if window <= 0 {
+		return transactions, 0
+	}
+
+	remaining := make([]model.Transaction, 0, len(transactions))
+	matched := 0
+
+	for _, txn := range transactions {
+		if txn.Direction != model.DirectionIncome {
+			remaining = append(remaining, txn)
+			continue
+		}
+
+		purchase, err := e.storage.FindRefundedPurchase(ctx, txn, window)
+		if err != nil {
+			if !errors.Is(err, storage.ErrRefundMatchNotFound) {
+				slog.Warn("Failed to look up refunded purchase",
+					"transaction_id", txn.ID,
+					"error", err)
+			}
+			remaining = append(remaining, txn)
+			continue
+		}
+
+		if !dryRun {
+			if err := e.saveRefund(ctx, txn, purchase); err != nil {
+				slog.Warn("Failed to save refund classification",
+					"transaction_id", txn.ID,
+					"error", err)
+				remaining = append(remaining, txn)
+				continue
+			}
+		}
+
+		slog.Debug("Refund matched to purchase",
+			"transaction_id", txn.ID,
+			"purchase_id", purchase.Transaction.ID,
+			"category", purchase.Category)
+		matched++
+	}
+
+	if matched > 0 {
+		slog.Info("Carried purchase categories over to refunds", "refunds", matched)
+	}
+
+	return remaining, matched
+}
+
+// saveRefund records a refund under the category of the purchase it reverses.
+func (e *ClassificationEngine) saveRefund(ctx context.Context, refund model.Transaction, purchase *model.Classification) error {
+	refund.IsRefund = true
+	refund.RefundCategory = purchase.Category
+
+	classification := model.Classification{
+		Transaction:     refund,
+		Category:        purchase.Category,
+		Status:          model.StatusClassifiedByRule,
+		Confidence:      1.0,
+		BusinessPercent: purchase.BusinessPercent,
+		Notes:           fmt.Sprintf("Refund of purchase on %s", purchase.Transaction.Date.Format("2006-01-02")),
+		ClassifiedAt:    time.Now(),
+	}
+
+	if err := e.storage.SaveClassification(ctx, &classification); err != nil {
+		return err
+	}
+
+	return e.storage.MarkTransactionRefund(ctx, refund.ID, purchase.Category)
+}
+
+// refundWindow converts the configured refund window into a duration.
+func refundWindow(opts BatchClassificationOptions) time.Duration {
+	if opts.RefundWindowDays <= 0 {
+		return 0
+	}
+	return time.Duration(opts.RefundWindowDays) * 24 * time.Hour
+}
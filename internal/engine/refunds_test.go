@@ -0,0 +1,120 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyTransactionsBatch_RefundInheritsPurchaseCategory(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+
+	for _, name := range []string{"Electronics", "Salary"} {
+		_, createErr := db.CreateCategoryWithType(ctx, name, name, model.CategoryTypeExpense)
+		require.NoError(t, createErr)
+	}
+
+	purchaseDate := time.Now().AddDate(0, 0, -10)
+	purchase := model.Transaction{
+		ID: "purchase", Hash: "hash-purchase", Name: "BEST BUY", MerchantName: "Best Buy",
+		Amount: 199.99, Date: purchaseDate, AccountID: "acc1", Direction: model.DirectionExpense,
+	}
+	refund := model.Transaction{
+		ID: "refund", Hash: "hash-refund", Name: "BEST BUY RETURN", MerchantName: "Best Buy",
+		Amount: 199.99, Date: time.Now(), AccountID: "acc1", Direction: model.DirectionIncome,
+	}
+	payroll := model.Transaction{
+		ID: "payroll", Hash: "hash-payroll", Name: "ACME PAYROLL", MerchantName: "Acme",
+		Amount: 2500, Date: time.Now(), AccountID: "acc1", Direction: model.DirectionIncome,
+	}
+	require.NoError(t, db.SaveTransactions(ctx, []model.Transaction{purchase, refund, payroll}))
+	require.NoError(t, db.SaveClassification(ctx, &model.Classification{
+		Transaction: purchase, Category: "Electronics", Status: model.StatusUserModified, Confidence: 1, ClassifiedAt: time.Now(),
+	}))
+
+	classifier := NewMockClassifier()
+	engine := &ClassificationEngine{
+		storage:    db,
+		classifier: classifier,
+		prompter:   NewMockPrompter(true),
+	}
+
+	opts := BatchClassificationOptions{
+		AutoAcceptThreshold: 0.80,
+		BatchSize:           5,
+		ParallelWorkers:     1,
+		SkipManualReview:    true,
+		RefundWindowDays:    30,
+	}
+
+	summary, err := engine.ClassifyTransactionsBatch(ctx, nil, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.RefundCount)
+	assert.Equal(t, 1, summary.TotalTransactions, "only the payroll deposit should reach the LLM")
+
+	for _, call := range classifier.GetCalls() {
+		assert.NotEqual(t, "refund", call.Transaction.ID)
+	}
+
+	classifications, err := db.GetClassificationsByDateRange(ctx, purchaseDate.Add(-time.Hour), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	var found bool
+	for _, c := range classifications {
+		if c.Transaction.ID != "refund" {
+			continue
+		}
+		found = true
+		assert.Equal(t, "Electronics", c.Category)
+		assert.Equal(t, model.StatusClassifiedByRule, c.Status)
+		assert.True(t, c.Transaction.IsRefund)
+		assert.Equal(t, "Electronics", c.Transaction.RefundCategory)
+	}
+	assert.True(t, found, "refund should have been classified")
+}
+
+func TestCarryOverRefunds_DisabledOrDryRun(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+	_, err = db.CreateCategoryWithType(ctx, "Electronics", "Electronics", model.CategoryTypeExpense)
+	require.NoError(t, err)
+
+	purchase := model.Transaction{
+		ID: "purchase", Hash: "hash-purchase", Name: "BEST BUY", MerchantName: "Best Buy",
+		Amount: 50, Date: time.Now().AddDate(0, 0, -2), AccountID: "acc1", Direction: model.DirectionExpense,
+	}
+	refund := model.Transaction{
+		ID: "refund", Hash: "hash-refund", Name: "BEST BUY RETURN", MerchantName: "Best Buy",
+		Amount: 50, Date: time.Now(), AccountID: "acc1", Direction: model.DirectionIncome,
+	}
+	require.NoError(t, db.SaveTransactions(ctx, []model.Transaction{purchase, refund}))
+	require.NoError(t, db.SaveClassification(ctx, &model.Classification{
+		Transaction: purchase, Category: "Electronics", Status: model.StatusUserModified, Confidence: 1, ClassifiedAt: time.Now(),
+	}))
+
+	engine := &ClassificationEngine{storage: db}
+
+	remaining, matched := engine.carryOverRefunds(ctx, []model.Transaction{refund}, 0, false)
+	assert.Len(t, remaining, 1)
+	assert.Zero(t, matched)
+
+	remaining, matched = engine.carryOverRefunds(ctx, []model.Transaction{refund}, 7*24*time.Hour, true)
+	assert.Empty(t, remaining)
+	assert.Equal(t, 1, matched)
+
+	toClassify, err := db.GetTransactionsToClassify(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, toClassify, 1, "dry run must not save the refund")
+	assert.Equal(t, "refund", toClassify[0].ID)
+}
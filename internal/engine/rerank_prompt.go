@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+// RerankPrompt selects the prompt low-confidence merchants are re-ranked with.
+type RerankPrompt string
+
+// Rerank prompts.
+const (
+	// RerankPromptReconsider shows the LLM its previous guess and confidence and
+	// asks it to reconsider.
+	RerankPromptReconsider RerankPrompt = "reconsider"
+	// RerankPromptStandard re-runs the regular classification prompt.
+	RerankPromptStandard RerankPrompt = "standard"
+	// RerankPromptCompare re-ranks alternate merchants with each prompt and
+	// reports the improvement rate of both.
+	RerankPromptCompare RerankPrompt = "compare"
+)
+
+// ParseRerankPrompt validates a rerank prompt name. An empty name means RerankPromptReconsider.
+func ParseRerankPrompt(name string) (RerankPrompt, error) {
+	switch RerankPrompt(name) {
+	case "", RerankPromptReconsider:
+		return RerankPromptReconsider, nil
+	case RerankPromptStandard, RerankPromptCompare:
+		return RerankPrompt(name), nil
+	default:
+		return "", fmt.Errorf("invalid rerank prompt %q (use %s, %s or %s)", name, RerankPromptReconsider, RerankPromptStandard, RerankPromptCompare)
+	}
+}
+
+// RerankPromptStats counts the transactions re-ranked with one prompt.
+type RerankPromptStats struct {
+	Prompt    RerankPrompt
+	Evaluated int // Transactions re-ranked with the prompt
+	Improved  int // Transactions whose confidence improved
+}
+
+// ImprovedRate is the share of evaluated transactions that improved.
+func (s RerankPromptStats) ImprovedRate() float64 {
+	if s.Evaluated == 0 {
+		return 0
+	}
+	return float64(s.Improved) / float64(s.Evaluated)
+}
+
+// rerankArm is the set of merchants re-ranked with one prompt.
+type rerankArm struct {
+	prompt    RerankPrompt
+	merchants []string
+}
+
+// rerankArms splits merchants by the prompt they are re-ranked with. Compare
+// alternates them in volume order, so both prompts see a similar mix.
+func rerankArms(sortedMerchants []string, prompt RerankPrompt) []rerankArm {
+	if prompt != RerankPromptCompare {
+		return []rerankArm{{prompt: prompt, merchants: sortedMerchants}}
+	}
+
+	reconsider := rerankArm{prompt: RerankPromptReconsider}
+	standard := rerankArm{prompt: RerankPromptStandard}
+	for i, merchant := range sortedMerchants {
+		if i%2 == 0 {
+			reconsider.merchants = append(reconsider.merchants, merchant)
+		} else {
+			standard.merchants = append(standard.merchants, merchant)
+		}
+	}
+	return []rerankArm{reconsider, standard}
+}
+
+// previousGuesses returns the earlier classification of each merchant group:
+// the category most of its transactions have, scored with the highest
+// confidence among them.
+func previousGuesses(merchantGroups map[string][]model.Transaction, previous map[string]model.Classification) map[string]model.CategoryRanking {
+	guesses := make(map[string]model.CategoryRanking, len(merchantGroups))
+	for merchant, txns := range merchantGroups {
+		counts := make(map[string]int)
+		confidence := make(map[string]float64)
+		var best string
+		for _, txn := range txns {
+			classification, ok := previous[txn.ID]
+			if !ok || classification.Category == "" {
+				continue
+			}
+			category := classification.Category
+			counts[category]++
+			confidence[category] = max(confidence[category], classification.Confidence)
+			if best == "" || counts[category] > counts[best] {
+				best = category
+			}
+		}
+		if best != "" {
+			guesses[merchant] = model.CategoryRanking{Category: best, Score: confidence[best]}
+		}
+	}
+	return guesses
+}
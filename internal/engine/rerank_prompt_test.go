@@ -0,0 +1,159 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/llm"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reconsideringClassifier is only more confident when shown a previous guess,
+// and records the requests it receives.
+type reconsideringClassifier struct {
+	*MockClassifier
+	requests []llm.MerchantBatchRequest
+	mu       sync.Mutex
+}
+
+func (c *reconsideringClassifier) SuggestCategoryBatch(_ context.Context, requests []llm.MerchantBatchRequest, _ []model.Category) (map[string]model.CategoryRankings, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	results := make(map[string]model.CategoryRankings, len(requests))
+	for _, req := range requests {
+		c.requests = append(c.requests, req)
+		score := 0.4
+		if req.PreviousCategory != "" {
+			score = 0.8
+		}
+		results[req.MerchantID] = model.CategoryRankings{{Category: "Food", Score: score}}
+	}
+	return results, nil
+}
+
+func TestRerankLowConfidenceTransactions_Prompt(t *testing.T) {
+	setup := func(t *testing.T) *storage.SQLiteStorage {
+		t.Helper()
+		ctx := context.Background()
+		db, err := storage.NewSQLiteStorage(":memory:")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+		require.NoError(t, db.Migrate(ctx))
+
+		_, err = db.CreateCategory(ctx, "Food", "Dining and groceries")
+		require.NoError(t, err)
+
+		for i, merchant := range []string{"Bistro", "Cafe", "Diner", "Eatery"} {
+			txn := model.Transaction{
+				ID: fmt.Sprintf("txn-%d", i), Hash: fmt.Sprintf("hash-%d", i), Date: time.Now(),
+				Name: merchant, MerchantName: merchant, Amount: 20, AccountID: "acc",
+				Direction: model.DirectionExpense,
+			}
+			require.NoError(t, db.SaveTransactions(ctx, []model.Transaction{txn}))
+			require.NoError(t, db.SaveClassification(ctx, &model.Classification{
+				Transaction: txn, Category: "Food", Status: model.StatusClassifiedByAI, Confidence: 0.5,
+			}))
+		}
+		return db
+	}
+
+	tests := []struct {
+		name            string
+		prompt          RerankPrompt
+		wantImproved    int
+		wantPreviousFor int
+		wantStats       []RerankPromptStats
+	}{
+		{
+			name:            "reconsider by default",
+			wantImproved:    4,
+			wantPreviousFor: 4,
+			wantStats:       []RerankPromptStats{{Prompt: RerankPromptReconsider, Evaluated: 4, Improved: 4}},
+		},
+		{
+			name:      "standard",
+			prompt:    RerankPromptStandard,
+			wantStats: []RerankPromptStats{{Prompt: RerankPromptStandard, Evaluated: 4}},
+		},
+		{
+			name:            "compare",
+			prompt:          RerankPromptCompare,
+			wantImproved:    2,
+			wantPreviousFor: 2,
+			wantStats: []RerankPromptStats{
+				{Prompt: RerankPromptReconsider, Evaluated: 2, Improved: 2},
+				{Prompt: RerankPromptStandard, Evaluated: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setup(t)
+			classifier := &reconsideringClassifier{MockClassifier: NewMockClassifier()}
+			engine := New(db, classifier, NewMockPrompter(true))
+
+			summary, err := engine.RerankLowConfidenceTransactions(context.Background(), RerankOptions{
+				ConfidenceThreshold: 0.6,
+				AutoAcceptThreshold: 0.95,
+				BatchSize:           2,
+				ParallelWorkers:     1,
+				SkipManualReview:    true,
+				Prompt:              tt.prompt,
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, 4, summary.TotalEvaluated)
+			assert.Equal(t, tt.wantImproved, summary.ImprovedCount)
+			assert.Equal(t, tt.wantStats, summary.PromptStats)
+
+			var withPrevious int
+			for _, req := range classifier.requests {
+				if req.PreviousCategory != "" {
+					withPrevious++
+					assert.Equal(t, "Food", req.PreviousCategory)
+					assert.InDelta(t, 0.5, req.PreviousConfidence, 0.001)
+				}
+			}
+			assert.Len(t, classifier.requests, 4)
+			assert.Equal(t, tt.wantPreviousFor, withPrevious)
+		})
+	}
+}
+
+func TestPreviousGuesses(t *testing.T) {
+	groups := map[string][]model.Transaction{
+		"Shop":  {{ID: "1"}, {ID: "2"}, {ID: "3"}},
+		"Other": {{ID: "4"}},
+	}
+	previous := map[string]model.Classification{
+		"1": {Category: "Groceries", Confidence: 0.4},
+		"2": {Category: "Shopping", Confidence: 0.6},
+		"3": {Category: "Groceries", Confidence: 0.5},
+	}
+
+	guesses := previousGuesses(groups, previous)
+	assert.Equal(t, map[string]model.CategoryRanking{
+		"Shop": {Category: "Groceries", Score: 0.5},
+	}, guesses)
+}
+
+func TestParseRerankPrompt(t *testing.T) {
+	prompt, err := ParseRerankPrompt("")
+	require.NoError(t, err)
+	assert.Equal(t, RerankPromptReconsider, prompt)
+
+	prompt, err = ParseRerankPrompt("compare")
+	require.NoError(t, err)
+	assert.Equal(t, RerankPromptCompare, prompt)
+
+	_, err = ParseRerankPrompt("louder")
+	require.Error(t, err)
+}
@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// savedCountPrompter records how many classifications were saved each time a
+// merchant is shown, and interrupts the review at cancelAt (1-based).
+type savedCountPrompter struct {
+	*MockPrompter
+	db       *storage.SQLiteStorage
+	t        *testing.T
+	saved    []int
+	cancelAt int
+}
+
+func (p *savedCountPrompter) BatchConfirmClassifications(ctx context.Context, pending []model.PendingClassification) ([]model.Classification, error) {
+	p.saved = append(p.saved, len(savedClassifications(p.t, p.db)))
+	if len(p.saved) == p.cancelAt {
+		return nil, context.Canceled
+	}
+	return p.MockPrompter.BatchConfirmClassifications(ctx, pending)
+}
+
+func TestHandleBatchReview_CommitEvery(t *testing.T) {
+	setup := func(t *testing.T) (*storage.SQLiteStorage, []model.Category, []BatchResult) {
+		t.Helper()
+		ctx := context.Background()
+		db, err := storage.NewSQLiteStorage(":memory:")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+		require.NoError(t, db.Migrate(ctx))
+
+		_, err = db.CreateCategory(ctx, "Food", "Dining and groceries")
+		require.NoError(t, err)
+		categories, err := db.GetCategories(ctx)
+		require.NoError(t, err)
+
+		// Three merchants of two transactions, reviewed lowest confidence first
+		var results []BatchResult
+		for i, merchant := range []string{"Bistro", "Cafe", "Diner"} {
+			var transactions []model.Transaction
+			for j := 0; j < 2; j++ {
+				transactions = append(transactions, model.Transaction{
+					ID: fmt.Sprintf("%s-%d", merchant, j), Hash: fmt.Sprintf("hash-%s-%d", merchant, j),
+					Date: time.Now(), Name: merchant, MerchantName: merchant, Amount: 20,
+					AccountID: "acc", Direction: model.DirectionExpense,
+				})
+			}
+			require.NoError(t, db.SaveTransactions(ctx, transactions))
+			results = append(results, BatchResult{
+				Merchant:     merchant,
+				Transactions: transactions,
+				Suggestion:   &model.CategoryRanking{Category: "Food", Score: 0.5 + float64(i)/10},
+			})
+		}
+		return db, categories, results
+	}
+
+	tests := []struct {
+		name        string
+		commitEvery int
+		cancelAt    int
+		wantSeen    []int // Classifications saved when each merchant is shown
+		wantSaved   int
+	}{
+		{name: "every merchant by default", commitEvery: 0, wantSeen: []int{0, 2, 4}, wantSaved: 6},
+		{name: "every three transactions", commitEvery: 3, wantSeen: []int{0, 0, 4}, wantSaved: 6},
+		{name: "pending merchants are committed on interrupt", commitEvery: 100, cancelAt: 3, wantSeen: []int{0, 0, 0}, wantSaved: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, categories, results := setup(t)
+			prompter := &savedCountPrompter{MockPrompter: NewMockPrompter(true), db: db, t: t, cancelAt: tt.cancelAt}
+			engine := &ClassificationEngine{storage: db, classifier: NewMockClassifier(), prompter: prompter}
+
+			err := engine.handleBatchReview(context.Background(), results, categories, 0, tt.commitEvery, false)
+			if tt.cancelAt > 0 {
+				require.ErrorIs(t, err, context.Canceled)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantSeen, prompter.saved)
+			assert.Len(t, savedClassifications(t, db), tt.wantSaved)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+// RuleConfirmation is the LLM's verdict on a rule match when rules are confirmed.
+type RuleConfirmation string
+
+const (
+	// RuleAgreed means the LLM's top category matched the rule's.
+	RuleAgreed RuleConfirmation = "agreed"
+	// RuleDisagreed means the LLM preferred a different category.
+	RuleDisagreed RuleConfirmation = "disagreed"
+	// RuleUnconfirmed means the LLM gave no usable answer for the merchant.
+	RuleUnconfirmed RuleConfirmation = "unconfirmed"
+)
+
+// trusted reports whether a result may be auto-accepted as far as rule
+// confirmation is concerned. Results that weren't confirmed at all are.
+func (c RuleConfirmation) trusted() bool {
+	return c == "" || c == RuleAgreed
+}
+
+// confirmRule compares the LLM's rankings for a rule-matched merchant with the
+// rule's category. The rule's suggestion is kept either way; a disagreement
+// only sends it to review.
+func (e *ClassificationEngine) confirmRule(result *BatchResult, rankings model.CategoryRankings, categories []model.Category) {
+	ruleCategory := result.Suggestion.Category
+
+	top, _ := e.directionSafeSuggestion(result.Merchant, rankings, categories, result.Transactions)
+	switch {
+	case top == nil:
+		result.RuleConfirmation = RuleUnconfirmed
+		slog.Warn("LLM gave no answer to confirm rule, sending to review",
+			"merchant", result.Merchant,
+			"rule_category", ruleCategory)
+	case strings.EqualFold(top.Category, ruleCategory):
+		result.RuleConfirmation = RuleAgreed
+		slog.Info("LLM agreed with rule",
+			"merchant", result.Merchant,
+			"category", ruleCategory,
+			"llm_confidence", top.Score)
+	default:
+		result.RuleConfirmation = RuleDisagreed
+		result.AutoAccepted = false
+		slog.Warn("LLM disagreed with rule, sending to review",
+			"merchant", result.Merchant,
+			"rule_category", ruleCategory,
+			"llm_category", top.Category,
+			"llm_confidence", top.Score)
+	}
+}
+
+// countRuleConfirmations returns how many rule matches the LLM agreed with, and
+// how many it disagreed with or couldn't confirm.
+func countRuleConfirmations(results []BatchResult) (agreed, disagreed int) {
+	for _, result := range results {
+		switch result.RuleConfirmation {
+		case RuleAgreed:
+			agreed++
+		case RuleDisagreed, RuleUnconfirmed:
+			disagreed++
+		}
+	}
+	return agreed, disagreed
+}
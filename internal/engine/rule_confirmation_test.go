@@ -0,0 +1,141 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessMerchantBatch_ConfirmRules(t *testing.T) {
+	ctx := context.Background()
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+
+	categories := []model.Category{
+		{Name: "Groceries", Type: model.CategoryTypeExpense},
+		{Name: "Department Stores", Type: model.CategoryTypeExpense},
+		{Name: "Gas Stations", Type: model.CategoryTypeExpense},
+	}
+	for _, cat := range categories {
+		_, err := db.CreateCategoryWithType(ctx, cat.Name, "", cat.Type)
+		require.NoError(t, err)
+	}
+	require.NoError(t, db.SaveVendor(ctx, &model.Vendor{Name: "Walmart", Category: "Groceries"}))
+	require.NoError(t, db.SaveVendor(ctx, &model.Vendor{Name: "Target", Category: "Groceries"}))
+	require.NoError(t, db.SaveVendor(ctx, &model.Vendor{Name: "Costco", Category: "Groceries"}))
+
+	merchants := []string{"Walmart", "Target", "Costco", "Shell"}
+	merchantGroups := map[string][]model.Transaction{
+		"Walmart": {{ID: "tx1", MerchantName: "Walmart", Amount: 50, Type: "DEBIT"}},
+		"Target":  {{ID: "tx2", MerchantName: "Target", Amount: 120, Type: "DEBIT"}},
+		"Costco":  {{ID: "tx3", MerchantName: "Costco", Amount: 200, Type: "DEBIT"}},
+		"Shell":   {{ID: "tx4", MerchantName: "Shell", Amount: 40, Type: "DEBIT"}},
+	}
+	response := map[string]model.CategoryRankings{
+		"Walmart": {{Category: "Groceries", Score: 0.8}, {Category: "Department Stores", Score: 0.3}},
+		"Target":  {{Category: "Department Stores", Score: 0.9}, {Category: "Groceries", Score: 0.4}},
+		"Shell":   {{Category: "Gas Stations", Score: 0.97}},
+		// No answer for Costco
+	}
+	opts := BatchClassificationOptions{BatchSize: 10, AutoAcceptThreshold: 0.95}
+
+	t.Run("rules are trusted by default", func(t *testing.T) {
+		classifier := NewMockClassifier()
+		classifier.SetBatchResponse(response)
+		engine := &ClassificationEngine{storage: db, classifier: classifier}
+
+		results := engine.processMerchantBatch(ctx, merchants, merchantGroups, categories, opts)
+
+		assert.Equal(t, 1, classifier.CallCount(), "only Shell goes to the LLM")
+		for _, result := range results {
+			assert.Empty(t, result.RuleConfirmation)
+			assert.True(t, autoAcceptable(result, opts), result.Merchant)
+		}
+	})
+
+	t.Run("rules are auto-accepted only when the LLM agrees", func(t *testing.T) {
+		classifier := NewMockClassifier()
+		classifier.SetBatchResponse(response)
+		engine := &ClassificationEngine{storage: db, classifier: classifier}
+		confirmOpts := opts
+		confirmOpts.ConfirmRules = true
+
+		results := engine.processMerchantBatch(ctx, merchants, merchantGroups, categories, confirmOpts)
+		require.Len(t, results, 4)
+		assert.Equal(t, 4, classifier.CallCount())
+
+		byMerchant := make(map[string]BatchResult, len(results))
+		for _, result := range results {
+			require.NoError(t, result.Error, result.Merchant)
+			byMerchant[result.Merchant] = result
+		}
+
+		assert.Equal(t, RuleAgreed, byMerchant["Walmart"].RuleConfirmation)
+		assert.True(t, autoAcceptable(byMerchant["Walmart"], confirmOpts))
+
+		// The rule's category is still suggested, but reviewed
+		assert.Equal(t, RuleDisagreed, byMerchant["Target"].RuleConfirmation)
+		assert.Equal(t, "Groceries", byMerchant["Target"].Suggestion.Category)
+		assert.False(t, autoAcceptable(byMerchant["Target"], confirmOpts))
+
+		assert.Equal(t, RuleUnconfirmed, byMerchant["Costco"].RuleConfirmation)
+		assert.False(t, autoAcceptable(byMerchant["Costco"], confirmOpts))
+
+		// Merchants without a rule are unaffected
+		assert.Empty(t, byMerchant["Shell"].RuleConfirmation)
+		assert.True(t, autoAcceptable(byMerchant["Shell"], confirmOpts))
+
+		agreed, disagreed := countRuleConfirmations(results)
+		assert.Equal(t, 1, agreed)
+		assert.Equal(t, 2, disagreed)
+	})
+}
+
+func TestClassifyTransactionsBatch_ConfirmRulesWithoutReview(t *testing.T) {
+	ctx := context.Background()
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+	defer func() { _ = db.Close() }()
+
+	for _, name := range []string{"Groceries", "Department Stores"} {
+		_, err := db.CreateCategoryWithType(ctx, name, "", model.CategoryTypeExpense)
+		require.NoError(t, err)
+	}
+	require.NoError(t, db.SaveVendor(ctx, &model.Vendor{Name: "Walmart", Category: "Groceries", LastUpdated: time.Now()}))
+	require.NoError(t, db.SaveVendor(ctx, &model.Vendor{Name: "Target", Category: "Groceries", LastUpdated: time.Now()}))
+
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, db.SaveTransactions(ctx, []model.Transaction{
+		{ID: "walmart", Hash: "hash-walmart", Name: "WALMART", MerchantName: "Walmart", Amount: 50, Type: "DEBIT", Direction: model.DirectionExpense, Date: date, AccountID: "acc1"},
+		{ID: "target", Hash: "hash-target", Name: "TARGET", MerchantName: "Target", Amount: 120, Type: "DEBIT", Direction: model.DirectionExpense, Date: date, AccountID: "acc1"},
+	}))
+
+	classifier := NewMockClassifier()
+	classifier.SetBatchResponse(map[string]model.CategoryRankings{
+		"Walmart": {{Category: "Groceries", Score: 0.8}},
+		"Target":  {{Category: "Department Stores", Score: 0.9}},
+	})
+	engine := &ClassificationEngine{storage: db, classifier: classifier, prompter: NewMockPrompter(true)}
+
+	_, err = engine.ClassifyTransactionsBatch(ctx, nil, BatchClassificationOptions{
+		AutoAcceptThreshold: 0.95,
+		BatchSize:           5,
+		ParallelWorkers:     1,
+		SkipManualReview:    true,
+		ConfirmRules:        true,
+	})
+	require.NoError(t, err)
+
+	// The rule the LLM disagreed with waits for a review
+	unclassified, err := db.GetTransactionsToClassify(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, unclassified, 1)
+	assert.Equal(t, "target", unclassified[0].ID)
+}
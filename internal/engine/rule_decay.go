@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// DecayCurve controls how a stale vendor rule's confidence falls with age.
+type DecayCurve string
+
+// Vendor rule decay curves.
+const (
+	// DecayNone keeps every vendor rule at full confidence.
+	DecayNone DecayCurve = "none"
+	// DecayStep drops a stale rule straight to the floor.
+	DecayStep DecayCurve = "step"
+	// DecayLinear lowers a stale rule's confidence evenly until it reaches the
+	// floor one span after going stale.
+	DecayLinear DecayCurve = "linear"
+	// DecayExponential halves the gap between a stale rule's confidence and
+	// the floor every span.
+	DecayExponential DecayCurve = "exponential"
+)
+
+// Defaults for RuleDecay.
+const (
+	DefaultRuleDecayAfter = 365 * 24 * time.Hour
+	DefaultRuleDecaySpan  = 365 * 24 * time.Hour
+	DefaultRuleDecayFloor = 0.5
+)
+
+// ParseDecayCurve validates a decay curve name. An empty name means DecayNone.
+func ParseDecayCurve(name string) (DecayCurve, error) {
+	switch DecayCurve(name) {
+	case "", DecayNone:
+		return DecayNone, nil
+	case DecayStep, DecayLinear, DecayExponential:
+		return DecayCurve(name), nil
+	default:
+		return "", fmt.Errorf("invalid vendor rule decay %q (use %s, %s, %s or %s)",
+			name, DecayNone, DecayStep, DecayLinear, DecayExponential)
+	}
+}
+
+// RuleDecay lowers the confidence of vendor rules that haven't been updated
+// in a while, so they are suggested for review instead of auto-accepted.
+type RuleDecay struct {
+	Curve DecayCurve    // Empty means DecayNone
+	After time.Duration // Age since the rule was last updated at which it goes stale
+	Span  time.Duration // Linear: time to reach Floor once stale; exponential: half-life
+	Floor float64       // Lowest confidence a stale rule decays to
+}
+
+// confidence returns the confidence of a vendor rule last updated at
+// lastUpdated. Rules with an unknown update time don't decay.
+func (d RuleDecay) confidence(lastUpdated, now time.Time) float64 {
+	if d.Curve == "" || d.Curve == DecayNone || lastUpdated.IsZero() {
+		return 1.0
+	}
+	stale := now.Sub(lastUpdated) - d.After
+	if stale <= 0 {
+		return 1.0
+	}
+
+	// Fraction of the way from full confidence down to the floor
+	var decayed float64
+	switch {
+	case d.Curve == DecayStep || d.Span <= 0:
+		decayed = 1
+	case d.Curve == DecayLinear:
+		decayed = math.Min(float64(stale)/float64(d.Span), 1)
+	case d.Curve == DecayExponential:
+		decayed = 1 - math.Pow(0.5, float64(stale)/float64(d.Span))
+	}
+	return 1.0 - (1.0-d.Floor)*decayed
+}
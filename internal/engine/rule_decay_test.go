@@ -0,0 +1,141 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleDecay_Confidence(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	year := 365 * 24 * time.Hour
+	decay := RuleDecay{After: year, Span: year, Floor: 0.5}
+
+	tests := []struct {
+		name     string
+		curve    DecayCurve
+		age      time.Duration
+		expected float64
+	}{
+		{"no decay by default", "", 5 * year, 1.0},
+		{"none", DecayNone, 5 * year, 1.0},
+		{"fresh rule", DecayStep, year / 2, 1.0},
+		{"step drops to the floor", DecayStep, year + 24*time.Hour, 0.5},
+		{"linear halfway", DecayLinear, year + year/2, 0.75},
+		{"linear stops at the floor", DecayLinear, 4 * year, 0.5},
+		{"exponential after one half-life", DecayExponential, 2 * year, 0.75},
+		{"exponential after two half-lives", DecayExponential, 3 * year, 0.625},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := decay
+			d.Curve = tt.curve
+			assert.InDelta(t, tt.expected, d.confidence(now.Add(-tt.age), now), 0.0001)
+		})
+	}
+
+	t.Run("unknown update time doesn't decay", func(t *testing.T) {
+		d := decay
+		d.Curve = DecayStep
+		assert.InDelta(t, 1.0, d.confidence(time.Time{}, now), 0.0001)
+	})
+}
+
+func TestParseDecayCurve(t *testing.T) {
+	curve, err := ParseDecayCurve("")
+	require.NoError(t, err)
+	assert.Equal(t, DecayNone, curve)
+
+	curve, err = ParseDecayCurve("exponential")
+	require.NoError(t, err)
+	assert.Equal(t, DecayExponential, curve)
+
+	_, err = ParseDecayCurve("cliff")
+	assert.ErrorContains(t, err, "invalid vendor rule decay")
+}
+
+func TestApplyRules_StaleVendorRule(t *testing.T) {
+	ctx := context.Background()
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+	defer func() { _ = db.Close() }()
+
+	_, err = db.CreateCategoryWithType(ctx, "Groceries", "", model.CategoryTypeExpense)
+	require.NoError(t, err)
+	require.NoError(t, db.SaveVendor(ctx, &model.Vendor{
+		Name: "Walmart", Category: "Groceries", LastUpdated: time.Now().AddDate(-2, 0, 0),
+	}))
+	require.NoError(t, db.SaveVendor(ctx, &model.Vendor{Name: "Costco", Category: "Groceries"}))
+
+	engine := &ClassificationEngine{storage: db}
+	opts := BatchClassificationOptions{
+		AutoAcceptThreshold: 0.95,
+		VendorRuleDecay:     RuleDecay{Curve: DecayStep, After: 365 * 24 * time.Hour, Floor: 0.6},
+	}
+
+	stale := BatchResult{Merchant: "Walmart", Transactions: []model.Transaction{{ID: "t1", MerchantName: "Walmart"}}}
+	require.True(t, engine.applyRules(ctx, &stale, opts))
+	assert.Equal(t, "Groceries", stale.Suggestion.Category)
+	assert.InDelta(t, 0.6, stale.Suggestion.Score, 0.0001)
+	assert.False(t, stale.AutoAccepted)
+	assert.False(t, autoAcceptable(stale, opts), "stale rules go to review")
+
+	fresh := BatchResult{Merchant: "Costco", Transactions: []model.Transaction{{ID: "t2", MerchantName: "Costco"}}}
+	require.True(t, engine.applyRules(ctx, &fresh, opts))
+	assert.InDelta(t, 1.0, fresh.Suggestion.Score, 0.0001)
+	assert.True(t, fresh.AutoAccepted)
+
+	// Without decay the old rule is trusted as before
+	stale = BatchResult{Merchant: "Walmart", Transactions: stale.Transactions}
+	require.True(t, engine.applyRules(ctx, &stale, BatchClassificationOptions{AutoAcceptThreshold: 0.95}))
+	assert.InDelta(t, 1.0, stale.Suggestion.Score, 0.0001)
+	assert.True(t, stale.AutoAccepted)
+}
+
+func TestSaveAutoAcceptedBatch_DecayedVendorRule(t *testing.T) {
+	ctx := context.Background()
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+	defer func() { _ = db.Close() }()
+
+	_, err = db.CreateCategoryWithType(ctx, "Groceries", "", model.CategoryTypeExpense)
+	require.NoError(t, err)
+	lastUpdated := time.Now().AddDate(-2, 0, 0).Truncate(time.Second)
+	require.NoError(t, db.SaveVendor(ctx, &model.Vendor{
+		Name: "Walmart", Category: "Groceries", Source: model.SourceManual, UseCount: 40, LastUpdated: lastUpdated,
+	}))
+	txn := model.Transaction{
+		ID: "t1", Hash: "h1", Date: time.Now(), Name: "Walmart", MerchantName: "Walmart",
+		Amount: 50, AccountID: "acc", Direction: model.DirectionExpense,
+	}
+	require.NoError(t, db.SaveTransactions(ctx, []model.Transaction{txn}))
+
+	// The stale rule decays to 0.9, still above the threshold
+	engine := &ClassificationEngine{storage: db}
+	opts := BatchClassificationOptions{
+		AutoAcceptThreshold: 0.85,
+		VendorRuleDecay:     RuleDecay{Curve: DecayStep, After: 365 * 24 * time.Hour, Floor: 0.9},
+	}
+	result := BatchResult{Merchant: "Walmart", Transactions: []model.Transaction{txn}}
+	require.True(t, engine.applyRules(ctx, &result, opts))
+	require.True(t, autoAcceptable(result, opts))
+	require.NoError(t, engine.saveAutoAcceptedBatch(ctx, []BatchResult{result}, 0))
+
+	vendor, err := db.GetVendor(ctx, "Walmart")
+	require.NoError(t, err)
+	assert.Equal(t, 41, vendor.UseCount)
+	assert.Equal(t, model.SourceManual, vendor.Source)
+	assert.True(t, vendor.LastUpdated.Equal(lastUpdated), "the rule stays stale until confirmed, got %s", vendor.LastUpdated)
+
+	classifications, err := db.GetClassificationsByConfidence(ctx, 1.1, false)
+	require.NoError(t, err)
+	require.Len(t, classifications, 1)
+	assert.Equal(t, model.StatusClassifiedByRule, classifications[0].Status)
+}
@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+// SampleStrategy controls which of a merchant's transactions are shown to the LLM.
+type SampleStrategy string
+
+// Sample strategies.
+const (
+	// SampleFirst sends the merchant's first transaction.
+	SampleFirst SampleStrategy = "first"
+	// SampleRepresentative sends the median-amount transaction in the merchant's most common direction.
+	SampleRepresentative SampleStrategy = "representative"
+)
+
+// ParseSampleStrategy validates a sample strategy name. An empty name means SampleFirst.
+func ParseSampleStrategy(name string) (SampleStrategy, error) {
+	switch SampleStrategy(name) {
+	case "", SampleFirst:
+		return SampleFirst, nil
+	case SampleRepresentative:
+		return SampleRepresentative, nil
+	default:
+		return "", fmt.Errorf("invalid sample strategy %q (use %s or %s)", name, SampleFirst, SampleRepresentative)
+	}
+}
+
+// selectSamples picks up to count transactions to describe a merchant, primary sample first.
+// A count below one is treated as one.
+func selectSamples(txns []model.Transaction, strategy SampleStrategy, count int) []model.Transaction {
+	if len(txns) == 0 {
+		return nil
+	}
+	if count < 1 {
+		count = 1
+	}
+
+	if strategy != SampleRepresentative {
+		if count > len(txns) {
+			count = len(txns)
+		}
+		return txns[:count]
+	}
+
+	// Only consider the dominant direction so a stray refund can't become the sample
+	candidates := make([]model.Transaction, 0, len(txns))
+	direction := dominantDirection(txns)
+	for _, txn := range txns {
+		if txn.Direction == direction {
+			candidates = append(candidates, txn)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return math.Abs(candidates[i].Amount) < math.Abs(candidates[j].Amount)
+	})
+
+	if count > len(candidates) {
+		count = len(candidates)
+	}
+
+	// The median comes first, then the extremes working inward to show the amount range
+	median := (len(candidates) - 1) / 2
+	samples := []model.Transaction{candidates[median]}
+	for low, high := 0, len(candidates)-1; len(samples) < count; low, high = low+1, high-1 {
+		if low != median {
+			samples = append(samples, candidates[low])
+		}
+		if high != low && high != median && len(samples) < count {
+			samples = append(samples, candidates[high])
+		}
+	}
+
+	return samples
+}
+
+// dominantDirection returns the most common direction, preferring the earliest seen on ties.
+func dominantDirection(txns []model.Transaction) model.TransactionDirection {
+	counts := make(map[model.TransactionDirection]int)
+	var order []model.TransactionDirection
+	for _, txn := range txns {
+		if counts[txn.Direction] == 0 {
+			order = append(order, txn.Direction)
+		}
+		counts[txn.Direction]++
+	}
+
+	best := order[0]
+	for _, direction := range order[1:] {
+		if counts[direction] > counts[best] {
+			best = direction
+		}
+	}
+	return best
+}
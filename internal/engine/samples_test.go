@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectSamples(t *testing.T) {
+	txns := []model.Transaction{
+		{ID: "refund", Amount: 500, Direction: model.DirectionIncome},
+		{ID: "small", Amount: 5, Direction: model.DirectionExpense},
+		{ID: "mid", Amount: 40, Direction: model.DirectionExpense},
+		{ID: "large", Amount: 300, Direction: model.DirectionExpense},
+		{ID: "low-mid", Amount: 25, Direction: model.DirectionExpense},
+	}
+
+	ids := func(samples []model.Transaction) []string {
+		out := make([]string, len(samples))
+		for i, s := range samples {
+			out[i] = s.ID
+		}
+		return out
+	}
+
+	tests := []struct {
+		name     string
+		strategy SampleStrategy
+		want     []string
+		count    int
+	}{
+		{name: "default keeps first transaction", strategy: "", count: 0, want: []string{"refund"}},
+		{name: "first with several samples", strategy: SampleFirst, count: 2, want: []string{"refund", "small"}},
+		{name: "first caps at transaction count", strategy: SampleFirst, count: 10, want: []string{"refund", "small", "mid", "large", "low-mid"}},
+		{name: "representative skips the odd refund", strategy: SampleRepresentative, count: 1, want: []string{"low-mid"}},
+		{name: "representative adds extremes", strategy: SampleRepresentative, count: 3, want: []string{"low-mid", "small", "large"}},
+		{name: "representative caps at dominant direction", strategy: SampleRepresentative, count: 10, want: []string{"low-mid", "small", "large", "mid"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ids(selectSamples(txns, tt.strategy, tt.count)))
+		})
+	}
+
+	assert.Empty(t, selectSamples(nil, SampleRepresentative, 3))
+}
+
+func TestParseSampleStrategy(t *testing.T) {
+	strategy, err := ParseSampleStrategy("")
+	require.NoError(t, err)
+	assert.Equal(t, SampleFirst, strategy)
+
+	strategy, err = ParseSampleStrategy("representative")
+	require.NoError(t, err)
+	assert.Equal(t, SampleRepresentative, strategy)
+
+	_, err = ParseSampleStrategy("random")
+	assert.Error(t, err)
+}
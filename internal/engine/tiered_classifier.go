@@ -0,0 +1,274 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/llm"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
+)
+
+// DefaultEscalationThreshold is the top confidence below which merchants get a second pass.
+const DefaultEscalationThreshold = 0.80
+
+// TieredClassifierConfig configures two-tier classification.
+type TieredClassifierConfig struct {
+	EscalationThreshold   float64 // Top confidence below which the strong classifier is consulted
+	CheapCostPerMerchant  float64 // Estimated cost of classifying one merchant with the cheap tier
+	StrongCostPerMerchant float64 // Estimated cost of classifying one merchant with the strong tier
+}
+
+// TierStats contains usage statistics for a single classifier tier.
+type TierStats struct {
+	Requests      int     // Number of calls made to the classifier
+	Merchants     int     // Number of merchants (or transactions) sent to the classifier
+	EstimatedCost float64 // Merchants multiplied by the configured per-merchant cost
+}
+
+// TieredStats contains usage statistics for a tiered classification run.
+type TieredStats struct {
+	Cheap     TierStats
+	Strong    TierStats
+	Escalated int // Merchants whose cheap result was replaced by the strong tier
+}
+
+// TieredClassifier classifies with a cheap classifier first and escalates
+// low-confidence merchants to a stronger classifier.
+type TieredClassifier struct {
+	cheap  Classifier
+	strong Classifier
+	config TieredClassifierConfig
+	stats  TieredStats
+	mu     sync.Mutex
+}
+
+// NewTieredClassifier creates a classifier that escalates from cheap to strong.
+func NewTieredClassifier(cheap, strong Classifier, config TieredClassifierConfig) *TieredClassifier {
+	if config.EscalationThreshold <= 0 {
+		config.EscalationThreshold = DefaultEscalationThreshold
+	}
+
+	return &TieredClassifier{
+		cheap:  cheap,
+		strong: strong,
+		config: config,
+	}
+}
+
+// Stats returns a snapshot of the per-tier usage statistics.
+func (t *TieredClassifier) Stats() TieredStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stats
+}
+
+// SuggestCategoryBatch classifies all merchants with the cheap tier and re-classifies
+// those below the escalation threshold with the strong tier.
+func (t *TieredClassifier) SuggestCategoryBatch(ctx context.Context, requests []llm.MerchantBatchRequest, categories []model.Category) (map[string]model.CategoryRankings, error) {
+	results, err := t.cheap.SuggestCategoryBatch(ctx, requests, categories)
+	t.record(false, len(requests))
+	if err != nil {
+		slog.Warn("cheap tier batch classification failed, escalating all merchants", "error", err)
+		results = make(map[string]model.CategoryRankings)
+	}
+
+	var escalate []llm.MerchantBatchRequest
+	for _, req := range requests {
+		if t.needsEscalation(results[req.MerchantID]) {
+			escalate = append(escalate, req)
+		}
+	}
+
+	if len(escalate) == 0 {
+		return results, nil
+	}
+
+	strongResults, strongErr := t.strong.SuggestCategoryBatch(ctx, escalate, categories)
+	t.record(true, len(escalate))
+	if strongErr != nil {
+		if err != nil {
+			return nil, fmt.Errorf("both classifier tiers failed: %w", strongErr)
+		}
+		slog.Warn("strong tier batch classification failed, keeping cheap results", "error", strongErr)
+		return results, nil
+	}
+
+	escalated := 0
+	for _, req := range escalate {
+		if rankings := strongResults[req.MerchantID]; len(rankings) > 0 {
+			results[req.MerchantID] = rankings
+			escalated++
+		}
+	}
+	t.addEscalated(escalated)
+
+	return results, nil
+}
+
+// SuggestCategoryRankings ranks categories with the cheap tier, escalating when unsure.
+func (t *TieredClassifier) SuggestCategoryRankings(ctx context.Context, transaction model.Transaction, categories []model.Category, checkPatterns []model.CheckPattern) (model.CategoryRankings, error) {
+	rankings, err := t.cheap.SuggestCategoryRankings(ctx, transaction, categories, checkPatterns)
+	t.record(false, 1)
+	if err == nil && !t.needsEscalation(rankings) {
+		return rankings, nil
+	}
+
+	strongRankings, strongErr := t.strong.SuggestCategoryRankings(ctx, transaction, categories, checkPatterns)
+	t.record(true, 1)
+	if strongErr != nil {
+		if err != nil {
+			return nil, fmt.Errorf("both classifier tiers failed: %w", strongErr)
+		}
+		return rankings, nil
+	}
+
+	t.addEscalated(1)
+	return strongRankings, nil
+}
+
+// SuggestCategory suggests a category with the cheap tier, escalating when unsure.
+func (t *TieredClassifier) SuggestCategory(ctx context.Context, transaction model.Transaction, categories []string) (string, float64, bool, string, error) {
+	category, confidence, isNew, description, err := t.cheap.SuggestCategory(ctx, transaction, categories)
+	t.record(false, 1)
+	if err == nil && confidence >= t.config.EscalationThreshold {
+		return category, confidence, isNew, description, nil
+	}
+
+	strongCategory, strongConfidence, strongIsNew, strongDescription, strongErr := t.strong.SuggestCategory(ctx, transaction, categories)
+	t.record(true, 1)
+	if strongErr != nil {
+		if err != nil {
+			return "", 0, false, "", fmt.Errorf("both classifier tiers failed: %w", strongErr)
+		}
+		return category, confidence, isNew, description, nil
+	}
+
+	t.addEscalated(1)
+	return strongCategory, strongConfidence, strongIsNew, strongDescription, nil
+}
+
+// BatchSuggestCategories suggests categories with the cheap tier and re-classifies
+// low-confidence transactions with the strong tier.
+func (t *TieredClassifier) BatchSuggestCategories(ctx context.Context, transactions []model.Transaction, categories []string) ([]service.LLMSuggestion, error) {
+	suggestions, err := t.cheap.BatchSuggestCategories(ctx, transactions, categories)
+	t.record(false, len(transactions))
+	if err != nil {
+		suggestions, err = t.strong.BatchSuggestCategories(ctx, transactions, categories)
+		t.record(true, len(transactions))
+		if err != nil {
+			return nil, fmt.Errorf("both classifier tiers failed: %w", err)
+		}
+		t.addEscalated(len(transactions))
+		return suggestions, nil
+	}
+
+	var escalate []model.Transaction
+	var indexes []int
+	for i, s := range suggestions {
+		if s.Confidence < t.config.EscalationThreshold && i < len(transactions) {
+			escalate = append(escalate, transactions[i])
+			indexes = append(indexes, i)
+		}
+	}
+	if len(escalate) == 0 {
+		return suggestions, nil
+	}
+
+	strongSuggestions, strongErr := t.strong.BatchSuggestCategories(ctx, escalate, categories)
+	t.record(true, len(escalate))
+	if strongErr != nil {
+		slog.Warn("strong tier classification failed, keeping cheap results", "error", strongErr)
+		return suggestions, nil
+	}
+
+	for j, idx := range indexes {
+		if j < len(strongSuggestions) {
+			suggestions[idx] = strongSuggestions[j]
+		}
+	}
+	t.addEscalated(len(indexes))
+
+	return suggestions, nil
+}
+
+// GenerateCategoryDescription uses the strong tier since descriptions are rare and user-facing.
+func (t *TieredClassifier) GenerateCategoryDescription(ctx context.Context, categoryName string) (string, float64, error) {
+	return t.strong.GenerateCategoryDescription(ctx, categoryName)
+}
+
+// Close closes both underlying classifiers when they support it.
+func (t *TieredClassifier) Close() error {
+	var firstErr error
+	for _, c := range []Classifier{t.cheap, t.strong} {
+		if closer, ok := c.(interface{ Close() error }); ok {
+			if err := closer.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// needsEscalation reports whether rankings are missing or below the escalation threshold.
+func (t *TieredClassifier) needsEscalation(rankings model.CategoryRankings) bool {
+	top := rankings.Top()
+	return top == nil || top.Score < t.config.EscalationThreshold
+}
+
+// record adds a classifier call to the statistics for the given tier.
+func (t *TieredClassifier) record(strong bool, merchants int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if strong {
+		t.stats.Strong.Requests++
+		t.stats.Strong.Merchants += merchants
+		t.stats.Strong.EstimatedCost += float64(merchants) * t.config.StrongCostPerMerchant
+		return
+	}
+
+	t.stats.Cheap.Requests++
+	t.stats.Cheap.Merchants += merchants
+	t.stats.Cheap.EstimatedCost += float64(merchants) * t.config.CheapCostPerMerchant
+}
+
+// addEscalated records merchants whose result came from the strong tier.
+func (t *TieredClassifier) addEscalated(count int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats.Escalated += count
+}
+
+// GetDisplay returns a JSON representation of the tier statistics.
+func (s TieredStats) GetDisplay() string {
+	type tierJSON struct {
+		Requests      int     `json:"requests"`
+		Merchants     int     `json:"merchants"`
+		EstimatedCost float64 `json:"estimated_cost"`
+	}
+
+	type statsJSON struct {
+		Cheap              tierJSON `json:"cheap_tier"`
+		Strong             tierJSON `json:"strong_tier"`
+		Escalated          int      `json:"escalated"`
+		TotalEstimatedCost float64  `json:"total_estimated_cost"`
+	}
+
+	data := statsJSON{
+		Cheap:              tierJSON(s.Cheap),
+		Strong:             tierJSON(s.Strong),
+		Escalated:          s.Escalated,
+		TotalEstimatedCost: s.Cheap.EstimatedCost + s.Strong.EstimatedCost,
+	}
+
+	bytes, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Sprintf(`{"error":"Failed to marshal tier stats: %v"}`, err)
+	}
+
+	return string(bytes)
+}
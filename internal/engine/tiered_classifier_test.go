@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/llm"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTieredClassifier_SuggestCategoryBatch(t *testing.T) {
+	cheap := NewMockClassifier()
+	strong := NewMockClassifier()
+
+	cheap.SetBatchResponse(map[string]model.CategoryRankings{
+		"walmart": {{Category: "Groceries", Score: 0.95}},
+		"amazon":  {{Category: "Shopping", Score: 0.40}},
+		"unknown": {},
+	})
+	strong.SetBatchResponse(map[string]model.CategoryRankings{
+		"amazon":  {{Category: "Office Supplies", Score: 0.90}},
+		"unknown": {{Category: "Entertainment", Score: 0.85}},
+	})
+
+	tiered := NewTieredClassifier(cheap, strong, TieredClassifierConfig{
+		EscalationThreshold:   0.80,
+		CheapCostPerMerchant:  0.001,
+		StrongCostPerMerchant: 0.01,
+	})
+
+	requests := []llm.MerchantBatchRequest{
+		{MerchantID: "walmart", MerchantName: "Walmart"},
+		{MerchantID: "amazon", MerchantName: "Amazon"},
+		{MerchantID: "unknown", MerchantName: "Unknown"},
+	}
+
+	results, err := tiered.SuggestCategoryBatch(context.Background(), requests, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Groceries", results["walmart"].Top().Category)
+	assert.Equal(t, "Office Supplies", results["amazon"].Top().Category)
+	assert.Equal(t, "Entertainment", results["unknown"].Top().Category)
+
+	// Only the two ambiguous merchants reach the strong tier
+	assert.Equal(t, 3, cheap.CallCount())
+	assert.Equal(t, 2, strong.CallCount())
+
+	stats := tiered.Stats()
+	assert.Equal(t, TierStats{Requests: 1, Merchants: 3, EstimatedCost: 0.003}, stats.Cheap)
+	assert.Equal(t, 1, stats.Strong.Requests)
+	assert.Equal(t, 2, stats.Strong.Merchants)
+	assert.InDelta(t, 0.02, stats.Strong.EstimatedCost, 1e-9)
+	assert.Equal(t, 2, stats.Escalated)
+	assert.Contains(t, stats.GetDisplay(), `"escalated":2`)
+}
+
+func TestTieredClassifier_NoEscalationNeeded(t *testing.T) {
+	cheap := NewMockClassifier()
+	strong := NewMockClassifier()
+
+	cheap.SetBatchResponse(map[string]model.CategoryRankings{
+		"walmart": {{Category: "Groceries", Score: 0.95}},
+	})
+
+	tiered := NewTieredClassifier(cheap, strong, TieredClassifierConfig{})
+
+	results, err := tiered.SuggestCategoryBatch(context.Background(), []llm.MerchantBatchRequest{{MerchantID: "walmart"}}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Groceries", results["walmart"].Top().Category)
+	assert.Equal(t, 0, strong.CallCount())
+	assert.Equal(t, 0, tiered.Stats().Escalated)
+}
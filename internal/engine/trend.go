@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
+)
+
+// DefaultTrendMonths is the number of months shown when none is given.
+const DefaultTrendMonths = 6
+
+// CategoryTrend holds a category's spending for each month of a trend window.
+type CategoryTrend struct {
+	Category string
+	Monthly  []float64 // Oldest month first; months without spending are zero
+	Total    float64
+}
+
+// SpendingTrend is per-category monthly spending over consecutive months.
+type SpendingTrend struct {
+	Start      time.Time // First day of the oldest month
+	Categories []CategoryTrend
+	Months     int
+}
+
+// Trend returns monthly spending per category for the months up to and
+// including end's month. It only reads stored classifications.
+func Trend(ctx context.Context, store service.Storage, end time.Time, months int) (*SpendingTrend, error) {
+	if months <= 0 {
+		months = DefaultTrendMonths
+	}
+	endMonth := time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, end.Location())
+	start := endMonth.AddDate(0, -(months - 1), 0)
+
+	classifications, err := store.GetClassificationsByDateRange(ctx, start, endMonth.AddDate(0, 1, 0).Add(-time.Nanosecond))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get classifications for trend: %w", err)
+	}
+
+	categories, err := store.GetCategories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories for trend: %w", err)
+	}
+
+	return BuildTrend(classifications, categories, start, months), nil
+}
+
+// BuildTrend sums expense spending per category into months starting at start,
+// with the monthly aggregation forecasts and the Monthly Flow sheet use.
+// Income, transfers and non-expense categories are excluded; refunds offset
+// their category's spending.
+func BuildTrend(classifications []model.Classification, categories []model.Category, start time.Time, months int) *SpendingTrend {
+	start = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, start.Location())
+
+	monthly := monthlySpending(classifications, categories, start, months)
+
+	trend := &SpendingTrend{
+		Start:      start,
+		Months:     months,
+		Categories: make([]CategoryTrend, 0, len(monthly)),
+	}
+	for category, totals := range monthly {
+		ct := CategoryTrend{Category: category, Monthly: totals}
+		for i, amount := range totals {
+			totals[i] = roundCents(amount)
+			ct.Total += amount
+		}
+		ct.Total = roundCents(ct.Total)
+		trend.Categories = append(trend.Categories, ct)
+	}
+
+	// Largest spending first
+	sort.Slice(trend.Categories, func(i, j int) bool {
+		if trend.Categories[i].Total != trend.Categories[j].Total {
+			return trend.Categories[i].Total > trend.Categories[j].Total
+		}
+		return trend.Categories[i].Category < trend.Categories[j].Category
+	})
+
+	return trend
+}
@@ -0,0 +1,67 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildTrend(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	day := func(m time.Month, d int) time.Time {
+		return time.Date(2024, m, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	categories := []model.Category{
+		{Name: "Groceries", Type: model.CategoryTypeExpense},
+		{Name: "Dining", Type: model.CategoryTypeExpense},
+		{Name: "Salary", Type: model.CategoryTypeIncome},
+	}
+	classifications := []model.Classification{
+		forecastClassification("Groceries", "Safeway", day(3, 2), 100, model.DirectionExpense),
+		forecastClassification("Groceries", "Safeway", day(3, 20), 50.5, model.DirectionExpense),
+		forecastClassification("Groceries", "Safeway", day(5, 9), 80, model.DirectionExpense),
+		forecastClassification("Dining", "Cafe", day(4, 1), 30, model.DirectionExpense),
+		forecastClassification("Salary", "Acme", day(4, 15), 5000, model.DirectionIncome),
+		forecastClassification("Dining", "Cafe", day(2, 28), 99, model.DirectionExpense), // before window
+		forecastClassification("Dining", "Cafe", day(6, 1), 99, model.DirectionExpense),  // after window
+	}
+
+	trend := BuildTrend(classifications, categories, start, 3)
+	assert.Equal(t, 3, trend.Months)
+	assert.Equal(t, start, trend.Start)
+	require.Len(t, trend.Categories, 2)
+
+	assert.Equal(t, "Groceries", trend.Categories[0].Category)
+	assert.Equal(t, []float64{150.5, 0, 80}, trend.Categories[0].Monthly, "sparse months are padded with zeros")
+	assert.InDelta(t, 230.5, trend.Categories[0].Total, 0.001)
+
+	assert.Equal(t, "Dining", trend.Categories[1].Category)
+	assert.Equal(t, []float64{0, 30, 0}, trend.Categories[1].Monthly)
+}
+
+func TestBuildTrend_Empty(t *testing.T) {
+	trend := BuildTrend(nil, nil, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), 4)
+	assert.Empty(t, trend.Categories)
+	assert.Equal(t, 1, trend.Start.Day())
+}
+
+func TestBuildTrend_Refunds(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	categories := []model.Category{{Name: "Shopping", Type: model.CategoryTypeExpense}}
+
+	refund := forecastClassification("Shopping", "Store", time.Date(2024, 4, 10, 0, 0, 0, 0, time.UTC), 25, model.DirectionExpense)
+	refund.Transaction.IsRefund = true
+	classifications := []model.Classification{
+		forecastClassification("Shopping", "Store", time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC), 100, model.DirectionExpense),
+		refund,
+	}
+
+	trend := BuildTrend(classifications, categories, start, 3)
+	require.Len(t, trend.Categories, 1)
+	assert.Equal(t, []float64{0, 75, 0}, trend.Categories[0].Monthly)
+	assert.InDelta(t, 75.0, trend.Categories[0].Total, 0.001)
+}
@@ -0,0 +1,136 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/llm"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// userModifiedTestStorage saves a low-confidence AI classification for Cafe
+// and one the user made for Bistro, both in Food.
+func userModifiedTestStorage(t *testing.T) (*storage.SQLiteStorage, []model.Transaction) {
+	t.Helper()
+	ctx := context.Background()
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+	t.Cleanup(func() { _ = db.Close() })
+
+	for _, name := range []string{"Food", "Dining"} {
+		_, err = db.CreateCategoryWithType(ctx, name, "", model.CategoryTypeExpense)
+		require.NoError(t, err)
+	}
+
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	transactions := []model.Transaction{
+		{ID: "cafe", Hash: "hash-cafe", Name: "CAFE", MerchantName: "Cafe", Amount: 12, Type: "DEBIT", Date: date, AccountID: "acc1", Direction: model.DirectionExpense},
+		{ID: "bistro", Hash: "hash-bistro", Name: "BISTRO", MerchantName: "Bistro", Amount: 30, Type: "DEBIT", Date: date, AccountID: "acc1", Direction: model.DirectionExpense},
+	}
+	require.NoError(t, db.SaveTransactions(ctx, transactions))
+	require.NoError(t, db.SaveClassification(ctx, &model.Classification{
+		Transaction: transactions[0], Category: "Food", Status: model.StatusClassifiedByAI, Confidence: 0.5,
+	}))
+	require.NoError(t, db.SaveClassification(ctx, &model.Classification{
+		Transaction: transactions[1], Category: "Food", Status: model.StatusUserModified, Confidence: 0.5,
+	}))
+	return db, transactions
+}
+
+// userModifiedTestCategories returns each transaction's saved category.
+func userModifiedTestCategories(t *testing.T, db *storage.SQLiteStorage) map[string]string {
+	t.Helper()
+	classifications, err := db.GetClassificationsByDateRange(context.Background(),
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	categories := make(map[string]string, len(classifications))
+	for _, c := range classifications {
+		categories[c.Transaction.ID] = c.Category
+	}
+	return categories
+}
+
+// diningClassifier confidently puts every merchant in Dining.
+type diningClassifier struct {
+	*MockClassifier
+}
+
+func newDiningClassifier() *diningClassifier {
+	return &diningClassifier{MockClassifier: NewMockClassifier()}
+}
+
+func (c *diningClassifier) SuggestCategoryBatch(_ context.Context, requests []llm.MerchantBatchRequest, _ []model.Category) (map[string]model.CategoryRankings, error) {
+	results := make(map[string]model.CategoryRankings, len(requests))
+	for _, req := range requests {
+		results[req.MerchantID] = model.CategoryRankings{{Category: "Dining", Score: 0.99}}
+	}
+	return results, nil
+}
+
+func TestRerank_UserModified(t *testing.T) {
+	ctx := context.Background()
+	opts := RerankOptions{
+		ConfidenceThreshold: 0.6,
+		AutoAcceptThreshold: 0.9,
+		BatchSize:           5,
+		ParallelWorkers:     1,
+		SkipManualReview:    true,
+		Prompt:              RerankPromptStandard,
+	}
+
+	db, _ := userModifiedTestStorage(t)
+	engine := New(db, newDiningClassifier(), NewMockPrompter(true))
+	summary, err := engine.RerankLowConfidenceTransactions(ctx, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.TotalEvaluated, "the user's classification isn't re-ranked")
+
+	// A rule pointing elsewhere only replaces it once the storage allows it
+	opts.IncludeUserModified = true
+	for _, overwrite := range []bool{false, true} {
+		db, _ = userModifiedTestStorage(t)
+		require.NoError(t, db.SaveVendor(ctx, &model.Vendor{Name: "Bistro", Category: "Dining"}))
+		db.SetOverwriteUserModified(overwrite)
+		engine = New(db, newDiningClassifier(), NewMockPrompter(true))
+		summary, err = engine.RerankLowConfidenceTransactions(ctx, opts)
+		require.NoError(t, err)
+		assert.Equal(t, 2, summary.TotalEvaluated)
+
+		want := "Food"
+		if overwrite {
+			want = "Dining"
+		}
+		assert.Equal(t, want, userModifiedTestCategories(t, db)["bistro"], "overwrite=%v", overwrite)
+	}
+}
+
+func TestClassifySpecificTransactions_UserModified(t *testing.T) {
+	ctx := context.Background()
+	opts := BatchClassificationOptions{
+		AutoAcceptThreshold: 0.9,
+		BatchSize:           5,
+		ParallelWorkers:     1,
+		SkipManualReview:    true,
+	}
+
+	// Re-running classification over both transactions, as recategorize does,
+	// only changes the AI's classification
+	db, transactions := userModifiedTestStorage(t)
+	require.NoError(t, db.DeleteVendor(ctx, "Bistro"), "drop the rule the user's classification created")
+	engine := New(db, newDiningClassifier(), NewMockPrompter(true))
+	_, err := engine.ClassifySpecificTransactions(ctx, transactions, opts)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"cafe": "Dining", "bistro": "Food"}, userModifiedTestCategories(t, db))
+
+	db, transactions = userModifiedTestStorage(t)
+	db.SetOverwriteUserModified(true)
+	require.NoError(t, db.DeleteVendor(ctx, "Bistro"))
+	engine = New(db, newDiningClassifier(), NewMockPrompter(true))
+	_, err = engine.ClassifySpecificTransactions(ctx, transactions, opts)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"cafe": "Dining", "bistro": "Dining"}, userModifiedTestCategories(t, db))
+}
@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/pattern"
+)
+
+const (
+	// DefaultValidationAmountMultiple is how many times a category's average amount
+	// a transaction must exceed before its classification is flagged.
+	DefaultValidationAmountMultiple = 10.0
+	// minValidationHistory is the number of classified transactions a category
+	// needs before its amounts are considered usual.
+	minValidationHistory = 5
+)
+
+// validateResults runs a sanity pass over the results that would be auto-accepted
+// and records any problems on them, which sends them to review instead. It
+// returns the number of merchant groups flagged.
+func (e *ClassificationEngine) validateResults(ctx context.Context, results []BatchResult, categories []model.Category, opts BatchClassificationOptions) int {
+	if !opts.Validate {
+		return 0
+	}
+
+	amountStats, err := e.storage.GetCategoryAmountStats(ctx)
+	if err != nil {
+		// Direction checks still work without history
+		slog.Warn("Failed to load category amounts for validation", "error", err)
+	}
+	multiple := opts.ValidationAmountMultiple
+	if multiple <= 0 {
+		multiple = DefaultValidationAmountMultiple
+	}
+
+	byName := make(map[string]model.Category, len(categories))
+	for _, cat := range categories {
+		byName[cat.Name] = cat
+	}
+	validator := pattern.NewValidator()
+
+	flagged := 0
+	for i := range results {
+		result := &results[i]
+		if result.Error != nil || !autoAcceptable(*result, opts) {
+			continue
+		}
+		category, ok := byName[result.Suggestion.Category]
+		if !ok {
+			continue
+		}
+
+		result.ValidationIssues = validateTransactions(ctx, validator, category, amountStats[category.Name], multiple, result.Transactions)
+		if len(result.ValidationIssues) > 0 {
+			flagged++
+			slog.Warn("Classification failed validation, sending to review",
+				"merchant", result.Merchant,
+				"category", category.Name,
+				"issues", result.ValidationIssues)
+		}
+	}
+
+	return flagged
+}
+
+// validateTransactions checks each transaction against category: its direction
+// must suit the category type, and its amount must not dwarf the amounts usually
+// seen in the category.
+func validateTransactions(ctx context.Context, validator pattern.TransactionValidator, category model.Category, stats model.CategoryAmountStats, multiple float64, txns []model.Transaction) []string {
+	var issues []string
+	for _, txn := range txns {
+		// Without a direction the validator can only guess from the amount sign,
+		// which is always positive since amounts are stored as absolute values
+		if txn.Direction != "" {
+			if err := validator.ValidateDirection(ctx, txn, category); err != nil {
+				issues = append(issues, err.Error())
+				continue
+			}
+		}
+
+		if stats.Count >= minValidationHistory && stats.Average > 0 &&
+			txn.Amount > stats.Average*multiple && txn.Amount > stats.Max {
+			issues = append(issues, fmt.Sprintf("amount $%.2f on %s is %.0fx the usual $%.2f for %q",
+				txn.Amount, txn.Date.Format("2006-01-02"), txn.Amount/stats.Average, stats.Average, category.Name))
+		}
+	}
+	return issues
+}
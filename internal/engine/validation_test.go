@@ -0,0 +1,136 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/pattern"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTransactions(t *testing.T) {
+	ctx := context.Background()
+	groceries := model.Category{Name: "Groceries", Type: model.CategoryTypeExpense}
+	history := model.CategoryAmountStats{Category: "Groceries", Count: 20, Average: 60, Max: 180}
+	date := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		stats      model.CategoryAmountStats
+		txn        model.Transaction
+		wantIssues int
+	}{
+		{
+			name:  "usual expense passes",
+			stats: history,
+			txn:   model.Transaction{Amount: 75, Direction: model.DirectionExpense, Date: date},
+		},
+		{
+			name:       "income in an expense category",
+			stats:      history,
+			txn:        model.Transaction{Amount: 75, Direction: model.DirectionIncome, Date: date},
+			wantIssues: 1,
+		},
+		{
+			name:       "amount far above the category's usual amounts",
+			stats:      history,
+			txn:        model.Transaction{Amount: 2400, Direction: model.DirectionExpense, Date: date},
+			wantIssues: 1,
+		},
+		{
+			name:  "large amount within what the category has seen",
+			stats: model.CategoryAmountStats{Count: 20, Average: 60, Max: 3000},
+			txn:   model.Transaction{Amount: 2400, Direction: model.DirectionExpense, Date: date},
+		},
+		{
+			name:  "too little history to judge amounts",
+			stats: model.CategoryAmountStats{Count: 2, Average: 60, Max: 70},
+			txn:   model.Transaction{Amount: 2400, Direction: model.DirectionExpense, Date: date},
+		},
+		{
+			name:  "missing direction is not guessed",
+			stats: history,
+			txn:   model.Transaction{Amount: 75, Date: date},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := validateTransactions(ctx, pattern.NewValidator(), groceries, tt.stats,
+				DefaultValidationAmountMultiple, []model.Transaction{tt.txn})
+			assert.Len(t, issues, tt.wantIssues, issues)
+		})
+	}
+}
+
+func TestClassifyTransactionsBatch_Validation(t *testing.T) {
+	for _, validate := range []bool{false, true} {
+		t.Run(fmt.Sprintf("validate=%v", validate), func(t *testing.T) {
+			ctx := context.Background()
+			db, err := storage.NewSQLiteStorage(":memory:")
+			require.NoError(t, err)
+			require.NoError(t, db.Migrate(ctx))
+			defer func() { _ = db.Close() }()
+
+			_, err = db.CreateCategoryWithType(ctx, "Groceries", "Food", model.CategoryTypeExpense)
+			require.NoError(t, err)
+
+			// Past groceries around $50 give the category its usual amounts
+			date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			for i := 0; i < 6; i++ {
+				txn := model.Transaction{
+					ID: fmt.Sprintf("past%d", i), Hash: fmt.Sprintf("hash-past%d", i), Name: "SAFEWAY",
+					MerchantName: "Safeway", Amount: float64(45 + i), Type: "DEBIT",
+					Date: date.AddDate(0, 0, i), AccountID: "acc1", Direction: model.DirectionExpense,
+				}
+				require.NoError(t, db.SaveTransactions(ctx, []model.Transaction{txn}))
+				require.NoError(t, db.SaveClassification(ctx, &model.Classification{
+					Transaction: txn, Category: "Groceries", Status: model.StatusClassifiedByAI, Confidence: 0.9,
+				}))
+			}
+
+			// A $2,400 "grocery" run is far from usual
+			require.NoError(t, db.SaveTransactions(ctx, []model.Transaction{{
+				ID: "big", Hash: "hash-big", Name: "WHOLE FOODS", MerchantName: "Whole Foods",
+				Amount: 2400, Type: "DEBIT", Date: date.AddDate(0, 1, 0), AccountID: "acc1",
+				Direction: model.DirectionExpense,
+			}}))
+
+			classifier := NewMockClassifier()
+			classifier.SetBatchResponse(map[string]model.CategoryRankings{
+				"Whole Foods": {{Category: "Groceries", Score: 0.97}},
+			})
+			engine := &ClassificationEngine{storage: db, classifier: classifier, prompter: NewMockPrompter(true)}
+
+			from := date.AddDate(0, 0, 20)
+			summary, err := engine.ClassifyTransactionsBatch(ctx, &from, BatchClassificationOptions{
+				AutoAcceptThreshold: 0.90,
+				BatchSize:           5,
+				ParallelWorkers:     1,
+				SkipManualReview:    true,
+				Validate:            validate,
+			})
+			require.NoError(t, err)
+
+			if validate {
+				assert.Equal(t, 1, summary.ValidationCount)
+				assert.Equal(t, 0, summary.AutoAcceptedCount)
+				assert.Equal(t, 1, summary.NeedsReviewCount)
+
+				// Skipping review doesn't save the flagged suggestion
+				unclassified, err := db.GetTransactionsToClassify(ctx, &from)
+				require.NoError(t, err)
+				require.Len(t, unclassified, 1)
+				assert.Equal(t, "big", unclassified[0].ID)
+			} else {
+				assert.Equal(t, 0, summary.ValidationCount)
+				assert.Equal(t, 1, summary.AutoAcceptedCount)
+			}
+		})
+	}
+}
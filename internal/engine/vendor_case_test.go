@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyRules_VendorCaseSensitivity(t *testing.T) {
+	for _, caseSensitive := range []bool{false, true} {
+		ctx := context.Background()
+		db, err := storage.NewSQLiteStorage(":memory:")
+		require.NoError(t, err)
+		require.NoError(t, db.Migrate(ctx))
+		defer func() { _ = db.Close() }()
+
+		for _, name := range []string{"Shopping", "Coffee"} {
+			_, err = db.CreateCategoryWithType(ctx, name, "", model.CategoryTypeExpense)
+			require.NoError(t, err)
+		}
+		require.NoError(t, db.SaveVendor(ctx, &model.Vendor{Name: "AMAZON", Category: "Shopping", UseCount: 3}))
+		require.NoError(t, db.SaveVendor(ctx, &model.Vendor{Name: "(?i)^starbucks", Category: "Coffee", IsRegex: true}))
+		db.SetVendorCaseSensitive(caseSensitive)
+
+		engine := &ClassificationEngine{storage: db}
+		opts := BatchClassificationOptions{AutoAcceptThreshold: 0.95}
+
+		txn := model.Transaction{ID: "t1", Hash: "h1", Name: "AMAZON MKTP", MerchantName: "Amazon", Amount: 20, Type: "DEBIT", Date: time.Now(), AccountID: "acc1"}
+		require.NoError(t, db.SaveTransactions(ctx, []model.Transaction{txn}))
+		result := BatchResult{Merchant: "Amazon", Transactions: []model.Transaction{txn}}
+		matched := engine.applyRules(ctx, &result, opts)
+
+		// Regex vendors carry their own case handling in either mode
+		coffee := BatchResult{Merchant: "STARBUCKS", Transactions: []model.Transaction{{ID: "t2", MerchantName: "STARBUCKS"}}}
+		require.True(t, engine.applyRules(ctx, &coffee, opts))
+		assert.Equal(t, "Coffee", coffee.Suggestion.Category)
+
+		if caseSensitive {
+			assert.False(t, matched, "AMAZON's rule doesn't cover Amazon case-sensitively")
+			continue
+		}
+
+		require.True(t, matched)
+		assert.Equal(t, SourceVendor, result.Source)
+		assert.Equal(t, "Shopping", result.Suggestion.Category)
+		require.NoError(t, engine.saveAutoAcceptedBatch(ctx, []BatchResult{result}, 0))
+
+		vendor, err := db.GetVendor(ctx, "AMAZON")
+		require.NoError(t, err)
+		assert.Equal(t, "Shopping", vendor.Category)
+		assert.Greater(t, vendor.UseCount, 3, "the use count goes to the rule that matched")
+		vendors, err := db.GetAllVendors(ctx)
+		require.NoError(t, err)
+		assert.Len(t, vendors, 2, "no rule is added for the other spelling")
+	}
+}
@@ -0,0 +1,185 @@
+package engine
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+// DefaultVendorSimilarity is the similarity two vendor names need before they
+// are suggested as duplicates.
+const DefaultVendorSimilarity = 0.85
+
+// VendorCluster is a group of exact vendor rules whose names look like the
+// same merchant.
+type VendorCluster struct {
+	// Vendors is ordered by use count, most used first, then by name; the
+	// first is the suggested canonical name
+	Vendors []model.Vendor
+	// Similarity is the lowest similarity of the pairs that joined the cluster
+	Similarity float64
+}
+
+// Canonical returns the suggested canonical vendor, the most used one.
+func (c VendorCluster) Canonical() model.Vendor {
+	return c.Vendors[0]
+}
+
+// FindDuplicateVendors groups exact vendor rules whose normalized names are at
+// least threshold similar, where similarity is one minus the Levenshtein
+// distance over the longer name's length. Names are normalized by upper
+// casing them and dropping everything but letters, digits and single spaces,
+// so "AMAZON.COM" and "Amazon Com" are identical. A vendor joins a cluster
+// when it is similar to any vendor already in it.
+//
+// Regex vendors and multi-category merchants are left out; neither is a
+// plain merchant name. Clusters are ordered by their combined use count.
+func FindDuplicateVendors(vendors []model.Vendor, threshold float64) []VendorCluster {
+	candidates := make([]model.Vendor, 0, len(vendors))
+	names := make([]string, 0, len(vendors))
+	for _, vendor := range vendors {
+		if vendor.IsRegex || vendor.MultiCategory {
+			continue
+		}
+		name := normalizeVendorName(vendor.Name)
+		if name == "" {
+			continue
+		}
+		candidates = append(candidates, vendor)
+		names = append(names, name)
+	}
+
+	// Union-find over every similar pair
+	parent := make([]int, len(candidates))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	type similarPair struct {
+		first      int
+		similarity float64
+	}
+	var pairs []similarPair
+	for i := range candidates {
+		for j := i + 1; j < len(candidates); j++ {
+			similarity := nameSimilarity(names[i], names[j])
+			if similarity < threshold {
+				continue
+			}
+			pairs = append(pairs, similarPair{first: i, similarity: similarity})
+			if ri, rj := find(i), find(j); ri != rj {
+				parent[rj] = ri
+			}
+		}
+	}
+
+	lowest := make(map[int]float64)
+	for _, pair := range pairs {
+		root := find(pair.first)
+		if s, ok := lowest[root]; !ok || pair.similarity < s {
+			lowest[root] = pair.similarity
+		}
+	}
+
+	groups := make(map[int][]model.Vendor)
+	for i, vendor := range candidates {
+		root := find(i)
+		groups[root] = append(groups[root], vendor)
+	}
+
+	clusters := make([]VendorCluster, 0)
+	for root, members := range groups {
+		if len(members) < 2 {
+			continue
+		}
+		sort.Slice(members, func(i, j int) bool {
+			if members[i].UseCount != members[j].UseCount {
+				return members[i].UseCount > members[j].UseCount
+			}
+			return members[i].Name < members[j].Name
+		})
+		clusters = append(clusters, VendorCluster{Vendors: members, Similarity: lowest[root]})
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		ui, uj := clusterUseCount(clusters[i]), clusterUseCount(clusters[j])
+		if ui != uj {
+			return ui > uj
+		}
+		return clusters[i].Canonical().Name < clusters[j].Canonical().Name
+	})
+
+	return clusters
+}
+
+func clusterUseCount(c VendorCluster) int {
+	total := 0
+	for _, vendor := range c.Vendors {
+		total += vendor.UseCount
+	}
+	return total
+}
+
+// normalizeVendorName upper cases a name and keeps only letters and digits,
+// with runs of anything else collapsed to one space.
+func normalizeVendorName(name string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToUpper(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+			continue
+		}
+		space = true
+	}
+	return b.String()
+}
+
+// nameSimilarity is one minus the Levenshtein distance between a and b over
+// the length of the longer one, so identical names score 1.
+func nameSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein counts the single-rune insertions, deletions and substitutions
+// that turn a into b.
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindDuplicateVendors(t *testing.T) {
+	vendors := []model.Vendor{
+		{Name: "AMAZON.COM", Category: "Shopping", UseCount: 3},
+		{Name: "Amazon Com", Category: "Shopping", UseCount: 12},
+		{Name: "AMAZON COM*2K4", Category: "Books", UseCount: 1},
+		{Name: "STARBUCKS", Category: "Coffee", UseCount: 40},
+		{Name: "STARBUKCS", Category: "Coffee", UseCount: 2},
+		{Name: "SHELL OIL", Category: "Gas", UseCount: 9},
+		{Name: "^AMAZON.*", Category: "Shopping", IsRegex: true},
+		{Name: "AMAZON", Category: "Shopping", MultiCategory: true},
+	}
+
+	clusters := FindDuplicateVendors(vendors, 0.7)
+	require.Len(t, clusters, 2)
+
+	// Ordered by combined use count
+	assert.Equal(t, "STARBUCKS", clusters[0].Canonical().Name)
+	assert.Len(t, clusters[0].Vendors, 2)
+
+	amazon := clusters[1]
+	require.Len(t, amazon.Vendors, 3)
+	assert.Equal(t, "Amazon Com", amazon.Canonical().Name, "the most used vendor is canonical")
+	assert.Equal(t, "AMAZON.COM", amazon.Vendors[1].Name)
+	assert.Equal(t, "AMAZON COM*2K4", amazon.Vendors[2].Name)
+	assert.InDelta(t, 10.0/14.0, amazon.Similarity, 0.001)
+}
+
+func TestFindDuplicateVendors_Threshold(t *testing.T) {
+	vendors := []model.Vendor{
+		{Name: "STARBUCKS", UseCount: 1},
+		{Name: "STARBUKCS", UseCount: 1},
+		{Name: "starbucks!", UseCount: 1},
+	}
+
+	// Only the spellings that normalize to the same name are identical
+	clusters := FindDuplicateVendors(vendors, 1)
+	require.Len(t, clusters, 1)
+	assert.Len(t, clusters[0].Vendors, 2)
+	assert.InDelta(t, 1.0, clusters[0].Similarity, 0.001)
+
+	assert.Len(t, FindDuplicateVendors(vendors, 0.75)[0].Vendors, 3)
+	assert.Empty(t, FindDuplicateVendors(vendors[:2], 0.9))
+}
+
+func TestNameSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"STARBUCKS", "STARBUCKS", 1},
+		{"STARBUCKS", "STARBUCK", 8.0 / 9.0},
+		{"SHELL", "EXXON", 0},
+		{"", "", 1},
+		{"CAFÉ", "CAFE", 0.75},
+	}
+	for _, tt := range tests {
+		assert.InDelta(t, tt.want, nameSimilarity(tt.a, tt.b), 0.001, "%q vs %q", tt.a, tt.b)
+	}
+}
+
+func TestNormalizeVendorName(t *testing.T) {
+	assert.Equal(t, "AMAZON COM", normalizeVendorName("Amazon.com"))
+	assert.Equal(t, "SQ JOE S COFFEE", normalizeVendorName("  SQ *JOE'S  COFFEE "))
+	assert.Equal(t, "", normalizeVendorName("***"))
+}
@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveAutoAcceptedBatch_VendorRuleThreshold(t *testing.T) {
+	ctx := context.Background()
+	db, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+	defer func() { _ = db.Close() }()
+
+	thresholds := map[string]float64{"Groceries": 0.75, "Business": 0.98, "Shopping": 0}
+	for name, threshold := range thresholds {
+		category, createErr := db.CreateCategory(ctx, name, "")
+		require.NoError(t, createErr)
+		require.NoError(t, db.SetCategoryVendorRuleThreshold(ctx, category.ID, threshold))
+	}
+
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		merchant   string
+		category   string
+		score      float64
+		wantVendor bool
+	}{
+		{merchant: "Whole Foods", category: "Groceries", score: 0.8, wantVendor: true},
+		{merchant: "Trader Joes", category: "Groceries", score: 0.7, wantVendor: false},
+		{merchant: "Acme Consulting", category: "Business", score: 0.95, wantVendor: false},
+		{merchant: "Office Depot", category: "Business", score: 0.99, wantVendor: true},
+		{merchant: "Target", category: "Shopping", score: 0.9, wantVendor: true},
+		{merchant: "Etsy", category: "Shopping", score: 0.8, wantVendor: false},
+	}
+
+	results := make([]BatchResult, 0, len(tests))
+	var transactions []model.Transaction
+	for _, tt := range tests {
+		txn := model.Transaction{ID: tt.merchant, Hash: "hash-" + tt.merchant, Name: tt.merchant, MerchantName: tt.merchant, Amount: 20, Type: "DEBIT", Date: date, AccountID: "acc1"}
+		transactions = append(transactions, txn)
+		results = append(results, BatchResult{
+			Merchant:     tt.merchant,
+			Transactions: []model.Transaction{txn},
+			Suggestion:   &model.CategoryRanking{Category: tt.category, Score: tt.score},
+			AutoAccepted: true,
+		})
+	}
+	require.NoError(t, db.SaveTransactions(ctx, transactions))
+
+	engine := &ClassificationEngine{storage: db}
+	require.NoError(t, engine.saveAutoAcceptedBatch(ctx, results, 0))
+
+	for _, tt := range tests {
+		vendor, vendorErr := db.GetVendor(ctx, tt.merchant)
+		if tt.wantVendor {
+			require.NoError(t, vendorErr, tt.merchant)
+			assert.Equal(t, tt.category, vendor.Category)
+		} else {
+			assert.ErrorIs(t, vendorErr, sql.ErrNoRows, tt.merchant)
+		}
+	}
+}
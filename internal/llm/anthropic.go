@@ -99,7 +99,7 @@ func (c *anthropicClient) Classify(ctx context.Context, prompt string) (Classifi
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return ClassificationResponse{}, fmt.Errorf("anthropic API error (status %d): %s", resp.StatusCode, string(body))
+		return ClassificationResponse{}, apiError("anthropic", resp, body)
 	}
 
 	var response anthropicResponse
@@ -203,7 +203,7 @@ func (c *anthropicClient) ClassifyWithRankings(ctx context.Context, prompt strin
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return RankingResponse{}, fmt.Errorf("anthropic API error (status %d): %s", resp.StatusCode, string(body))
+		return RankingResponse{}, apiError("anthropic", resp, body)
 	}
 
 	var response anthropicResponse
@@ -301,7 +301,7 @@ func (c *anthropicClient) GenerateDescription(ctx context.Context, prompt string
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return DescriptionResponse{}, fmt.Errorf("anthropic API error (status %d): %s", resp.StatusCode, string(body))
+		return DescriptionResponse{}, apiError("anthropic", resp, body)
 	}
 
 	var response anthropicResponse
@@ -375,7 +375,7 @@ func (c *anthropicClient) ClassifyMerchantBatch(ctx context.Context, prompt stri
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return MerchantBatchResponse{}, fmt.Errorf("anthropic API error (status %d): %s", resp.StatusCode, string(body))
+		return MerchantBatchResponse{}, apiError("anthropic", resp, body)
 	}
 
 	var response anthropicResponse
@@ -413,6 +413,7 @@ func (c *anthropicClient) parseMerchantBatchResponse(content string) (MerchantBa
 				Score       float64 `json:"score"`
 				IsNew       bool    `json:"isNew"`
 				Description string  `json:"description,omitempty"`
+				Reason      string  `json:"reason,omitempty"`
 			} `json:"rankings"`
 		} `json:"classifications"`
 	}
@@ -433,6 +434,7 @@ func (c *anthropicClient) parseMerchantBatchResponse(content string) (MerchantBa
 				Score:       r.Score,
 				IsNew:       r.IsNew,
 				Description: r.Description,
+				Reason:      r.Reason,
 			})
 		}
 		classifications = append(classifications, MerchantClassification{
@@ -493,7 +495,7 @@ func (c *anthropicClient) Analyze(ctx context.Context, prompt string, systemProm
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("anthropic API error (status %d): %s", resp.StatusCode, string(body))
+		return "", apiError("anthropic", resp, body)
 	}
 
 	var response anthropicResponse
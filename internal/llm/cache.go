@@ -1,19 +1,49 @@
 package llm
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
 	"sync"
 	"time"
 
-	"github.com/Veraticus/the-spice-must-flow/internal/service"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
 )
 
-// cacheEntry represents a cached classification suggestion.
+// ResponseCache stores the LLM's classification of merchants so they aren't
+// sent to the LLM again. The default cache keeps them in memory for the life
+// of the process; pass another implementation to WithCache, for example one
+// backed by SQLite or Redis, to reuse them across runs. Implementations must
+// be safe for concurrent use.
+type ResponseCache interface {
+	// Get returns the response stored under key, if it hasn't expired.
+	Get(key string) (CachedResponse, bool)
+	// Set stores resp under key for ttl; a ttl of 0 means the cache's default.
+	Set(key string, resp CachedResponse, ttl time.Duration)
+}
+
+// CachedResponse is a cached classification of a merchant.
+type CachedResponse struct {
+	Rankings model.CategoryRankings `json:"rankings"`
+}
+
+// CacheKey derives the cache key of a merchant's classification from the
+// provider, model, prompt template version and merchant name. It depends on
+// nothing else, so a merchant gets the same key in every run. Merchant names
+// are compared ignoring case and whitespace.
+func CacheKey(provider, model, promptVersion, merchant string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(merchant)), " ")
+	sum := sha256.Sum256([]byte(strings.Join([]string{strings.ToLower(provider), model, promptVersion, normalized}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// cacheEntry represents a cached classification.
 type cacheEntry struct {
-	expiry     time.Time
-	suggestion service.LLMSuggestion
+	expiry   time.Time
+	response CachedResponse
 }
 
-// suggestionCache provides thread-safe caching for LLM suggestions.
+// suggestionCache is the default, in-memory ResponseCache.
 type suggestionCache struct {
 	entries map[string]cacheEntry
 	stopCh  chan struct{}
@@ -21,7 +51,7 @@ type suggestionCache struct {
 	mu      sync.RWMutex
 }
 
-// newSuggestionCache creates a new cache with the specified TTL.
+// newSuggestionCache creates a new cache with the specified default TTL.
 func newSuggestionCache(ttl time.Duration) *suggestionCache {
 	if ttl == 0 {
 		ttl = 15 * time.Minute // Default TTL
@@ -39,31 +69,35 @@ func newSuggestionCache(ttl time.Duration) *suggestionCache {
 	return cache
 }
 
-// get retrieves a suggestion from the cache if it exists and hasn't expired.
-func (c *suggestionCache) get(key string) (service.LLMSuggestion, bool) {
+// Get retrieves a response from the cache if it exists and hasn't expired.
+func (c *suggestionCache) Get(key string) (CachedResponse, bool) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	entry, exists := c.entries[key]
 	if !exists {
-		return service.LLMSuggestion{}, false
+		return CachedResponse{}, false
 	}
 
 	if time.Now().After(entry.expiry) {
-		return service.LLMSuggestion{}, false
+		return CachedResponse{}, false
 	}
 
-	return entry.suggestion, true
+	return entry.response, true
 }
 
-// set stores a suggestion in the cache.
-func (c *suggestionCache) set(key string, suggestion service.LLMSuggestion) {
+// Set stores a response in the cache.
+func (c *suggestionCache) Set(key string, resp CachedResponse, ttl time.Duration) {
+	if ttl == 0 {
+		ttl = c.ttl
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	c.entries[key] = cacheEntry{
-		suggestion: suggestion,
-		expiry:     time.Now().Add(c.ttl),
+		response: resp,
+		expiry:   time.Now().Add(ttl),
 	}
 }
 
@@ -1,10 +1,13 @@
 package llm
 
 import (
+	"context"
+	"log/slog"
+	"sync"
 	"testing"
 	"time"
 
-	"github.com/Veraticus/the-spice-must-flow/internal/service"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -15,20 +18,16 @@ func TestSuggestionCache(t *testing.T) {
 		defer cache.clear()
 
 		// Test empty cache
-		_, found := cache.get("non-existent")
+		_, found := cache.Get("non-existent")
 		assert.False(t, found)
 
 		// Test set and get
-		suggestion := service.LLMSuggestion{
-			TransactionID: "test-123",
-			Category:      "Coffee & Dining",
-			Confidence:    0.95,
-		}
-		cache.set("key1", suggestion)
+		response := CachedResponse{Rankings: model.CategoryRankings{{Category: "Coffee & Dining", Score: 0.95}}}
+		cache.Set("key1", response, 0)
 
-		retrieved, found := cache.get("key1")
+		retrieved, found := cache.Get("key1")
 		assert.True(t, found)
-		assert.Equal(t, suggestion, retrieved)
+		assert.Equal(t, response, retrieved)
 
 		// Test size
 		assert.Equal(t, 1, cache.size())
@@ -36,7 +35,7 @@ func TestSuggestionCache(t *testing.T) {
 		// Test clear
 		cache.clear()
 		assert.Equal(t, 0, cache.size())
-		_, found = cache.get("key1")
+		_, found = cache.Get("key1")
 		assert.False(t, found)
 	})
 
@@ -45,15 +44,11 @@ func TestSuggestionCache(t *testing.T) {
 		cache := newSuggestionCache(50 * time.Millisecond)
 		defer cache.clear()
 
-		suggestion := service.LLMSuggestion{
-			TransactionID: "test-456",
-			Category:      "Shopping",
-			Confidence:    0.85,
-		}
-		cache.set("key2", suggestion)
+		response := CachedResponse{Rankings: model.CategoryRankings{{Category: "Shopping", Score: 0.85}}}
+		cache.Set("key2", response, 0)
 
 		// Should be found immediately
-		_, found := cache.get("key2")
+		_, found := cache.Get("key2")
 		assert.True(t, found)
 
 		// Wait for expiration using a timer
@@ -61,7 +56,7 @@ func TestSuggestionCache(t *testing.T) {
 		<-timer.C
 
 		// Should not be found after expiration
-		_, found = cache.get("key2")
+		_, found = cache.Get("key2")
 		assert.False(t, found)
 	})
 
@@ -73,18 +68,14 @@ func TestSuggestionCache(t *testing.T) {
 		done := make(chan bool)
 		go func() {
 			for i := 0; i < 100; i++ {
-				cache.set("concurrent", service.LLMSuggestion{
-					TransactionID: "test",
-					Category:      "Test",
-					Confidence:    0.8,
-				})
+				cache.Set("concurrent", CachedResponse{Rankings: model.CategoryRankings{{Category: "Test", Score: 0.8}}}, 0)
 			}
 			done <- true
 		}()
 
 		go func() {
 			for i := 0; i < 100; i++ {
-				_, _ = cache.get("concurrent")
+				_, _ = cache.Get("concurrent")
 			}
 			done <- true
 		}()
@@ -103,12 +94,8 @@ func TestSuggestionCache(t *testing.T) {
 		}
 
 		// Cache should still be functional
-		cache.set("after-concurrent", service.LLMSuggestion{
-			TransactionID: "final",
-			Category:      "Final",
-			Confidence:    0.9,
-		})
-		_, found := cache.get("after-concurrent")
+		cache.Set("after-concurrent", CachedResponse{Rankings: model.CategoryRankings{{Category: "Final", Score: 0.9}}}, 0)
+		_, found := cache.Get("after-concurrent")
 		assert.True(t, found)
 	})
 
@@ -116,22 +103,22 @@ func TestSuggestionCache(t *testing.T) {
 		cache := newSuggestionCache(5 * time.Minute)
 		defer cache.clear()
 
-		suggestions := []service.LLMSuggestion{
-			{TransactionID: "1", Category: "Coffee & Dining", Confidence: 0.95},
-			{TransactionID: "2", Category: "Shopping", Confidence: 0.85},
-			{TransactionID: "3", Category: "Groceries", Confidence: 0.90},
+		suggestions := []CachedResponse{
+			{Rankings: model.CategoryRankings{{Category: "Coffee & Dining", Score: 0.95}}},
+			{Rankings: model.CategoryRankings{{Category: "Shopping", Score: 0.85}}},
+			{Rankings: model.CategoryRankings{{Category: "Groceries", Score: 0.90}}},
 		}
 
 		// Add multiple entries
 		for i, s := range suggestions {
-			cache.set(string(rune(i)), s)
+			cache.Set(string(rune(i)), s, 0)
 		}
 
 		assert.Equal(t, 3, cache.size())
 
 		// Verify all entries
 		for i, expected := range suggestions {
-			retrieved, found := cache.get(string(rune(i)))
+			retrieved, found := cache.Get(string(rune(i)))
 			require.True(t, found)
 			assert.Equal(t, expected, retrieved)
 		}
@@ -142,11 +129,7 @@ func TestSuggestionCache(t *testing.T) {
 		cache := newSuggestionCache(5 * time.Minute)
 
 		// Add some entries to ensure the cache is active
-		cache.set("test1", service.LLMSuggestion{
-			TransactionID: "test-cleanup",
-			Category:      "Test",
-			Confidence:    0.9,
-		})
+		cache.Set("test1", CachedResponse{Rankings: model.CategoryRankings{{Category: "Test", Score: 0.9}}}, 0)
 
 		// Close the cache
 		cache.Close()
@@ -160,7 +143,157 @@ func TestSuggestionCache(t *testing.T) {
 		}()
 
 		// Try to use cache after close - should not panic
-		_, _ = cache.get("test")
+		_, _ = cache.Get("test")
 		assert.True(t, true, "Cache closed without panic")
 	})
 }
+
+func TestCacheKey(t *testing.T) {
+	key := CacheKey("openai", "gpt-4", "batch/1", "Whole Foods")
+	assert.Len(t, key, 64)
+	assert.Equal(t, key, CacheKey("OpenAI", "gpt-4", "batch/1", "  WHOLE   foods "))
+
+	assert.NotEqual(t, key, CacheKey("anthropic", "gpt-4", "batch/1", "Whole Foods"))
+	assert.NotEqual(t, key, CacheKey("openai", "gpt-4o", "batch/1", "Whole Foods"))
+	assert.NotEqual(t, key, CacheKey("openai", "gpt-4", "batch/2", "Whole Foods"))
+	assert.NotEqual(t, key, CacheKey("openai", "gpt-4", "batch/1", "Whole Foods Market"))
+}
+
+// mapCache is a ResponseCache that never expires, like a persistent cache
+// shared between runs.
+type mapCache struct {
+	entries map[string]CachedResponse
+	mu      sync.Mutex
+}
+
+func (m *mapCache) Get(key string) (CachedResponse, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	resp, found := m.entries[key]
+	return resp, found
+}
+
+func (m *mapCache) Set(key string, resp CachedResponse, _ time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[key] = resp
+}
+
+// countingBatchClient counts batch classification calls.
+type countingBatchClient struct {
+	mockBatchClient
+	calls int
+}
+
+func (c *countingBatchClient) ClassifyMerchantBatch(ctx context.Context, prompt string) (MerchantBatchResponse, error) {
+	c.calls++
+	return c.mockBatchClient.ClassifyMerchantBatch(ctx, prompt)
+}
+
+func TestClassifier_WithCache(t *testing.T) {
+	ctx := context.Background()
+	cache := &mapCache{entries: make(map[string]CachedResponse)}
+	categories := []model.Category{{Name: "Groceries"}, {Name: "Shopping"}}
+	response := MerchantBatchResponse{Classifications: []MerchantClassification{{
+		MerchantID: "Whole Foods",
+		Rankings:   []CategoryRanking{{Category: "Groceries", Score: 0.9}},
+	}}}
+
+	// newRun returns a classifier as a new process would create it, sharing
+	// only the cache
+	newRun := func() (*Classifier, *countingBatchClient) {
+		client := &countingBatchClient{mockBatchClient: mockBatchClient{response: response}}
+		classifier := &Classifier{
+			client:      client,
+			provider:    "openai",
+			model:       "gpt-4",
+			rateLimiter: newRateLimiter(100),
+			logger:      slog.Default(),
+		}
+		WithCache(cache)(classifier)
+		return classifier, client
+	}
+	request := MerchantBatchRequest{MerchantID: "Whole Foods", MerchantName: "Whole Foods"}
+
+	first, firstClient := newRun()
+	results, err := first.SuggestCategoryBatch(ctx, []MerchantBatchRequest{request}, categories)
+	require.NoError(t, err)
+	assert.Equal(t, "Groceries", results["Whole Foods"].Top().Category)
+	assert.Equal(t, 1, firstClient.calls)
+	require.Len(t, cache.entries, 1)
+
+	t.Run("hit in a later run", func(t *testing.T) {
+		second, secondClient := newRun()
+		results, err := second.SuggestCategoryBatch(ctx, []MerchantBatchRequest{request}, categories)
+		require.NoError(t, err)
+		assert.Equal(t, "Groceries", results["Whole Foods"].Top().Category)
+		assert.Equal(t, 0, secondClient.calls)
+	})
+
+	t.Run("deleted category is a miss", func(t *testing.T) {
+		second, secondClient := newRun()
+		_, err := second.SuggestCategoryBatch(ctx, []MerchantBatchRequest{request}, []model.Category{{Name: "Shopping"}})
+		require.NoError(t, err)
+		assert.Equal(t, 1, secondClient.calls)
+	})
+
+	t.Run("hints and previous guesses skip the cache", func(t *testing.T) {
+		hinted := request
+		hinted.Hint = "Sells office supplies too"
+		reranked := request
+		reranked.PreviousCategory = "Groceries"
+		reranked.PreviousConfidence = 0.4
+
+		for _, req := range []MerchantBatchRequest{hinted, reranked} {
+			second, secondClient := newRun()
+			_, err := second.SuggestCategoryBatch(ctx, []MerchantBatchRequest{req}, categories)
+			require.NoError(t, err)
+			assert.Equal(t, 1, secondClient.calls)
+		}
+	})
+
+	t.Run("caller's cache isn't closed", func(t *testing.T) {
+		classifier, err := NewClassifier(Config{Provider: "ollama"}, slog.Default(), WithCache(cache))
+		require.NoError(t, err)
+		assert.Same(t, cache, classifier.cache)
+		require.NoError(t, classifier.Close())
+	})
+}
+
+func TestClassifier_SuggestCategoryRankingsCachesPerTransaction(t *testing.T) {
+	ctx := context.Background()
+	client := &mockClient{responses: []ClassificationResponse{
+		{Category: "Groceries", Confidence: 0.95},
+		{Category: "Shopping", Confidence: 0.6},
+	}}
+	classifier := &Classifier{
+		client:      client,
+		cache:       &mapCache{entries: make(map[string]CachedResponse)},
+		provider:    "openai",
+		model:       "gpt-4",
+		rateLimiter: newRateLimiter(100),
+		logger:      slog.Default(),
+		language:    defaultPromptLanguage(t),
+	}
+	categories := []model.Category{{Name: "Groceries"}, {Name: "Shopping"}}
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	small := model.Transaction{ID: "t1", Hash: "hash1", Name: "COSTCO", MerchantName: "Costco", Amount: 20, Date: date}
+	large := model.Transaction{ID: "t2", Hash: "hash2", Name: "COSTCO", MerchantName: "Costco", Amount: 2000, Date: date}
+
+	// The same merchant's transactions each get their own answer
+	smallRankings, err := classifier.SuggestCategoryRankings(ctx, small, categories, nil)
+	require.NoError(t, err)
+	largeRankings, err := classifier.SuggestCategoryRankings(ctx, large, categories, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, client.calls)
+	assert.Equal(t, "Groceries", smallRankings.Top().Category)
+	assert.InDelta(t, 0.95, smallRankings.Top().Score, 0.0001)
+	assert.Equal(t, "Shopping", largeRankings.Top().Category)
+	assert.InDelta(t, 0.6, largeRankings.Top().Score, 0.0001)
+
+	// A transaction seen before is answered from the cache
+	again, err := classifier.SuggestCategoryRankings(ctx, small, categories, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, client.calls)
+	assert.Equal(t, "Groceries", again.Top().Category)
+}
@@ -144,6 +144,16 @@ func TestNewClassifier(t *testing.T) {
 			wantErr: true,
 			errMsg:  "OpenAI API key is required",
 		},
+		{
+			name: "unsupported language",
+			config: Config{
+				Provider: "openai",
+				APIKey:   "test-key",
+				Language: "klingon",
+			},
+			wantErr: true,
+			errMsg:  "unsupported llm language",
+		},
 	}
 
 	for _, tt := range tests {
@@ -260,7 +270,7 @@ func TestClassifier_SuggestCategory(t *testing.T) {
 			}
 
 			// First call
-			category, confidence, isNew, description, err := classifier.SuggestCategory(ctx, txn, []string{"Groceries", "Entertainment"})
+			category, confidence, isNew, description, err := classifier.SuggestCategory(ctx, txn, []string{"Groceries", "Entertainment", "Coffee & Dining"})
 
 			if tt.expectError {
 				require.Error(t, err)
@@ -275,7 +285,7 @@ func TestClassifier_SuggestCategory(t *testing.T) {
 			// Test cache hit if applicable
 			if tt.name == "cache hit on second call" && !tt.expectError {
 				// Second call should hit cache
-				category2, confidence2, isNew2, description2, err2 := classifier.SuggestCategory(ctx, txn, []string{"Groceries", "Entertainment"})
+				category2, confidence2, isNew2, description2, err2 := classifier.SuggestCategory(ctx, txn, []string{"Groceries", "Entertainment", "Coffee & Dining"})
 				require.NoError(t, err2)
 				assert.Equal(t, category, category2)
 				assert.Equal(t, confidence, confidence2)
@@ -336,7 +346,7 @@ func TestClassifier_BatchSuggestCategories(t *testing.T) {
 		},
 	}
 
-	suggestions, err := classifier.BatchSuggestCategories(ctx, transactions, []string{"Coffee & Dining", "Shopping", "Transportation"})
+	suggestions, err := classifier.BatchSuggestCategories(ctx, transactions, []string{"Coffee & Dining", "Shopping", "Groceries", "Transportation"})
 	require.NoError(t, err)
 	require.Len(t, suggestions, 3)
 
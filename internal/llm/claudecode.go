@@ -420,6 +420,7 @@ func (c *claudeCodeClient) parseMerchantBatchResponse(content string) (MerchantB
 				Score       float64 `json:"score"`
 				IsNew       bool    `json:"isNew"`
 				Description string  `json:"description,omitempty"`
+				Reason      string  `json:"reason,omitempty"`
 			} `json:"rankings"`
 		} `json:"classifications"`
 	}
@@ -438,6 +439,7 @@ func (c *claudeCodeClient) parseMerchantBatchResponse(content string) (MerchantB
 				Score:       r.Score,
 				IsNew:       r.IsNew,
 				Description: r.Description,
+				Reason:      r.Reason,
 			})
 		}
 		classifications = append(classifications, MerchantClassification{
@@ -41,6 +41,7 @@ type RankingResponse struct {
 type CategoryRanking struct {
 	Category    string
 	Description string
+	Reason      string // Why the AI ranked the category, when asked to explain
 	Score       float64
 	IsNew       bool
 }
@@ -56,8 +57,15 @@ type DirectionResponse struct {
 type MerchantBatchRequest struct {
 	MerchantID        string
 	MerchantName      string
+	Hint              string              // Optional note from the user about what this merchant is
+	AdditionalSamples []model.Transaction // Optional extra transactions showing the merchant's range
 	SampleTransaction model.Transaction
 	TransactionCount  int
+	// PreviousCategory and PreviousConfidence describe an earlier low-confidence
+	// classification of the merchant. Set when re-ranking, which asks the model
+	// to reconsider that guess.
+	PreviousCategory   string
+	PreviousConfidence float64
 }
 
 // MerchantBatchResponse contains classification results for multiple merchants.
@@ -1,4 +1,6 @@
 // Package llm provides language model interfaces for transaction classification.
 // It supports multiple LLM providers including OpenAI and Anthropic, with features
-// like retry logic, rate limiting, and response caching.
+// like retry logic, rate limiting, and response caching. Responses are cached in
+// memory by default; pass a ResponseCache to NewClassifier with WithCache to
+// keep them elsewhere.
 package llm
@@ -0,0 +1,185 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/common"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
+)
+
+// DefaultEmbeddingModel is the OpenAI model used for merchant embeddings.
+const DefaultEmbeddingModel = "text-embedding-3-small"
+
+// DefaultEmbeddingBatchSize is the number of texts sent in one embeddings request.
+const DefaultEmbeddingBatchSize = 100
+
+// Embedder turns texts into embedding vectors.
+type Embedder interface {
+	// Embed returns one vector per text, in order, from a single request.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	// EmbedBatch returns one vector per text, in order, sending at most
+	// batchSize texts per request (0 = DefaultEmbeddingBatchSize).
+	EmbedBatch(ctx context.Context, texts []string, batchSize int) ([][]float32, error)
+}
+
+// NewEmbedder creates an embedder for the configured provider. Only OpenAI
+// offers embeddings; cfg.Model selects the embedding model. Requests count
+// against cfg.RequestsPerMinute and cfg.TokensPerMinute together with every
+// classifier of the provider, and are retried like classifier calls.
+func NewEmbedder(cfg Config) (Embedder, error) {
+	switch strings.ToLower(cfg.Provider) {
+	case "openai":
+		return newOpenAIEmbedder(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported embedding provider: %s", cfg.Provider)
+	}
+}
+
+// openAIEmbedder implements Embedder with the OpenAI embeddings API.
+type openAIEmbedder struct {
+	httpClient *http.Client
+	limiter    *providerLimiter // nil = unlimited
+	apiKey     string
+	model      string
+	baseURL    string
+	retryOpts  service.RetryOptions
+}
+
+func newOpenAIEmbedder(cfg Config) (*openAIEmbedder, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("OpenAI API key is required")
+	}
+
+	model := cfg.Model
+	if model == "" {
+		model = DefaultEmbeddingModel
+	}
+
+	return &openAIEmbedder{
+		apiKey:     cfg.APIKey,
+		model:      model,
+		baseURL:    "https://api.openai.com",
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		limiter:    sharedProviderLimiter(cfg.Provider, cfg.RequestsPerMinute, cfg.TokensPerMinute),
+		retryOpts:  retryOptions(cfg),
+	}, nil
+}
+
+// Close closes idle connections to the API.
+func (e *openAIEmbedder) Close() error {
+	e.httpClient.CloseIdleConnections()
+	return nil
+}
+
+// EmbedBatch splits the texts into requests of batchSize.
+func (e *openAIEmbedder) EmbedBatch(ctx context.Context, texts []string, batchSize int) ([][]float32, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultEmbeddingBatchSize
+	}
+
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += batchSize {
+		batch, err := e.Embed(ctx, texts[start:min(start+batchSize, len(texts))])
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, batch...)
+	}
+	return vectors, nil
+}
+
+// Embed sends the texts to OpenAI in a single request, retrying failures
+// other than client errors.
+func (e *openAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	jsonBody, err := json.Marshal(map[string]any{
+		"model": e.model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	tokens := 0
+	for _, text := range texts {
+		tokens += EstimateTokens(text)
+	}
+
+	var vectors [][]float32
+	err = common.WithRetry(ctx, func() error {
+		if e.limiter != nil {
+			if err := e.limiter.wait(ctx, tokens); err != nil {
+				return &common.RetryableError{Err: fmt.Errorf("rate limit error: %w", err), Retryable: false}
+			}
+		}
+
+		var err error
+		vectors, err = e.embed(ctx, jsonBody, len(texts))
+		return err
+	}, e.retryOpts)
+	if err != nil {
+		return nil, fmt.Errorf("embedding failed: %w", err)
+	}
+	return vectors, nil
+}
+
+// embed makes one embeddings request. Client errors other than rate limits
+// and malformed responses are not retryable.
+func (e *openAIEmbedder) embed(ctx context.Context, jsonBody []byte, count int) ([][]float32, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+"/v1/embeddings", bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, &common.RetryableError{Err: fmt.Errorf("failed to create request: %w", err), Retryable: false}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+e.apiKey)
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		err := apiError("OpenAI", resp, body)
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return nil, &common.RetryableError{Err: err, Retryable: false}
+		}
+		return nil, err
+	}
+
+	var response struct {
+		Data []struct {
+			Embedding []float32 `json:"embedding"`
+			Index     int       `json:"index"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, &common.RetryableError{Err: fmt.Errorf("failed to parse response: %w", err), Retryable: false}
+	}
+	if len(response.Data) != count {
+		return nil, &common.RetryableError{Err: fmt.Errorf("expected %d embeddings, got %d", count, len(response.Data)), Retryable: false}
+	}
+
+	vectors := make([][]float32, count)
+	for _, item := range response.Data {
+		if item.Index < 0 || item.Index >= count || vectors[item.Index] != nil {
+			return nil, &common.RetryableError{Err: fmt.Errorf("unexpected embedding index %d", item.Index), Retryable: false}
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	return vectors, nil
+}
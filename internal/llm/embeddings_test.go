@@ -0,0 +1,155 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEmbedder(t *testing.T) {
+	_, err := NewEmbedder(Config{Provider: "anthropic", APIKey: "test-key"})
+	require.Error(t, err)
+
+	_, err = NewEmbedder(Config{Provider: "openai"})
+	require.Error(t, err)
+
+	embedder, err := NewEmbedder(Config{Provider: "openai", APIKey: "test-key"})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultEmbeddingModel, embedder.(*openAIEmbedder).model)
+}
+
+func TestOpenAIEmbedder_Embed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/embeddings", r.URL.Path)
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+
+		var request struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "test-model", request.Model)
+		assert.Equal(t, []string{"Starbucks", "Shell"}, request.Input)
+
+		// Returned out of order; Embed must reorder by index
+		_, _ = w.Write([]byte(`{"data": [
+			{"index": 1, "embedding": [0, 1]},
+			{"index": 0, "embedding": [1, 0]}
+		]}`))
+	}))
+	defer server.Close()
+
+	embedder := &openAIEmbedder{
+		apiKey:     "test-key",
+		model:      "test-model",
+		baseURL:    server.URL,
+		httpClient: server.Client(),
+	}
+	defer func() { _ = embedder.Close() }()
+
+	vectors, err := embedder.Embed(context.Background(), []string{"Starbucks", "Shell"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{1, 0}, {0, 1}}, vectors)
+
+	vectors, err = embedder.Embed(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Nil(t, vectors)
+}
+
+func TestOpenAIEmbedder_EmbedError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error": "invalid key"}`))
+	}))
+	defer server.Close()
+
+	embedder := &openAIEmbedder{apiKey: "bad", model: "m", baseURL: server.URL, httpClient: server.Client()}
+	defer func() { _ = embedder.Close() }()
+	_, err := embedder.Embed(context.Background(), []string{"Starbucks"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 401")
+	assert.NotContains(t, err.Error(), "attempts", "a client error isn't retried")
+}
+
+func TestOpenAIEmbedder_EmbedRetries(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error": "slow down"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data": [{"index": 0, "embedding": [1, 0]}]}`))
+	}))
+	defer server.Close()
+
+	embedder := &openAIEmbedder{
+		apiKey:     "k",
+		model:      "m",
+		baseURL:    server.URL,
+		httpClient: server.Client(),
+		retryOpts:  retryOptions(Config{MaxRetries: 2, RetryDelay: time.Millisecond}),
+	}
+	defer func() { _ = embedder.Close() }()
+
+	vectors, err := embedder.Embed(context.Background(), []string{"Starbucks"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{1, 0}}, vectors)
+	assert.Equal(t, 2, calls)
+}
+
+func TestNewEmbedder_SharesProviderLimiter(t *testing.T) {
+	cfg := Config{Provider: "openai", APIKey: "test-key", RequestsPerMinute: 123, TokensPerMinute: 4567}
+
+	embedder, err := NewEmbedder(cfg)
+	require.NoError(t, err)
+	defer func() { _ = embedder.(*openAIEmbedder).Close() }()
+
+	client := withProviderLimits(&mockClient{}, cfg)
+	require.IsType(t, &rateLimitedClient{}, client)
+	assert.Same(t, client.(*rateLimitedClient).limiter, embedder.(*openAIEmbedder).limiter)
+
+	unlimited, err := NewEmbedder(Config{Provider: "openai", APIKey: "test-key"})
+	require.NoError(t, err)
+	assert.Nil(t, unlimited.(*openAIEmbedder).limiter)
+}
+
+func TestOpenAIEmbedder_EmbedBatch(t *testing.T) {
+	var requests [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Input []string `json:"input"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		requests = append(requests, request.Input)
+
+		type item struct {
+			Embedding []float32 `json:"embedding"`
+			Index     int       `json:"index"`
+		}
+		response := struct {
+			Data []item `json:"data"`
+		}{}
+		for i, text := range request.Input {
+			response.Data = append(response.Data, item{Embedding: []float32{float32(len(text))}, Index: i})
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	embedder := &openAIEmbedder{apiKey: "k", model: "m", baseURL: server.URL, httpClient: server.Client()}
+	defer func() { _ = embedder.Close() }()
+
+	vectors, err := embedder.EmbedBatch(context.Background(), []string{"a", "bb", "ccc", "dddd", "eeeee"}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"a", "bb"}, {"ccc", "dddd"}, {"eeeee"}}, requests)
+	assert.Equal(t, [][]float32{{1}, {2}, {3}, {4}, {5}}, vectors)
+}
@@ -0,0 +1,63 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	assert.Equal(t, 0, EstimateTokens(""))
+	assert.Equal(t, 1, EstimateTokens("abc"))
+	assert.Equal(t, 2, EstimateTokens("abcde"))
+}
+
+func TestEstimateBatchTokens(t *testing.T) {
+	categories := []model.Category{{Name: "Groceries", Description: "Food for home"}, {Name: "Dining", Description: "Restaurants"}}
+	requests := []MerchantBatchRequest{
+		{MerchantID: "walmart", MerchantName: "Walmart", SampleTransaction: model.Transaction{Name: "WALMART #1", Amount: 50}, TransactionCount: 2},
+		{MerchantID: "chipotle", MerchantName: "Chipotle", SampleTransaction: model.Transaction{Name: "CHIPOTLE 123", Amount: 12}, TransactionCount: 1},
+	}
+
+	empty, err := EstimateBatchTokens(Config{}, nil, categories)
+	require.NoError(t, err)
+	assert.Equal(t, TokenEstimate{}, empty)
+
+	estimate, err := EstimateBatchTokens(Config{}, requests, categories)
+	require.NoError(t, err)
+	assert.Equal(t, 1, estimate.Calls)
+	prompt := (&Classifier{language: defaultPromptLanguage(t)}).buildBatchPrompt(requests, categories)
+	assert.Equal(t, batchSystemPromptTokens+EstimateTokens(prompt), estimate.PromptTokens)
+	assert.Positive(t, estimate.CompletionTokens)
+
+	fewer, err := EstimateBatchTokens(Config{TopN: 1}, requests, categories)
+	require.NoError(t, err)
+	assert.Less(t, fewer.CompletionTokens, estimate.CompletionTokens)
+
+	explained, err := EstimateBatchTokens(Config{Explain: true}, requests, categories)
+	require.NoError(t, err)
+	assert.Greater(t, explained.PromptTokens, estimate.PromptTokens)
+	assert.Greater(t, explained.CompletionTokens, estimate.CompletionTokens)
+
+	_, err = EstimateBatchTokens(Config{Language: "xx"}, requests, categories)
+	assert.Error(t, err)
+
+	total := estimate.Add(explained)
+	assert.Equal(t, 2, total.Calls)
+	assert.Equal(t, estimate.PromptTokens+explained.PromptTokens, total.PromptTokens)
+}
+
+func TestModelPrice_Cost(t *testing.T) {
+	price := ModelPrice{InputPerMillion: 10, OutputPerMillion: 30}
+	assert.InDelta(t, 0.025, price.Cost(TokenEstimate{PromptTokens: 1000, CompletionTokens: 500}), 1e-9)
+	assert.Zero(t, ModelPrice{}.Cost(TokenEstimate{PromptTokens: 1000}))
+}
+
+func defaultPromptLanguage(t *testing.T) promptLanguage {
+	t.Helper()
+	language, err := lookupLanguage("")
+	require.NoError(t, err)
+	return language
+}
@@ -8,16 +8,26 @@ import (
 // NewClient creates a raw LLM client based on the provided configuration.
 // This is primarily used for analysis operations that need direct access to the LLM.
 func NewClient(cfg Config) (Client, error) {
+	var client Client
+	var err error
 	switch strings.ToLower(cfg.Provider) {
 	case "openai":
-		return newOpenAIClient(cfg)
+		client, err = newOpenAIClient(cfg)
 	case "anthropic":
-		return newAnthropicClient(cfg)
+		client, err = newAnthropicClient(cfg)
 	case "claudecode":
-		return newClaudeCodeClient(cfg)
+		client, err = newClaudeCodeClient(cfg)
+	case "gemini":
+		client, err = newGeminiClient(cfg)
+	case "ollama":
+		client, err = newOllamaClient(cfg)
 	default:
 		return nil, fmt.Errorf("unsupported LLM provider: %s", cfg.Provider)
 	}
+	if err != nil {
+		return nil, err
+	}
+	return withProviderLimits(client, cfg), nil
 }
 
 // NewSessionClient creates a session-capable LLM client if the provider supports it.
@@ -0,0 +1,347 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// geminiClient implements the Client interface for the Google Gemini API.
+type geminiClient struct {
+	httpClient  *http.Client
+	apiKey      string
+	model       string
+	baseURL     string
+	temperature float64
+	maxTokens   int
+}
+
+// newGeminiClient creates a new Gemini API client.
+func newGeminiClient(cfg Config) (Client, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("gemini API key is required")
+	}
+
+	model := cfg.Model
+	if model == "" {
+		model = "gemini-1.5-flash"
+	}
+
+	temperature := cfg.Temperature
+	if temperature == 0 {
+		temperature = 0.3
+	}
+
+	maxTokens := cfg.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = 150
+	}
+
+	return &geminiClient{
+		apiKey:      cfg.APIKey,
+		model:       model,
+		baseURL:     "https://generativelanguage.googleapis.com",
+		temperature: temperature,
+		maxTokens:   maxTokens,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				MaxIdleConns:        100,
+				MaxIdleConnsPerHost: 10,
+				IdleConnTimeout:     90 * time.Second,
+			},
+		},
+	}, nil
+}
+
+// geminiResponse represents the Gemini generateContent response structure.
+type geminiResponse struct {
+	Candidates []struct {
+		Content struct {
+			Parts []struct {
+				Text    string `json:"text"`
+				Thought bool   `json:"thought,omitempty"`
+			} `json:"parts"`
+		} `json:"content"`
+		FinishReason string `json:"finishReason"`
+	} `json:"candidates"`
+	PromptFeedback struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback"`
+}
+
+// generate sends a prompt to Gemini and returns the text of the first
+// candidate. When jsonOutput is set, Gemini is asked for a JSON response.
+func (c *geminiClient) generate(ctx context.Context, systemPrompt, prompt string, maxTokens int, jsonOutput bool) (string, error) {
+	generationConfig := map[string]any{
+		"temperature":     c.temperature,
+		"maxOutputTokens": maxTokens,
+	}
+	if jsonOutput {
+		generationConfig["responseMimeType"] = "application/json"
+	}
+
+	requestBody := map[string]any{
+		"contents": []map[string]any{
+			{
+				"role":  "user",
+				"parts": []map[string]string{{"text": prompt}},
+			},
+		},
+		"generationConfig": generationConfig,
+	}
+	if systemPrompt != "" {
+		requestBody["systemInstruction"] = map[string]any{
+			"parts": []map[string]string{{"text": systemPrompt}},
+		}
+	}
+
+	jsonBody, err := json.Marshal(requestBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/v1beta/models/%s:generateContent", c.baseURL, url.PathEscape(c.model))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(jsonBody))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-goog-api-key", c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", apiError("gemini", resp, body)
+	}
+
+	var response geminiResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if response.PromptFeedback.BlockReason != "" {
+		return "", fmt.Errorf("gemini blocked the prompt: %s", response.PromptFeedback.BlockReason)
+	}
+	if len(response.Candidates) == 0 {
+		return "", fmt.Errorf("no candidates in response")
+	}
+
+	// Thinking models return their thoughts as separate parts; only the
+	// answer is kept
+	var text strings.Builder
+	for _, part := range response.Candidates[0].Content.Parts {
+		if !part.Thought {
+			text.WriteString(part.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return "", fmt.Errorf("no content in response (finish reason %s)", response.Candidates[0].FinishReason)
+	}
+
+	return text.String(), nil
+}
+
+// Classify sends a classification request to Gemini.
+func (c *geminiClient) Classify(ctx context.Context, prompt string) (ClassificationResponse, error) {
+	systemPrompt := "You are a financial transaction classifier. You MUST respond with ONLY a valid JSON object. Do not include any explanatory text, markdown formatting, or commentary before or after the JSON. Start your response directly with { and end with }."
+
+	content, err := c.generate(ctx, systemPrompt, prompt, c.maxTokens, true)
+	if err != nil {
+		return ClassificationResponse{}, err
+	}
+
+	return c.parseClassification(content)
+}
+
+// parseClassification extracts category and confidence from the LLM response.
+func (c *geminiClient) parseClassification(content string) (ClassificationResponse, error) {
+	var jsonResp struct {
+		Category    string  `json:"category"`
+		Confidence  float64 `json:"confidence"`
+		IsNew       bool    `json:"isNew"`
+		Description string  `json:"description,omitempty"`
+	}
+
+	content = cleanMarkdownWrapper(content)
+
+	if err := json.Unmarshal([]byte(content), &jsonResp); err != nil {
+		return ClassificationResponse{}, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+
+	if jsonResp.Category == "" {
+		return ClassificationResponse{}, fmt.Errorf("no category found in response")
+	}
+
+	return ClassificationResponse{
+		Category:            jsonResp.Category,
+		Confidence:          jsonResp.Confidence,
+		IsNew:               jsonResp.IsNew,
+		CategoryDescription: jsonResp.Description,
+	}, nil
+}
+
+// ClassifyWithRankings sends a ranking classification request to Gemini.
+func (c *geminiClient) ClassifyWithRankings(ctx context.Context, prompt string) (RankingResponse, error) {
+	systemPrompt := "You are a financial transaction classifier. You MUST respond with ONLY a valid JSON object containing rankings. Do not include any explanatory text, markdown formatting, or commentary before or after the JSON. Start your response directly with { and end with }."
+
+	// More tokens needed for ranking all categories
+	content, err := c.generate(ctx, systemPrompt, prompt, c.maxTokens*3, true)
+	if err != nil {
+		return RankingResponse{}, err
+	}
+
+	return c.parseRankings(content)
+}
+
+// parseRankings parses the rankings of a ranking classification response.
+func (c *geminiClient) parseRankings(content string) (RankingResponse, error) {
+	var jsonResp struct {
+		Rankings []struct {
+			Category string  `json:"category"`
+			Score    float64 `json:"score"`
+		} `json:"rankings"`
+		NewCategory *struct {
+			Name        string  `json:"name"`
+			Score       float64 `json:"score"`
+			Description string  `json:"description"`
+		} `json:"newCategory,omitempty"`
+	}
+
+	content = cleanMarkdownWrapper(content)
+
+	if err := json.Unmarshal([]byte(content), &jsonResp); err != nil {
+		return RankingResponse{}, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+
+	var rankings []CategoryRanking
+	for _, r := range jsonResp.Rankings {
+		rankings = append(rankings, CategoryRanking{
+			Category: r.Category,
+			Score:    r.Score,
+		})
+	}
+
+	// Add new category if present
+	if jsonResp.NewCategory != nil {
+		rankings = append(rankings, CategoryRanking{
+			Category:    jsonResp.NewCategory.Name,
+			Score:       jsonResp.NewCategory.Score,
+			IsNew:       true,
+			Description: jsonResp.NewCategory.Description,
+		})
+	}
+
+	return RankingResponse{Rankings: rankings}, nil
+}
+
+// GenerateDescription generates a description for a category.
+func (c *geminiClient) GenerateDescription(ctx context.Context, prompt string) (DescriptionResponse, error) {
+	systemPrompt := "You are a financial category description generator. You MUST respond with ONLY a valid JSON object. Do not include any explanatory text, markdown formatting, or commentary before or after the JSON. Start your response directly with { and end with }."
+
+	content, err := c.generate(ctx, systemPrompt, prompt, c.maxTokens, true)
+	if err != nil {
+		return DescriptionResponse{}, err
+	}
+
+	var descResp struct {
+		Description string  `json:"description"`
+		Confidence  float64 `json:"confidence"`
+	}
+
+	content = cleanMarkdownWrapper(content)
+
+	if err := json.Unmarshal([]byte(content), &descResp); err != nil {
+		return DescriptionResponse{}, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+
+	return DescriptionResponse{
+		Description: descResp.Description,
+		Confidence:  descResp.Confidence,
+	}, nil
+}
+
+// ClassifyMerchantBatch classifies multiple merchants in a single API call.
+func (c *geminiClient) ClassifyMerchantBatch(ctx context.Context, prompt string) (MerchantBatchResponse, error) {
+	systemPrompt := "You are a financial transaction classifier. You MUST respond with ONLY a valid JSON object containing merchant classifications. Do not include any explanatory text, markdown formatting, or commentary before or after the JSON. Start your response directly with { and end with }."
+
+	// More tokens needed for batch response
+	content, err := c.generate(ctx, systemPrompt, prompt, c.maxTokens*10, true)
+	if err != nil {
+		return MerchantBatchResponse{}, err
+	}
+
+	return c.parseMerchantBatchResponse(content)
+}
+
+// parseMerchantBatchResponse parses the batch classification response.
+func (c *geminiClient) parseMerchantBatchResponse(content string) (MerchantBatchResponse, error) {
+	var jsonResp struct {
+		Classifications []struct {
+			MerchantID string `json:"merchantId"`
+			Rankings   []struct {
+				Category    string  `json:"category"`
+				Score       float64 `json:"score"`
+				IsNew       bool    `json:"isNew"`
+				Description string  `json:"description,omitempty"`
+				Reason      string  `json:"reason,omitempty"`
+			} `json:"rankings"`
+		} `json:"classifications"`
+	}
+
+	content = cleanMarkdownWrapper(content)
+
+	if err := json.Unmarshal([]byte(content), &jsonResp); err != nil {
+		return MerchantBatchResponse{}, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+
+	classifications := make([]MerchantClassification, 0, len(jsonResp.Classifications))
+	for _, c := range jsonResp.Classifications {
+		rankings := make([]CategoryRanking, 0, len(c.Rankings))
+		for _, r := range c.Rankings {
+			rankings = append(rankings, CategoryRanking{
+				Category:    r.Category,
+				Score:       r.Score,
+				IsNew:       r.IsNew,
+				Description: r.Description,
+				Reason:      r.Reason,
+			})
+		}
+		classifications = append(classifications, MerchantClassification{
+			MerchantID: c.MerchantID,
+			Rankings:   rankings,
+		})
+	}
+
+	return MerchantBatchResponse{
+		Classifications: classifications,
+	}, nil
+}
+
+// Analyze performs general-purpose AI analysis and returns raw response text.
+func (c *geminiClient) Analyze(ctx context.Context, prompt string, systemPrompt string) (string, error) {
+	// Use higher token limit for analysis
+	maxTokens := 4000
+	if c.maxTokens > 4000 {
+		maxTokens = c.maxTokens
+	}
+
+	// Return raw content without any parsing
+	return c.generate(ctx, systemPrompt, prompt, maxTokens, false)
+}
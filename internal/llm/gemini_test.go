@@ -0,0 +1,185 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGeminiClient(t *testing.T) {
+	_, err := newGeminiClient(Config{})
+	require.ErrorContains(t, err, "gemini API key is required")
+
+	client, err := newGeminiClient(Config{APIKey: "test-key"})
+	require.NoError(t, err)
+	assert.Equal(t, "gemini-1.5-flash", client.(*geminiClient).model)
+
+	classifier, err := NewClassifier(Config{Provider: "gemini", APIKey: "test-key", Model: "gemini-1.5-pro"}, slog.Default())
+	require.NoError(t, err)
+	assert.Equal(t, "gemini-1.5-pro", classifier.client.(*geminiClient).model)
+	require.NoError(t, classifier.Close())
+}
+
+// newTestGeminiClient returns a Gemini client whose requests are answered
+// with text by a test server. Each request body is passed to inspect.
+func newTestGeminiClient(t *testing.T, text string, inspect func(body map[string]any)) *geminiClient {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1beta/models/gemini-test:generateContent", r.URL.Path)
+		assert.Equal(t, "test-key", r.Header.Get("x-goog-api-key"))
+
+		data, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		var body map[string]any
+		assert.NoError(t, json.Unmarshal(data, &body))
+		if inspect != nil {
+			inspect(body)
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"candidates": []map[string]any{{
+				"content": map[string]any{"parts": []map[string]any{
+					{"text": "Checking the merchant names first.", "thought": true},
+					{"text": text},
+				}},
+				"finishReason": "STOP",
+			}},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	return &geminiClient{
+		httpClient:  server.Client(),
+		apiKey:      "test-key",
+		model:       "gemini-test",
+		baseURL:     server.URL,
+		temperature: 0.3,
+		maxTokens:   150,
+	}
+}
+
+func TestGeminiClient_Requests(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("classify asks for JSON", func(t *testing.T) {
+		client := newTestGeminiClient(t, `{"category": "Groceries", "confidence": 0.92, "isNew": false}`, func(body map[string]any) {
+			config := body["generationConfig"].(map[string]any)
+			assert.Equal(t, "application/json", config["responseMimeType"])
+			assert.InDelta(t, 150, config["maxOutputTokens"], 0)
+			assert.Contains(t, body, "systemInstruction")
+		})
+
+		response, err := client.Classify(ctx, "classify this")
+		require.NoError(t, err)
+		assert.Equal(t, "Groceries", response.Category)
+		assert.InDelta(t, 0.92, response.Confidence, 0.001)
+	})
+
+	t.Run("rankings in a code fence", func(t *testing.T) {
+		client := newTestGeminiClient(t, "```json\n{\"rankings\": [{\"category\": \"Dining\", \"score\": 0.8}], \"newCategory\": {\"name\": \"Coffee\", \"score\": 0.6, \"description\": \"Coffee shops\"}}\n```", nil)
+
+		response, err := client.ClassifyWithRankings(ctx, "rank this")
+		require.NoError(t, err)
+		require.Len(t, response.Rankings, 2)
+		assert.Equal(t, "Dining", response.Rankings[0].Category)
+		assert.True(t, response.Rankings[1].IsNew)
+		assert.Equal(t, "Coffee shops", response.Rankings[1].Description)
+	})
+
+	t.Run("analyze returns raw text", func(t *testing.T) {
+		client := newTestGeminiClient(t, "Plain analysis", func(body map[string]any) {
+			config := body["generationConfig"].(map[string]any)
+			assert.NotContains(t, config, "responseMimeType")
+			assert.InDelta(t, 4000, config["maxOutputTokens"], 0)
+		})
+
+		response, err := client.Analyze(ctx, "analyze this", "system")
+		require.NoError(t, err)
+		assert.Equal(t, "Plain analysis", response)
+	})
+
+	t.Run("API errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error": {"code": 429, "message": "Resource has been exhausted"}}`))
+		}))
+		defer server.Close()
+
+		client := &geminiClient{httpClient: server.Client(), apiKey: "test-key", model: "gemini-test", baseURL: server.URL}
+		_, err := client.Classify(ctx, "classify this")
+		assert.ErrorContains(t, err, "gemini API error (status 429)")
+	})
+
+	t.Run("blocked prompt", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"promptFeedback": {"blockReason": "SAFETY"}}`))
+		}))
+		defer server.Close()
+
+		client := &geminiClient{httpClient: server.Client(), apiKey: "test-key", model: "gemini-test", baseURL: server.URL}
+		_, err := client.ClassifyMerchantBatch(ctx, "classify these")
+		assert.ErrorContains(t, err, "gemini blocked the prompt: SAFETY")
+	})
+}
+
+func TestGeminiClient_ParseMerchantBatchResponse(t *testing.T) {
+	client := &geminiClient{}
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "plain JSON", content: `{"classifications": [{"merchantId": "starbucks", "rankings": [{"category": "Coffee Shops", "score": 0.95, "reason": "Coffee chain"}]}]}`},
+		{name: "json code fence", content: "```json\n{\"classifications\": [{\"merchantId\": \"starbucks\", \"rankings\": [{\"category\": \"Coffee Shops\", \"score\": 0.95, \"reason\": \"Coffee chain\"}]}]}\n```"},
+		{name: "bare code fence", content: "```\n{\"classifications\": [{\"merchantId\": \"starbucks\", \"rankings\": [{\"category\": \"Coffee Shops\", \"score\": 0.95, \"reason\": \"Coffee chain\"}]}]}\n```"},
+		{name: "code fence with text around it", content: "Here are the classifications:\n\n```json\n{\"classifications\": [{\"merchantId\": \"starbucks\", \"rankings\": [{\"category\": \"Coffee Shops\", \"score\": 0.95, \"reason\": \"Coffee chain\"}]}]}\n```\n\nLet me know if you need anything else."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := client.parseMerchantBatchResponse(tt.content)
+			require.NoError(t, err)
+			require.Len(t, response.Classifications, 1)
+			assert.Equal(t, "starbucks", response.Classifications[0].MerchantID)
+			require.Len(t, response.Classifications[0].Rankings, 1)
+			assert.Equal(t, "Coffee Shops", response.Classifications[0].Rankings[0].Category)
+			assert.Equal(t, "Coffee chain", response.Classifications[0].Rankings[0].Reason)
+		})
+	}
+
+	_, err := client.parseMerchantBatchResponse("I can't classify these merchants.")
+	assert.ErrorContains(t, err, "failed to parse JSON response")
+}
+
+func TestClassifier_SuggestCategoryBatch_Gemini(t *testing.T) {
+	client := newTestGeminiClient(t, "```json\n{\"classifications\": [{\"merchantId\": \"m1\", \"rankings\": [{\"category\": \"Groceries\", \"score\": 0.9, \"isNew\": false}]}]}\n```", nil)
+	classifier := &Classifier{
+		client:      client,
+		cache:       newSuggestionCache(time.Hour),
+		rateLimiter: newRateLimiter(100),
+		logger:      slog.Default(),
+	}
+
+	requests := []MerchantBatchRequest{{
+		MerchantID:        "m1",
+		MerchantName:      "Whole Foods",
+		SampleTransaction: model.Transaction{ID: "tx1", Hash: "hash1", Name: "WHOLE FOODS"},
+		TransactionCount:  1,
+	}}
+	results, err := classifier.SuggestCategoryBatch(context.Background(), requests, []model.Category{{Name: "Groceries"}})
+	require.NoError(t, err)
+	require.Len(t, results["m1"], 1)
+	assert.Equal(t, "Groceries", results["m1"][0].Category)
+
+	cached, found := classifier.cache.Get(classifier.cacheKey(batchPromptVersion, "m1"))
+	require.True(t, found)
+	assert.Equal(t, "Groceries", cached.Rankings.Top().Category)
+}
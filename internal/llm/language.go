@@ -0,0 +1,88 @@
+package llm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultLanguage is the prompt language used when none is configured.
+const DefaultLanguage = "en"
+
+// promptLanguage holds the localized instructions added to every prompt.
+type promptLanguage struct {
+	name         string
+	instructions string
+}
+
+// promptLanguages lists the ready-made prompt languages. English needs no
+// extra instructions because the base prompts are written in English.
+// JSON keys and the example response format stay in English for every
+// language so the response parser keeps working.
+var promptLanguages = map[string]promptLanguage{
+	"en": {name: "English"},
+	"de": {
+		name: "German",
+		instructions: `SPRACHE:
+- Händlernamen, Buchungstexte und Kategorien sind auf Deutsch.
+- Verwende die vorhandenen Kategorienamen exakt wie angegeben und übersetze sie nicht.
+- Schreibe neue Kategorienamen und Beschreibungen auf Deutsch.
+- Die JSON-Schlüssel bleiben unverändert auf Englisch.`,
+	},
+	"es": {
+		name: "Spanish",
+		instructions: `IDIOMA:
+- Los nombres de comercios, las descripciones de los movimientos y las categorías están en español.
+- Usa los nombres de categoría existentes exactamente como aparecen y no los traduzcas.
+- Escribe en español los nombres y descripciones de las categorías nuevas.
+- Las claves JSON se mantienen en inglés sin cambios.`,
+	},
+	"fr": {
+		name: "French",
+		instructions: `LANGUE :
+- Les noms de commerçants, les libellés des opérations et les catégories sont en français.
+- Utilise les noms de catégories existants exactement tels qu'ils sont écrits, sans les traduire.
+- Rédige en français les noms et descriptions des nouvelles catégories.
+- Les clés JSON restent en anglais, sans modification.`,
+	},
+	"nl": {
+		name: "Dutch",
+		instructions: `TAAL:
+- Namen van winkels, omschrijvingen van transacties en categorieën zijn in het Nederlands.
+- Gebruik de bestaande categorienamen exact zoals ze zijn opgegeven en vertaal ze niet.
+- Schrijf namen en beschrijvingen van nieuwe categorieën in het Nederlands.
+- De JSON-sleutels blijven ongewijzigd in het Engels.`,
+	},
+}
+
+// SupportedLanguages returns the language codes accepted by Config.Language.
+func SupportedLanguages() []string {
+	codes := make([]string, 0, len(promptLanguages))
+	for code := range promptLanguages {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// lookupLanguage resolves a configured language code, defaulting to English.
+func lookupLanguage(code string) (promptLanguage, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if code == "" {
+		code = DefaultLanguage
+	}
+	lang, ok := promptLanguages[code]
+	if !ok {
+		return promptLanguage{}, fmt.Errorf("unsupported llm language %q (supported: %s)",
+			code, strings.Join(SupportedLanguages(), ", "))
+	}
+	return lang, nil
+}
+
+// localize prepends the language instructions to a prompt.
+func (l promptLanguage) localize(prompt string) string {
+	if l.instructions == "" {
+		return prompt
+	}
+	return l.instructions + "\n\n" + prompt
+}
@@ -0,0 +1,58 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+func TestLookupLanguage(t *testing.T) {
+	lang, err := lookupLanguage("")
+	require.NoError(t, err)
+	assert.Equal(t, "English", lang.name)
+
+	lang, err = lookupLanguage(" DE ")
+	require.NoError(t, err)
+	assert.Equal(t, "German", lang.name)
+
+	_, err = lookupLanguage("xx")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "de, en, es, fr, nl")
+}
+
+func TestLocalizedPrompts(t *testing.T) {
+	german, err := lookupLanguage("de")
+	require.NoError(t, err)
+	classifier := &Classifier{language: german}
+
+	txn := model.Transaction{
+		MerchantName: "Edeka",
+		Name:         "EDEKA MARKT 1234",
+		Amount:       42.10,
+		Date:         time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC),
+	}
+	categories := []model.Category{{Name: "Lebensmittel", Description: "Einkäufe im Supermarkt"}}
+
+	prompts := map[string]string{
+		"single":  classifier.buildPrompt(txn, []string{"Lebensmittel"}),
+		"ranking": classifier.buildPromptWithRanking(txn, categories, nil),
+		"batch": classifier.buildBatchPrompt([]MerchantBatchRequest{
+			{MerchantID: "edeka", MerchantName: "Edeka", SampleTransaction: txn, TransactionCount: 1},
+		}, categories),
+	}
+	for name, prompt := range prompts {
+		t.Run(name, func(t *testing.T) {
+			assert.True(t, strings.HasPrefix(prompt, "SPRACHE:"), "instructions come first")
+			assert.Contains(t, prompt, "Schreibe neue Kategorienamen und Beschreibungen auf Deutsch")
+			assert.Contains(t, prompt, "Lebensmittel", "category names are passed through unchanged")
+		})
+	}
+
+	english := (&Classifier{}).buildPrompt(txn, []string{"Lebensmittel"})
+	assert.NotContains(t, english, "SPRACHE:")
+}
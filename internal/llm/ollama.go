@@ -0,0 +1,376 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Defaults for the local Ollama backend. Local models are much slower than
+// the cloud providers, so requests get a longer timeout.
+const (
+	DefaultOllamaBaseURL = "http://localhost:11434"
+	DefaultOllamaModel   = "llama3"
+	DefaultOllamaTimeout = 5 * time.Minute
+)
+
+// ollamaClient implements the Client interface for a local Ollama server.
+type ollamaClient struct {
+	httpClient  *http.Client
+	model       string
+	baseURL     string
+	temperature float64
+	maxTokens   int
+}
+
+// newOllamaClient creates a new Ollama client. No API key is needed.
+func newOllamaClient(cfg Config) (Client, error) {
+	model := cfg.Model
+	if model == "" {
+		model = DefaultOllamaModel
+	}
+
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultOllamaBaseURL
+	}
+
+	temperature := cfg.Temperature
+	if temperature == 0 {
+		temperature = 0.3
+	}
+
+	maxTokens := cfg.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = 150
+	}
+
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = DefaultOllamaTimeout
+	}
+
+	return &ollamaClient{
+		model:       model,
+		baseURL:     baseURL,
+		temperature: temperature,
+		maxTokens:   maxTokens,
+		httpClient:  &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// ollamaChatChunk is a /api/chat response. A streamed response is a sequence
+// of chunks, one JSON object per line, ending with one where Done is set; a
+// non-streamed response is a single chunk.
+type ollamaChatChunk struct {
+	Message struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	} `json:"message"`
+	Error string `json:"error,omitempty"`
+	Done  bool   `json:"done"`
+}
+
+// chat sends a prompt to Ollama's /api/chat endpoint and returns the model's
+// reply. When jsonOutput is set, Ollama is told to answer in JSON.
+func (c *ollamaClient) chat(ctx context.Context, systemPrompt, prompt string, maxTokens int, jsonOutput bool) (string, error) {
+	messages := make([]map[string]string, 0, 2)
+	if systemPrompt != "" {
+		messages = append(messages, map[string]string{"role": "system", "content": systemPrompt})
+	}
+	messages = append(messages, map[string]string{"role": "user", "content": prompt})
+
+	requestBody := map[string]any{
+		"model":    c.model,
+		"messages": messages,
+		"stream":   false,
+		"options": map[string]any{
+			"temperature": c.temperature,
+			"num_predict": maxTokens,
+		},
+	}
+	if jsonOutput {
+		requestBody["format"] = "json"
+	}
+
+	jsonBody, err := json.Marshal(requestBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/chat", bytes.NewReader(jsonBody))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("ollama API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	return readOllamaChat(resp.Body)
+}
+
+// readOllamaChat joins the message content of a streamed or non-streamed
+// /api/chat response.
+func readOllamaChat(r io.Reader) (string, error) {
+	var content strings.Builder
+	decoder := json.NewDecoder(r)
+	for {
+		var chunk ollamaChatChunk
+		if err := decoder.Decode(&chunk); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return "", fmt.Errorf("failed to parse response: %w", err)
+		}
+		if chunk.Error != "" {
+			return "", fmt.Errorf("ollama error: %s", chunk.Error)
+		}
+		content.WriteString(chunk.Message.Content)
+		if chunk.Done {
+			break
+		}
+	}
+
+	if content.Len() == 0 {
+		return "", fmt.Errorf("no content in response")
+	}
+	return content.String(), nil
+}
+
+// Classify sends a classification request to Ollama.
+func (c *ollamaClient) Classify(ctx context.Context, prompt string) (ClassificationResponse, error) {
+	systemPrompt := "You are a financial transaction classifier. You MUST respond with ONLY a valid JSON object. Do not include any explanatory text, markdown formatting, or commentary before or after the JSON. Start your response directly with { and end with }."
+
+	content, err := c.chat(ctx, systemPrompt, prompt, c.maxTokens, true)
+	if err != nil {
+		return ClassificationResponse{}, err
+	}
+
+	var jsonResp struct {
+		Category    string  `json:"category"`
+		Confidence  float64 `json:"confidence"`
+		IsNew       bool    `json:"isNew"`
+		Description string  `json:"description,omitempty"`
+	}
+
+	content = cleanMarkdownWrapper(content)
+
+	if err := json.Unmarshal([]byte(content), &jsonResp); err != nil {
+		return ClassificationResponse{}, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+
+	if jsonResp.Category == "" {
+		return ClassificationResponse{}, fmt.Errorf("no category found in response")
+	}
+
+	return ClassificationResponse{
+		Category:            jsonResp.Category,
+		Confidence:          jsonResp.Confidence,
+		IsNew:               jsonResp.IsNew,
+		CategoryDescription: jsonResp.Description,
+	}, nil
+}
+
+// ClassifyWithRankings sends a ranking classification request to Ollama.
+func (c *ollamaClient) ClassifyWithRankings(ctx context.Context, prompt string) (RankingResponse, error) {
+	systemPrompt := "You are a financial transaction classifier. You MUST respond with ONLY a valid JSON object containing rankings. Do not include any explanatory text, markdown formatting, or commentary before or after the JSON. Start your response directly with { and end with }."
+
+	// More tokens needed for ranking all categories
+	content, err := c.chat(ctx, systemPrompt, prompt, c.maxTokens*3, true)
+	if err != nil {
+		return RankingResponse{}, err
+	}
+
+	var jsonResp struct {
+		Rankings []struct {
+			Category string  `json:"category"`
+			Score    float64 `json:"score"`
+		} `json:"rankings"`
+		NewCategory *struct {
+			Name        string  `json:"name"`
+			Score       float64 `json:"score"`
+			Description string  `json:"description"`
+		} `json:"newCategory,omitempty"`
+	}
+
+	content = cleanMarkdownWrapper(content)
+
+	if err := json.Unmarshal([]byte(content), &jsonResp); err != nil {
+		return RankingResponse{}, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+
+	var rankings []CategoryRanking
+	for _, r := range jsonResp.Rankings {
+		rankings = append(rankings, CategoryRanking{
+			Category: r.Category,
+			Score:    r.Score,
+		})
+	}
+
+	// Add new category if present
+	if jsonResp.NewCategory != nil {
+		rankings = append(rankings, CategoryRanking{
+			Category:    jsonResp.NewCategory.Name,
+			Score:       jsonResp.NewCategory.Score,
+			IsNew:       true,
+			Description: jsonResp.NewCategory.Description,
+		})
+	}
+
+	return RankingResponse{Rankings: rankings}, nil
+}
+
+// GenerateDescription generates a description for a category.
+func (c *ollamaClient) GenerateDescription(ctx context.Context, prompt string) (DescriptionResponse, error) {
+	systemPrompt := "You are a financial category description generator. You MUST respond with ONLY a valid JSON object. Do not include any explanatory text, markdown formatting, or commentary before or after the JSON. Start your response directly with { and end with }."
+
+	content, err := c.chat(ctx, systemPrompt, prompt, c.maxTokens, true)
+	if err != nil {
+		return DescriptionResponse{}, err
+	}
+
+	var descResp struct {
+		Description string  `json:"description"`
+		Confidence  float64 `json:"confidence"`
+	}
+
+	content = cleanMarkdownWrapper(content)
+
+	if err := json.Unmarshal([]byte(content), &descResp); err != nil {
+		return DescriptionResponse{}, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+
+	return DescriptionResponse{
+		Description: descResp.Description,
+		Confidence:  descResp.Confidence,
+	}, nil
+}
+
+// ClassifyMerchantBatch classifies multiple merchants in a single API call.
+func (c *ollamaClient) ClassifyMerchantBatch(ctx context.Context, prompt string) (MerchantBatchResponse, error) {
+	systemPrompt := "You are a financial transaction classifier. You MUST respond with ONLY a valid JSON object containing merchant classifications. Do not include any explanatory text, markdown formatting, or commentary before or after the JSON. Start your response directly with { and end with }."
+
+	// More tokens needed for batch response
+	content, err := c.chat(ctx, systemPrompt, prompt, c.maxTokens*10, true)
+	if err != nil {
+		return MerchantBatchResponse{}, err
+	}
+
+	var jsonResp struct {
+		Classifications []struct {
+			MerchantID string `json:"merchantId"`
+			Rankings   []struct {
+				Category    string  `json:"category"`
+				Score       float64 `json:"score"`
+				IsNew       bool    `json:"isNew"`
+				Description string  `json:"description,omitempty"`
+				Reason      string  `json:"reason,omitempty"`
+			} `json:"rankings"`
+		} `json:"classifications"`
+	}
+
+	content = cleanMarkdownWrapper(content)
+
+	if err := json.Unmarshal([]byte(content), &jsonResp); err != nil {
+		return MerchantBatchResponse{}, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+
+	classifications := make([]MerchantClassification, 0, len(jsonResp.Classifications))
+	for _, mc := range jsonResp.Classifications {
+		rankings := make([]CategoryRanking, 0, len(mc.Rankings))
+		for _, r := range mc.Rankings {
+			rankings = append(rankings, CategoryRanking{
+				Category:    r.Category,
+				Score:       r.Score,
+				IsNew:       r.IsNew,
+				Description: r.Description,
+				Reason:      r.Reason,
+			})
+		}
+		classifications = append(classifications, MerchantClassification{
+			MerchantID: mc.MerchantID,
+			Rankings:   rankings,
+		})
+	}
+
+	return MerchantBatchResponse{
+		Classifications: classifications,
+	}, nil
+}
+
+// Analyze performs general-purpose AI analysis and returns raw response text.
+func (c *ollamaClient) Analyze(ctx context.Context, prompt string, systemPrompt string) (string, error) {
+	// Use higher token limit for analysis
+	maxTokens := 4000
+	if c.maxTokens > 4000 {
+		maxTokens = c.maxTokens
+	}
+
+	// Return raw content without any parsing
+	return c.chat(ctx, systemPrompt, prompt, maxTokens, false)
+}
+
+// PingOllama checks that the Ollama server at baseURL is running and that
+// model has been pulled, using the /api/tags endpoint. A model without a tag
+// matches its "latest" tag.
+func PingOllama(ctx context.Context, baseURL, model string) error {
+	baseURL = strings.TrimRight(baseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultOllamaBaseURL
+	}
+	if model == "" {
+		model = DefaultOllamaModel
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/api/tags", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ollama is not reachable at %s: %w", baseURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("ollama API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	want := model
+	if !strings.Contains(want, ":") {
+		want += ":latest"
+	}
+	for _, m := range tags.Models {
+		if m.Name == model || m.Name == want {
+			return nil
+		}
+	}
+	return fmt.Errorf("model %s has not been pulled; run 'ollama pull %s'", model, model)
+}
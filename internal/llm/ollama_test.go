@@ -0,0 +1,181 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOllamaClient(t *testing.T) {
+	client, err := newOllamaClient(Config{})
+	require.NoError(t, err)
+	ollama := client.(*ollamaClient)
+	assert.Equal(t, DefaultOllamaModel, ollama.model)
+	assert.Equal(t, DefaultOllamaBaseURL, ollama.baseURL)
+	assert.Equal(t, DefaultOllamaTimeout, ollama.httpClient.Timeout)
+
+	classifier, err := NewClassifier(Config{Provider: "ollama", Model: "mistral", BaseURL: "http://gpu-box:11434/", Timeout: time.Minute}, slog.Default())
+	require.NoError(t, err)
+	ollama = classifier.client.(*ollamaClient)
+	assert.Equal(t, "mistral", ollama.model)
+	assert.Equal(t, "http://gpu-box:11434", ollama.baseURL)
+	assert.Equal(t, time.Minute, ollama.httpClient.Timeout)
+	require.NoError(t, classifier.Close())
+}
+
+// newTestOllamaClient returns an Ollama client whose chat requests are
+// answered with reply by a test server. Each request body is passed to inspect.
+func newTestOllamaClient(t *testing.T, reply string, inspect func(body map[string]any)) *ollamaClient {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/chat", r.URL.Path)
+
+		data, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		var body map[string]any
+		assert.NoError(t, json.Unmarshal(data, &body))
+		if inspect != nil {
+			inspect(body)
+		}
+
+		_, _ = w.Write([]byte(reply))
+	}))
+	t.Cleanup(server.Close)
+
+	return &ollamaClient{
+		httpClient:  server.Client(),
+		model:       "llama3",
+		baseURL:     server.URL,
+		temperature: 0.3,
+		maxTokens:   150,
+	}
+}
+
+// ollamaReply encodes content as a non-streamed /api/chat response.
+func ollamaReply(t *testing.T, content string) string {
+	t.Helper()
+	data, err := json.Marshal(map[string]any{
+		"model":   "llama3",
+		"message": map[string]string{"role": "assistant", "content": content},
+		"done":    true,
+	})
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestOllamaClient_Requests(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("classify asks for JSON", func(t *testing.T) {
+		client := newTestOllamaClient(t, ollamaReply(t, `{"category": "Groceries", "confidence": 0.92, "isNew": false}`), func(body map[string]any) {
+			assert.Equal(t, "llama3", body["model"])
+			assert.Equal(t, "json", body["format"])
+			assert.Contains(t, body, "stream")
+			assert.NotEqual(t, true, body["stream"])
+			options := body["options"].(map[string]any)
+			assert.InDelta(t, 150, options["num_predict"], 0)
+			messages := body["messages"].([]any)
+			if assert.Len(t, messages, 2) {
+				assert.Equal(t, "system", messages[0].(map[string]any)["role"])
+				assert.Equal(t, "classify this", messages[1].(map[string]any)["content"])
+			}
+		})
+
+		response, err := client.Classify(ctx, "classify this")
+		require.NoError(t, err)
+		assert.Equal(t, "Groceries", response.Category)
+		assert.InDelta(t, 0.92, response.Confidence, 0.001)
+	})
+
+	t.Run("streamed response", func(t *testing.T) {
+		stream := strings.Join([]string{
+			`{"message": {"role": "assistant", "content": "{\"category\": "}, "done": false}`,
+			`{"message": {"role": "assistant", "content": "\"Dining\", \"confidence\": 0.7}"}, "done": false}`,
+			`{"message": {"role": "assistant", "content": ""}, "done": true}`,
+		}, "\n")
+		client := newTestOllamaClient(t, stream, nil)
+
+		response, err := client.Classify(ctx, "classify this")
+		require.NoError(t, err)
+		assert.Equal(t, "Dining", response.Category)
+	})
+
+	t.Run("analyze returns raw text", func(t *testing.T) {
+		client := newTestOllamaClient(t, ollamaReply(t, "Plain analysis"), func(body map[string]any) {
+			assert.NotContains(t, body, "format")
+			options := body["options"].(map[string]any)
+			assert.InDelta(t, 4000, options["num_predict"], 0)
+		})
+
+		response, err := client.Analyze(ctx, "analyze this", "system")
+		require.NoError(t, err)
+		assert.Equal(t, "Plain analysis", response)
+	})
+
+	t.Run("missing model", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": "model \"llama3\" not found, try pulling it first"}`))
+		}))
+		defer server.Close()
+
+		client := &ollamaClient{httpClient: server.Client(), model: "llama3", baseURL: server.URL}
+		_, err := client.Classify(ctx, "classify this")
+		assert.ErrorContains(t, err, "ollama API error (status 404)")
+	})
+
+	t.Run("error in stream", func(t *testing.T) {
+		client := newTestOllamaClient(t, `{"error": "out of memory"}`, nil)
+		_, err := client.ClassifyMerchantBatch(ctx, "classify these")
+		assert.ErrorContains(t, err, "ollama error: out of memory")
+	})
+}
+
+func TestClassifier_SuggestCategoryBatch_Ollama(t *testing.T) {
+	client := newTestOllamaClient(t, ollamaReply(t, `{"classifications": [{"merchantId": "m1", "rankings": [{"category": "Groceries", "score": 0.9, "isNew": false}]}]}`), nil)
+	classifier := &Classifier{
+		client:      client,
+		cache:       newSuggestionCache(time.Hour),
+		rateLimiter: newRateLimiter(100),
+		logger:      slog.Default(),
+	}
+
+	requests := []MerchantBatchRequest{{
+		MerchantID:        "m1",
+		MerchantName:      "Whole Foods",
+		SampleTransaction: model.Transaction{ID: "tx1", Hash: "hash1", Name: "WHOLE FOODS"},
+		TransactionCount:  1,
+	}}
+	results, err := classifier.SuggestCategoryBatch(context.Background(), requests, []model.Category{{Name: "Groceries"}})
+	require.NoError(t, err)
+	require.Len(t, results["m1"], 1)
+	assert.Equal(t, "Groceries", results["m1"][0].Category)
+}
+
+func TestPingOllama(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/tags", r.URL.Path)
+		_, _ = w.Write([]byte(`{"models": [{"name": "llama3:latest"}, {"name": "mistral:7b"}]}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	require.NoError(t, PingOllama(ctx, server.URL, "llama3"))
+	require.NoError(t, PingOllama(ctx, server.URL, "llama3:latest"))
+	require.NoError(t, PingOllama(ctx, server.URL+"/", "mistral:7b"))
+	assert.ErrorContains(t, PingOllama(ctx, server.URL, "mistral"), "run 'ollama pull mistral'")
+
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+	assert.ErrorContains(t, PingOllama(ctx, unreachable.URL, "llama3"), "ollama is not reachable")
+}
@@ -99,7 +99,7 @@ func (c *openAIClient) Classify(ctx context.Context, prompt string) (Classificat
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return ClassificationResponse{}, fmt.Errorf("OpenAI API error (status %d): %s", resp.StatusCode, string(body))
+		return ClassificationResponse{}, apiError("OpenAI", resp, body)
 	}
 
 	var response openAIResponse
@@ -206,7 +206,7 @@ func (c *openAIClient) ClassifyWithRankings(ctx context.Context, prompt string)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return RankingResponse{}, fmt.Errorf("OpenAI API error (status %d): %s", resp.StatusCode, string(body))
+		return RankingResponse{}, apiError("OpenAI", resp, body)
 	}
 
 	var response openAIResponse
@@ -310,7 +310,7 @@ func (c *openAIClient) GenerateDescription(ctx context.Context, prompt string) (
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return DescriptionResponse{}, fmt.Errorf("OpenAI API error (status %d): %s", resp.StatusCode, string(body))
+		return DescriptionResponse{}, apiError("OpenAI", resp, body)
 	}
 
 	var response openAIResponse
@@ -385,7 +385,7 @@ func (c *openAIClient) ClassifyMerchantBatch(ctx context.Context, prompt string)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return MerchantBatchResponse{}, fmt.Errorf("OpenAI API error (status %d): %s", resp.StatusCode, string(body))
+		return MerchantBatchResponse{}, apiError("OpenAI", resp, body)
 	}
 
 	var response openAIResponse
@@ -423,6 +423,7 @@ func (c *openAIClient) parseMerchantBatchResponse(content string) (MerchantBatch
 				Score       float64 `json:"score"`
 				IsNew       bool    `json:"isNew"`
 				Description string  `json:"description,omitempty"`
+				Reason      string  `json:"reason,omitempty"`
 			} `json:"rankings"`
 		} `json:"classifications"`
 	}
@@ -443,6 +444,7 @@ func (c *openAIClient) parseMerchantBatchResponse(content string) (MerchantBatch
 				Score:       r.Score,
 				IsNew:       r.IsNew,
 				Description: r.Description,
+				Reason:      r.Reason,
 			})
 		}
 		classifications = append(classifications, MerchantClassification{
@@ -505,7 +507,7 @@ func (c *openAIClient) Analyze(ctx context.Context, prompt string, systemPrompt
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("OpenAI API error (status %d): %s", resp.StatusCode, string(body))
+		return "", apiError("OpenAI", resp, body)
 	}
 
 	var response openAIResponse
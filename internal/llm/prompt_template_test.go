@@ -0,0 +1,136 @@
+package llm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+func promptTemplateRequests() ([]MerchantBatchRequest, []model.Category) {
+	requests := []MerchantBatchRequest{
+		{
+			MerchantID:        "kroger",
+			MerchantName:      "Kroger",
+			SampleTransaction: model.Transaction{Name: "KROGER #123", Amount: 54.20, Type: "DEBIT"},
+			TransactionCount:  4,
+			Hint:              "weekly groceries",
+		},
+		{
+			MerchantID:        "shell",
+			MerchantName:      "Shell",
+			SampleTransaction: model.Transaction{Name: "SHELL OIL 5678", Amount: 40, Type: "DEBIT"},
+			TransactionCount:  2,
+		},
+	}
+	categories := []model.Category{
+		{Name: "Groceries", Description: "Food and household supplies"},
+		{Name: "Fuel", Description: "Gas stations"},
+	}
+	return requests, categories
+}
+
+func TestParsePromptTemplate(t *testing.T) {
+	requests, categories := promptTemplateRequests()
+
+	prompt, err := parsePromptTemplate(`Sort these merchants into my budget.
+Categories:
+{{.Categories}}
+Examples:
+- "COSTCO WHSE" is Groceries
+{{range .Merchants}}
+{{.ID}}: {{.Name}} ({{.SampleTransaction}}, ${{printf "%.2f" .Amount}}){{if .Hint}} hint: {{.Hint}}{{end}}
+{{- end}}
+Give up to {{.MaxRankings}} categories each.
+`)
+	require.NoError(t, err)
+	assert.NotEqual(t, builtinPromptTemplate.version, prompt.version, "custom templates get their own cache entries")
+
+	classifier := &Classifier{prompt: prompt, topN: 2}
+	rendered := classifier.buildBatchPrompt(requests, categories)
+	assert.Contains(t, rendered, "- Groceries: Food and household supplies")
+	assert.Contains(t, rendered, `- "COSTCO WHSE" is Groceries`)
+	assert.Contains(t, rendered, "kroger: Kroger (KROGER #123, $54.20) hint: weekly groceries")
+	assert.Contains(t, rendered, "shell: Shell (SHELL OIL 5678, $40.00)\n")
+	assert.Contains(t, rendered, "Give up to 2 categories each.\n\nRespond with a JSON object",
+		"the response format follows the template")
+	assert.NotContains(t, rendered, "SKEPTICAL")
+
+	same, err := parsePromptTemplate("{{.Categories}}\n{{.MerchantDetails}}")
+	require.NoError(t, err, "the formatted merchant block has every merchant field")
+	assert.NotEqual(t, prompt.version, same.version)
+}
+
+func TestParsePromptTemplate_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		wantErr string
+	}{
+		{
+			name:    "no categories",
+			text:    "{{.MerchantDetails}}",
+			wantErr: "missing placeholders {{.Categories}}",
+		},
+		{
+			name:    "no merchants",
+			text:    "Pick one of:\n{{.Categories}}",
+			wantErr: "missing placeholders {{.ID}}, {{.Name}}, {{.SampleTransaction}}",
+		},
+		{
+			name:    "no sample transaction",
+			text:    "{{.Categories}}{{range .Merchants}}{{.ID}} {{.Name}}{{end}}",
+			wantErr: "missing placeholders {{.SampleTransaction}}",
+		},
+		{
+			name:    "unknown field",
+			text:    "{{.Categories}} {{.Merchant}}",
+			wantErr: "failed to render prompt template",
+		},
+		{
+			name:    "syntax error",
+			text:    "{{.Categories",
+			wantErr: "unclosed action",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parsePromptTemplate(tt.text)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestLoadPromptTemplate(t *testing.T) {
+	prompt, err := loadPromptTemplate("")
+	require.NoError(t, err)
+	assert.Same(t, builtinPromptTemplate, prompt)
+
+	requests, categories := promptTemplateRequests()
+	rendered := (&Classifier{}).buildBatchPrompt(requests, categories)
+	assert.Contains(t, rendered, "You are a SKEPTICAL financial transaction classifier")
+	assert.Contains(t, rendered, "Merchant 1 (ID: kroger):")
+	assert.Contains(t, rendered, "AT MOST 5 of the most likely categories")
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "prompt.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte("{{.Categories}}\n{{.MerchantDetails}}"), 0o600))
+	prompt, err = loadPromptTemplate(path)
+	require.NoError(t, err)
+	assert.NotSame(t, builtinPromptTemplate, prompt)
+
+	_, err = loadPromptTemplate(filepath.Join(dir, "missing.tmpl"))
+	assert.ErrorContains(t, err, "failed to read prompt template")
+
+	broken := filepath.Join(dir, "broken.tmpl")
+	require.NoError(t, os.WriteFile(broken, []byte("{{.MerchantDetails}}"), 0o600))
+	_, err = NewClassifier(Config{Provider: "openai", APIKey: "test", PromptTemplate: broken}, nil)
+	require.Error(t, err, "a broken template fails at startup")
+	assert.Contains(t, err.Error(), "invalid prompt template "+broken)
+}
@@ -0,0 +1,211 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// providerLimiter keeps a client within its provider's requests and tokens
+// per minute. Each limit is a token bucket holding a minute's allowance that
+// refills continuously; a nil bucket is unlimited.
+type providerLimiter struct {
+	now      func() time.Time
+	requests *minuteBucket
+	tokens   *minuteBucket
+	provider string
+	mu       sync.Mutex
+}
+
+// providerLimits identifies the limiter shared by clients of a provider.
+type providerLimits struct {
+	provider          string
+	requestsPerMinute int
+	tokensPerMinute   int
+}
+
+var (
+	sharedLimitersMu sync.Mutex
+	sharedLimiters   = make(map[providerLimits]*providerLimiter)
+)
+
+// sharedProviderLimiter returns the limiter for a provider's limits, the
+// same one for every client and embedder created with them, so together they
+// stay within the provider's allowance. It returns nil when neither limit is set.
+func sharedProviderLimiter(provider string, requestsPerMinute, tokensPerMinute int) *providerLimiter {
+	key := providerLimits{provider: strings.ToLower(provider), requestsPerMinute: requestsPerMinute, tokensPerMinute: tokensPerMinute}
+
+	sharedLimitersMu.Lock()
+	defer sharedLimitersMu.Unlock()
+	if limiter, ok := sharedLimiters[key]; ok {
+		return limiter
+	}
+	limiter := newProviderLimiter(key.provider, requestsPerMinute, tokensPerMinute)
+	if limiter != nil {
+		sharedLimiters[key] = limiter
+	}
+	return limiter
+}
+
+// newProviderLimiter returns nil when neither limit is set.
+func newProviderLimiter(provider string, requestsPerMinute, tokensPerMinute int) *providerLimiter {
+	if requestsPerMinute <= 0 && tokensPerMinute <= 0 {
+		return nil
+	}
+	now := time.Now()
+	return &providerLimiter{
+		now:      time.Now,
+		requests: newMinuteBucket(requestsPerMinute, now),
+		tokens:   newMinuteBucket(tokensPerMinute, now),
+		provider: provider,
+	}
+}
+
+// wait blocks until a request of the given estimated tokens fits within the
+// limits, or the context is canceled.
+func (l *providerLimiter) wait(ctx context.Context, tokens int) error {
+	for {
+		delay := l.reserve(tokens)
+		if delay == 0 {
+			return nil
+		}
+
+		slog.Debug("waiting for LLM provider rate limit",
+			"provider", l.provider,
+			"wait", delay,
+			"estimated_tokens", tokens)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("rate limiter canceled: %w", ctx.Err())
+		case <-timer.C:
+			// Try again
+		}
+	}
+}
+
+// reserve takes a request and its tokens from the buckets if both have
+// enough, returning 0. Otherwise it takes nothing and returns how long until
+// they will.
+func (l *providerLimiter) reserve(tokens int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.requests.refill(now)
+	l.tokens.refill(now)
+
+	cost := l.tokens.cost(tokens)
+	if delay := max(l.requests.delay(1), l.tokens.delay(cost)); delay > 0 {
+		return delay
+	}
+	l.requests.take(1)
+	l.tokens.take(cost)
+	return 0
+}
+
+// minuteBucket is a token bucket refilled at perMinute per minute.
+type minuteBucket struct {
+	last      time.Time
+	available float64
+	perMinute float64
+}
+
+// newMinuteBucket returns a full bucket, or nil when perMinute isn't positive.
+func newMinuteBucket(perMinute int, now time.Time) *minuteBucket {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &minuteBucket{last: now, available: float64(perMinute), perMinute: float64(perMinute)}
+}
+
+func (b *minuteBucket) refill(now time.Time) {
+	if b == nil {
+		return
+	}
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.available = math.Min(b.perMinute, b.available+elapsed.Minutes()*b.perMinute)
+		b.last = now
+	}
+}
+
+// cost caps n at the bucket's size, so a request larger than a minute's
+// allowance waits for a full bucket instead of forever.
+func (b *minuteBucket) cost(n int) float64 {
+	if b == nil {
+		return 0
+	}
+	return math.Min(float64(n), b.perMinute)
+}
+
+// delay returns how long until n are available.
+func (b *minuteBucket) delay(n float64) time.Duration {
+	if b == nil || b.available >= n {
+		return 0
+	}
+	return time.Duration(math.Ceil((n - b.available) / b.perMinute * float64(time.Minute)))
+}
+
+func (b *minuteBucket) take(n float64) {
+	if b != nil {
+		b.available -= n
+	}
+}
+
+// rateLimitedClient holds each call to client until its provider's limits
+// allow it. Tokens are estimated from the prompt.
+type rateLimitedClient struct {
+	client  Client
+	limiter *providerLimiter
+}
+
+// withProviderLimits wraps client in the requests and tokens per minute of
+// cfg, or returns it unchanged when neither is set.
+func withProviderLimits(client Client, cfg Config) Client {
+	limiter := sharedProviderLimiter(cfg.Provider, cfg.RequestsPerMinute, cfg.TokensPerMinute)
+	if limiter == nil {
+		return client
+	}
+	return &rateLimitedClient{client: client, limiter: limiter}
+}
+
+func (c *rateLimitedClient) Classify(ctx context.Context, prompt string) (ClassificationResponse, error) {
+	if err := c.limiter.wait(ctx, EstimateTokens(prompt)); err != nil {
+		return ClassificationResponse{}, fmt.Errorf("rate limit error: %w", err)
+	}
+	return c.client.Classify(ctx, prompt)
+}
+
+func (c *rateLimitedClient) ClassifyWithRankings(ctx context.Context, prompt string) (RankingResponse, error) {
+	if err := c.limiter.wait(ctx, EstimateTokens(prompt)); err != nil {
+		return RankingResponse{}, fmt.Errorf("rate limit error: %w", err)
+	}
+	return c.client.ClassifyWithRankings(ctx, prompt)
+}
+
+func (c *rateLimitedClient) ClassifyMerchantBatch(ctx context.Context, prompt string) (MerchantBatchResponse, error) {
+	if err := c.limiter.wait(ctx, EstimateTokens(prompt)+batchSystemPromptTokens); err != nil {
+		return MerchantBatchResponse{}, fmt.Errorf("rate limit error: %w", err)
+	}
+	return c.client.ClassifyMerchantBatch(ctx, prompt)
+}
+
+func (c *rateLimitedClient) GenerateDescription(ctx context.Context, prompt string) (DescriptionResponse, error) {
+	if err := c.limiter.wait(ctx, EstimateTokens(prompt)); err != nil {
+		return DescriptionResponse{}, fmt.Errorf("rate limit error: %w", err)
+	}
+	return c.client.GenerateDescription(ctx, prompt)
+}
+
+func (c *rateLimitedClient) Analyze(ctx context.Context, prompt string, systemPrompt string) (string, error) {
+	if err := c.limiter.wait(ctx, EstimateTokens(prompt)+EstimateTokens(systemPrompt)); err != nil {
+		return "", fmt.Errorf("rate limit error: %w", err)
+	}
+	return c.client.Analyze(ctx, prompt, systemPrompt)
+}
@@ -0,0 +1,102 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestProviderLimiter returns a limiter on a clock the test moves.
+func newTestProviderLimiter(requestsPerMinute, tokensPerMinute int) (*providerLimiter, *time.Time) {
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := newProviderLimiter("openai", requestsPerMinute, tokensPerMinute)
+	limiter.now = func() time.Time { return clock }
+	limiter.requests = newMinuteBucket(requestsPerMinute, clock)
+	limiter.tokens = newMinuteBucket(tokensPerMinute, clock)
+	return limiter, &clock
+}
+
+func TestProviderLimiter(t *testing.T) {
+	t.Run("no limits", func(t *testing.T) {
+		assert.Nil(t, newProviderLimiter("openai", 0, 0))
+	})
+
+	t.Run("requests per minute", func(t *testing.T) {
+		limiter, clock := newTestProviderLimiter(60, 0)
+		for i := 0; i < 60; i++ {
+			require.Zero(t, limiter.reserve(1000), "request %d", i)
+		}
+		assert.Equal(t, time.Second, limiter.reserve(1000))
+
+		*clock = clock.Add(500 * time.Millisecond)
+		assert.Equal(t, 500*time.Millisecond, limiter.reserve(1000))
+
+		*clock = clock.Add(500 * time.Millisecond)
+		assert.Zero(t, limiter.reserve(1000))
+	})
+
+	t.Run("tokens per minute", func(t *testing.T) {
+		limiter, clock := newTestProviderLimiter(0, 6000)
+		require.Zero(t, limiter.reserve(4000))
+		assert.Equal(t, 20*time.Second, limiter.reserve(4000), "waits for the missing 2000 tokens")
+
+		*clock = clock.Add(20 * time.Second)
+		assert.Zero(t, limiter.reserve(4000))
+	})
+
+	t.Run("a waiting request takes nothing", func(t *testing.T) {
+		limiter, clock := newTestProviderLimiter(1, 6000)
+		require.Zero(t, limiter.reserve(100))
+		require.NotZero(t, limiter.reserve(100))
+
+		// The tokens weren't taken while the request was held back
+		*clock = clock.Add(time.Minute)
+		assert.Zero(t, limiter.reserve(6000))
+	})
+
+	t.Run("requests larger than a minute wait for a full bucket", func(t *testing.T) {
+		limiter, clock := newTestProviderLimiter(0, 1000)
+		require.Zero(t, limiter.reserve(500))
+		assert.Equal(t, 30*time.Second, limiter.reserve(5000))
+
+		*clock = clock.Add(30 * time.Second)
+		assert.Zero(t, limiter.reserve(5000))
+	})
+
+	t.Run("wait respects cancellation", func(t *testing.T) {
+		limiter := newProviderLimiter("openai", 1, 0)
+		require.NoError(t, limiter.wait(context.Background(), 10))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err := limiter.wait(ctx, 10)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestWithProviderLimits(t *testing.T) {
+	client := &mockClient{responses: []ClassificationResponse{{Category: "Food"}}}
+
+	assert.Same(t, Client(client), withProviderLimits(client, Config{Provider: "openai"}))
+
+	limited := withProviderLimits(client, Config{Provider: "OpenAI", TokensPerMinute: 100})
+	require.IsType(t, &rateLimitedClient{}, limited)
+	assert.Equal(t, "openai", limited.(*rateLimitedClient).limiter.provider)
+
+	resp, err := limited.Classify(context.Background(), "Classify STARBUCKS")
+	require.NoError(t, err)
+	assert.Equal(t, "Food", resp.Category)
+
+	// The next prompt is a minute's worth of tokens, so it waits
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = limited.Analyze(ctx, strings.Repeat("x", 400), "")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "rate limit error")
+	assert.Equal(t, 1, client.calls, "the held call never reached the client")
+}
@@ -0,0 +1,46 @@
+package llm
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/common"
+)
+
+// apiError describes a failed response from provider's API. When a rate
+// limited or unavailable server says when to retry, the error carries it so
+// the retries wait that long.
+func apiError(provider string, resp *http.Response, body []byte) error {
+	err := fmt.Errorf("%s API error (status %d): %s", provider, resp.StatusCode, string(body))
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return err
+	}
+	after, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	if !ok {
+		return err
+	}
+	return &common.RetryAfterError{Err: err, After: after}
+}
+
+// parseRetryAfter parses a Retry-After header, either a number of seconds or
+// an HTTP date. A date in the past means retrying right away.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	when, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	return max(when.Sub(now), 0), true
+}
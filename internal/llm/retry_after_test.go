@@ -0,0 +1,112 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/common"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "seconds", value: "30", want: 30 * time.Second, wantOK: true},
+		{name: "zero seconds", value: "0", want: 0, wantOK: true},
+		{name: "padded", value: " 5 ", want: 5 * time.Second, wantOK: true},
+		{name: "http date", value: "Sat, 01 Jun 2024 12:01:30 GMT", want: 90 * time.Second, wantOK: true},
+		{name: "http date in the past", value: "Sat, 01 Jun 2024 11:59:00 GMT", want: 0, wantOK: true},
+		{name: "missing", value: "", wantOK: false},
+		{name: "negative", value: "-5", wantOK: false},
+		{name: "garbage", value: "soon", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value, now)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	response := func(status int, retryAfter string) *http.Response {
+		resp := &http.Response{StatusCode: status, Header: http.Header{}}
+		if retryAfter != "" {
+			resp.Header.Set("Retry-After", retryAfter)
+		}
+		return resp
+	}
+
+	err := apiError("OpenAI", response(http.StatusTooManyRequests, "7"), []byte("slow down"))
+	assert.EqualError(t, err, "OpenAI API error (status 429): slow down")
+	var retryAfterErr *common.RetryAfterError
+	require.ErrorAs(t, err, &retryAfterErr)
+	assert.Equal(t, 7*time.Second, retryAfterErr.After)
+
+	err = apiError("anthropic", response(http.StatusServiceUnavailable, "3"), []byte("overloaded"))
+	require.ErrorAs(t, err, &retryAfterErr)
+	assert.Equal(t, 3*time.Second, retryAfterErr.After)
+
+	// Without the header, or on other statuses, the usual backoff applies
+	err = apiError("OpenAI", response(http.StatusTooManyRequests, ""), []byte("slow down"))
+	assert.False(t, errors.As(err, &retryAfterErr))
+	err = apiError("OpenAI", response(http.StatusInternalServerError, "7"), []byte("oops"))
+	assert.False(t, errors.As(err, &retryAfterErr))
+	assert.EqualError(t, err, "OpenAI API error (status 500): oops")
+}
+
+// retryAfterClient fails its first description with a Retry-After error.
+type retryAfterClient struct {
+	mockClient
+	after time.Duration
+	calls int
+}
+
+func (c *retryAfterClient) GenerateDescription(_ context.Context, _ string) (DescriptionResponse, error) {
+	c.calls++
+	if c.calls == 1 {
+		return DescriptionResponse{}, &common.RetryAfterError{Err: errors.New("OpenAI API error (status 429)"), After: c.after}
+	}
+	return DescriptionResponse{Description: "Meals out", Confidence: 0.9}, nil
+}
+
+func TestClassifier_HonorsRetryAfter(t *testing.T) {
+	client := &retryAfterClient{after: 20 * time.Millisecond}
+	classifier := &Classifier{
+		client:      client,
+		logger:      slog.Default(),
+		rateLimiter: newRateLimiter(60),
+		language:    defaultPromptLanguage(t),
+		retryOpts: service.RetryOptions{
+			MaxAttempts:  2,
+			InitialDelay: time.Minute, // Never reached when the server says when to retry
+			MaxDelay:     time.Minute,
+			Multiplier:   2.0,
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	start := time.Now()
+	description, _, err := classifier.GenerateCategoryDescription(ctx, "Dining")
+	require.NoError(t, err)
+	assert.Equal(t, "Meals out", description)
+	assert.Equal(t, 2, client.calls)
+	elapsed := time.Since(start)
+	assert.GreaterOrEqual(t, elapsed, 20*time.Millisecond)
+	assert.Less(t, elapsed, 5*time.Second)
+}
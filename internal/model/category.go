@@ -23,4 +23,50 @@ type Category struct {
 	ID                     int
 	DefaultBusinessPercent int
 	IsActive               bool
+	// AlwaysReview sends every transaction suggested for the category to manual
+	// review, whatever the confidence or rule behind the suggestion.
+	AlwaysReview bool
+	// VendorRuleThreshold is the confidence an auto-accepted suggestion for the
+	// category needs before a vendor rule is created for its merchant; 0 means
+	// the default.
+	VendorRuleThreshold float64
+	// Aliases are other names the LLM uses for the category. Rankings naming an
+	// alias are counted for the category.
+	Aliases []string
+}
+
+// CategoryAlias maps another name for a category onto the category.
+type CategoryAlias struct {
+	CreatedAt time.Time
+	Alias     string
+	Category  string
+}
+
+// CategorySnapshot is the category set as it was when a report was generated,
+// kept so the report can be regenerated the same way after categories change.
+// Only the name, description, type and default business percent of each
+// category are kept.
+type CategorySnapshot struct {
+	CreatedAt   time.Time
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Report      string // Report the snapshot was taken for, e.g. "flow"
+	Categories  []Category
+	ID          int64
+}
+
+// CategoryMergeResult counts the references moved when one category is merged into another.
+type CategoryMergeResult struct {
+	Classifications int64
+	Vendors         int64
+	CheckPatterns   int64
+	PatternRules    int64
+}
+
+// CategoryAmountStats describes the amounts of the transactions classified in a category.
+type CategoryAmountStats struct {
+	Category string
+	Count    int
+	Average  float64
+	Max      float64
 }
@@ -9,6 +9,7 @@ import (
 type CategoryRanking struct {
 	Category    string
 	Description string
+	Reason      string // Why the AI ranked the category, when asked to explain
 	Score       float64
 	IsNew       bool
 }
@@ -3,9 +3,21 @@ package model
 import (
 	"encoding/json"
 	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// checkAmountTolerance is how far apart two amounts can be and still be the
+// same amount: half a cent, so amounts that round to the same cent match
+// despite floating-point error.
+const checkAmountTolerance = 0.005
+
+// DefaultCheckPatternConfidence is the confidence used for patterns without an explicit score.
+const DefaultCheckPatternConfidence = 1.0
+
 // CheckPattern represents a pattern for automatically categorizing check transactions.
 type CheckPattern struct {
 	CreatedAt          time.Time
@@ -18,15 +30,55 @@ type CheckPattern struct {
 	Category           string
 	Notes              string
 	PatternName        string
+	MemoPattern        string // Case-insensitive text that must appear in the check's name/memo
 	Amounts            []float64
+	Confidence         float64 // Suggestion confidence (0-1]; 0 means DefaultCheckPatternConfidence
 	ID                 int64
 	UseCount           int
 }
 
+// EffectiveConfidence returns the pattern's confidence, falling back to the default.
+func (p *CheckPattern) EffectiveConfidence() float64 {
+	if p.Confidence <= 0 {
+		return DefaultCheckPatternConfidence
+	}
+	return p.Confidence
+}
+
 // CheckNumberMatcher represents complex check number matching patterns.
 type CheckNumberMatcher struct {
-	Modulo int `json:"modulo,omitempty"` // e.g., check number % 10 == offset
-	Offset int `json:"offset,omitempty"`
+	Regex  string `json:"regex,omitempty"`  // Must match the check number, e.g. ^10\d{2}$
+	Modulo int    `json:"modulo,omitempty"` // e.g., check number % 10 == offset
+	Offset int    `json:"offset,omitempty"`
+}
+
+// Match reports whether a check number satisfies the matcher. An empty check
+// number always matches, since there is nothing to check. An error is
+// returned when Regex does not compile.
+func (m *CheckNumberMatcher) Match(checkNumber string) (bool, error) {
+	checkNumber = strings.TrimSpace(checkNumber)
+	if checkNumber == "" {
+		return true, nil
+	}
+
+	if m.Regex != "" {
+		re, err := regexp.Compile(m.Regex)
+		if err != nil {
+			return false, fmt.Errorf("invalid check number regex %q: %w", m.Regex, err)
+		}
+		if !re.MatchString(checkNumber) {
+			return false, nil
+		}
+	}
+
+	if m.Modulo > 0 {
+		number, err := strconv.Atoi(checkNumber)
+		if err != nil || number%m.Modulo != m.Offset {
+			return false, nil
+		}
+	}
+
+	return true, nil
 }
 
 // MarshalJSON handles JSON serialization for CheckNumberPattern field.
@@ -53,53 +105,85 @@ func (p *CheckPattern) MarshalJSON() ([]byte, error) {
 	})
 }
 
-// Matches determines if a transaction matches this pattern.
+// Matches determines if a transaction matches this pattern. A pattern whose
+// check number regex does not compile matches nothing; use Match to see why.
 func (p *CheckPattern) Matches(txn Transaction) bool {
+	matched, err := p.Match(txn)
+	return err == nil && matched
+}
+
+// Match determines if a transaction matches this pattern, returning an error
+// when the pattern's check number regex does not compile.
+func (p *CheckPattern) Match(txn Transaction) (bool, error) {
 	// Check transaction type
 	if txn.Type != "CHECK" {
-		return false
+		return false, nil
 	}
 
-	// Check specific amounts first
-	if len(p.Amounts) > 0 {
-		matched := false
-		for _, amount := range p.Amounts {
-			if txn.Amount == amount {
-				matched = true
-				break
-			}
-		}
-		if !matched {
-			return false
-		}
-	} else {
-		// Fall back to amount range
-		if p.AmountMin != nil && txn.Amount < *p.AmountMin {
-			return false
-		}
-		if p.AmountMax != nil && txn.Amount > *p.AmountMax {
-			return false
-		}
+	// Check amounts: a listed amount or the range, whichever are set
+	if !p.matchesAmount(txn.Amount) {
+		return false, nil
 	}
 
 	// Check day of month
 	if p.DayOfMonthMin != nil || p.DayOfMonthMax != nil {
 		day := txn.Date.Day()
 		if p.DayOfMonthMin != nil && day < *p.DayOfMonthMin {
-			return false
+			return false, nil
 		}
 		if p.DayOfMonthMax != nil && day > *p.DayOfMonthMax {
-			return false
+			return false, nil
 		}
 	}
 
-	// Check number pattern matching (if implemented)
-	// This would require parsing check number from transaction name
-	// For now, we'll skip this check
+	// Check payee/memo text
+	if p.MemoPattern != "" && !p.matchesMemo(txn) {
+		return false, nil
+	}
+
+	// Check number, when the transaction has one
+	if p.CheckNumberPattern != nil {
+		return p.CheckNumberPattern.Match(txn.CheckNumber)
+	}
+
+	return true, nil
+}
+
+// matchesAmount reports whether amount equals one of the pattern's amounts or
+// falls within its range. With both set either is a hit; with neither, any
+// amount matches. Comparisons allow checkAmountTolerance for float error.
+func (p *CheckPattern) matchesAmount(amount float64) bool {
+	hasRange := p.AmountMin != nil || p.AmountMax != nil
+	if len(p.Amounts) == 0 && !hasRange {
+		return true
+	}
 
+	for _, listed := range p.Amounts {
+		if math.Abs(amount-listed) < checkAmountTolerance {
+			return true
+		}
+	}
+
+	if !hasRange {
+		return false
+	}
+	if p.AmountMin != nil && amount <= *p.AmountMin-checkAmountTolerance {
+		return false
+	}
+	if p.AmountMax != nil && amount >= *p.AmountMax+checkAmountTolerance {
+		return false
+	}
 	return true
 }
 
+// matchesMemo reports whether the pattern's memo text appears in the
+// transaction's name/memo or merchant name.
+func (p *CheckPattern) matchesMemo(txn Transaction) bool {
+	memo := strings.ToLower(strings.TrimSpace(p.MemoPattern))
+	return strings.Contains(strings.ToLower(txn.Name), memo) ||
+		strings.Contains(strings.ToLower(txn.MerchantName), memo)
+}
+
 // Validate ensures the pattern has valid data.
 func (p *CheckPattern) Validate() error {
 	if p.PatternName == "" {
@@ -110,23 +194,26 @@ func (p *CheckPattern) Validate() error {
 		return fmt.Errorf("category is required")
 	}
 
-	// Validate amounts
-	if len(p.Amounts) > 0 {
-		// If specific amounts are provided, min/max should not be set
-		if p.AmountMin != nil || p.AmountMax != nil {
-			return fmt.Errorf("cannot specify both specific amounts and amount range")
-		}
-		// Validate each amount
-		for i, amount := range p.Amounts {
-			if amount <= 0 {
-				return fmt.Errorf("amount at index %d must be positive", i)
-			}
+	if p.Confidence < 0 || p.Confidence > 1 {
+		return fmt.Errorf("confidence must be between 0 and 1, got %.2f", p.Confidence)
+	}
+
+	// Validate amounts; a list and a range may both be set, and either matches
+	for i, amount := range p.Amounts {
+		if amount <= 0 {
+			return fmt.Errorf("amount at index %d must be positive", i)
 		}
-	} else if p.AmountMin != nil && p.AmountMax != nil && *p.AmountMin > *p.AmountMax {
-		// Validate amount range
+	}
+	if p.AmountMin != nil && p.AmountMax != nil && *p.AmountMin > *p.AmountMax {
 		return fmt.Errorf("amount min must be less than or equal to amount max")
 	}
 
+	if p.CheckNumberPattern != nil && p.CheckNumberPattern.Regex != "" {
+		if _, err := regexp.Compile(p.CheckNumberPattern.Regex); err != nil {
+			return fmt.Errorf("invalid check number regex: %w", err)
+		}
+	}
+
 	// Validate day of month range
 	if p.DayOfMonthMin != nil && (*p.DayOfMonthMin < 1 || *p.DayOfMonthMin > 31) {
 		return fmt.Errorf("day of month min must be between 1 and 31")
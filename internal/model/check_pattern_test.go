@@ -100,6 +100,26 @@ func TestCheckPattern_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "day of month min must be less than or equal to day of month max",
 		},
+		{
+			name: "valid pattern with amounts and range",
+			pattern: CheckPattern{
+				PatternName: "Both",
+				Amounts:     []float64{500},
+				AmountMin:   floatPtr(100),
+				AmountMax:   floatPtr(200),
+				Category:    "Test",
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid check number regex",
+			pattern: CheckPattern{
+				PatternName:        "Invalid regex",
+				CheckNumberPattern: &CheckNumberMatcher{Regex: "^(10"},
+				Category:           "Test",
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -252,6 +272,187 @@ func TestCheckPattern_Matches(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "matches memo text case-insensitively",
+			pattern: CheckPattern{
+				MemoPattern: "jane's cleaning",
+			},
+			txn: Transaction{
+				Type:   "CHECK",
+				Name:   "CHECK #1234 - JANE'S CLEANING",
+				Amount: 120,
+				Date:   testDate,
+			},
+			want: true,
+		},
+		{
+			name: "matches memo with amount range",
+			pattern: CheckPattern{
+				MemoPattern: "Lawn",
+				AmountMin:   floatPtr(50),
+				AmountMax:   floatPtr(80),
+			},
+			txn: Transaction{
+				Type:   "CHECK",
+				Name:   "CHK 1001 LAWN CARE",
+				Amount: 65,
+				Date:   testDate,
+			},
+			want: true,
+		},
+		{
+			name: "no match - memo text missing",
+			pattern: CheckPattern{
+				MemoPattern: "Lawn",
+			},
+			txn: Transaction{
+				Type:   "CHECK",
+				Name:   "CHECK #1234",
+				Amount: 65,
+				Date:   testDate,
+			},
+			want: false,
+		},
+		{
+			name: "no match - memo matches but amount does not",
+			pattern: CheckPattern{
+				MemoPattern: "Lawn",
+				Amounts:     []float64{100},
+			},
+			txn: Transaction{
+				Type:   "CHECK",
+				Name:   "CHK 1001 LAWN CARE",
+				Amount: 65,
+				Date:   testDate,
+			},
+			want: false,
+		}, {
+			name: "matches listed amount within a cent",
+			pattern: CheckPattern{
+				Amounts: []float64{100, 150},
+			},
+			txn: Transaction{
+				Type:   "CHECK",
+				Amount: 150.004,
+				Date:   testDate,
+			},
+			want: true,
+		},
+		{
+			name: "no match - listed amount off by a cent",
+			pattern: CheckPattern{
+				Amounts: []float64{100, 150},
+			},
+			txn: Transaction{
+				Type:   "CHECK",
+				Amount: 150.01,
+				Date:   testDate,
+			},
+			want: false,
+		},
+		{
+			name: "matches range bound despite float error",
+			pattern: CheckPattern{
+				AmountMin: floatPtr(0.3),
+				AmountMax: floatPtr(0.5),
+			},
+			txn: Transaction{
+				Type:   "CHECK",
+				Amount: 0.1 + 0.2, // 0.30000000000000004
+				Date:   testDate,
+			},
+			want: true,
+		},
+		{
+			name: "matches listed amount outside range",
+			pattern: CheckPattern{
+				Amounts:   []float64{500},
+				AmountMin: floatPtr(100),
+				AmountMax: floatPtr(200),
+			},
+			txn: Transaction{
+				Type:   "CHECK",
+				Amount: 500,
+				Date:   testDate,
+			},
+			want: true,
+		},
+		{
+			name: "matches range when no listed amount does",
+			pattern: CheckPattern{
+				Amounts:   []float64{500},
+				AmountMin: floatPtr(100),
+				AmountMax: floatPtr(200),
+			},
+			txn: Transaction{
+				Type:   "CHECK",
+				Amount: 150,
+				Date:   testDate,
+			},
+			want: true,
+		},
+		{
+			name: "no match - neither listed amounts nor range",
+			pattern: CheckPattern{
+				Amounts:   []float64{500},
+				AmountMin: floatPtr(100),
+				AmountMax: floatPtr(200),
+			},
+			txn: Transaction{
+				Type:   "CHECK",
+				Amount: 300,
+				Date:   testDate,
+			},
+			want: false,
+		},
+		{
+			name: "matches check number regex",
+			pattern: CheckPattern{
+				CheckNumberPattern: &CheckNumberMatcher{Regex: `^10\d{2}$`},
+			},
+			txn: Transaction{
+				Type:        "CHECK",
+				CheckNumber: "1042",
+				Date:        testDate,
+			},
+			want: true,
+		},
+		{
+			name: "no match - check number regex",
+			pattern: CheckPattern{
+				CheckNumberPattern: &CheckNumberMatcher{Regex: `^10\d{2}$`},
+			},
+			txn: Transaction{
+				Type:        "CHECK",
+				CheckNumber: "2042",
+				Date:        testDate,
+			},
+			want: false,
+		},
+		{
+			name: "matches check number modulo",
+			pattern: CheckPattern{
+				CheckNumberPattern: &CheckNumberMatcher{Modulo: 10, Offset: 2},
+			},
+			txn: Transaction{
+				Type:        "CHECK",
+				CheckNumber: "1042",
+				Date:        testDate,
+			},
+			want: true,
+		},
+		{
+			name: "no match - invalid check number regex",
+			pattern: CheckPattern{
+				CheckNumberPattern: &CheckNumberMatcher{Regex: "^(10"},
+			},
+			txn: Transaction{
+				Type:        "CHECK",
+				CheckNumber: "1042",
+				Date:        testDate,
+			},
+			want: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -264,6 +465,29 @@ func TestCheckPattern_Matches(t *testing.T) {
 	}
 }
 
+func TestTransaction_CheckPayee(t *testing.T) {
+	tests := []struct {
+		name string
+		txn  Transaction
+		want string
+	}{
+		{name: "payee after check number", txn: Transaction{Type: "CHECK", Name: "CHECK #1234 - Jane's Cleaning"}, want: "Jane's Cleaning"},
+		{name: "abbreviated check prefix", txn: Transaction{Type: "CHECK", Name: "CHK 1001 LAWN CARE"}, want: "LAWN CARE"},
+		{name: "plain payee name", txn: Transaction{Type: "CHECK", Name: "Jane Doe"}, want: "Jane Doe"},
+		{name: "only check number", txn: Transaction{Type: "CHECK", Name: "CHECK #1234"}, want: ""},
+		{name: "merchant name already set", txn: Transaction{Type: "CHECK", Name: "CHECK #1 Landlord", MerchantName: "Landlord LLC"}, want: ""},
+		{name: "not a check", txn: Transaction{Type: "DEBIT", Name: "Coffee Shop"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.txn.CheckPayee(); got != tt.want {
+				t.Errorf("CheckPayee() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 // Helper functions.
 func floatPtr(f float64) *float64 {
 	return &f
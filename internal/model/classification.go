@@ -16,13 +16,17 @@ const (
 
 // Classification represents a transaction after processing.
 type Classification struct {
-	ClassifiedAt    time.Time
-	Category        string
-	Status          ClassificationStatus
-	Notes           string
-	Transaction     Transaction
-	Confidence      float64
-	BusinessPercent float64 // 0-100, percentage that's business-deductible
+	ClassifiedAt               time.Time
+	Category                   string
+	Status                     ClassificationStatus
+	Notes                      string
+	NewCategoryDescription     string       // Description for a category requested via CreateNewCategory; empty lets the AI generate one
+	NewCategoryType            CategoryType // Type for a category requested via CreateNewCategory; empty means expense
+	Transaction                Transaction
+	Confidence                 float64
+	BusinessPercent            float64 // 0-100, percentage that's business-deductible
+	NewCategoryBusinessPercent int     // Default business percentage for a category requested via CreateNewCategory
+	CreateNewCategory          bool    // Category doesn't exist yet and should be created before saving
 }
 
 // PendingClassification represents a transaction awaiting user confirmation.
@@ -37,3 +41,26 @@ type PendingClassification struct {
 	SimilarCount        int
 	IsNewCategory       bool
 }
+
+// CategoryChange is a transaction whose category changed within a window of
+// classification history.
+type CategoryChange struct {
+	ChangedAt   time.Time // When the transaction got its To category
+	From        string    // Category before the window, or the first one in it for newly classified transactions
+	To          string    // Latest category in the window
+	Transaction Transaction
+}
+
+// ConfidenceOutcome pairs an AI classification from the history with where the
+// transaction ended up, to judge how reliable a given confidence was.
+type ConfidenceOutcome struct {
+	TransactionID string
+	AICategory    string  // Category of the transaction's latest AI classification
+	FinalCategory string  // Category it has now
+	Confidence    float64 // Confidence of that AI classification
+}
+
+// Correct reports whether the transaction kept the AI's category.
+func (o ConfidenceOutcome) Correct() bool {
+	return o.AICategory == o.FinalCategory
+}
@@ -0,0 +1,34 @@
+package model
+
+// CoverageMonth counts how many of one month's transactions have a category.
+// Unclassified transactions are either pending (never classified) or skipped
+// (classified as StatusUnclassified, e.g. skipped during review).
+type CoverageMonth struct {
+	Month      string // YYYY-MM
+	Total      int
+	Classified int
+	Pending    int
+	Skipped    int
+}
+
+// Unclassified returns the number of transactions without a category.
+func (m CoverageMonth) Unclassified() int {
+	return m.Pending + m.Skipped
+}
+
+// Percent returns the classified share of the month's transactions (0-100).
+func (m CoverageMonth) Percent() float64 {
+	if m.Total == 0 {
+		return 0
+	}
+	return float64(m.Classified) / float64(m.Total) * 100
+}
+
+// UnclassifiedMerchant summarizes one merchant's transactions without a category.
+type UnclassifiedMerchant struct {
+	Merchant string
+	Count    int
+	Pending  int
+	Skipped  int
+	Amount   float64 // Sum of absolute amounts
+}
@@ -3,9 +3,14 @@ package model
 import (
 	"crypto/sha256"
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 )
 
+// checkPrefixPattern matches check-number boilerplate such as "CHECK #1234 -".
+var checkPrefixPattern = regexp.MustCompile(`(?i)^(check|chk|ck)\b\s*(#|no\.?)?\s*\d*\s*[-:/]?\s*`)
+
 // TransactionDirection indicates whether a transaction is income, expense, or transfer.
 type TransactionDirection string
 
@@ -20,19 +25,21 @@ const (
 
 // Transaction represents a single financial transaction from any source.
 type Transaction struct {
-	Date           time.Time
-	Type           string
-	Name           string
-	MerchantName   string
-	AccountID      string
-	Hash           string
-	ID             string
-	CheckNumber    string
-	Direction      TransactionDirection
-	RefundCategory string
-	Category       []string
-	Amount         float64
-	IsRefund       bool
+	Date             time.Time
+	Type             string
+	Name             string
+	MerchantName     string
+	AccountID        string
+	Hash             string
+	ID               string
+	CheckNumber      string
+	Direction        TransactionDirection
+	RefundCategory   string
+	OriginalCurrency string // ISO-4217 code of a foreign purchase, empty for home currency
+	Category         []string
+	Amount           float64
+	OriginalAmount   float64 // Amount in OriginalCurrency before conversion (always positive)
+	IsRefund         bool
 }
 
 // GenerateHash creates a unique hash for duplicate detection.
@@ -45,3 +52,28 @@ func (t *Transaction) GenerateHash() string {
 	hash := sha256.Sum256([]byte(data))
 	return fmt.Sprintf("%x", hash)
 }
+
+// IsForeignCurrency reports whether the transaction records an original
+// foreign-currency amount alongside the posted home-currency amount.
+func (t *Transaction) IsForeignCurrency() bool {
+	return t.OriginalCurrency != "" && t.OriginalAmount > 0
+}
+
+// EffectiveRate returns the posted amount per unit of the original currency,
+// or zero if the transaction has no foreign-currency information.
+func (t *Transaction) EffectiveRate() float64 {
+	if !t.IsForeignCurrency() {
+		return 0
+	}
+	return t.Amount / t.OriginalAmount
+}
+
+// CheckPayee returns the payee of a CHECK transaction that has no merchant name,
+// extracted from the Name/memo with check-number boilerplate removed. It returns
+// an empty string for other transactions or when no payee text is present.
+func (t *Transaction) CheckPayee() string {
+	if t.Type != "CHECK" || strings.TrimSpace(t.MerchantName) != "" {
+		return ""
+	}
+	return strings.TrimSpace(checkPrefixPattern.ReplaceAllString(strings.TrimSpace(t.Name), ""))
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// TransactionSortField names the field transactions can be ordered by.
+type TransactionSortField string
+
+// Transaction sort fields.
+const (
+	SortByDate     TransactionSortField = "date"
+	SortByAmount   TransactionSortField = "amount"
+	SortByMerchant TransactionSortField = "merchant"
+	SortByCategory TransactionSortField = "category"
+)
+
+// TransactionQuery filters and orders transactions. Zero-valued fields are not applied.
+type TransactionQuery struct {
+	StartDate  *time.Time // Inclusive lower bound on the transaction date
+	EndDate    *time.Time // Inclusive upper bound on the transaction date
+	MinAmount  *float64   // Inclusive lower bound on the absolute amount
+	MaxAmount  *float64   // Inclusive upper bound on the absolute amount
+	Category   string     // Exact classified category, case-insensitive
+	Merchant   string     // Case-insensitive substring of the merchant name or description
+	Direction  TransactionDirection
+	Status     ClassificationStatus // StatusUnclassified matches transactions without a classification
+	SortBy     TransactionSortField // Defaults to SortByDate
+	Limit      int                  // Maximum rows to return; 0 means no limit
+	Descending bool
+	// MissingDirection matches only transactions without a direction, such as
+	// rows imported before directions were tracked. Excludes Direction.
+	MissingDirection bool
+}
+
+// Validate checks that the query's bounds and options are consistent.
+func (q *TransactionQuery) Validate() error {
+	if q.StartDate != nil && q.EndDate != nil && q.StartDate.After(*q.EndDate) {
+		return fmt.Errorf("start date %s is after end date %s",
+			q.StartDate.Format("2006-01-02"), q.EndDate.Format("2006-01-02"))
+	}
+	if q.MinAmount != nil && *q.MinAmount < 0 {
+		return fmt.Errorf("minimum amount cannot be negative")
+	}
+	if q.MinAmount != nil && q.MaxAmount != nil && *q.MinAmount > *q.MaxAmount {
+		return fmt.Errorf("minimum amount %.2f is greater than maximum amount %.2f", *q.MinAmount, *q.MaxAmount)
+	}
+	if q.Limit < 0 {
+		return fmt.Errorf("limit cannot be negative")
+	}
+
+	if q.MissingDirection && q.Direction != "" {
+		return fmt.Errorf("cannot filter by direction %q and missing direction together", q.Direction)
+	}
+
+	switch q.Direction {
+	case "", DirectionIncome, DirectionExpense, DirectionTransfer:
+	default:
+		return fmt.Errorf("invalid direction %q", q.Direction)
+	}
+
+	switch q.Status {
+	case "", StatusUnclassified, StatusClassifiedByRule, StatusClassifiedByAI, StatusUserModified:
+	default:
+		return fmt.Errorf("invalid status %q", q.Status)
+	}
+
+	switch q.SortBy {
+	case "", SortByDate, SortByAmount, SortByMerchant, SortByCategory:
+	default:
+		return fmt.Errorf("invalid sort field %q", q.SortBy)
+	}
+
+	return nil
+}
@@ -14,6 +14,17 @@ const (
 	SourceAutoConfirmed VendorSource = "AUTO_CONFIRMED"
 )
 
+// RegexVendorPrecedence decides which regex vendor wins when several match a merchant.
+// Exact (non-regex) vendors always take precedence over any regex vendor.
+type RegexVendorPrecedence string
+
+const (
+	// PrecedenceUseCount prefers the most-used regex, then the most specific.
+	PrecedenceUseCount RegexVendorPrecedence = "use_count"
+	// PrecedenceSpecificity prefers the regex with the most literal characters, then the most used.
+	PrecedenceSpecificity RegexVendorPrecedence = "specificity"
+)
+
 // Vendor represents a known merchant with a user-confirmed category.
 type Vendor struct {
 	LastUpdated time.Time
@@ -22,4 +33,32 @@ type Vendor struct {
 	Source      VendorSource
 	UseCount    int
 	IsRegex     bool
+	// MultiCategory marks a merchant that spans many categories (Target,
+	// Amazon). It is never used as a rule: its transactions are classified one
+	// by one and no rule is created for it.
+	MultiCategory bool
+}
+
+// VendorRecount records a vendor whose stored use count was corrected.
+type VendorRecount struct {
+	Name     string
+	OldCount int
+	NewCount int
+}
+
+// MerchantHint is a note about a merchant passed to the LLM when classifying it.
+// Unlike a vendor rule it only biases the suggestion; the LLM still decides.
+type MerchantHint struct {
+	UpdatedAt    time.Time
+	MerchantName string
+	Hint         string
+}
+
+// MerchantEmbedding is the embedding vector of a merchant name, used to
+// classify new merchants by their nearest classified neighbor.
+type MerchantEmbedding struct {
+	MerchantName string
+	Model        string    // Embedding model that produced Vector
+	Category     string    // The merchant's most common category; empty if never classified
+	Vector       []float32 // Not stored with Category, which comes from classifications
 }
@@ -0,0 +1,193 @@
+// Package notify sends run summaries to users outside the terminal.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/engine"
+)
+
+// DefaultSMTPPort is the submission port used when none is configured.
+const DefaultSMTPPort = 587
+
+// SMTPConfig holds the configuration for the SMTP notifier.
+type SMTPConfig struct {
+	Host          string
+	Username      string
+	Password      string
+	From          string
+	SubjectPrefix string
+	To            []string
+	Port          int
+}
+
+// Validate checks if the configuration is complete.
+func (c *SMTPConfig) Validate() error {
+	if c.Host == "" {
+		return fmt.Errorf("smtp host is required")
+	}
+	if c.From == "" {
+		return fmt.Errorf("smtp from address is required")
+	}
+	if len(c.To) == 0 {
+		return fmt.Errorf("at least one smtp recipient is required")
+	}
+	if c.Port < 0 || c.Port > 65535 {
+		return fmt.Errorf("invalid smtp port: %d", c.Port)
+	}
+	return nil
+}
+
+// sendFunc matches smtp.SendMail so tests can capture outgoing mail.
+type sendFunc func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+
+// SMTPNotifier emails classification run summaries.
+type SMTPNotifier struct {
+	send   sendFunc
+	now    func() time.Time
+	config SMTPConfig
+}
+
+// NewSMTPNotifier creates a notifier from a validated configuration.
+func NewSMTPNotifier(config SMTPConfig) (*SMTPNotifier, error) {
+	if config.Port == 0 {
+		config.Port = DefaultSMTPPort
+	}
+	if config.SubjectPrefix == "" {
+		config.SubjectPrefix = "[spice]"
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &SMTPNotifier{
+		config: config,
+		send:   smtp.SendMail,
+		now:    time.Now,
+	}, nil
+}
+
+// SendClassificationSummary emails the results of a classify run.
+func (n *SMTPNotifier) SendClassificationSummary(ctx context.Context, summary *engine.BatchClassificationSummary) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	msg, err := n.buildMessage(summary)
+	if err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+
+	var auth smtp.Auth
+	if n.config.Username != "" {
+		auth = smtp.PlainAuth("", n.config.Username, n.config.Password, n.config.Host)
+	}
+
+	addr := net.JoinHostPort(n.config.Host, strconv.Itoa(n.config.Port))
+	if err := n.send(addr, auth, n.config.From, n.config.To, msg); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+// buildMessage renders a multipart/alternative email with plaintext and HTML bodies.
+func (n *SMTPNotifier) buildMessage(summary *engine.BatchClassificationSummary) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", plainTextSummary(summary)},
+		{"text/html; charset=utf-8", htmlSummary(summary)},
+	}
+	for _, p := range parts {
+		part, err := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {p.contentType}})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := part.Write([]byte(p.content)); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	subject := fmt.Sprintf("%s Classification run: %d transactions", n.config.SubjectPrefix, summary.TotalTransactions)
+	if summary.FailedCount > 0 {
+		subject += fmt.Sprintf(", %d failures", summary.FailedCount)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.config.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.config.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", n.now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", writer.Boundary())
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), nil
+}
+
+// summaryRows returns the label/value pairs shared by both email bodies.
+func summaryRows(summary *engine.BatchClassificationSummary) [][2]string {
+	return [][2]string{
+		{"Merchants", strconv.Itoa(summary.TotalMerchants)},
+		{"Transactions", strconv.Itoa(summary.TotalTransactions)},
+		{"Auto-accepted", fmt.Sprintf("%d merchants (%d transactions)", summary.AutoAcceptedCount, summary.AutoAcceptedTxns)},
+		{"Needed review", fmt.Sprintf("%d merchants (%d transactions)", summary.NeedsReviewCount, summary.NeedsReviewTxns)},
+		{"Failed", strconv.Itoa(summary.FailedCount)},
+		{"Processing time", summary.ProcessingTime.Round(time.Second).String()},
+	}
+}
+
+func plainTextSummary(summary *engine.BatchClassificationSummary) string {
+	var b strings.Builder
+	b.WriteString("Classification run summary\n\n")
+	for _, row := range summaryRows(summary) {
+		fmt.Fprintf(&b, "%-16s %s\n", row[0]+":", row[1])
+	}
+
+	if len(summary.FailedMerchants) > 0 {
+		b.WriteString("\nFailed merchants:\n")
+		for _, merchant := range summary.FailedMerchants {
+			fmt.Fprintf(&b, "  - %s\n", merchant)
+		}
+	}
+
+	return b.String()
+}
+
+func htmlSummary(summary *engine.BatchClassificationSummary) string {
+	var b strings.Builder
+	b.WriteString("<html><body>\n<h2>Classification run summary</h2>\n<table>\n")
+	for _, row := range summaryRows(summary) {
+		fmt.Fprintf(&b, "<tr><th align=\"left\">%s</th><td>%s</td></tr>\n", html.EscapeString(row[0]), html.EscapeString(row[1]))
+	}
+	b.WriteString("</table>\n")
+
+	if len(summary.FailedMerchants) > 0 {
+		b.WriteString("<h3>Failed merchants</h3>\n<ul>\n")
+		for _, merchant := range summary.FailedMerchants {
+			fmt.Fprintf(&b, "<li>%s</li>\n", html.EscapeString(merchant))
+		}
+		b.WriteString("</ul>\n")
+	}
+
+	b.WriteString("</body></html>\n")
+	return b.String()
+}
@@ -0,0 +1,86 @@
+package notify
+
+import (
+	"context"
+	"net/smtp"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSMTPConfig_Validate(t *testing.T) {
+	valid := SMTPConfig{Host: "smtp.example.com", From: "spice@example.com", To: []string{"me@example.com"}}
+	require.NoError(t, valid.Validate())
+
+	tests := []struct {
+		mutate func(*SMTPConfig)
+		name   string
+	}{
+		{name: "missing host", mutate: func(c *SMTPConfig) { c.Host = "" }},
+		{name: "missing from", mutate: func(c *SMTPConfig) { c.From = "" }},
+		{name: "missing recipients", mutate: func(c *SMTPConfig) { c.To = nil }},
+		{name: "invalid port", mutate: func(c *SMTPConfig) { c.Port = 70000 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.mutate(&cfg)
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
+
+func TestSMTPNotifier_SendClassificationSummary(t *testing.T) {
+	notifier, err := NewSMTPNotifier(SMTPConfig{
+		Host:     "smtp.example.com",
+		Username: "user",
+		Password: "secret",
+		From:     "spice@example.com",
+		To:       []string{"me@example.com", "you@example.com"},
+	})
+	require.NoError(t, err)
+
+	var (
+		gotAddr string
+		gotAuth smtp.Auth
+		gotFrom string
+		gotTo   []string
+		gotMsg  string
+	)
+	notifier.send = func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotAuth, gotFrom, gotTo, gotMsg = addr, auth, from, to, string(msg)
+		return nil
+	}
+	notifier.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	summary := &engine.BatchClassificationSummary{
+		TotalMerchants:    10,
+		TotalTransactions: 42,
+		AutoAcceptedCount: 8,
+		AutoAcceptedTxns:  38,
+		NeedsReviewCount:  1,
+		NeedsReviewTxns:   3,
+		FailedCount:       1,
+		FailedMerchants:   []string{"Bob's <Shop>"},
+		ProcessingTime:    90 * time.Second,
+	}
+
+	require.NoError(t, notifier.SendClassificationSummary(context.Background(), summary))
+
+	assert.Equal(t, "smtp.example.com:587", gotAddr)
+	assert.NotNil(t, gotAuth)
+	assert.Equal(t, "spice@example.com", gotFrom)
+	assert.Equal(t, []string{"me@example.com", "you@example.com"}, gotTo)
+
+	assert.Contains(t, gotMsg, "Subject: [spice] Classification run: 42 transactions, 1 failures\r\n")
+	assert.Contains(t, gotMsg, "Content-Type: multipart/alternative")
+	assert.Contains(t, gotMsg, "text/plain; charset=utf-8")
+	assert.Contains(t, gotMsg, "text/html; charset=utf-8")
+	assert.Contains(t, gotMsg, "Transactions:    42")
+	assert.Contains(t, gotMsg, "  - Bob's <Shop>")
+	assert.Contains(t, gotMsg, "<li>Bob&#39;s &lt;Shop&gt;</li>")
+}
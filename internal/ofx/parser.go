@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"regexp"
 	"strings"
 
@@ -187,6 +188,9 @@ func (p *Parser) convertTransaction(ofxTx ofxgo.Transaction, accountID string) m
 		tx.CheckNumber = string(ofxTx.CheckNum)
 	}
 
+	// Record the original amount for foreign-currency transactions
+	applyForeignCurrency(&tx, ofxTx)
+
 	// OFX doesn't provide categories, but we could infer some based on transaction type
 	// This is optional and can be expanded later
 	switch tx.Type {
@@ -204,6 +208,37 @@ func (p *Parser) convertTransaction(ofxTx ofxgo.Transaction, accountID string) m
 	return tx
 }
 
+// applyForeignCurrency records the original foreign amount when the OFX feed
+// provides currency information. CURRATE is the ratio of the statement currency
+// to the foreign currency, so home = foreign * rate.
+func applyForeignCurrency(tx *model.Transaction, ofxTx ofxgo.Transaction) {
+	switch {
+	case ofxTx.OrigCurrency != nil:
+		// TRNAMT is already in the statement currency; it was converted from CURSYM
+		if ok, _ := ofxTx.OrigCurrency.Valid(); !ok {
+			return
+		}
+		rate, _ := ofxTx.OrigCurrency.CurRate.Float64()
+		if rate <= 0 {
+			return
+		}
+		tx.OriginalCurrency = ofxTx.OrigCurrency.CurSym.String()
+		tx.OriginalAmount = math.Round(tx.Amount/rate*100) / 100
+	case ofxTx.Currency != nil:
+		// TRNAMT is in CURSYM; convert it to the statement currency
+		if ok, _ := ofxTx.Currency.Valid(); !ok {
+			return
+		}
+		rate, _ := ofxTx.Currency.CurRate.Float64()
+		if rate <= 0 {
+			return
+		}
+		tx.OriginalCurrency = ofxTx.Currency.CurSym.String()
+		tx.OriginalAmount = tx.Amount
+		tx.Amount = math.Round(tx.Amount*rate*100) / 100
+	}
+}
+
 // extractMerchantName tries to get a clean merchant name from OFX data.
 func (p *Parser) extractMerchantName(tx ofxgo.Transaction) string {
 	// Prefer PAYEE if available (cleaner merchant name)
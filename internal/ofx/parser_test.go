@@ -374,6 +374,60 @@ func TestTransactionDirectionFallback(t *testing.T) {
 	assert.Equal(t, 100.00, tx.Amount)
 }
 
+func TestForeignCurrencyTransactions(t *testing.T) {
+	tests := []struct {
+		name             string
+		currencyBlock    string
+		trnAmt           string
+		expectedCurrency string
+		expectedAmount   float64
+		expectedOriginal float64
+	}{
+		{
+			name:             "no currency information",
+			trnAmt:           "-50.00",
+			expectedAmount:   50.00,
+			expectedCurrency: "",
+			expectedOriginal: 0,
+		},
+		{
+			name:             "original currency converted to statement currency",
+			currencyBlock:    "<ORIGCURRENCY>\n<CURRATE>1.10\n<CURSYM>EUR\n</ORIGCURRENCY>",
+			trnAmt:           "-55.00",
+			expectedAmount:   55.00,
+			expectedCurrency: "EUR",
+			expectedOriginal: 50.00,
+		},
+		{
+			name:             "transaction amount in foreign currency",
+			currencyBlock:    "<CURRENCY>\n<CURRATE>0.0068\n<CURSYM>JPY\n</CURRENCY>",
+			trnAmt:           "-10000",
+			expectedAmount:   68.00,
+			expectedCurrency: "JPY",
+			expectedOriginal: 10000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ofxData := generateTestOFX("DEBIT", tt.trnAmt, "2024011510", "CAFE PARIS")
+			if tt.currencyBlock != "" {
+				ofxData = strings.Replace(ofxData, "<NAME>CAFE PARIS\n", "<NAME>CAFE PARIS\n"+tt.currencyBlock+"\n", 1)
+			}
+
+			transactions, err := NewParser().ParseFile(context.Background(), strings.NewReader(ofxData))
+			require.NoError(t, err)
+			require.Len(t, transactions, 1)
+
+			tx := transactions[0]
+			assert.InDelta(t, tt.expectedAmount, tx.Amount, 0.001)
+			assert.Equal(t, tt.expectedCurrency, tx.OriginalCurrency)
+			assert.InDelta(t, tt.expectedOriginal, tx.OriginalAmount, 0.001)
+			assert.Equal(t, tt.expectedCurrency != "", tx.IsForeignCurrency())
+		})
+	}
+}
+
 func TestTransactionDeduplication(t *testing.T) {
 	// Create two identical transactions
 	tx1 := model.Transaction{
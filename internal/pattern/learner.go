@@ -0,0 +1,262 @@
+package pattern
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+// Defaults for LearnOptions.
+const (
+	DefaultMinSupport   = 3
+	DefaultMinAgreement = 0.9
+	// maxLearnedConfidence keeps learned rules below vendor rules and manual review.
+	maxLearnedConfidence = 0.95
+	// minPrefixLength is the shortest merchant prefix worth a regex rule.
+	minPrefixLength = 4
+)
+
+// LearnOptions controls how many corrections it takes to propose a rule.
+type LearnOptions struct {
+	MinSupport   int     // Corrections that must agree before a rule is proposed; below 1 means DefaultMinSupport
+	MinAgreement float64 // Share of the corrections a rule matches that must agree (0-1); 0 means DefaultMinAgreement
+}
+
+// ProposedRule is a pattern rule inferred from the user's manual corrections.
+type ProposedRule struct {
+	Examples  []string // IDs of corrections the rule agrees with
+	Rule      model.PatternRule
+	Support   int // Corrections the rule matches with the same category
+	Conflicts int // Corrections the rule matches with a different category
+}
+
+// LearnRules looks for characteristics shared by user-modified classifications
+// and proposes pattern rules for them, strongest first:
+//
+//   - a merchant the user always puts in one category;
+//   - an amount band within a merchant that the user categorizes differently
+//     from its other transactions;
+//   - a merchant name prefix shared by several merchants with one category.
+//
+// Corrections already covered by an existing rule for the same category are
+// ignored, so accepted proposals aren't offered again.
+func LearnRules(corrections []model.Classification, existing []model.PatternRule, opts LearnOptions) []ProposedRule {
+	if opts.MinSupport < 1 {
+		opts.MinSupport = DefaultMinSupport
+	}
+	if opts.MinAgreement <= 0 {
+		opts.MinAgreement = DefaultMinAgreement
+	}
+
+	matcher := NewMatcher(existing)
+	var relevant []model.Classification
+	for _, c := range corrections {
+		if c.Status != model.StatusUserModified || c.Category == "" {
+			continue
+		}
+		relevant = append(relevant, c)
+	}
+
+	byMerchant := make(map[string][]model.Classification)
+	for _, c := range relevant {
+		key := merchantKey(c.Transaction)
+		byMerchant[key] = append(byMerchant[key], c)
+	}
+
+	var proposals []ProposedRule
+	covered := make(map[string]bool) // Merchants with a whole-merchant proposal
+
+	for _, key := range sortedKeys(byMerchant) {
+		group := byMerchant[key]
+		merchantRule := model.PatternRule{MerchantPattern: key, AmountCondition: string(model.AmountAny)}
+
+		if p, ok := propose(merchantRule, group, relevant, matcher, opts); ok {
+			covered[key] = true
+			proposals = append(proposals, p)
+			continue
+		}
+
+		// Mixed merchant: look for a category confined to its own amount band
+		for _, category := range categoriesOf(group) {
+			low, high := amountBand(group, category)
+			rule := merchantRule
+			rule.AmountCondition = string(model.AmountRange)
+			rule.AmountMin, rule.AmountMax = &low, &high
+			rule.DefaultCategory = category
+			if p, ok := propose(rule, group, relevant, matcher, opts); ok {
+				proposals = append(proposals, p)
+			}
+		}
+	}
+
+	// Several merchants sharing a prefix, such as card descriptors with store numbers
+	byPrefix := make(map[string][]string)
+	for _, key := range sortedKeys(byMerchant) {
+		if covered[key] {
+			continue
+		}
+		if prefix := merchantPrefix(key); prefix != "" {
+			byPrefix[prefix] = append(byPrefix[prefix], key)
+		}
+	}
+	for _, prefix := range sortedKeys(byPrefix) {
+		if len(byPrefix[prefix]) < 2 {
+			continue
+		}
+		var group []model.Classification
+		for _, key := range byPrefix[prefix] {
+			group = append(group, byMerchant[key]...)
+		}
+		rule := model.PatternRule{
+			MerchantPattern: "^" + regexp.QuoteMeta(prefix) + `\b`,
+			IsRegex:         true,
+			AmountCondition: string(model.AmountAny),
+		}
+		if p, ok := propose(rule, group, relevant, matcher, opts); ok {
+			proposals = append(proposals, p)
+		}
+	}
+
+	sort.SliceStable(proposals, func(i, j int) bool {
+		return proposals[i].Support > proposals[j].Support
+	})
+	return proposals
+}
+
+// propose evaluates rule against all corrections and returns a proposal when the
+// corrections it matches agree strongly enough. If rule has no category, the
+// most common category among candidates is used.
+func propose(rule model.PatternRule, candidates, all []model.Classification, existing *MatcherImpl, opts LearnOptions) (ProposedRule, bool) {
+	if rule.DefaultCategory == "" {
+		categories := categoriesOf(candidates)
+		if len(categories) == 0 {
+			return ProposedRule{}, false
+		}
+		rule.DefaultCategory = categories[0]
+	}
+
+	candidate := rule
+	candidate.ID = 1 // The matcher keys compiled regexes by ID
+	matcher := NewMatcher([]Rule{candidate})
+
+	var proposal ProposedRule
+	directions := make(map[model.TransactionDirection]bool)
+	alreadyCovered := true
+	for _, c := range all {
+		if !matcher.matchesRule(c.Transaction, candidate) {
+			continue
+		}
+		if !strings.EqualFold(c.Category, rule.DefaultCategory) {
+			proposal.Conflicts++
+			continue
+		}
+		proposal.Support++
+		proposal.Examples = append(proposal.Examples, c.Transaction.ID)
+		directions[c.Transaction.Direction] = true
+		if !coveredBy(existing, c) {
+			alreadyCovered = false
+		}
+	}
+
+	agreement := float64(proposal.Support) / float64(proposal.Support+proposal.Conflicts)
+	if proposal.Support < opts.MinSupport || agreement < opts.MinAgreement || alreadyCovered {
+		return ProposedRule{}, false
+	}
+
+	// Only pin the direction when every supporting correction had the same one
+	if len(directions) == 1 {
+		for d := range directions {
+			if d != "" {
+				direction := d
+				rule.Direction = &direction
+			}
+		}
+	}
+
+	support := float64(proposal.Support)
+	rule.Confidence = math.Round(math.Min(maxLearnedConfidence, agreement*support/(support+1))*100) / 100
+	rule.Name = learnedRuleName(rule)
+	rule.Description = fmt.Sprintf("Learned from %d manual classifications", proposal.Support)
+	rule.IsActive = true
+	proposal.Rule = rule
+	return proposal, true
+}
+
+// coveredBy reports whether an existing rule already puts c in its category.
+func coveredBy(existing *MatcherImpl, c model.Classification) bool {
+	for _, rule := range existing.rules {
+		if rule.IsActive && strings.EqualFold(rule.DefaultCategory, c.Category) && existing.matchesRule(c.Transaction, rule) {
+			return true
+		}
+	}
+	return false
+}
+
+func learnedRuleName(rule model.PatternRule) string {
+	name := rule.MerchantPattern
+	if rule.IsRegex {
+		name = strings.TrimSuffix(strings.TrimPrefix(name, "^"), `\b`) + "*"
+	}
+	if rule.AmountCondition == string(model.AmountRange) && rule.AmountMin != nil && rule.AmountMax != nil {
+		name += fmt.Sprintf(" $%.2f-$%.2f", *rule.AmountMin, *rule.AmountMax)
+	}
+	return fmt.Sprintf("%s → %s", name, rule.DefaultCategory)
+}
+
+// merchantKey is the lowercased name the matcher compares merchant patterns with.
+func merchantKey(txn model.Transaction) string {
+	if txn.MerchantName != "" {
+		return strings.ToLower(txn.MerchantName)
+	}
+	return strings.ToLower(txn.Name)
+}
+
+// merchantPrefix returns the first word of a merchant name when it is long enough
+// to identify a merchant on its own.
+func merchantPrefix(key string) string {
+	fields := strings.FieldsFunc(key, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	if len(fields) < 2 || len(fields[0]) < minPrefixLength || !strings.HasPrefix(key, fields[0]) {
+		return ""
+	}
+	return fields[0]
+}
+
+// categoriesOf returns the categories in group, most frequent first.
+func categoriesOf(group []model.Classification) []string {
+	counts := make(map[string]int)
+	for _, c := range group {
+		counts[c.Category]++
+	}
+	categories := sortedKeys(counts)
+	sort.SliceStable(categories, func(i, j int) bool {
+		return counts[categories[i]] > counts[categories[j]]
+	})
+	return categories
+}
+
+// amountBand returns the smallest and largest amounts of group's transactions in category.
+func amountBand(group []model.Classification, category string) (float64, float64) {
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, c := range group {
+		if c.Category == category {
+			low = math.Min(low, c.Transaction.Amount)
+			high = math.Max(high, c.Transaction.Amount)
+		}
+	}
+	return low, high
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,107 @@
+package pattern
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func correction(id, merchant string, amount float64, category string) model.Classification {
+	return model.Classification{
+		Transaction: model.Transaction{
+			ID:           id,
+			MerchantName: merchant,
+			Amount:       amount,
+			Direction:    model.DirectionExpense,
+		},
+		Category: category,
+		Status:   model.StatusUserModified,
+	}
+}
+
+func corrections(prefix, merchant string, amounts []float64, category string) []model.Classification {
+	result := make([]model.Classification, len(amounts))
+	for i, amount := range amounts {
+		result[i] = correction(fmt.Sprintf("%s-%d", prefix, i), merchant, amount, category)
+	}
+	return result
+}
+
+func TestLearnRules(t *testing.T) {
+	t.Run("merchant with one category", func(t *testing.T) {
+		input := corrections("gym", "City Gym", []float64{40, 40, 40, 40}, "Fitness")
+		// AI classifications don't count as user corrections
+		input = append(input, model.Classification{
+			Transaction: model.Transaction{ID: "ai", MerchantName: "City Gym", Amount: 40},
+			Category:    "Shopping",
+			Status:      model.StatusClassifiedByAI,
+		})
+
+		proposals := LearnRules(input, nil, LearnOptions{})
+		require.Len(t, proposals, 1)
+		p := proposals[0]
+		assert.Equal(t, "city gym", p.Rule.MerchantPattern)
+		assert.Equal(t, "any", p.Rule.AmountCondition)
+		assert.Equal(t, "Fitness", p.Rule.DefaultCategory)
+		assert.Equal(t, 4, p.Support)
+		assert.Zero(t, p.Conflicts)
+		assert.InDelta(t, 0.8, p.Rule.Confidence, 1e-9)
+		require.NotNil(t, p.Rule.Direction)
+		assert.Equal(t, model.DirectionExpense, *p.Rule.Direction)
+		assert.Equal(t, "city gym → Fitness", p.Rule.Name)
+	})
+
+	t.Run("too few corrections", func(t *testing.T) {
+		input := corrections("gym", "City Gym", []float64{40, 40}, "Fitness")
+		assert.Empty(t, LearnRules(input, nil, LearnOptions{}))
+		assert.Len(t, LearnRules(input, nil, LearnOptions{MinSupport: 2}), 1)
+	})
+
+	t.Run("amount bands within a mixed merchant", func(t *testing.T) {
+		input := corrections("small", "Amazon", []float64{8, 12, 15}, "Books")
+		input = append(input, corrections("large", "Amazon", []float64{300, 450, 900}, "Electronics")...)
+
+		proposals := LearnRules(input, nil, LearnOptions{})
+		require.Len(t, proposals, 2)
+		byCategory := map[string]model.PatternRule{}
+		for _, p := range proposals {
+			byCategory[p.Rule.DefaultCategory] = p.Rule
+		}
+		books := byCategory["Books"]
+		assert.Equal(t, "range", books.AmountCondition)
+		assert.Equal(t, 8.0, *books.AmountMin)
+		assert.Equal(t, 15.0, *books.AmountMax)
+		assert.Equal(t, 900.0, *byCategory["Electronics"].AmountMax)
+	})
+
+	t.Run("overlapping bands are not proposed", func(t *testing.T) {
+		input := corrections("a", "Amazon", []float64{10, 50, 90}, "Books")
+		input = append(input, corrections("b", "Amazon", []float64{20, 60, 80}, "Electronics")...)
+		assert.Empty(t, LearnRules(input, nil, LearnOptions{}))
+	})
+
+	t.Run("shared merchant prefix", func(t *testing.T) {
+		input := []model.Classification{
+			correction("1", "SHELL OIL 1234", 40, "Fuel"),
+			correction("2", "SHELL OIL 5678", 35, "Fuel"),
+			correction("3", "SHELL 9999", 50, "Fuel"),
+		}
+
+		proposals := LearnRules(input, nil, LearnOptions{})
+		require.Len(t, proposals, 1)
+		assert.True(t, proposals[0].Rule.IsRegex)
+		assert.Equal(t, `^shell\b`, proposals[0].Rule.MerchantPattern)
+		assert.Equal(t, 3, proposals[0].Support)
+	})
+
+	t.Run("already covered by an existing rule", func(t *testing.T) {
+		input := corrections("gym", "City Gym", []float64{40, 40, 40}, "Fitness")
+		existing := []model.PatternRule{{
+			ID: 7, MerchantPattern: "City Gym", AmountCondition: "any", DefaultCategory: "Fitness", IsActive: true,
+		}}
+		assert.Empty(t, LearnRules(input, existing, LearnOptions{}))
+	})
+}
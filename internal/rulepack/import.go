@@ -0,0 +1,285 @@
+package rulepack
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
+)
+
+// Mode controls how a pack is combined with the rules already in the database.
+type Mode string
+
+const (
+	// ModeMerge adds what's missing and leaves existing entries untouched.
+	ModeMerge Mode = "merge"
+	// ModeReplace removes existing vendor rules, pattern rules (inactive ones
+	// too) and check patterns before importing, and updates existing
+	// categories from the pack. Categories are never deleted because
+	// classifications reference them.
+	ModeReplace Mode = "replace"
+)
+
+// ImportResult counts what an import changed.
+type ImportResult struct {
+	CategoriesCreated     int
+	CategoriesUpdated     int
+	VendorsImported       int
+	PatternRulesImported  int
+	CheckPatternsImported int
+	Skipped               int // Entries already present, in merge mode or earlier in the pack
+	Removed               int // Existing rules removed in replace mode
+}
+
+// Import validates the pack against the database and then applies it, all in
+// one transaction: nothing is written unless the whole pack is imported.
+func Import(ctx context.Context, store service.Storage, pack *Pack, mode Mode) (*ImportResult, error) {
+	if mode != ModeMerge && mode != ModeReplace {
+		return nil, fmt.Errorf("invalid import mode %q (use merge or replace)", mode)
+	}
+
+	tx, err := store.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := importPack(ctx, tx, pack, mode)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit import: %w", err)
+	}
+	return result, nil
+}
+
+func importPack(ctx context.Context, store service.Storage, pack *Pack, mode Mode) (*ImportResult, error) {
+	existing, err := store.GetCategories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+	if err := pack.Validate(existing); err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{}
+
+	// Rule references are case-insensitive; resolve them to the stored spelling
+	canonical := make(map[string]string, len(existing)+len(pack.Categories))
+	byName := make(map[string]model.Category, len(existing))
+	for _, c := range existing {
+		canonical[strings.ToLower(c.Name)] = c.Name
+		byName[strings.ToLower(c.Name)] = c
+	}
+	if err := importCategories(ctx, store, pack.Categories, byName, canonical, mode, result); err != nil {
+		return result, err
+	}
+	resolve := func(name string) string {
+		if c, ok := canonical[strings.ToLower(name)]; ok {
+			return c
+		}
+		return name
+	}
+
+	if mode == ModeReplace {
+		if err := removeExistingRules(ctx, store, result); err != nil {
+			return result, err
+		}
+	}
+
+	if err := importVendors(ctx, store, pack.Vendors, resolve, mode, result); err != nil {
+		return result, err
+	}
+	if err := importPatternRules(ctx, store, pack.PatternRules, resolve, mode, result); err != nil {
+		return result, err
+	}
+	if err := importCheckPatterns(ctx, store, pack.CheckPatterns, resolve, mode, result); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func importCategories(ctx context.Context, store service.Storage, categories []Category, existing map[string]model.Category, canonical map[string]string, mode Mode, result *ImportResult) error {
+	for _, c := range categories {
+		name := strings.TrimSpace(c.Name)
+		categoryType := c.Type
+		if categoryType == "" {
+			categoryType = model.CategoryTypeExpense
+		}
+
+		if current, ok := existing[strings.ToLower(name)]; ok {
+			if mode != ModeReplace {
+				result.Skipped++
+				continue
+			}
+			if c.Description != "" && c.Description != current.Description {
+				if err := store.UpdateCategory(ctx, current.ID, current.Name, c.Description); err != nil {
+					return fmt.Errorf("failed to update category %q: %w", current.Name, err)
+				}
+			}
+			if c.DefaultBusinessPercent != current.DefaultBusinessPercent {
+				if err := store.UpdateCategoryBusinessPercent(ctx, current.ID, c.DefaultBusinessPercent); err != nil {
+					return fmt.Errorf("failed to update category %q: %w", current.Name, err)
+				}
+			}
+			result.CategoriesUpdated++
+			continue
+		}
+
+		created, err := store.CreateCategoryWithType(ctx, name, c.Description, categoryType)
+		if err != nil {
+			return fmt.Errorf("failed to create category %q: %w", name, err)
+		}
+		if c.DefaultBusinessPercent != 0 {
+			if err := store.UpdateCategoryBusinessPercent(ctx, created.ID, c.DefaultBusinessPercent); err != nil {
+				return fmt.Errorf("failed to set business percent for %q: %w", name, err)
+			}
+		}
+		canonical[strings.ToLower(name)] = created.Name
+		result.CategoriesCreated++
+	}
+	return nil
+}
+
+func removeExistingRules(ctx context.Context, store service.Storage, result *ImportResult) error {
+	vendors, err := store.GetAllVendors(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get vendors: %w", err)
+	}
+	for _, v := range vendors {
+		if err := store.DeleteVendor(ctx, v.Name); err != nil {
+			return fmt.Errorf("failed to delete vendor %q: %w", v.Name, err)
+		}
+		result.Removed++
+	}
+
+	rules, err := store.GetAllPatternRules(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get pattern rules: %w", err)
+	}
+	for _, r := range rules {
+		if err := store.DeletePatternRule(ctx, r.ID); err != nil {
+			return fmt.Errorf("failed to delete pattern rule %q: %w", r.Name, err)
+		}
+		result.Removed++
+	}
+
+	checks, err := store.GetActiveCheckPatterns(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get check patterns: %w", err)
+	}
+	for _, p := range checks {
+		if err := store.DeleteCheckPattern(ctx, p.ID); err != nil {
+			return fmt.Errorf("failed to delete check pattern %q: %w", p.PatternName, err)
+		}
+		result.Removed++
+	}
+	return nil
+}
+
+func importVendors(ctx context.Context, store service.Storage, vendors []Vendor, resolve func(string) string, mode Mode, result *ImportResult) error {
+	now := time.Now()
+	for _, v := range vendors {
+		// Saving upserts an exact vendor under any spelling when vendor
+		// matching ignores case, so look for it the same way. In replace mode
+		// this only finds the pack's own earlier spellings
+		existing, err := findVendor(ctx, store, v)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			result.Skipped++
+			continue
+		}
+		vendor := &model.Vendor{
+			Name:        v.Name,
+			Category:    resolve(v.Category),
+			IsRegex:     v.IsRegex,
+			Source:      model.SourceManual,
+			LastUpdated: now,
+		}
+		if err := store.SaveVendor(ctx, vendor); err != nil {
+			return fmt.Errorf("failed to save vendor %q: %w", v.Name, err)
+		}
+		result.VendorsImported++
+	}
+	return nil
+}
+
+// findVendor returns the stored vendor rule a pack vendor would be saved as,
+// or nil when there is none.
+func findVendor(ctx context.Context, store service.Storage, v Vendor) (*model.Vendor, error) {
+	lookup := store.GetVendorRule
+	if v.IsRegex {
+		lookup = store.GetVendor
+	}
+	vendor, err := lookup(ctx, v.Name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check vendor %q: %w", v.Name, err)
+	}
+	return vendor, nil
+}
+
+func importPatternRules(ctx context.Context, store service.Storage, rules []PatternRule, resolve func(string) string, mode Mode, result *ImportResult) error {
+	present := make(map[string]bool)
+	if mode == ModeMerge {
+		current, err := store.GetActivePatternRules(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get pattern rules: %w", err)
+		}
+		for _, r := range current {
+			present[strings.ToLower(r.Name)] = true
+		}
+	}
+
+	for _, r := range rules {
+		if present[strings.ToLower(r.Name)] {
+			result.Skipped++
+			continue
+		}
+		rule := r.toModel()
+		rule.DefaultCategory = resolve(rule.DefaultCategory)
+		if err := store.CreatePatternRule(ctx, &rule); err != nil {
+			return fmt.Errorf("failed to create pattern rule %q: %w", r.Name, err)
+		}
+		result.PatternRulesImported++
+	}
+	return nil
+}
+
+func importCheckPatterns(ctx context.Context, store service.Storage, patterns []CheckPattern, resolve func(string) string, mode Mode, result *ImportResult) error {
+	present := make(map[string]bool)
+	if mode == ModeMerge {
+		current, err := store.GetActiveCheckPatterns(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get check patterns: %w", err)
+		}
+		for _, p := range current {
+			present[strings.ToLower(p.PatternName)] = true
+		}
+	}
+
+	for _, p := range patterns {
+		if present[strings.ToLower(p.PatternName)] {
+			result.Skipped++
+			continue
+		}
+		pattern := p.toModel()
+		pattern.Category = resolve(pattern.Category)
+		if err := store.CreateCheckPattern(ctx, &pattern); err != nil {
+			return fmt.Errorf("failed to create check pattern %q: %w", p.PatternName, err)
+		}
+		result.CheckPatternsImported++
+	}
+	return nil
+}
@@ -0,0 +1,348 @@
+// Package rulepack bundles categories and classification rules into a single
+// shareable file, so a curated setup can be moved to a new machine or shared.
+package rulepack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
+)
+
+// FormatVersion is the rules pack format written by Export.
+const FormatVersion = 1
+
+// Pack is the on-disk representation of a rules pack.
+type Pack struct {
+	ExportedAt    time.Time      `json:"exported_at"`
+	Categories    []Category     `json:"categories"`
+	Vendors       []Vendor       `json:"vendors"`
+	PatternRules  []PatternRule  `json:"pattern_rules"`
+	CheckPatterns []CheckPattern `json:"check_patterns"`
+	Version       int            `json:"version"`
+}
+
+// Category is a category definition in a pack.
+type Category struct {
+	Name                   string             `json:"name"`
+	Description            string             `json:"description,omitempty"`
+	Type                   model.CategoryType `json:"type,omitempty"`
+	DefaultBusinessPercent int                `json:"default_business_percent,omitempty"`
+}
+
+// Vendor is a vendor rule in a pack.
+type Vendor struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	IsRegex  bool   `json:"is_regex,omitempty"`
+}
+
+// PatternRule is a pattern rule in a pack.
+type PatternRule struct {
+	AmountValue     *float64                    `json:"amount_value,omitempty"`
+	AmountMin       *float64                    `json:"amount_min,omitempty"`
+	AmountMax       *float64                    `json:"amount_max,omitempty"`
+	Direction       *model.TransactionDirection `json:"direction,omitempty"`
+	Name            string                      `json:"name"`
+	Description     string                      `json:"description,omitempty"`
+	MerchantPattern string                      `json:"merchant_pattern,omitempty"`
+	AmountCondition string                      `json:"amount_condition"`
+	DefaultCategory string                      `json:"default_category"`
+	Confidence      float64                     `json:"confidence"`
+	Priority        int                         `json:"priority,omitempty"`
+	IsRegex         bool                        `json:"is_regex,omitempty"`
+}
+
+// CheckPattern is a check pattern in a pack.
+type CheckPattern struct {
+	AmountMin          *float64                  `json:"amount_min,omitempty"`
+	AmountMax          *float64                  `json:"amount_max,omitempty"`
+	CheckNumberPattern *model.CheckNumberMatcher `json:"check_number_pattern,omitempty"`
+	DayOfMonthMin      *int                      `json:"day_of_month_min,omitempty"`
+	DayOfMonthMax      *int                      `json:"day_of_month_max,omitempty"`
+	PatternName        string                    `json:"pattern_name"`
+	Category           string                    `json:"category"`
+	Notes              string                    `json:"notes,omitempty"`
+	MemoPattern        string                    `json:"memo_pattern,omitempty"`
+	Amounts            []float64                 `json:"amounts,omitempty"`
+	Confidence         float64                   `json:"confidence,omitempty"`
+}
+
+// Export collects all active categories, vendor rules, pattern rules and
+// check patterns from the store into a pack.
+func Export(ctx context.Context, store service.Storage) (*Pack, error) {
+	categories, err := store.GetCategories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+	vendors, err := store.GetAllVendors(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vendors: %w", err)
+	}
+	rules, err := store.GetActivePatternRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pattern rules: %w", err)
+	}
+	checks, err := store.GetActiveCheckPatterns(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get check patterns: %w", err)
+	}
+
+	pack := &Pack{
+		Version:       FormatVersion,
+		ExportedAt:    time.Now().UTC(),
+		Categories:    make([]Category, 0, len(categories)),
+		Vendors:       make([]Vendor, 0, len(vendors)),
+		PatternRules:  make([]PatternRule, 0, len(rules)),
+		CheckPatterns: make([]CheckPattern, 0, len(checks)),
+	}
+	for _, c := range categories {
+		pack.Categories = append(pack.Categories, Category{
+			Name:                   c.Name,
+			Description:            c.Description,
+			Type:                   c.Type,
+			DefaultBusinessPercent: c.DefaultBusinessPercent,
+		})
+	}
+	for _, v := range vendors {
+		// A multi-category mark is not a rule
+		if v.MultiCategory {
+			continue
+		}
+		pack.Vendors = append(pack.Vendors, Vendor{Name: v.Name, Category: v.Category, IsRegex: v.IsRegex})
+	}
+	for _, r := range rules {
+		pack.PatternRules = append(pack.PatternRules, PatternRule{
+			Name:            r.Name,
+			Description:     r.Description,
+			MerchantPattern: r.MerchantPattern,
+			IsRegex:         r.IsRegex,
+			AmountCondition: r.AmountCondition,
+			AmountValue:     r.AmountValue,
+			AmountMin:       r.AmountMin,
+			AmountMax:       r.AmountMax,
+			Direction:       r.Direction,
+			DefaultCategory: r.DefaultCategory,
+			Confidence:      r.Confidence,
+			Priority:        r.Priority,
+		})
+	}
+	for _, p := range checks {
+		pack.CheckPatterns = append(pack.CheckPatterns, CheckPattern{
+			PatternName:        p.PatternName,
+			Category:           p.Category,
+			Notes:              p.Notes,
+			MemoPattern:        p.MemoPattern,
+			Amounts:            p.Amounts,
+			AmountMin:          p.AmountMin,
+			AmountMax:          p.AmountMax,
+			CheckNumberPattern: p.CheckNumberPattern,
+			DayOfMonthMin:      p.DayOfMonthMin,
+			DayOfMonthMax:      p.DayOfMonthMax,
+			Confidence:         p.Confidence,
+		})
+	}
+
+	// Stable ordering keeps packs diffable
+	sort.Slice(pack.Vendors, func(i, j int) bool { return pack.Vendors[i].Name < pack.Vendors[j].Name })
+	sort.Slice(pack.PatternRules, func(i, j int) bool { return pack.PatternRules[i].Name < pack.PatternRules[j].Name })
+	sort.Slice(pack.CheckPatterns, func(i, j int) bool { return pack.CheckPatterns[i].PatternName < pack.CheckPatterns[j].PatternName })
+
+	return pack, nil
+}
+
+// Write encodes the pack as indented JSON.
+func (p *Pack) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(p); err != nil {
+		return fmt.Errorf("failed to encode rules pack: %w", err)
+	}
+	return nil
+}
+
+// Read decodes a pack, rejecting unknown fields and unsupported versions.
+func Read(r io.Reader) (*Pack, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	var pack Pack
+	if err := decoder.Decode(&pack); err != nil {
+		return nil, fmt.Errorf("failed to decode rules pack: %w", err)
+	}
+	if pack.Version < 1 || pack.Version > FormatVersion {
+		return nil, fmt.Errorf("unsupported rules pack version %d (supported: 1-%d)", pack.Version, FormatVersion)
+	}
+	return &pack, nil
+}
+
+// Validate checks every entry in the pack. Rules may reference categories
+// defined in the pack or in existing, the categories already in the database.
+// All problems are reported together so a pack can be fixed in one pass.
+func (p *Pack) Validate(existing []model.Category) error {
+	var problems []string
+	addf := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	known := make(map[string]bool, len(existing)+len(p.Categories))
+	for _, c := range existing {
+		known[strings.ToLower(c.Name)] = true
+	}
+
+	seen := make(map[string]bool)
+	for i, c := range p.Categories {
+		name := strings.TrimSpace(c.Name)
+		switch {
+		case name == "":
+			addf("categories[%d]: name is required", i)
+			continue
+		case seen[strings.ToLower(name)]:
+			addf("categories[%d]: duplicate category %q", i, name)
+		}
+		seen[strings.ToLower(name)] = true
+		known[strings.ToLower(name)] = true
+
+		switch c.Type {
+		case "", model.CategoryTypeIncome, model.CategoryTypeExpense, model.CategoryTypeSystem:
+		default:
+			addf("categories[%d]: invalid type %q", i, c.Type)
+		}
+		if c.DefaultBusinessPercent < 0 || c.DefaultBusinessPercent > 100 {
+			addf("categories[%d]: default_business_percent must be between 0 and 100", i)
+		}
+	}
+
+	checkCategory := func(where, category string) {
+		if strings.TrimSpace(category) == "" {
+			addf("%s: category is required", where)
+		} else if !known[strings.ToLower(category)] {
+			addf("%s: unknown category %q", where, category)
+		}
+	}
+
+	seen = make(map[string]bool)
+	for i, v := range p.Vendors {
+		where := fmt.Sprintf("vendors[%d]", i)
+		if strings.TrimSpace(v.Name) == "" {
+			addf("%s: name is required", where)
+		} else if seen[v.Name] {
+			addf("%s: duplicate vendor %q", where, v.Name)
+		}
+		seen[v.Name] = true
+		if v.IsRegex {
+			if _, err := regexp.Compile(v.Name); err != nil {
+				addf("%s: invalid regex %q: %v", where, v.Name, err)
+			}
+		}
+		checkCategory(where, v.Category)
+	}
+
+	seen = make(map[string]bool)
+	for i, r := range p.PatternRules {
+		where := fmt.Sprintf("pattern_rules[%d]", i)
+		if strings.TrimSpace(r.Name) == "" {
+			addf("%s: name is required", where)
+		} else if seen[strings.ToLower(r.Name)] {
+			addf("%s: duplicate pattern rule %q", where, r.Name)
+		}
+		seen[strings.ToLower(r.Name)] = true
+		if err := validatePatternRule(r); err != nil {
+			addf("%s: %v", where, err)
+		}
+		checkCategory(where, r.DefaultCategory)
+	}
+
+	seen = make(map[string]bool)
+	for i, c := range p.CheckPatterns {
+		where := fmt.Sprintf("check_patterns[%d]", i)
+		if seen[strings.ToLower(c.PatternName)] {
+			addf("%s: duplicate check pattern %q", where, c.PatternName)
+		}
+		seen[strings.ToLower(c.PatternName)] = true
+		pattern := c.toModel()
+		if err := pattern.Validate(); err != nil {
+			addf("%s: %v", where, err)
+		}
+		checkCategory(where, c.Category)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid rules pack:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// validatePatternRule mirrors the checks storage applies when a rule is created.
+func validatePatternRule(r PatternRule) error {
+	if r.Confidence < 0 || r.Confidence > 1 {
+		return fmt.Errorf("confidence must be between 0 and 1")
+	}
+	switch model.AmountConditionType(r.AmountCondition) {
+	case model.AmountLessThan, model.AmountLessEqual, model.AmountEqual, model.AmountGreaterEqual, model.AmountGreaterThan:
+		if r.AmountValue == nil {
+			return fmt.Errorf("amount_value required for condition %s", r.AmountCondition)
+		}
+	case model.AmountRange:
+		if r.AmountMin == nil && r.AmountMax == nil {
+			return fmt.Errorf("at least one of amount_min or amount_max required for range condition")
+		}
+	case model.AmountAny:
+	default:
+		return fmt.Errorf("invalid amount condition %q", r.AmountCondition)
+	}
+	if r.Direction != nil {
+		switch *r.Direction {
+		case model.DirectionIncome, model.DirectionExpense, model.DirectionTransfer:
+		default:
+			return fmt.Errorf("invalid direction %q", *r.Direction)
+		}
+	}
+	if r.IsRegex && r.MerchantPattern != "" {
+		if _, err := regexp.Compile(r.MerchantPattern); err != nil {
+			return fmt.Errorf("invalid merchant regex %q: %w", r.MerchantPattern, err)
+		}
+	}
+	return nil
+}
+
+func (r PatternRule) toModel() model.PatternRule {
+	return model.PatternRule{
+		Name:            r.Name,
+		Description:     r.Description,
+		MerchantPattern: r.MerchantPattern,
+		IsRegex:         r.IsRegex,
+		AmountCondition: r.AmountCondition,
+		AmountValue:     r.AmountValue,
+		AmountMin:       r.AmountMin,
+		AmountMax:       r.AmountMax,
+		Direction:       r.Direction,
+		DefaultCategory: r.DefaultCategory,
+		Confidence:      r.Confidence,
+		Priority:        r.Priority,
+		IsActive:        true,
+	}
+}
+
+func (c CheckPattern) toModel() model.CheckPattern {
+	return model.CheckPattern{
+		PatternName:        c.PatternName,
+		Category:           c.Category,
+		Notes:              c.Notes,
+		MemoPattern:        c.MemoPattern,
+		Amounts:            c.Amounts,
+		AmountMin:          c.AmountMin,
+		AmountMax:          c.AmountMax,
+		CheckNumberPattern: c.CheckNumberPattern,
+		DayOfMonthMin:      c.DayOfMonthMin,
+		DayOfMonthMax:      c.DayOfMonthMax,
+		Confidence:         c.Confidence,
+	}
+}
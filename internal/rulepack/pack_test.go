@@ -0,0 +1,210 @@
+package rulepack
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStorage(t *testing.T) *storage.SQLiteStorage {
+	t.Helper()
+	store, err := storage.NewSQLiteStorage(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, store.Migrate(context.Background()))
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func seedRules(t *testing.T, store *storage.SQLiteStorage) {
+	t.Helper()
+	ctx := context.Background()
+
+	_, err := store.CreateCategoryWithType(ctx, "Groceries", "Food for home", model.CategoryTypeExpense)
+	require.NoError(t, err)
+	_, err = store.CreateCategoryWithType(ctx, "Salary", "Paychecks", model.CategoryTypeIncome)
+	require.NoError(t, err)
+	_, err = store.CreateCategory(ctx, "Rent", "Housing")
+	require.NoError(t, err)
+
+	require.NoError(t, store.SaveVendor(ctx, &model.Vendor{Name: "Whole Foods", Category: "Groceries", Source: model.SourceManual}))
+	require.NoError(t, store.SaveVendor(ctx, &model.Vendor{Name: "^TRADER JOE", Category: "Groceries", Source: model.SourceManual, IsRegex: true}))
+
+	income := model.DirectionIncome
+	require.NoError(t, store.CreatePatternRule(ctx, &model.PatternRule{
+		Name:            "Payroll",
+		MerchantPattern: "ACME",
+		AmountCondition: "any",
+		Direction:       &income,
+		DefaultCategory: "Salary",
+		Confidence:      0.9,
+		IsActive:        true,
+	}))
+
+	amount := 1500.0
+	require.NoError(t, store.CreateCheckPattern(ctx, &model.CheckPattern{
+		PatternName: "Monthly rent",
+		Category:    "Rent",
+		Amounts:     []float64{amount},
+	}))
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	source := newTestStorage(t)
+	seedRules(t, source)
+
+	pack, err := Export(ctx, source)
+	require.NoError(t, err)
+	assert.Equal(t, FormatVersion, pack.Version)
+	assert.Len(t, pack.Vendors, 2)
+	assert.Len(t, pack.PatternRules, 1)
+	assert.Len(t, pack.CheckPatterns, 1)
+
+	var buf bytes.Buffer
+	require.NoError(t, pack.Write(&buf))
+	decoded, err := Read(&buf)
+	require.NoError(t, err)
+
+	target := newTestStorage(t)
+	result, err := Import(ctx, target, decoded, ModeMerge)
+	require.NoError(t, err)
+	assert.Equal(t, len(pack.Categories), result.CategoriesCreated)
+	assert.Equal(t, 2, result.VendorsImported)
+	assert.Equal(t, 1, result.PatternRulesImported)
+	assert.Equal(t, 1, result.CheckPatternsImported)
+
+	salary, err := target.GetCategoryByName(ctx, "Salary")
+	require.NoError(t, err)
+	assert.Equal(t, model.CategoryTypeIncome, salary.Type)
+
+	vendor, err := target.GetVendor(ctx, "^TRADER JOE")
+	require.NoError(t, err)
+	assert.True(t, vendor.IsRegex)
+
+	rules, err := target.GetActivePatternRules(ctx)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.NotNil(t, rules[0].Direction)
+	assert.Equal(t, model.DirectionIncome, *rules[0].Direction)
+
+	t.Run("merging again skips everything", func(t *testing.T) {
+		again, err := Import(ctx, target, decoded, ModeMerge)
+		require.NoError(t, err)
+		assert.Zero(t, again.CategoriesCreated)
+		assert.Zero(t, again.VendorsImported)
+		assert.Zero(t, again.PatternRulesImported)
+		assert.Zero(t, again.CheckPatternsImported)
+		assert.Equal(t, len(pack.Categories)+4, again.Skipped)
+	})
+
+	t.Run("merging keeps rules spelled differently", func(t *testing.T) {
+		respelled := *decoded
+		respelled.Vendors = []Vendor{{Name: "WHOLE FOODS", Category: "Rent"}}
+
+		merged, err := Import(ctx, target, &respelled, ModeMerge)
+		require.NoError(t, err)
+		assert.Zero(t, merged.VendorsImported)
+
+		vendor, err := target.GetVendor(ctx, "Whole Foods")
+		require.NoError(t, err)
+		assert.Equal(t, "Groceries", vendor.Category)
+
+		// Case-sensitively it's a rule of its own
+		target.SetVendorCaseSensitive(true)
+		defer target.SetVendorCaseSensitive(false)
+		merged, err = Import(ctx, target, &respelled, ModeMerge)
+		require.NoError(t, err)
+		assert.Equal(t, 1, merged.VendorsImported)
+		require.NoError(t, target.DeleteVendor(ctx, "WHOLE FOODS"))
+	})
+
+	t.Run("replace swaps the rules", func(t *testing.T) {
+		smaller := *decoded
+		smaller.Vendors = []Vendor{{Name: "Costco", Category: "groceries"}}
+		smaller.PatternRules = nil
+		smaller.CheckPatterns = nil
+
+		// Inactive rules go too
+		require.NoError(t, target.CreatePatternRule(ctx, &model.PatternRule{
+			Name:            "Old payroll",
+			MerchantPattern: "INITECH",
+			AmountCondition: "any",
+			DefaultCategory: "Salary",
+			Confidence:      0.9,
+		}))
+
+		replaced, err := Import(ctx, target, &smaller, ModeReplace)
+		require.NoError(t, err)
+		assert.Equal(t, 5, replaced.Removed)
+		assert.Equal(t, 1, replaced.VendorsImported)
+
+		vendors, err := target.GetAllVendors(ctx)
+		require.NoError(t, err)
+		require.Len(t, vendors, 1)
+		assert.Equal(t, "Groceries", vendors[0].Category, "category references resolve case-insensitively")
+
+		rules, err := target.GetAllPatternRules(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, rules)
+	})
+
+	t.Run("a failed import changes nothing", func(t *testing.T) {
+		failing := *decoded
+		// Income categories can't have a business percentage, which only
+		// storage checks
+		failing.Categories = append([]Category(nil), decoded.Categories...)
+		failing.Categories = append(failing.Categories, Category{Name: "Bonus", Type: model.CategoryTypeIncome, DefaultBusinessPercent: 50})
+
+		_, err := Import(ctx, target, &failing, ModeReplace)
+		require.Error(t, err)
+
+		_, err = target.GetCategoryByName(ctx, "Bonus")
+		assert.Error(t, err, "the category created before the failure is rolled back")
+		vendors, err := target.GetAllVendors(ctx)
+		require.NoError(t, err)
+		require.Len(t, vendors, 1)
+		assert.Equal(t, "Costco", vendors[0].Name)
+	})
+}
+
+func TestPackValidate(t *testing.T) {
+	pack := &Pack{
+		Version:    FormatVersion,
+		Categories: []Category{{Name: "Dining"}, {Name: "dining"}, {Name: "Odd", Type: "weird"}},
+		Vendors: []Vendor{
+			{Name: "[bad", Category: "Dining", IsRegex: true},
+			{Name: "Cafe", Category: "Nowhere"},
+		},
+		PatternRules: []PatternRule{
+			{Name: "Big", AmountCondition: "gt", DefaultCategory: "Dining", Confidence: 0.8},
+		},
+		CheckPatterns: []CheckPattern{{PatternName: "", Category: "Existing"}},
+	}
+
+	err := pack.Validate([]model.Category{{Name: "Existing"}})
+	require.Error(t, err)
+	for _, want := range []string{
+		`categories[1]: duplicate category "dining"`,
+		`categories[2]: invalid type "weird"`,
+		`vendors[0]: invalid regex`,
+		`vendors[1]: unknown category "Nowhere"`,
+		`pattern_rules[0]: amount_value required`,
+		`check_patterns[0]: pattern name is required`,
+	} {
+		assert.Contains(t, err.Error(), want)
+	}
+}
+
+func TestRead(t *testing.T) {
+	_, err := Read(strings.NewReader(`{"version": 99}`))
+	assert.ErrorContains(t, err, "unsupported rules pack version")
+
+	_, err = Read(strings.NewReader(`{"version": 1, "surprise": true}`))
+	assert.Error(t, err, "unknown fields are rejected")
+}
@@ -0,0 +1,214 @@
+// Package server exposes the classification engine over a small HTTP API.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/common"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+// maxRequestBytes bounds the size of a classify request body.
+const maxRequestBytes = 1 << 20
+
+// Ranker ranks categories for transactions. *engine.ClassificationEngine satisfies it.
+type Ranker interface {
+	RankTransaction(ctx context.Context, transaction model.Transaction) (model.CategoryRankings, error)
+	GetCategories(ctx context.Context) ([]model.Category, error)
+}
+
+// Server serves the classification API.
+type Server struct {
+	ranker Ranker
+	token  string
+}
+
+// New creates a server that requires the given bearer token on every request.
+func New(ranker Ranker, token string) (*Server, error) {
+	if ranker == nil {
+		return nil, fmt.Errorf("ranker is required")
+	}
+	if token == "" {
+		return nil, fmt.Errorf("api token is required")
+	}
+	return &Server{ranker: ranker, token: token}, nil
+}
+
+// Handler returns the HTTP handler for the API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /classify", s.handleClassify)
+	mux.HandleFunc("GET /categories", s.handleCategories)
+	return s.requireToken(mux)
+}
+
+// ClassifyRequest is the transaction payload accepted by POST /classify.
+type ClassifyRequest struct {
+	Date         string  `json:"date"` // YYYY-MM-DD, defaults to today
+	ID           string  `json:"id"`
+	Name         string  `json:"name"`
+	MerchantName string  `json:"merchant_name"`
+	Direction    string  `json:"direction"`
+	Type         string  `json:"type"`
+	CheckNumber  string  `json:"check_number"`
+	Amount       float64 `json:"amount"`
+}
+
+// RankingResponse is a single ranked category.
+type RankingResponse struct {
+	Category    string  `json:"category"`
+	Description string  `json:"description,omitempty"`
+	Score       float64 `json:"score"`
+	IsNew       bool    `json:"is_new"`
+}
+
+// ClassifyResponse is returned by POST /classify, best match first.
+type ClassifyResponse struct {
+	Rankings []RankingResponse `json:"rankings"`
+}
+
+// CategoryResponse describes a category returned by GET /categories.
+type CategoryResponse struct {
+	Name                   string `json:"name"`
+	Description            string `json:"description,omitempty"`
+	Type                   string `json:"type"`
+	ID                     int    `json:"id"`
+	DefaultBusinessPercent int    `json:"default_business_percent"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (s *Server) requireToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, "invalid or missing api token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleClassify(w http.ResponseWriter, r *http.Request) {
+	var req ClassifyRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+
+	txn, err := req.toTransaction()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rankings, err := s.ranker.RankTransaction(r.Context(), txn)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, common.ErrRateLimit):
+			status = http.StatusTooManyRequests
+		case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+			status = http.StatusServiceUnavailable
+		}
+		slog.Warn("classify request failed", "merchant", txn.MerchantName, "error", err)
+		writeError(w, status, fmt.Sprintf("classification failed: %v", err))
+		return
+	}
+
+	resp := ClassifyResponse{Rankings: make([]RankingResponse, 0, len(rankings))}
+	for _, ranking := range rankings {
+		resp.Rankings = append(resp.Rankings, RankingResponse{
+			Category:    ranking.Category,
+			Description: ranking.Description,
+			Score:       ranking.Score,
+			IsNew:       ranking.IsNew,
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := s.ranker.GetCategories(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("failed to get categories: %v", err))
+		return
+	}
+
+	resp := make([]CategoryResponse, 0, len(categories))
+	for _, category := range categories {
+		resp = append(resp, CategoryResponse{
+			ID:                     category.ID,
+			Name:                   category.Name,
+			Description:            category.Description,
+			Type:                   string(category.Type),
+			DefaultBusinessPercent: category.DefaultBusinessPercent,
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// toTransaction validates the payload and converts it into a transaction.
+func (req ClassifyRequest) toTransaction() (model.Transaction, error) {
+	if req.Name == "" && req.MerchantName == "" {
+		return model.Transaction{}, fmt.Errorf("name or merchant_name is required")
+	}
+
+	txn := model.Transaction{
+		ID:           req.ID,
+		Name:         req.Name,
+		MerchantName: req.MerchantName,
+		Amount:       req.Amount,
+		Type:         strings.ToUpper(req.Type),
+		CheckNumber:  req.CheckNumber,
+		Direction:    model.TransactionDirection(strings.ToLower(req.Direction)),
+		Date:         time.Now(),
+	}
+	if txn.MerchantName == "" {
+		txn.MerchantName = txn.Name
+	}
+	if txn.Name == "" {
+		txn.Name = txn.MerchantName
+	}
+
+	switch txn.Direction {
+	case "", model.DirectionIncome, model.DirectionExpense, model.DirectionTransfer:
+	default:
+		return model.Transaction{}, fmt.Errorf("invalid direction %q (use income, expense or transfer)", req.Direction)
+	}
+
+	if req.Date != "" {
+		date, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			return model.Transaction{}, fmt.Errorf("invalid date format (use YYYY-MM-DD): %w", err)
+		}
+		txn.Date = date
+	}
+
+	return txn, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("failed to write response", "error", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/common"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRanker struct {
+	err      error
+	received model.Transaction
+}
+
+func (f *fakeRanker) RankTransaction(_ context.Context, transaction model.Transaction) (model.CategoryRankings, error) {
+	f.received = transaction
+	if f.err != nil {
+		return nil, f.err
+	}
+	return model.CategoryRankings{
+		{Category: "Groceries", Score: 0.92},
+		{Category: "Dining", Score: 0.05},
+	}, nil
+}
+
+func (f *fakeRanker) GetCategories(_ context.Context) ([]model.Category, error) {
+	return []model.Category{{ID: 1, Name: "Groceries", Type: model.CategoryTypeExpense}}, nil
+}
+
+func doRequest(t *testing.T, handler http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(&fakeRanker{}, "")
+	assert.Error(t, err)
+	_, err = New(nil, "secret")
+	assert.Error(t, err)
+}
+
+func TestServer_Auth(t *testing.T) {
+	srv, err := New(&fakeRanker{}, "secret")
+	require.NoError(t, err)
+	handler := srv.Handler()
+
+	for name, token := range map[string]string{"missing": "", "wrong": "nope"} {
+		t.Run(name, func(t *testing.T) {
+			rec := doRequest(t, handler, http.MethodGet, "/categories", token, "")
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		})
+	}
+}
+
+func TestServer_Classify(t *testing.T) {
+	ranker := &fakeRanker{}
+	srv, err := New(ranker, "secret")
+	require.NoError(t, err)
+	handler := srv.Handler()
+
+	rec := doRequest(t, handler, http.MethodPost, "/classify", "secret",
+		`{"merchant_name":"Whole Foods","amount":85.2,"direction":"expense","date":"2024-03-05"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp ClassifyResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Len(t, resp.Rankings, 2)
+	assert.Equal(t, "Groceries", resp.Rankings[0].Category)
+	assert.InDelta(t, 0.92, resp.Rankings[0].Score, 0.001)
+
+	assert.Equal(t, "Whole Foods", ranker.received.Name, "name falls back to the merchant")
+	assert.Equal(t, model.DirectionExpense, ranker.received.Direction)
+	assert.Equal(t, "2024-03-05", ranker.received.Date.Format("2006-01-02"))
+}
+
+func TestServer_ClassifyErrors(t *testing.T) {
+	tests := []struct {
+		err    error
+		name   string
+		body   string
+		method string
+		status int
+	}{
+		{name: "missing merchant", body: `{"amount":5}`, status: http.StatusBadRequest},
+		{name: "bad json", body: `{`, status: http.StatusBadRequest},
+		{name: "unknown field", body: `{"merchant_name":"A","colour":"red"}`, status: http.StatusBadRequest},
+		{name: "bad direction", body: `{"merchant_name":"A","direction":"up"}`, status: http.StatusBadRequest},
+		{name: "bad date", body: `{"merchant_name":"A","date":"03/05/2024"}`, status: http.StatusBadRequest},
+		{name: "rate limited", body: `{"merchant_name":"A"}`, err: fmt.Errorf("llm: %w", common.ErrRateLimit), status: http.StatusTooManyRequests},
+		{name: "classifier failure", body: `{"merchant_name":"A"}`, err: fmt.Errorf("boom"), status: http.StatusInternalServerError},
+		{name: "wrong method", method: http.MethodGet, status: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, err := New(&fakeRanker{err: tt.err}, "secret")
+			require.NoError(t, err)
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			rec := doRequest(t, srv.Handler(), method, "/classify", "secret", tt.body)
+			assert.Equal(t, tt.status, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestServer_Categories(t *testing.T) {
+	srv, err := New(&fakeRanker{}, "secret")
+	require.NoError(t, err)
+
+	rec := doRequest(t, srv.Handler(), http.MethodGet, "/categories", "secret", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var categories []CategoryResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &categories))
+	require.Len(t, categories, 1)
+	assert.Equal(t, "Groceries", categories[0].Name)
+	assert.Equal(t, "expense", categories[0].Type)
+}
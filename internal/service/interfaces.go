@@ -14,21 +14,29 @@ type Storage interface {
 	SaveTransactions(ctx context.Context, transactions []model.Transaction) error
 	GetTransactionsToClassify(ctx context.Context, fromDate *time.Time) ([]model.Transaction, error)
 	GetTransactionByID(ctx context.Context, id string) (*model.Transaction, error)
+	GetTransactionByHash(ctx context.Context, hash string) (*model.Transaction, error)
+	QueryTransactions(ctx context.Context, query model.TransactionQuery) ([]model.Classification, error)
 	GetTransactionsByCategory(ctx context.Context, categoryName string) ([]model.Transaction, error)
 	GetTransactionsByCategoryID(ctx context.Context, categoryID int) ([]model.Transaction, error)
 	UpdateTransactionCategories(ctx context.Context, fromCategory, toCategory string) error
 	UpdateTransactionCategoriesByID(ctx context.Context, fromCategoryID, toCategoryID int) error
+	UpdateTransactionDirections(ctx context.Context, directions map[string]model.TransactionDirection) error
 	GetTransactionCount(ctx context.Context) (int, error)
 	GetTransactionCountByCategory(ctx context.Context, categoryName string) (int, error)
 	GetEarliestTransactionDate(ctx context.Context) (time.Time, error)
 	GetLatestTransactionDate(ctx context.Context) (time.Time, error)
 	GetCategorySummary(ctx context.Context, start, end time.Time) (map[string]float64, error)
 	GetMerchantSummary(ctx context.Context, start, end time.Time) (map[string]float64, error)
+	GetClassificationCoverage(ctx context.Context, start, end time.Time) ([]model.CoverageMonth, error)
+	GetUnclassifiedMerchants(ctx context.Context, start, end time.Time, limit int) ([]model.UnclassifiedMerchant, error)
+	GetCategoryAmountStats(ctx context.Context) (map[string]model.CategoryAmountStats, error)
 
 	// Vendor operations
 	GetVendor(ctx context.Context, merchantName string) (*model.Vendor, error)
+	GetVendorRule(ctx context.Context, merchantName string) (*model.Vendor, error)
 	SaveVendor(ctx context.Context, vendor *model.Vendor) error
 	DeleteVendor(ctx context.Context, merchantName string) error
+	SetVendorMultiCategory(ctx context.Context, merchantName string, multiCategory bool) error
 	GetAllVendors(ctx context.Context) ([]model.Vendor, error)
 	GetVendorsByCategory(ctx context.Context, categoryName string) ([]model.Vendor, error)
 	GetVendorsByCategoryID(ctx context.Context, categoryID int) ([]model.Vendor, error)
@@ -37,11 +45,39 @@ type Storage interface {
 	UpdateVendorCategories(ctx context.Context, fromCategory, toCategory string) error
 	UpdateVendorCategoriesByID(ctx context.Context, fromCategoryID, toCategoryID int) error
 	FindVendorMatch(ctx context.Context, merchantName string) (*model.Vendor, error)
+	RecomputeVendorUseCounts(ctx context.Context) ([]model.VendorRecount, error)
+
+	// Merchant hint operations
+	SaveMerchantHint(ctx context.Context, hint *model.MerchantHint) error
+	GetMerchantHints(ctx context.Context) ([]model.MerchantHint, error)
+	DeleteMerchantHint(ctx context.Context, merchantName string) error
+
+	// Merchant embedding operations
+	SaveMerchantEmbeddings(ctx context.Context, embeddings []model.MerchantEmbedding) error
+	GetMerchantEmbeddings(ctx context.Context, embeddingModel string) ([]model.MerchantEmbedding, error)
+	GetMerchantsWithoutEmbedding(ctx context.Context, embeddingModel string) ([]string, error)
+
+	// Category alias operations
+	SaveCategoryAlias(ctx context.Context, alias, category string) error
+	GetCategoryAliases(ctx context.Context) ([]model.CategoryAlias, error)
+	DeleteCategoryAlias(ctx context.Context, alias string) error
+	SaveCategorySnapshot(ctx context.Context, snapshot *model.CategorySnapshot) error
+	GetCategorySnapshot(ctx context.Context, id int64) (*model.CategorySnapshot, error)
+	GetCategorySnapshots(ctx context.Context) ([]model.CategorySnapshot, error)
 
 	// Classification operations
 	SaveClassification(ctx context.Context, classification *model.Classification) error
+	SaveClassifications(ctx context.Context, classifications []model.Classification) error
 	GetClassificationsByDateRange(ctx context.Context, start, end time.Time) ([]model.Classification, error)
 	GetClassificationsByConfidence(ctx context.Context, maxConfidence float64, excludeUserModified bool) ([]model.Classification, error)
+	HasClassificationHistory(ctx context.Context, merchantName string) (bool, error)
+	GetCategoryChanges(ctx context.Context, since time.Time) ([]model.CategoryChange, error)
+	SaveClassificationHistory(ctx context.Context, classifications []model.Classification) error
+	GetConfidenceOutcomes(ctx context.Context) ([]model.ConfidenceOutcome, error)
+	FindRefundedPurchase(ctx context.Context, refund model.Transaction, window time.Duration) (*model.Classification, error)
+	MarkTransactionRefund(ctx context.Context, transactionID, category string) error
+	UpdateBusinessPercentByCategory(ctx context.Context, categoryName string, businessPercent int) (int64, error)
+	UpdateBusinessPercentByVendor(ctx context.Context, merchantName string, businessPercent int) (int64, error)
 	ClearAllClassifications(ctx context.Context) error
 
 	// Category operations
@@ -51,7 +87,10 @@ type Storage interface {
 	CreateCategoryWithType(ctx context.Context, name, description string, categoryType model.CategoryType) (*model.Category, error)
 	UpdateCategory(ctx context.Context, id int, name, description string) error
 	UpdateCategoryBusinessPercent(ctx context.Context, id int, businessPercent int) error
+	SetCategoryAlwaysReview(ctx context.Context, id int, alwaysReview bool) error
+	SetCategoryVendorRuleThreshold(ctx context.Context, id int, threshold float64) error
 	DeleteCategory(ctx context.Context, id int) error
+	MergeCategories(ctx context.Context, fromID, toID int) (*model.CategoryMergeResult, error)
 
 	// Check pattern operations
 	CreateCheckPattern(ctx context.Context, pattern *model.CheckPattern) error
@@ -66,6 +105,7 @@ type Storage interface {
 	CreatePatternRule(ctx context.Context, rule *model.PatternRule) error
 	GetPatternRule(ctx context.Context, id int) (*model.PatternRule, error)
 	GetActivePatternRules(ctx context.Context) ([]model.PatternRule, error)
+	GetAllPatternRules(ctx context.Context) ([]model.PatternRule, error)
 	UpdatePatternRule(ctx context.Context, rule *model.PatternRule) error
 	DeletePatternRule(ctx context.Context, id int) error
 	IncrementPatternRuleUseCount(ctx context.Context, id int) error
@@ -77,6 +117,15 @@ type Storage interface {
 	Close() error
 }
 
+// ReportStorage is the read-only subset of Storage used to build reports.
+// It lets the report path run against several databases at once.
+type ReportStorage interface {
+	GetTransactionsToClassify(ctx context.Context, fromDate *time.Time) ([]model.Transaction, error)
+	GetClassificationsByDateRange(ctx context.Context, start, end time.Time) ([]model.Classification, error)
+	GetCategories(ctx context.Context) ([]model.Category, error)
+	Close() error
+}
+
 // Transaction represents a database transaction.
 type Transaction interface {
 	Commit() error
@@ -100,6 +149,7 @@ type CompletionStats struct {
 	AutoClassified    int
 	UserClassified    int
 	NewVendorRules    int
+	Excluded          int // Classified transactions left out of the counts above, such as transfers
 	Duration          time.Duration
 }
 
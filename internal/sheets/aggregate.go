@@ -0,0 +1,344 @@
+package sheets
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/shopspring/decimal"
+)
+
+// minAggregationShard is the fewest classifications worth aggregating on a
+// goroutine of their own; smaller reports are aggregated sequentially.
+const minAggregationShard = 5000
+
+// aggregation holds the rows, totals and summary maps built from a run of
+// classifications, before they are sorted into TabData.
+type aggregation struct {
+	totalIncome         decimal.Decimal
+	totalExpenses       decimal.Decimal
+	totalDeductible     decimal.Decimal
+	vendors             map[string]*VendorSummaryRow
+	categories          map[string]*CategorySummaryRow
+	categoryBusinessPct map[string]int            // Sum of business percentages per expense category
+	vendorConfidence    map[string]float64        // Sum of classification confidences per vendor
+	vendorCategories    map[string]map[string]int // vendor -> category -> transactions
+	months              map[string]*MonthlyFlowRow
+	categoryMonths      map[string]map[string]decimal.Decimal // category -> month -> total
+	vendorLookup        map[string]string                     // vendor -> category
+	categoryLookup      map[string]string                     // category -> type
+	expenses            []ExpenseRow
+	income              []IncomeRow
+	businessExpenses    []BusinessExpenseRow
+	foreignCurrency     []ForeignCurrencyRow
+}
+
+// aggregateClassifications aggregates classifications on up to
+// AggregationConcurrency goroutines. Each takes a contiguous shard and the
+// partial results are merged in shard order, so the result matches a
+// sequential pass over the same classifications.
+func (w *Writer) aggregateClassifications(classifications []model.Classification, categoryTypes map[string]model.CategoryType) *aggregation {
+	shards := w.config.AggregationConcurrency
+	if most := len(classifications) / minAggregationShard; shards > most {
+		shards = most
+	}
+	if shards <= 1 {
+		return w.aggregateShard(classifications, categoryTypes)
+	}
+
+	size := (len(classifications) + shards - 1) / shards
+	parts := make([]*aggregation, shards)
+	var wg sync.WaitGroup
+	for i := range parts {
+		start := i * size
+		end := min(start+size, len(classifications))
+		wg.Add(1)
+		go func(idx int, shard []model.Classification) {
+			defer wg.Done()
+			parts[idx] = w.aggregateShard(shard, categoryTypes)
+		}(i, classifications[start:end])
+	}
+	wg.Wait()
+
+	merged := parts[0]
+	for _, part := range parts[1:] {
+		merged.merge(part)
+	}
+	return merged
+}
+
+// aggregateShard builds rows, totals and summaries from classifications in order.
+func (w *Writer) aggregateShard(classifications []model.Classification, categoryTypes map[string]model.CategoryType) *aggregation {
+	agg := &aggregation{
+		vendors:             make(map[string]*VendorSummaryRow),
+		categories:          make(map[string]*CategorySummaryRow),
+		categoryBusinessPct: make(map[string]int),
+		vendorConfidence:    make(map[string]float64),
+		vendorCategories:    make(map[string]map[string]int),
+		months:              make(map[string]*MonthlyFlowRow),
+		categoryMonths:      make(map[string]map[string]decimal.Decimal),
+		vendorLookup:        make(map[string]string),
+		categoryLookup:      make(map[string]string),
+	}
+
+	for _, class := range classifications {
+		amount := decimal.NewFromFloat(class.Transaction.Amount)
+
+		// Determine if income or expense based on category type
+		isIncome := categoryTypes[class.Category] == model.CategoryTypeIncome
+
+		// Refunds carry their purchase's category and offset its spending
+		notes := class.Notes
+		if class.Transaction.IsRefund && !isIncome {
+			amount = amount.Neg()
+			notes = refundNotes(notes)
+		}
+
+		key := class.Transaction.Hash
+		if key == "" {
+			key = class.Transaction.ID
+		}
+
+		if isIncome {
+			// Add to income tab
+			agg.income = append(agg.income, IncomeRow{
+				Date:     class.Transaction.Date,
+				Amount:   amount,
+				Key:      key,
+				Source:   class.Transaction.MerchantName,
+				Category: class.Category,
+				Notes:    notes,
+			})
+			agg.totalIncome = agg.totalIncome.Add(amount)
+		} else {
+			// Add to expenses tab
+			businessPct := int(class.BusinessPercent)
+			agg.expenses = append(agg.expenses, ExpenseRow{
+				Date:        class.Transaction.Date,
+				Amount:      amount,
+				Key:         key,
+				Vendor:      class.Transaction.MerchantName,
+				Category:    class.Category,
+				BusinessPct: businessPct,
+				Notes:       notes,
+			})
+			agg.totalExpenses = agg.totalExpenses.Add(amount)
+			agg.categoryBusinessPct[class.Category] += businessPct
+
+			// Add to business expenses if applicable
+			if businessPct > 0 {
+				deductible := amount.Mul(decimal.NewFromFloat(float64(businessPct) / 100))
+				if w.config.DeductibleRounding == DeductibleRoundingLine {
+					deductible = deductible.Round(2)
+				}
+				agg.businessExpenses = append(agg.businessExpenses, BusinessExpenseRow{
+					Date:             class.Transaction.Date,
+					Vendor:           class.Transaction.MerchantName,
+					Category:         class.Category,
+					OriginalAmount:   amount,
+					BusinessPct:      businessPct,
+					DeductibleAmount: deductible,
+					Notes:            notes,
+				})
+				agg.totalDeductible = agg.totalDeductible.Add(deductible)
+			}
+		}
+
+		// Track foreign-currency transactions for the FX report
+		if class.Transaction.IsForeignCurrency() {
+			agg.foreignCurrency = append(agg.foreignCurrency, ForeignCurrencyRow{
+				Date:             class.Transaction.Date,
+				Vendor:           class.Transaction.MerchantName,
+				Category:         class.Category,
+				OriginalCurrency: class.Transaction.OriginalCurrency,
+				OriginalAmount:   decimal.NewFromFloat(class.Transaction.OriginalAmount),
+				PostedAmount:     amount,
+				IsIncome:         isIncome,
+			})
+		}
+
+		// Update vendor summary
+		vendorKey := class.Transaction.MerchantName
+		if vendor, exists := agg.vendors[vendorKey]; exists {
+			vendor.TotalAmount = vendor.TotalAmount.Add(amount)
+			vendor.TransactionCount++
+		} else {
+			agg.vendors[vendorKey] = &VendorSummaryRow{
+				VendorName:         vendorKey,
+				AssociatedCategory: class.Category,
+				TotalAmount:        amount,
+				TransactionCount:   1,
+			}
+		}
+		// Track vendor -> category mapping for lookup table
+		agg.vendorLookup[vendorKey] = class.Category
+		agg.vendorConfidence[vendorKey] += class.Confidence
+		if agg.vendorCategories[vendorKey] == nil {
+			agg.vendorCategories[vendorKey] = make(map[string]int)
+		}
+		agg.vendorCategories[vendorKey][class.Category]++
+
+		// Update category summary
+		categoryKey := class.Category
+		categoryType := "Expense"
+		if isIncome {
+			categoryType = "Income"
+		}
+
+		monthIndex := class.Transaction.Date.Month() - 1
+		if cat, exists := agg.categories[categoryKey]; exists {
+			cat.TotalAmount = cat.TotalAmount.Add(amount)
+			cat.TransactionCount++
+			cat.MonthlyAmounts[monthIndex] = cat.MonthlyAmounts[monthIndex].Add(amount)
+		} else {
+			monthlyAmounts := [12]decimal.Decimal{}
+			monthlyAmounts[monthIndex] = amount
+
+			agg.categories[categoryKey] = &CategorySummaryRow{
+				CategoryName:     categoryKey,
+				Type:             categoryType,
+				TotalAmount:      amount,
+				TransactionCount: 1,
+				MonthlyAmounts:   monthlyAmounts,
+			}
+		}
+		// Track category -> type mapping for lookup table
+		agg.categoryLookup[categoryKey] = categoryType
+
+		// Update monthly flow
+		monthKey := class.Transaction.Date.Format(monthKeyLayout)
+		if month, exists := agg.months[monthKey]; exists {
+			if isIncome {
+				month.TotalIncome = month.TotalIncome.Add(amount)
+			} else {
+				month.TotalExpenses = month.TotalExpenses.Add(amount)
+			}
+		} else {
+			row := &MonthlyFlowRow{
+				Month: monthKey,
+			}
+			if isIncome {
+				row.TotalIncome = amount
+			} else {
+				row.TotalExpenses = amount
+			}
+			agg.months[monthKey] = row
+		}
+		if agg.categoryMonths[categoryKey] == nil {
+			agg.categoryMonths[categoryKey] = make(map[string]decimal.Decimal)
+		}
+		agg.categoryMonths[categoryKey][monthKey] = agg.categoryMonths[categoryKey][monthKey].Add(amount)
+	}
+
+	return agg
+}
+
+// merge folds a later shard into a. Rows are appended after a's, a vendor keeps
+// the category it was first seen with, and lookups take the last one seen, as
+// a sequential pass would.
+func (a *aggregation) merge(b *aggregation) {
+	a.expenses = append(a.expenses, b.expenses...)
+	a.income = append(a.income, b.income...)
+	a.businessExpenses = append(a.businessExpenses, b.businessExpenses...)
+	a.foreignCurrency = append(a.foreignCurrency, b.foreignCurrency...)
+	a.totalIncome = a.totalIncome.Add(b.totalIncome)
+	a.totalExpenses = a.totalExpenses.Add(b.totalExpenses)
+	a.totalDeductible = a.totalDeductible.Add(b.totalDeductible)
+
+	for name, row := range b.vendors {
+		vendor, exists := a.vendors[name]
+		if !exists {
+			a.vendors[name] = row
+			continue
+		}
+		vendor.TotalAmount = vendor.TotalAmount.Add(row.TotalAmount)
+		vendor.TransactionCount += row.TransactionCount
+	}
+	for vendor, category := range b.vendorLookup {
+		a.vendorLookup[vendor] = category
+	}
+	for vendor, confidence := range b.vendorConfidence {
+		a.vendorConfidence[vendor] += confidence
+	}
+	for vendor, counts := range b.vendorCategories {
+		totals, exists := a.vendorCategories[vendor]
+		if !exists {
+			a.vendorCategories[vendor] = counts
+			continue
+		}
+		for category, count := range counts {
+			totals[category] += count
+		}
+	}
+
+	for name, row := range b.categories {
+		cat, exists := a.categories[name]
+		if !exists {
+			a.categories[name] = row
+			continue
+		}
+		cat.TotalAmount = cat.TotalAmount.Add(row.TotalAmount)
+		cat.TransactionCount += row.TransactionCount
+		for i := range cat.MonthlyAmounts {
+			cat.MonthlyAmounts[i] = cat.MonthlyAmounts[i].Add(row.MonthlyAmounts[i])
+		}
+	}
+	for category, pct := range b.categoryBusinessPct {
+		a.categoryBusinessPct[category] += pct
+	}
+	for category, categoryType := range b.categoryLookup {
+		a.categoryLookup[category] = categoryType
+	}
+
+	for key, row := range b.months {
+		month, exists := a.months[key]
+		if !exists {
+			a.months[key] = row
+			continue
+		}
+		month.TotalIncome = month.TotalIncome.Add(row.TotalIncome)
+		month.TotalExpenses = month.TotalExpenses.Add(row.TotalExpenses)
+	}
+	for category, months := range b.categoryMonths {
+		totals, exists := a.categoryMonths[category]
+		if !exists {
+			a.categoryMonths[category] = months
+			continue
+		}
+		for month, amount := range months {
+			totals[month] = totals[month].Add(amount)
+		}
+	}
+}
+
+// vendorConsistency returns the share of a vendor's transactions that are in
+// its most common category. A vendor with a single transaction is trivially
+// consistent.
+func vendorConsistency(counts map[string]int, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	most := 0
+	for _, count := range counts {
+		most = max(most, count)
+	}
+	return float64(most) / float64(total)
+}
+
+// monthKeyLayout formats the month keys of aggregation.months.
+const monthKeyLayout = "January 2006"
+
+// sortedMonthKeys returns the keys of months in chronological order.
+func sortedMonthKeys(months map[string]*MonthlyFlowRow) []string {
+	keys := make([]string, 0, len(months))
+	for key := range months {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, _ := time.Parse(monthKeyLayout, keys[i])
+		b, _ := time.Parse(monthKeyLayout, keys[j])
+		return a.Before(b)
+	})
+	return keys
+}
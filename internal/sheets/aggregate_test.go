@@ -0,0 +1,96 @@
+package sheets
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syntheticReport builds n classifications across a year with a mix of
+// income, refunds, business expenses and foreign-currency transactions.
+func syntheticReport(n int) ([]model.Classification, []model.Category, *service.ReportSummary) {
+	categories := []model.Category{
+		{ID: 1, Name: "Salary", Type: model.CategoryTypeIncome},
+		{ID: 2, Name: "Groceries", Type: model.CategoryTypeExpense},
+		{ID: 3, Name: "Software", Type: model.CategoryTypeExpense, DefaultBusinessPercent: 100},
+		{ID: 4, Name: "Travel", Type: model.CategoryTypeExpense},
+		{ID: 5, Name: "Meals", Type: model.CategoryTypeExpense},
+	}
+
+	rng := rand.New(rand.NewSource(42))
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	classifications := make([]model.Classification, n)
+	for i := range classifications {
+		category := categories[rng.Intn(len(categories))]
+		txn := model.Transaction{
+			ID:           fmt.Sprintf("txn-%d", i),
+			Hash:         fmt.Sprintf("hash-%d", i),
+			Date:         start.AddDate(0, 0, rng.Intn(366)),
+			MerchantName: fmt.Sprintf("Merchant %d", rng.Intn(500)),
+			Amount:       float64(rng.Intn(100000)) / 100,
+			IsRefund:     rng.Intn(50) == 0,
+		}
+		if rng.Intn(20) == 0 {
+			txn.OriginalCurrency = "EUR"
+			txn.OriginalAmount = txn.Amount * 0.9
+		}
+		classifications[i] = model.Classification{
+			Transaction:     txn,
+			Category:        category.Name,
+			BusinessPercent: float64(rng.Intn(3) * 50),
+			Status:          model.StatusClassifiedByAI,
+			// Quarters sum exactly in any order, so shards can't change the averages
+			Confidence: float64(rng.Intn(5)) / 4,
+		}
+	}
+
+	summary := &service.ReportSummary{
+		DateRange: service.DateRange{Start: start, End: start.AddDate(1, 0, 0)},
+	}
+	return classifications, categories, summary
+}
+
+func TestWriter_aggregateDataConcurrency(t *testing.T) {
+	classifications, categories, summary := syntheticReport(4 * minAggregationShard)
+
+	render := func(t *testing.T, concurrency int) string {
+		t.Helper()
+		writer := &Writer{logger: testLogger(), config: Config{AggregationConcurrency: concurrency}}
+		data, err := writer.aggregateData(classifications, summary, categories)
+		require.NoError(t, err)
+		out, err := json.Marshal(data)
+		require.NoError(t, err)
+		return string(out)
+	}
+
+	sequential := render(t, 1)
+	for _, concurrency := range []int{2, 3, 4, 16} {
+		t.Run(fmt.Sprintf("concurrency=%d", concurrency), func(t *testing.T) {
+			assert.Equal(t, sequential, render(t, concurrency))
+		})
+	}
+}
+
+func BenchmarkWriter_aggregateData(b *testing.B) {
+	classifications, categories, summary := syntheticReport(100000)
+
+	for _, concurrency := range []int{1, 4, 8} {
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			writer := &Writer{logger: testLogger(), config: Config{AggregationConcurrency: concurrency}}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := writer.aggregateData(classifications, summary, categories); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -5,31 +5,89 @@ import (
 	"fmt"
 	"os"
 	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// DefaultFormattingBatchSize keeps each formatting batchUpdate well under the
+// Sheets API per-request limits, even for large reports.
+const DefaultFormattingBatchSize = 500
+
+// DefaultWriteConcurrency is how many tabs are written at once by default: a
+// large report finishes much sooner, but stays well under the write quota.
+const DefaultWriteConcurrency = 3
+
+// DeductibleRounding controls how business deductible amounts are rounded to cents.
+type DeductibleRounding string
+
+// Deductible rounding policies.
+const (
+	// DeductibleRoundingNone keeps exact fractional-cent deductibles and totals.
+	DeductibleRoundingNone DeductibleRounding = "none"
+	// DeductibleRoundingLine rounds each deductible; totals are sums of the rounded lines.
+	DeductibleRoundingLine DeductibleRounding = "line"
+	// DeductibleRoundingTotal keeps line items exact and rounds only the totals.
+	DeductibleRoundingTotal DeductibleRounding = "total"
+)
+
+// SignConvention controls the sign of amounts written to the Expenses, Income
+// and Monthly Flow tabs. Amounts are stored as absolute values, so the default
+// writes both income and expenses as positive numbers.
+type SignConvention string
+
+// Sign conventions.
+const (
+	// SignConventionPositive writes income and expenses as positive amounts.
+	SignConventionPositive SignConvention = "positive"
+	// SignConventionExpensesNegative writes expenses as negative amounts, so net flow is a plain sum.
+	SignConventionExpensesNegative SignConvention = "expenses_negative"
+	// SignConventionIncomeNegative writes income as negative amounts, as in double-entry ledgers.
+	SignConventionIncomeNegative SignConvention = "income_negative"
 )
 
 // Config holds the configuration for the Google Sheets writer.
 type Config struct {
-	ClientID           string
-	ClientSecret       string
-	RefreshToken       string
-	ServiceAccountPath string
-	SpreadsheetID      string
-	SpreadsheetName    string
-	TimeZone           string
-	BatchSize          int
-	RetryAttempts      int
-	RetryDelay         time.Duration
-	EnableFormatting   bool
+	ClientID              string
+	ClientSecret          string
+	RefreshToken          string
+	ServiceAccountPath    string
+	SpreadsheetID         string
+	SpreadsheetName       string
+	TimeZone              string
+	DeductibleRounding    DeductibleRounding // Empty means DeductibleRoundingNone
+	SignConvention        SignConvention     // Empty means SignConventionPositive
+	BatchSize             int
+	FormattingBatchSize   int // Max formatting requests per batchUpdate call
+	FormattingConcurrency int // Number of formatting batches applied in parallel
+	// WriteConcurrency is the number of tabs whose values are written in
+	// parallel once the lookup tabs are written; 0 or 1 writes them one by one.
+	// Keep it low to stay under the Sheets per-minute write quota.
+	WriteConcurrency int
+	// AggregationConcurrency is the number of goroutines that aggregate
+	// classifications into report rows; 0 or 1 aggregates sequentially.
+	AggregationConcurrency int
+	RetryAttempts          int
+	RetryDelay             time.Duration
+	EnableFormatting       bool
+	// Incremental updates the Expenses and Income tabs in place, keyed by
+	// transaction hash, instead of clearing and rewriting them.
+	Incremental bool
+	// VendorConfidence adds average confidence and category consistency
+	// columns to the Vendor Summary tab.
+	VendorConfidence bool
 }
 
 // DefaultConfig returns a Config with sensible defaults.
 func DefaultConfig() Config {
 	return Config{
-		EnableFormatting: true,
-		TimeZone:         "America/New_York",
-		BatchSize:        1000,
-		RetryAttempts:    3,
-		RetryDelay:       time.Second,
+		EnableFormatting:      true,
+		TimeZone:              "America/New_York",
+		BatchSize:             1000,
+		FormattingBatchSize:   DefaultFormattingBatchSize,
+		FormattingConcurrency: 1,
+		WriteConcurrency:      DefaultWriteConcurrency,
+		RetryAttempts:         3,
+		RetryDelay:            time.Second,
 	}
 }
 
@@ -79,6 +137,23 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("batch size must be positive")
 	}
 
+	// Validate formatting settings (zero means use the default)
+	if c.FormattingBatchSize < 0 {
+		return fmt.Errorf("formatting batch size cannot be negative")
+	}
+
+	if c.FormattingConcurrency < 0 {
+		return fmt.Errorf("formatting concurrency cannot be negative")
+	}
+
+	if c.WriteConcurrency < 0 {
+		return fmt.Errorf("write concurrency cannot be negative")
+	}
+
+	if err := c.ValidateReport(); err != nil {
+		return err
+	}
+
 	// Validate retry settings
 	if c.RetryAttempts < 0 {
 		return fmt.Errorf("retry attempts cannot be negative")
@@ -90,3 +165,35 @@ func (c *Config) Validate() error {
 
 	return nil
 }
+
+// ValidateReport checks the settings that shape the report data: aggregation,
+// deductible rounding and sign convention. Writers that don't talk to Google
+// Sheets only need these.
+func (c *Config) ValidateReport() error {
+	if c.AggregationConcurrency < 0 {
+		return fmt.Errorf("aggregation concurrency cannot be negative")
+	}
+
+	switch c.DeductibleRounding {
+	case "", DeductibleRoundingNone, DeductibleRoundingLine, DeductibleRoundingTotal:
+	default:
+		return fmt.Errorf("invalid deductible rounding %q (use none, line or total)", c.DeductibleRounding)
+	}
+
+	switch c.SignConvention {
+	case "", SignConventionPositive, SignConventionExpensesNegative, SignConventionIncomeNegative:
+	default:
+		return fmt.Errorf("invalid sign convention %q (use positive, expenses_negative or income_negative)", c.SignConvention)
+	}
+
+	return nil
+}
+
+// RoundDeductibleTotal rounds a deductible total to the cent when the rounding
+// policy applies to totals. Line rounding already yields whole-cent totals.
+func (c Config) RoundDeductibleTotal(total decimal.Decimal) decimal.Decimal {
+	if c.DeductibleRounding == DeductibleRoundingTotal {
+		return total.Round(2)
+	}
+	return total
+}
@@ -0,0 +1,108 @@
+package sheets
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/shopspring/decimal"
+)
+
+// TimeSeriesPoint is one month of the Monthly Flow data, for charting outside
+// of Google Sheets.
+type TimeSeriesPoint struct {
+	Month          time.Time // First day of the month
+	Categories     map[string]decimal.Decimal
+	Income         decimal.Decimal
+	Expenses       decimal.Decimal
+	Net            decimal.Decimal // Income - Expenses
+	RunningBalance decimal.Decimal
+}
+
+// TimeSeriesOptions configures BuildTimeSeries.
+type TimeSeriesOptions struct {
+	// From and To, if set, bound the series to whole months; months without
+	// transactions in between are included with zero totals.
+	From, To time.Time
+	// ByCategory fills each point's Categories with per-category totals.
+	ByCategory bool
+	// AggregationConcurrency is passed on as Config.AggregationConcurrency.
+	AggregationConcurrency int
+}
+
+// BuildTimeSeries aggregates classifications into a monthly cash flow series
+// the same way the Monthly Flow tab is built. Amounts are always positive
+// (SignConventionPositive); refunds reduce their category's expenses.
+func BuildTimeSeries(classifications []model.Classification, categories []model.Category, opts TimeSeriesOptions) []TimeSeriesPoint {
+	categoryTypes := make(map[string]model.CategoryType, len(categories))
+	for i := range categories {
+		categoryTypes[categories[i].Name] = categories[i].Type
+	}
+
+	w := &Writer{config: Config{AggregationConcurrency: opts.AggregationConcurrency}}
+	agg := w.aggregateClassifications(classifications, categoryTypes)
+
+	months := make(map[time.Time]*MonthlyFlowRow, len(agg.months))
+	for key, row := range agg.months {
+		month, err := time.Parse(monthKeyLayout, key)
+		if err != nil {
+			continue
+		}
+		months[month] = row
+	}
+
+	first, last := firstOfMonth(opts.From), firstOfMonth(opts.To)
+	for month := range months {
+		if opts.From.IsZero() && (first.IsZero() || month.Before(first)) {
+			first = month
+		}
+		if opts.To.IsZero() && month.After(last) {
+			last = month
+		}
+	}
+	if first.IsZero() || last.IsZero() || last.Before(first) {
+		return nil
+	}
+
+	var names []string
+	if opts.ByCategory {
+		names = make([]string, 0, len(agg.categoryMonths))
+		for name := range agg.categoryMonths {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	var points []TimeSeriesPoint
+	runningBalance := decimal.Zero
+	for month := first; !month.After(last); month = month.AddDate(0, 1, 0) {
+		point := TimeSeriesPoint{Month: month}
+		if row, ok := months[month]; ok {
+			point.Income = row.TotalIncome
+			point.Expenses = row.TotalExpenses
+		}
+		point.Net = point.Income.Sub(point.Expenses)
+		runningBalance = runningBalance.Add(point.Net)
+		point.RunningBalance = runningBalance
+
+		if opts.ByCategory {
+			point.Categories = make(map[string]decimal.Decimal, len(names))
+			key := month.Format(monthKeyLayout)
+			for _, name := range names {
+				point.Categories[name] = agg.categoryMonths[name][key]
+			}
+		}
+		points = append(points, point)
+	}
+
+	return points
+}
+
+// firstOfMonth returns midnight UTC on the first day of t's month, or the zero
+// time for a zero t.
+func firstOfMonth(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
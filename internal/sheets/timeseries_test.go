@@ -0,0 +1,68 @@
+package sheets
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildTimeSeries(t *testing.T) {
+	categories := []model.Category{
+		{Name: "Salary", Type: model.CategoryTypeIncome},
+		{Name: "Groceries", Type: model.CategoryTypeExpense},
+	}
+	classification := func(category string, amount float64, date time.Time, refund bool) model.Classification {
+		return model.Classification{
+			Category:    category,
+			Transaction: model.Transaction{Amount: amount, Date: date, IsRefund: refund, MerchantName: "Shop"},
+		}
+	}
+	classifications := []model.Classification{
+		classification("Groceries", 120, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), false),
+		classification("Salary", 1000, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), false),
+		classification("Groceries", 100, time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC), false),
+		classification("Groceries", 20, time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC), true),
+	}
+
+	t.Run("spans the data, filling gaps", func(t *testing.T) {
+		points := BuildTimeSeries(classifications, categories, TimeSeriesOptions{})
+		require.Len(t, points, 3)
+
+		assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), points[0].Month)
+		assert.Equal(t, "1000", points[0].Income.String())
+		assert.Equal(t, "80", points[0].Expenses.String(), "refunds reduce expenses")
+		assert.Equal(t, "920", points[0].Net.String())
+
+		assert.True(t, points[1].Net.IsZero(), "February has no transactions")
+		assert.Equal(t, "920", points[1].RunningBalance.String())
+
+		assert.Equal(t, "-120", points[2].Net.String())
+		assert.Equal(t, "800", points[2].RunningBalance.String())
+		assert.Nil(t, points[2].Categories)
+	})
+
+	t.Run("bounded with categories", func(t *testing.T) {
+		points := BuildTimeSeries(classifications, categories, TimeSeriesOptions{
+			From:       time.Date(2023, 12, 15, 0, 0, 0, 0, time.UTC),
+			To:         time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			ByCategory: true,
+		})
+		require.Len(t, points, 2)
+		assert.Equal(t, time.December, points[0].Month.Month())
+		assert.True(t, points[0].Categories["Groceries"].IsZero())
+		assert.Equal(t, "80", points[1].Categories["Groceries"].String())
+		assert.Equal(t, "1000", points[1].Categories["Salary"].String())
+	})
+
+	t.Run("no data", func(t *testing.T) {
+		assert.Empty(t, BuildTimeSeries(nil, categories, TimeSeriesOptions{}))
+	})
+}
+
+func TestSortedMonthKeys(t *testing.T) {
+	months := map[string]*MonthlyFlowRow{"April 2024": {}, "February 2024": {}, "December 2023": {}}
+	assert.Equal(t, []string{"December 2023", "February 2024", "April 2024"}, sortedMonthKeys(months))
+}
@@ -10,6 +10,7 @@ import (
 type ExpenseRow struct {
 	Date        time.Time
 	Amount      decimal.Decimal
+	Key         string // Transaction hash identifying the row in incremental mode
 	Vendor      string
 	Category    string
 	Notes       string
@@ -20,6 +21,7 @@ type ExpenseRow struct {
 type IncomeRow struct {
 	Date     time.Time
 	Amount   decimal.Decimal
+	Key      string // Transaction hash identifying the row in incremental mode
 	Source   string // vendor/payer
 	Category string
 	Notes    string
@@ -31,6 +33,8 @@ type VendorSummaryRow struct {
 	AssociatedCategory string
 	TotalAmount        decimal.Decimal
 	TransactionCount   int
+	AverageConfidence  float64 // Mean classification confidence, 0-1
+	Consistency        float64 // Share of transactions in the vendor's most common category, 0-1
 }
 
 // CategorySummaryRow represents a single row in the Category Summary tab.
@@ -59,10 +63,24 @@ type MonthlyFlowRow struct {
 	Month          string // e.g., "January 2024"
 	TotalIncome    decimal.Decimal
 	TotalExpenses  decimal.Decimal
-	NetFlow        decimal.Decimal // Income - Expenses
+	NetFlow        decimal.Decimal // Income - Expenses, whatever the sign convention
 	RunningBalance decimal.Decimal
 }
 
+// ForeignCurrencyRow represents a single row in the Foreign Currency tab.
+type ForeignCurrencyRow struct {
+	Date             time.Time
+	Vendor           string
+	Category         string
+	OriginalCurrency string
+	OriginalAmount   decimal.Decimal
+	PostedAmount     decimal.Decimal // Amount in the home currency
+	EffectiveRate    decimal.Decimal // PostedAmount / OriginalAmount
+	AverageRate      decimal.Decimal // Weighted average rate for the currency over the report
+	FXGainLoss       decimal.Decimal // Positive when the conversion beat the average rate
+	IsIncome         bool
+}
+
 // VendorLookupRow represents a single row in the Vendor Lookup tab.
 type VendorLookupRow struct {
 	VendorName string
@@ -91,12 +109,14 @@ type TabData struct {
 	TotalIncome         decimal.Decimal
 	TotalExpenses       decimal.Decimal
 	TotalDeductible     decimal.Decimal
+	TotalFXGainLoss     decimal.Decimal
 	Expenses            []ExpenseRow
 	Income              []IncomeRow
 	VendorSummary       []VendorSummaryRow
 	CategorySummary     []CategorySummaryRow
 	BusinessExpenses    []BusinessExpenseRow
 	MonthlyFlow         []MonthlyFlowRow
+	ForeignCurrency     []ForeignCurrencyRow
 	VendorLookup        []VendorLookupRow
 	CategoryLookup      []CategoryLookupRow
 	BusinessRulesLookup []BusinessRuleLookupRow
@@ -6,6 +6,8 @@ import (
 	"log/slog"
 	"os"
 	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/Veraticus/the-spice-must-flow/internal/common"
@@ -14,10 +16,15 @@ import (
 	"github.com/shopspring/decimal"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/api/option"
 	"google.golang.org/api/sheets/v4"
 )
 
+// foreignCurrencyTab is the optional tab added only when a report includes
+// foreign-currency transactions.
+const foreignCurrencyTab = "Foreign Currency"
+
 // Writer implements the ReportWriter interface for Google Sheets.
 type Writer struct {
 	service *sheets.Service
@@ -68,12 +75,21 @@ func (w *Writer) Write(ctx context.Context, classifications []model.Classificati
 		return fmt.Errorf("failed to aggregate data: %w", err)
 	}
 
+	// Add the foreign currency tab only when there is something to report
+	hasForeignTab, err := w.prepareForeignCurrencyTab(ctx, spreadsheetID, len(tabData.ForeignCurrency) > 0)
+	if err != nil {
+		return fmt.Errorf("failed to prepare foreign currency tab: %w", err)
+	}
+
 	// Clear all tabs
 	if clearErr := w.clearAllTabs(ctx, spreadsheetID); clearErr != nil {
 		return fmt.Errorf("failed to clear tabs: %w", clearErr)
 	}
+	if hasForeignTab {
+		w.clearTab(ctx, spreadsheetID, foreignCurrencyTab)
+	}
 
-	// Write data to each tab with retry
+	// Write data to each tab (each tab write is retried individually)
 	retryOpts := service.RetryOptions{
 		MaxAttempts:  w.config.RetryAttempts,
 		InitialDelay: w.config.RetryDelay,
@@ -81,19 +97,13 @@ func (w *Writer) Write(ctx context.Context, classifications []model.Classificati
 		Multiplier:   2.0,
 	}
 
-	err = common.WithRetry(ctx, func() error {
-		return w.writeAllTabs(ctx, spreadsheetID, tabData)
-	}, retryOpts)
-
-	if err != nil {
+	if err := w.writeAllTabs(ctx, spreadsheetID, tabData, retryOpts); err != nil {
 		return fmt.Errorf("failed to write data: %w", err)
 	}
 
-	// Apply formatting if enabled
+	// Apply formatting if enabled (each formatting batch is retried individually)
 	if w.config.EnableFormatting {
-		err = common.WithRetry(ctx, func() error {
-			return w.applyFormattingToAllTabs(ctx, spreadsheetID)
-		}, retryOpts)
+		err = w.applyFormattingToAllTabs(ctx, spreadsheetID, retryOpts)
 		if err != nil {
 			w.logger.Warn("failed to apply formatting", "error", err)
 			// Don't fail the whole operation if formatting fails
@@ -245,17 +255,76 @@ func (w *Writer) clearAllTabs(ctx context.Context, spreadsheetID string) error {
 	tabs := []string{"Expenses", "Income", "Vendor Summary", "Category Summary", "Business Expenses", "Monthly Flow", "Vendor Lookup", "Category Lookup", "Business Rules"}
 
 	for _, tab := range tabs {
-		rangeStr := fmt.Sprintf("%s!A:Z", tab)
-		_, err := w.service.Spreadsheets.Values.Clear(spreadsheetID, rangeStr, &sheets.ClearValuesRequest{}).Context(ctx).Do()
-		if err != nil {
-			w.logger.Warn("failed to clear tab", "tab", tab, "error", err)
-			// Continue with other tabs even if one fails
+		// Incremental tabs are diffed against their current contents instead
+		if w.config.Incremental && incrementalTabs[tab] {
+			continue
 		}
+		// Continue with other tabs even if one fails
+		w.clearTab(ctx, spreadsheetID, tab)
 	}
 
 	return nil
 }
 
+// clearTab clears data from a single tab, logging rather than failing on error.
+func (w *Writer) clearTab(ctx context.Context, spreadsheetID, tab string) {
+	rangeStr := fmt.Sprintf("%s!A:Z", tab)
+	_, err := w.service.Spreadsheets.Values.Clear(spreadsheetID, rangeStr, &sheets.ClearValuesRequest{}).Context(ctx).Do()
+	if err != nil {
+		w.logger.Warn("failed to clear tab", "tab", tab, "error", err)
+	}
+}
+
+// prepareForeignCurrencyTab reports whether the optional foreign currency tab
+// exists, creating it first when needed. Spreadsheets without FX activity are
+// left untouched.
+func (w *Writer) prepareForeignCurrencyTab(ctx context.Context, spreadsheetID string, needed bool) (bool, error) {
+	spreadsheet, err := w.service.Spreadsheets.Get(spreadsheetID).Context(ctx).Do()
+	if err != nil {
+		return false, fmt.Errorf("unable to get spreadsheet: %w", err)
+	}
+
+	for _, sheet := range spreadsheet.Sheets {
+		if sheet.Properties.Title == foreignCurrencyTab {
+			return true, nil
+		}
+	}
+
+	if !needed {
+		return false, nil
+	}
+
+	batchUpdate := &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{
+			{
+				AddSheet: &sheets.AddSheetRequest{
+					Properties: &sheets.SheetProperties{
+						Title: foreignCurrencyTab,
+						Index: int64(len(spreadsheet.Sheets)),
+					},
+				},
+			},
+		},
+	}
+	if _, err := w.service.Spreadsheets.BatchUpdate(spreadsheetID, batchUpdate).Context(ctx).Do(); err != nil {
+		return false, fmt.Errorf("failed to add foreign currency tab: %w", err)
+	}
+
+	return true, nil
+}
+
+// Aggregate processes classifications into the data of every report tab,
+// exactly as Writer.Write does before writing it to Google Sheets. Only the
+// report settings of config are used, so other report writers share the
+// numbers without needing Sheets credentials.
+func Aggregate(classifications []model.Classification, summary *service.ReportSummary, categories []model.Category, config Config) (*TabData, error) {
+	if err := config.ValidateReport(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	w := &Writer{config: config, logger: slog.Default()}
+	return w.aggregateData(classifications, summary, categories)
+}
+
 // aggregateData processes classifications into the TabData structure.
 func (w *Writer) aggregateData(classifications []model.Classification, summary *service.ReportSummary, categories []model.Category) (*TabData, error) {
 
@@ -275,166 +344,47 @@ func (w *Writer) aggregateData(classifications []model.Classification, summary *
 		BusinessRulesLookup: make([]BusinessRuleLookupRow, 0),
 	}
 
-	// Build category maps from categories array
+	// Build category type map from categories array
 	categoryTypes := make(map[string]model.CategoryType)
-	categoryInfoMap := make(map[string]*model.Category)
 	for i := range categories {
 		categoryTypes[categories[i].Name] = categories[i].Type
-		categoryInfoMap[categories[i].Name] = &categories[i]
-	}
-
-	// Maps for aggregation
-	vendorSummaryMap := make(map[string]*VendorSummaryRow)
-	categorySummaryMap := make(map[string]*CategorySummaryRow)
-	monthlyMap := make(map[string]*MonthlyFlowRow)
-	// Maps for lookup tables
-	vendorLookupMap := make(map[string]string)   // vendor -> category
-	categoryLookupMap := make(map[string]string) // category -> type
-
-	// Process each classification
-	for _, class := range classifications {
-		amount := decimal.NewFromFloat(class.Transaction.Amount)
-
-		// Determine if income or expense based on category type
-		isIncome := categoryTypes[class.Category] == model.CategoryTypeIncome
-
-		if isIncome {
-			// Add to income tab
-			data.Income = append(data.Income, IncomeRow{
-				Date:     class.Transaction.Date,
-				Amount:   amount,
-				Source:   class.Transaction.MerchantName,
-				Category: class.Category,
-				Notes:    class.Notes,
-			})
-			data.TotalIncome = data.TotalIncome.Add(amount)
-		} else {
-			// Add to expenses tab
-			businessPct := int(class.BusinessPercent)
-			data.Expenses = append(data.Expenses, ExpenseRow{
-				Date:        class.Transaction.Date,
-				Amount:      amount,
-				Vendor:      class.Transaction.MerchantName,
-				Category:    class.Category,
-				BusinessPct: businessPct,
-				Notes:       class.Notes,
-			})
-			data.TotalExpenses = data.TotalExpenses.Add(amount)
-
-			// Add to business expenses if applicable
-			if businessPct > 0 {
-				deductible := amount.Mul(decimal.NewFromFloat(float64(businessPct) / 100))
-				data.BusinessExpenses = append(data.BusinessExpenses, BusinessExpenseRow{
-					Date:             class.Transaction.Date,
-					Vendor:           class.Transaction.MerchantName,
-					Category:         class.Category,
-					OriginalAmount:   amount,
-					BusinessPct:      businessPct,
-					DeductibleAmount: deductible,
-					Notes:            class.Notes,
-				})
-				data.TotalDeductible = data.TotalDeductible.Add(deductible)
-			}
-		}
-
-		// Update vendor summary
-		vendorKey := class.Transaction.MerchantName
-		if vendor, exists := vendorSummaryMap[vendorKey]; exists {
-			vendor.TotalAmount = vendor.TotalAmount.Add(amount)
-			vendor.TransactionCount++
-		} else {
-			vendorSummaryMap[vendorKey] = &VendorSummaryRow{
-				VendorName:         vendorKey,
-				AssociatedCategory: class.Category,
-				TotalAmount:        amount,
-				TransactionCount:   1,
-			}
-		}
-		// Track vendor -> category mapping for lookup table
-		vendorLookupMap[vendorKey] = class.Category
-
-		// Update category summary
-		categoryKey := class.Category
-		categoryType := "Expense"
-		if isIncome {
-			categoryType = "Income"
-		}
-
-		if cat, exists := categorySummaryMap[categoryKey]; exists {
-			cat.TotalAmount = cat.TotalAmount.Add(amount)
-			cat.TransactionCount++
-			// Update monthly amount
-			monthIndex := class.Transaction.Date.Month() - 1
-			cat.MonthlyAmounts[monthIndex] = cat.MonthlyAmounts[monthIndex].Add(amount)
-		} else {
-			monthlyAmounts := [12]decimal.Decimal{}
-			monthIndex := class.Transaction.Date.Month() - 1
-			monthlyAmounts[monthIndex] = amount
-
-			categorySummaryMap[categoryKey] = &CategorySummaryRow{
-				CategoryName:     categoryKey,
-				Type:             categoryType,
-				TotalAmount:      amount,
-				TransactionCount: 1,
-				MonthlyAmounts:   monthlyAmounts,
-			}
-		}
-		// Track category -> type mapping for lookup table
-		categoryLookupMap[categoryKey] = categoryType
-
-		// Update monthly flow
-		monthKey := class.Transaction.Date.Format("January 2006")
-		if month, exists := monthlyMap[monthKey]; exists {
-			if isIncome {
-				month.TotalIncome = month.TotalIncome.Add(amount)
-			} else {
-				month.TotalExpenses = month.TotalExpenses.Add(amount)
-			}
-		} else {
-			row := &MonthlyFlowRow{
-				Month: monthKey,
-			}
-			if isIncome {
-				row.TotalIncome = amount
-			} else {
-				row.TotalExpenses = amount
-			}
-			monthlyMap[monthKey] = row
-		}
 	}
 
+	agg := w.aggregateClassifications(classifications, categoryTypes)
+	data.Expenses = append(data.Expenses, agg.expenses...)
+	data.Income = append(data.Income, agg.income...)
+	data.BusinessExpenses = append(data.BusinessExpenses, agg.businessExpenses...)
+	data.ForeignCurrency = agg.foreignCurrency
+	data.TotalIncome = agg.totalIncome
+	data.TotalExpenses = agg.totalExpenses
+	data.TotalDeductible = agg.totalDeductible
+	monthlyMap := agg.months
+	vendorLookupMap := agg.vendorLookup
+	categoryLookupMap := agg.categoryLookup
+
 	// Convert maps to slices
-	for _, vendor := range vendorSummaryMap {
+	for name, vendor := range agg.vendors {
+		if vendor.TransactionCount > 0 {
+			vendor.AverageConfidence = agg.vendorConfidence[name] / float64(vendor.TransactionCount)
+		}
+		vendor.Consistency = vendorConsistency(agg.vendorCategories[name], vendor.TransactionCount)
 		data.VendorSummary = append(data.VendorSummary, *vendor)
 	}
 
-	for _, category := range categorySummaryMap {
-		// Calculate average business percentage for expense categories
+	for _, category := range agg.categories {
+		// Average business percentage for expense categories
 		if category.Type == "Expense" && category.TransactionCount > 0 {
-			totalBusinessPct := 0
-			expenseCount := 0
-			for _, expense := range data.Expenses {
-				if expense.Category == category.CategoryName {
-					totalBusinessPct += expense.BusinessPct
-					expenseCount++
-				}
-			}
-			if expenseCount > 0 {
-				category.BusinessPct = totalBusinessPct / expenseCount
-			}
+			category.BusinessPct = agg.categoryBusinessPct[category.CategoryName] / category.TransactionCount
 		}
 		data.CategorySummary = append(data.CategorySummary, *category)
 	}
+	sort.Slice(data.CategorySummary, func(i, j int) bool {
+		return data.CategorySummary[i].CategoryName < data.CategorySummary[j].CategoryName
+	})
 
 	// Create monthly flow with running balance
-	months := make([]string, 0, len(monthlyMap))
-	for month := range monthlyMap {
-		months = append(months, month)
-	}
-	sort.Strings(months)
-
 	runningBalance := decimal.Zero
-	for _, month := range months {
+	for _, month := range sortedMonthKeys(monthlyMap) {
 		flow := monthlyMap[month]
 		flow.NetFlow = flow.TotalIncome.Sub(flow.TotalExpenses)
 		runningBalance = runningBalance.Add(flow.NetFlow)
@@ -442,9 +392,12 @@ func (w *Writer) aggregateData(classifications []model.Classification, summary *
 		data.MonthlyFlow = append(data.MonthlyFlow, *flow)
 	}
 
-	// Sort vendor summary by total amount descending
+	// Sort vendor summary by total amount descending, then by name
 	sort.Slice(data.VendorSummary, func(i, j int) bool {
-		return data.VendorSummary[i].TotalAmount.GreaterThan(data.VendorSummary[j].TotalAmount)
+		if !data.VendorSummary[i].TotalAmount.Equal(data.VendorSummary[j].TotalAmount) {
+			return data.VendorSummary[i].TotalAmount.GreaterThan(data.VendorSummary[j].TotalAmount)
+		}
+		return data.VendorSummary[i].VendorName < data.VendorSummary[j].VendorName
 	})
 
 	// Sort expenses and income by date descending
@@ -456,6 +409,12 @@ func (w *Writer) aggregateData(classifications []model.Classification, summary *
 		return data.Income[i].Date.After(data.Income[j].Date)
 	})
 
+	// Compute FX gain/loss and sort foreign transactions by date descending
+	data.TotalFXGainLoss = calculateFXGainLoss(data.ForeignCurrency)
+	sort.Slice(data.ForeignCurrency, func(i, j int) bool {
+		return data.ForeignCurrency[i].Date.After(data.ForeignCurrency[j].Date)
+	})
+
 	// Sort business expenses by category, then date
 	sort.Slice(data.BusinessExpenses, func(i, j int) bool {
 		if data.BusinessExpenses[i].Category != data.BusinessExpenses[j].Category {
@@ -535,57 +494,193 @@ func (w *Writer) aggregateData(classifications []model.Classification, summary *
 		if data.BusinessRulesLookup[i].VendorPattern != data.BusinessRulesLookup[j].VendorPattern {
 			return data.BusinessRulesLookup[i].VendorPattern < data.BusinessRulesLookup[j].VendorPattern
 		}
-		return data.BusinessRulesLookup[i].Category < data.BusinessRulesLookup[j].Category
+		if data.BusinessRulesLookup[i].Category != data.BusinessRulesLookup[j].Category {
+			return data.BusinessRulesLookup[i].Category < data.BusinessRulesLookup[j].Category
+		}
+		return data.BusinessRulesLookup[i].BusinessPct < data.BusinessRulesLookup[j].BusinessPct
 	})
 
+	data.TotalDeductible = w.roundDeductibleTotal(data.TotalDeductible)
+	w.applySignConvention(data)
+
 	return data, nil
 }
 
-// writeAllTabs writes data to all tabs in the spreadsheet.
-func (w *Writer) writeAllTabs(ctx context.Context, spreadsheetID string, data *TabData) error {
-	// Write lookup tables first (they need to exist for formulas to work)
-	if err := w.writeVendorLookupTab(ctx, spreadsheetID, data.VendorLookup); err != nil {
-		return fmt.Errorf("failed to write vendor lookup tab: %w", err)
+// applySignConvention negates the expense or income amounts on the Expenses,
+// Income and Monthly Flow rows as the configured sign convention requires.
+// Net flow and running balance already account for direction and keep their
+// sign, as do the report totals and every other tab.
+func (w *Writer) applySignConvention(data *TabData) {
+	switch w.config.SignConvention {
+	case SignConventionExpensesNegative:
+		for i := range data.Expenses {
+			data.Expenses[i].Amount = data.Expenses[i].Amount.Neg()
+		}
+		for i := range data.MonthlyFlow {
+			data.MonthlyFlow[i].TotalExpenses = data.MonthlyFlow[i].TotalExpenses.Neg()
+		}
+	case SignConventionIncomeNegative:
+		for i := range data.Income {
+			data.Income[i].Amount = data.Income[i].Amount.Neg()
+		}
+		for i := range data.MonthlyFlow {
+			data.MonthlyFlow[i].TotalIncome = data.MonthlyFlow[i].TotalIncome.Neg()
+		}
 	}
+}
 
-	if err := w.writeCategoryLookupTab(ctx, spreadsheetID, data.CategoryLookup); err != nil {
-		return fmt.Errorf("failed to write category lookup tab: %w", err)
-	}
+// roundDeductibleTotal rounds a deductible total as the config's rounding
+// policy requires.
+func (w *Writer) roundDeductibleTotal(total decimal.Decimal) decimal.Decimal {
+	return w.config.RoundDeductibleTotal(total)
+}
 
-	if err := w.writeBusinessRulesTab(ctx, spreadsheetID, data.BusinessRulesLookup); err != nil {
-		return fmt.Errorf("failed to write business rules tab: %w", err)
+// refundNotes marks a note as belonging to a refund.
+func refundNotes(notes string) string {
+	if strings.HasPrefix(notes, "Refund") {
+		return notes
 	}
-
-	// Write transaction tabs
-	if err := w.writeExpensesTab(ctx, spreadsheetID, data.Expenses); err != nil {
-		return fmt.Errorf("failed to write expenses tab: %w", err)
+	if notes == "" {
+		return "Refund"
 	}
+	return "Refund: " + notes
+}
 
-	if err := w.writeIncomeTab(ctx, spreadsheetID, data.Income); err != nil {
-		return fmt.Errorf("failed to write income tab: %w", err)
+// calculateFXGainLoss fills in rates and FX gain/loss for each foreign-currency row
+// and returns the total. Each currency's reference rate is the weighted average
+// rate across the report; a row gains when its conversion beat that average
+// (paid less for an expense, received more for income).
+func calculateFXGainLoss(rows []ForeignCurrencyRow) decimal.Decimal {
+	type currencyTotals struct {
+		original decimal.Decimal
+		posted   decimal.Decimal
+	}
+
+	totals := make(map[string]*currencyTotals)
+	for _, row := range rows {
+		t, ok := totals[row.OriginalCurrency]
+		if !ok {
+			t = &currencyTotals{}
+			totals[row.OriginalCurrency] = t
+		}
+		t.original = t.original.Add(row.OriginalAmount)
+		t.posted = t.posted.Add(row.PostedAmount)
 	}
 
-	if err := w.writeVendorSummaryTab(ctx, spreadsheetID, data.VendorSummary); err != nil {
-		return fmt.Errorf("failed to write vendor summary tab: %w", err)
-	}
+	total := decimal.Zero
+	for i := range rows {
+		row := &rows[i]
+		if row.OriginalAmount.IsZero() {
+			continue
+		}
 
-	if err := w.writeCategorySummaryTab(ctx, spreadsheetID, data.CategorySummary); err != nil {
-		return fmt.Errorf("failed to write category summary tab: %w", err)
+		t := totals[row.OriginalCurrency]
+		row.EffectiveRate = row.PostedAmount.Div(row.OriginalAmount)
+		row.AverageRate = t.posted.Div(t.original)
+
+		expected := row.OriginalAmount.Mul(row.AverageRate)
+		if row.IsIncome {
+			row.FXGainLoss = row.PostedAmount.Sub(expected).Round(2)
+		} else {
+			row.FXGainLoss = expected.Sub(row.PostedAmount).Round(2)
+		}
+		total = total.Add(row.FXGainLoss)
 	}
 
-	if err := w.writeBusinessExpensesTab(ctx, spreadsheetID, data.BusinessExpenses); err != nil {
-		return fmt.Errorf("failed to write business expenses tab: %w", err)
+	return total
+}
+
+// tabWrite writes the values of one tab.
+type tabWrite struct {
+	write func(ctx context.Context) error
+	name  string
+}
+
+// writeAllTabs writes data to all tabs in the spreadsheet. The lookup tabs are
+// written first, one after another, since the other tabs' formulas reference
+// them. The remaining tabs are then written with up to WriteConcurrency calls
+// in flight. Each tab is retried independently, and the first tab that still
+// fails cancels the writes that haven't finished.
+func (w *Writer) writeAllTabs(ctx context.Context, spreadsheetID string, data *TabData, retryOpts service.RetryOptions) error {
+	lookupTabs := []tabWrite{
+		{name: "vendor lookup", write: func(ctx context.Context) error {
+			return w.writeVendorLookupTab(ctx, spreadsheetID, data.VendorLookup)
+		}},
+		{name: "category lookup", write: func(ctx context.Context) error {
+			return w.writeCategoryLookupTab(ctx, spreadsheetID, data.CategoryLookup)
+		}},
+		{name: "business rules", write: func(ctx context.Context) error {
+			return w.writeBusinessRulesTab(ctx, spreadsheetID, data.BusinessRulesLookup)
+		}},
+	}
+	for _, tab := range lookupTabs {
+		if err := w.writeTab(ctx, tab, retryOpts); err != nil {
+			return err
+		}
 	}
 
-	if err := w.writeMonthlyFlowTab(ctx, spreadsheetID, data.MonthlyFlow); err != nil {
-		return fmt.Errorf("failed to write monthly flow tab: %w", err)
+	dataTabs := []tabWrite{
+		{name: "expenses", write: func(ctx context.Context) error {
+			if w.config.Incremental {
+				return w.writeKeyedTab(ctx, spreadsheetID, expensesKeyedTab(data.Expenses))
+			}
+			return w.writeExpensesTab(ctx, spreadsheetID, data.Expenses)
+		}},
+		{name: "income", write: func(ctx context.Context) error {
+			if w.config.Incremental {
+				return w.writeKeyedTab(ctx, spreadsheetID, incomeKeyedTab(data.Income))
+			}
+			return w.writeIncomeTab(ctx, spreadsheetID, data.Income)
+		}},
+		{name: "vendor summary", write: func(ctx context.Context) error {
+			return w.writeVendorSummaryTab(ctx, spreadsheetID, data.VendorSummary)
+		}},
+		{name: "category summary", write: func(ctx context.Context) error {
+			return w.writeCategorySummaryTab(ctx, spreadsheetID, data.CategorySummary)
+		}},
+		{name: "business expenses", write: func(ctx context.Context) error {
+			return w.writeBusinessExpensesTab(ctx, spreadsheetID, data.BusinessExpenses)
+		}},
+		{name: "monthly flow", write: func(ctx context.Context) error {
+			return w.writeMonthlyFlowTab(ctx, spreadsheetID, data.MonthlyFlow)
+		}},
+	}
+	// The foreign currency tab is only written when there is FX activity
+	if len(data.ForeignCurrency) > 0 {
+		dataTabs = append(dataTabs, tabWrite{name: "foreign currency", write: func(ctx context.Context) error {
+			return w.writeForeignCurrencyTab(ctx, spreadsheetID, data.ForeignCurrency, data.TotalFXGainLoss)
+		}})
+	}
+
+	concurrency := w.config.WriteConcurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	w.logger.Debug("writing tabs", "tabs", len(lookupTabs)+len(dataTabs), "concurrency", concurrency)
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(concurrency)
+	for _, tab := range dataTabs {
+		group.Go(func() error {
+			return w.writeTab(groupCtx, tab, retryOpts)
+		})
 	}
+	return group.Wait()
+}
 
+// writeTab writes a tab, retrying failed writes.
+func (w *Writer) writeTab(ctx context.Context, tab tabWrite, retryOpts service.RetryOptions) error {
+	err := common.WithRetry(ctx, func() error {
+		return tab.write(ctx)
+	}, retryOpts)
+	if err != nil {
+		return fmt.Errorf("failed to write %s tab: %w", tab.name, err)
+	}
 	return nil
 }
 
 // applyFormattingToAllTabs applies formatting to all tabs.
-func (w *Writer) applyFormattingToAllTabs(ctx context.Context, spreadsheetID string) error {
+func (w *Writer) applyFormattingToAllTabs(ctx context.Context, spreadsheetID string, retryOpts service.RetryOptions) error {
 	// Get spreadsheet to get sheet IDs
 	spreadsheet, err := w.service.Spreadsheets.Get(spreadsheetID).Context(ctx).Do()
 	if err != nil {
@@ -604,11 +699,17 @@ func (w *Writer) applyFormattingToAllTabs(ctx context.Context, spreadsheetID str
 	// Format Expenses tab
 	if sheetID, ok := sheetIDs["Expenses"]; ok {
 		requests = append(requests, w.formatExpensesTab(sheetID)...)
+		if w.config.Incremental {
+			requests = append(requests, hideColumn(sheetID, len(expensesHeader)))
+		}
 	}
 
 	// Format Income tab
 	if sheetID, ok := sheetIDs["Income"]; ok {
 		requests = append(requests, w.formatIncomeTab(sheetID)...)
+		if w.config.Incremental {
+			requests = append(requests, hideColumn(sheetID, len(incomeHeader)))
+		}
 	}
 
 	// Format Vendor Summary tab
@@ -631,6 +732,11 @@ func (w *Writer) applyFormattingToAllTabs(ctx context.Context, spreadsheetID str
 		requests = append(requests, w.formatMonthlyFlowTab(sheetID)...)
 	}
 
+	// Format Foreign Currency tab (only present when FX activity was reported)
+	if sheetID, ok := sheetIDs[foreignCurrencyTab]; ok {
+		requests = append(requests, w.formatForeignCurrencyTab(sheetID)...)
+	}
+
 	// Format Vendor Lookup tab
 	if sheetID, ok := sheetIDs["Vendor Lookup"]; ok {
 		requests = append(requests, w.formatVendorLookupTab(sheetID)...)
@@ -646,53 +752,92 @@ func (w *Writer) applyFormattingToAllTabs(ctx context.Context, spreadsheetID str
 		requests = append(requests, w.formatBusinessRulesTab(sheetID)...)
 	}
 
-	// Apply all formatting in a single batch
-	if len(requests) > 0 {
-		batchUpdateRequest := &sheets.BatchUpdateSpreadsheetRequest{
-			Requests: requests,
-		}
+	return w.applyFormattingRequests(ctx, spreadsheetID, requests, retryOpts)
+}
+
+// applyFormattingRequests sends formatting requests in chunks that stay under the
+// API's per-request limits. Chunks are applied with up to FormattingConcurrency
+// calls in flight, and each chunk is retried independently.
+func (w *Writer) applyFormattingRequests(ctx context.Context, spreadsheetID string, requests []*sheets.Request, retryOpts service.RetryOptions) error {
+	chunks := chunkRequests(requests, w.config.FormattingBatchSize)
+	if len(chunks) == 0 {
+		return nil
+	}
+
+	concurrency := w.config.FormattingConcurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	w.logger.Debug("applying formatting",
+		"requests", len(requests),
+		"batches", len(chunks),
+		"concurrency", concurrency)
+
+	sem := make(chan struct{}, concurrency)
+	errs := make([]error, len(chunks))
+	var wg sync.WaitGroup
+
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(idx int, chunk []*sheets.Request) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[idx] = ctx.Err()
+				return
+			}
+
+			errs[idx] = common.WithRetry(ctx, func() error {
+				batchUpdateRequest := &sheets.BatchUpdateSpreadsheetRequest{
+					Requests: chunk,
+				}
+				_, err := w.service.Spreadsheets.BatchUpdate(spreadsheetID, batchUpdateRequest).Context(ctx).Do()
+				return err
+			}, retryOpts)
+		}(i, chunk)
+	}
+
+	wg.Wait()
 
-		_, err = w.service.Spreadsheets.BatchUpdate(spreadsheetID, batchUpdateRequest).Context(ctx).Do()
+	for i, err := range errs {
 		if err != nil {
-			return fmt.Errorf("failed to apply formatting: %w", err)
+			return fmt.Errorf("failed to apply formatting batch %d of %d: %w", i+1, len(chunks), err)
 		}
 	}
 
 	return nil
 }
 
+// chunkRequests splits requests into consecutive chunks of at most size requests.
+func chunkRequests(requests []*sheets.Request, size int) [][]*sheets.Request {
+	if size <= 0 {
+		size = DefaultFormattingBatchSize
+	}
+
+	chunks := make([][]*sheets.Request, 0, (len(requests)+size-1)/size)
+	for start := 0; start < len(requests); start += size {
+		end := start + size
+		if end > len(requests) {
+			end = len(requests)
+		}
+		chunks = append(chunks, requests[start:end])
+	}
+
+	return chunks
+}
+
 // writeExpensesTab writes expense data to the Expenses tab with formulas.
 func (w *Writer) writeExpensesTab(ctx context.Context, spreadsheetID string, expenses []ExpenseRow) error {
 	// Prepare values
-	values := [][]any{
-		// Header row
-		{"Date", "Amount", "Vendor", "Category", "Business %", "Notes"},
-	}
+	values := [][]any{expensesHeader}
 
 	// Add expense rows with formulas
 	for i, expense := range expenses {
-		row := i + 2 // Account for header row, 1-based indexing
-
-		// Category formula using VLOOKUP to find category from vendor
-		categoryFormula := fmt.Sprintf(`=IFERROR(VLOOKUP(C%d,'Vendor Lookup'!A:B,2,FALSE),"%s")`, row, expense.Category)
-
-		// Business percentage formula with smart override support
-		// First tries to find a specific rule in Business Rules table
-		// If not found, uses VLOOKUP to get the category default from Category Lookup
-		// We use the static category value from the expense data, not the formula result
-		businessPctFormula := fmt.Sprintf(
-			`=IFERROR(INDEX('Business Rules'!C:C,MATCH(1,(C%d='Business Rules'!A:A)*(D%d='Business Rules'!B:B),0)),IFERROR(VLOOKUP("%s",'Category Lookup'!A:D,4,FALSE)/100,%g))`,
-			row, row, expense.Category, float64(expense.BusinessPct)/100,
-		)
-
-		values = append(values, []any{
-			expense.Date.Format("2006-01-02"),
-			expense.Amount.InexactFloat64(),
-			expense.Vendor,
-			categoryFormula,    // Use formula instead of static value
-			businessPctFormula, // Use formula to lookup business %
-			expense.Notes,
-		})
+		values = append(values, expenseValues(i+2, expense)) // Account for header row, 1-based indexing
 	}
 
 	// Write to sheet
@@ -709,28 +854,41 @@ func (w *Writer) writeExpensesTab(ctx context.Context, spreadsheetID string, exp
 	return err
 }
 
+// expensesHeader is the header row of the Expenses tab.
+var expensesHeader = []any{"Date", "Amount", "Vendor", "Category", "Business %", "Notes"}
+
+// expenseValues returns the cells of an expense written to the given sheet row.
+func expenseValues(row int, expense ExpenseRow) []any {
+	// Category formula using VLOOKUP to find category from vendor
+	categoryFormula := fmt.Sprintf(`=IFERROR(VLOOKUP(C%d,'Vendor Lookup'!A:B,2,FALSE),"%s")`, row, expense.Category)
+
+	// Business percentage formula with smart override support
+	// First tries to find a specific rule in Business Rules table
+	// If not found, uses VLOOKUP to get the category default from Category Lookup
+	// We use the static category value from the expense data, not the formula result
+	businessPctFormula := fmt.Sprintf(
+		`=IFERROR(INDEX('Business Rules'!C:C,MATCH(1,(C%d='Business Rules'!A:A)*(D%d='Business Rules'!B:B),0)),IFERROR(VLOOKUP("%s",'Category Lookup'!A:D,4,FALSE)/100,%g))`,
+		row, row, expense.Category, float64(expense.BusinessPct)/100,
+	)
+
+	return []any{
+		expense.Date.Format("2006-01-02"),
+		expense.Amount.InexactFloat64(),
+		expense.Vendor,
+		categoryFormula,    // Use formula instead of static value
+		businessPctFormula, // Use formula to lookup business %
+		expense.Notes,
+	}
+}
+
 // writeIncomeTab writes income data to the Income tab with formulas.
 func (w *Writer) writeIncomeTab(ctx context.Context, spreadsheetID string, income []IncomeRow) error {
 	// Prepare values
-	values := [][]any{
-		// Header row
-		{"Date", "Amount", "Source", "Category", "Notes"},
-	}
+	values := [][]any{incomeHeader}
 
 	// Add income rows with formulas
 	for i, inc := range income {
-		row := i + 2 // Account for header row, 1-based indexing
-
-		// Category formula using VLOOKUP to find category from source/vendor
-		categoryFormula := fmt.Sprintf(`=IFERROR(VLOOKUP(C%d,'Vendor Lookup'!A:B,2,FALSE),"%s")`, row, inc.Category)
-
-		values = append(values, []any{
-			inc.Date.Format("2006-01-02"),
-			inc.Amount.InexactFloat64(),
-			inc.Source,
-			categoryFormula, // Use formula instead of static value
-			inc.Notes,
-		})
+		values = append(values, incomeValues(i+2, inc)) // Account for header row, 1-based indexing
 	}
 
 	// Write to sheet
@@ -747,13 +905,31 @@ func (w *Writer) writeIncomeTab(ctx context.Context, spreadsheetID string, incom
 	return err
 }
 
+// incomeHeader is the header row of the Income tab.
+var incomeHeader = []any{"Date", "Amount", "Source", "Category", "Notes"}
+
+// incomeValues returns the cells of an income row written to the given sheet row.
+func incomeValues(row int, inc IncomeRow) []any {
+	// Category formula using VLOOKUP to find category from source/vendor
+	categoryFormula := fmt.Sprintf(`=IFERROR(VLOOKUP(C%d,'Vendor Lookup'!A:B,2,FALSE),"%s")`, row, inc.Category)
+
+	return []any{
+		inc.Date.Format("2006-01-02"),
+		inc.Amount.InexactFloat64(),
+		inc.Source,
+		categoryFormula, // Use formula instead of static value
+		inc.Notes,
+	}
+}
+
 // writeVendorSummaryTab writes vendor summary data with formulas.
 func (w *Writer) writeVendorSummaryTab(ctx context.Context, spreadsheetID string, vendors []VendorSummaryRow) error {
 	// Prepare values
-	values := [][]any{
-		// Header row
-		{"Vendor Name", "Category", "Total Amount", "Transaction Count"},
+	header := []any{"Vendor Name", "Category", "Total Amount", "Transaction Count"}
+	if w.config.VendorConfidence {
+		header = append(header, "Avg Confidence", "Consistency")
 	}
+	values := [][]any{header}
 
 	// Add vendor rows with formulas
 	for i, vendor := range vendors {
@@ -774,12 +950,8 @@ func (w *Writer) writeVendorSummaryTab(ctx context.Context, spreadsheetID string
 			row, row,
 		)
 
-		values = append(values, []any{
-			vendor.VendorName,
-			categoryFormula,
-			totalFormula,
-			countFormula,
-		})
+		values = append(values, vendorSummaryValues(vendor, w.config.VendorConfidence,
+			categoryFormula, totalFormula, countFormula))
 	}
 
 	// Write to sheet
@@ -796,6 +968,23 @@ func (w *Writer) writeVendorSummaryTab(ctx context.Context, spreadsheetID string
 	return err
 }
 
+// vendorSummaryValues builds a Vendor Summary row. Confidence and consistency
+// come from the classifications rather than formulas, since the sheets don't
+// carry confidences. Consistency says nothing about a single transaction, so
+// it is shown as n/a.
+func vendorSummaryValues(vendor VendorSummaryRow, withConfidence bool, categoryFormula, totalFormula, countFormula string) []any {
+	row := []any{vendor.VendorName, categoryFormula, totalFormula, countFormula}
+	if !withConfidence {
+		return row
+	}
+
+	var consistency any = "n/a"
+	if vendor.TransactionCount > 1 {
+		consistency = vendor.Consistency
+	}
+	return append(row, vendor.AverageConfidence, consistency)
+}
+
 // writeCategorySummaryTab writes category summary data with formulas.
 func (w *Writer) writeCategorySummaryTab(ctx context.Context, spreadsheetID string, categories []CategorySummaryRow) error {
 	// Prepare header
@@ -938,7 +1127,7 @@ func (w *Writer) writeBusinessExpensesTab(ctx context.Context, spreadsheetID str
 			// Add subtotal for previous category if not the first
 			if currentCategory != "" && !categoryTotal.IsZero() {
 				values = append(values, []any{
-					"", "", fmt.Sprintf("Subtotal - %s", currentCategory), "", "", categoryTotal.InexactFloat64(), "",
+					"", "", fmt.Sprintf("Subtotal - %s", currentCategory), "", "", w.roundDeductibleTotal(categoryTotal).InexactFloat64(), "",
 				})
 			}
 
@@ -968,7 +1157,7 @@ func (w *Writer) writeBusinessExpensesTab(ctx context.Context, spreadsheetID str
 		// Add final subtotal if this is the last expense
 		if i == len(expenses)-1 && !categoryTotal.IsZero() {
 			values = append(values, []any{
-				"", "", fmt.Sprintf("Subtotal - %s", currentCategory), "", "", categoryTotal.InexactFloat64(), "",
+				"", "", fmt.Sprintf("Subtotal - %s", currentCategory), "", "", w.roundDeductibleTotal(categoryTotal).InexactFloat64(), "",
 			})
 		}
 	}
@@ -978,7 +1167,7 @@ func (w *Writer) writeBusinessExpensesTab(ctx context.Context, spreadsheetID str
 		values = append(values,
 			[]any{}, // Empty row
 			[]any{
-				"", "", "GRAND TOTAL (Schedule C)", "", "", grandTotal.InexactFloat64(), "",
+				"", "", "GRAND TOTAL (Schedule C)", "", "", w.roundDeductibleTotal(grandTotal).InexactFloat64(), "",
 			})
 	}
 
@@ -1017,12 +1206,13 @@ func (w *Writer) writeMonthlyFlowTab(ctx context.Context, spreadsheetID string,
 
 	// Add yearly totals
 	if len(monthlyFlow) > 0 {
-		var totalIncome, totalExpenses decimal.Decimal
+		// Sum net flow rather than derive it, since the columns may be signed
+		var totalIncome, totalExpenses, netFlow decimal.Decimal
 		for _, month := range monthlyFlow {
 			totalIncome = totalIncome.Add(month.TotalIncome)
 			totalExpenses = totalExpenses.Add(month.TotalExpenses)
+			netFlow = netFlow.Add(month.NetFlow)
 		}
-		netFlow := totalIncome.Sub(totalExpenses)
 
 		values = append(values,
 			[]any{}, // Empty row
@@ -1059,6 +1249,47 @@ func (w *Writer) writeMonthlyFlowTab(ctx context.Context, spreadsheetID string,
 	return err
 }
 
+// writeForeignCurrencyTab writes foreign-currency transactions with their FX gain/loss.
+func (w *Writer) writeForeignCurrencyTab(ctx context.Context, spreadsheetID string, rows []ForeignCurrencyRow, totalGainLoss decimal.Decimal) error {
+	// Prepare values
+	values := [][]any{
+		// Header row
+		{"Date", "Vendor", "Category", "Currency", "Original Amount", "Posted Amount", "Effective Rate", "Average Rate", "FX Gain/Loss"},
+	}
+
+	for _, row := range rows {
+		values = append(values, []any{
+			row.Date.Format("2006-01-02"),
+			row.Vendor,
+			row.Category,
+			row.OriginalCurrency,
+			row.OriginalAmount.InexactFloat64(),
+			row.PostedAmount.InexactFloat64(),
+			row.EffectiveRate.Round(6).InexactFloat64(),
+			row.AverageRate.Round(6).InexactFloat64(),
+			row.FXGainLoss.InexactFloat64(),
+		})
+	}
+
+	// Add total row
+	values = append(values,
+		[]any{}, // Empty row
+		[]any{"TOTAL FX GAIN/LOSS", "", "", "", "", "", "", "", totalGainLoss.InexactFloat64()})
+
+	// Write to sheet
+	valueRange := &sheets.ValueRange{
+		Values: values,
+	}
+
+	rangeStr := foreignCurrencyTab + "!A1"
+	_, err := w.service.Spreadsheets.Values.Update(spreadsheetID, rangeStr, valueRange).
+		ValueInputOption("USER_ENTERED").
+		Context(ctx).
+		Do()
+
+	return err
+}
+
 // formatExpensesTab formats the Expenses tab.
 func (w *Writer) formatExpensesTab(sheetID int64) []*sheets.Request {
 	requests := []*sheets.Request{
@@ -1236,6 +1467,30 @@ func (w *Writer) formatVendorSummaryTab(sheetID int64) []*sheets.Request {
 		},
 	}
 
+	// Format confidence and consistency columns as percentages
+	if w.config.VendorConfidence {
+		requests = append(requests, &sheets.Request{
+			RepeatCell: &sheets.RepeatCellRequest{
+				Range: &sheets.GridRange{
+					SheetId:          sheetID,
+					StartRowIndex:    1,
+					EndRowIndex:      1000,
+					StartColumnIndex: 4,
+					EndColumnIndex:   6,
+				},
+				Cell: &sheets.CellData{
+					UserEnteredFormat: &sheets.CellFormat{
+						NumberFormat: &sheets.NumberFormat{
+							Type:    "PERCENT",
+							Pattern: "0%",
+						},
+					},
+				},
+				Fields: "userEnteredFormat.numberFormat",
+			},
+		})
+	}
+
 	return requests
 }
 
@@ -1615,6 +1870,122 @@ func (w *Writer) writeCategoryLookupTab(ctx context.Context, spreadsheetID strin
 	return err
 }
 
+// formatForeignCurrencyTab formats the Foreign Currency tab.
+func (w *Writer) formatForeignCurrencyTab(sheetID int64) []*sheets.Request {
+	requests := []*sheets.Request{
+		// Bold header row
+		{
+			RepeatCell: &sheets.RepeatCellRequest{
+				Range: &sheets.GridRange{
+					SheetId:       sheetID,
+					StartRowIndex: 0,
+					EndRowIndex:   1,
+				},
+				Cell: &sheets.CellData{
+					UserEnteredFormat: &sheets.CellFormat{
+						TextFormat: &sheets.TextFormat{
+							Bold: true,
+						},
+						BackgroundColor: &sheets.Color{
+							Red:   0.9,
+							Green: 0.9,
+							Blue:  0.9,
+							Alpha: 1.0,
+						},
+					},
+				},
+				Fields: "userEnteredFormat.textFormat,userEnteredFormat.backgroundColor",
+			},
+		},
+		// Original amounts are in their own currency, so no symbol
+		{
+			RepeatCell: &sheets.RepeatCellRequest{
+				Range: &sheets.GridRange{
+					SheetId:          sheetID,
+					StartRowIndex:    1,
+					EndRowIndex:      1000,
+					StartColumnIndex: 4,
+					EndColumnIndex:   5,
+				},
+				Cell: &sheets.CellData{
+					UserEnteredFormat: &sheets.CellFormat{
+						NumberFormat: &sheets.NumberFormat{
+							Type:    "NUMBER",
+							Pattern: "#,##0.00",
+						},
+					},
+				},
+				Fields: "userEnteredFormat.numberFormat",
+			},
+		},
+		// Format posted amount as currency
+		{
+			RepeatCell: &sheets.RepeatCellRequest{
+				Range: &sheets.GridRange{
+					SheetId:          sheetID,
+					StartRowIndex:    1,
+					EndRowIndex:      1000,
+					StartColumnIndex: 5,
+					EndColumnIndex:   6,
+				},
+				Cell: &sheets.CellData{
+					UserEnteredFormat: &sheets.CellFormat{
+						NumberFormat: &sheets.NumberFormat{
+							Type:    "CURRENCY",
+							Pattern: "$#,##0.00",
+						},
+					},
+				},
+				Fields: "userEnteredFormat.numberFormat",
+			},
+		},
+		// Format exchange rates
+		{
+			RepeatCell: &sheets.RepeatCellRequest{
+				Range: &sheets.GridRange{
+					SheetId:          sheetID,
+					StartRowIndex:    1,
+					EndRowIndex:      1000,
+					StartColumnIndex: 6,
+					EndColumnIndex:   8,
+				},
+				Cell: &sheets.CellData{
+					UserEnteredFormat: &sheets.CellFormat{
+						NumberFormat: &sheets.NumberFormat{
+							Type:    "NUMBER",
+							Pattern: "0.000000",
+						},
+					},
+				},
+				Fields: "userEnteredFormat.numberFormat",
+			},
+		},
+		// Format FX gain/loss as currency
+		{
+			RepeatCell: &sheets.RepeatCellRequest{
+				Range: &sheets.GridRange{
+					SheetId:          sheetID,
+					StartRowIndex:    1,
+					EndRowIndex:      1000,
+					StartColumnIndex: 8,
+					EndColumnIndex:   9,
+				},
+				Cell: &sheets.CellData{
+					UserEnteredFormat: &sheets.CellFormat{
+						NumberFormat: &sheets.NumberFormat{
+							Type:    "CURRENCY",
+							Pattern: "$#,##0.00",
+						},
+					},
+				},
+				Fields: "userEnteredFormat.numberFormat",
+			},
+		},
+	}
+
+	return requests
+}
+
 // formatVendorLookupTab formats the Vendor Lookup tab.
 func (w *Writer) formatVendorLookupTab(sheetID int64) []*sheets.Request {
 	requests := []*sheets.Request{
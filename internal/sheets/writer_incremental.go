@@ -0,0 +1,255 @@
+package sheets
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// keyHeader titles the hidden column holding each row's transaction hash in
+// incremental mode.
+const keyHeader = "Key"
+
+// incrementalTabs are the per-transaction tabs updated in place in incremental mode.
+// Every other tab is derived from them and is rewritten on each run.
+var incrementalTabs = map[string]bool{"Expenses": true, "Income": true}
+
+// keyedTab describes the rows a per-transaction tab should contain. Each row's
+// last cell is its key.
+type keyedTab struct {
+	build  func(row, i int) []any // Cells of desired row i written to the given sheet row
+	name   string
+	header []any
+	keys   []string
+}
+
+// rowUpdate writes the cells of one sheet row (1-based).
+type rowUpdate struct {
+	values []any
+	row    int
+}
+
+// incrementalPlan is the set of changes that brings a tab up to date.
+type incrementalPlan struct {
+	deletes []int       // Sheet rows (1-based) to delete, bottom first
+	updates []rowUpdate // Rows to write once the deletes are applied
+}
+
+// expensesKeyedTab describes the Expenses tab with a trailing key column.
+func expensesKeyedTab(expenses []ExpenseRow) keyedTab {
+	keys := make([]string, len(expenses))
+	for i, expense := range expenses {
+		keys[i] = expense.Key
+	}
+	return keyedTab{
+		name:   "Expenses",
+		header: append(append([]any{}, expensesHeader...), keyHeader),
+		keys:   keys,
+		build: func(row, i int) []any {
+			return append(expenseValues(row, expenses[i]), expenses[i].Key)
+		},
+	}
+}
+
+// incomeKeyedTab describes the Income tab with a trailing key column.
+func incomeKeyedTab(income []IncomeRow) keyedTab {
+	keys := make([]string, len(income))
+	for i, inc := range income {
+		keys[i] = inc.Key
+	}
+	return keyedTab{
+		name:   "Income",
+		header: append(append([]any{}, incomeHeader...), keyHeader),
+		keys:   keys,
+		build: func(row, i int) []any {
+			return append(incomeValues(row, income[i]), income[i].Key)
+		},
+	}
+}
+
+// writeKeyedTab updates a per-transaction tab in place. Rows whose transaction
+// is unchanged are left alone, changed rows are rewritten where they stand, new
+// transactions are appended, and rows for transactions no longer in the report
+// are deleted. Cells to the right of the key column are never written, so
+// manual annotations there stay with their transaction.
+func (w *Writer) writeKeyedTab(ctx context.Context, spreadsheetID string, tab keyedTab) error {
+	readRange := fmt.Sprintf("%s!A:%s", tab.name, columnLetter(len(tab.header)-1))
+	existing, err := w.service.Spreadsheets.Values.Get(spreadsheetID, readRange).
+		ValueRenderOption("FORMULA").
+		DateTimeRenderOption("FORMATTED_STRING").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return fmt.Errorf("failed to read %s tab: %w", tab.name, err)
+	}
+
+	plan := planKeyedTab(existing.Values, tab)
+
+	if len(plan.deletes) > 0 {
+		sheetID, err := w.sheetID(ctx, spreadsheetID, tab.name)
+		if err != nil {
+			return err
+		}
+		requests := make([]*sheets.Request, 0, len(plan.deletes))
+		for _, row := range plan.deletes {
+			requests = append(requests, &sheets.Request{
+				DeleteDimension: &sheets.DeleteDimensionRequest{
+					Range: &sheets.DimensionRange{
+						SheetId:    sheetID,
+						Dimension:  "ROWS",
+						StartIndex: int64(row - 1),
+						EndIndex:   int64(row),
+					},
+				},
+			})
+		}
+		batchUpdate := &sheets.BatchUpdateSpreadsheetRequest{Requests: requests}
+		if _, err := w.service.Spreadsheets.BatchUpdate(spreadsheetID, batchUpdate).Context(ctx).Do(); err != nil {
+			return fmt.Errorf("failed to delete rows from %s tab: %w", tab.name, err)
+		}
+	}
+
+	if len(plan.updates) > 0 {
+		data := make([]*sheets.ValueRange, 0, len(plan.updates))
+		for _, update := range plan.updates {
+			data = append(data, &sheets.ValueRange{
+				Range:  fmt.Sprintf("%s!A%d", tab.name, update.row),
+				Values: [][]any{update.values},
+			})
+		}
+		request := &sheets.BatchUpdateValuesRequest{ValueInputOption: "USER_ENTERED", Data: data}
+		if _, err := w.service.Spreadsheets.Values.BatchUpdate(spreadsheetID, request).Context(ctx).Do(); err != nil {
+			return fmt.Errorf("failed to update %s tab: %w", tab.name, err)
+		}
+	}
+
+	w.logger.Info("updated tab incrementally",
+		"tab", tab.name,
+		"rows", len(tab.keys),
+		"written", len(plan.updates),
+		"deleted", len(plan.deletes))
+
+	return nil
+}
+
+// planKeyedTab diffs a tab's current cells (header first) against the rows it
+// should contain. Existing rows keep their position; rows without a key, such
+// as ones the user added, are kept as they are.
+func planKeyedTab(existing [][]any, tab keyedTab) incrementalPlan {
+	var plan incrementalPlan
+	keyCol := len(tab.header) - 1
+
+	if len(existing) == 0 || !cellsEqual(existing[0], tab.header) {
+		plan.updates = append(plan.updates, rowUpdate{row: 1, values: tab.header})
+	}
+
+	wanted := make(map[string]bool, len(tab.keys))
+	for _, key := range tab.keys {
+		wanted[key] = true
+	}
+
+	rowOf := make(map[string]int) // Key -> sheet row before deletes
+	for i := 1; i < len(existing); i++ {
+		row := i + 1
+		key := ""
+		if keyCol < len(existing[i]) {
+			key = fmt.Sprint(existing[i][keyCol])
+		}
+		if key == "" {
+			continue
+		}
+		if _, seen := rowOf[key]; seen || !wanted[key] {
+			plan.deletes = append(plan.deletes, row)
+			continue
+		}
+		rowOf[key] = row
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(plan.deletes)))
+
+	// shifted returns where a row ends up once the deletes above it are applied
+	shifted := func(row int) int {
+		above := 0
+		for _, deleted := range plan.deletes {
+			if deleted < row {
+				above++
+			}
+		}
+		return row - above
+	}
+
+	next := max(len(existing), 1) - len(plan.deletes) + 1
+	for i, key := range tab.keys {
+		row, ok := rowOf[key]
+		if !ok {
+			plan.updates = append(plan.updates, rowUpdate{row: next, values: tab.build(next, i)})
+			next++
+			continue
+		}
+		// Compare against the row as built for its current position, since the
+		// sheet adjusts formula references itself when rows above are deleted
+		if cellsEqual(existing[row-1], tab.build(row, i)) {
+			continue
+		}
+		target := shifted(row)
+		plan.updates = append(plan.updates, rowUpdate{row: target, values: tab.build(target, i)})
+	}
+
+	return plan
+}
+
+// cellsEqual reports whether the cells read from the sheet match the cells
+// that would be written. Missing trailing cells read back as empty.
+func cellsEqual(existing, desired []any) bool {
+	for i, want := range desired {
+		got := ""
+		if i < len(existing) {
+			got = fmt.Sprint(existing[i])
+		}
+		if got != fmt.Sprint(want) {
+			return false
+		}
+	}
+	return true
+}
+
+// sheetID returns the ID of the tab with the given title.
+func (w *Writer) sheetID(ctx context.Context, spreadsheetID, title string) (int64, error) {
+	spreadsheet, err := w.service.Spreadsheets.Get(spreadsheetID).Context(ctx).Do()
+	if err != nil {
+		return 0, fmt.Errorf("unable to get spreadsheet: %w", err)
+	}
+	for _, sheet := range spreadsheet.Sheets {
+		if sheet.Properties.Title == title {
+			return sheet.Properties.SheetId, nil
+		}
+	}
+	return 0, fmt.Errorf("tab %q not found", title)
+}
+
+// hideColumn returns a request hiding one column (0-based) of a tab.
+func hideColumn(sheetID int64, column int) *sheets.Request {
+	return &sheets.Request{
+		UpdateDimensionProperties: &sheets.UpdateDimensionPropertiesRequest{
+			Range: &sheets.DimensionRange{
+				SheetId:    sheetID,
+				Dimension:  "COLUMNS",
+				StartIndex: int64(column),
+				EndIndex:   int64(column + 1),
+			},
+			Properties: &sheets.DimensionProperties{HiddenByUser: true},
+			Fields:     "hiddenByUser",
+		},
+	}
+}
+
+// columnLetter converts a 0-based column index to its A1 letter (0 -> A, 26 -> AA).
+func columnLetter(column int) string {
+	letters := ""
+	for column >= 0 {
+		letters = string(rune('A'+column%26)) + letters
+		column = column/26 - 1
+	}
+	return letters
+}
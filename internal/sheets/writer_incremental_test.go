@@ -0,0 +1,109 @@
+package sheets
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanKeyedTab(t *testing.T) {
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	income := func(key string, amount int64) IncomeRow {
+		return IncomeRow{Date: date, Amount: decimal.NewFromInt(amount), Key: key, Source: "Employer " + key, Category: "Salary"}
+	}
+	// existingRows renders rows the way a previous run would have written them
+	existingRows := func(rows ...IncomeRow) [][]any {
+		tab := incomeKeyedTab(rows)
+		values := [][]any{tab.header}
+		for i := range rows {
+			values = append(values, tab.build(i+2, i))
+		}
+		return values
+	}
+	header := incomeKeyedTab(nil).header
+
+	tests := []struct {
+		name     string
+		existing [][]any
+		rows     []IncomeRow
+		deletes  []int
+		updates  map[int]string // Sheet row -> key written there
+	}{
+		{
+			name:    "empty tab writes header and all rows",
+			rows:    []IncomeRow{income("a", 1), income("b", 2)},
+			updates: map[int]string{1: keyHeader, 2: "a", 3: "b"},
+		},
+		{
+			name:     "unchanged rows are left alone",
+			existing: existingRows(income("a", 1), income("b", 2)),
+			rows:     []IncomeRow{income("a", 1), income("b", 2)},
+			updates:  map[int]string{},
+		},
+		{
+			name:     "changed row is rewritten in place",
+			existing: existingRows(income("a", 1), income("b", 2)),
+			rows:     []IncomeRow{income("a", 1), income("b", 5)},
+			updates:  map[int]string{3: "b"},
+		},
+		{
+			name:     "existing order is kept and new rows are appended",
+			existing: existingRows(income("b", 2), income("a", 1)),
+			rows:     []IncomeRow{income("c", 3), income("a", 1), income("b", 2)},
+			updates:  map[int]string{4: "c"},
+		},
+		{
+			name:     "removed rows are deleted and changed rows below shift up",
+			existing: existingRows(income("a", 1), income("b", 2), income("c", 3)),
+			rows:     []IncomeRow{income("c", 4), income("d", 5)},
+			deletes:  []int{3, 2},
+			updates:  map[int]string{2: "c", 3: "d"},
+		},
+		{
+			name: "rows without a key and duplicate keys",
+			existing: append(existingRows(income("a", 1), income("a", 1)),
+				[]any{"2024-05-02", 10, "Side gig", "Manual"}),
+			rows:    []IncomeRow{income("a", 1)},
+			deletes: []int{3},
+			updates: map[int]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := planKeyedTab(tt.existing, incomeKeyedTab(tt.rows))
+
+			assert.Equal(t, tt.deletes, plan.deletes)
+			written := make(map[int]string, len(plan.updates))
+			for _, update := range plan.updates {
+				written[update.row] = update.values[len(header)-1].(string)
+			}
+			assert.Equal(t, tt.updates, written)
+		})
+	}
+}
+
+func TestPlanKeyedTab_FormulasFollowRow(t *testing.T) {
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	rows := []IncomeRow{
+		{Date: date, Amount: decimal.NewFromInt(1), Key: "a", Source: "A", Category: "Salary"},
+		{Date: date, Amount: decimal.NewFromInt(2), Key: "b", Source: "B", Category: "Salary"},
+	}
+	tab := incomeKeyedTab(rows)
+	existing := [][]any{tab.header, tab.build(2, 0), tab.build(3, 1)}
+
+	// Dropping "a" moves "b" up a row; the sheet adjusts its formula itself
+	plan := planKeyedTab(existing, incomeKeyedTab(rows[1:]))
+
+	assert.Equal(t, []int{2}, plan.deletes)
+	assert.Empty(t, plan.updates)
+}
+
+func TestColumnLetter(t *testing.T) {
+	assert.Equal(t, "A", columnLetter(0))
+	assert.Equal(t, "G", columnLetter(6))
+	assert.Equal(t, "Z", columnLetter(25))
+	assert.Equal(t, "AA", columnLetter(26))
+}
@@ -0,0 +1,68 @@
+package sheets
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter_RefundsOffsetExpenses(t *testing.T) {
+	categories := []model.Category{
+		{ID: 1, Name: "Shopping", Type: model.CategoryTypeExpense, IsActive: true},
+	}
+
+	testDate := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	classifications := []model.Classification{
+		{
+			Transaction: model.Transaction{ID: "purchase", Date: testDate, MerchantName: "Amazon", Amount: 80},
+			Category:    "Shopping",
+			Status:      model.StatusClassifiedByAI,
+		},
+		{
+			Transaction: model.Transaction{
+				ID:             "refund",
+				Date:           testDate.AddDate(0, 0, 5),
+				MerchantName:   "Amazon",
+				Amount:         30,
+				Direction:      model.DirectionIncome,
+				IsRefund:       true,
+				RefundCategory: "Shopping",
+			},
+			Category: "Shopping",
+			Status:   model.StatusClassifiedByRule,
+			Notes:    "Refund of purchase on 2024-03-10",
+		},
+	}
+
+	summary := &service.ReportSummary{
+		DateRange: service.DateRange{Start: testDate, End: testDate.AddDate(0, 1, 0)},
+	}
+
+	writer := &Writer{logger: testLogger()}
+	tabData, err := writer.aggregateData(classifications, summary, categories)
+	require.NoError(t, err)
+
+	require.Len(t, tabData.Expenses, 2)
+	assert.Empty(t, tabData.Income)
+	var refundRow *ExpenseRow
+	for i := range tabData.Expenses {
+		if tabData.Expenses[i].Date.Equal(testDate.AddDate(0, 0, 5)) {
+			refundRow = &tabData.Expenses[i]
+		}
+	}
+	require.NotNil(t, refundRow)
+	assert.True(t, refundRow.Amount.Equal(decimal.NewFromInt(-30)), "refund should offset spending")
+	assert.Equal(t, "Refund of purchase on 2024-03-10", refundRow.Notes)
+	assert.True(t, tabData.TotalExpenses.Equal(decimal.NewFromInt(50)))
+}
+
+func TestRefundNotes(t *testing.T) {
+	assert.Equal(t, "Refund", refundNotes(""))
+	assert.Equal(t, "Refund: returned item", refundNotes("returned item"))
+	assert.Equal(t, "Refund of purchase on 2024-03-10", refundNotes("Refund of purchase on 2024-03-10"))
+}
@@ -0,0 +1,84 @@
+package sheets
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter_DeductibleRounding(t *testing.T) {
+	categories := []model.Category{
+		{ID: 1, Name: "Meals", Type: model.CategoryTypeExpense, IsActive: true},
+	}
+
+	testDate := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	// 33% of $1.50 is $0.495, so line and total rounding disagree by a cent
+	var classifications []model.Classification
+	for i, amount := range []float64{1.50, 1.50, 1.50} {
+		classifications = append(classifications, model.Classification{
+			Transaction: model.Transaction{
+				ID:           string(rune('a' + i)),
+				Date:         testDate.AddDate(0, 0, i),
+				MerchantName: "Cafe",
+				Amount:       amount,
+			},
+			Category:        "Meals",
+			BusinessPercent: 33,
+			Status:          model.StatusClassifiedByRule,
+		})
+	}
+	summary := &service.ReportSummary{
+		DateRange: service.DateRange{Start: testDate, End: testDate.AddDate(0, 1, 0)},
+	}
+
+	aggregate := func(t *testing.T, rounding DeductibleRounding) *TabData {
+		t.Helper()
+		writer := &Writer{logger: testLogger(), config: Config{DeductibleRounding: rounding}}
+		data, err := writer.aggregateData(classifications, summary, categories)
+		require.NoError(t, err)
+		require.Len(t, data.BusinessExpenses, 3)
+		return data
+	}
+
+	t.Run("none keeps fractional cents", func(t *testing.T) {
+		data := aggregate(t, DeductibleRoundingNone)
+		assert.True(t, data.TotalDeductible.Equal(decimal.RequireFromString("1.485")), data.TotalDeductible.String())
+	})
+
+	t.Run("line rounds each item and the total is their sum", func(t *testing.T) {
+		data := aggregate(t, DeductibleRoundingLine)
+
+		sum := decimal.Zero
+		for _, expense := range data.BusinessExpenses {
+			assert.True(t, expense.DeductibleAmount.Equal(expense.DeductibleAmount.Round(2)),
+				"line item %s should be whole cents", expense.DeductibleAmount)
+			sum = sum.Add(expense.DeductibleAmount)
+		}
+		assert.True(t, data.TotalDeductible.Equal(sum))
+		assert.True(t, data.TotalDeductible.Equal(decimal.RequireFromString("1.50")), data.TotalDeductible.String())
+	})
+
+	t.Run("total rounds only the total", func(t *testing.T) {
+		data := aggregate(t, DeductibleRoundingTotal)
+		assert.True(t, data.BusinessExpenses[0].DeductibleAmount.Equal(decimal.RequireFromString("0.495")))
+		assert.True(t, data.TotalDeductible.Equal(decimal.RequireFromString("1.49")), data.TotalDeductible.String())
+	})
+}
+
+func TestConfig_ValidateDeductibleRounding(t *testing.T) {
+	config := DefaultConfig()
+	config.ServiceAccountPath = "/tmp/key.json"
+
+	for _, rounding := range []DeductibleRounding{"", DeductibleRoundingNone, DeductibleRoundingLine, DeductibleRoundingTotal} {
+		config.DeductibleRounding = rounding
+		assert.NoError(t, config.Validate(), rounding)
+	}
+
+	config.DeductibleRounding = "banker"
+	assert.Error(t, config.Validate())
+}
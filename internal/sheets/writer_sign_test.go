@@ -0,0 +1,87 @@
+package sheets
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter_SignConvention(t *testing.T) {
+	categories := []model.Category{
+		{ID: 1, Name: "Salary", Type: model.CategoryTypeIncome, IsActive: true},
+		{ID: 2, Name: "Groceries", Type: model.CategoryTypeExpense, IsActive: true},
+	}
+
+	testDate := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	classifications := []model.Classification{
+		{
+			Transaction: model.Transaction{ID: "pay", Date: testDate, MerchantName: "Employer", Amount: 3000, Direction: model.DirectionIncome},
+			Category:    "Salary",
+			Status:      model.StatusClassifiedByRule,
+		},
+		{
+			Transaction: model.Transaction{ID: "food", Date: testDate.AddDate(0, 0, 3), MerchantName: "Market", Amount: 200},
+			Category:    "Groceries",
+			Status:      model.StatusClassifiedByAI,
+		},
+	}
+	summary := &service.ReportSummary{
+		DateRange: service.DateRange{Start: testDate, End: testDate.AddDate(0, 1, 0)},
+	}
+
+	tests := []struct {
+		name       string
+		convention SignConvention
+		income     string
+		expenses   string
+	}{
+		{name: "default keeps amounts positive", convention: "", income: "3000", expenses: "200"},
+		{name: "positive", convention: SignConventionPositive, income: "3000", expenses: "200"},
+		{name: "expenses negative", convention: SignConventionExpensesNegative, income: "3000", expenses: "-200"},
+		{name: "income negative", convention: SignConventionIncomeNegative, income: "-3000", expenses: "200"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := &Writer{logger: testLogger(), config: Config{SignConvention: tt.convention}}
+			data, err := writer.aggregateData(classifications, summary, categories)
+			require.NoError(t, err)
+			require.Len(t, data.Income, 1)
+			require.Len(t, data.Expenses, 1)
+			require.Len(t, data.MonthlyFlow, 1)
+
+			income := decimal.RequireFromString(tt.income)
+			expenses := decimal.RequireFromString(tt.expenses)
+			assert.True(t, data.Income[0].Amount.Equal(income), data.Income[0].Amount.String())
+			assert.True(t, data.Expenses[0].Amount.Equal(expenses), data.Expenses[0].Amount.String())
+
+			flow := data.MonthlyFlow[0]
+			assert.True(t, flow.TotalIncome.Equal(income), flow.TotalIncome.String())
+			assert.True(t, flow.TotalExpenses.Equal(expenses), flow.TotalExpenses.String())
+			assert.True(t, flow.NetFlow.Equal(decimal.NewFromInt(2800)), flow.NetFlow.String())
+			assert.True(t, flow.RunningBalance.Equal(decimal.NewFromInt(2800)), flow.RunningBalance.String())
+
+			// Totals and summaries stay positive
+			assert.True(t, data.TotalIncome.Equal(decimal.NewFromInt(3000)))
+			assert.True(t, data.TotalExpenses.Equal(decimal.NewFromInt(200)))
+		})
+	}
+}
+
+func TestConfig_ValidateSignConvention(t *testing.T) {
+	config := DefaultConfig()
+	config.ServiceAccountPath = "/tmp/key.json"
+
+	for _, convention := range []SignConvention{"", SignConventionPositive, SignConventionExpensesNegative, SignConventionIncomeNegative} {
+		config.SignConvention = convention
+		assert.NoError(t, config.Validate(), convention)
+	}
+
+	config.SignConvention = "negative"
+	assert.Error(t, config.Validate())
+}
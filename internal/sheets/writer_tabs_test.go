@@ -0,0 +1,95 @@
+package sheets
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+// tabServer fakes the Sheets values API, recording which tabs are written in
+// what order and how many writes are in flight at once.
+type tabServer struct {
+	failTab     string
+	written     []string
+	inflight    int
+	maxInflight int
+	mu          sync.Mutex
+}
+
+func (s *tabServer) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	_, writeRange, _ := strings.Cut(r.URL.Path, "/values/")
+	tab, _, _ := strings.Cut(writeRange, "!")
+
+	s.mu.Lock()
+	s.inflight++
+	s.maxInflight = max(s.maxInflight, s.inflight)
+	s.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	s.mu.Lock()
+	s.inflight--
+	s.written = append(s.written, tab)
+	s.mu.Unlock()
+
+	if tab == s.failTab {
+		http.Error(rw, `{"error":{"code":400,"message":"bad range"}}`, http.StatusBadRequest)
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	_, _ = rw.Write([]byte(`{}`))
+}
+
+func newTabTestWriter(t *testing.T, handler http.Handler, concurrency int) *Writer {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	sheetsService, err := sheets.NewService(context.Background(),
+		option.WithEndpoint(server.URL+"/"),
+		option.WithHTTPClient(server.Client()))
+	require.NoError(t, err)
+
+	config := DefaultConfig()
+	config.WriteConcurrency = concurrency
+	return &Writer{service: sheetsService, logger: slog.Default(), config: config}
+}
+
+func TestWriter_writeAllTabs(t *testing.T) {
+	retryOpts := service.RetryOptions{MaxAttempts: 1}
+
+	t.Run("lookup tabs first, then the rest in parallel", func(t *testing.T) {
+		server := &tabServer{}
+		writer := newTabTestWriter(t, server, 2)
+
+		require.NoError(t, writer.writeAllTabs(context.Background(), "sheet", &TabData{}, retryOpts))
+
+		require.Len(t, server.written, 9)
+		assert.Equal(t, []string{"Vendor Lookup", "Category Lookup", "Business Rules"}, server.written[:3])
+		assert.ElementsMatch(t, []string{
+			"Expenses", "Income", "Vendor Summary", "Category Summary", "Business Expenses", "Monthly Flow",
+		}, server.written[3:])
+		assert.Equal(t, 2, server.maxInflight, "writes are capped at WriteConcurrency")
+	})
+
+	t.Run("first failure is returned with its tab", func(t *testing.T) {
+		server := &tabServer{failTab: "Income"}
+		writer := newTabTestWriter(t, server, 1)
+
+		err := writer.writeAllTabs(context.Background(), "sheet", &TabData{}, retryOpts)
+		require.ErrorContains(t, err, "failed to write income tab")
+		assert.Equal(t, 1, server.maxInflight)
+		assert.NotContains(t, server.written, "Monthly Flow", "remaining writes are canceled")
+	})
+}
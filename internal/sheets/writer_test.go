@@ -8,8 +8,10 @@ import (
 
 	"github.com/Veraticus/the-spice-must-flow/internal/model"
 	"github.com/Veraticus/the-spice-must-flow/internal/service"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/api/sheets/v4"
 )
 
 func TestConfig_Validate(t *testing.T) {
@@ -91,6 +93,34 @@ func TestConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "retry attempts cannot be negative",
 		},
+		{
+			name: "negative formatting batch size",
+			config: Config{
+				ClientID:            "test-client",
+				ClientSecret:        "test-secret",
+				RefreshToken:        "test-token",
+				BatchSize:           100,
+				FormattingBatchSize: -1,
+				RetryAttempts:       3,
+				RetryDelay:          time.Second,
+			},
+			wantErr: true,
+			errMsg:  "formatting batch size cannot be negative",
+		},
+		{
+			name: "negative write concurrency",
+			config: Config{
+				ClientID:         "test-client",
+				ClientSecret:     "test-secret",
+				RefreshToken:     "test-token",
+				BatchSize:        100,
+				WriteConcurrency: -1,
+				RetryAttempts:    3,
+				RetryDelay:       time.Second,
+			},
+			wantErr: true,
+			errMsg:  "write concurrency cannot be negative",
+		},
 	}
 
 	for _, tt := range tests {
@@ -315,16 +345,196 @@ func TestWriter_aggregateData(t *testing.T) {
 	assert.Equal(t, "20", tabData.TotalDeductible.String())
 }
 
+func TestWriter_aggregateData_VendorConfidence(t *testing.T) {
+	writer := &Writer{config: DefaultConfig(), logger: testLogger()}
+
+	classify := func(id, merchant, category string, confidence float64) model.Classification {
+		return model.Classification{
+			Transaction: model.Transaction{
+				ID: id, Date: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), MerchantName: merchant, Amount: 10,
+			},
+			Category:   category,
+			Status:     model.StatusClassifiedByAI,
+			Confidence: confidence,
+		}
+	}
+	classifications := []model.Classification{
+		classify("1", "Amazon", "Shopping", 0.9),
+		classify("2", "Amazon", "Shopping", 0.7),
+		classify("3", "Amazon", "Office Supplies", 0.5),
+		classify("4", "Amazon", "Shopping", 0.9),
+		classify("5", "Corner Cafe", "Dining", 0.8),
+	}
+	categories := []model.Category{
+		{ID: 1, Name: "Shopping", Type: model.CategoryTypeExpense},
+		{ID: 2, Name: "Office Supplies", Type: model.CategoryTypeExpense},
+		{ID: 3, Name: "Dining", Type: model.CategoryTypeExpense},
+	}
+	summary := &service.ReportSummary{}
+
+	tabData, err := writer.aggregateData(classifications, summary, categories)
+	require.NoError(t, err)
+	require.Len(t, tabData.VendorSummary, 2)
+
+	amazon := tabData.VendorSummary[0]
+	assert.Equal(t, "Amazon", amazon.VendorName)
+	assert.InDelta(t, 0.75, amazon.AverageConfidence, 0.0001)
+	assert.InDelta(t, 0.75, amazon.Consistency, 0.0001)
+
+	cafe := tabData.VendorSummary[1]
+	assert.InDelta(t, 0.8, cafe.AverageConfidence, 0.0001)
+	assert.InDelta(t, 1.0, cafe.Consistency, 0.0001)
+
+	// Columns appear only when enabled, and a lone transaction has no consistency
+	assert.Len(t, vendorSummaryValues(cafe, false, "c", "t", "n"), 4)
+	assert.Equal(t, []any{"Amazon", "c", "t", "n", amazon.AverageConfidence, amazon.Consistency},
+		vendorSummaryValues(amazon, true, "c", "t", "n"))
+	assert.Equal(t, []any{"Corner Cafe", "c", "t", "n", cafe.AverageConfidence, "n/a"},
+		vendorSummaryValues(cafe, true, "c", "t", "n"))
+}
+
+func TestWriter_aggregateData_ForeignCurrency(t *testing.T) {
+	writer := &Writer{
+		config: DefaultConfig(),
+		logger: slog.New(slog.NewTextHandler(os.Stderr, nil)),
+	}
+
+	classifications := []model.Classification{
+		{
+			Transaction: model.Transaction{
+				ID:               "1",
+				Date:             time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC),
+				MerchantName:     "Cafe Paris",
+				Amount:           110.00,
+				OriginalAmount:   100.00,
+				OriginalCurrency: "EUR",
+			},
+			Category: "Dining",
+		},
+		{
+			Transaction: model.Transaction{
+				ID:               "2",
+				Date:             time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC),
+				MerchantName:     "Hotel Lyon",
+				Amount:           130.00,
+				OriginalAmount:   100.00,
+				OriginalCurrency: "EUR",
+			},
+			Category: "Travel",
+		},
+		{
+			Transaction: model.Transaction{
+				ID:           "3",
+				Date:         time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC),
+				MerchantName: "Local Grocery",
+				Amount:       40.00,
+			},
+			Category: "Groceries",
+		},
+	}
+
+	summary := &service.ReportSummary{
+		DateRange: service.DateRange{
+			Start: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	tabData, err := writer.aggregateData(classifications, summary, nil)
+	require.NoError(t, err)
+
+	// Home-currency transactions don't appear in the FX report
+	require.Len(t, tabData.ForeignCurrency, 2)
+
+	// Sorted by date descending; average EUR rate is 240/200 = 1.2
+	hotel := tabData.ForeignCurrency[0]
+	assert.Equal(t, "Hotel Lyon", hotel.Vendor)
+	assert.Equal(t, "EUR", hotel.OriginalCurrency)
+	assert.Equal(t, "1.3", hotel.EffectiveRate.String())
+	assert.Equal(t, "1.2", hotel.AverageRate.String())
+	assert.Equal(t, "-10", hotel.FXGainLoss.String())
+
+	cafe := tabData.ForeignCurrency[1]
+	assert.Equal(t, "Cafe Paris", cafe.Vendor)
+	assert.Equal(t, "10", cafe.FXGainLoss.String())
+
+	assert.True(t, tabData.TotalFXGainLoss.IsZero())
+	assert.Equal(t, "280", tabData.TotalExpenses.String())
+}
+
+func TestCalculateFXGainLoss(t *testing.T) {
+	rows := []ForeignCurrencyRow{
+		{OriginalCurrency: "GBP", OriginalAmount: decimal.NewFromInt(100), PostedAmount: decimal.NewFromInt(125)},
+		{OriginalCurrency: "GBP", OriginalAmount: decimal.NewFromInt(100), PostedAmount: decimal.NewFromInt(135), IsIncome: true},
+		{OriginalCurrency: "JPY", OriginalAmount: decimal.NewFromInt(10000), PostedAmount: decimal.NewFromInt(68)},
+	}
+
+	total := calculateFXGainLoss(rows)
+
+	// GBP average rate is 260/200 = 1.3
+	assert.Equal(t, "5", rows[0].FXGainLoss.String(), "expense paid below the average rate is a gain")
+	assert.Equal(t, "5", rows[1].FXGainLoss.String(), "income received above the average rate is a gain")
+	// A single transaction in a currency is its own reference rate
+	assert.True(t, rows[2].FXGainLoss.IsZero())
+	assert.Equal(t, "10", total.String())
+}
+
 func TestDefaultConfig(t *testing.T) {
 	config := DefaultConfig()
 
 	assert.True(t, config.EnableFormatting)
 	assert.Equal(t, "America/New_York", config.TimeZone)
 	assert.Equal(t, 1000, config.BatchSize)
+	assert.Equal(t, DefaultFormattingBatchSize, config.FormattingBatchSize)
+	assert.Equal(t, 1, config.FormattingConcurrency)
+	assert.Equal(t, DefaultWriteConcurrency, config.WriteConcurrency)
 	assert.Equal(t, 3, config.RetryAttempts)
 	assert.Equal(t, time.Second, config.RetryDelay)
 }
 
+func TestChunkRequests(t *testing.T) {
+	makeRequests := func(n int) []*sheets.Request {
+		requests := make([]*sheets.Request, n)
+		for i := range requests {
+			requests[i] = &sheets.Request{}
+		}
+		return requests
+	}
+
+	tests := []struct {
+		name      string
+		wantSizes []int
+		count     int
+		size      int
+	}{
+		{name: "no requests", count: 0, size: 10, wantSizes: []int{}},
+		{name: "single partial chunk", count: 3, size: 10, wantSizes: []int{3}},
+		{name: "exact multiple", count: 20, size: 10, wantSizes: []int{10, 10}},
+		{name: "remainder chunk", count: 25, size: 10, wantSizes: []int{10, 10, 5}},
+		{name: "zero size uses default", count: DefaultFormattingBatchSize + 1, size: 0, wantSizes: []int{DefaultFormattingBatchSize, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := makeRequests(tt.count)
+			chunks := chunkRequests(requests, tt.size)
+
+			sizes := make([]int, 0, len(chunks))
+			var flattened []*sheets.Request
+			for _, chunk := range chunks {
+				sizes = append(sizes, len(chunk))
+				flattened = append(flattened, chunk...)
+			}
+			assert.Equal(t, tt.wantSizes, sizes)
+
+			// Order must be preserved across chunks
+			for i := range flattened {
+				assert.Same(t, requests[i], flattened[i])
+			}
+		})
+	}
+}
+
 func TestWriter_clearSheet(t *testing.T) {
 	// This test would require mocking the Google Sheets API
 	// For now, we'll just verify the function exists and can be called
@@ -469,6 +679,13 @@ func TestWriter_formatVendorSummaryTab(t *testing.T) {
 		}
 	}
 	assert.True(t, hasCurrency, "Vendor Summary tab should have currency formatting")
+
+	// Confidence columns add percentage formatting
+	writer.config.VendorConfidence = true
+	requests = writer.formatVendorSummaryTab(300)
+	require.Len(t, requests, 3)
+	assert.Equal(t, "PERCENT", requests[2].RepeatCell.Cell.UserEnteredFormat.NumberFormat.Type)
+	assert.Equal(t, int64(4), requests[2].RepeatCell.Range.StartColumnIndex)
 }
 
 func TestWriter_formatCategorySummaryTab(t *testing.T) {
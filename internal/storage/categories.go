@@ -21,7 +21,7 @@ func (s *SQLiteStorage) GetCategories(ctx context.Context) ([]model.Category, er
 	}
 
 	query := `
-		SELECT id, name, description, created_at, is_active, type, default_business_percent
+		SELECT id, name, description, created_at, is_active, type, default_business_percent, always_review, vendor_rule_threshold
 		FROM categories
 		WHERE is_active = 1
 		ORDER BY name`
@@ -41,7 +41,7 @@ func (s *SQLiteStorage) GetCategories(ctx context.Context) ([]model.Category, er
 		var cat model.Category
 		var catType sql.NullString
 		var defaultBusinessPercent sql.NullInt64
-		if err := rows.Scan(&cat.ID, &cat.Name, &cat.Description, &cat.CreatedAt, &cat.IsActive, &catType, &defaultBusinessPercent); err != nil {
+		if err := rows.Scan(&cat.ID, &cat.Name, &cat.Description, &cat.CreatedAt, &cat.IsActive, &catType, &defaultBusinessPercent, &cat.AlwaysReview, &cat.VendorRuleThreshold); err != nil {
 			return nil, fmt.Errorf("failed to scan category: %w", err)
 		}
 		// Set category type
@@ -60,6 +60,9 @@ func (s *SQLiteStorage) GetCategories(ctx context.Context) ([]model.Category, er
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating categories: %w", err)
 	}
+	if err := attachCategoryAliases(ctx, s.db, categories); err != nil {
+		return nil, err
+	}
 
 	slog.Debug("retrieved categories", "count", len(categories))
 	return categories, nil
@@ -76,7 +79,7 @@ func (s *SQLiteStorage) GetCategoryByName(ctx context.Context, name string) (*mo
 	}
 
 	query := `
-		SELECT id, name, description, created_at, is_active, type, default_business_percent
+		SELECT id, name, description, created_at, is_active, type, default_business_percent, always_review, vendor_rule_threshold
 		FROM categories
 		WHERE name = ? AND is_active = 1`
 
@@ -84,7 +87,7 @@ func (s *SQLiteStorage) GetCategoryByName(ctx context.Context, name string) (*mo
 	var catType sql.NullString
 	var defaultBusinessPercent sql.NullInt64
 	err := s.db.QueryRowContext(ctx, query, name).Scan(
-		&cat.ID, &cat.Name, &cat.Description, &cat.CreatedAt, &cat.IsActive, &catType, &defaultBusinessPercent,
+		&cat.ID, &cat.Name, &cat.Description, &cat.CreatedAt, &cat.IsActive, &catType, &defaultBusinessPercent, &cat.AlwaysReview, &cat.VendorRuleThreshold,
 	)
 
 	if err == sql.ErrNoRows {
@@ -119,7 +122,7 @@ func (s *SQLiteStorage) GetCategoryByID(ctx context.Context, id int) (*model.Cat
 	}
 
 	query := `
-		SELECT id, name, description, created_at, is_active, type, default_business_percent
+		SELECT id, name, description, created_at, is_active, type, default_business_percent, always_review, vendor_rule_threshold
 		FROM categories
 		WHERE id = ? AND is_active = 1`
 
@@ -127,7 +130,7 @@ func (s *SQLiteStorage) GetCategoryByID(ctx context.Context, id int) (*model.Cat
 	var catType sql.NullString
 	var defaultBusinessPercent sql.NullInt64
 	err := s.db.QueryRowContext(ctx, query, id).Scan(
-		&cat.ID, &cat.Name, &cat.Description, &cat.CreatedAt, &cat.IsActive, &catType, &defaultBusinessPercent,
+		&cat.ID, &cat.Name, &cat.Description, &cat.CreatedAt, &cat.IsActive, &catType, &defaultBusinessPercent, &cat.AlwaysReview, &cat.VendorRuleThreshold,
 	)
 
 	if err == sql.ErrNoRows {
@@ -161,6 +164,9 @@ func (s *SQLiteStorage) CreateCategoryWithType(ctx context.Context, name, descri
 	if err := validateContext(ctx); err != nil {
 		return nil, err
 	}
+	if err := s.checkWritable("create category"); err != nil {
+		return nil, err
+	}
 
 	if name == "" {
 		return nil, fmt.Errorf("category name cannot be empty")
@@ -168,14 +174,14 @@ func (s *SQLiteStorage) CreateCategoryWithType(ctx context.Context, name, descri
 
 	// Check if category already exists (including inactive ones)
 	existingQuery := `
-		SELECT id, name, description, created_at, is_active, type, default_business_percent
+		SELECT id, name, description, created_at, is_active, type, default_business_percent, always_review, vendor_rule_threshold
 		FROM categories
 		WHERE name = ?`
 
 	var existing model.Category
 	var typeStr sql.NullString
 	err := s.db.QueryRowContext(ctx, existingQuery, name).Scan(
-		&existing.ID, &existing.Name, &existing.Description, &existing.CreatedAt, &existing.IsActive, &typeStr, &existing.DefaultBusinessPercent,
+		&existing.ID, &existing.Name, &existing.Description, &existing.CreatedAt, &existing.IsActive, &typeStr, &existing.DefaultBusinessPercent, &existing.AlwaysReview, &existing.VendorRuleThreshold,
 	)
 
 	if err == nil {
@@ -236,7 +242,7 @@ func (t *sqliteTransaction) GetCategories(ctx context.Context) ([]model.Category
 	}
 
 	query := `
-		SELECT id, name, description, created_at, is_active, type, default_business_percent
+		SELECT id, name, description, created_at, is_active, type, default_business_percent, always_review, vendor_rule_threshold
 		FROM categories
 		WHERE is_active = 1
 		ORDER BY name`
@@ -256,7 +262,7 @@ func (t *sqliteTransaction) GetCategories(ctx context.Context) ([]model.Category
 		var cat model.Category
 		var catType sql.NullString
 		var defaultBusinessPercent sql.NullInt64
-		if err := rows.Scan(&cat.ID, &cat.Name, &cat.Description, &cat.CreatedAt, &cat.IsActive, &catType, &defaultBusinessPercent); err != nil {
+		if err := rows.Scan(&cat.ID, &cat.Name, &cat.Description, &cat.CreatedAt, &cat.IsActive, &catType, &defaultBusinessPercent, &cat.AlwaysReview, &cat.VendorRuleThreshold); err != nil {
 			return nil, fmt.Errorf("failed to scan category: %w", err)
 		}
 		// Set category type
@@ -275,6 +281,9 @@ func (t *sqliteTransaction) GetCategories(ctx context.Context) ([]model.Category
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating categories: %w", err)
 	}
+	if err := attachCategoryAliases(ctx, t.tx, categories); err != nil {
+		return nil, err
+	}
 
 	return categories, nil
 }
@@ -290,7 +299,7 @@ func (t *sqliteTransaction) GetCategoryByName(ctx context.Context, name string)
 	}
 
 	query := `
-		SELECT id, name, description, created_at, is_active, type, default_business_percent
+		SELECT id, name, description, created_at, is_active, type, default_business_percent, always_review, vendor_rule_threshold
 		FROM categories
 		WHERE name = ? AND is_active = 1`
 
@@ -298,7 +307,7 @@ func (t *sqliteTransaction) GetCategoryByName(ctx context.Context, name string)
 	var catType sql.NullString
 	var defaultBusinessPercent sql.NullInt64
 	err := t.tx.QueryRowContext(ctx, query, name).Scan(
-		&cat.ID, &cat.Name, &cat.Description, &cat.CreatedAt, &cat.IsActive, &catType, &defaultBusinessPercent,
+		&cat.ID, &cat.Name, &cat.Description, &cat.CreatedAt, &cat.IsActive, &catType, &defaultBusinessPercent, &cat.AlwaysReview, &cat.VendorRuleThreshold,
 	)
 
 	if err == sql.ErrNoRows {
@@ -404,6 +413,9 @@ func (s *SQLiteStorage) UpdateCategory(ctx context.Context, id int, name, descri
 	if err := validateContext(ctx); err != nil {
 		return err
 	}
+	if err := s.checkWritable("update category"); err != nil {
+		return err
+	}
 
 	if name == "" {
 		return fmt.Errorf("category name cannot be empty")
@@ -448,6 +460,9 @@ func (s *SQLiteStorage) UpdateCategoryBusinessPercent(ctx context.Context, id in
 	if err := validateContext(ctx); err != nil {
 		return err
 	}
+	if err := s.checkWritable("update category business percent"); err != nil {
+		return err
+	}
 
 	if businessPercent < 0 || businessPercent > 100 {
 		return fmt.Errorf("business percentage must be between 0 and 100")
@@ -491,11 +506,91 @@ func (s *SQLiteStorage) UpdateCategoryBusinessPercent(ctx context.Context, id in
 	return nil
 }
 
+// SetCategoryAlwaysReview sets whether transactions suggested for a category
+// always go to manual review.
+func (s *SQLiteStorage) SetCategoryAlwaysReview(ctx context.Context, id int, alwaysReview bool) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := s.checkWritable("update category review"); err != nil {
+		return err
+	}
+	if err := s.setCategoryAlwaysReviewTx(ctx, s.db, id, alwaysReview); err != nil {
+		return err
+	}
+
+	slog.Info("updated category review", "id", id, "always_review", alwaysReview)
+	return nil
+}
+
+func (s *SQLiteStorage) setCategoryAlwaysReviewTx(ctx context.Context, q queryable, id int, alwaysReview bool) error {
+	result, err := q.ExecContext(ctx, `
+		UPDATE categories
+		SET always_review = ?
+		WHERE id = ? AND is_active = 1`, alwaysReview, id)
+	if err != nil {
+		return fmt.Errorf("failed to update category review: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("category with ID %d not found", id)
+	}
+	return nil
+}
+
+// SetCategoryVendorRuleThreshold sets the confidence an auto-accepted
+// suggestion for a category needs before a vendor rule is created for its
+// merchant. 0 restores the default.
+func (s *SQLiteStorage) SetCategoryVendorRuleThreshold(ctx context.Context, id int, threshold float64) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := s.checkWritable("update category vendor rule threshold"); err != nil {
+		return err
+	}
+	if err := s.setCategoryVendorRuleThresholdTx(ctx, s.db, id, threshold); err != nil {
+		return err
+	}
+
+	slog.Info("updated category vendor rule threshold", "id", id, "threshold", threshold)
+	return nil
+}
+
+func (s *SQLiteStorage) setCategoryVendorRuleThresholdTx(ctx context.Context, q queryable, id int, threshold float64) error {
+	if threshold < 0 || threshold > 1 {
+		return fmt.Errorf("vendor rule threshold must be between 0 and 1, got %v", threshold)
+	}
+
+	result, err := q.ExecContext(ctx, `
+		UPDATE categories
+		SET vendor_rule_threshold = ?
+		WHERE id = ? AND is_active = 1`, threshold, id)
+	if err != nil {
+		return fmt.Errorf("failed to update category vendor rule threshold: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("category with ID %d not found", id)
+	}
+	return nil
+}
+
 // DeleteCategory soft-deletes a category by setting is_active to false.
 func (s *SQLiteStorage) DeleteCategory(ctx context.Context, id int) error {
 	if err := validateContext(ctx); err != nil {
 		return err
 	}
+	if err := s.checkWritable("delete category"); err != nil {
+		return err
+	}
 
 	// Check if category is in use
 	var usageCount int
@@ -532,6 +627,91 @@ func (s *SQLiteStorage) DeleteCategory(ctx context.Context, id int) error {
 	return nil
 }
 
+// MergeCategories moves every reference to the source category onto the target
+// and soft-deletes the source. All updates happen in a single transaction.
+func (s *SQLiteStorage) MergeCategories(ctx context.Context, fromID, toID int) (*model.CategoryMergeResult, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if err := s.checkWritable("merge categories"); err != nil {
+		return nil, err
+	}
+	if fromID == toID {
+		return nil, fmt.Errorf("cannot merge category %d into itself", fromID)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := s.mergeCategoriesTx(ctx, tx, fromID, toID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit category merge: %w", err)
+	}
+
+	if result.Vendors > 0 {
+		s.cacheMutex.Lock()
+		s.vendorCache = make(map[string]*model.Vendor)
+		s.cacheMutex.Unlock()
+	}
+
+	return result, nil
+}
+
+func (s *SQLiteStorage) mergeCategoriesTx(ctx context.Context, q queryable, fromID, toID int) (*model.CategoryMergeResult, error) {
+	names := make(map[int]string, 2)
+	for _, id := range []int{fromID, toID} {
+		var name string
+		err := q.QueryRowContext(ctx, `SELECT name FROM categories WHERE id = ? AND is_active = 1`, id).Scan(&name)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("category with ID %d not found", id)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get category %d: %w", id, err)
+		}
+		names[id] = name
+	}
+	fromName, toName := names[fromID], names[toID]
+
+	result := &model.CategoryMergeResult{}
+	updates := []struct {
+		count *int64
+		query string
+	}{
+		{&result.Classifications, `UPDATE classifications SET category = ? WHERE category = ?`},
+		{&result.Vendors, `UPDATE vendors SET category = ? WHERE category = ?`},
+		{&result.CheckPatterns, `UPDATE check_patterns SET category = ? WHERE category = ?`},
+		{&result.PatternRules, `UPDATE pattern_rules SET default_category = ? WHERE default_category = ?`},
+		{nil, `UPDATE transactions SET refund_category = ? WHERE refund_category = ?`},
+		{nil, `UPDATE category_aliases SET category = ? WHERE category = ?`},
+	}
+	for _, update := range updates {
+		res, err := q.ExecContext(ctx, update.query, toName, fromName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to move references from %q to %q: %w", fromName, toName, err)
+		}
+		if update.count != nil {
+			if *update.count, err = res.RowsAffected(); err != nil {
+				return nil, fmt.Errorf("failed to get rows affected: %w", err)
+			}
+		}
+	}
+
+	if _, err := q.ExecContext(ctx, `UPDATE categories SET is_active = 0 WHERE id = ?`, fromID); err != nil {
+		return nil, fmt.Errorf("failed to delete merged category: %w", err)
+	}
+
+	slog.Info("merged category", "from", fromName, "to", toName,
+		"classifications", result.Classifications, "vendors", result.Vendors)
+	return result, nil
+}
+
 // Transaction implementations for UpdateCategory and DeleteCategory
 
 // UpdateCategory updates an existing category within a transaction.
@@ -624,6 +804,22 @@ func (t *sqliteTransaction) UpdateCategoryBusinessPercent(ctx context.Context, i
 	return nil
 }
 
+// SetCategoryAlwaysReview sets whether a category always needs review within a transaction.
+func (t *sqliteTransaction) SetCategoryAlwaysReview(ctx context.Context, id int, alwaysReview bool) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	return t.storage.setCategoryAlwaysReviewTx(ctx, t.tx, id, alwaysReview)
+}
+
+// SetCategoryVendorRuleThreshold sets a category's vendor rule threshold within a transaction.
+func (t *sqliteTransaction) SetCategoryVendorRuleThreshold(ctx context.Context, id int, threshold float64) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	return t.storage.setCategoryVendorRuleThresholdTx(ctx, t.tx, id, threshold)
+}
+
 // DeleteCategory soft-deletes a category within a transaction.
 func (t *sqliteTransaction) DeleteCategory(ctx context.Context, id int) error {
 	if err := validateContext(ctx); err != nil {
@@ -663,3 +859,24 @@ func (t *sqliteTransaction) DeleteCategory(ctx context.Context, id int) error {
 
 	return nil
 }
+
+// MergeCategories moves every reference to the source category onto the target within a transaction.
+func (t *sqliteTransaction) MergeCategories(ctx context.Context, fromID, toID int) (*model.CategoryMergeResult, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if fromID == toID {
+		return nil, fmt.Errorf("cannot merge category %d into itself", fromID)
+	}
+	result, err := t.storage.mergeCategoriesTx(ctx, t.tx, fromID, toID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Vendors may have moved; drop cached entries so lookups see the new category
+	t.storage.cacheMutex.Lock()
+	t.storage.vendorCache = make(map[string]*model.Vendor)
+	t.storage.cacheMutex.Unlock()
+
+	return result, nil
+}
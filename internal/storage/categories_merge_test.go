@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+func TestMergeCategories(t *testing.T) {
+	ctx := context.Background()
+	store, cleanup := createTestStorage(t)
+	defer cleanup()
+
+	keep, err := store.CreateCategory(ctx, "Travel", "Trips and flights")
+	require.NoError(t, err)
+	dup, err := store.CreateCategory(ctx, "travel ", "Imported duplicate")
+	require.NoError(t, err)
+
+	txns := createTestTransactions(2)
+	require.NoError(t, store.SaveTransactions(ctx, txns))
+	require.NoError(t, store.SaveClassification(ctx, &model.Classification{
+		Transaction: txns[0], Category: keep.Name, Status: model.StatusUserModified, Confidence: 1, ClassifiedAt: time.Now(),
+	}))
+	require.NoError(t, store.SaveClassification(ctx, &model.Classification{
+		Transaction: txns[1], Category: dup.Name, Status: model.StatusClassifiedByAI, Confidence: 0.9, ClassifiedAt: time.Now(),
+	}))
+	require.NoError(t, store.SaveVendor(ctx, &model.Vendor{Name: "Delta", Category: dup.Name, Source: model.SourceManual}))
+
+	// Prime the vendor cache so the merge has to invalidate it
+	_, err = store.GetVendor(ctx, "Delta")
+	require.NoError(t, err)
+
+	minAmount, maxAmount := 100.0, 200.0
+	require.NoError(t, store.CreateCheckPattern(ctx, &model.CheckPattern{
+		PatternName: "Airfare check", Category: dup.Name, AmountMin: &minAmount, AmountMax: &maxAmount,
+	}))
+	require.NoError(t, store.CreatePatternRule(ctx, &model.PatternRule{
+		Name: "Airlines", MerchantPattern: "AIRLINE", AmountCondition: "any", DefaultCategory: dup.Name,
+		Confidence: 0.9, IsActive: true,
+	}))
+
+	result, err := store.MergeCategories(ctx, dup.ID, keep.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.CategoryMergeResult{Classifications: 1, Vendors: 1, CheckPatterns: 1, PatternRules: 1}, *result)
+
+	classifications, err := store.GetClassificationsByDateRange(ctx, time.Now().AddDate(-1, 0, 0), time.Now().AddDate(0, 0, 1))
+	require.NoError(t, err)
+	require.Len(t, classifications, 2)
+	for _, c := range classifications {
+		assert.Equal(t, "Travel", c.Category)
+	}
+
+	vendor, err := store.GetVendor(ctx, "Delta")
+	require.NoError(t, err)
+	assert.Equal(t, "Travel", vendor.Category)
+
+	patterns, err := store.GetActiveCheckPatterns(ctx)
+	require.NoError(t, err)
+	require.Len(t, patterns, 1)
+	assert.Equal(t, "Travel", patterns[0].Category)
+
+	rules, err := store.GetActivePatternRules(ctx)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, "Travel", rules[0].DefaultCategory)
+
+	categories, err := store.GetCategories(ctx)
+	require.NoError(t, err)
+	for _, cat := range categories {
+		assert.NotEqual(t, dup.ID, cat.ID, "merged category should be deleted")
+	}
+
+	t.Run("rejects invalid ids", func(t *testing.T) {
+		_, err := store.MergeCategories(ctx, keep.ID, keep.ID)
+		assert.Error(t, err, "merging into itself")
+
+		_, err = store.MergeCategories(ctx, dup.ID, keep.ID)
+		assert.Error(t, err, "source already merged")
+
+		_, err = store.MergeCategories(ctx, keep.ID, 9999)
+		assert.Error(t, err, "missing target")
+	})
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetCategoryAlwaysReview(t *testing.T) {
+	ctx := context.Background()
+	store, cleanup := createTestStorageWithCategories(t, "Medical", "Groceries")
+	defer cleanup()
+
+	medical, err := store.GetCategoryByName(ctx, "Medical")
+	require.NoError(t, err)
+	assert.False(t, medical.AlwaysReview)
+
+	require.NoError(t, store.SetCategoryAlwaysReview(ctx, medical.ID, true))
+
+	medical, err = store.GetCategoryByID(ctx, medical.ID)
+	require.NoError(t, err)
+	assert.True(t, medical.AlwaysReview)
+
+	categories, err := store.GetCategories(ctx)
+	require.NoError(t, err)
+	for _, category := range categories {
+		assert.Equal(t, category.Name == "Medical", category.AlwaysReview, category.Name)
+	}
+
+	require.NoError(t, store.SetCategoryAlwaysReview(ctx, medical.ID, false))
+	medical, err = store.GetCategoryByName(ctx, "Medical")
+	require.NoError(t, err)
+	assert.False(t, medical.AlwaysReview)
+
+	assert.ErrorContains(t, store.SetCategoryAlwaysReview(ctx, 999, true), "category with ID 999 not found")
+}
+
+func TestSetCategoryVendorRuleThreshold(t *testing.T) {
+	ctx := context.Background()
+	store, cleanup := createTestStorageWithCategories(t, "Business", "Groceries")
+	defer cleanup()
+
+	business, err := store.GetCategoryByName(ctx, "Business")
+	require.NoError(t, err)
+	assert.Zero(t, business.VendorRuleThreshold)
+
+	require.NoError(t, store.SetCategoryVendorRuleThreshold(ctx, business.ID, 0.98))
+
+	business, err = store.GetCategoryByID(ctx, business.ID)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.98, business.VendorRuleThreshold, 0.0001)
+
+	categories, err := store.GetCategories(ctx)
+	require.NoError(t, err)
+	for _, category := range categories {
+		if category.Name == "Groceries" {
+			assert.Zero(t, category.VendorRuleThreshold)
+		}
+	}
+
+	require.NoError(t, store.SetCategoryVendorRuleThreshold(ctx, business.ID, 0))
+	business, err = store.GetCategoryByName(ctx, "Business")
+	require.NoError(t, err)
+	assert.Zero(t, business.VendorRuleThreshold)
+
+	assert.ErrorContains(t, store.SetCategoryVendorRuleThreshold(ctx, business.ID, 1.5), "must be between 0 and 1")
+	assert.ErrorContains(t, store.SetCategoryVendorRuleThreshold(ctx, 999, 0.9), "category with ID 999 not found")
+}
@@ -0,0 +1,154 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/common"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+// SaveCategoryAlias maps alias onto an active category, replacing any category
+// the alias pointed to before. Aliases are matched case-insensitively and may
+// not be the name of an active category.
+func (s *SQLiteStorage) SaveCategoryAlias(ctx context.Context, alias, category string) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := s.checkWritable("save category alias"); err != nil {
+		return err
+	}
+	return s.saveCategoryAliasTx(ctx, s.db, alias, category)
+}
+
+func (s *SQLiteStorage) saveCategoryAliasTx(ctx context.Context, q queryable, alias, category string) error {
+	alias = strings.TrimSpace(alias)
+	if err := validateString(alias, "alias"); err != nil {
+		return err
+	}
+	if err := validateString(category, "category"); err != nil {
+		return err
+	}
+
+	var canonical string
+	err := q.QueryRowContext(ctx, `SELECT name FROM categories WHERE name = ? COLLATE NOCASE AND is_active = 1`, category).Scan(&canonical)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%w: %s", ErrCategoryNotFound, category)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get category: %w", err)
+	}
+
+	var existing string
+	err = q.QueryRowContext(ctx, `SELECT name FROM categories WHERE name = ? COLLATE NOCASE AND is_active = 1`, alias).Scan(&existing)
+	if err == nil {
+		return fmt.Errorf("alias %q is already a category name", alias)
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("failed to check alias against categories: %w", err)
+	}
+
+	_, err = q.ExecContext(ctx, `
+		INSERT INTO category_aliases (alias, category)
+		VALUES (?, ?)
+		ON CONFLICT(alias) DO UPDATE SET
+			category = excluded.category
+	`, alias, canonical)
+	if err != nil {
+		return fmt.Errorf("failed to save category alias: %w", err)
+	}
+	return nil
+}
+
+// GetCategoryAliases returns every category alias, ordered by category and alias.
+func (s *SQLiteStorage) GetCategoryAliases(ctx context.Context) ([]model.CategoryAlias, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	return s.getCategoryAliasesTx(ctx, s.db)
+}
+
+func (s *SQLiteStorage) getCategoryAliasesTx(ctx context.Context, q queryable) ([]model.CategoryAlias, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT alias, category, created_at
+		FROM category_aliases
+		ORDER BY category, alias
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query category aliases: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var aliases []model.CategoryAlias
+	for rows.Next() {
+		var alias model.CategoryAlias
+		if err := rows.Scan(&alias.Alias, &alias.Category, &alias.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan category alias: %w", err)
+		}
+		aliases = append(aliases, alias)
+	}
+
+	return aliases, rows.Err()
+}
+
+// DeleteCategoryAlias removes an alias.
+func (s *SQLiteStorage) DeleteCategoryAlias(ctx context.Context, alias string) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := s.checkWritable("delete category alias"); err != nil {
+		return err
+	}
+	return s.deleteCategoryAliasTx(ctx, s.db, alias)
+}
+
+func (s *SQLiteStorage) deleteCategoryAliasTx(ctx context.Context, q queryable, alias string) error {
+	if err := validateString(alias, "alias"); err != nil {
+		return err
+	}
+
+	result, err := q.ExecContext(ctx, `DELETE FROM category_aliases WHERE alias = ?`, strings.TrimSpace(alias))
+	if err != nil {
+		return fmt.Errorf("failed to delete category alias: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return common.ErrNotFound
+	}
+	return nil
+}
+
+// attachCategoryAliases fills in the aliases of each category.
+func attachCategoryAliases(ctx context.Context, q queryable, categories []model.Category) error {
+	if len(categories) == 0 {
+		return nil
+	}
+
+	rows, err := q.QueryContext(ctx, `SELECT alias, category FROM category_aliases ORDER BY alias`)
+	if err != nil {
+		return fmt.Errorf("failed to query category aliases: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	byCategory := make(map[string][]string)
+	for rows.Next() {
+		var alias, category string
+		if err := rows.Scan(&alias, &category); err != nil {
+			return fmt.Errorf("failed to scan category alias: %w", err)
+		}
+		byCategory[category] = append(byCategory[category], alias)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating category aliases: %w", err)
+	}
+
+	for i := range categories {
+		categories[i].Aliases = byCategory[categories[i].Name]
+	}
+	return nil
+}
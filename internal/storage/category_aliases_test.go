@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/common"
+)
+
+func TestSQLiteStorage_CategoryAliases(t *testing.T) {
+	store, cleanup := createTestStorageWithCategories(t, "Food & Dining", "Shopping")
+	defer cleanup()
+	ctx := context.Background()
+
+	if err := store.SaveCategoryAlias(ctx, "Dining", "food & dining"); err != nil {
+		t.Fatalf("Failed to save alias: %v", err)
+	}
+	if err := store.SaveCategoryAlias(ctx, "Restaurants", "Food & Dining"); err != nil {
+		t.Fatalf("Failed to save alias: %v", err)
+	}
+	// Saving again under a different case moves the alias
+	if err := store.SaveCategoryAlias(ctx, "restaurants", "Shopping"); err != nil {
+		t.Fatalf("Failed to move alias: %v", err)
+	}
+
+	if err := store.SaveCategoryAlias(ctx, "Eating Out", "Travel"); !errors.Is(err, ErrCategoryNotFound) {
+		t.Errorf("Expected ErrCategoryNotFound for an unknown category, got %v", err)
+	}
+	if err := store.SaveCategoryAlias(ctx, "shopping", "Food & Dining"); err == nil {
+		t.Error("Expected an error for an alias that is a category name")
+	}
+
+	aliases, err := store.GetCategoryAliases(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get aliases: %v", err)
+	}
+	if len(aliases) != 2 {
+		t.Fatalf("Expected 2 aliases, got %d: %+v", len(aliases), aliases)
+	}
+	if aliases[0].Alias != "Dining" || aliases[0].Category != "Food & Dining" {
+		t.Errorf("Unexpected first alias: %+v", aliases[0])
+	}
+	if aliases[1].Alias != "Restaurants" || aliases[1].Category != "Shopping" {
+		t.Errorf("Unexpected second alias: %+v", aliases[1])
+	}
+
+	categories, err := store.GetCategories(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get categories: %v", err)
+	}
+	got := make(map[string][]string)
+	for _, category := range categories {
+		got[category.Name] = category.Aliases
+	}
+	want := map[string][]string{"Food & Dining": {"Dining"}, "Shopping": {"Restaurants"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected category aliases %v, got %v", want, got)
+	}
+
+	if err := store.DeleteCategoryAlias(ctx, "DINING"); err != nil {
+		t.Fatalf("Failed to delete alias: %v", err)
+	}
+	if err := store.DeleteCategoryAlias(ctx, "Dining"); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound deleting a missing alias, got %v", err)
+	}
+}
+
+func TestSQLiteStorage_MergeCategoriesMovesAliases(t *testing.T) {
+	store, cleanup := createTestStorageWithCategories(t, "Dining", "Food")
+	defer cleanup()
+	ctx := context.Background()
+
+	if err := store.SaveCategoryAlias(ctx, "Restaurants", "Dining"); err != nil {
+		t.Fatalf("Failed to save alias: %v", err)
+	}
+
+	from, err := store.GetCategoryByName(ctx, "Dining")
+	if err != nil {
+		t.Fatalf("Failed to get category: %v", err)
+	}
+	to, err := store.GetCategoryByName(ctx, "Food")
+	if err != nil {
+		t.Fatalf("Failed to get category: %v", err)
+	}
+	if _, err := store.MergeCategories(ctx, from.ID, to.ID); err != nil {
+		t.Fatalf("Failed to merge categories: %v", err)
+	}
+
+	aliases, err := store.GetCategoryAliases(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get aliases: %v", err)
+	}
+	if len(aliases) != 1 || aliases[0].Category != "Food" {
+		t.Errorf("Expected the alias to follow the merge into Food, got %+v", aliases)
+	}
+}
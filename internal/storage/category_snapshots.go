@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/common"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+// snapshotCategory is the part of a category kept in a snapshot.
+type snapshotCategory struct {
+	Name                   string             `json:"name"`
+	Description            string             `json:"description,omitempty"`
+	Type                   model.CategoryType `json:"type"`
+	DefaultBusinessPercent int                `json:"default_business_percent"`
+}
+
+// SaveCategorySnapshot stores a snapshot of a category set and sets its ID and
+// creation time.
+func (s *SQLiteStorage) SaveCategorySnapshot(ctx context.Context, snapshot *model.CategorySnapshot) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := s.checkWritable("save category snapshot"); err != nil {
+		return err
+	}
+	return s.saveCategorySnapshotTx(ctx, s.db, snapshot)
+}
+
+func (s *SQLiteStorage) saveCategorySnapshotTx(ctx context.Context, q queryable, snapshot *model.CategorySnapshot) error {
+	if snapshot == nil {
+		return fmt.Errorf("category snapshot cannot be nil")
+	}
+	if err := validateString(snapshot.Report, "report"); err != nil {
+		return err
+	}
+
+	categories := make([]snapshotCategory, 0, len(snapshot.Categories))
+	for _, category := range snapshot.Categories {
+		categories = append(categories, snapshotCategory{
+			Name:                   category.Name,
+			Description:            category.Description,
+			Type:                   category.Type,
+			DefaultBusinessPercent: category.DefaultBusinessPercent,
+		})
+	}
+	data, err := json.Marshal(categories)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot categories: %w", err)
+	}
+
+	createdAt := time.Now()
+	result, err := q.ExecContext(ctx, `
+		INSERT INTO category_snapshots (report, period_start, period_end, categories, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, snapshot.Report, snapshot.PeriodStart, snapshot.PeriodEnd, string(data), createdAt)
+	if err != nil {
+		return fmt.Errorf("failed to save category snapshot: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get category snapshot ID: %w", err)
+	}
+	snapshot.ID = id
+	snapshot.CreatedAt = createdAt
+	return nil
+}
+
+// GetCategorySnapshot returns a category snapshot by ID.
+func (s *SQLiteStorage) GetCategorySnapshot(ctx context.Context, id int64) (*model.CategorySnapshot, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	return s.getCategorySnapshotTx(ctx, s.db, id)
+}
+
+func (s *SQLiteStorage) getCategorySnapshotTx(ctx context.Context, q queryable, id int64) (*model.CategorySnapshot, error) {
+	row := q.QueryRowContext(ctx, `
+		SELECT id, report, period_start, period_end, categories, created_at
+		FROM category_snapshots
+		WHERE id = ?
+	`, id)
+
+	snapshot, err := scanCategorySnapshot(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: category snapshot %d", common.ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// GetCategorySnapshots returns every category snapshot, newest first.
+func (s *SQLiteStorage) GetCategorySnapshots(ctx context.Context) ([]model.CategorySnapshot, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	return s.getCategorySnapshotsTx(ctx, s.db)
+}
+
+func (s *SQLiteStorage) getCategorySnapshotsTx(ctx context.Context, q queryable) ([]model.CategorySnapshot, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT id, report, period_start, period_end, categories, created_at
+		FROM category_snapshots
+		ORDER BY id DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query category snapshots: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var snapshots []model.CategorySnapshot
+	for rows.Next() {
+		snapshot, err := scanCategorySnapshot(rows)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, *snapshot)
+	}
+
+	return snapshots, rows.Err()
+}
+
+// scanCategorySnapshot scans a category_snapshots row. sql.ErrNoRows is
+// returned as is.
+func scanCategorySnapshot(row interface{ Scan(dest ...any) error }) (*model.CategorySnapshot, error) {
+	var snapshot model.CategorySnapshot
+	var data string
+	if err := row.Scan(&snapshot.ID, &snapshot.Report, &snapshot.PeriodStart, &snapshot.PeriodEnd, &data, &snapshot.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan category snapshot: %w", err)
+	}
+
+	var categories []snapshotCategory
+	if err := json.Unmarshal([]byte(data), &categories); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal categories of snapshot %d: %w", snapshot.ID, err)
+	}
+	snapshot.Categories = make([]model.Category, 0, len(categories))
+	for _, category := range categories {
+		snapshot.Categories = append(snapshot.Categories, model.Category{
+			Name:                   category.Name,
+			Description:            category.Description,
+			Type:                   category.Type,
+			DefaultBusinessPercent: category.DefaultBusinessPercent,
+			IsActive:               true,
+		})
+	}
+	return &snapshot, nil
+}
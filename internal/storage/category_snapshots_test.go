@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/common"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+func TestSQLiteStorage_CategorySnapshots(t *testing.T) {
+	store, cleanup := createTestStorageWithCategories(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC)
+	categories := []model.Category{
+		{ID: 7, Name: "Food", Description: "Meals", Type: model.CategoryTypeExpense, DefaultBusinessPercent: 0, Aliases: []string{"Dining"}},
+		{ID: 8, Name: "Office", Type: model.CategoryTypeExpense, DefaultBusinessPercent: 100},
+		{ID: 9, Name: "Salary", Type: model.CategoryTypeIncome},
+	}
+
+	first := &model.CategorySnapshot{Report: "flow", PeriodStart: start, PeriodEnd: end, Categories: categories}
+	if err := store.SaveCategorySnapshot(ctx, first); err != nil {
+		t.Fatalf("Failed to save snapshot: %v", err)
+	}
+	if first.ID == 0 || first.CreatedAt.IsZero() {
+		t.Fatalf("Expected the snapshot ID and creation time to be set, got %+v", first)
+	}
+	second := &model.CategorySnapshot{Report: "flow", PeriodStart: start, PeriodEnd: end}
+	if err := store.SaveCategorySnapshot(ctx, second); err != nil {
+		t.Fatalf("Failed to save snapshot: %v", err)
+	}
+	if err := store.SaveCategorySnapshot(ctx, &model.CategorySnapshot{}); err == nil {
+		t.Error("Expected an error for a snapshot without a report")
+	}
+
+	got, err := store.GetCategorySnapshot(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("Failed to get snapshot: %v", err)
+	}
+	if got.Report != "flow" || !got.PeriodStart.Equal(start) || !got.PeriodEnd.Equal(end) {
+		t.Errorf("Unexpected snapshot: %+v", got)
+	}
+	if len(got.Categories) != 3 {
+		t.Fatalf("Expected 3 categories, got %d", len(got.Categories))
+	}
+	// Only the name, description, type and business percent are kept
+	want := model.Category{Name: "Food", Description: "Meals", Type: model.CategoryTypeExpense, IsActive: true}
+	if got.Categories[0].Name != want.Name || got.Categories[0].Description != want.Description ||
+		got.Categories[0].Type != want.Type || got.Categories[0].ID != 0 || got.Categories[0].Aliases != nil {
+		t.Errorf("Expected %+v, got %+v", want, got.Categories[0])
+	}
+	if got.Categories[1].DefaultBusinessPercent != 100 || got.Categories[2].Type != model.CategoryTypeIncome {
+		t.Errorf("Unexpected categories: %+v", got.Categories)
+	}
+
+	snapshots, err := store.GetCategorySnapshots(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list snapshots: %v", err)
+	}
+	if len(snapshots) != 2 || snapshots[0].ID != second.ID || len(snapshots[0].Categories) != 0 {
+		t.Errorf("Expected the newest snapshot first, got %+v", snapshots)
+	}
+
+	if _, err := store.GetCategorySnapshot(ctx, 999); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing snapshot, got %v", err)
+	}
+}
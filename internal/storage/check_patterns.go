@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"sort"
 
 	"github.com/Veraticus/the-spice-must-flow/internal/model"
 )
@@ -19,7 +20,14 @@ func (s *SQLiteStorage) CreateCheckPattern(ctx context.Context, pattern *model.C
 	if err := validateContext(ctx); err != nil {
 		return err
 	}
+	if err := s.checkWritable("create check pattern"); err != nil {
+		return err
+	}
+	return s.createCheckPatternTx(ctx, s.db, pattern)
+}
 
+// createCheckPatternTx creates a check pattern using q, which may be a transaction.
+func (s *SQLiteStorage) createCheckPatternTx(ctx context.Context, q queryable, pattern *model.CheckPattern) error {
 	if pattern == nil {
 		return fmt.Errorf("pattern cannot be nil")
 	}
@@ -53,13 +61,14 @@ func (s *SQLiteStorage) CreateCheckPattern(ctx context.Context, pattern *model.C
 	query := `
 		INSERT INTO check_patterns (
 			pattern_name, amount_min, amount_max, check_number_pattern,
-			day_of_month_min, day_of_month_max, category, notes, amounts
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+			day_of_month_min, day_of_month_max, category, notes, amounts,
+			memo_pattern, confidence
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	result, err := s.db.ExecContext(ctx, query,
+	result, err := q.ExecContext(ctx, query,
 		pattern.PatternName, pattern.AmountMin, pattern.AmountMax, checkNumberJSON,
 		pattern.DayOfMonthMin, pattern.DayOfMonthMax, pattern.Category, pattern.Notes,
-		amountsJSON,
+		amountsJSON, pattern.MemoPattern, pattern.EffectiveConfidence(),
 	)
 
 	if err != nil {
@@ -72,6 +81,7 @@ func (s *SQLiteStorage) CreateCheckPattern(ctx context.Context, pattern *model.C
 	}
 
 	pattern.ID = id
+	pattern.Confidence = pattern.EffectiveConfidence()
 	slog.Info("created check pattern", "id", id, "name", pattern.PatternName)
 	return nil
 }
@@ -85,19 +95,22 @@ func (s *SQLiteStorage) GetCheckPattern(ctx context.Context, id int64) (*model.C
 	query := `
 		SELECT id, pattern_name, amount_min, amount_max, check_number_pattern,
 			day_of_month_min, day_of_month_max, category, notes,
-			use_count, created_at, updated_at, amounts
+			use_count, created_at, updated_at, amounts, memo_pattern, confidence
 		FROM check_patterns
 		WHERE id = ?`
 
 	pattern := &model.CheckPattern{}
 	var checkNumberJSON sql.NullString
 	var amountsJSON sql.NullString
+	var memoPattern sql.NullString
+	var confidence sql.NullFloat64
 
 	err := s.db.QueryRowContext(ctx, query, id).Scan(
 		&pattern.ID, &pattern.PatternName, &pattern.AmountMin, &pattern.AmountMax,
 		&checkNumberJSON, &pattern.DayOfMonthMin, &pattern.DayOfMonthMax,
 		&pattern.Category, &pattern.Notes,
 		&pattern.UseCount, &pattern.CreatedAt, &pattern.UpdatedAt, &amountsJSON,
+		&memoPattern, &confidence,
 	)
 
 	if err == sql.ErrNoRows {
@@ -122,6 +135,9 @@ func (s *SQLiteStorage) GetCheckPattern(ctx context.Context, id int64) (*model.C
 			return nil, fmt.Errorf("failed to unmarshal amounts: %w", err)
 		}
 	}
+	pattern.MemoPattern = memoPattern.String
+	pattern.Confidence = confidence.Float64
+	pattern.Confidence = pattern.EffectiveConfidence()
 
 	return pattern, nil
 }
@@ -131,15 +147,19 @@ func (s *SQLiteStorage) GetActiveCheckPatterns(ctx context.Context) ([]model.Che
 	if err := validateContext(ctx); err != nil {
 		return nil, err
 	}
+	return s.getActiveCheckPatternsTx(ctx, s.db)
+}
 
+// getActiveCheckPatternsTx returns all check patterns using q, which may be a transaction.
+func (s *SQLiteStorage) getActiveCheckPatternsTx(ctx context.Context, q queryable) ([]model.CheckPattern, error) {
 	query := `
 		SELECT id, pattern_name, amount_min, amount_max, check_number_pattern,
 			day_of_month_min, day_of_month_max, category, notes,
-			use_count, created_at, updated_at, amounts
+			use_count, created_at, updated_at, amounts, memo_pattern, confidence
 		FROM check_patterns
 		ORDER BY use_count DESC, pattern_name`
 
-	rows, err := s.db.QueryContext(ctx, query)
+	rows, err := q.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query check patterns: %w", err)
 	}
@@ -154,12 +174,15 @@ func (s *SQLiteStorage) GetActiveCheckPatterns(ctx context.Context) ([]model.Che
 		var pattern model.CheckPattern
 		var checkNumberJSON sql.NullString
 		var amountsJSON sql.NullString
+		var memoPattern sql.NullString
+		var confidence sql.NullFloat64
 
 		if err := rows.Scan(
 			&pattern.ID, &pattern.PatternName, &pattern.AmountMin, &pattern.AmountMax,
 			&checkNumberJSON, &pattern.DayOfMonthMin, &pattern.DayOfMonthMax,
 			&pattern.Category, &pattern.Notes,
 			&pattern.UseCount, &pattern.CreatedAt, &pattern.UpdatedAt, &amountsJSON,
+			&memoPattern, &confidence,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan check pattern: %w", err)
 		}
@@ -179,6 +202,9 @@ func (s *SQLiteStorage) GetActiveCheckPatterns(ctx context.Context) ([]model.Che
 				return nil, fmt.Errorf("failed to unmarshal amounts: %w", err)
 			}
 		}
+		pattern.MemoPattern = memoPattern.String
+		pattern.Confidence = confidence.Float64
+		pattern.Confidence = pattern.EffectiveConfidence()
 
 		patterns = append(patterns, pattern)
 	}
@@ -203,16 +229,31 @@ func (s *SQLiteStorage) GetMatchingCheckPatterns(ctx context.Context, txn model.
 		return nil, err
 	}
 
-	// Filter patterns that match the transaction
+	matching := matchCheckPatterns(patterns, txn)
+	slog.Debug("found matching check patterns", "transaction_id", txn.ID, "count", len(matching))
+	return matching, nil
+}
+
+// matchCheckPatterns filters patterns down to those matching txn, most
+// confident first with ties kept in use-count order. A pattern whose check
+// number regex does not compile is logged and skipped.
+func matchCheckPatterns(patterns []model.CheckPattern, txn model.Transaction) []model.CheckPattern {
 	var matching []model.CheckPattern
 	for _, pattern := range patterns {
-		if pattern.Matches(txn) {
+		matched, err := pattern.Match(txn)
+		if err != nil {
+			slog.Warn("skipping check pattern", "pattern_id", pattern.ID, "pattern", pattern.PatternName, "error", err)
+			continue
+		}
+		if matched {
 			matching = append(matching, pattern)
 		}
 	}
 
-	slog.Debug("found matching check patterns", "transaction_id", txn.ID, "count", len(matching))
-	return matching, nil
+	sort.SliceStable(matching, func(i, j int) bool {
+		return matching[i].Confidence > matching[j].Confidence
+	})
+	return matching
 }
 
 // UpdateCheckPattern updates an existing check pattern.
@@ -220,6 +261,9 @@ func (s *SQLiteStorage) UpdateCheckPattern(ctx context.Context, pattern *model.C
 	if err := validateContext(ctx); err != nil {
 		return err
 	}
+	if err := s.checkWritable("update check pattern"); err != nil {
+		return err
+	}
 
 	if pattern == nil {
 		return fmt.Errorf("pattern cannot be nil")
@@ -256,13 +300,13 @@ func (s *SQLiteStorage) UpdateCheckPattern(ctx context.Context, pattern *model.C
 			pattern_name = ?, amount_min = ?, amount_max = ?, 
 			check_number_pattern = ?, day_of_month_min = ?, 
 			day_of_month_max = ?, category = ?, notes = ?,
-			amounts = ?, updated_at = CURRENT_TIMESTAMP
+			amounts = ?, memo_pattern = ?, confidence = ?, updated_at = CURRENT_TIMESTAMP
 		WHERE id = ?`
 
 	result, err := s.db.ExecContext(ctx, query,
 		pattern.PatternName, pattern.AmountMin, pattern.AmountMax, checkNumberJSON,
 		pattern.DayOfMonthMin, pattern.DayOfMonthMax, pattern.Category, pattern.Notes,
-		amountsJSON, pattern.ID,
+		amountsJSON, pattern.MemoPattern, pattern.EffectiveConfidence(), pattern.ID,
 	)
 
 	if err != nil {
@@ -287,10 +331,17 @@ func (s *SQLiteStorage) DeleteCheckPattern(ctx context.Context, id int64) error
 	if err := validateContext(ctx); err != nil {
 		return err
 	}
+	if err := s.checkWritable("delete check pattern"); err != nil {
+		return err
+	}
+	return s.deleteCheckPatternTx(ctx, s.db, id)
+}
 
+// deleteCheckPatternTx deletes a check pattern using q, which may be a transaction.
+func (s *SQLiteStorage) deleteCheckPatternTx(ctx context.Context, q queryable, id int64) error {
 	query := `DELETE FROM check_patterns WHERE id = ?`
 
-	result, err := s.db.ExecContext(ctx, query, id)
+	result, err := q.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete check pattern: %w", err)
 	}
@@ -313,6 +364,9 @@ func (s *SQLiteStorage) IncrementCheckPatternUseCount(ctx context.Context, id in
 	if err := validateContext(ctx); err != nil {
 		return err
 	}
+	if err := s.checkWritable("update check pattern use count"); err != nil {
+		return err
+	}
 
 	query := `
 		UPDATE check_patterns 
@@ -341,10 +395,10 @@ func (s *SQLiteStorage) IncrementCheckPatternUseCount(ctx context.Context, id in
 
 // CreateCheckPattern creates a new check pattern within a transaction.
 func (t *sqliteTransaction) CreateCheckPattern(ctx context.Context, pattern *model.CheckPattern) error {
-	// Reuse the main implementation with transaction's connection
-	// For now, we'll delegate to the main storage instance
-	// In a real implementation, we'd use t.tx instead of s.db
-	return t.storage.CreateCheckPattern(ctx, pattern)
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	return t.storage.createCheckPatternTx(ctx, t.tx, pattern)
 }
 
 // GetCheckPattern retrieves a check pattern by ID within a transaction.
@@ -354,7 +408,10 @@ func (t *sqliteTransaction) GetCheckPattern(ctx context.Context, id int64) (*mod
 
 // GetActiveCheckPatterns returns all active check patterns within a transaction.
 func (t *sqliteTransaction) GetActiveCheckPatterns(ctx context.Context) ([]model.CheckPattern, error) {
-	return t.storage.GetActiveCheckPatterns(ctx)
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	return t.storage.getActiveCheckPatternsTx(ctx, t.tx)
 }
 
 // GetMatchingCheckPatterns returns patterns that match the transaction within a transaction.
@@ -369,7 +426,10 @@ func (t *sqliteTransaction) UpdateCheckPattern(ctx context.Context, pattern *mod
 
 // DeleteCheckPattern deletes a check pattern within a transaction.
 func (t *sqliteTransaction) DeleteCheckPattern(ctx context.Context, id int64) error {
-	return t.storage.DeleteCheckPattern(ctx, id)
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	return t.storage.deleteCheckPatternTx(ctx, t.tx, id)
 }
 
 // IncrementCheckPatternUseCount increments use count within a transaction.
@@ -303,6 +303,272 @@ func TestCheckPatternStorage(t *testing.T) {
 			t.Errorf("CheckNumberPattern = %+v, want {Modulo:10 Offset:5}", retrieved.CheckNumberPattern)
 		}
 	})
+
+	t.Run("MemoPattern_Matching", func(t *testing.T) {
+		clearCheckPatterns(t, storage)
+
+		pattern := &model.CheckPattern{
+			PatternName: "Cleaning",
+			MemoPattern: "Jane's Cleaning",
+			Category:    "Home Services",
+		}
+		if err := storage.CreateCheckPattern(ctx, pattern); err != nil {
+			t.Fatalf("CreateCheckPattern() error = %v", err)
+		}
+
+		retrieved, err := storage.GetCheckPattern(ctx, pattern.ID)
+		if err != nil {
+			t.Fatalf("GetCheckPattern() error = %v", err)
+		}
+		if retrieved.MemoPattern != "Jane's Cleaning" {
+			t.Errorf("MemoPattern = %q, want %q", retrieved.MemoPattern, "Jane's Cleaning")
+		}
+
+		matching := model.Transaction{Type: "CHECK", Name: "CHECK #1001 - JANE'S CLEANING", Amount: 120}
+		matches, err := storage.GetMatchingCheckPatterns(ctx, matching)
+		if err != nil {
+			t.Fatalf("GetMatchingCheckPatterns() error = %v", err)
+		}
+		if len(matches) != 1 {
+			t.Errorf("GetMatchingCheckPatterns() returned %d patterns, want 1", len(matches))
+		}
+
+		other := model.Transaction{Type: "CHECK", Name: "CHECK #1002 - Lawn Care", Amount: 120}
+		matches, err = storage.GetMatchingCheckPatterns(ctx, other)
+		if err != nil {
+			t.Fatalf("GetMatchingCheckPatterns() error = %v", err)
+		}
+		if len(matches) != 0 {
+			t.Errorf("GetMatchingCheckPatterns() returned %d patterns, want 0", len(matches))
+		}
+	})
+
+	t.Run("Confidence", func(t *testing.T) {
+		clearCheckPatterns(t, storage)
+
+		broadMin, broadMax := 50.0, 500.0
+		broad := &model.CheckPattern{
+			PatternName: "Any mid-size check",
+			AmountMin:   &broadMin,
+			AmountMax:   &broadMax,
+			Category:    "Home Services",
+			Confidence:  0.7,
+		}
+		exactAmount := 120.0
+		exact := &model.CheckPattern{
+			PatternName: "Cleaning",
+			AmountMin:   &exactAmount,
+			AmountMax:   &exactAmount,
+			Category:    "Home Services",
+		}
+		for _, p := range []*model.CheckPattern{broad, exact} {
+			if err := storage.CreateCheckPattern(ctx, p); err != nil {
+				t.Fatalf("CreateCheckPattern() error = %v", err)
+			}
+		}
+
+		retrieved, err := storage.GetCheckPattern(ctx, broad.ID)
+		if err != nil {
+			t.Fatalf("GetCheckPattern() error = %v", err)
+		}
+		if retrieved.Confidence != 0.7 {
+			t.Errorf("Confidence = %v, want 0.7", retrieved.Confidence)
+		}
+
+		// Unset confidence defaults to 1.0 so existing patterns keep auto-accepting
+		retrieved, err = storage.GetCheckPattern(ctx, exact.ID)
+		if err != nil {
+			t.Fatalf("GetCheckPattern() error = %v", err)
+		}
+		if retrieved.Confidence != model.DefaultCheckPatternConfidence {
+			t.Errorf("Confidence = %v, want %v", retrieved.Confidence, model.DefaultCheckPatternConfidence)
+		}
+
+		matches, err := storage.GetMatchingCheckPatterns(ctx, model.Transaction{Type: "CHECK", Amount: 120})
+		if err != nil {
+			t.Fatalf("GetMatchingCheckPatterns() error = %v", err)
+		}
+		if len(matches) != 2 || matches[0].ID != exact.ID {
+			t.Errorf("GetMatchingCheckPatterns() should return the most confident pattern first, got %+v", matches)
+		}
+
+		broad.Confidence = 1.5
+		if err := storage.UpdateCheckPattern(ctx, broad); err == nil {
+			t.Error("UpdateCheckPattern() should reject confidence above 1")
+		}
+	})
+
+	t.Run("CheckNumberRegex_Matching", func(t *testing.T) {
+		clearCheckPatterns(t, storage)
+
+		pattern := &model.CheckPattern{
+			PatternName:        "Rent checkbook",
+			CheckNumberPattern: &model.CheckNumberMatcher{Regex: `^20\d{2}$`},
+			Category:           "Rent",
+		}
+		if err := storage.CreateCheckPattern(ctx, pattern); err != nil {
+			t.Fatalf("CreateCheckPattern() error = %v", err)
+		}
+
+		tests := []struct {
+			checkNumber string
+			want        int
+		}{
+			{"2042", 1},
+			{"1042", 0},
+			{"20420", 0},
+			{"", 1}, // Nothing to check the regex against
+		}
+		for _, tt := range tests {
+			txn := model.Transaction{Type: "CHECK", Name: "CHECK", Amount: 1800, CheckNumber: tt.checkNumber}
+			matches, err := storage.GetMatchingCheckPatterns(ctx, txn)
+			if err != nil {
+				t.Fatalf("GetMatchingCheckPatterns() error = %v", err)
+			}
+			if len(matches) != tt.want {
+				t.Errorf("check %q: GetMatchingCheckPatterns() returned %d patterns, want %d", tt.checkNumber, len(matches), tt.want)
+			}
+		}
+	})
+
+	t.Run("CheckNumberRegex_WithAmountRange", func(t *testing.T) {
+		clearCheckPatterns(t, storage)
+
+		minAmount, maxAmount := 100.0, 200.0
+		pattern := &model.CheckPattern{
+			PatternName:        "Tutoring",
+			AmountMin:          &minAmount,
+			AmountMax:          &maxAmount,
+			CheckNumberPattern: &model.CheckNumberMatcher{Regex: `^5\d+$`},
+			Category:           "Education",
+		}
+		if err := storage.CreateCheckPattern(ctx, pattern); err != nil {
+			t.Fatalf("CreateCheckPattern() error = %v", err)
+		}
+
+		tests := []struct {
+			checkNumber string
+			amount      float64
+			want        int
+		}{
+			{"501", 150, 1},
+			{"501", 250, 0},
+			{"401", 150, 0},
+		}
+		for _, tt := range tests {
+			txn := model.Transaction{Type: "CHECK", Name: "CHECK", Amount: tt.amount, CheckNumber: tt.checkNumber}
+			matches, err := storage.GetMatchingCheckPatterns(ctx, txn)
+			if err != nil {
+				t.Fatalf("GetMatchingCheckPatterns() error = %v", err)
+			}
+			if len(matches) != tt.want {
+				t.Errorf("check %q for %.2f: GetMatchingCheckPatterns() returned %d patterns, want %d",
+					tt.checkNumber, tt.amount, len(matches), tt.want)
+			}
+		}
+	})
+
+	t.Run("CheckNumberRegex_InvalidSkipped", func(t *testing.T) {
+		clearCheckPatterns(t, storage)
+
+		valid := &model.CheckPattern{PatternName: "Any check", Category: "Misc"}
+		if err := storage.CreateCheckPattern(ctx, valid); err != nil {
+			t.Fatalf("CreateCheckPattern() error = %v", err)
+		}
+
+		invalid := &model.CheckPattern{
+			PatternName:        "Broken",
+			CheckNumberPattern: &model.CheckNumberMatcher{Regex: `^(10`},
+			Category:           "Misc",
+		}
+		if err := storage.CreateCheckPattern(ctx, invalid); err == nil {
+			t.Fatal("CreateCheckPattern() should reject an invalid check number regex")
+		}
+
+		// Patterns saved before validation existed can still hold one
+		if _, err := storage.db.Exec(`INSERT INTO check_patterns (pattern_name, category, notes, check_number_pattern)
+			VALUES ('Broken', 'Misc', '', '{"regex":"^(10"}')`); err != nil {
+			t.Fatalf("failed to insert pattern: %v", err)
+		}
+
+		txn := model.Transaction{Type: "CHECK", Name: "CHECK", Amount: 50, CheckNumber: "1001"}
+		matches, err := storage.GetMatchingCheckPatterns(ctx, txn)
+		if err != nil {
+			t.Fatalf("GetMatchingCheckPatterns() error = %v", err)
+		}
+		if len(matches) != 1 || matches[0].ID != valid.ID {
+			t.Errorf("GetMatchingCheckPatterns() = %+v, want only the valid pattern", matches)
+		}
+	})
+
+	t.Run("Amounts_NullAndEmpty", func(t *testing.T) {
+		clearCheckPatterns(t, storage)
+
+		// A JSON null or empty list leaves only the range to match on
+		for _, amounts := range []string{"null", "[]"} {
+			if _, err := storage.db.Exec(`INSERT INTO check_patterns (pattern_name, category, notes, amount_min, amount_max, amounts)
+				VALUES (?, 'Misc', '', 100, 200, ?)`, "Amounts "+amounts, amounts); err != nil {
+				t.Fatalf("failed to insert pattern: %v", err)
+			}
+		}
+
+		patterns, err := storage.GetActiveCheckPatterns(ctx)
+		if err != nil {
+			t.Fatalf("GetActiveCheckPatterns() error = %v", err)
+		}
+		for _, p := range patterns {
+			if len(p.Amounts) != 0 {
+				t.Errorf("%s: Amounts = %v, want none", p.PatternName, p.Amounts)
+			}
+		}
+
+		for amount, want := range map[float64]int{150: 2, 250: 0} {
+			matches, err := storage.GetMatchingCheckPatterns(ctx, model.Transaction{Type: "CHECK", Amount: amount})
+			if err != nil {
+				t.Fatalf("GetMatchingCheckPatterns() error = %v", err)
+			}
+			if len(matches) != want {
+				t.Errorf("amount %.2f: GetMatchingCheckPatterns() returned %d patterns, want %d", amount, len(matches), want)
+			}
+		}
+	})
+
+	t.Run("Amounts_WithRange", func(t *testing.T) {
+		clearCheckPatterns(t, storage)
+
+		minAmount, maxAmount := 100.0, 200.0
+		pattern := &model.CheckPattern{
+			PatternName: "Piano lessons",
+			Amounts:     []float64{45, 90},
+			AmountMin:   &minAmount,
+			AmountMax:   &maxAmount,
+			Category:    "Education",
+		}
+		if err := storage.CreateCheckPattern(ctx, pattern); err != nil {
+			t.Fatalf("CreateCheckPattern() error = %v", err)
+		}
+
+		tests := []struct {
+			amount float64
+			want   int
+		}{
+			{45, 1},     // Listed
+			{89.999, 1}, // Listed, within a cent
+			{150, 1},    // In the range
+			{200, 1},    // Range bound
+			{60, 0},
+			{200.5, 0},
+		}
+		for _, tt := range tests {
+			matches, err := storage.GetMatchingCheckPatterns(ctx, model.Transaction{Type: "CHECK", Amount: tt.amount})
+			if err != nil {
+				t.Fatalf("GetMatchingCheckPatterns() error = %v", err)
+			}
+			if len(matches) != tt.want {
+				t.Errorf("amount %.3f: GetMatchingCheckPatterns() returned %d patterns, want %d", tt.amount, len(matches), tt.want)
+			}
+		}
+	})
 }
 
 // clearCheckPatterns deletes all check patterns for test isolation.
@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+// historyTimeFormat matches the UTC CURRENT_TIMESTAMP text stored in
+// classification_history.created_at.
+const historyTimeFormat = "2006-01-02 15:04:05"
+
+// GetCategoryChanges returns the transactions whose category changed in the
+// classification history since the given time, oldest change first. Each
+// transaction appears once, going from its category before the window (or
+// its first category in the window) to its latest one; transactions that
+// ended up back where they started are left out.
+func (s *SQLiteStorage) GetCategoryChanges(ctx context.Context, since time.Time) ([]model.CategoryChange, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	return s.getCategoryChangesTx(ctx, s.db, since)
+}
+
+func (s *SQLiteStorage) getCategoryChangesTx(ctx context.Context, q queryable, since time.Time) ([]model.CategoryChange, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT h.transaction_id, h.category, h.created_at,
+		       (SELECT p.category FROM classification_history p
+		        WHERE p.transaction_id = h.transaction_id AND p.id < h.id
+		        ORDER BY p.id DESC LIMIT 1),
+		       t.date, t.name, t.merchant_name, t.amount, t.direction
+		FROM classification_history h
+		JOIN transactions t ON t.id = h.transaction_id
+		WHERE h.created_at >= ?
+		ORDER BY h.created_at, h.id
+	`, since.UTC().Format(historyTimeFormat))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query classification history: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	changes := make(map[string]*model.CategoryChange)
+	var order []string
+	for rows.Next() {
+		var entry model.CategoryChange
+		var previous, direction sql.NullString
+		if err := rows.Scan(
+			&entry.Transaction.ID,
+			&entry.To,
+			&entry.ChangedAt,
+			&previous,
+			&entry.Transaction.Date,
+			&entry.Transaction.Name,
+			&entry.Transaction.MerchantName,
+			&entry.Transaction.Amount,
+			&direction,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan classification history: %w", err)
+		}
+
+		change, seen := changes[entry.Transaction.ID]
+		if !seen {
+			entry.From = entry.To
+			if previous.Valid {
+				entry.From = previous.String
+			}
+			entry.Transaction.Direction = model.TransactionDirection(direction.String)
+			changes[entry.Transaction.ID] = &entry
+			order = append(order, entry.Transaction.ID)
+			continue
+		}
+		if change.To != entry.To {
+			change.To = entry.To
+			change.ChangedAt = entry.ChangedAt
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read classification history: %w", err)
+	}
+
+	result := make([]model.CategoryChange, 0, len(order))
+	for _, id := range order {
+		if change := changes[id]; change.From != change.To {
+			result = append(result, *change)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].ChangedAt.Before(result[j].ChangedAt)
+	})
+
+	return result, nil
+}
+
+// SaveClassificationHistory adds classifications to the classification history
+// without changing any transaction's current classification, such as an AI
+// suggestion the user replaced in review.
+func (s *SQLiteStorage) SaveClassificationHistory(ctx context.Context, classifications []model.Classification) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := s.checkWritable("save classification history"); err != nil {
+		return err
+	}
+	return s.saveClassificationHistoryTx(ctx, s.db, classifications)
+}
+
+func (s *SQLiteStorage) saveClassificationHistoryTx(ctx context.Context, q queryable, classifications []model.Classification) error {
+	for _, classification := range classifications {
+		if _, err := q.ExecContext(ctx, `
+			INSERT INTO classification_history (
+				transaction_id, category, status, confidence
+			) VALUES (?, ?, ?, ?)
+		`,
+			classification.Transaction.ID,
+			classification.Category,
+			string(classification.Status),
+			classification.Confidence,
+		); err != nil {
+			return fmt.Errorf("failed to save classification history: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetConfidenceOutcomes returns, for every classified transaction the AI has
+// classified, the confidence and category of its latest AI classification in
+// the history together with its current category.
+func (s *SQLiteStorage) GetConfidenceOutcomes(ctx context.Context) ([]model.ConfidenceOutcome, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	return s.getConfidenceOutcomesTx(ctx, s.db)
+}
+
+func (s *SQLiteStorage) getConfidenceOutcomesTx(ctx context.Context, q queryable) ([]model.ConfidenceOutcome, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT h.transaction_id, h.category, h.confidence, c.category
+		FROM classification_history h
+		JOIN classifications c ON c.transaction_id = h.transaction_id
+		WHERE h.id = (SELECT MAX(p.id) FROM classification_history p
+		              WHERE p.transaction_id = h.transaction_id AND p.status = ?)
+		  AND c.status != ? AND c.category != ''
+		ORDER BY h.id
+	`, string(model.StatusClassifiedByAI), string(model.StatusUnclassified))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query classification history: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var outcomes []model.ConfidenceOutcome
+	for rows.Next() {
+		var outcome model.ConfidenceOutcome
+		var confidence sql.NullFloat64
+		if err := rows.Scan(&outcome.TransactionID, &outcome.AICategory, &confidence, &outcome.FinalCategory); err != nil {
+			return nil, fmt.Errorf("failed to scan classification history: %w", err)
+		}
+		outcome.Confidence = confidence.Float64
+		outcomes = append(outcomes, outcome)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read classification history: %w", err)
+	}
+
+	return outcomes, nil
+}
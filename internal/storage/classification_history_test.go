@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+func TestSQLiteStorage_GetCategoryChanges(t *testing.T) {
+	store, cleanup := createTestStorageWithCategories(t, "Food", "Shopping")
+	defer cleanup()
+	ctx := context.Background()
+
+	var transactions []model.Transaction
+	for _, id := range []string{"changed", "same", "reverted", "new"} {
+		transactions = append(transactions, model.Transaction{
+			ID: id, Hash: "hash-" + id, Date: time.Now(), Name: id, MerchantName: "Merchant " + id,
+			Amount: 10, AccountID: "acc1",
+		})
+	}
+	if err := store.SaveTransactions(ctx, transactions); err != nil {
+		t.Fatalf("SaveTransactions failed: %v", err)
+	}
+
+	classify := func(txn model.Transaction, category string) {
+		t.Helper()
+		err := store.SaveClassification(ctx, &model.Classification{
+			Transaction: txn, Category: category, Status: model.StatusClassifiedByAI, Confidence: 0.9,
+		})
+		if err != nil {
+			t.Fatalf("SaveClassification failed: %v", err)
+		}
+	}
+
+	// Classifications from before the window
+	for _, txn := range transactions[:3] {
+		classify(txn, "Food")
+	}
+	if _, err := store.db.ExecContext(ctx, "UPDATE classification_history SET created_at = '2024-01-01 00:00:00'"); err != nil {
+		t.Fatalf("failed to backdate history: %v", err)
+	}
+
+	// A bulk operation inside the window
+	classify(transactions[0], "Shopping")
+	classify(transactions[1], "Food")
+	classify(transactions[2], "Shopping")
+	classify(transactions[2], "Food")
+	classify(transactions[3], "Food")
+	classify(transactions[3], "Shopping")
+
+	changes, err := store.GetCategoryChanges(ctx, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("GetCategoryChanges failed: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %d: %+v", len(changes), changes)
+	}
+
+	got := map[string]string{}
+	for _, change := range changes {
+		got[change.Transaction.ID] = change.From + " -> " + change.To
+		if change.Transaction.MerchantName == "" || change.Transaction.Amount != 10 {
+			t.Errorf("transaction details missing: %+v", change.Transaction)
+		}
+		if change.ChangedAt.IsZero() {
+			t.Errorf("ChangedAt not set for %s", change.Transaction.ID)
+		}
+	}
+	if got["changed"] != "Food -> Shopping" {
+		t.Errorf("changed: got %q", got["changed"])
+	}
+	if got["new"] != "Food -> Shopping" {
+		t.Errorf("new: got %q", got["new"])
+	}
+
+	// Nothing has changed since now
+	later, err := store.GetCategoryChanges(ctx, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("GetCategoryChanges failed: %v", err)
+	}
+	if len(later) != 0 {
+		t.Errorf("expected no changes in the future, got %d", len(later))
+	}
+}
+
+func TestSQLiteStorage_GetConfidenceOutcomes(t *testing.T) {
+	store, cleanup := createTestStorageWithCategories(t, "Food", "Shopping")
+	defer cleanup()
+	ctx := context.Background()
+
+	var transactions []model.Transaction
+	for _, id := range []string{"kept", "corrected", "reranked", "manual"} {
+		transactions = append(transactions, model.Transaction{
+			ID: id, Hash: "hash-" + id, Date: time.Now(), Name: id, MerchantName: "Merchant " + id,
+			Amount: 10, AccountID: "acc1",
+		})
+	}
+	if err := store.SaveTransactions(ctx, transactions); err != nil {
+		t.Fatalf("SaveTransactions failed: %v", err)
+	}
+
+	classify := func(txn model.Transaction, category string, status model.ClassificationStatus, confidence float64) {
+		t.Helper()
+		err := store.SaveClassification(ctx, &model.Classification{
+			Transaction: txn, Category: category, Status: status, Confidence: confidence,
+		})
+		if err != nil {
+			t.Fatalf("SaveClassification failed: %v", err)
+		}
+	}
+
+	classify(transactions[0], "Food", model.StatusClassifiedByAI, 0.9)
+	classify(transactions[1], "Food", model.StatusClassifiedByAI, 0.8)
+	classify(transactions[1], "Shopping", model.StatusUserModified, 1.0)
+	classify(transactions[2], "Food", model.StatusClassifiedByAI, 0.5)
+	classify(transactions[2], "Shopping", model.StatusClassifiedByAI, 0.97)
+	classify(transactions[3], "Food", model.StatusUserModified, 1.0)
+
+	outcomes, err := store.GetConfidenceOutcomes(ctx)
+	if err != nil {
+		t.Fatalf("GetConfidenceOutcomes failed: %v", err)
+	}
+	if len(outcomes) != 3 {
+		t.Fatalf("expected 3 outcomes, got %d: %+v", len(outcomes), outcomes)
+	}
+
+	got := map[string]model.ConfidenceOutcome{}
+	for _, outcome := range outcomes {
+		got[outcome.TransactionID] = outcome
+	}
+	if outcome := got["kept"]; !outcome.Correct() || outcome.Confidence != 0.9 {
+		t.Errorf("kept: got %+v", outcome)
+	}
+	if outcome := got["corrected"]; outcome.Correct() || outcome.AICategory != "Food" || outcome.FinalCategory != "Shopping" || outcome.Confidence != 0.8 {
+		t.Errorf("corrected: got %+v", outcome)
+	}
+	// The latest AI classification counts
+	if outcome := got["reranked"]; !outcome.Correct() || outcome.Confidence != 0.97 {
+		t.Errorf("reranked: got %+v", outcome)
+	}
+	if _, ok := got["manual"]; ok {
+		t.Error("transactions never classified by the AI should be left out")
+	}
+}
+
+func TestSQLiteStorage_SaveClassificationHistory(t *testing.T) {
+	store, cleanup := createTestStorageWithCategories(t, "Food", "Shopping")
+	defer cleanup()
+	ctx := context.Background()
+
+	txn := model.Transaction{
+		ID: "corrected", Hash: "hash-corrected", Date: time.Now(), Name: "corrected",
+		MerchantName: "Merchant corrected", Amount: 10, AccountID: "acc1",
+	}
+	if err := store.SaveTransactions(ctx, []model.Transaction{txn}); err != nil {
+		t.Fatalf("SaveTransactions failed: %v", err)
+	}
+
+	// The suggestion the user replaced during review
+	err := store.SaveClassificationHistory(ctx, []model.Classification{{
+		Transaction: txn, Category: "Food", Status: model.StatusClassifiedByAI, Confidence: 0.7,
+	}})
+	if err != nil {
+		t.Fatalf("SaveClassificationHistory failed: %v", err)
+	}
+	err = store.SaveClassification(ctx, &model.Classification{
+		Transaction: txn, Category: "Shopping", Status: model.StatusUserModified, Confidence: 1.0,
+	})
+	if err != nil {
+		t.Fatalf("SaveClassification failed: %v", err)
+	}
+
+	current, err := store.GetClassificationsByConfidence(ctx, 1.1, false)
+	if err != nil {
+		t.Fatalf("GetClassificationsByConfidence failed: %v", err)
+	}
+	if len(current) != 1 || current[0].Category != "Shopping" {
+		t.Errorf("current classification should be the user's: %+v", current)
+	}
+
+	outcomes, err := store.GetConfidenceOutcomes(ctx)
+	if err != nil {
+		t.Fatalf("GetConfidenceOutcomes failed: %v", err)
+	}
+	if len(outcomes) != 1 {
+		t.Fatalf("expected 1 outcome, got %d: %+v", len(outcomes), outcomes)
+	}
+	if outcome := outcomes[0]; outcome.Correct() || outcome.AICategory != "Food" || outcome.Confidence != 0.7 {
+		t.Errorf("corrected: got %+v", outcome)
+	}
+}
@@ -5,17 +5,25 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/Veraticus/the-spice-must-flow/internal/model"
 )
 
+// ErrUserModified is returned when saving a classification would replace one
+// the user made or corrected, which only SetOverwriteUserModified allows.
+var ErrUserModified = errors.New("classification was set by the user")
+
 // SaveClassification saves a classification for a transaction.
 func (s *SQLiteStorage) SaveClassification(ctx context.Context, classification *model.Classification) error {
 	if err := validateContext(ctx); err != nil {
 		return err
 	}
+	if err := s.checkWritable("save classification"); err != nil {
+		return err
+	}
 	if err := validateClassification(classification); err != nil {
 		return err
 	}
@@ -33,12 +41,67 @@ func (s *SQLiteStorage) SaveClassification(ctx context.Context, classification *
 	return tx.Commit()
 }
 
+// SaveClassifications saves many classifications in a single transaction,
+// which is much faster than saving them one by one. Either all of them are
+// saved or, on the first error, none are.
+func (s *SQLiteStorage) SaveClassifications(ctx context.Context, classifications []model.Classification) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := s.checkWritable("save classifications"); err != nil {
+		return err
+	}
+	if len(classifications) == 0 {
+		return nil
+	}
+	for i := range classifications {
+		if err := validateClassification(&classifications[i]); err != nil {
+			return fmt.Errorf("classification for transaction %s: %w", classifications[i].Transaction.ID, err)
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := s.saveClassificationsTx(ctx, tx, classifications); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLiteStorage) saveClassificationsTx(ctx context.Context, tx *sql.Tx, classifications []model.Classification) error {
+	for i := range classifications {
+		if err := s.saveClassificationTx(ctx, tx, &classifications[i]); err != nil {
+			return fmt.Errorf("transaction %s: %w", classifications[i].Transaction.ID, err)
+		}
+	}
+	return nil
+}
+
 func (s *SQLiteStorage) saveClassificationTx(ctx context.Context, tx *sql.Tx, classification *model.Classification) error {
 	// Set ClassifiedAt if not set
 	if classification.ClassifiedAt.IsZero() {
 		classification.ClassifiedAt = time.Now()
 	}
 
+	// The user's own classifications stick unless overwriting them is allowed
+	if classification.Status != model.StatusUserModified && !s.overwriteUserModified {
+		var status string
+		err := tx.QueryRowContext(ctx, `
+			SELECT status FROM classifications WHERE transaction_id = ?
+		`, classification.Transaction.ID).Scan(&status)
+		switch {
+		case err == nil && model.ClassificationStatus(status) == model.StatusUserModified:
+			return fmt.Errorf("%w: transaction %s", ErrUserModified, classification.Transaction.ID)
+		case err != nil && !errors.Is(err, sql.ErrNoRows):
+			return fmt.Errorf("failed to check existing classification: %w", err)
+		}
+	}
+
 	// Validate category exists (only if status is not unclassified and category is provided)
 	if classification.Status != model.StatusUnclassified && classification.Category != "" {
 		var categoryExists bool
@@ -98,19 +161,31 @@ func (s *SQLiteStorage) saveClassificationTx(ctx context.Context, tx *sql.Tx, cl
 		return fmt.Errorf("failed to save classification history: %w", err)
 	}
 
-	// If this is a user-modified or rule-based classification with a category, create/update vendor rule
+	// If this is a user-modified classification with a category, create/update its vendor rule;
+	// a rule-based one updates the rule that matched
 	if (classification.Status == model.StatusUserModified || classification.Status == model.StatusClassifiedByRule) &&
 		classification.Transaction.MerchantName != "" && classification.Category != "" {
+		// Multi-category merchants never get a rule
+		multiCategory, err := s.isMultiCategoryVendorTx(ctx, tx, classification.Transaction.MerchantName)
+		if err != nil || multiCategory {
+			return err
+		}
+
 		// Create a transaction wrapper to use vendor methods
 		txWrapper := &sqliteTransaction{tx: tx, storage: s}
 
-		// Check if vendor exists
-		vendor, err := txWrapper.GetVendor(ctx, classification.Transaction.MerchantName)
+		// Check if vendor exists, under any spelling when matching ignores case
+		vendor, err := s.getVendorRuleTx(ctx, tx, classification.Transaction.MerchantName)
 		if err != nil && err != sql.ErrNoRows {
 			return fmt.Errorf("failed to check vendor: %w", err)
 		}
 
-		if vendor == nil {
+		switch {
+		case vendor == nil && classification.Status == model.StatusClassifiedByRule:
+			// A rule match only counts a use of its vendor; pattern rules and
+			// check patterns don't become vendor rules
+			return nil
+		case vendor == nil:
 			// Create new vendor
 			vendor = &model.Vendor{
 				Name:     classification.Transaction.MerchantName,
@@ -118,10 +193,14 @@ func (s *SQLiteStorage) saveClassificationTx(ctx context.Context, tx *sql.Tx, cl
 				Source:   model.SourceAuto,
 				UseCount: 1,
 			}
-		} else {
+		default:
 			// Update existing vendor
 			vendor.Category = classification.Category
 			vendor.UseCount++
+			// A user's choice re-verifies the rule, so it no longer counts as stale
+			if classification.Status == model.StatusUserModified {
+				vendor.LastUpdated = time.Now()
+			}
 			// Preserve existing source - don't change it
 		}
 
@@ -150,6 +229,8 @@ func (s *SQLiteStorage) getClassificationsByDateRangeTx(ctx context.Context, q q
 			t.id, t.hash, t.date, t.name, t.merchant_name,
 			t.amount, t.categories, t.account_id,
 			t.transaction_type, t.check_number,
+			t.original_amount, t.original_currency,
+			COALESCE(t.is_refund, 0), t.refund_category,
 			c.category, c.status, c.confidence, c.classified_at, c.notes,
 			c.business_percent
 		FROM classifications c
@@ -170,6 +251,9 @@ func (s *SQLiteStorage) getClassificationsByDateRangeTx(ctx context.Context, q q
 		var categories sql.NullString
 		var txType sql.NullString
 		var checkNum sql.NullString
+		var originalAmount sql.NullFloat64
+		var originalCurrency sql.NullString
+		var refundCategory sql.NullString
 
 		err := rows.Scan(
 			&c.Transaction.ID,
@@ -182,6 +266,10 @@ func (s *SQLiteStorage) getClassificationsByDateRangeTx(ctx context.Context, q q
 			&c.Transaction.AccountID,
 			&txType,
 			&checkNum,
+			&originalAmount,
+			&originalCurrency,
+			&c.Transaction.IsRefund,
+			&refundCategory,
 			&c.Category,
 			&statusStr,
 			&c.Confidence,
@@ -209,6 +297,10 @@ func (s *SQLiteStorage) getClassificationsByDateRangeTx(ctx context.Context, q q
 		if checkNum.Valid {
 			c.Transaction.CheckNumber = checkNum.String
 		}
+		setOriginalCurrency(&c.Transaction, originalAmount, originalCurrency)
+		if refundCategory.Valid {
+			c.Transaction.RefundCategory = refundCategory.String
+		}
 
 		classifications = append(classifications, c)
 	}
@@ -301,11 +393,132 @@ func (s *SQLiteStorage) GetClassificationsByConfidence(ctx context.Context, maxC
 	return classifications, rows.Err()
 }
 
+// UpdateBusinessPercentByCategory sets the business percentage on every existing
+// classification in a category and returns the number of classifications updated.
+func (s *SQLiteStorage) UpdateBusinessPercentByCategory(ctx context.Context, categoryName string, businessPercent int) (int64, error) {
+	if err := validateContext(ctx); err != nil {
+		return 0, err
+	}
+	if err := s.checkWritable("update business percent"); err != nil {
+		return 0, err
+	}
+	if err := validateString(categoryName, "categoryName"); err != nil {
+		return 0, err
+	}
+	return s.updateBusinessPercentByCategoryTx(ctx, s.db, categoryName, businessPercent)
+}
+
+func (s *SQLiteStorage) updateBusinessPercentByCategoryTx(ctx context.Context, q queryable, categoryName string, businessPercent int) (int64, error) {
+	if businessPercent < 0 || businessPercent > 100 {
+		return 0, fmt.Errorf("business percentage must be between 0 and 100")
+	}
+
+	// Only expense categories can carry a business percentage
+	var catType sql.NullString
+	err := q.QueryRowContext(ctx, `
+		SELECT type FROM categories WHERE name = ? AND is_active = 1
+	`, categoryName).Scan(&catType)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("category '%s' does not exist", categoryName)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to check category: %w", err)
+	}
+	if catType.Valid && (catType.String == string(model.CategoryTypeIncome) || catType.String == string(model.CategoryTypeSystem)) {
+		return 0, fmt.Errorf("cannot set business percentage for %s categories", catType.String)
+	}
+
+	result, err := q.ExecContext(ctx, `
+		UPDATE classifications 
+		SET business_percent = ?
+		WHERE category = ?
+	`, businessPercent, categoryName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update business percent: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// UpdateBusinessPercentByVendor sets the business percentage on every existing
+// expense classification for a merchant and returns the number updated.
+// Transactions without a merchant name are matched on their raw name.
+func (s *SQLiteStorage) UpdateBusinessPercentByVendor(ctx context.Context, merchantName string, businessPercent int) (int64, error) {
+	if err := validateContext(ctx); err != nil {
+		return 0, err
+	}
+	if err := s.checkWritable("update business percent"); err != nil {
+		return 0, err
+	}
+	if err := validateString(merchantName, "merchantName"); err != nil {
+		return 0, err
+	}
+	return s.updateBusinessPercentByVendorTx(ctx, s.db, merchantName, businessPercent)
+}
+
+func (s *SQLiteStorage) updateBusinessPercentByVendorTx(ctx context.Context, q queryable, merchantName string, businessPercent int) (int64, error) {
+	if businessPercent < 0 || businessPercent > 100 {
+		return 0, fmt.Errorf("business percentage must be between 0 and 100")
+	}
+
+	// Income and system classifications never carry a business percentage
+	result, err := q.ExecContext(ctx, `
+		UPDATE classifications 
+		SET business_percent = ?
+		WHERE transaction_id IN (
+			SELECT id FROM transactions
+			WHERE merchant_name = ?
+			   OR (COALESCE(merchant_name, '') = '' AND name = ?)
+		)
+		AND category NOT IN (
+			SELECT name FROM categories WHERE type IN (?, ?)
+		)
+	`, businessPercent, merchantName, merchantName,
+		string(model.CategoryTypeIncome), string(model.CategoryTypeSystem))
+	if err != nil {
+		return 0, fmt.Errorf("failed to update business percent: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// HasClassificationHistory reports whether any transaction from the merchant has
+// been classified before. Transactions without a merchant name are matched on their raw name.
+func (s *SQLiteStorage) HasClassificationHistory(ctx context.Context, merchantName string) (bool, error) {
+	if err := validateContext(ctx); err != nil {
+		return false, err
+	}
+	if err := validateString(merchantName, "merchantName"); err != nil {
+		return false, err
+	}
+	return s.hasClassificationHistoryTx(ctx, s.db, merchantName)
+}
+
+func (s *SQLiteStorage) hasClassificationHistoryTx(ctx context.Context, q queryable, merchantName string) (bool, error) {
+	var exists bool
+	err := q.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM classifications c
+			JOIN transactions t ON c.transaction_id = t.id
+			WHERE t.merchant_name = ? COLLATE NOCASE
+			   OR (COALESCE(t.merchant_name, '') = '' AND t.name = ? COLLATE NOCASE)
+		)
+	`, merchantName, merchantName).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check classification history: %w", err)
+	}
+
+	return exists, nil
+}
+
 // ClearAllClassifications deletes all classification records.
 func (s *SQLiteStorage) ClearAllClassifications(ctx context.Context) error {
 	if err := validateContext(ctx); err != nil {
 		return err
 	}
+	if err := s.checkWritable("clear classifications"); err != nil {
+		return err
+	}
 
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
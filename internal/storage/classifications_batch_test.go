@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+// batchTestClassifications saves count transactions and returns a
+// classification of each into category.
+func batchTestClassifications(ctx context.Context, store *SQLiteStorage, count int, category string) ([]model.Classification, error) {
+	transactions := make([]model.Transaction, count)
+	for i := range transactions {
+		transactions[i] = model.Transaction{
+			ID:           fmt.Sprintf("batch-%d", i),
+			Date:         time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(i) * time.Hour),
+			Name:         fmt.Sprintf("MERCHANT %d", i%20),
+			MerchantName: fmt.Sprintf("Merchant %d", i%20),
+			Amount:       float64(i%100) + 0.99,
+			AccountID:    "acc1",
+		}
+		transactions[i].Hash = transactions[i].GenerateHash()
+	}
+	if err := store.SaveTransactions(ctx, transactions); err != nil {
+		return nil, err
+	}
+
+	classifications := make([]model.Classification, count)
+	for i, txn := range transactions {
+		classifications[i] = model.Classification{
+			Transaction: txn,
+			Category:    category,
+			Status:      model.StatusClassifiedByAI,
+			Confidence:  0.97,
+		}
+	}
+	return classifications, nil
+}
+
+func TestSQLiteStorage_SaveClassifications(t *testing.T) {
+	store, cleanup := createTestStorageWithCategories(t, "Groceries")
+	defer cleanup()
+	ctx := context.Background()
+
+	classifications, err := batchTestClassifications(ctx, store, 50, "Groceries")
+	if err != nil {
+		t.Fatalf("Failed to create classifications: %v", err)
+	}
+
+	if err := store.SaveClassifications(ctx, classifications[:40]); err != nil {
+		t.Fatalf("SaveClassifications failed: %v", err)
+	}
+	if classifications[0].ClassifiedAt.IsZero() {
+		t.Error("ClassifiedAt should be set on the saved classifications")
+	}
+	remaining, err := store.GetTransactionsToClassify(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to get unclassified transactions: %v", err)
+	}
+	if len(remaining) != 10 {
+		t.Errorf("Expected 10 unclassified transactions, got %d", len(remaining))
+	}
+
+	// One bad classification rolls back the whole batch
+	batch := append([]model.Classification(nil), classifications[40:]...)
+	batch[5].Category = "Missing"
+	if err := store.SaveClassifications(ctx, batch); err == nil {
+		t.Fatal("Expected an error for a category that doesn't exist")
+	}
+	remaining, err = store.GetTransactionsToClassify(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to get unclassified transactions: %v", err)
+	}
+	if len(remaining) != 10 {
+		t.Errorf("Expected the failed batch to save nothing, got %d unclassified", len(remaining))
+	}
+
+	if err := store.SaveClassifications(ctx, nil); err != nil {
+		t.Errorf("Saving no classifications should succeed: %v", err)
+	}
+}
+
+func BenchmarkSQLiteStorage_SaveClassifications(b *testing.B) {
+	const count = 1000
+	ctx := context.Background()
+
+	setup := func(b *testing.B) (*SQLiteStorage, []model.Classification) {
+		b.Helper()
+		store, err := NewSQLiteStorage(filepath.Join(b.TempDir(), "bench.db"))
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Cleanup(func() { _ = store.Close() })
+		if err := store.Migrate(ctx); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := store.CreateCategory(ctx, "Groceries", ""); err != nil {
+			b.Fatal(err)
+		}
+		classifications, err := batchTestClassifications(ctx, store, count, "Groceries")
+		if err != nil {
+			b.Fatal(err)
+		}
+		return store, classifications
+	}
+
+	b.Run("per-row", func(b *testing.B) {
+		store, classifications := setup(b)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			for j := range classifications {
+				if err := store.SaveClassification(ctx, &classifications[j]); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("batched", func(b *testing.B) {
+		store, classifications := setup(b)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := store.SaveClassifications(ctx, classifications); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
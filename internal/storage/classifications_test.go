@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -652,3 +653,279 @@ func TestSQLiteStorage_ClassificationAmountAggregation(t *testing.T) {
 		}
 	}
 }
+
+func TestSQLiteStorage_ForeignCurrencyRoundTrip(t *testing.T) {
+	store, cleanup := createTestStorageWithCategories(t, "Travel")
+	defer cleanup()
+	ctx := context.Background()
+
+	transactions := []model.Transaction{
+		{
+			ID:               "fx-1",
+			Date:             time.Now(),
+			Name:             "HOTEL LYON",
+			MerchantName:     "Hotel Lyon",
+			Amount:           130.00,
+			OriginalAmount:   120.00,
+			OriginalCurrency: "EUR",
+			AccountID:        "acc1",
+		},
+		{
+			ID:           "home-1",
+			Date:         time.Now(),
+			Name:         "TAXI",
+			MerchantName: "Taxi",
+			Amount:       20.00,
+			AccountID:    "acc1",
+		},
+	}
+	for i := range transactions {
+		transactions[i].Hash = transactions[i].GenerateHash()
+	}
+	if err := store.SaveTransactions(ctx, transactions); err != nil {
+		t.Fatalf("Failed to save transactions: %v", err)
+	}
+
+	// Original currency is returned for single-transaction lookups
+	foreign, err := store.GetTransactionByID(ctx, "fx-1")
+	if err != nil {
+		t.Fatalf("Failed to get transaction: %v", err)
+	}
+	if foreign.OriginalCurrency != "EUR" || foreign.OriginalAmount != 120.00 {
+		t.Errorf("Expected original 120.00 EUR, got %.2f %q", foreign.OriginalAmount, foreign.OriginalCurrency)
+	}
+
+	for _, txn := range transactions {
+		classification := &model.Classification{
+			Transaction: txn,
+			Category:    "Travel",
+			Status:      model.StatusUserModified,
+			Confidence:  1.0,
+		}
+		if err := store.SaveClassification(ctx, classification); err != nil {
+			t.Fatalf("Failed to save classification: %v", err)
+		}
+	}
+
+	results, err := store.GetClassificationsByDateRange(ctx, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to get classifications: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 classifications, got %d", len(results))
+	}
+
+	for _, r := range results {
+		switch r.Transaction.ID {
+		case "fx-1":
+			if !r.Transaction.IsForeignCurrency() || r.Transaction.OriginalCurrency != "EUR" || r.Transaction.OriginalAmount != 120.00 {
+				t.Errorf("Expected original 120.00 EUR, got %.2f %q", r.Transaction.OriginalAmount, r.Transaction.OriginalCurrency)
+			}
+		case "home-1":
+			// Home-currency transactions are unaffected
+			if r.Transaction.IsForeignCurrency() {
+				t.Errorf("Expected home-currency transaction, got %.2f %q", r.Transaction.OriginalAmount, r.Transaction.OriginalCurrency)
+			}
+		}
+	}
+}
+
+func TestSQLiteStorage_UpdateBusinessPercent(t *testing.T) {
+	store, cleanup := createTestStorageWithCategories(t, "Office Supplies", "Phone")
+	defer cleanup()
+	ctx := context.Background()
+
+	if _, err := store.CreateCategoryWithType(ctx, "Refunds", "Money back", model.CategoryTypeIncome); err != nil {
+		t.Fatalf("Failed to create income category: %v", err)
+	}
+
+	transactions := []model.Transaction{
+		{ID: "office-1", Date: time.Now(), Name: "STAPLES", MerchantName: "Staples", Amount: 40, AccountID: "acc1"},
+		{ID: "office-2", Date: time.Now(), Name: "OFFICE DEPOT", MerchantName: "Office Depot", Amount: 60, AccountID: "acc1"},
+		{ID: "phone-1", Date: time.Now(), Name: "VERIZON", MerchantName: "Verizon", Amount: 80, AccountID: "acc1"},
+		{ID: "phone-2", Date: time.Now().Add(-time.Hour), Name: "Verizon", Amount: 80, AccountID: "acc1"},
+		{ID: "refund-1", Date: time.Now(), Name: "VERIZON CREDIT", MerchantName: "Verizon", Amount: 10, AccountID: "acc1"},
+	}
+	for i := range transactions {
+		transactions[i].Hash = transactions[i].GenerateHash()
+	}
+	if err := store.SaveTransactions(ctx, transactions); err != nil {
+		t.Fatalf("Failed to save transactions: %v", err)
+	}
+
+	categoriesByID := map[string]string{
+		"office-1": "Office Supplies",
+		"office-2": "Office Supplies",
+		"phone-1":  "Phone",
+		"phone-2":  "Phone",
+		"refund-1": "Refunds",
+	}
+	for _, txn := range transactions {
+		classification := &model.Classification{
+			Transaction: txn,
+			Category:    categoriesByID[txn.ID],
+			Status:      model.StatusUserModified,
+			Confidence:  1.0,
+		}
+		if err := store.SaveClassification(ctx, classification); err != nil {
+			t.Fatalf("Failed to save classification: %v", err)
+		}
+	}
+
+	updated, err := store.UpdateBusinessPercentByCategory(ctx, "Office Supplies", 100)
+	if err != nil {
+		t.Fatalf("UpdateBusinessPercentByCategory() error = %v", err)
+	}
+	if updated != 2 {
+		t.Errorf("UpdateBusinessPercentByCategory() updated %d, want 2", updated)
+	}
+
+	// Matches merchant name and raw name, but skips the income classification
+	updated, err = store.UpdateBusinessPercentByVendor(ctx, "Verizon", 50)
+	if err != nil {
+		t.Fatalf("UpdateBusinessPercentByVendor() error = %v", err)
+	}
+	if updated != 2 {
+		t.Errorf("UpdateBusinessPercentByVendor() updated %d, want 2", updated)
+	}
+
+	if _, err := store.UpdateBusinessPercentByCategory(ctx, "Refunds", 50); err == nil {
+		t.Error("Expected error setting business percent on an income category")
+	}
+	if _, err := store.UpdateBusinessPercentByCategory(ctx, "Office Supplies", 101); err == nil {
+		t.Error("Expected error for business percent above 100")
+	}
+	if _, err := store.UpdateBusinessPercentByCategory(ctx, "Missing", 50); err == nil {
+		t.Error("Expected error for unknown category")
+	}
+
+	results, err := store.GetClassificationsByDateRange(ctx, time.Now().Add(-2*time.Hour), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to get classifications: %v", err)
+	}
+
+	want := map[string]float64{"office-1": 100, "office-2": 100, "phone-1": 50, "phone-2": 50, "refund-1": 0}
+	for _, r := range results {
+		if r.BusinessPercent != want[r.Transaction.ID] {
+			t.Errorf("%s: BusinessPercent = %v, want %v", r.Transaction.ID, r.BusinessPercent, want[r.Transaction.ID])
+		}
+	}
+}
+
+func TestSQLiteStorage_HasClassificationHistory(t *testing.T) {
+	store, cleanup := createTestStorageWithCategories(t, "Groceries")
+	defer cleanup()
+	ctx := context.Background()
+
+	transactions := []model.Transaction{
+		{ID: "classified", Date: time.Now(), Name: "WHOLE FOODS #12", MerchantName: "Whole Foods", Amount: 40, AccountID: "acc1"},
+		{ID: "unclassified", Date: time.Now(), Name: "TRADER JOES", MerchantName: "Trader Joes", Amount: 25, AccountID: "acc1"},
+		{ID: "no-merchant", Date: time.Now(), Name: "FARMERS MARKET", Amount: 15, AccountID: "acc1"},
+	}
+	for i := range transactions {
+		transactions[i].Hash = transactions[i].GenerateHash()
+	}
+	if err := store.SaveTransactions(ctx, transactions); err != nil {
+		t.Fatalf("Failed to save transactions: %v", err)
+	}
+	for _, txn := range []model.Transaction{transactions[0], transactions[2]} {
+		if err := store.SaveClassification(ctx, &model.Classification{
+			Transaction:  txn,
+			Category:     "Groceries",
+			Status:       model.StatusUserModified,
+			Confidence:   1.0,
+			ClassifiedAt: time.Now(),
+		}); err != nil {
+			t.Fatalf("Failed to save classification: %v", err)
+		}
+	}
+
+	tests := []struct {
+		merchant string
+		want     bool
+	}{
+		{merchant: "Whole Foods", want: true},
+		{merchant: "whole foods", want: true},
+		{merchant: "Trader Joes", want: false},
+		{merchant: "FARMERS MARKET", want: true},
+		{merchant: "Never Seen", want: false},
+	}
+
+	for _, tt := range tests {
+		got, err := store.HasClassificationHistory(ctx, tt.merchant)
+		if err != nil {
+			t.Fatalf("HasClassificationHistory(%q) failed: %v", tt.merchant, err)
+		}
+		if got != tt.want {
+			t.Errorf("HasClassificationHistory(%q) = %v, want %v", tt.merchant, got, tt.want)
+		}
+	}
+}
+
+func TestSQLiteStorage_UserModifiedGuard(t *testing.T) {
+	store, cleanup := createTestStorageWithCategories(t, "Groceries", "Dining")
+	defer cleanup()
+	ctx := context.Background()
+
+	transactions := []model.Transaction{
+		{ID: "verified", Date: time.Now(), Name: "WHOLE FOODS #12", MerchantName: "Whole Foods", Amount: 40, AccountID: "acc1"},
+		{ID: "guessed", Date: time.Now(), Name: "TRADER JOES", MerchantName: "Trader Joes", Amount: 25, AccountID: "acc1"},
+	}
+	for i := range transactions {
+		transactions[i].Hash = transactions[i].GenerateHash()
+	}
+	if err := store.SaveTransactions(ctx, transactions); err != nil {
+		t.Fatalf("Failed to save transactions: %v", err)
+	}
+	if err := store.SaveClassification(ctx, &model.Classification{
+		Transaction: transactions[0], Category: "Groceries", Status: model.StatusUserModified, Confidence: 1.0,
+	}); err != nil {
+		t.Fatalf("Failed to save classification: %v", err)
+	}
+
+	category := func(id string) string {
+		t.Helper()
+		classifications, err := store.GetClassificationsByDateRange(ctx, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatalf("Failed to get classifications: %v", err)
+		}
+		for _, c := range classifications {
+			if c.Transaction.ID == id {
+				return c.Category
+			}
+		}
+		return ""
+	}
+	aiGuess := func(txn model.Transaction) model.Classification {
+		return model.Classification{Transaction: txn, Category: "Dining", Status: model.StatusClassifiedByAI, Confidence: 0.95}
+	}
+
+	guess := aiGuess(transactions[0])
+	if err := store.SaveClassification(ctx, &guess); !errors.Is(err, ErrUserModified) {
+		t.Fatalf("SaveClassification() over the user's classification = %v, want ErrUserModified", err)
+	}
+
+	// A batch containing a protected row is rolled back as a whole
+	batch := []model.Classification{aiGuess(transactions[1]), aiGuess(transactions[0])}
+	if err := store.SaveClassifications(ctx, batch); !errors.Is(err, ErrUserModified) {
+		t.Fatalf("SaveClassifications() = %v, want ErrUserModified", err)
+	}
+	if got := category("guessed"); got != "" {
+		t.Errorf("guessed was saved as %q from a failed batch", got)
+	}
+
+	// The user can still change their own classification
+	corrected := model.Classification{Transaction: transactions[0], Category: "Dining", Status: model.StatusUserModified, Confidence: 1.0}
+	if err := store.SaveClassification(ctx, &corrected); err != nil {
+		t.Fatalf("Failed to save the user's correction: %v", err)
+	}
+
+	store.SetOverwriteUserModified(true)
+	guess.Category = "Groceries"
+	if err := store.SaveClassification(ctx, &guess); err != nil {
+		t.Fatalf("SaveClassification() with overwriting allowed failed: %v", err)
+	}
+	if got := category("verified"); got != "Groceries" {
+		t.Errorf("verified = %q, want Groceries", got)
+	}
+}
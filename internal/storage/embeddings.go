@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+// merchantCategoriesCTE counts the classified transactions of every merchant
+// per category. A transaction without a merchant name is keyed by its name,
+// the same way the classification engine groups them.
+const merchantCategoriesCTE = `
+	WITH merchant_categories AS (
+		SELECT
+			CASE WHEN COALESCE(t.merchant_name, '') = '' THEN t.name ELSE t.merchant_name END AS merchant,
+			c.category AS category,
+			COUNT(*) AS uses
+		FROM transactions t
+		JOIN classifications c ON c.transaction_id = t.id
+		WHERE c.category != ''
+		GROUP BY merchant COLLATE NOCASE, c.category
+	)`
+
+// SaveMerchantEmbeddings creates or replaces merchant embeddings. Merchant
+// names are matched case-insensitively within an embedding model.
+func (s *SQLiteStorage) SaveMerchantEmbeddings(ctx context.Context, embeddings []model.MerchantEmbedding) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := s.checkWritable("save merchant embeddings"); err != nil {
+		return err
+	}
+	if len(embeddings) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := s.saveMerchantEmbeddingsTx(ctx, tx, embeddings); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit merchant embeddings: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) saveMerchantEmbeddingsTx(ctx context.Context, q queryable, embeddings []model.MerchantEmbedding) error {
+	for _, embedding := range embeddings {
+		if err := validateString(embedding.MerchantName, "merchantName"); err != nil {
+			return err
+		}
+		if err := validateString(embedding.Model, "model"); err != nil {
+			return err
+		}
+		if len(embedding.Vector) == 0 {
+			return fmt.Errorf("%w: vector", ErrNilParameter)
+		}
+
+		_, err := q.ExecContext(ctx, `
+			INSERT INTO embeddings (merchant_name, model, vector, created_at)
+			VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT(merchant_name, model) DO UPDATE SET
+				vector = excluded.vector,
+				created_at = excluded.created_at
+		`, embedding.MerchantName, embedding.Model, encodeVector(embedding.Vector))
+		if err != nil {
+			return fmt.Errorf("failed to save embedding for %q: %w", embedding.MerchantName, err)
+		}
+	}
+	return nil
+}
+
+// GetMerchantEmbeddings returns every merchant embedding made with a model,
+// each with the merchant's most common category. Merchants that were never
+// classified have an empty category.
+func (s *SQLiteStorage) GetMerchantEmbeddings(ctx context.Context, embeddingModel string) ([]model.MerchantEmbedding, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	return s.getMerchantEmbeddingsTx(ctx, s.db, embeddingModel)
+}
+
+func (s *SQLiteStorage) getMerchantEmbeddingsTx(ctx context.Context, q queryable, embeddingModel string) ([]model.MerchantEmbedding, error) {
+	if err := validateString(embeddingModel, "model"); err != nil {
+		return nil, err
+	}
+
+	rows, err := q.QueryContext(ctx, merchantCategoriesCTE+`
+		SELECT e.merchant_name, e.model, e.vector,
+			COALESCE((
+				SELECT mc.category FROM merchant_categories mc
+				WHERE mc.merchant = e.merchant_name COLLATE NOCASE
+				ORDER BY mc.uses DESC, mc.category
+				LIMIT 1
+			), '')
+		FROM embeddings e
+		WHERE e.model = ?
+		ORDER BY e.merchant_name
+	`, embeddingModel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query merchant embeddings: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var embeddings []model.MerchantEmbedding
+	for rows.Next() {
+		var embedding model.MerchantEmbedding
+		var vector []byte
+		if err := rows.Scan(&embedding.MerchantName, &embedding.Model, &vector, &embedding.Category); err != nil {
+			return nil, fmt.Errorf("failed to scan merchant embedding: %w", err)
+		}
+		if embedding.Vector, err = decodeVector(vector); err != nil {
+			return nil, fmt.Errorf("invalid embedding for %q: %w", embedding.MerchantName, err)
+		}
+		embeddings = append(embeddings, embedding)
+	}
+
+	return embeddings, rows.Err()
+}
+
+// GetMerchantsWithoutEmbedding returns the classified merchants that have no
+// embedding made with a model, ordered by name.
+func (s *SQLiteStorage) GetMerchantsWithoutEmbedding(ctx context.Context, embeddingModel string) ([]string, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	return s.getMerchantsWithoutEmbeddingTx(ctx, s.db, embeddingModel)
+}
+
+func (s *SQLiteStorage) getMerchantsWithoutEmbeddingTx(ctx context.Context, q queryable, embeddingModel string) ([]string, error) {
+	if err := validateString(embeddingModel, "model"); err != nil {
+		return nil, err
+	}
+
+	rows, err := q.QueryContext(ctx, merchantCategoriesCTE+`
+		SELECT MIN(mc.merchant) AS merchant
+		FROM merchant_categories mc
+		WHERE NOT EXISTS (
+			SELECT 1 FROM embeddings e
+			WHERE e.merchant_name = mc.merchant COLLATE NOCASE AND e.model = ?
+		)
+		GROUP BY mc.merchant COLLATE NOCASE
+		ORDER BY merchant
+	`, embeddingModel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query merchants without embedding: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var merchants []string
+	for rows.Next() {
+		var merchant string
+		if err := rows.Scan(&merchant); err != nil {
+			return nil, fmt.Errorf("failed to scan merchant: %w", err)
+		}
+		merchants = append(merchants, merchant)
+	}
+
+	return merchants, rows.Err()
+}
+
+// encodeVector stores a vector as little-endian float32s.
+func encodeVector(vector []float32) []byte {
+	buf := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+	}
+	return buf
+}
+
+func decodeVector(buf []byte) ([]float32, error) {
+	if len(buf)%4 != 0 {
+		return nil, fmt.Errorf("vector length %d is not a multiple of 4", len(buf))
+	}
+	vector := make([]float32, len(buf)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return vector, nil
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+func TestSQLiteStorage_MerchantEmbeddings(t *testing.T) {
+	store, cleanup := createTestStorageWithCategories(t, "Coffee", "Groceries")
+	defer cleanup()
+	ctx := context.Background()
+
+	// Starbucks is mostly Coffee; the nameless transaction is keyed by its name
+	transactions := []model.Transaction{
+		{ID: "t1", Hash: "h1", Date: time.Now(), Name: "SBUX 1", MerchantName: "Starbucks", Amount: 5, AccountID: "acc1"},
+		{ID: "t2", Hash: "h2", Date: time.Now(), Name: "SBUX 2", MerchantName: "Starbucks", Amount: 6, AccountID: "acc1"},
+		{ID: "t3", Hash: "h3", Date: time.Now(), Name: "SBUX 3", MerchantName: "STARBUCKS", Amount: 30, AccountID: "acc1"},
+		{ID: "t4", Hash: "h4", Date: time.Now(), Name: "Corner Market", Amount: 12, AccountID: "acc1"},
+		{ID: "t5", Hash: "h5", Date: time.Now(), Name: "Unclassified", MerchantName: "Nowhere", Amount: 1, AccountID: "acc1"},
+	}
+	if err := store.SaveTransactions(ctx, transactions); err != nil {
+		t.Fatalf("SaveTransactions failed: %v", err)
+	}
+	for i, category := range []string{"Coffee", "Coffee", "Groceries", "Groceries"} {
+		err := store.SaveClassification(ctx, &model.Classification{
+			Transaction: transactions[i], Category: category, Status: model.StatusUserModified, Confidence: 1,
+		})
+		if err != nil {
+			t.Fatalf("SaveClassification failed: %v", err)
+		}
+	}
+
+	missing, err := store.GetMerchantsWithoutEmbedding(ctx, "m1")
+	if err != nil {
+		t.Fatalf("GetMerchantsWithoutEmbedding failed: %v", err)
+	}
+	if !reflect.DeepEqual(missing, []string{"Corner Market", "STARBUCKS"}) {
+		t.Errorf("Expected the two classified merchants, got %v", missing)
+	}
+
+	err = store.SaveMerchantEmbeddings(ctx, []model.MerchantEmbedding{
+		{MerchantName: "Starbucks", Model: "m1", Vector: []float32{0.5, -1.25}},
+		{MerchantName: "Nowhere", Model: "m1", Vector: []float32{1}},
+		{MerchantName: "Corner Market", Model: "m2", Vector: []float32{1}},
+	})
+	if err != nil {
+		t.Fatalf("SaveMerchantEmbeddings failed: %v", err)
+	}
+	// Saving under another case replaces the vector
+	if err := store.SaveMerchantEmbeddings(ctx, []model.MerchantEmbedding{{MerchantName: "starbucks", Model: "m1", Vector: []float32{3, 4}}}); err != nil {
+		t.Fatalf("SaveMerchantEmbeddings failed: %v", err)
+	}
+
+	embeddings, err := store.GetMerchantEmbeddings(ctx, "m1")
+	if err != nil {
+		t.Fatalf("GetMerchantEmbeddings failed: %v", err)
+	}
+	if len(embeddings) != 2 {
+		t.Fatalf("Expected 2 m1 embeddings, got %+v", embeddings)
+	}
+	if embeddings[0].MerchantName != "Nowhere" || embeddings[0].Category != "" {
+		t.Errorf("Expected unclassified Nowhere first, got %+v", embeddings[0])
+	}
+	if embeddings[1].Category != "Coffee" || !reflect.DeepEqual(embeddings[1].Vector, []float32{3, 4}) {
+		t.Errorf("Expected Starbucks as Coffee with the replaced vector, got %+v", embeddings[1])
+	}
+
+	missing, err = store.GetMerchantsWithoutEmbedding(ctx, "m1")
+	if err != nil {
+		t.Fatalf("GetMerchantsWithoutEmbedding failed: %v", err)
+	}
+	if !reflect.DeepEqual(missing, []string{"Corner Market"}) {
+		t.Errorf("Expected only Corner Market to be missing, got %v", missing)
+	}
+
+	if err := store.SaveMerchantEmbeddings(ctx, []model.MerchantEmbedding{{MerchantName: "Empty", Model: "m1"}}); err == nil {
+		t.Error("Expected an error for an empty vector")
+	}
+}
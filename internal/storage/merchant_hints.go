@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/common"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+// SaveMerchantHint creates or replaces the LLM hint for a merchant.
+// Merchant names are matched case-insensitively.
+func (s *SQLiteStorage) SaveMerchantHint(ctx context.Context, hint *model.MerchantHint) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := s.checkWritable("save merchant hint"); err != nil {
+		return err
+	}
+	return s.saveMerchantHintTx(ctx, s.db, hint)
+}
+
+func (s *SQLiteStorage) saveMerchantHintTx(ctx context.Context, q queryable, hint *model.MerchantHint) error {
+	if hint == nil {
+		return fmt.Errorf("%w: hint", ErrNilParameter)
+	}
+	if err := validateString(hint.MerchantName, "merchantName"); err != nil {
+		return err
+	}
+	if err := validateString(hint.Hint, "hint"); err != nil {
+		return err
+	}
+	if hint.UpdatedAt.IsZero() {
+		hint.UpdatedAt = time.Now()
+	}
+
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO merchant_hints (merchant_name, hint, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(merchant_name) DO UPDATE SET
+			hint = excluded.hint,
+			updated_at = excluded.updated_at
+	`, hint.MerchantName, hint.Hint, hint.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save merchant hint: %w", err)
+	}
+	return nil
+}
+
+// GetMerchantHints returns every merchant hint, ordered by merchant name.
+func (s *SQLiteStorage) GetMerchantHints(ctx context.Context) ([]model.MerchantHint, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	return s.getMerchantHintsTx(ctx, s.db)
+}
+
+func (s *SQLiteStorage) getMerchantHintsTx(ctx context.Context, q queryable) ([]model.MerchantHint, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT merchant_name, hint, updated_at
+		FROM merchant_hints
+		ORDER BY merchant_name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query merchant hints: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var hints []model.MerchantHint
+	for rows.Next() {
+		var hint model.MerchantHint
+		if err := rows.Scan(&hint.MerchantName, &hint.Hint, &hint.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan merchant hint: %w", err)
+		}
+		hints = append(hints, hint)
+	}
+
+	return hints, rows.Err()
+}
+
+// DeleteMerchantHint removes the hint for a merchant.
+func (s *SQLiteStorage) DeleteMerchantHint(ctx context.Context, merchantName string) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := s.checkWritable("delete merchant hint"); err != nil {
+		return err
+	}
+	return s.deleteMerchantHintTx(ctx, s.db, merchantName)
+}
+
+func (s *SQLiteStorage) deleteMerchantHintTx(ctx context.Context, q queryable, merchantName string) error {
+	if err := validateString(merchantName, "merchantName"); err != nil {
+		return err
+	}
+
+	result, err := q.ExecContext(ctx, `DELETE FROM merchant_hints WHERE merchant_name = ?`, merchantName)
+	if err != nil {
+		return fmt.Errorf("failed to delete merchant hint: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return common.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/common"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+func TestSQLiteStorage_MerchantHints(t *testing.T) {
+	store, cleanup := createTestStorageWithCategories(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	if err := store.SaveMerchantHint(ctx, &model.MerchantHint{MerchantName: "ACME LLC", Hint: "My web hosting provider"}); err != nil {
+		t.Fatalf("Failed to save hint: %v", err)
+	}
+	if err := store.SaveMerchantHint(ctx, &model.MerchantHint{MerchantName: "Bakery", Hint: "Catering for client events"}); err != nil {
+		t.Fatalf("Failed to save hint: %v", err)
+	}
+
+	// Saving again under a different case replaces the hint
+	if err := store.SaveMerchantHint(ctx, &model.MerchantHint{MerchantName: "acme llc", Hint: "Web hosting, always business"}); err != nil {
+		t.Fatalf("Failed to replace hint: %v", err)
+	}
+
+	hints, err := store.GetMerchantHints(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get hints: %v", err)
+	}
+	if len(hints) != 2 {
+		t.Fatalf("Expected 2 hints, got %d: %+v", len(hints), hints)
+	}
+	if hints[0].MerchantName != "ACME LLC" || hints[0].Hint != "Web hosting, always business" {
+		t.Errorf("Unexpected first hint: %+v", hints[0])
+	}
+	if hints[0].UpdatedAt.IsZero() {
+		t.Error("Expected UpdatedAt to be set")
+	}
+
+	if err := store.SaveMerchantHint(ctx, &model.MerchantHint{MerchantName: "Empty", Hint: " "}); !errors.Is(err, ErrEmptyString) {
+		t.Errorf("Expected ErrEmptyString for blank hint, got %v", err)
+	}
+
+	if err := store.DeleteMerchantHint(ctx, "Acme LLC"); err != nil {
+		t.Fatalf("Failed to delete hint: %v", err)
+	}
+	if err := store.DeleteMerchantHint(ctx, "ACME LLC"); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound deleting a missing hint, got %v", err)
+	}
+
+	hints, err = store.GetMerchantHints(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get hints: %v", err)
+	}
+	if len(hints) != 1 || hints[0].MerchantName != "Bakery" {
+		t.Errorf("Expected only the Bakery hint to remain, got %+v", hints)
+	}
+}
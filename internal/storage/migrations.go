@@ -10,11 +10,13 @@ import (
 
 // ExpectedSchemaVersion is the latest schema version that the application expects.
 // If the database cannot be migrated to this version, it's a fatal error.
-const ExpectedSchemaVersion = 22
+const ExpectedSchemaVersion = 32
 
-// Migration represents a database schema migration.
+// Migration represents a database schema migration. Down undoes Up for
+// MigrateTo; a migration without one can't be rolled back.
 type Migration struct {
 	Up          func(*sql.Tx) error
+	Down        func(*sql.Tx) error
 	Description string
 	Version     int
 }
@@ -939,6 +941,210 @@ var migrations = []Migration{
 			return nil
 		},
 	},
+	{
+		Version:     23,
+		Description: "Add original amount and currency for foreign transactions",
+		Up: func(tx *sql.Tx) error {
+			// Both columns stay NULL for home-currency transactions
+			if _, err := tx.Exec(`
+				ALTER TABLE transactions 
+				ADD COLUMN original_amount REAL
+			`); err != nil {
+				return fmt.Errorf("failed to add original_amount column: %w", err)
+			}
+
+			if _, err := tx.Exec(`
+				ALTER TABLE transactions 
+				ADD COLUMN original_currency TEXT
+			`); err != nil {
+				return fmt.Errorf("failed to add original_currency column: %w", err)
+			}
+
+			return nil
+		},
+		Down: func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`ALTER TABLE transactions DROP COLUMN original_currency`); err != nil {
+				return fmt.Errorf("failed to drop original_currency column: %w", err)
+			}
+			if _, err := tx.Exec(`ALTER TABLE transactions DROP COLUMN original_amount`); err != nil {
+				return fmt.Errorf("failed to drop original_amount column: %w", err)
+			}
+			return nil
+		},
+	},
+	{
+		Version:     24,
+		Description: "Add memo_pattern column to check_patterns",
+		Up: func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`ALTER TABLE check_patterns ADD COLUMN memo_pattern TEXT DEFAULT ''`); err != nil {
+				return fmt.Errorf("failed to add memo_pattern column: %w", err)
+			}
+			return nil
+		},
+		Down: func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`ALTER TABLE check_patterns DROP COLUMN memo_pattern`); err != nil {
+				return fmt.Errorf("failed to drop memo_pattern column: %w", err)
+			}
+			return nil
+		},
+	},
+	{
+		Version:     25,
+		Description: "Add confidence column to check_patterns",
+		Up: func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`ALTER TABLE check_patterns ADD COLUMN confidence REAL DEFAULT 1.0`); err != nil {
+				return fmt.Errorf("failed to add confidence column: %w", err)
+			}
+			return nil
+		},
+		Down: func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`ALTER TABLE check_patterns DROP COLUMN confidence`); err != nil {
+				return fmt.Errorf("failed to drop confidence column: %w", err)
+			}
+			return nil
+		},
+	},
+	{
+		Version:     26,
+		Description: "Add merchant_hints table for LLM classification hints",
+		Up: func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`
+				CREATE TABLE IF NOT EXISTS merchant_hints (
+					merchant_name TEXT PRIMARY KEY COLLATE NOCASE,
+					hint TEXT NOT NULL,
+					updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+				)
+			`); err != nil {
+				return fmt.Errorf("failed to create merchant_hints table: %w", err)
+			}
+			return nil
+		},
+		Down: func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`DROP TABLE IF EXISTS merchant_hints`); err != nil {
+				return fmt.Errorf("failed to drop merchant_hints table: %w", err)
+			}
+			return nil
+		},
+	},
+	{
+		Version:     27,
+		Description: "Add multi_category column to vendors",
+		Up: func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`ALTER TABLE vendors ADD COLUMN multi_category BOOLEAN DEFAULT FALSE`); err != nil {
+				return fmt.Errorf("failed to add multi_category column: %w", err)
+			}
+			return nil
+		},
+		Down: func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`ALTER TABLE vendors DROP COLUMN multi_category`); err != nil {
+				return fmt.Errorf("failed to drop multi_category column: %w", err)
+			}
+			return nil
+		},
+	},
+	{
+		Version:     28,
+		Description: "Add embeddings table for nearest-neighbor classification",
+		Up: func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`
+				CREATE TABLE IF NOT EXISTS embeddings (
+					merchant_name TEXT NOT NULL COLLATE NOCASE,
+					model TEXT NOT NULL,
+					vector BLOB NOT NULL,
+					created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+					PRIMARY KEY (merchant_name, model)
+				)
+			`); err != nil {
+				return fmt.Errorf("failed to create embeddings table: %w", err)
+			}
+			return nil
+		},
+		Down: func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`DROP TABLE IF EXISTS embeddings`); err != nil {
+				return fmt.Errorf("failed to drop embeddings table: %w", err)
+			}
+			return nil
+		},
+	},
+	{
+		Version:     29,
+		Description: "Add category_aliases table for LLM output normalization",
+		Up: func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`
+				CREATE TABLE IF NOT EXISTS category_aliases (
+					alias TEXT PRIMARY KEY COLLATE NOCASE,
+					category TEXT NOT NULL,
+					created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+				)
+			`); err != nil {
+				return fmt.Errorf("failed to create category_aliases table: %w", err)
+			}
+			return nil
+		},
+		Down: func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`DROP TABLE IF EXISTS category_aliases`); err != nil {
+				return fmt.Errorf("failed to drop category_aliases table: %w", err)
+			}
+			return nil
+		},
+	},
+	{
+		Version:     30,
+		Description: "Add category_snapshots table for reproducible reports",
+		Up: func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`
+				CREATE TABLE IF NOT EXISTS category_snapshots (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					report TEXT NOT NULL,
+					period_start DATETIME NOT NULL,
+					period_end DATETIME NOT NULL,
+					categories TEXT NOT NULL, -- JSON array
+					created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+				)
+			`); err != nil {
+				return fmt.Errorf("failed to create category_snapshots table: %w", err)
+			}
+			return nil
+		},
+		Down: func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`DROP TABLE IF EXISTS category_snapshots`); err != nil {
+				return fmt.Errorf("failed to drop category_snapshots table: %w", err)
+			}
+			return nil
+		},
+	},
+	{
+		Version:     31,
+		Description: "Add always_review column to categories",
+		Up: func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`ALTER TABLE categories ADD COLUMN always_review BOOLEAN NOT NULL DEFAULT FALSE`); err != nil {
+				return fmt.Errorf("failed to add always_review column: %w", err)
+			}
+			return nil
+		},
+		Down: func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`ALTER TABLE categories DROP COLUMN always_review`); err != nil {
+				return fmt.Errorf("failed to drop always_review column: %w", err)
+			}
+			return nil
+		},
+	},
+	{
+		Version:     32,
+		Description: "Add vendor_rule_threshold column to categories",
+		Up: func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`ALTER TABLE categories ADD COLUMN vendor_rule_threshold REAL NOT NULL DEFAULT 0`); err != nil {
+				return fmt.Errorf("failed to add vendor_rule_threshold column: %w", err)
+			}
+			return nil
+		},
+		Down: func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`ALTER TABLE categories DROP COLUMN vendor_rule_threshold`); err != nil {
+				return fmt.Errorf("failed to drop vendor_rule_threshold column: %w", err)
+			}
+			return nil
+		},
+	},
 }
 
 // Migrate applies all pending database migrations.
@@ -954,47 +1160,157 @@ func (s *SQLiteStorage) Migrate(ctx context.Context) error {
 		return fmt.Errorf("failed to get schema version: %w", err)
 	}
 
-	// Apply migrations
+	// A read-only database can't be migrated, only verified
+	if s.readOnly {
+		if currentVersion != ExpectedSchemaVersion {
+			return fmt.Errorf("%w: database schema version %d needs migration to %d", ErrReadOnly, currentVersion, ExpectedSchemaVersion)
+		}
+		return nil
+	}
+
+	if err := applyMigrations(ctx, s.db, currentVersion, ExpectedSchemaVersion, logAppliedMigration); err != nil {
+		return err
+	}
+
+	// Verify we're at the expected schema version
+	var finalVersion int
+	err = s.db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&finalVersion)
+	if err != nil {
+		return fmt.Errorf("failed to verify final schema version: %w", err)
+	}
+
+	if finalVersion != ExpectedSchemaVersion {
+		return fmt.Errorf("database schema version mismatch: expected %d, got %d", ExpectedSchemaVersion, finalVersion)
+	}
+
+	return nil
+}
+
+// MigrateTo moves the schema to targetVersion. A newer version applies the
+// pending migrations up to it; an older one rolls migrations back newest first
+// with their Down functions, each in its own transaction. Rolling back fails
+// before changing anything if a migration in the way has no Down function.
+func (s *SQLiteStorage) MigrateTo(ctx context.Context, targetVersion int) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := s.checkWritable("migrate"); err != nil {
+		return err
+	}
+	if targetVersion < 0 || targetVersion > ExpectedSchemaVersion {
+		return fmt.Errorf("invalid target schema version %d: must be between 0 and %d", targetVersion, ExpectedSchemaVersion)
+	}
+
+	var currentVersion int
+	if err := s.db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&currentVersion); err != nil {
+		return fmt.Errorf("failed to get schema version: %w", err)
+	}
+	if currentVersion > ExpectedSchemaVersion {
+		return fmt.Errorf("database schema version %d is newer than the latest known version %d", currentVersion, ExpectedSchemaVersion)
+	}
+
+	if targetVersion >= currentVersion {
+		return applyMigrations(ctx, s.db, currentVersion, targetVersion, logAppliedMigration)
+	}
+	return rollbackMigrations(ctx, s.db, currentVersion, targetVersion, func(m Migration) {
+		slog.Info("Rolled back migration",
+			"version", m.Version,
+			"description", m.Description)
+	})
+}
+
+func logAppliedMigration(m Migration) {
+	slog.Info("Applied migration",
+		"version", m.Version,
+		"description", m.Description)
+}
+
+// applyMigrations runs every migration newer than currentVersion up to
+// targetVersion, each in its own transaction, calling onApplied after each
+// one commits.
+func applyMigrations(ctx context.Context, db *sql.DB, currentVersion, targetVersion int, onApplied func(Migration)) error {
 	for _, migration := range migrations {
-		if migration.Version <= currentVersion {
+		if migration.Version <= currentVersion || migration.Version > targetVersion {
 			continue
 		}
-
-		tx, txErr := s.db.BeginTx(ctx, nil)
-		if txErr != nil {
-			return fmt.Errorf("failed to begin transaction: %w", txErr)
+		if err := applyMigration(ctx, db, migration); err != nil {
+			return err
 		}
-
-		if upErr := migration.Up(tx); upErr != nil {
-			_ = tx.Rollback()
-			return fmt.Errorf("migration %d failed: %w", migration.Version, upErr)
+		if onApplied != nil {
+			onApplied(migration)
 		}
+	}
+	return nil
+}
 
-		// Update version
-		if _, execErr := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", migration.Version)); execErr != nil {
-			_ = tx.Rollback()
-			return fmt.Errorf("failed to update schema version: %w", execErr)
+// rollbackMigrations undoes every migration newer than targetVersion up to
+// currentVersion, newest first, calling onRolledBack after each one commits.
+func rollbackMigrations(ctx context.Context, db *sql.DB, currentVersion, targetVersion int, onRolledBack func(Migration)) error {
+	var pending []Migration
+	for i := len(migrations) - 1; i >= 0; i-- {
+		migration := migrations[i]
+		if migration.Version > currentVersion || migration.Version <= targetVersion {
+			continue
 		}
+		if migration.Down == nil {
+			return fmt.Errorf("cannot roll back to version %d: migration %d (%s) can't be undone",
+				targetVersion, migration.Version, migration.Description)
+		}
+		pending = append(pending, migration)
+	}
 
-		if commitErr := tx.Commit(); commitErr != nil {
-			return fmt.Errorf("failed to commit migration %d: %w", migration.Version, commitErr)
+	for _, migration := range pending {
+		if err := rollbackMigration(ctx, db, migration); err != nil {
+			return err
+		}
+		if onRolledBack != nil {
+			onRolledBack(migration)
 		}
+	}
+	return nil
+}
+
+func applyMigration(ctx context.Context, db *sql.DB, migration Migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
 
-		slog.Info("Applied migration",
-			"version", migration.Version,
-			"description", migration.Description)
+	if upErr := migration.Up(tx); upErr != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("migration %d failed: %w", migration.Version, upErr)
 	}
 
-	// Verify we're at the expected schema version
-	var finalVersion int
-	err = s.db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&finalVersion)
+	// Update version
+	if _, execErr := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", migration.Version)); execErr != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to update schema version: %w", execErr)
+	}
+
+	if commitErr := tx.Commit(); commitErr != nil {
+		return fmt.Errorf("failed to commit migration %d: %w", migration.Version, commitErr)
+	}
+	return nil
+}
+
+func rollbackMigration(ctx context.Context, db *sql.DB, migration Migration) error {
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to verify final schema version: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
-	if finalVersion != ExpectedSchemaVersion {
-		return fmt.Errorf("database schema version mismatch: expected %d, got %d", ExpectedSchemaVersion, finalVersion)
+	if downErr := migration.Down(tx); downErr != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("rolling back migration %d failed: %w", migration.Version, downErr)
 	}
 
+	if _, execErr := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", migration.Version-1)); execErr != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to update schema version: %w", execErr)
+	}
+
+	if commitErr := tx.Commit(); commitErr != nil {
+		return fmt.Errorf("failed to commit rollback of migration %d: %w", migration.Version, commitErr)
+	}
 	return nil
 }
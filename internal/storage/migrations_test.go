@@ -2,7 +2,10 @@ package storage
 
 import (
 	"context"
+	"database/sql"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Veraticus/the-spice-must-flow/internal/model"
 )
@@ -83,3 +86,89 @@ func TestMigration14_NewVendorDefaultSource(t *testing.T) {
 		t.Errorf("New vendor has source %q, want %q", retrieved.Source, model.SourceAuto)
 	}
 }
+
+func TestMigrateTo(t *testing.T) {
+	store, cleanup := createTestStorage(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	txn := model.Transaction{ID: "t1", Date: time.Now(), Name: "KROGER", MerchantName: "Kroger", Amount: 40, AccountID: "acc1"}
+	txn.Hash = txn.GenerateHash()
+	if err := store.SaveTransactions(ctx, []model.Transaction{txn}); err != nil {
+		t.Fatalf("Failed to save transaction: %v", err)
+	}
+	latest, err := snapshotSchema(ctx, store.db)
+	if err != nil {
+		t.Fatalf("Failed to snapshot schema: %v", err)
+	}
+
+	// Migration 22 only changes data, so it can't be undone
+	err = store.MigrateTo(ctx, 21)
+	if err == nil || !strings.Contains(err.Error(), "migration 22") {
+		t.Fatalf("MigrateTo(21) = %v, want an error naming migration 22", err)
+	}
+	if version := schemaVersion(t, store.db); version != ExpectedSchemaVersion {
+		t.Fatalf("Version after refused rollback = %d, want it unchanged at %d", version, ExpectedSchemaVersion)
+	}
+
+	if err := store.MigrateTo(ctx, 22); err != nil {
+		t.Fatalf("MigrateTo(22) failed: %v", err)
+	}
+	rolledBack, err := snapshotSchema(ctx, store.db)
+	if err != nil {
+		t.Fatalf("Failed to snapshot schema: %v", err)
+	}
+	for _, diff := range diffSchema(schemaAt(t, 22), rolledBack) {
+		t.Errorf("Rolled back schema differs from version 22: %s", diff)
+	}
+
+	if err := store.MigrateTo(ctx, ExpectedSchemaVersion); err != nil {
+		t.Fatalf("MigrateTo(%d) failed: %v", ExpectedSchemaVersion, err)
+	}
+	upgraded, err := snapshotSchema(ctx, store.db)
+	if err != nil {
+		t.Fatalf("Failed to snapshot schema: %v", err)
+	}
+	for _, diff := range diffSchema(latest, upgraded) {
+		t.Errorf("Schema after rolling forward again differs: %s", diff)
+	}
+	if _, err := store.GetTransactionByID(ctx, "t1"); err != nil {
+		t.Errorf("Transaction lost across rollback: %v", err)
+	}
+
+	for _, target := range []int{-1, ExpectedSchemaVersion + 1} {
+		if err := store.MigrateTo(ctx, target); err == nil {
+			t.Errorf("MigrateTo(%d) succeeded, want an error", target)
+		}
+	}
+}
+
+// schemaAt returns the schema a new database has at version.
+func schemaAt(t *testing.T, version int) *SchemaSnapshot {
+	t.Helper()
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	db.SetMaxOpenConns(1)
+
+	if err := applyMigrations(ctx, db, 0, version, nil); err != nil {
+		t.Fatalf("Failed to migrate to version %d: %v", version, err)
+	}
+	snapshot, err := snapshotSchema(ctx, db)
+	if err != nil {
+		t.Fatalf("Failed to snapshot schema: %v", err)
+	}
+	return snapshot
+}
+
+func schemaVersion(t *testing.T, db *sql.DB) int {
+	t.Helper()
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		t.Fatalf("Failed to get schema version: %v", err)
+	}
+	return version
+}
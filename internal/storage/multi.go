@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
+)
+
+// SourceSeparator joins a source name and a transaction ID in merged results.
+const SourceSeparator = ":"
+
+// NamedSource is one database taking part in a multi-source report.
+type NamedSource struct {
+	Storage service.ReportStorage
+	Name    string
+}
+
+// MultiStorage unions report data from several databases.
+// Transaction IDs are prefixed with their source name so that rows imported
+// into different databases can never collide.
+type MultiStorage struct {
+	sources []NamedSource
+}
+
+// NewMultiStorage combines the given sources. Source names must be unique,
+// non-empty and free of the separator so namespaced IDs stay unambiguous.
+func NewMultiStorage(sources ...NamedSource) (*MultiStorage, error) {
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("at least one source is required")
+	}
+
+	seen := make(map[string]bool, len(sources))
+	for _, src := range sources {
+		if src.Storage == nil {
+			return nil, fmt.Errorf("source %q has no storage", src.Name)
+		}
+		if src.Name == "" {
+			return nil, fmt.Errorf("source name cannot be empty")
+		}
+		if strings.Contains(src.Name, SourceSeparator) {
+			return nil, fmt.Errorf("source name %q cannot contain %q", src.Name, SourceSeparator)
+		}
+		if seen[src.Name] {
+			return nil, fmt.Errorf("duplicate source name %q", src.Name)
+		}
+		seen[src.Name] = true
+	}
+
+	return &MultiStorage{sources: sources}, nil
+}
+
+// NamespacedID returns the ID a transaction from source has in merged results.
+func NamespacedID(source, id string) string {
+	return source + SourceSeparator + id
+}
+
+// GetTransactionsToClassify returns unclassified transactions from every source, oldest first.
+func (m *MultiStorage) GetTransactionsToClassify(ctx context.Context, fromDate *time.Time) ([]model.Transaction, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	var all []model.Transaction
+	for _, src := range m.sources {
+		txns, err := src.Storage.GetTransactionsToClassify(ctx, fromDate)
+		if err != nil {
+			return nil, fmt.Errorf("source %q: %w", src.Name, err)
+		}
+		for _, txn := range txns {
+			txn.ID = NamespacedID(src.Name, txn.ID)
+			all = append(all, txn)
+		}
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Date.Before(all[j].Date)
+	})
+	return all, nil
+}
+
+// GetClassificationsByDateRange returns classifications from every source, oldest first.
+func (m *MultiStorage) GetClassificationsByDateRange(ctx context.Context, start, end time.Time) ([]model.Classification, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	var all []model.Classification
+	for _, src := range m.sources {
+		classifications, err := src.Storage.GetClassificationsByDateRange(ctx, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("source %q: %w", src.Name, err)
+		}
+		for _, c := range classifications {
+			c.Transaction.ID = NamespacedID(src.Name, c.Transaction.ID)
+			all = append(all, c)
+		}
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Transaction.Date.Before(all[j].Transaction.Date)
+	})
+	return all, nil
+}
+
+// GetCategories merges categories by name, case-insensitively. The first
+// source to define a category wins; IDs are renumbered because category IDs
+// from different databases are unrelated.
+func (m *MultiStorage) GetCategories(ctx context.Context) ([]model.Category, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	var merged []model.Category
+	index := make(map[string]int)
+	for _, src := range m.sources {
+		categories, err := src.Storage.GetCategories(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("source %q: %w", src.Name, err)
+		}
+		for _, cat := range categories {
+			key := strings.ToLower(cat.Name)
+			if i, ok := index[key]; ok {
+				if merged[i].Type != cat.Type {
+					slog.Warn("Category type differs between sources; keeping the first",
+						"category", cat.Name,
+						"source", src.Name,
+						"kept", merged[i].Type,
+						"ignored", cat.Type)
+				}
+				continue
+			}
+			index[key] = len(merged)
+			merged = append(merged, cat)
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Name < merged[j].Name
+	})
+	for i := range merged {
+		merged[i].ID = i + 1
+	}
+	return merged, nil
+}
+
+// Close closes every source, reporting all failures.
+func (m *MultiStorage) Close() error {
+	var errs []error
+	for _, src := range m.sources {
+		if err := src.Storage.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("source %q: %w", src.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
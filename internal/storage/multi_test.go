@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+func TestMultiStorage(t *testing.T) {
+	ctx := context.Background()
+	personal, cleanupPersonal := createTestStorageWithCategories(t, "Groceries", "Dining")
+	defer cleanupPersonal()
+	business, cleanupBusiness := createTestStorageWithCategories(t, "groceries", "Software")
+	defer cleanupBusiness()
+
+	seed := func(store *SQLiteStorage, id string, day int, category string) {
+		txn := model.Transaction{
+			ID:           id,
+			Date:         time.Date(2024, 3, day, 0, 0, 0, 0, time.UTC),
+			Name:         "MERCHANT " + id,
+			MerchantName: "Merchant " + id,
+			Amount:       10,
+			AccountID:    "acc1",
+			Direction:    model.DirectionExpense,
+		}
+		txn.Hash = txn.GenerateHash()
+		require.NoError(t, store.SaveTransactions(ctx, []model.Transaction{txn}))
+		if category != "" {
+			require.NoError(t, store.SaveClassification(ctx, &model.Classification{
+				Transaction: txn, Category: category, Status: model.StatusClassifiedByAI, Confidence: 0.9, ClassifiedAt: time.Now(),
+			}))
+		}
+	}
+
+	// Both databases use the same transaction ID
+	seed(personal, "txn1", 10, "Groceries")
+	seed(business, "txn1", 5, "Software")
+	seed(business, "txn2", 7, "")
+
+	multi, err := NewMultiStorage(
+		NamedSource{Name: "personal", Storage: personal},
+		NamedSource{Name: "business", Storage: business},
+	)
+	require.NoError(t, err)
+
+	t.Run("classifications are unioned with namespaced IDs", func(t *testing.T) {
+		start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+		end := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+		classifications, err := multi.GetClassificationsByDateRange(ctx, start, end)
+		require.NoError(t, err)
+		require.Len(t, classifications, 2)
+		assert.Equal(t, "business:txn1", classifications[0].Transaction.ID, "ordered by date across sources")
+		assert.Equal(t, "Software", classifications[0].Category)
+		assert.Equal(t, "personal:txn1", classifications[1].Transaction.ID)
+	})
+
+	t.Run("unclassified transactions are unioned", func(t *testing.T) {
+		txns, err := multi.GetTransactionsToClassify(ctx, nil)
+		require.NoError(t, err)
+		require.Len(t, txns, 1)
+		assert.Equal(t, "business:txn2", txns[0].ID)
+	})
+
+	t.Run("categories are merged by name", func(t *testing.T) {
+		categories, err := multi.GetCategories(ctx)
+		require.NoError(t, err)
+
+		var names []string
+		for i, cat := range categories {
+			names = append(names, cat.Name)
+			assert.Equal(t, i+1, cat.ID)
+		}
+		assert.Equal(t, []string{"Dining", "Groceries", "Software"}, names)
+	})
+}
+
+func TestNewMultiStorage_Validation(t *testing.T) {
+	store, cleanup := createTestStorage(t)
+	defer cleanup()
+
+	tests := []struct {
+		name    string
+		sources []NamedSource
+	}{
+		{name: "no sources"},
+		{name: "empty name", sources: []NamedSource{{Storage: store}}},
+		{name: "separator in name", sources: []NamedSource{{Name: "a:b", Storage: store}}},
+		{name: "nil storage", sources: []NamedSource{{Name: "a"}}},
+		{name: "duplicate name", sources: []NamedSource{{Name: "a", Storage: store}, {Name: "a", Storage: store}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewMultiStorage(tt.sources...)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -14,6 +14,9 @@ func (s *SQLiteStorage) CreatePatternRule(ctx context.Context, rule *model.Patte
 	if err := validateContext(ctx); err != nil {
 		return err
 	}
+	if err := s.checkWritable("create pattern rule"); err != nil {
+		return err
+	}
 
 	if err := validatePatternRule(rule); err != nil {
 		return err
@@ -142,11 +145,63 @@ func (s *SQLiteStorage) GetActivePatternRules(ctx context.Context) ([]model.Patt
 	return rules, nil
 }
 
+// GetAllPatternRules retrieves all pattern rules, including inactive ones.
+func (s *SQLiteStorage) GetAllPatternRules(ctx context.Context) ([]model.PatternRule, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	return getAllPatternRulesTx(ctx, s.db)
+}
+
+// getAllPatternRulesTx retrieves all pattern rules using q, which may be a transaction.
+func getAllPatternRulesTx(ctx context.Context, q queryable) ([]model.PatternRule, error) {
+	query := `
+		SELECT id, name, description, merchant_pattern, is_regex,
+			amount_condition, amount_value, amount_min, amount_max,
+			direction, default_category, confidence, priority, is_active,
+			created_at, updated_at, use_count
+		FROM pattern_rules
+		ORDER BY priority DESC, id ASC
+	`
+
+	rows, err := q.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pattern rules: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var rules []model.PatternRule
+	for rows.Next() {
+		var rule model.PatternRule
+		var direction sql.NullString
+		err := rows.Scan(
+			&rule.ID, &rule.Name, &rule.Description, &rule.MerchantPattern, &rule.IsRegex,
+			&rule.AmountCondition, &rule.AmountValue, &rule.AmountMin, &rule.AmountMax,
+			&direction, &rule.DefaultCategory, &rule.Confidence, &rule.Priority, &rule.IsActive,
+			&rule.CreatedAt, &rule.UpdatedAt, &rule.UseCount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pattern rule: %w", err)
+		}
+		rule.Direction = nullStringToDirection(direction)
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pattern rules: %w", err)
+	}
+
+	return rules, nil
+}
+
 // UpdatePatternRule updates an existing pattern rule.
 func (s *SQLiteStorage) UpdatePatternRule(ctx context.Context, rule *model.PatternRule) error {
 	if err := validateContext(ctx); err != nil {
 		return err
 	}
+	if err := s.checkWritable("update pattern rule"); err != nil {
+		return err
+	}
 
 	if err := validatePatternRule(rule); err != nil {
 		return err
@@ -199,6 +254,9 @@ func (s *SQLiteStorage) DeletePatternRule(ctx context.Context, id int) error {
 	if err := validateContext(ctx); err != nil {
 		return err
 	}
+	if err := s.checkWritable("delete pattern rule"); err != nil {
+		return err
+	}
 
 	result, err := s.db.ExecContext(ctx, "DELETE FROM pattern_rules WHERE id = ?", id)
 	if err != nil {
@@ -221,6 +279,9 @@ func (s *SQLiteStorage) IncrementPatternRuleUseCount(ctx context.Context, id int
 	if err := validateContext(ctx); err != nil {
 		return err
 	}
+	if err := s.checkWritable("update pattern rule use count"); err != nil {
+		return err
+	}
 
 	query := `UPDATE pattern_rules SET use_count = use_count + 1 WHERE id = ?`
 	result, err := s.db.ExecContext(ctx, query, id)
@@ -480,6 +541,14 @@ func (t *sqliteTransaction) GetActivePatternRules(ctx context.Context) ([]model.
 	return rules, nil
 }
 
+// GetAllPatternRules retrieves all pattern rules, including inactive ones, within a transaction.
+func (t *sqliteTransaction) GetAllPatternRules(ctx context.Context) ([]model.PatternRule, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	return getAllPatternRulesTx(ctx, t.tx)
+}
+
 // UpdatePatternRule updates an existing pattern rule within a transaction.
 func (t *sqliteTransaction) UpdatePatternRule(ctx context.Context, rule *model.PatternRule) error {
 	if err := validateContext(ctx); err != nil {
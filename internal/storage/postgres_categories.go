@@ -0,0 +1,590 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/common"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+const pgCategoryColumns = `id, name, description, created_at, is_active, type, default_business_percent, always_review, vendor_rule_threshold`
+
+// GetCategories returns all active categories.
+func (s *PostgresStorage) GetCategories(ctx context.Context) ([]model.Category, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.conn().QueryContext(ctx, `
+		SELECT `+pgCategoryColumns+`
+		FROM categories
+		WHERE is_active = TRUE
+		ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query categories: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var categories []model.Category
+	for rows.Next() {
+		cat, err := scanPostgresCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, *cat)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating categories: %w", err)
+	}
+	if err := attachCategoryAliases(ctx, s.conn(), categories); err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+// GetCategoryByName returns a category by its name.
+func (s *PostgresStorage) GetCategoryByName(ctx context.Context, name string) (*model.Category, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return nil, fmt.Errorf("category name cannot be empty")
+	}
+
+	row := s.conn().QueryRowContext(ctx, `
+		SELECT `+pgCategoryColumns+`
+		FROM categories
+		WHERE name = $1 AND is_active = TRUE`, name)
+	cat, err := scanPostgresCategory(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrCategoryNotFound
+	}
+	return cat, err
+}
+
+// GetCategoryByID returns a category by its ID.
+func (s *PostgresStorage) GetCategoryByID(ctx context.Context, id int) (*model.Category, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid category ID: %d", id)
+	}
+
+	row := s.conn().QueryRowContext(ctx, `
+		SELECT `+pgCategoryColumns+`
+		FROM categories
+		WHERE id = $1 AND is_active = TRUE`, id)
+	cat, err := scanPostgresCategory(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrCategoryNotFound
+	}
+	return cat, err
+}
+
+// CreateCategory creates a new category with default expense type.
+func (s *PostgresStorage) CreateCategory(ctx context.Context, name, description string) (*model.Category, error) {
+	return s.CreateCategoryWithType(ctx, name, description, model.CategoryTypeExpense)
+}
+
+// CreateCategoryWithType creates a new category with the specified type. An
+// inactive category of the same name is reactivated instead.
+func (s *PostgresStorage) CreateCategoryWithType(ctx context.Context, name, description string, categoryType model.CategoryType) (*model.Category, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return nil, fmt.Errorf("category name cannot be empty")
+	}
+
+	row := s.conn().QueryRowContext(ctx, `
+		SELECT `+pgCategoryColumns+`
+		FROM categories
+		WHERE name = $1`, name)
+	existing, err := scanPostgresCategory(row)
+	if err == nil {
+		if !existing.IsActive {
+			if _, err := s.conn().ExecContext(ctx, `
+				UPDATE categories SET is_active = TRUE, type = $1 WHERE id = $2
+			`, string(categoryType), existing.ID); err != nil {
+				return nil, fmt.Errorf("failed to reactivate category: %w", err)
+			}
+			existing.IsActive = true
+			existing.Type = categoryType
+			slog.Info("reactivated existing category", "name", name, "type", categoryType)
+		}
+		return existing, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to check existing category: %w", err)
+	}
+
+	row = s.conn().QueryRowContext(ctx, `
+		INSERT INTO categories (name, description, created_at, is_active, type)
+		VALUES ($1, $2, $3, TRUE, $4)
+		RETURNING `+pgCategoryColumns,
+		name, description, time.Now(), string(categoryType))
+	category, err := scanPostgresCategory(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create category: %w", err)
+	}
+
+	slog.Info("created new category", "name", name, "id", category.ID, "type", categoryType)
+	return category, nil
+}
+
+// UpdateCategory updates an existing category.
+func (s *PostgresStorage) UpdateCategory(ctx context.Context, id int, name, description string) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if name == "" {
+		return fmt.Errorf("category name cannot be empty")
+	}
+
+	// Check if another category already has this name
+	var existingID int
+	err := s.conn().QueryRowContext(ctx, `
+		SELECT id FROM categories WHERE name = $1 AND id != $2 AND is_active = TRUE
+	`, name, id).Scan(&existingID)
+	if err != sql.ErrNoRows {
+		if err == nil {
+			return fmt.Errorf("category with name %q already exists", name)
+		}
+		return fmt.Errorf("failed to check for duplicate category: %w", err)
+	}
+
+	result, err := s.conn().ExecContext(ctx, `
+		UPDATE categories
+		SET name = $1, description = $2
+		WHERE id = $3 AND is_active = TRUE`, name, description, id)
+	if err != nil {
+		return fmt.Errorf("failed to update category: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("category not found or inactive")
+	}
+
+	slog.Info("updated category", "id", id, "name", name)
+	return nil
+}
+
+// UpdateCategoryBusinessPercent updates the default business percentage for a category.
+func (s *PostgresStorage) UpdateCategoryBusinessPercent(ctx context.Context, id int, businessPercent int) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if businessPercent < 0 || businessPercent > 100 {
+		return fmt.Errorf("business percentage must be between 0 and 100")
+	}
+
+	// Only allow business percent updates for expense categories
+	var catType sql.NullString
+	err := s.conn().QueryRowContext(ctx, `
+		SELECT type FROM categories WHERE id = $1 AND is_active = TRUE
+	`, id).Scan(&catType)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("category with ID %d not found", id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check category: %w", err)
+	}
+	if catType.Valid && (catType.String == string(model.CategoryTypeIncome) || catType.String == string(model.CategoryTypeSystem)) {
+		return fmt.Errorf("cannot set business percentage for %s categories", catType.String)
+	}
+
+	return s.updateActiveCategory(ctx, id, "default_business_percent", businessPercent,
+		"failed to update category business percentage")
+}
+
+// SetCategoryAlwaysReview sets whether transactions suggested for a category
+// always go to manual review.
+func (s *PostgresStorage) SetCategoryAlwaysReview(ctx context.Context, id int, alwaysReview bool) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	return s.updateActiveCategory(ctx, id, "always_review", alwaysReview,
+		"failed to update category review")
+}
+
+// SetCategoryVendorRuleThreshold sets the confidence an auto-accepted
+// suggestion for a category needs before a vendor rule is created for its
+// merchant. 0 restores the default.
+func (s *PostgresStorage) SetCategoryVendorRuleThreshold(ctx context.Context, id int, threshold float64) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if threshold < 0 || threshold > 1 {
+		return fmt.Errorf("vendor rule threshold must be between 0 and 1, got %v", threshold)
+	}
+	return s.updateActiveCategory(ctx, id, "vendor_rule_threshold", threshold,
+		"failed to update category vendor rule threshold")
+}
+
+// updateActiveCategory sets one column of an active category.
+func (s *PostgresStorage) updateActiveCategory(ctx context.Context, id int, column string, value any, failure string) error {
+	result, err := s.conn().ExecContext(ctx, `
+		UPDATE categories
+		SET `+column+` = $1
+		WHERE id = $2 AND is_active = TRUE`, value, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", failure, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("category with ID %d not found", id)
+	}
+	return nil
+}
+
+// DeleteCategory soft-deletes a category by setting is_active to false.
+func (s *PostgresStorage) DeleteCategory(ctx context.Context, id int) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+
+	// Check if category is in use
+	var usageCount int
+	if err := s.conn().QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM classifications
+		WHERE category = (SELECT name FROM categories WHERE id = $1)
+	`, id).Scan(&usageCount); err != nil {
+		return fmt.Errorf("failed to check category usage: %w", err)
+	}
+	if usageCount > 0 {
+		return fmt.Errorf("cannot delete category: %d transactions are using it", usageCount)
+	}
+
+	result, err := s.conn().ExecContext(ctx, `UPDATE categories SET is_active = FALSE WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("category not found")
+	}
+
+	slog.Info("deleted category", "id", id)
+	return nil
+}
+
+// MergeCategories moves every reference to the source category onto the target
+// and soft-deletes the source. All updates happen in a single transaction.
+func (s *PostgresStorage) MergeCategories(ctx context.Context, fromID, toID int) (*model.CategoryMergeResult, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if fromID == toID {
+		return nil, fmt.Errorf("cannot merge category %d into itself", fromID)
+	}
+
+	var result *model.CategoryMergeResult
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		result, err = s.mergeCategoriesTx(ctx, tx, fromID, toID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Vendors may have moved; drop cached entries so lookups see the new category
+	if result.Vendors > 0 {
+		s.vendorCache.clear()
+	}
+	return result, nil
+}
+
+func (s *PostgresStorage) mergeCategoriesTx(ctx context.Context, tx *sql.Tx, fromID, toID int) (*model.CategoryMergeResult, error) {
+	names := make(map[int]string, 2)
+	for _, id := range []int{fromID, toID} {
+		var name string
+		err := tx.QueryRowContext(ctx, `SELECT name FROM categories WHERE id = $1 AND is_active = TRUE`, id).Scan(&name)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("category with ID %d not found", id)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get category %d: %w", id, err)
+		}
+		names[id] = name
+	}
+	fromName, toName := names[fromID], names[toID]
+
+	result := &model.CategoryMergeResult{}
+	updates := []struct {
+		count *int64
+		query string
+	}{
+		{&result.Classifications, `UPDATE classifications SET category = $1 WHERE category = $2`},
+		{&result.Vendors, `UPDATE vendors SET category = $1 WHERE category = $2`},
+		{&result.CheckPatterns, `UPDATE check_patterns SET category = $1, updated_at = CURRENT_TIMESTAMP WHERE category = $2`},
+		{&result.PatternRules, `UPDATE pattern_rules SET default_category = $1, updated_at = CURRENT_TIMESTAMP WHERE default_category = $2`},
+		{nil, `UPDATE transactions SET refund_category = $1 WHERE refund_category = $2`},
+		{nil, `UPDATE category_aliases SET category = $1 WHERE category = $2`},
+	}
+	for _, update := range updates {
+		res, err := tx.ExecContext(ctx, update.query, toName, fromName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to move references from %q to %q: %w", fromName, toName, err)
+		}
+		if update.count != nil {
+			if *update.count, err = res.RowsAffected(); err != nil {
+				return nil, fmt.Errorf("failed to get rows affected: %w", err)
+			}
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE categories SET is_active = FALSE WHERE id = $1`, fromID); err != nil {
+		return nil, fmt.Errorf("failed to delete merged category: %w", err)
+	}
+
+	slog.Info("merged category", "from", fromName, "to", toName,
+		"classifications", result.Classifications, "vendors", result.Vendors)
+	return result, nil
+}
+
+// scanPostgresCategory scans a row of pgCategoryColumns. sql.ErrNoRows is
+// returned as is.
+func scanPostgresCategory(row interface{ Scan(dest ...any) error }) (*model.Category, error) {
+	var cat model.Category
+	var catType sql.NullString
+	var defaultBusinessPercent sql.NullInt64
+	var description sql.NullString
+	err := row.Scan(&cat.ID, &cat.Name, &description, &cat.CreatedAt, &cat.IsActive,
+		&catType, &defaultBusinessPercent, &cat.AlwaysReview, &cat.VendorRuleThreshold)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan category: %w", err)
+	}
+
+	cat.Description = description.String
+	cat.Type = model.CategoryTypeExpense
+	if catType.Valid && catType.String != "" {
+		cat.Type = model.CategoryType(catType.String)
+	}
+	if defaultBusinessPercent.Valid {
+		cat.DefaultBusinessPercent = int(defaultBusinessPercent.Int64)
+	}
+	return &cat, nil
+}
+
+// SaveCategoryAlias maps alias onto an active category, replacing any category
+// the alias pointed to before. Aliases are matched case-insensitively and may
+// not be the name of an active category.
+func (s *PostgresStorage) SaveCategoryAlias(ctx context.Context, alias, category string) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+
+	alias = strings.TrimSpace(alias)
+	if err := validateString(alias, "alias"); err != nil {
+		return err
+	}
+	if err := validateString(category, "category"); err != nil {
+		return err
+	}
+
+	q := s.conn()
+	var canonical string
+	err := q.QueryRowContext(ctx, `
+		SELECT name FROM categories WHERE LOWER(name) = LOWER($1) AND is_active = TRUE
+	`, category).Scan(&canonical)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%w: %s", ErrCategoryNotFound, category)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get category: %w", err)
+	}
+
+	var existing string
+	err = q.QueryRowContext(ctx, `
+		SELECT name FROM categories WHERE LOWER(name) = LOWER($1) AND is_active = TRUE
+	`, alias).Scan(&existing)
+	if err == nil {
+		return fmt.Errorf("alias %q is already a category name", alias)
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("failed to check alias against categories: %w", err)
+	}
+
+	_, err = q.ExecContext(ctx, `
+		INSERT INTO category_aliases (alias, category)
+		VALUES ($1, $2)
+		ON CONFLICT ((LOWER(alias))) DO UPDATE SET
+			category = excluded.category
+	`, alias, canonical)
+	if err != nil {
+		return fmt.Errorf("failed to save category alias: %w", err)
+	}
+	return nil
+}
+
+// GetCategoryAliases returns every category alias, ordered by category and alias.
+func (s *PostgresStorage) GetCategoryAliases(ctx context.Context) ([]model.CategoryAlias, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.conn().QueryContext(ctx, `
+		SELECT alias, category, created_at
+		FROM category_aliases
+		ORDER BY category, alias
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query category aliases: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var aliases []model.CategoryAlias
+	for rows.Next() {
+		var alias model.CategoryAlias
+		if err := rows.Scan(&alias.Alias, &alias.Category, &alias.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan category alias: %w", err)
+		}
+		aliases = append(aliases, alias)
+	}
+
+	return aliases, rows.Err()
+}
+
+// DeleteCategoryAlias removes an alias.
+func (s *PostgresStorage) DeleteCategoryAlias(ctx context.Context, alias string) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := validateString(alias, "alias"); err != nil {
+		return err
+	}
+
+	result, err := s.conn().ExecContext(ctx, `
+		DELETE FROM category_aliases WHERE LOWER(alias) = LOWER($1)
+	`, strings.TrimSpace(alias))
+	if err != nil {
+		return fmt.Errorf("failed to delete category alias: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return common.ErrNotFound
+	}
+	return nil
+}
+
+// SaveCategorySnapshot stores a snapshot of a category set and sets its ID and
+// creation time.
+func (s *PostgresStorage) SaveCategorySnapshot(ctx context.Context, snapshot *model.CategorySnapshot) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if snapshot == nil {
+		return fmt.Errorf("category snapshot cannot be nil")
+	}
+	if err := validateString(snapshot.Report, "report"); err != nil {
+		return err
+	}
+
+	categories := make([]snapshotCategory, 0, len(snapshot.Categories))
+	for _, category := range snapshot.Categories {
+		categories = append(categories, snapshotCategory{
+			Name:                   category.Name,
+			Description:            category.Description,
+			Type:                   category.Type,
+			DefaultBusinessPercent: category.DefaultBusinessPercent,
+		})
+	}
+	data, err := json.Marshal(categories)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot categories: %w", err)
+	}
+
+	createdAt := time.Now()
+	var id int64
+	err = s.conn().QueryRowContext(ctx, `
+		INSERT INTO category_snapshots (report, period_start, period_end, categories, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, snapshot.Report, snapshot.PeriodStart, snapshot.PeriodEnd, string(data), createdAt).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("failed to save category snapshot: %w", err)
+	}
+
+	snapshot.ID = id
+	snapshot.CreatedAt = createdAt
+	return nil
+}
+
+// GetCategorySnapshot returns a category snapshot by ID.
+func (s *PostgresStorage) GetCategorySnapshot(ctx context.Context, id int64) (*model.CategorySnapshot, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	row := s.conn().QueryRowContext(ctx, `
+		SELECT id, report, period_start, period_end, categories, created_at
+		FROM category_snapshots
+		WHERE id = $1
+	`, id)
+
+	snapshot, err := scanCategorySnapshot(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: category snapshot %d", common.ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// GetCategorySnapshots returns every category snapshot, newest first.
+func (s *PostgresStorage) GetCategorySnapshots(ctx context.Context) ([]model.CategorySnapshot, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.conn().QueryContext(ctx, `
+		SELECT id, report, period_start, period_end, categories, created_at
+		FROM category_snapshots
+		ORDER BY id DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query category snapshots: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var snapshots []model.CategorySnapshot
+	for rows.Next() {
+		snapshot, err := scanCategorySnapshot(rows)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, *snapshot)
+	}
+
+	return snapshots, rows.Err()
+}
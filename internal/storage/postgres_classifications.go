@@ -0,0 +1,674 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+// SaveClassification saves a classification for a transaction.
+func (s *PostgresStorage) SaveClassification(ctx context.Context, classification *model.Classification) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := validateClassification(classification); err != nil {
+		return err
+	}
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		return s.saveClassificationTx(ctx, tx, classification)
+	})
+}
+
+// SaveClassifications saves many classifications in a single transaction.
+// Either all of them are saved or, on the first error, none are.
+func (s *PostgresStorage) SaveClassifications(ctx context.Context, classifications []model.Classification) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if len(classifications) == 0 {
+		return nil
+	}
+	for i := range classifications {
+		if err := validateClassification(&classifications[i]); err != nil {
+			return fmt.Errorf("classification for transaction %s: %w", classifications[i].Transaction.ID, err)
+		}
+	}
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for i := range classifications {
+			if err := s.saveClassificationTx(ctx, tx, &classifications[i]); err != nil {
+				return fmt.Errorf("transaction %s: %w", classifications[i].Transaction.ID, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (s *PostgresStorage) saveClassificationTx(ctx context.Context, tx *sql.Tx, classification *model.Classification) error {
+	// Set ClassifiedAt if not set
+	if classification.ClassifiedAt.IsZero() {
+		classification.ClassifiedAt = time.Now()
+	}
+
+	// The user's own classifications stick unless overwriting them is allowed
+	if classification.Status != model.StatusUserModified && !s.overwriteUserModified {
+		var status string
+		err := tx.QueryRowContext(ctx, `
+			SELECT status FROM classifications WHERE transaction_id = $1
+		`, classification.Transaction.ID).Scan(&status)
+		switch {
+		case err == nil && model.ClassificationStatus(status) == model.StatusUserModified:
+			return fmt.Errorf("%w: transaction %s", ErrUserModified, classification.Transaction.ID)
+		case err != nil && !errors.Is(err, sql.ErrNoRows):
+			return fmt.Errorf("failed to check existing classification: %w", err)
+		}
+	}
+
+	// Validate category exists (only if status is not unclassified and category is provided)
+	if classification.Status != model.StatusUnclassified && classification.Category != "" {
+		if err := requireActiveCategory(ctx, tx, classification.Category); err != nil {
+			return err
+		}
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO classifications (
+			transaction_id, category, status, confidence,
+			classified_at, notes, business_percent
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (transaction_id) DO UPDATE SET
+			category = excluded.category,
+			status = excluded.status,
+			confidence = excluded.confidence,
+			classified_at = excluded.classified_at,
+			notes = excluded.notes,
+			business_percent = excluded.business_percent
+	`,
+		classification.Transaction.ID,
+		classification.Category,
+		string(classification.Status),
+		classification.Confidence,
+		classification.ClassifiedAt,
+		classification.Notes,
+		classification.BusinessPercent,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save classification: %w", err)
+	}
+
+	// Add to history for auditing
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO classification_history (
+			transaction_id, category, status, confidence
+		) VALUES ($1, $2, $3, $4)
+	`,
+		classification.Transaction.ID,
+		classification.Category,
+		string(classification.Status),
+		classification.Confidence,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save classification history: %w", err)
+	}
+
+	// If this is a user-modified classification with a category, create/update its vendor rule;
+	// a rule-based one updates the rule that matched
+	if (classification.Status == model.StatusUserModified || classification.Status == model.StatusClassifiedByRule) &&
+		classification.Transaction.MerchantName != "" && classification.Category != "" {
+		// Multi-category merchants never get a rule
+		multiCategory, err := s.isMultiCategoryVendor(ctx, tx, classification.Transaction.MerchantName)
+		if err != nil || multiCategory {
+			return err
+		}
+
+		// Check if vendor exists, under any spelling when matching ignores case
+		vendor, err := s.getVendorRule(ctx, tx, classification.Transaction.MerchantName)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("failed to check vendor: %w", err)
+		}
+
+		switch {
+		case vendor == nil && classification.Status == model.StatusClassifiedByRule:
+			// A rule match only counts a use of its vendor; pattern rules and
+			// check patterns don't become vendor rules
+			return nil
+		case vendor == nil:
+			vendor = &model.Vendor{
+				Name:     classification.Transaction.MerchantName,
+				Category: classification.Category,
+				Source:   model.SourceAuto,
+				UseCount: 1,
+			}
+		default:
+			// Update a copy; the cached vendor must not change if the save fails
+			updated := *vendor
+			vendor = &updated
+			vendor.Category = classification.Category
+			vendor.UseCount++
+			// A user's choice re-verifies the rule, so it no longer counts as stale
+			if classification.Status == model.StatusUserModified {
+				vendor.LastUpdated = time.Now()
+			}
+		}
+
+		if err := s.saveVendorTx(ctx, tx, vendor); err != nil {
+			return fmt.Errorf("failed to save vendor rule: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetClassificationsByDateRange retrieves classifications within a date range.
+func (s *PostgresStorage) GetClassificationsByDateRange(ctx context.Context, start, end time.Time) ([]model.Classification, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("%w: end date %v is before start date %v", ErrInvalidDateRange, end, start)
+	}
+
+	rows, err := s.conn().QueryContext(ctx, `
+		SELECT
+			t.id, t.hash, t.date, t.name, COALESCE(t.merchant_name, ''),
+			t.amount, t.categories, COALESCE(t.account_id, ''),
+			t.transaction_type, t.check_number,
+			t.original_amount, t.original_currency,
+			COALESCE(t.is_refund, FALSE), t.refund_category,
+			c.category, c.status, c.confidence, c.classified_at, COALESCE(c.notes, ''),
+			c.business_percent
+		FROM classifications c
+		JOIN transactions t ON c.transaction_id = t.id
+		WHERE t.date >= $1 AND t.date <= $2
+		ORDER BY t.date
+	`, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query classifications: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var classifications []model.Classification
+	for rows.Next() {
+		var c model.Classification
+		var statusStr string
+		var categories sql.NullString
+		var txType sql.NullString
+		var checkNum sql.NullString
+		var originalAmount sql.NullFloat64
+		var originalCurrency sql.NullString
+		var refundCategory sql.NullString
+
+		err := rows.Scan(
+			&c.Transaction.ID,
+			&c.Transaction.Hash,
+			&c.Transaction.Date,
+			&c.Transaction.Name,
+			&c.Transaction.MerchantName,
+			&c.Transaction.Amount,
+			&categories,
+			&c.Transaction.AccountID,
+			&txType,
+			&checkNum,
+			&originalAmount,
+			&originalCurrency,
+			&c.Transaction.IsRefund,
+			&refundCategory,
+			&c.Category,
+			&statusStr,
+			&c.Confidence,
+			&c.ClassifiedAt,
+			&c.Notes,
+			&c.BusinessPercent,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan classification: %w", err)
+		}
+
+		c.Status = model.ClassificationStatus(statusStr)
+
+		// Parse categories JSON
+		if categories.Valid && categories.String != "" {
+			if err := json.Unmarshal([]byte(categories.String), &c.Transaction.Category); err != nil {
+				return nil, fmt.Errorf("failed to parse categories: %w", err)
+			}
+		}
+
+		c.Transaction.Type = txType.String
+		c.Transaction.CheckNumber = checkNum.String
+		setOriginalCurrency(&c.Transaction, originalAmount, originalCurrency)
+		c.Transaction.RefundCategory = refundCategory.String
+
+		classifications = append(classifications, c)
+	}
+
+	return classifications, rows.Err()
+}
+
+// GetClassificationsByConfidence retrieves classifications below a confidence threshold.
+func (s *PostgresStorage) GetClassificationsByConfidence(ctx context.Context, maxConfidence float64, excludeUserModified bool) ([]model.Classification, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	var args pgArgs
+	query := `
+		SELECT
+			t.id, t.hash, t.date, t.name, COALESCE(t.merchant_name, ''),
+			t.amount, t.categories, COALESCE(t.account_id, ''),
+			t.transaction_type, t.check_number,
+			c.category, c.status, c.confidence, c.classified_at, COALESCE(c.notes, ''),
+			c.business_percent
+		FROM classifications c
+		JOIN transactions t ON c.transaction_id = t.id
+		WHERE c.confidence < ` + args.add(maxConfidence)
+	if excludeUserModified {
+		query += ` AND c.status != ` + args.add(string(model.StatusUserModified))
+	}
+	query += ` ORDER BY c.confidence ASC, t.date DESC`
+
+	rows, err := s.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query classifications by confidence: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var classifications []model.Classification
+	for rows.Next() {
+		var c model.Classification
+		var statusStr string
+		var categories sql.NullString
+		var txType sql.NullString
+		var checkNum sql.NullString
+
+		err := rows.Scan(
+			&c.Transaction.ID,
+			&c.Transaction.Hash,
+			&c.Transaction.Date,
+			&c.Transaction.Name,
+			&c.Transaction.MerchantName,
+			&c.Transaction.Amount,
+			&categories,
+			&c.Transaction.AccountID,
+			&txType,
+			&checkNum,
+			&c.Category,
+			&statusStr,
+			&c.Confidence,
+			&c.ClassifiedAt,
+			&c.Notes,
+			&c.BusinessPercent,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan classification: %w", err)
+		}
+
+		c.Status = model.ClassificationStatus(statusStr)
+
+		// Parse categories JSON
+		if categories.Valid && categories.String != "" {
+			if err := json.Unmarshal([]byte(categories.String), &c.Transaction.Category); err != nil {
+				return nil, fmt.Errorf("failed to parse categories: %w", err)
+			}
+		}
+
+		c.Transaction.Type = txType.String
+		c.Transaction.CheckNumber = checkNum.String
+
+		classifications = append(classifications, c)
+	}
+
+	return classifications, rows.Err()
+}
+
+// UpdateBusinessPercentByCategory sets the business percentage on every existing
+// classification in a category and returns the number of classifications updated.
+func (s *PostgresStorage) UpdateBusinessPercentByCategory(ctx context.Context, categoryName string, businessPercent int) (int64, error) {
+	if err := validateContext(ctx); err != nil {
+		return 0, err
+	}
+	if err := validateString(categoryName, "categoryName"); err != nil {
+		return 0, err
+	}
+	if businessPercent < 0 || businessPercent > 100 {
+		return 0, fmt.Errorf("business percentage must be between 0 and 100")
+	}
+
+	// Only expense categories can carry a business percentage
+	var catType sql.NullString
+	err := s.conn().QueryRowContext(ctx, `
+		SELECT type FROM categories WHERE name = $1 AND is_active = TRUE
+	`, categoryName).Scan(&catType)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("category '%s' does not exist", categoryName)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to check category: %w", err)
+	}
+	if catType.Valid && (catType.String == string(model.CategoryTypeIncome) || catType.String == string(model.CategoryTypeSystem)) {
+		return 0, fmt.Errorf("cannot set business percentage for %s categories", catType.String)
+	}
+
+	result, err := s.conn().ExecContext(ctx, `
+		UPDATE classifications SET business_percent = $1 WHERE category = $2
+	`, businessPercent, categoryName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update business percent: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// UpdateBusinessPercentByVendor sets the business percentage on every existing
+// expense classification for a merchant and returns the number updated.
+// Transactions without a merchant name are matched on their raw name.
+func (s *PostgresStorage) UpdateBusinessPercentByVendor(ctx context.Context, merchantName string, businessPercent int) (int64, error) {
+	if err := validateContext(ctx); err != nil {
+		return 0, err
+	}
+	if err := validateString(merchantName, "merchantName"); err != nil {
+		return 0, err
+	}
+	if businessPercent < 0 || businessPercent > 100 {
+		return 0, fmt.Errorf("business percentage must be between 0 and 100")
+	}
+
+	// Income and system classifications never carry a business percentage
+	result, err := s.conn().ExecContext(ctx, `
+		UPDATE classifications
+		SET business_percent = $1
+		WHERE transaction_id IN (
+			SELECT id FROM transactions
+			WHERE merchant_name = $2
+			   OR (COALESCE(merchant_name, '') = '' AND name = $2)
+		)
+		AND category NOT IN (
+			SELECT name FROM categories WHERE type IN ($3, $4)
+		)
+	`, businessPercent, merchantName,
+		string(model.CategoryTypeIncome), string(model.CategoryTypeSystem))
+	if err != nil {
+		return 0, fmt.Errorf("failed to update business percent: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// HasClassificationHistory reports whether any transaction from the merchant has
+// been classified before. Transactions without a merchant name are matched on their raw name.
+func (s *PostgresStorage) HasClassificationHistory(ctx context.Context, merchantName string) (bool, error) {
+	if err := validateContext(ctx); err != nil {
+		return false, err
+	}
+	if err := validateString(merchantName, "merchantName"); err != nil {
+		return false, err
+	}
+
+	var exists bool
+	err := s.conn().QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM classifications c
+			JOIN transactions t ON c.transaction_id = t.id
+			WHERE LOWER(t.merchant_name) = LOWER($1)
+			   OR (COALESCE(t.merchant_name, '') = '' AND LOWER(t.name) = LOWER($1))
+		)
+	`, merchantName).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check classification history: %w", err)
+	}
+
+	return exists, nil
+}
+
+// ClearAllClassifications deletes all classification records and their history.
+func (s *PostgresStorage) ClearAllClassifications(ctx context.Context) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM classifications"); err != nil {
+			return fmt.Errorf("failed to clear classifications: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM classification_history"); err != nil {
+			return fmt.Errorf("failed to clear classification history: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetCategoryChanges returns the transactions whose category changed in the
+// classification history since the given time, oldest change first. Each
+// transaction appears once, going from its category before the window (or
+// its first category in the window) to its latest one; transactions that
+// ended up back where they started are left out.
+func (s *PostgresStorage) GetCategoryChanges(ctx context.Context, since time.Time) ([]model.CategoryChange, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.conn().QueryContext(ctx, `
+		SELECT h.transaction_id, h.category, h.created_at,
+		       (SELECT p.category FROM classification_history p
+		        WHERE p.transaction_id = h.transaction_id AND p.id < h.id
+		        ORDER BY p.id DESC LIMIT 1),
+		       t.date, t.name, COALESCE(t.merchant_name, ''), t.amount, t.direction
+		FROM classification_history h
+		JOIN transactions t ON t.id = h.transaction_id
+		WHERE h.created_at >= $1
+		ORDER BY h.created_at, h.id
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query classification history: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	changes := make(map[string]*model.CategoryChange)
+	var order []string
+	for rows.Next() {
+		var entry model.CategoryChange
+		var previous, direction sql.NullString
+		if err := rows.Scan(
+			&entry.Transaction.ID,
+			&entry.To,
+			&entry.ChangedAt,
+			&previous,
+			&entry.Transaction.Date,
+			&entry.Transaction.Name,
+			&entry.Transaction.MerchantName,
+			&entry.Transaction.Amount,
+			&direction,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan classification history: %w", err)
+		}
+
+		change, seen := changes[entry.Transaction.ID]
+		if !seen {
+			entry.From = entry.To
+			if previous.Valid {
+				entry.From = previous.String
+			}
+			entry.Transaction.Direction = model.TransactionDirection(direction.String)
+			changes[entry.Transaction.ID] = &entry
+			order = append(order, entry.Transaction.ID)
+			continue
+		}
+		if change.To != entry.To {
+			change.To = entry.To
+			change.ChangedAt = entry.ChangedAt
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read classification history: %w", err)
+	}
+
+	result := make([]model.CategoryChange, 0, len(order))
+	for _, id := range order {
+		if change := changes[id]; change.From != change.To {
+			result = append(result, *change)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].ChangedAt.Before(result[j].ChangedAt)
+	})
+
+	return result, nil
+}
+
+// SaveClassificationHistory adds classifications to the classification history
+// without changing any transaction's current classification.
+func (s *PostgresStorage) SaveClassificationHistory(ctx context.Context, classifications []model.Classification) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, classification := range classifications {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO classification_history (
+					transaction_id, category, status, confidence
+				) VALUES ($1, $2, $3, $4)
+			`,
+				classification.Transaction.ID,
+				classification.Category,
+				string(classification.Status),
+				classification.Confidence,
+			); err != nil {
+				return fmt.Errorf("failed to save classification history: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetConfidenceOutcomes returns, for every classified transaction the AI has
+// classified, the confidence and category of its latest AI classification in
+// the history together with its current category.
+func (s *PostgresStorage) GetConfidenceOutcomes(ctx context.Context) ([]model.ConfidenceOutcome, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.conn().QueryContext(ctx, `
+		SELECT h.transaction_id, h.category, h.confidence, c.category
+		FROM classification_history h
+		JOIN classifications c ON c.transaction_id = h.transaction_id
+		WHERE h.id = (SELECT MAX(p.id) FROM classification_history p
+		              WHERE p.transaction_id = h.transaction_id AND p.status = $1)
+		  AND c.status != $2 AND c.category != ''
+		ORDER BY h.id
+	`, string(model.StatusClassifiedByAI), string(model.StatusUnclassified))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query classification history: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var outcomes []model.ConfidenceOutcome
+	for rows.Next() {
+		var outcome model.ConfidenceOutcome
+		var confidence sql.NullFloat64
+		if err := rows.Scan(&outcome.TransactionID, &outcome.AICategory, &confidence, &outcome.FinalCategory); err != nil {
+			return nil, fmt.Errorf("failed to scan classification history: %w", err)
+		}
+		outcome.Confidence = confidence.Float64
+		outcomes = append(outcomes, outcome)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read classification history: %w", err)
+	}
+
+	return outcomes, nil
+}
+
+// FindRefundedPurchase finds the classified purchase a refund most likely reverses:
+// a non-income transaction from the same merchant, dated up to window before the
+// refund, for at least the refunded amount. Exact amount matches win, then the most
+// recent purchase. Returns ErrRefundMatchNotFound when nothing qualifies.
+func (s *PostgresStorage) FindRefundedPurchase(ctx context.Context, refund model.Transaction, window time.Duration) (*model.Classification, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if window < 0 {
+		return nil, fmt.Errorf("refund window cannot be negative")
+	}
+
+	merchant := refund.MerchantName
+	if merchant == "" {
+		merchant = refund.Name
+	}
+	if merchant == "" {
+		return nil, ErrRefundMatchNotFound
+	}
+	amount := math.Abs(refund.Amount)
+
+	var c model.Classification
+	var statusStr string
+	err := s.conn().QueryRowContext(ctx, `
+		SELECT t.id, t.date, t.name, COALESCE(t.merchant_name, ''), t.amount,
+		       c.category, c.status, c.confidence, c.business_percent
+		FROM transactions t
+		JOIN classifications c ON c.transaction_id = t.id
+		WHERE t.id != $1
+		  AND COALESCE(t.direction, '') != $2
+		  AND LOWER(CASE WHEN COALESCE(t.merchant_name, '') = '' THEN t.name ELSE t.merchant_name END) = LOWER($3)
+		  AND t.date >= $4 AND t.date <= $5
+		  AND ABS(t.amount) >= $6 - 0.005
+		ORDER BY ABS(ABS(t.amount) - $6) < 0.005 DESC, t.date DESC
+		LIMIT 1
+	`, refund.ID, string(model.DirectionIncome), merchant,
+		refund.Date.Add(-window), refund.Date, amount).Scan(
+		&c.Transaction.ID,
+		&c.Transaction.Date,
+		&c.Transaction.Name,
+		&c.Transaction.MerchantName,
+		&c.Transaction.Amount,
+		&c.Category,
+		&statusStr,
+		&c.Confidence,
+		&c.BusinessPercent,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRefundMatchNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find refunded purchase: %w", err)
+	}
+
+	c.Status = model.ClassificationStatus(statusStr)
+	return &c, nil
+}
+
+// MarkTransactionRefund flags a transaction as a refund of a purchase in the given category.
+func (s *PostgresStorage) MarkTransactionRefund(ctx context.Context, transactionID, category string) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := validateString(transactionID, "transactionID"); err != nil {
+		return err
+	}
+	if err := validateString(category, "category"); err != nil {
+		return err
+	}
+
+	result, err := s.conn().ExecContext(ctx, `
+		UPDATE transactions SET is_refund = TRUE, refund_category = $1 WHERE id = $2
+	`, category, transactionID)
+	if err != nil {
+		return fmt.Errorf("failed to mark refund: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("transaction %s not found", transactionID)
+	}
+
+	return nil
+}
@@ -0,0 +1,228 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/common"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+// pgMerchantCategoriesCTE is merchantCategoriesCTE for PostgreSQL, which
+// groups merchant names case-insensitively on their lowered spelling.
+const pgMerchantCategoriesCTE = `
+	WITH merchant_categories AS (
+		SELECT
+			MIN(CASE WHEN COALESCE(t.merchant_name, '') = '' THEN t.name ELSE t.merchant_name END) AS merchant,
+			c.category AS category,
+			COUNT(*) AS uses
+		FROM transactions t
+		JOIN classifications c ON c.transaction_id = t.id
+		WHERE c.category != ''
+		GROUP BY LOWER(CASE WHEN COALESCE(t.merchant_name, '') = '' THEN t.name ELSE t.merchant_name END), c.category
+	)`
+
+// SaveMerchantHint creates or replaces the LLM hint for a merchant.
+// Merchant names are matched case-insensitively.
+func (s *PostgresStorage) SaveMerchantHint(ctx context.Context, hint *model.MerchantHint) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if hint == nil {
+		return fmt.Errorf("%w: hint", ErrNilParameter)
+	}
+	if err := validateString(hint.MerchantName, "merchantName"); err != nil {
+		return err
+	}
+	if err := validateString(hint.Hint, "hint"); err != nil {
+		return err
+	}
+	if hint.UpdatedAt.IsZero() {
+		hint.UpdatedAt = time.Now()
+	}
+
+	_, err := s.conn().ExecContext(ctx, `
+		INSERT INTO merchant_hints (merchant_name, hint, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT ((LOWER(merchant_name))) DO UPDATE SET
+			hint = excluded.hint,
+			updated_at = excluded.updated_at
+	`, hint.MerchantName, hint.Hint, hint.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save merchant hint: %w", err)
+	}
+	return nil
+}
+
+// GetMerchantHints returns every merchant hint, ordered by merchant name.
+func (s *PostgresStorage) GetMerchantHints(ctx context.Context) ([]model.MerchantHint, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.conn().QueryContext(ctx, `
+		SELECT merchant_name, hint, updated_at
+		FROM merchant_hints
+		ORDER BY LOWER(merchant_name)
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query merchant hints: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var hints []model.MerchantHint
+	for rows.Next() {
+		var hint model.MerchantHint
+		if err := rows.Scan(&hint.MerchantName, &hint.Hint, &hint.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan merchant hint: %w", err)
+		}
+		hints = append(hints, hint)
+	}
+
+	return hints, rows.Err()
+}
+
+// DeleteMerchantHint removes the hint for a merchant.
+func (s *PostgresStorage) DeleteMerchantHint(ctx context.Context, merchantName string) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := validateString(merchantName, "merchantName"); err != nil {
+		return err
+	}
+
+	result, err := s.conn().ExecContext(ctx, `
+		DELETE FROM merchant_hints WHERE LOWER(merchant_name) = LOWER($1)
+	`, merchantName)
+	if err != nil {
+		return fmt.Errorf("failed to delete merchant hint: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return common.ErrNotFound
+	}
+	return nil
+}
+
+// SaveMerchantEmbeddings creates or replaces merchant embeddings. Merchant
+// names are matched case-insensitively within an embedding model.
+func (s *PostgresStorage) SaveMerchantEmbeddings(ctx context.Context, embeddings []model.MerchantEmbedding) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if len(embeddings) == 0 {
+		return nil
+	}
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, embedding := range embeddings {
+			if err := validateString(embedding.MerchantName, "merchantName"); err != nil {
+				return err
+			}
+			if err := validateString(embedding.Model, "model"); err != nil {
+				return err
+			}
+			if len(embedding.Vector) == 0 {
+				return fmt.Errorf("%w: vector", ErrNilParameter)
+			}
+
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO embeddings (merchant_name, model, vector, created_at)
+				VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+				ON CONFLICT ((LOWER(merchant_name)), model) DO UPDATE SET
+					vector = excluded.vector,
+					created_at = excluded.created_at
+			`, embedding.MerchantName, embedding.Model, encodeVector(embedding.Vector))
+			if err != nil {
+				return fmt.Errorf("failed to save embedding for %q: %w", embedding.MerchantName, err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetMerchantEmbeddings returns every merchant embedding made with a model,
+// each with the merchant's most common category. Merchants that were never
+// classified have an empty category.
+func (s *PostgresStorage) GetMerchantEmbeddings(ctx context.Context, embeddingModel string) ([]model.MerchantEmbedding, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if err := validateString(embeddingModel, "model"); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.conn().QueryContext(ctx, pgMerchantCategoriesCTE+`
+		SELECT e.merchant_name, e.model, e.vector,
+			COALESCE((
+				SELECT mc.category FROM merchant_categories mc
+				WHERE LOWER(mc.merchant) = LOWER(e.merchant_name)
+				ORDER BY mc.uses DESC, mc.category
+				LIMIT 1
+			), '')
+		FROM embeddings e
+		WHERE e.model = $1
+		ORDER BY e.merchant_name
+	`, embeddingModel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query merchant embeddings: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var embeddings []model.MerchantEmbedding
+	for rows.Next() {
+		var embedding model.MerchantEmbedding
+		var vector []byte
+		if err := rows.Scan(&embedding.MerchantName, &embedding.Model, &vector, &embedding.Category); err != nil {
+			return nil, fmt.Errorf("failed to scan merchant embedding: %w", err)
+		}
+		if embedding.Vector, err = decodeVector(vector); err != nil {
+			return nil, fmt.Errorf("invalid embedding for %q: %w", embedding.MerchantName, err)
+		}
+		embeddings = append(embeddings, embedding)
+	}
+
+	return embeddings, rows.Err()
+}
+
+// GetMerchantsWithoutEmbedding returns the classified merchants that have no
+// embedding made with a model, ordered by name.
+func (s *PostgresStorage) GetMerchantsWithoutEmbedding(ctx context.Context, embeddingModel string) ([]string, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if err := validateString(embeddingModel, "model"); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.conn().QueryContext(ctx, pgMerchantCategoriesCTE+`
+		SELECT MIN(mc.merchant) AS merchant
+		FROM merchant_categories mc
+		WHERE NOT EXISTS (
+			SELECT 1 FROM embeddings e
+			WHERE LOWER(e.merchant_name) = LOWER(mc.merchant) AND e.model = $1
+		)
+		GROUP BY LOWER(mc.merchant)
+		ORDER BY merchant
+	`, embeddingModel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query merchants without embedding: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var merchants []string
+	for rows.Next() {
+		var merchant string
+		if err := rows.Scan(&merchant); err != nil {
+			return nil, fmt.Errorf("failed to scan merchant: %w", err)
+		}
+		merchants = append(merchants, merchant)
+	}
+
+	return merchants, rows.Err()
+}
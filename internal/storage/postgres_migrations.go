@@ -0,0 +1,273 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// postgresMigrations are the PostgreSQL counterparts of migrations. Their
+// versions follow the SQLite ones, so a schema change needs a migration in
+// both lists before ExpectedSchemaVersion is raised.
+var postgresMigrations = []Migration{
+	{
+		Version:     32,
+		Description: "Initial schema, equivalent to SQLite schema version 32",
+		Up: func(tx *sql.Tx) error {
+			queries := []string{
+				`CREATE TABLE transactions (
+					id TEXT PRIMARY KEY,
+					hash TEXT UNIQUE NOT NULL,
+					date TIMESTAMPTZ NOT NULL,
+					name TEXT NOT NULL,
+					merchant_name TEXT,
+					amount DOUBLE PRECISION NOT NULL,
+					categories TEXT,
+					account_id TEXT,
+					created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+					transaction_type TEXT,
+					check_number TEXT,
+					direction TEXT,
+					is_refund BOOLEAN DEFAULT FALSE,
+					refund_category TEXT,
+					original_amount DOUBLE PRECISION,
+					original_currency TEXT
+				)`,
+				`CREATE INDEX idx_transactions_date ON transactions(date)`,
+				`CREATE INDEX idx_transactions_merchant ON transactions(merchant_name)`,
+				`CREATE INDEX idx_transactions_type ON transactions(transaction_type)`,
+				`CREATE INDEX idx_transactions_direction ON transactions(direction)`,
+
+				`CREATE TABLE classifications (
+					transaction_id TEXT PRIMARY KEY REFERENCES transactions(id),
+					category TEXT NOT NULL,
+					status TEXT NOT NULL,
+					confidence DOUBLE PRECISION DEFAULT 0,
+					classified_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+					notes TEXT,
+					business_percent DOUBLE PRECISION DEFAULT 0
+				)`,
+				`CREATE INDEX idx_classifications_category ON classifications(category)`,
+
+				`CREATE TABLE classification_history (
+					id BIGSERIAL PRIMARY KEY,
+					transaction_id TEXT NOT NULL REFERENCES transactions(id),
+					category TEXT NOT NULL,
+					status TEXT NOT NULL,
+					confidence DOUBLE PRECISION DEFAULT 0,
+					created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+				)`,
+				`CREATE INDEX idx_classification_history_transaction_id ON classification_history(transaction_id)`,
+
+				`CREATE TABLE vendors (
+					name TEXT PRIMARY KEY,
+					category TEXT NOT NULL,
+					last_updated TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+					use_count INTEGER DEFAULT 0,
+					source TEXT DEFAULT 'AUTO',
+					is_regex BOOLEAN DEFAULT FALSE,
+					multi_category BOOLEAN DEFAULT FALSE
+				)`,
+				`CREATE INDEX idx_vendors_source ON vendors(source)`,
+				`CREATE INDEX idx_vendors_is_regex ON vendors(is_regex)`,
+				`CREATE INDEX idx_vendors_name_lower ON vendors(LOWER(name))`,
+
+				`CREATE TABLE categories (
+					id SERIAL PRIMARY KEY,
+					name TEXT UNIQUE NOT NULL,
+					created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+					is_active BOOLEAN DEFAULT TRUE,
+					description TEXT DEFAULT '',
+					type TEXT,
+					default_business_percent INTEGER DEFAULT 0,
+					always_review BOOLEAN NOT NULL DEFAULT FALSE,
+					vendor_rule_threshold DOUBLE PRECISION NOT NULL DEFAULT 0
+				)`,
+				`CREATE INDEX idx_categories_active ON categories(is_active)`,
+				`CREATE INDEX idx_categories_type ON categories(type)`,
+
+				// SQLite's NOCASE keys become unique indexes on the lowered name
+				`CREATE TABLE category_aliases (
+					alias TEXT NOT NULL,
+					category TEXT NOT NULL,
+					created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+				)`,
+				`CREATE UNIQUE INDEX idx_category_aliases_alias ON category_aliases(LOWER(alias))`,
+
+				`CREATE TABLE category_snapshots (
+					id BIGSERIAL PRIMARY KEY,
+					report TEXT NOT NULL,
+					period_start TIMESTAMPTZ NOT NULL,
+					period_end TIMESTAMPTZ NOT NULL,
+					categories TEXT NOT NULL,
+					created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+				)`,
+
+				`CREATE TABLE merchant_hints (
+					merchant_name TEXT NOT NULL,
+					hint TEXT NOT NULL,
+					updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+				)`,
+				`CREATE UNIQUE INDEX idx_merchant_hints_merchant ON merchant_hints(LOWER(merchant_name))`,
+
+				`CREATE TABLE embeddings (
+					merchant_name TEXT NOT NULL,
+					model TEXT NOT NULL,
+					vector BYTEA NOT NULL,
+					created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+				)`,
+				`CREATE UNIQUE INDEX idx_embeddings_merchant_model ON embeddings(LOWER(merchant_name), model)`,
+
+				`CREATE TABLE check_patterns (
+					id BIGSERIAL PRIMARY KEY,
+					pattern_name TEXT NOT NULL,
+					amount_min DOUBLE PRECISION,
+					amount_max DOUBLE PRECISION,
+					check_number_pattern TEXT,
+					day_of_month_min INTEGER,
+					day_of_month_max INTEGER,
+					category TEXT NOT NULL,
+					notes TEXT,
+					use_count INTEGER DEFAULT 0,
+					amounts TEXT,
+					created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+					updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+					memo_pattern TEXT DEFAULT '',
+					confidence DOUBLE PRECISION DEFAULT 1.0
+				)`,
+				`CREATE INDEX idx_check_patterns_category ON check_patterns(category)`,
+				`CREATE INDEX idx_check_patterns_amount ON check_patterns(amount_min, amount_max)`,
+
+				`CREATE TABLE pattern_rules (
+					id SERIAL PRIMARY KEY,
+					name TEXT NOT NULL,
+					description TEXT,
+					merchant_pattern TEXT,
+					is_regex BOOLEAN DEFAULT FALSE,
+					amount_condition TEXT CHECK(amount_condition IN ('lt', 'le', 'eq', 'ge', 'gt', 'range', 'any')),
+					amount_value DOUBLE PRECISION,
+					amount_min DOUBLE PRECISION,
+					amount_max DOUBLE PRECISION,
+					direction TEXT CHECK(direction IN ('income', 'expense', 'transfer') OR direction IS NULL),
+					default_category TEXT NOT NULL,
+					confidence DOUBLE PRECISION DEFAULT 0.8 CHECK(confidence >= 0 AND confidence <= 1),
+					priority INTEGER DEFAULT 0,
+					is_active BOOLEAN DEFAULT TRUE,
+					created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+					updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+					use_count INTEGER DEFAULT 0
+				)`,
+				`CREATE INDEX idx_pattern_rules_merchant ON pattern_rules(merchant_pattern)`,
+				`CREATE INDEX idx_pattern_rules_category ON pattern_rules(default_category)`,
+				`CREATE INDEX idx_pattern_rules_active ON pattern_rules(is_active)`,
+				`CREATE INDEX idx_pattern_rules_priority ON pattern_rules(priority DESC)`,
+			}
+
+			for _, query := range queries {
+				if _, err := tx.Exec(query); err != nil {
+					return fmt.Errorf("failed to execute query: %w", err)
+				}
+			}
+			return nil
+		},
+	},
+}
+
+// Migrate applies all pending database migrations. The schema version lives
+// in the schema_version table, as PostgreSQL has no PRAGMA user_version.
+func (s *PostgresStorage) Migrate(ctx context.Context) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+
+	if _, err := s.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_version (version INTEGER NOT NULL)
+	`); err != nil {
+		return fmt.Errorf("failed to create schema_version table: %w", err)
+	}
+
+	currentVersion, err := s.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if currentVersion > ExpectedSchemaVersion {
+		return fmt.Errorf("database schema version %d is newer than the latest known version %d", currentVersion, ExpectedSchemaVersion)
+	}
+
+	for _, migration := range postgresMigrations {
+		if migration.Version <= currentVersion || migration.Version > ExpectedSchemaVersion {
+			continue
+		}
+		if err := s.applyPostgresMigration(ctx, migration); err != nil {
+			return err
+		}
+		logAppliedMigration(migration)
+	}
+
+	// Verify we're at the expected schema version
+	finalVersion, err := s.SchemaVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to verify final schema version: %w", err)
+	}
+	if finalVersion != ExpectedSchemaVersion {
+		return fmt.Errorf("database schema version mismatch: expected %d, got %d", ExpectedSchemaVersion, finalVersion)
+	}
+
+	return nil
+}
+
+// SchemaVersion returns the recorded schema version, 0 for a new database.
+func (s *PostgresStorage) SchemaVersion(ctx context.Context) (int, error) {
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, `SELECT to_regclass('schema_version') IS NOT NULL`).Scan(&exists); err != nil {
+		return 0, fmt.Errorf("failed to get schema version: %w", err)
+	}
+	if !exists {
+		return 0, nil
+	}
+
+	var version int
+	err := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get schema version: %w", err)
+	}
+	return version, nil
+}
+
+func (s *PostgresStorage) applyPostgresMigration(ctx context.Context, migration Migration) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Serialize concurrent migrators; the lock is released with the transaction
+	if _, err := tx.ExecContext(ctx, `LOCK TABLE schema_version IN EXCLUSIVE MODE`); err != nil {
+		return fmt.Errorf("failed to lock schema_version: %w", err)
+	}
+
+	var version int
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version); err != nil {
+		return fmt.Errorf("failed to get schema version: %w", err)
+	}
+	if version >= migration.Version {
+		// Another process applied it while we waited for the lock
+		return nil
+	}
+
+	if err := migration.Up(tx); err != nil {
+		return fmt.Errorf("migration %d failed: %w", migration.Version, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM schema_version`); err != nil {
+		return fmt.Errorf("failed to update schema version: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_version (version) VALUES ($1)`, migration.Version); err != nil {
+		return fmt.Errorf("failed to update schema version: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d: %w", migration.Version, err)
+	}
+	return nil
+}
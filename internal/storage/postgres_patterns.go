@@ -0,0 +1,492 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+const pgCheckPatternColumns = `id, pattern_name, amount_min, amount_max, check_number_pattern,
+	day_of_month_min, day_of_month_max, category, notes,
+	use_count, created_at, updated_at, amounts, memo_pattern, confidence`
+
+const pgPatternRuleColumns = `id, name, description, merchant_pattern, is_regex,
+	amount_condition, amount_value, amount_min, amount_max,
+	direction, default_category, confidence, priority, is_active,
+	created_at, updated_at, use_count`
+
+// CreateCheckPattern creates a new check pattern.
+func (s *PostgresStorage) CreateCheckPattern(ctx context.Context, pattern *model.CheckPattern) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if pattern == nil {
+		return fmt.Errorf("pattern cannot be nil")
+	}
+	if err := pattern.Validate(); err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	checkNumberJSON, amountsJSON, err := marshalCheckPatternJSON(pattern)
+	if err != nil {
+		return err
+	}
+
+	var id int64
+	err = s.conn().QueryRowContext(ctx, `
+		INSERT INTO check_patterns (
+			pattern_name, amount_min, amount_max, check_number_pattern,
+			day_of_month_min, day_of_month_max, category, notes, amounts,
+			memo_pattern, confidence
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id`,
+		pattern.PatternName, pattern.AmountMin, pattern.AmountMax, checkNumberJSON,
+		pattern.DayOfMonthMin, pattern.DayOfMonthMax, pattern.Category, pattern.Notes,
+		amountsJSON, pattern.MemoPattern, pattern.EffectiveConfidence(),
+	).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("failed to create check pattern: %w", err)
+	}
+
+	pattern.ID = id
+	pattern.Confidence = pattern.EffectiveConfidence()
+	slog.Info("created check pattern", "id", id, "name", pattern.PatternName)
+	return nil
+}
+
+// GetCheckPattern retrieves a check pattern by ID.
+func (s *PostgresStorage) GetCheckPattern(ctx context.Context, id int64) (*model.CheckPattern, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	row := s.conn().QueryRowContext(ctx, `
+		SELECT `+pgCheckPatternColumns+`
+		FROM check_patterns
+		WHERE id = $1`, id)
+	pattern, err := scanPostgresCheckPattern(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrCheckPatternNotFound
+	}
+	return pattern, err
+}
+
+// GetActiveCheckPatterns returns all check patterns.
+func (s *PostgresStorage) GetActiveCheckPatterns(ctx context.Context) ([]model.CheckPattern, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.conn().QueryContext(ctx, `
+		SELECT `+pgCheckPatternColumns+`
+		FROM check_patterns
+		ORDER BY use_count DESC, pattern_name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query check patterns: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var patterns []model.CheckPattern
+	for rows.Next() {
+		pattern, err := scanPostgresCheckPattern(rows)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, *pattern)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating check patterns: %w", err)
+	}
+	return patterns, nil
+}
+
+// GetMatchingCheckPatterns returns all active patterns that match the given transaction.
+func (s *PostgresStorage) GetMatchingCheckPatterns(ctx context.Context, txn model.Transaction) ([]model.CheckPattern, error) {
+	patterns, err := s.GetActiveCheckPatterns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return matchCheckPatterns(patterns, txn), nil
+}
+
+// UpdateCheckPattern updates an existing check pattern.
+func (s *PostgresStorage) UpdateCheckPattern(ctx context.Context, pattern *model.CheckPattern) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if pattern == nil {
+		return fmt.Errorf("pattern cannot be nil")
+	}
+	if err := pattern.Validate(); err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	checkNumberJSON, amountsJSON, err := marshalCheckPatternJSON(pattern)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.conn().ExecContext(ctx, `
+		UPDATE check_patterns SET
+			pattern_name = $1, amount_min = $2, amount_max = $3,
+			check_number_pattern = $4, day_of_month_min = $5,
+			day_of_month_max = $6, category = $7, notes = $8,
+			amounts = $9, memo_pattern = $10, confidence = $11, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $12`,
+		pattern.PatternName, pattern.AmountMin, pattern.AmountMax, checkNumberJSON,
+		pattern.DayOfMonthMin, pattern.DayOfMonthMax, pattern.Category, pattern.Notes,
+		amountsJSON, pattern.MemoPattern, pattern.EffectiveConfidence(), pattern.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update check pattern: %w", err)
+	}
+
+	if err := requireRowsAffected(result, ErrCheckPatternNotFound); err != nil {
+		return err
+	}
+
+	slog.Info("updated check pattern", "id", pattern.ID, "name", pattern.PatternName)
+	return nil
+}
+
+// DeleteCheckPattern permanently deletes a check pattern.
+func (s *PostgresStorage) DeleteCheckPattern(ctx context.Context, id int64) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+
+	result, err := s.conn().ExecContext(ctx, `DELETE FROM check_patterns WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete check pattern: %w", err)
+	}
+	if err := requireRowsAffected(result, ErrCheckPatternNotFound); err != nil {
+		return err
+	}
+
+	slog.Info("deleted check pattern", "id", id)
+	return nil
+}
+
+// IncrementCheckPatternUseCount increments the use count for a check pattern.
+func (s *PostgresStorage) IncrementCheckPatternUseCount(ctx context.Context, id int64) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+
+	result, err := s.conn().ExecContext(ctx, `
+		UPDATE check_patterns
+		SET use_count = use_count + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to increment use count: %w", err)
+	}
+	return requireRowsAffected(result, ErrCheckPatternNotFound)
+}
+
+// marshalCheckPatternJSON encodes the JSON columns of a check pattern; each is
+// nil when the pattern leaves it unset.
+func marshalCheckPatternJSON(pattern *model.CheckPattern) (checkNumberJSON, amountsJSON *string, err error) {
+	if pattern.CheckNumberPattern != nil {
+		data, err := json.Marshal(pattern.CheckNumberPattern)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal check number pattern: %w", err)
+		}
+		str := string(data)
+		checkNumberJSON = &str
+	}
+	if len(pattern.Amounts) > 0 {
+		data, err := json.Marshal(pattern.Amounts)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal amounts: %w", err)
+		}
+		str := string(data)
+		amountsJSON = &str
+	}
+	return checkNumberJSON, amountsJSON, nil
+}
+
+// scanPostgresCheckPattern scans a row of pgCheckPatternColumns. sql.ErrNoRows
+// is returned as is.
+func scanPostgresCheckPattern(row interface{ Scan(dest ...any) error }) (*model.CheckPattern, error) {
+	pattern := &model.CheckPattern{}
+	var checkNumberJSON sql.NullString
+	var amountsJSON sql.NullString
+	var memoPattern sql.NullString
+	var confidence sql.NullFloat64
+	var notes sql.NullString
+
+	err := row.Scan(
+		&pattern.ID, &pattern.PatternName, &pattern.AmountMin, &pattern.AmountMax,
+		&checkNumberJSON, &pattern.DayOfMonthMin, &pattern.DayOfMonthMax,
+		&pattern.Category, &notes,
+		&pattern.UseCount, &pattern.CreatedAt, &pattern.UpdatedAt, &amountsJSON,
+		&memoPattern, &confidence,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan check pattern: %w", err)
+	}
+
+	if checkNumberJSON.Valid {
+		var matcher model.CheckNumberMatcher
+		if err := json.Unmarshal([]byte(checkNumberJSON.String), &matcher); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal check number pattern: %w", err)
+		}
+		pattern.CheckNumberPattern = &matcher
+	}
+	if amountsJSON.Valid {
+		if err := json.Unmarshal([]byte(amountsJSON.String), &pattern.Amounts); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal amounts: %w", err)
+		}
+	}
+	pattern.Notes = notes.String
+	pattern.MemoPattern = memoPattern.String
+	pattern.Confidence = confidence.Float64
+	pattern.Confidence = pattern.EffectiveConfidence()
+
+	return pattern, nil
+}
+
+// CreatePatternRule creates a new pattern rule.
+func (s *PostgresStorage) CreatePatternRule(ctx context.Context, rule *model.PatternRule) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := validatePatternRule(rule); err != nil {
+		return err
+	}
+	if err := s.requirePatternRuleCategory(ctx, rule.DefaultCategory); err != nil {
+		return err
+	}
+
+	var id int
+	err := s.conn().QueryRowContext(ctx, `
+		INSERT INTO pattern_rules (
+			name, description, merchant_pattern, is_regex,
+			amount_condition, amount_value, amount_min, amount_max,
+			direction, default_category, confidence, priority, is_active
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id`,
+		rule.Name, rule.Description, rule.MerchantPattern, rule.IsRegex,
+		rule.AmountCondition, rule.AmountValue, rule.AmountMin, rule.AmountMax,
+		directionToNullString(rule.Direction), rule.DefaultCategory,
+		rule.Confidence, rule.Priority, rule.IsActive,
+	).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("failed to create pattern rule: %w", err)
+	}
+
+	rule.ID = id
+	rule.CreatedAt = time.Now()
+	rule.UpdatedAt = time.Now()
+	return nil
+}
+
+// GetPatternRule retrieves a pattern rule by ID.
+func (s *PostgresStorage) GetPatternRule(ctx context.Context, id int) (*model.PatternRule, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	row := s.conn().QueryRowContext(ctx, `
+		SELECT `+pgPatternRuleColumns+`
+		FROM pattern_rules
+		WHERE id = $1`, id)
+	rule, err := scanPostgresPatternRule(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("pattern rule not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pattern rule: %w", err)
+	}
+	return rule, nil
+}
+
+// GetActivePatternRules retrieves all active pattern rules ordered by priority.
+func (s *PostgresStorage) GetActivePatternRules(ctx context.Context) ([]model.PatternRule, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	rules, err := s.queryPatternRules(ctx, `
+		SELECT `+pgPatternRuleColumns+`
+		FROM pattern_rules
+		WHERE is_active = TRUE
+		ORDER BY priority DESC, id ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active pattern rules: %w", err)
+	}
+	return rules, nil
+}
+
+// GetAllPatternRules retrieves all pattern rules, including inactive ones.
+func (s *PostgresStorage) GetAllPatternRules(ctx context.Context) ([]model.PatternRule, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	rules, err := s.queryPatternRules(ctx, `
+		SELECT `+pgPatternRuleColumns+`
+		FROM pattern_rules
+		ORDER BY priority DESC, id ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pattern rules: %w", err)
+	}
+	return rules, nil
+}
+
+// GetPatternRulesByCategory retrieves all pattern rules for a specific category.
+func (s *PostgresStorage) GetPatternRulesByCategory(ctx context.Context, category string) ([]model.PatternRule, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if err := validateString(category, "category"); err != nil {
+		return nil, err
+	}
+
+	rules, err := s.queryPatternRules(ctx, `
+		SELECT `+pgPatternRuleColumns+`
+		FROM pattern_rules
+		WHERE default_category = $1
+		ORDER BY priority DESC, id ASC`, category)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pattern rules by category: %w", err)
+	}
+	return rules, nil
+}
+
+// UpdatePatternRule updates an existing pattern rule.
+func (s *PostgresStorage) UpdatePatternRule(ctx context.Context, rule *model.PatternRule) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := validatePatternRule(rule); err != nil {
+		return err
+	}
+	if err := s.requirePatternRuleCategory(ctx, rule.DefaultCategory); err != nil {
+		return err
+	}
+
+	result, err := s.conn().ExecContext(ctx, `
+		UPDATE pattern_rules SET
+			name = $1, description = $2, merchant_pattern = $3, is_regex = $4,
+			amount_condition = $5, amount_value = $6, amount_min = $7, amount_max = $8,
+			direction = $9, default_category = $10, confidence = $11, priority = $12, is_active = $13,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $14`,
+		rule.Name, rule.Description, rule.MerchantPattern, rule.IsRegex,
+		rule.AmountCondition, rule.AmountValue, rule.AmountMin, rule.AmountMax,
+		directionToNullString(rule.Direction), rule.DefaultCategory,
+		rule.Confidence, rule.Priority, rule.IsActive,
+		rule.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update pattern rule: %w", err)
+	}
+	return requireRowsAffected(result, fmt.Errorf("pattern rule not found"))
+}
+
+// DeletePatternRule deletes a pattern rule.
+func (s *PostgresStorage) DeletePatternRule(ctx context.Context, id int) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+
+	result, err := s.conn().ExecContext(ctx, `DELETE FROM pattern_rules WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete pattern rule: %w", err)
+	}
+	return requireRowsAffected(result, fmt.Errorf("pattern rule not found"))
+}
+
+// IncrementPatternRuleUseCount increments the use count for a pattern rule.
+func (s *PostgresStorage) IncrementPatternRuleUseCount(ctx context.Context, id int) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+
+	result, err := s.conn().ExecContext(ctx, `
+		UPDATE pattern_rules SET use_count = use_count + 1 WHERE id = $1
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to increment pattern rule use count: %w", err)
+	}
+	return requireRowsAffected(result, fmt.Errorf("pattern rule not found"))
+}
+
+// requirePatternRuleCategory fails unless category is an active category.
+func (s *PostgresStorage) requirePatternRuleCategory(ctx context.Context, category string) error {
+	var exists bool
+	err := s.conn().QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM categories WHERE name = $1 AND is_active = TRUE)
+	`, category).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to verify category: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("category %q does not exist or is inactive", category)
+	}
+	return nil
+}
+
+func (s *PostgresStorage) queryPatternRules(ctx context.Context, query string, args ...any) ([]model.PatternRule, error) {
+	rows, err := s.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var rules []model.PatternRule
+	for rows.Next() {
+		rule, err := scanPostgresPatternRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pattern rule: %w", err)
+		}
+		rules = append(rules, *rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pattern rules: %w", err)
+	}
+	return rules, nil
+}
+
+// scanPostgresPatternRule scans a row of pgPatternRuleColumns.
+func scanPostgresPatternRule(row interface{ Scan(dest ...any) error }) (*model.PatternRule, error) {
+	var rule model.PatternRule
+	var description, merchantPattern, direction sql.NullString
+	err := row.Scan(
+		&rule.ID, &rule.Name, &description, &merchantPattern, &rule.IsRegex,
+		&rule.AmountCondition, &rule.AmountValue, &rule.AmountMin, &rule.AmountMax,
+		&direction, &rule.DefaultCategory, &rule.Confidence, &rule.Priority, &rule.IsActive,
+		&rule.CreatedAt, &rule.UpdatedAt, &rule.UseCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.Description = description.String
+	rule.MerchantPattern = merchantPattern.String
+	rule.Direction = nullStringToDirection(direction)
+	return &rule, nil
+}
+
+// requireRowsAffected returns notFound when result changed no rows.
+func requireRowsAffected(result sql.Result, notFound error) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return notFound
+	}
+	return nil
+}
@@ -0,0 +1,433 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresMigrations_ReachExpectedSchemaVersion(t *testing.T) {
+	require.NotEmpty(t, postgresMigrations)
+	for i := 1; i < len(postgresMigrations); i++ {
+		assert.Greater(t, postgresMigrations[i].Version, postgresMigrations[i-1].Version,
+			"postgres migrations must be in version order")
+	}
+	assert.Equal(t, ExpectedSchemaVersion, postgresMigrations[len(postgresMigrations)-1].Version,
+		"every SQLite schema change needs a PostgreSQL migration")
+}
+
+func TestNewPostgresStorage_EmptyDSN(t *testing.T) {
+	_, err := NewPostgresStorage("")
+	require.Error(t, err)
+}
+
+// createPostgresTestStorage connects to the database in SPICE_TEST_POSTGRES_DSN
+// and migrates a schema of its own, dropped again when the test ends. Tests
+// using it are skipped when the variable is unset.
+func createPostgresTestStorage(t *testing.T) *PostgresStorage {
+	t.Helper()
+	dsn := os.Getenv("SPICE_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("SPICE_TEST_POSTGRES_DSN not set")
+	}
+
+	admin, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	schema := fmt.Sprintf("spice_test_%d", time.Now().UnixNano())
+	_, err = admin.Exec("CREATE SCHEMA " + schema)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		_ = admin.Close()
+	})
+
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	store, err := NewPostgresStorage(dsn + separator + "search_path=" + schema)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	require.NoError(t, store.Migrate(context.Background()))
+	return store
+}
+
+func TestPostgresStorage_Migrate(t *testing.T) {
+	store := createPostgresTestStorage(t)
+	ctx := context.Background()
+
+	// Migrating again is a no-op
+	require.NoError(t, store.Migrate(ctx))
+	version, err := store.SchemaVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, ExpectedSchemaVersion, version)
+
+	// A newer schema than this build knows is refused
+	_, err = store.DB().Exec(`UPDATE schema_version SET version = $1`, ExpectedSchemaVersion+1)
+	require.NoError(t, err)
+	assert.Error(t, store.Migrate(ctx))
+}
+
+func TestPostgresStorage_TransactionsAndClassifications(t *testing.T) {
+	store := createPostgresTestStorage(t)
+	ctx := context.Background()
+
+	_, err := store.CreateCategory(ctx, "Groceries", "Food for home")
+	require.NoError(t, err)
+
+	txns := createTestTransactions(3)
+	require.NoError(t, store.SaveTransactions(ctx, txns))
+	// Duplicates are ignored
+	require.NoError(t, store.SaveTransactions(ctx, txns))
+
+	count, err := store.GetTransactionCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	toClassify, err := store.GetTransactionsToClassify(ctx, nil)
+	require.NoError(t, err)
+	assert.Len(t, toClassify, 3)
+
+	got, err := store.GetTransactionByID(ctx, txns[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, txns[0].Name, got.Name)
+	assert.Equal(t, txns[0].Category, got.Category)
+
+	// An unknown category is rejected
+	err = store.SaveClassification(ctx, &model.Classification{
+		Transaction: txns[0],
+		Category:    "Nope",
+		Status:      model.StatusClassifiedByAI,
+		Confidence:  0.9,
+	})
+	require.Error(t, err)
+
+	// A user classification creates a vendor rule for the merchant
+	require.NoError(t, store.SaveClassification(ctx, &model.Classification{
+		Transaction: txns[0],
+		Category:    "Groceries",
+		Status:      model.StatusUserModified,
+		Confidence:  1.0,
+	}))
+	vendor, err := store.GetVendor(ctx, txns[0].MerchantName)
+	require.NoError(t, err)
+	assert.Equal(t, "Groceries", vendor.Category)
+	assert.Equal(t, model.SourceAuto, vendor.Source)
+
+	// The user's classification is protected from the AI
+	err = store.SaveClassification(ctx, &model.Classification{
+		Transaction: txns[0],
+		Category:    "Groceries",
+		Status:      model.StatusClassifiedByAI,
+		Confidence:  0.5,
+	})
+	assert.ErrorIs(t, err, ErrUserModified)
+
+	toClassify, err = store.GetTransactionsToClassify(ctx, nil)
+	require.NoError(t, err)
+	assert.Len(t, toClassify, 2)
+
+	start, end := txns[0].Date.Add(-time.Hour), txns[2].Date.Add(time.Hour)
+	classifications, err := store.GetClassificationsByDateRange(ctx, start, end)
+	require.NoError(t, err)
+	require.Len(t, classifications, 1)
+	assert.Equal(t, model.StatusUserModified, classifications[0].Status)
+
+	summary, err := store.GetCategorySummary(ctx, start, end)
+	require.NoError(t, err)
+	assert.InDelta(t, txns[0].Amount, summary["Groceries"], 0.001)
+
+	results, err := store.QueryTransactions(ctx, model.TransactionQuery{Merchant: strings.ToLower(txns[1].MerchantName)})
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+
+	has, err := store.HasClassificationHistory(ctx, strings.ToUpper(txns[0].MerchantName))
+	require.NoError(t, err)
+	assert.True(t, has)
+}
+
+func TestPostgresStorage_VendorMatching(t *testing.T) {
+	store := createPostgresTestStorage(t)
+	ctx := context.Background()
+
+	_, err := store.CreateCategory(ctx, "Shopping", "")
+	require.NoError(t, err)
+	_, err = store.CreateCategory(ctx, "Coffee", "")
+	require.NoError(t, err)
+
+	require.NoError(t, store.SaveVendor(ctx, &model.Vendor{Name: "Amazon", Category: "Shopping", Source: model.SourceManual}))
+	require.NoError(t, store.SaveVendor(ctx, &model.Vendor{Name: "^STARBUCKS.*", Category: "Coffee", IsRegex: true, Source: model.SourceManual}))
+
+	match, err := store.FindVendorMatch(ctx, "AMAZON")
+	require.NoError(t, err)
+	require.NotNil(t, match)
+	assert.Equal(t, "Amazon", match.Name)
+	rule, err := store.GetVendorRule(ctx, "AMAZON")
+	require.NoError(t, err)
+	assert.Equal(t, "Amazon", rule.Name)
+
+	match, err = store.FindVendorMatch(ctx, "STARBUCKS #123")
+	require.NoError(t, err)
+	require.NotNil(t, match)
+	assert.Equal(t, "Coffee", match.Category)
+
+	store.SetVendorCaseSensitive(true)
+	_, err = store.FindVendorMatch(ctx, "AMAZON")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = store.GetVendorRule(ctx, "AMAZON")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	err = store.SaveVendor(ctx, &model.Vendor{Name: "Target", Category: "Missing"})
+	require.Error(t, err)
+
+	vendors, err := store.GetVendorsBySource(ctx, model.SourceManual)
+	require.NoError(t, err)
+	assert.Len(t, vendors, 2)
+
+	require.NoError(t, store.DeleteVendor(ctx, "Amazon"))
+	_, err = store.GetVendor(ctx, "Amazon")
+	assert.Error(t, err)
+}
+
+func TestPostgresStorage_Categories(t *testing.T) {
+	store := createPostgresTestStorage(t)
+	ctx := context.Background()
+
+	food, err := store.CreateCategory(ctx, "Food", "")
+	require.NoError(t, err)
+	dining, err := store.CreateCategoryWithType(ctx, "Dining", "", model.CategoryTypeExpense)
+	require.NoError(t, err)
+	assert.NotZero(t, dining.ID)
+
+	require.NoError(t, store.SaveCategoryAlias(ctx, "Restaurants", "dining"))
+	err = store.SaveCategoryAlias(ctx, "FOOD", "Dining")
+	assert.Error(t, err, "an alias may not be a category name")
+
+	require.NoError(t, store.SetCategoryAlwaysReview(ctx, dining.ID, true))
+	require.Error(t, store.SetCategoryVendorRuleThreshold(ctx, dining.ID, 2))
+
+	require.NoError(t, store.SaveVendor(ctx, &model.Vendor{Name: "Chipotle", Category: "Dining"}))
+	result, err := store.MergeCategories(ctx, dining.ID, food.ID)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, result.Vendors)
+
+	categories, err := store.GetCategories(ctx)
+	require.NoError(t, err)
+	require.Len(t, categories, 1)
+	assert.Equal(t, "Food", categories[0].Name)
+	assert.Equal(t, []string{"Restaurants"}, categories[0].Aliases)
+
+	vendor, err := store.GetVendor(ctx, "Chipotle")
+	require.NoError(t, err)
+	assert.Equal(t, "Food", vendor.Category)
+
+	// Recreating a merged category reactivates it
+	again, err := store.CreateCategory(ctx, "Dining", "")
+	require.NoError(t, err)
+	assert.Equal(t, dining.ID, again.ID)
+
+	snapshot := &model.CategorySnapshot{Report: "monthly", Categories: categories, PeriodStart: time.Now(), PeriodEnd: time.Now()}
+	require.NoError(t, store.SaveCategorySnapshot(ctx, snapshot))
+	saved, err := store.GetCategorySnapshot(ctx, snapshot.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "monthly", saved.Report)
+}
+
+func TestPostgresStorage_PatternRules(t *testing.T) {
+	store := createPostgresTestStorage(t)
+	ctx := context.Background()
+
+	_, err := store.CreateCategory(ctx, "Salary", "")
+	require.NoError(t, err)
+
+	income := model.DirectionIncome
+	rule := &model.PatternRule{
+		Name:            "Payroll",
+		MerchantPattern: "ACME",
+		AmountCondition: "any",
+		Direction:       &income,
+		DefaultCategory: "Salary",
+		Confidence:      0.9,
+		Priority:        5,
+		IsActive:        true,
+	}
+	require.NoError(t, store.CreatePatternRule(ctx, rule))
+	assert.NotZero(t, rule.ID)
+
+	require.NoError(t, store.IncrementPatternRuleUseCount(ctx, rule.ID))
+	got, err := store.GetPatternRule(ctx, rule.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.UseCount)
+	require.NotNil(t, got.Direction)
+	assert.Equal(t, model.DirectionIncome, *got.Direction)
+
+	// Inactive rules are only listed with all of them
+	rule.IsActive = false
+	require.NoError(t, store.UpdatePatternRule(ctx, rule))
+	active, err := store.GetActivePatternRules(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, active)
+	all, err := store.GetAllPatternRules(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.False(t, all[0].IsActive)
+
+	rule.DefaultCategory = "Missing"
+	assert.Error(t, store.UpdatePatternRule(ctx, rule))
+
+	require.NoError(t, store.DeletePatternRule(ctx, rule.ID))
+	_, err = store.GetPatternRule(ctx, rule.ID)
+	assert.Error(t, err)
+
+	minAmount, maxAmount := 100.0, 200.0
+	pattern := &model.CheckPattern{PatternName: "Rent", AmountMin: &minAmount, AmountMax: &maxAmount, Category: "Salary"}
+	require.NoError(t, store.CreateCheckPattern(ctx, pattern))
+	matching, err := store.GetMatchingCheckPatterns(ctx, model.Transaction{Type: "CHECK", Amount: 150})
+	require.NoError(t, err)
+	assert.Len(t, matching, 1)
+
+	require.NoError(t, store.DeleteCheckPattern(ctx, pattern.ID))
+	_, err = store.GetCheckPattern(ctx, pattern.ID)
+	assert.ErrorIs(t, err, ErrCheckPatternNotFound)
+}
+
+func TestPostgresStorage_Transaction(t *testing.T) {
+	store := createPostgresTestStorage(t)
+	ctx := context.Background()
+
+	tx, err := store.BeginTx(ctx)
+	require.NoError(t, err)
+	_, err = tx.CreateCategory(ctx, "Travel", "")
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+
+	_, err = store.GetCategoryByName(ctx, "Travel")
+	assert.True(t, errors.Is(err, ErrCategoryNotFound))
+
+	tx, err = store.BeginTx(ctx)
+	require.NoError(t, err)
+	_, err = tx.CreateCategory(ctx, "Travel", "")
+	require.NoError(t, err)
+	require.NoError(t, tx.SaveTransactions(ctx, createTestTransactions(1)))
+	require.NoError(t, tx.Commit())
+
+	_, err = store.GetCategoryByName(ctx, "Travel")
+	require.NoError(t, err)
+	count, err := store.GetTransactionCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestPostgresStorage_MerchantHintsAndEmbeddings(t *testing.T) {
+	store := createPostgresTestStorage(t)
+	ctx := context.Background()
+
+	require.NoError(t, store.SaveMerchantHint(ctx, &model.MerchantHint{MerchantName: "Costco", Hint: "bulk groceries"}))
+	require.NoError(t, store.SaveMerchantHint(ctx, &model.MerchantHint{MerchantName: "COSTCO", Hint: "warehouse club"}))
+	hints, err := store.GetMerchantHints(ctx)
+	require.NoError(t, err)
+	require.Len(t, hints, 1)
+	assert.Equal(t, "warehouse club", hints[0].Hint)
+
+	vector := []float32{0.25, -1, 3.5}
+	require.NoError(t, store.SaveMerchantEmbeddings(ctx, []model.MerchantEmbedding{
+		{MerchantName: "Costco", Model: "test", Vector: vector},
+	}))
+	embeddings, err := store.GetMerchantEmbeddings(ctx, "test")
+	require.NoError(t, err)
+	require.Len(t, embeddings, 1)
+	assert.Equal(t, vector, embeddings[0].Vector)
+
+	require.NoError(t, store.DeleteMerchantHint(ctx, "costco"))
+}
+
+func TestPostgresStorage_Reports(t *testing.T) {
+	store := createPostgresTestStorage(t)
+	ctx := context.Background()
+
+	_, err := store.CreateCategory(ctx, "Shopping", "")
+	require.NoError(t, err)
+
+	txns := createTestTransactions(4)
+	require.NoError(t, store.SaveTransactions(ctx, txns))
+	require.NoError(t, store.UpdateTransactionDirections(ctx, map[string]model.TransactionDirection{
+		txns[0].ID: model.DirectionExpense,
+		txns[3].ID: model.DirectionIncome,
+	}))
+	since := time.Now().Add(-time.Minute)
+	for _, txn := range txns[:3] {
+		require.NoError(t, store.SaveClassification(ctx, &model.Classification{
+			Transaction: txn,
+			Category:    "Shopping",
+			Status:      model.StatusClassifiedByAI,
+			Confidence:  0.6,
+		}))
+	}
+
+	start, end := txns[0].Date.Add(-time.Hour), txns[3].Date.Add(time.Hour)
+
+	coverage, err := store.GetClassificationCoverage(ctx, start, end)
+	require.NoError(t, err)
+	assert.NotEmpty(t, coverage)
+
+	unclassified, err := store.GetUnclassifiedMerchants(ctx, start, end, 10)
+	require.NoError(t, err)
+	assert.Len(t, unclassified, 1)
+
+	merchants, err := store.GetMerchantSummary(ctx, start, end)
+	require.NoError(t, err)
+	assert.NotEmpty(t, merchants)
+
+	stats, err := store.GetCategoryAmountStats(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, stats, "Shopping")
+
+	lowConfidence, err := store.GetClassificationsByConfidence(ctx, 0.7, true)
+	require.NoError(t, err)
+	assert.Len(t, lowConfidence, 3)
+
+	changes, err := store.GetCategoryChanges(ctx, since)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	require.NoError(t, store.SaveClassificationHistory(ctx, []model.Classification{{
+		Transaction: txns[0], Category: "Food", Status: model.StatusClassifiedByAI, Confidence: 0.9,
+	}}))
+	outcomes, err := store.GetConfidenceOutcomes(ctx)
+	require.NoError(t, err)
+	assert.Len(t, outcomes, 3)
+	for _, outcome := range outcomes {
+		assert.Equal(t, outcome.TransactionID != txns[0].ID, outcome.Correct(), outcome.TransactionID)
+	}
+
+	updated, err := store.UpdateBusinessPercentByCategory(ctx, "Shopping", 50)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, updated)
+
+	_, err = store.RecomputeVendorUseCounts(ctx)
+	require.NoError(t, err)
+
+	refund := txns[3]
+	refund.MerchantName = txns[0].MerchantName
+	refund.Amount = txns[0].Amount
+	refund.Date = txns[0].Date.Add(24 * time.Hour)
+	purchase, err := store.FindRefundedPurchase(ctx, refund, 30*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, txns[0].ID, purchase.Transaction.ID)
+	require.NoError(t, store.MarkTransactionRefund(ctx, refund.ID, purchase.Category))
+
+	require.NoError(t, store.ClearAllClassifications(ctx))
+	toClassify, err := store.GetTransactionsToClassify(ctx, nil)
+	require.NoError(t, err)
+	assert.Len(t, toClassify, 4)
+}
@@ -0,0 +1,790 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/common"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+// pgTransactionColumns are the transaction columns scanned by scanPostgresTransactions.
+const pgTransactionColumns = `
+	t.id, t.hash, t.date, t.name, COALESCE(t.merchant_name, ''),
+	t.amount, t.categories, COALESCE(t.account_id, ''),
+	t.transaction_type, t.check_number, t.direction`
+
+// SaveTransactions saves multiple transactions to the database. Transactions
+// whose ID or hash is already stored are skipped.
+func (s *PostgresStorage) SaveTransactions(ctx context.Context, transactions []model.Transaction) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := validateTransactions(transactions); err != nil {
+		return err
+	}
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, `
+			INSERT INTO transactions (
+				id, hash, date, name, merchant_name, amount,
+				categories, account_id, transaction_type, check_number, direction,
+				original_amount, original_currency
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			ON CONFLICT DO NOTHING
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to prepare statement: %w", err)
+		}
+		defer func() { _ = stmt.Close() }()
+
+		for _, txn := range transactions {
+			// Generate hash if not already set
+			if txn.Hash == "" {
+				txn.Hash = txn.GenerateHash()
+			}
+
+			// Convert categories slice to JSON string
+			categoriesJSON := ""
+			if len(txn.Category) > 0 {
+				categoriesBytes, marshalErr := json.Marshal(txn.Category)
+				if marshalErr == nil {
+					categoriesJSON = string(categoriesBytes)
+				}
+			}
+
+			originalAmount, originalCurrency := originalCurrencyValues(txn)
+			if _, err := stmt.ExecContext(ctx,
+				txn.ID,
+				txn.Hash,
+				txn.Date,
+				txn.Name,
+				txn.MerchantName,
+				txn.Amount,
+				categoriesJSON,
+				txn.AccountID,
+				txn.Type,
+				txn.CheckNumber,
+				string(txn.Direction),
+				originalAmount,
+				originalCurrency,
+			); err != nil {
+				return fmt.Errorf("failed to insert transaction %s: %w", txn.ID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetTransactionsToClassify retrieves unclassified transactions.
+func (s *PostgresStorage) GetTransactionsToClassify(ctx context.Context, fromDate *time.Time) ([]model.Transaction, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	var args pgArgs
+	query := `
+		SELECT ` + pgTransactionColumns + `
+		FROM transactions t
+		LEFT JOIN classifications c ON t.id = c.transaction_id
+		WHERE c.transaction_id IS NULL`
+	if fromDate != nil {
+		query += " AND t.date > " + args.add(*fromDate)
+	}
+	query += " ORDER BY t.date ASC"
+
+	rows, err := s.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transactions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanPostgresTransactions(rows)
+}
+
+// GetTransactionByID retrieves a single transaction by ID.
+func (s *PostgresStorage) GetTransactionByID(ctx context.Context, id string) (*model.Transaction, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if err := validateString(id, "id"); err != nil {
+		return nil, err
+	}
+	return s.getTransactionWhere(ctx, "id", id)
+}
+
+// GetTransactionByHash retrieves a single transaction by its duplicate detection hash.
+func (s *PostgresStorage) GetTransactionByHash(ctx context.Context, hash string) (*model.Transaction, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if err := validateString(hash, "hash"); err != nil {
+		return nil, err
+	}
+	return s.getTransactionWhere(ctx, "hash", hash)
+}
+
+// getTransactionWhere retrieves the transaction whose column equals value.
+// column must be a unique column name, never user input.
+func (s *PostgresStorage) getTransactionWhere(ctx context.Context, column, value string) (*model.Transaction, error) {
+	var txn model.Transaction
+	var categories sql.NullString
+	var originalAmount sql.NullFloat64
+	var originalCurrency sql.NullString
+
+	err := s.conn().QueryRowContext(ctx, `
+		SELECT id, hash, date, name, COALESCE(merchant_name, ''),
+		       amount, categories, COALESCE(account_id, ''),
+		       original_amount, original_currency
+		FROM transactions
+		WHERE `+column+` = $1
+	`, value).Scan(
+		&txn.ID,
+		&txn.Hash,
+		&txn.Date,
+		&txn.Name,
+		&txn.MerchantName,
+		&txn.Amount,
+		&categories,
+		&txn.AccountID,
+		&originalAmount,
+		&originalCurrency,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, common.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	// Parse categories JSON
+	if categories.Valid && categories.String != "" {
+		if err := json.Unmarshal([]byte(categories.String), &txn.Category); err != nil {
+			return nil, fmt.Errorf("failed to parse categories: %w", err)
+		}
+	}
+
+	setOriginalCurrency(&txn, originalAmount, originalCurrency)
+
+	return &txn, nil
+}
+
+// GetTransactionsByCategory retrieves all transactions for a specific category.
+func (s *PostgresStorage) GetTransactionsByCategory(ctx context.Context, categoryName string) ([]model.Transaction, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if err := validateString(categoryName, "categoryName"); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.conn().QueryContext(ctx, `
+		SELECT `+pgTransactionColumns+`
+		FROM transactions t
+		JOIN classifications c ON t.id = c.transaction_id
+		WHERE c.category = $1
+		ORDER BY t.date DESC
+	`, categoryName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transactions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanPostgresTransactions(rows)
+}
+
+// GetTransactionsByCategoryID retrieves all transactions for a specific category ID.
+func (s *PostgresStorage) GetTransactionsByCategoryID(ctx context.Context, categoryID int) ([]model.Transaction, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	categoryName, err := s.categoryNameByID(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetTransactionsByCategory(ctx, categoryName)
+}
+
+// UpdateTransactionCategories moves all classifications from one category to another.
+func (s *PostgresStorage) UpdateTransactionCategories(ctx context.Context, fromCategory, toCategory string) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := validateString(fromCategory, "fromCategory"); err != nil {
+		return err
+	}
+	if err := validateString(toCategory, "toCategory"); err != nil {
+		return err
+	}
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := requireActiveCategory(ctx, tx, toCategory); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE classifications SET category = $1 WHERE category = $2
+		`, toCategory, fromCategory); err != nil {
+			return fmt.Errorf("failed to update classifications: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpdateTransactionCategoriesByID moves all classifications from one category ID to another.
+func (s *PostgresStorage) UpdateTransactionCategoriesByID(ctx context.Context, fromCategoryID, toCategoryID int) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+
+	fromCategory, err := s.categoryNameByID(ctx, fromCategoryID)
+	if err != nil {
+		return err
+	}
+	toCategory, err := s.categoryNameByID(ctx, toCategoryID)
+	if err != nil {
+		return err
+	}
+
+	return s.UpdateTransactionCategories(ctx, fromCategory, toCategory)
+}
+
+// UpdateTransactionDirections sets the direction of each transaction in the map,
+// keyed by transaction ID, in a single database transaction.
+func (s *PostgresStorage) UpdateTransactionDirections(ctx context.Context, directions map[string]model.TransactionDirection) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := validateDirections(directions); err != nil {
+		return err
+	}
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for id, direction := range directions {
+			result, err := tx.ExecContext(ctx, `UPDATE transactions SET direction = $1 WHERE id = $2`, string(direction), id)
+			if err != nil {
+				return fmt.Errorf("failed to update direction of transaction %s: %w", id, err)
+			}
+			rows, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("failed to get rows affected: %w", err)
+			}
+			if rows == 0 {
+				return fmt.Errorf("transaction %s not found", id)
+			}
+		}
+		return nil
+	})
+}
+
+// GetTransactionCount returns the total number of transactions.
+func (s *PostgresStorage) GetTransactionCount(ctx context.Context) (int, error) {
+	if err := validateContext(ctx); err != nil {
+		return 0, err
+	}
+
+	var count int
+	if err := s.conn().QueryRowContext(ctx, `SELECT COUNT(*) FROM transactions`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to get transaction count: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetTransactionCountByCategory returns the number of transactions for a specific category.
+func (s *PostgresStorage) GetTransactionCountByCategory(ctx context.Context, categoryName string) (int, error) {
+	if err := validateContext(ctx); err != nil {
+		return 0, err
+	}
+	if err := validateString(categoryName, "categoryName"); err != nil {
+		return 0, err
+	}
+
+	var count int
+	err := s.conn().QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM transactions t
+		JOIN classifications c ON t.id = c.transaction_id
+		WHERE c.category = $1
+	`, categoryName).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get transaction count by category: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetEarliestTransactionDate returns the date of the earliest transaction.
+func (s *PostgresStorage) GetEarliestTransactionDate(ctx context.Context) (time.Time, error) {
+	if err := validateContext(ctx); err != nil {
+		return time.Time{}, err
+	}
+	return s.transactionDateBound(ctx, "MIN", "earliest")
+}
+
+// GetLatestTransactionDate returns the date of the latest transaction.
+func (s *PostgresStorage) GetLatestTransactionDate(ctx context.Context) (time.Time, error) {
+	if err := validateContext(ctx); err != nil {
+		return time.Time{}, err
+	}
+	return s.transactionDateBound(ctx, "MAX", "latest")
+}
+
+// transactionDateBound returns MIN or MAX of the transaction dates, or
+// common.ErrNotFound when there are no transactions.
+func (s *PostgresStorage) transactionDateBound(ctx context.Context, aggregate, label string) (time.Time, error) {
+	var date sql.NullTime
+	if err := s.conn().QueryRowContext(ctx, `SELECT `+aggregate+`(date) FROM transactions`).Scan(&date); err != nil {
+		return time.Time{}, fmt.Errorf("failed to get %s transaction date: %w", label, err)
+	}
+	if !date.Valid {
+		return time.Time{}, common.ErrNotFound
+	}
+	return date.Time, nil
+}
+
+// GetCategorySummary returns a summary of transaction amounts by category for a date range.
+func (s *PostgresStorage) GetCategorySummary(ctx context.Context, start, end time.Time) (map[string]float64, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.conn().QueryContext(ctx, `
+		SELECT c.category, SUM(t.amount) AS total
+		FROM transactions t
+		JOIN classifications c ON t.id = c.transaction_id
+		WHERE t.date >= $1 AND t.date <= $2
+		GROUP BY c.category
+		ORDER BY total DESC
+	`, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query category summary: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	summary := make(map[string]float64)
+	for rows.Next() {
+		var category string
+		var total float64
+		if err := rows.Scan(&category, &total); err != nil {
+			return nil, fmt.Errorf("failed to scan category summary: %w", err)
+		}
+		summary[category] = total
+	}
+
+	return summary, rows.Err()
+}
+
+// GetMerchantSummary returns a summary of transaction amounts by merchant for a date range.
+func (s *PostgresStorage) GetMerchantSummary(ctx context.Context, start, end time.Time) (map[string]float64, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.conn().QueryContext(ctx, `
+		SELECT merchant_name, SUM(amount) AS total
+		FROM transactions
+		WHERE date >= $1 AND date <= $2 AND merchant_name != ''
+		GROUP BY merchant_name
+		ORDER BY total DESC
+	`, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query merchant summary: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	summary := make(map[string]float64)
+	for rows.Next() {
+		var merchant string
+		var total float64
+		if err := rows.Scan(&merchant, &total); err != nil {
+			return nil, fmt.Errorf("failed to scan merchant summary: %w", err)
+		}
+		summary[merchant] = total
+	}
+
+	return summary, rows.Err()
+}
+
+// GetClassificationCoverage counts classified, pending and skipped transactions
+// per month between start and end, oldest month first. A zero start or end
+// leaves that side of the range open.
+func (s *PostgresStorage) GetClassificationCoverage(ctx context.Context, start, end time.Time) ([]model.CoverageMonth, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	var args pgArgs
+	skipped := args.add(string(model.StatusUnclassified))
+	conditions := pgDateConditions(&args, start, end)
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// Months are taken in UTC, as SQLite's strftime does
+	rows, err := s.conn().QueryContext(ctx, `
+		SELECT to_char(t.date AT TIME ZONE 'UTC', 'YYYY-MM') AS month,
+		       COUNT(*),
+		       SUM(CASE WHEN c.transaction_id IS NULL THEN 1 ELSE 0 END),
+		       SUM(CASE WHEN c.status = `+skipped+` THEN 1 ELSE 0 END)
+		FROM transactions t
+		LEFT JOIN classifications c ON t.id = c.transaction_id
+		`+where+`
+		GROUP BY month
+		ORDER BY month
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query classification coverage: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var months []model.CoverageMonth
+	for rows.Next() {
+		var m model.CoverageMonth
+		if err := rows.Scan(&m.Month, &m.Total, &m.Pending, &m.Skipped); err != nil {
+			return nil, fmt.Errorf("failed to scan classification coverage: %w", err)
+		}
+		m.Classified = m.Total - m.Pending - m.Skipped
+		months = append(months, m)
+	}
+
+	return months, rows.Err()
+}
+
+// GetUnclassifiedMerchants returns the merchants with the most transactions
+// lacking a category between start and end, largest amount first on ties.
+// A zero start or end leaves that side of the range open; limit <= 0 returns all.
+func (s *PostgresStorage) GetUnclassifiedMerchants(ctx context.Context, start, end time.Time, limit int) ([]model.UnclassifiedMerchant, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	var args pgArgs
+	conditions := pgDateConditions(&args, start, end)
+	conditions = append(conditions, "(c.transaction_id IS NULL OR c.status = "+args.add(string(model.StatusUnclassified))+")")
+
+	query := `
+		SELECT COALESCE(NULLIF(t.merchant_name, ''), t.name) AS merchant,
+		       COUNT(*),
+		       SUM(CASE WHEN c.transaction_id IS NULL THEN 1 ELSE 0 END),
+		       SUM(ABS(t.amount))
+		FROM transactions t
+		LEFT JOIN classifications c ON t.id = c.transaction_id
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		GROUP BY merchant
+		ORDER BY COUNT(*) DESC, SUM(ABS(t.amount)) DESC, merchant
+	`
+	if limit > 0 {
+		query += " LIMIT " + args.add(limit)
+	}
+
+	rows, err := s.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unclassified merchants: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var merchants []model.UnclassifiedMerchant
+	for rows.Next() {
+		var m model.UnclassifiedMerchant
+		if err := rows.Scan(&m.Merchant, &m.Count, &m.Pending, &m.Amount); err != nil {
+			return nil, fmt.Errorf("failed to scan unclassified merchant: %w", err)
+		}
+		m.Skipped = m.Count - m.Pending
+		merchants = append(merchants, m)
+	}
+
+	return merchants, rows.Err()
+}
+
+// GetCategoryAmountStats returns the count, average and largest amount of the
+// transactions classified in each category, keyed by category name. Refunds are
+// left out so they don't skew their purchase category.
+func (s *PostgresStorage) GetCategoryAmountStats(ctx context.Context) (map[string]model.CategoryAmountStats, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.conn().QueryContext(ctx, `
+		SELECT c.category, COUNT(*), AVG(ABS(t.amount)), MAX(ABS(t.amount))
+		FROM transactions t
+		JOIN classifications c ON t.id = c.transaction_id
+		WHERE c.status != $1 AND c.category IS NOT NULL AND c.category != ''
+			AND COALESCE(t.is_refund, FALSE) = FALSE
+		GROUP BY c.category
+	`, string(model.StatusUnclassified))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query category amount stats: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	stats := make(map[string]model.CategoryAmountStats)
+	for rows.Next() {
+		var st model.CategoryAmountStats
+		if err := rows.Scan(&st.Category, &st.Count, &st.Average, &st.Max); err != nil {
+			return nil, fmt.Errorf("failed to scan category amount stats: %w", err)
+		}
+		stats[st.Category] = st
+	}
+
+	return stats, rows.Err()
+}
+
+// pgDateConditions returns the conditions for an optional date range on t.date.
+func pgDateConditions(args *pgArgs, start, end time.Time) []string {
+	var conditions []string
+	if !start.IsZero() {
+		conditions = append(conditions, "t.date >= "+args.add(start))
+	}
+	if !end.IsZero() {
+		conditions = append(conditions, "t.date <= "+args.add(end))
+	}
+	return conditions
+}
+
+// QueryTransactions returns transactions matching the query along with their
+// classification. Unclassified transactions have StatusUnclassified and no category.
+func (s *PostgresStorage) QueryTransactions(ctx context.Context, query model.TransactionQuery) ([]model.Classification, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if err := query.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid transaction query: %w", err)
+	}
+
+	sqlQuery, args := buildPostgresTransactionQuery(query)
+
+	rows, err := s.conn().QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transactions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var results []model.Classification
+	for rows.Next() {
+		var c model.Classification
+		var categories sql.NullString
+		var txType sql.NullString
+		var checkNum sql.NullString
+		var direction sql.NullString
+		var originalAmount sql.NullFloat64
+		var originalCurrency sql.NullString
+		var category sql.NullString
+		var status sql.NullString
+		var confidence sql.NullFloat64
+		var classifiedAt sql.NullTime
+		var notes sql.NullString
+		var businessPercent sql.NullFloat64
+
+		err := rows.Scan(
+			&c.Transaction.ID,
+			&c.Transaction.Hash,
+			&c.Transaction.Date,
+			&c.Transaction.Name,
+			&c.Transaction.MerchantName,
+			&c.Transaction.Amount,
+			&categories,
+			&c.Transaction.AccountID,
+			&txType,
+			&checkNum,
+			&direction,
+			&originalAmount,
+			&originalCurrency,
+			&category,
+			&status,
+			&confidence,
+			&classifiedAt,
+			&notes,
+			&businessPercent,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+
+		if categories.Valid && categories.String != "" {
+			if err := json.Unmarshal([]byte(categories.String), &c.Transaction.Category); err != nil {
+				slog.Warn("Failed to parse categories JSON", "error", err, "json", categories.String)
+			}
+		}
+		c.Transaction.Type = txType.String
+		c.Transaction.CheckNumber = checkNum.String
+		c.Transaction.Direction = model.TransactionDirection(direction.String)
+		setOriginalCurrency(&c.Transaction, originalAmount, originalCurrency)
+
+		c.Status = model.StatusUnclassified
+		if status.Valid {
+			c.Status = model.ClassificationStatus(status.String)
+			c.Category = category.String
+			c.Confidence = confidence.Float64
+			c.ClassifiedAt = classifiedAt.Time
+			c.Notes = notes.String
+			c.BusinessPercent = businessPercent.Float64
+		}
+
+		results = append(results, c)
+	}
+
+	return results, rows.Err()
+}
+
+// buildPostgresTransactionQuery is buildTransactionQuery for PostgreSQL: numbered
+// placeholders, and LOWER() where SQLite compares with NOCASE.
+func buildPostgresTransactionQuery(query model.TransactionQuery) (string, []any) {
+	var where []string
+	var args pgArgs
+
+	if query.StartDate != nil {
+		where = append(where, "t.date >= "+args.add(*query.StartDate))
+	}
+	if query.EndDate != nil {
+		where = append(where, "t.date <= "+args.add(*query.EndDate))
+	}
+	if query.MinAmount != nil {
+		where = append(where, "ABS(t.amount) >= "+args.add(*query.MinAmount))
+	}
+	if query.MaxAmount != nil {
+		where = append(where, "ABS(t.amount) <= "+args.add(*query.MaxAmount))
+	}
+	if query.Category != "" {
+		where = append(where, "LOWER(c.category) = LOWER("+args.add(query.Category)+")")
+	}
+	if query.Merchant != "" {
+		// SQLite's LIKE ignores ASCII case; ILIKE keeps that behavior
+		pattern := args.add("%" + escapeLike(query.Merchant) + "%")
+		where = append(where, `(t.merchant_name ILIKE `+pattern+` ESCAPE '\' OR t.name ILIKE `+pattern+` ESCAPE '\')`)
+	}
+	if query.Direction != "" {
+		where = append(where, "t.direction = "+args.add(string(query.Direction)))
+	}
+	if query.MissingDirection {
+		where = append(where, "COALESCE(t.direction, '') = ''")
+	}
+	if query.Status != "" {
+		where = append(where, "COALESCE(c.status, "+args.add(string(model.StatusUnclassified))+") = "+args.add(string(query.Status)))
+	}
+
+	sqlQuery := `
+		SELECT
+			t.id, t.hash, t.date, t.name, COALESCE(t.merchant_name, ''),
+			t.amount, t.categories, COALESCE(t.account_id, ''),
+			t.transaction_type, t.check_number, t.direction,
+			t.original_amount, t.original_currency,
+			c.category, c.status, c.confidence, c.classified_at, c.notes,
+			c.business_percent
+		FROM transactions t
+		LEFT JOIN classifications c ON c.transaction_id = t.id
+	`
+	if len(where) > 0 {
+		sqlQuery += " WHERE " + strings.Join(where, " AND ")
+	}
+
+	order := "ASC"
+	if query.Descending {
+		order = "DESC"
+	}
+	var sortColumn string
+	switch query.SortBy {
+	case model.SortByAmount:
+		sortColumn = "ABS(t.amount)"
+	case model.SortByMerchant:
+		sortColumn = "LOWER(t.merchant_name)"
+	case model.SortByCategory:
+		sortColumn = "LOWER(c.category)"
+	default:
+		sortColumn = "t.date"
+	}
+	// SQLite sorts NULLs first ascending; match it, and tie-break on date and id
+	nulls := "NULLS FIRST"
+	if query.Descending {
+		nulls = "NULLS LAST"
+	}
+	sqlQuery += fmt.Sprintf(" ORDER BY %s %s %s, t.date %s, t.id", sortColumn, order, nulls, order)
+
+	if query.Limit > 0 {
+		sqlQuery += " LIMIT " + args.add(query.Limit)
+	}
+
+	return sqlQuery, args
+}
+
+// scanPostgresTransactions scans rows selected with pgTransactionColumns.
+func scanPostgresTransactions(rows *sql.Rows) ([]model.Transaction, error) {
+	var transactions []model.Transaction
+	for rows.Next() {
+		var txn model.Transaction
+		var categoriesJSON sql.NullString
+		var txType sql.NullString
+		var checkNum sql.NullString
+		var direction sql.NullString
+
+		if err := rows.Scan(
+			&txn.ID,
+			&txn.Hash,
+			&txn.Date,
+			&txn.Name,
+			&txn.MerchantName,
+			&txn.Amount,
+			&categoriesJSON,
+			&txn.AccountID,
+			&txType,
+			&checkNum,
+			&direction,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+
+		// Parse categories JSON
+		if categoriesJSON.Valid && categoriesJSON.String != "" {
+			if err := json.Unmarshal([]byte(categoriesJSON.String), &txn.Category); err != nil {
+				// Log but don't fail on JSON parse error
+				slog.Warn("Failed to parse categories JSON", "error", err, "json", categoriesJSON.String)
+			}
+		}
+
+		txn.Type = txType.String
+		txn.CheckNumber = checkNum.String
+		txn.Direction = model.TransactionDirection(direction.String)
+
+		transactions = append(transactions, txn)
+	}
+
+	return transactions, rows.Err()
+}
+
+// categoryNameByID returns the name of the category with the given ID,
+// active or not.
+func (s *PostgresStorage) categoryNameByID(ctx context.Context, categoryID int) (string, error) {
+	var name string
+	err := s.conn().QueryRowContext(ctx, `SELECT name FROM categories WHERE id = $1`, categoryID).Scan(&name)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("category with ID %d not found", categoryID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get category name: %w", err)
+	}
+	return name, nil
+}
+
+// requireActiveCategory returns an error unless an active category has the given name.
+func requireActiveCategory(ctx context.Context, q queryable, name string) error {
+	var exists bool
+	err := q.QueryRowContext(ctx, `
+		SELECT EXISTS(SELECT 1 FROM categories WHERE name = $1 AND is_active = TRUE)
+	`, name).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check category existence: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("category '%s' does not exist", name)
+	}
+	return nil
+}
@@ -0,0 +1,563 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/common"
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+// pgVendorColumns are the vendor columns scanned by scanPostgresVendor.
+const pgVendorColumns = `name, category, last_updated, use_count, source, is_regex, multi_category`
+
+// GetVendor retrieves a vendor by name. It returns sql.ErrNoRows when there is none.
+func (s *PostgresStorage) GetVendor(ctx context.Context, merchantName string) (*model.Vendor, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if err := validateString(merchantName, "merchantName"); err != nil {
+		return nil, err
+	}
+
+	// Check cache first
+	if vendor := s.vendorCache.get(merchantName); vendor != nil {
+		return vendor, nil
+	}
+
+	return s.getVendor(ctx, s.conn(), merchantName)
+}
+
+// GetVendorRule retrieves the exact vendor rule of a merchant: the vendor of
+// that name or, unless vendor matching is case sensitive, of another spelling.
+func (s *PostgresStorage) GetVendorRule(ctx context.Context, merchantName string) (*model.Vendor, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if err := validateString(merchantName, "merchantName"); err != nil {
+		return nil, err
+	}
+	return s.getVendorRule(ctx, s.conn(), merchantName)
+}
+
+func (s *PostgresStorage) getVendor(ctx context.Context, q queryable, merchantName string) (*model.Vendor, error) {
+	vendor, err := scanPostgresVendor(q.QueryRowContext(ctx, `
+		SELECT `+pgVendorColumns+` FROM vendors WHERE name = $1
+	`, merchantName))
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows // Not an error, just not found
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vendor: %w", err)
+	}
+
+	s.vendorCache.put(vendor)
+	return vendor, nil
+}
+
+// SaveVendor saves or updates a vendor rule.
+func (s *PostgresStorage) SaveVendor(ctx context.Context, vendor *model.Vendor) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := validateVendor(vendor); err != nil {
+		return err
+	}
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		return s.saveVendorTx(ctx, tx, vendor)
+	})
+}
+
+func (s *PostgresStorage) saveVendorTx(ctx context.Context, tx *sql.Tx, vendor *model.Vendor) error {
+	// Set LastUpdated if not set
+	if vendor.LastUpdated.IsZero() {
+		vendor.LastUpdated = time.Now()
+	}
+
+	// Set default source if not set
+	if vendor.Source == "" {
+		vendor.Source = model.SourceAuto
+	}
+
+	// Without case-sensitive matching "AMAZON" and "Amazon" share one rule, so
+	// update an existing rule under its own spelling rather than adding a twin
+	if !vendor.IsRegex && !s.vendorCaseSensitive {
+		var existing string
+		err := tx.QueryRowContext(ctx, `
+			SELECT name FROM vendors WHERE LOWER(name) = LOWER($1) AND is_regex = FALSE
+			ORDER BY name = $1 DESC, use_count DESC, name LIMIT 1
+		`, vendor.Name).Scan(&existing)
+		switch {
+		case err == nil:
+			vendor.Name = existing
+		case err != sql.ErrNoRows:
+			return fmt.Errorf("failed to check vendor: %w", err)
+		}
+	}
+
+	if err := requireActiveCategory(ctx, tx, vendor.Category); err != nil {
+		return err
+	}
+
+	// A multi-category merchant must not get a rule through an ordinary save
+	multiCategory, err := s.isMultiCategoryVendor(ctx, tx, vendor.Name)
+	if err != nil {
+		return err
+	}
+	if multiCategory {
+		return fmt.Errorf("%w: %s", ErrMultiCategoryVendor, vendor.Name)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO vendors (name, category, last_updated, use_count, source, is_regex)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (name) DO UPDATE SET
+			category = excluded.category,
+			last_updated = excluded.last_updated,
+			use_count = excluded.use_count,
+			source = excluded.source,
+			is_regex = excluded.is_regex
+	`, vendor.Name, vendor.Category, vendor.LastUpdated, vendor.UseCount, string(vendor.Source), vendor.IsRegex)
+	if err != nil {
+		return fmt.Errorf("failed to save vendor: %w", err)
+	}
+
+	s.vendorCache.put(vendor)
+	return nil
+}
+
+// GetAllVendors retrieves all vendor rules.
+func (s *PostgresStorage) GetAllVendors(ctx context.Context) ([]model.Vendor, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	return s.queryVendors(ctx, s.conn(), `SELECT `+pgVendorColumns+` FROM vendors ORDER BY name`)
+}
+
+// DeleteVendor deletes a vendor rule.
+func (s *PostgresStorage) DeleteVendor(ctx context.Context, merchantName string) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := validateString(merchantName, "merchantName"); err != nil {
+		return err
+	}
+
+	result, err := s.conn().ExecContext(ctx, `DELETE FROM vendors WHERE name = $1`, merchantName)
+	if err != nil {
+		return fmt.Errorf("failed to delete vendor: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return common.ErrNotFound
+	}
+
+	s.vendorCache.remove(merchantName)
+	return nil
+}
+
+// GetVendorsByCategory retrieves all vendors for a specific category.
+func (s *PostgresStorage) GetVendorsByCategory(ctx context.Context, categoryName string) ([]model.Vendor, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if err := validateString(categoryName, "categoryName"); err != nil {
+		return nil, err
+	}
+	return s.queryVendors(ctx, s.conn(), `
+		SELECT `+pgVendorColumns+` FROM vendors WHERE category = $1 ORDER BY name
+	`, categoryName)
+}
+
+// GetVendorsByCategoryID retrieves all vendors for a specific category ID.
+func (s *PostgresStorage) GetVendorsByCategoryID(ctx context.Context, categoryID int) ([]model.Vendor, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	categoryName, err := s.categoryNameByID(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetVendorsByCategory(ctx, categoryName)
+}
+
+// UpdateVendorCategories updates all vendors from one category to another.
+func (s *PostgresStorage) UpdateVendorCategories(ctx context.Context, fromCategory, toCategory string) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := validateString(fromCategory, "fromCategory"); err != nil {
+		return err
+	}
+	if err := validateString(toCategory, "toCategory"); err != nil {
+		return err
+	}
+
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := requireActiveCategory(ctx, tx, toCategory); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE vendors SET category = $1, last_updated = $2 WHERE category = $3
+		`, toCategory, time.Now(), fromCategory); err != nil {
+			return fmt.Errorf("failed to update vendors: %w", err)
+		}
+		return nil
+	})
+
+	// Clear cache since we've updated vendors
+	s.vendorCache.clear()
+	return err
+}
+
+// UpdateVendorCategoriesByID updates all vendors from one category ID to another.
+func (s *PostgresStorage) UpdateVendorCategoriesByID(ctx context.Context, fromCategoryID, toCategoryID int) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+
+	fromCategory, err := s.categoryNameByID(ctx, fromCategoryID)
+	if err != nil {
+		return err
+	}
+	toCategory, err := s.categoryNameByID(ctx, toCategoryID)
+	if err != nil {
+		return err
+	}
+
+	return s.UpdateVendorCategories(ctx, fromCategory, toCategory)
+}
+
+// GetVendorsBySource retrieves all vendors with a specific source.
+func (s *PostgresStorage) GetVendorsBySource(ctx context.Context, source model.VendorSource) ([]model.Vendor, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	return s.queryVendors(ctx, s.conn(), `
+		SELECT `+pgVendorColumns+` FROM vendors WHERE source = $1 ORDER BY name
+	`, string(source))
+}
+
+// DeleteVendorsBySource deletes all vendors with a specific source.
+func (s *PostgresStorage) DeleteVendorsBySource(ctx context.Context, source model.VendorSource) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+
+	if _, err := s.conn().ExecContext(ctx, `DELETE FROM vendors WHERE source = $1`, string(source)); err != nil {
+		return fmt.Errorf("failed to delete vendors by source: %w", err)
+	}
+
+	// Clear cache since we've deleted vendors
+	s.vendorCache.clear()
+	return nil
+}
+
+// FindVendorMatch looks for a vendor that matches the given merchant name.
+// It first checks for an exact match, then, unless vendor matching is case
+// sensitive, for one ignoring case, and finally checks regex vendors.
+func (s *PostgresStorage) FindVendorMatch(ctx context.Context, merchantName string) (*model.Vendor, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if err := validateString(merchantName, "merchantName"); err != nil {
+		return nil, err
+	}
+
+	// Exact matches always beat regex vendors, however broad the regex
+	vendor, err := s.GetVendor(ctx, merchantName)
+	if err == nil && vendor != nil && !vendor.IsRegex {
+		return vendor, nil
+	}
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	if !s.vendorCaseSensitive {
+		vendor, err = s.findExactVendorIgnoreCase(ctx, s.conn(), merchantName)
+		if err == nil {
+			return vendor, nil
+		}
+		if err != sql.ErrNoRows {
+			return nil, err
+		}
+	}
+
+	return s.findRegexVendorMatch(ctx, merchantName)
+}
+
+// getVendorRule returns the exact vendor rule of a merchant: the vendor of
+// that name or, unless vendor matching is case sensitive, of another spelling.
+func (s *PostgresStorage) getVendorRule(ctx context.Context, q queryable, merchantName string) (*model.Vendor, error) {
+	vendor, err := s.getVendor(ctx, q, merchantName)
+	if err != sql.ErrNoRows || s.vendorCaseSensitive {
+		return vendor, err
+	}
+	return s.findExactVendorIgnoreCase(ctx, q, merchantName)
+}
+
+// findExactVendorIgnoreCase finds a non-regex vendor whose name equals the merchant ignoring case.
+func (s *PostgresStorage) findExactVendorIgnoreCase(ctx context.Context, q queryable, merchantName string) (*model.Vendor, error) {
+	vendor, err := scanPostgresVendor(q.QueryRowContext(ctx, `
+		SELECT `+pgVendorColumns+`
+		FROM vendors
+		WHERE LOWER(name) = LOWER($1) AND is_regex = FALSE
+		ORDER BY use_count DESC, name
+		LIMIT 1
+	`, merchantName))
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vendor: %w", err)
+	}
+	return vendor, nil
+}
+
+// findRegexVendorMatch returns the matching regex vendor that ranks first under
+// the configured precedence. Ties fall back to the vendor name for determinism.
+func (s *PostgresStorage) findRegexVendorMatch(ctx context.Context, merchantName string) (*model.Vendor, error) {
+	vendors, err := s.queryVendors(ctx, s.conn(), `
+		SELECT `+pgVendorColumns+` FROM vendors WHERE is_regex = TRUE ORDER BY use_count DESC, name
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	var best *model.Vendor
+	for i := range vendors {
+		matched, err := common.MatchRegex(vendors[i].Name, merchantName)
+		if err != nil {
+			// Invalid regex, skip this vendor
+			continue
+		}
+		if matched && (best == nil || regexVendorBeats(s.regexPrecedence, &vendors[i], best)) {
+			best = &vendors[i]
+		}
+	}
+
+	if best == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	// Cache the match for future use
+	s.vendorCache.put(best)
+	return best, nil
+}
+
+// RecomputeVendorUseCounts recounts each vendor's uses from the classifications
+// it accounts for and stores the corrected counts. A classification counts
+// toward the vendor its merchant resolves to, as FindVendorMatch would resolve
+// it, when it has that vendor's category. It returns the vendors whose count changed.
+func (s *PostgresStorage) RecomputeVendorUseCounts(ctx context.Context) ([]model.VendorRecount, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	var changes []model.VendorRecount
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		vendors, err := s.queryVendors(ctx, tx, `SELECT `+pgVendorColumns+` FROM vendors ORDER BY name`)
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.QueryContext(ctx, `
+			SELECT t.merchant_name, c.category, COUNT(*)
+			FROM classifications c
+			JOIN transactions t ON t.id = c.transaction_id
+			WHERE t.merchant_name != '' AND c.category IS NOT NULL AND c.category != ''
+				AND c.status != $1
+			GROUP BY t.merchant_name, c.category
+		`, string(model.StatusUnclassified))
+		if err != nil {
+			return fmt.Errorf("failed to count classifications by merchant: %w", err)
+		}
+		defer func() { _ = rows.Close() }()
+
+		counts := make(map[string]int, len(vendors))
+		for rows.Next() {
+			var merchant, category string
+			var count int
+			if err := rows.Scan(&merchant, &category, &count); err != nil {
+				return fmt.Errorf("failed to scan classification count: %w", err)
+			}
+			if vendor := resolveVendor(vendors, merchant, s.regexPrecedence, s.vendorCaseSensitive); vendor != nil && vendor.Category == category {
+				counts[vendor.Name] += count
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating classification counts: %w", err)
+		}
+		_ = rows.Close()
+
+		for _, vendor := range vendors {
+			count := counts[vendor.Name]
+			if count == vendor.UseCount {
+				continue
+			}
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE vendors SET use_count = $1 WHERE name = $2
+			`, count, vendor.Name); err != nil {
+				return fmt.Errorf("failed to update use count for vendor %q: %w", vendor.Name, err)
+			}
+			changes = append(changes, model.VendorRecount{Name: vendor.Name, OldCount: vendor.UseCount, NewCount: count})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Clear cache since cached vendors carry the old counts
+	s.vendorCache.clear()
+	return changes, nil
+}
+
+// SetVendorMultiCategory marks or unmarks a merchant as spanning many
+// categories. Names match existing vendors ignoring case, unless vendor
+// matching is case sensitive. Marking a merchant without a vendor rule adds a
+// vendor with no category; unmarking it removes that vendor again, while a
+// merchant that had a rule before being marked gets the rule back. Unmarking
+// an unknown merchant returns common.ErrNotFound.
+func (s *PostgresStorage) SetVendorMultiCategory(ctx context.Context, merchantName string, multiCategory bool) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := validateString(merchantName, "merchantName"); err != nil {
+		return err
+	}
+
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		return s.setVendorMultiCategoryTx(ctx, tx, merchantName, multiCategory)
+	})
+
+	// Cached vendors, including regex and case-insensitive matches, may be stale
+	s.vendorCache.clear()
+	return err
+}
+
+func (s *PostgresStorage) setVendorMultiCategoryTx(ctx context.Context, tx *sql.Tx, merchantName string, multiCategory bool) error {
+	nameMatches := s.vendorNameMatch("$1")
+
+	if multiCategory {
+		// Mark an existing rule under its own spelling rather than adding a twin
+		var existing string
+		err := tx.QueryRowContext(ctx, `
+			SELECT name FROM vendors WHERE `+nameMatches+` ORDER BY name = $1 DESC, name LIMIT 1
+		`, merchantName).Scan(&existing)
+		switch {
+		case err == nil:
+			merchantName = existing
+		case err != sql.ErrNoRows:
+			return fmt.Errorf("failed to check vendor: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO vendors (name, category, last_updated, use_count, source, is_regex, multi_category)
+			VALUES ($1, '', $2, 0, $3, FALSE, TRUE)
+			ON CONFLICT (name) DO UPDATE SET
+				multi_category = TRUE,
+				last_updated = excluded.last_updated
+		`, merchantName, time.Now(), string(model.SourceManual))
+		if err != nil {
+			return fmt.Errorf("failed to mark vendor multi-category: %w", err)
+		}
+		return nil
+	}
+
+	// Without a category of its own the vendor only existed for the mark
+	deleted, err := tx.ExecContext(ctx, `
+		DELETE FROM vendors WHERE `+nameMatches+` AND category = '' AND multi_category = TRUE
+	`, merchantName)
+	if err != nil {
+		return fmt.Errorf("failed to unmark vendor: %w", err)
+	}
+	updated, err := tx.ExecContext(ctx, `
+		UPDATE vendors SET multi_category = FALSE WHERE `+nameMatches+` AND multi_category = TRUE
+	`, merchantName)
+	if err != nil {
+		return fmt.Errorf("failed to unmark vendor: %w", err)
+	}
+	deletedRows, err := deleted.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	updatedRows, err := updated.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if deletedRows == 0 && updatedRows == 0 {
+		return common.ErrNotFound
+	}
+	return nil
+}
+
+// isMultiCategoryVendor reports whether a merchant is marked multi-category,
+// ignoring case unless vendor matching is case sensitive.
+func (s *PostgresStorage) isMultiCategoryVendor(ctx context.Context, q queryable, merchantName string) (bool, error) {
+	var multiCategory bool
+	err := q.QueryRowContext(ctx, `
+		SELECT EXISTS(SELECT 1 FROM vendors WHERE `+s.vendorNameMatch("$1")+` AND multi_category = TRUE)
+	`, merchantName).Scan(&multiCategory)
+	if err != nil {
+		return false, fmt.Errorf("failed to check vendor: %w", err)
+	}
+	return multiCategory, nil
+}
+
+// vendorNameMatch is the condition comparing vendor names to the merchant name
+// in placeholder, which ignores case unless vendor matching is case sensitive.
+func (s *PostgresStorage) vendorNameMatch(placeholder string) string {
+	if s.vendorCaseSensitive {
+		return "name = " + placeholder
+	}
+	return "LOWER(name) = LOWER(" + placeholder + ")"
+}
+
+// queryVendors runs a query selecting pgVendorColumns.
+func (s *PostgresStorage) queryVendors(ctx context.Context, q queryable, query string, args ...any) ([]model.Vendor, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query vendors: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var vendors []model.Vendor
+	for rows.Next() {
+		vendor, err := scanPostgresVendor(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan vendor: %w", err)
+		}
+		vendors = append(vendors, *vendor)
+	}
+
+	return vendors, rows.Err()
+}
+
+// scanPostgresVendor scans a row of pgVendorColumns. sql.ErrNoRows is returned as is.
+func scanPostgresVendor(row interface{ Scan(dest ...any) error }) (*model.Vendor, error) {
+	var vendor model.Vendor
+	var source string
+	if err := row.Scan(
+		&vendor.Name,
+		&vendor.Category,
+		&vendor.LastUpdated,
+		&vendor.UseCount,
+		&source,
+		&vendor.IsRegex,
+		&vendor.MultiCategory,
+	); err != nil {
+		return nil, err
+	}
+	vendor.Source = model.VendorSource(source)
+	return &vendor, nil
+}
@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+// ErrRefundMatchNotFound is returned when no classified purchase matches a refund.
+var ErrRefundMatchNotFound = errors.New("no matching purchase for refund")
+
+// FindRefundedPurchase finds the classified purchase a refund most likely reverses:
+// a non-income transaction from the same merchant, dated up to window before the
+// refund, for at least the refunded amount. Exact amount matches win, then the most
+// recent purchase. Returns ErrRefundMatchNotFound when nothing qualifies.
+func (s *SQLiteStorage) FindRefundedPurchase(ctx context.Context, refund model.Transaction, window time.Duration) (*model.Classification, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if window < 0 {
+		return nil, fmt.Errorf("refund window cannot be negative")
+	}
+	return s.findRefundedPurchaseTx(ctx, s.db, refund, window)
+}
+
+func (s *SQLiteStorage) findRefundedPurchaseTx(ctx context.Context, q queryable, refund model.Transaction, window time.Duration) (*model.Classification, error) {
+	merchant := refund.MerchantName
+	if merchant == "" {
+		merchant = refund.Name
+	}
+	if merchant == "" {
+		return nil, ErrRefundMatchNotFound
+	}
+	amount := math.Abs(refund.Amount)
+
+	var c model.Classification
+	var statusStr string
+	err := q.QueryRowContext(ctx, `
+		SELECT t.id, t.date, t.name, t.merchant_name, t.amount,
+		       c.category, c.status, c.confidence, c.business_percent
+		FROM transactions t
+		JOIN classifications c ON c.transaction_id = t.id
+		WHERE t.id != ?
+		  AND COALESCE(t.direction, '') != ?
+		  AND CASE WHEN COALESCE(t.merchant_name, '') = '' THEN t.name ELSE t.merchant_name END = ? COLLATE NOCASE
+		  AND t.date >= ? AND t.date <= ?
+		  AND ABS(t.amount) >= ? - 0.005
+		ORDER BY ABS(ABS(t.amount) - ?) < 0.005 DESC, t.date DESC
+		LIMIT 1
+	`, refund.ID, model.DirectionIncome, merchant,
+		refund.Date.Add(-window), refund.Date, amount, amount).Scan(
+		&c.Transaction.ID,
+		&c.Transaction.Date,
+		&c.Transaction.Name,
+		&c.Transaction.MerchantName,
+		&c.Transaction.Amount,
+		&c.Category,
+		&statusStr,
+		&c.Confidence,
+		&c.BusinessPercent,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRefundMatchNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find refunded purchase: %w", err)
+	}
+
+	c.Status = model.ClassificationStatus(statusStr)
+	return &c, nil
+}
+
+// MarkTransactionRefund flags a transaction as a refund of a purchase in the given category.
+func (s *SQLiteStorage) MarkTransactionRefund(ctx context.Context, transactionID, category string) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := s.checkWritable("mark refund"); err != nil {
+		return err
+	}
+	if err := validateString(transactionID, "transactionID"); err != nil {
+		return err
+	}
+	if err := validateString(category, "category"); err != nil {
+		return err
+	}
+	return s.markTransactionRefundTx(ctx, s.db, transactionID, category)
+}
+
+func (s *SQLiteStorage) markTransactionRefundTx(ctx context.Context, q queryable, transactionID, category string) error {
+	result, err := q.ExecContext(ctx, `
+		UPDATE transactions SET is_refund = 1, refund_category = ? WHERE id = ?
+	`, category, transactionID)
+	if err != nil {
+		return fmt.Errorf("failed to mark refund: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("transaction %s not found", transactionID)
+	}
+
+	return nil
+}
+
+// FindRefundedPurchase finds the purchase a refund reverses within a transaction.
+func (t *sqliteTransaction) FindRefundedPurchase(ctx context.Context, refund model.Transaction, window time.Duration) (*model.Classification, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if window < 0 {
+		return nil, fmt.Errorf("refund window cannot be negative")
+	}
+	return t.storage.findRefundedPurchaseTx(ctx, t.tx, refund, window)
+}
+
+// MarkTransactionRefund flags a transaction as a refund within a transaction.
+func (t *sqliteTransaction) MarkTransactionRefund(ctx context.Context, transactionID, category string) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := validateString(transactionID, "transactionID"); err != nil {
+		return err
+	}
+	if err := validateString(category, "category"); err != nil {
+		return err
+	}
+	return t.storage.markTransactionRefundTx(ctx, t.tx, transactionID, category)
+}
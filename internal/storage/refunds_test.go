@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+)
+
+func TestFindRefundedPurchase(t *testing.T) {
+	ctx := context.Background()
+	store, cleanup := createTestStorage(t)
+	defer cleanup()
+
+	_, err := store.CreateCategory(ctx, "Shopping", "Retail purchases")
+	require.NoError(t, err)
+	_, err = store.CreateCategory(ctx, "Electronics", "Gadgets")
+	require.NoError(t, err)
+
+	refundDate := time.Date(2024, 6, 20, 0, 0, 0, 0, time.UTC)
+	purchase := func(id string, daysBefore int, amount float64) model.Transaction {
+		txn := model.Transaction{
+			ID:           id,
+			Date:         refundDate.AddDate(0, 0, -daysBefore),
+			Name:         "AMAZON MKTPLACE",
+			MerchantName: "Amazon",
+			Amount:       amount,
+			AccountID:    "acc1",
+			Direction:    model.DirectionExpense,
+		}
+		txn.Hash = txn.GenerateHash()
+		return txn
+	}
+
+	txns := []model.Transaction{
+		purchase("recent-large", 3, 120.00),
+		purchase("exact", 10, 45.00),
+		purchase("too-old", 45, 45.00),
+		purchase("too-small", 1, 20.00),
+	}
+	require.NoError(t, store.SaveTransactions(ctx, txns))
+	for _, txn := range txns {
+		category := "Shopping"
+		if txn.ID == "exact" {
+			category = "Electronics"
+		}
+		require.NoError(t, store.SaveClassification(ctx, &model.Classification{
+			Transaction: txn, Category: category, Status: model.StatusClassifiedByAI, Confidence: 0.9, ClassifiedAt: time.Now(),
+		}))
+	}
+
+	refund := model.Transaction{
+		ID:           "refund",
+		Date:         refundDate,
+		Name:         "AMAZON REFUND",
+		MerchantName: "amazon",
+		Amount:       45.00,
+		AccountID:    "acc1",
+		Direction:    model.DirectionIncome,
+	}
+	refund.Hash = refund.GenerateHash()
+	require.NoError(t, store.SaveTransactions(ctx, []model.Transaction{refund}))
+
+	t.Run("prefers exact amount within window", func(t *testing.T) {
+		match, err := store.FindRefundedPurchase(ctx, refund, 30*24*time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, "exact", match.Transaction.ID)
+		assert.Equal(t, "Electronics", match.Category)
+	})
+
+	t.Run("falls back to larger purchase", func(t *testing.T) {
+		match, err := store.FindRefundedPurchase(ctx, refund, 5*24*time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, "recent-large", match.Transaction.ID)
+	})
+
+	t.Run("no purchase large enough", func(t *testing.T) {
+		big := refund
+		big.Amount = 500
+		_, err := store.FindRefundedPurchase(ctx, big, 30*24*time.Hour)
+		assert.ErrorIs(t, err, ErrRefundMatchNotFound)
+	})
+
+	t.Run("other merchant", func(t *testing.T) {
+		other := refund
+		other.MerchantName = "Target"
+		_, err := store.FindRefundedPurchase(ctx, other, 30*24*time.Hour)
+		assert.ErrorIs(t, err, ErrRefundMatchNotFound)
+	})
+
+	t.Run("negative window", func(t *testing.T) {
+		_, err := store.FindRefundedPurchase(ctx, refund, -time.Hour)
+		assert.Error(t, err)
+	})
+
+	t.Run("mark refund", func(t *testing.T) {
+		require.NoError(t, store.SaveClassification(ctx, &model.Classification{
+			Transaction: refund, Category: "Electronics", Status: model.StatusClassifiedByRule, Confidence: 1, ClassifiedAt: time.Now(),
+		}))
+		require.NoError(t, store.MarkTransactionRefund(ctx, refund.ID, "Electronics"))
+
+		classifications, err := store.GetClassificationsByDateRange(ctx, refundDate, refundDate)
+		require.NoError(t, err)
+		require.Len(t, classifications, 1)
+		assert.True(t, classifications[0].Transaction.IsRefund)
+		assert.Equal(t, "Electronics", classifications[0].Transaction.RefundCategory)
+
+		assert.Error(t, store.MarkTransactionRefund(ctx, "missing", "Electronics"))
+	})
+}
@@ -0,0 +1,310 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// expectedTables lists every table and column the migration chain must produce
+// at ExpectedSchemaVersion. Update it together with any migration that changes
+// the schema; VerifyMigrations reports any drift.
+var expectedTables = map[string][]string{
+	"analysis_category_stats":     {"id", "report_id", "category_id", "category_name", "transaction_count", "total_amount", "consistency", "issues", "created_at"},
+	"analysis_fixes":              {"id", "issue_id", "type", "description", "data", "applied", "applied_at", "created_at"},
+	"analysis_issues":             {"id", "report_id", "type", "severity", "description", "current_category", "suggested_category", "transaction_ids", "affected_count", "confidence", "created_at"},
+	"analysis_reports":            {"id", "session_id", "generated_at", "period_start", "period_end", "coherence_score", "insights", "created_at"},
+	"analysis_sessions":           {"id", "started_at", "last_attempt", "completed_at", "status", "attempts", "error", "report_id", "created_at", "updated_at"},
+	"analysis_suggested_patterns": {"id", "report_id", "name", "description", "impact", "pattern", "example_txn_ids", "match_count", "confidence", "created_at"},
+	"categories":                  {"id", "name", "created_at", "is_active", "description", "type", "default_business_percent", "always_review", "vendor_rule_threshold"},
+	"category_aliases":            {"alias", "category", "created_at"},
+	"category_snapshots":          {"id", "report", "period_start", "period_end", "categories", "created_at"},
+	"check_patterns":              {"id", "pattern_name", "amount_min", "amount_max", "check_number_pattern", "day_of_month_min", "day_of_month_max", "category", "notes", "use_count", "amounts", "created_at", "updated_at", "memo_pattern", "confidence"},
+	"checkpoint_metadata":         {"id", "created_at", "description", "file_size", "row_counts", "schema_version", "is_auto", "parent_checkpoint"},
+	"classification_history":      {"id", "transaction_id", "category", "status", "confidence", "created_at"},
+	"classifications":             {"transaction_id", "category", "status", "confidence", "classified_at", "notes", "business_percent"},
+	"embeddings":                  {"merchant_name", "model", "vector", "created_at"},
+	"merchant_hints":              {"merchant_name", "hint", "updated_at"},
+	"pattern_rules":               {"id", "name", "description", "merchant_pattern", "is_regex", "amount_condition", "amount_value", "amount_min", "amount_max", "direction", "default_category", "confidence", "priority", "is_active", "created_at", "updated_at", "use_count"},
+	"progress":                    {"id", "last_processed_id", "last_processed_date", "total_processed", "started_at", "updated_at"},
+	"transactions":                {"id", "hash", "date", "name", "merchant_name", "amount", "categories", "account_id", "created_at", "transaction_type", "check_number", "direction", "is_refund", "refund_category", "original_amount", "original_currency"},
+	"vendors":                     {"name", "category", "last_updated", "use_count", "source", "is_regex", "multi_category"},
+}
+
+// expectedIndexes lists the named indexes the migration chain must produce.
+var expectedIndexes = []string{
+	"idx_analysis_category_stats_category_id",
+	"idx_analysis_category_stats_report_id",
+	"idx_analysis_fixes_applied",
+	"idx_analysis_fixes_issue_id",
+	"idx_analysis_issues_report_id",
+	"idx_analysis_issues_severity",
+	"idx_analysis_issues_type",
+	"idx_analysis_reports_period",
+	"idx_analysis_reports_session_id",
+	"idx_analysis_sessions_report_id",
+	"idx_analysis_sessions_started_at",
+	"idx_analysis_sessions_status",
+	"idx_analysis_suggested_patterns_report_id",
+	"idx_categories_active",
+	"idx_categories_name",
+	"idx_categories_type",
+	"idx_check_patterns_amount",
+	"idx_check_patterns_category",
+	"idx_checkpoint_metadata_created_at",
+	"idx_checkpoint_metadata_is_auto",
+	"idx_classification_history_transaction_id",
+	"idx_classifications_category",
+	"idx_pattern_rules_active",
+	"idx_pattern_rules_category",
+	"idx_pattern_rules_merchant",
+	"idx_pattern_rules_priority",
+	"idx_transactions_date",
+	"idx_transactions_direction",
+	"idx_transactions_merchant",
+	"idx_transactions_type",
+	"idx_vendors_is_regex",
+	"idx_vendors_source",
+}
+
+// SchemaSnapshot describes the structure of a database. Column and index
+// lists are sorted so snapshots compare by presence, not declaration order.
+type SchemaSnapshot struct {
+	Tables  map[string][]string
+	Indexes []string
+	Version int
+}
+
+// MigrationVerification is the outcome of VerifyMigrations.
+type MigrationVerification struct {
+	Problems     []string
+	Version      int // Schema version reached by a fresh database
+	Tables       int
+	Indexes      int
+	PathsChecked int // Upgrade paths replayed, including the fresh one
+}
+
+// OK reports whether verification found no problems.
+func (v *MigrationVerification) OK() bool {
+	return len(v.Problems) == 0
+}
+
+// VerifyMigrations checks the migration chain without touching any real database.
+// It migrates a fresh in-memory database to the latest version and compares the
+// result with the expected schema, then replays the upgrade from every older
+// version (as if an old database were opened by this binary) and checks each
+// path ends with the same schema as the fresh one.
+func VerifyMigrations(ctx context.Context) (*MigrationVerification, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	result := &MigrationVerification{}
+	addf := func(format string, args ...any) {
+		result.Problems = append(result.Problems, fmt.Sprintf(format, args...))
+	}
+
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			addf("migration at position %d has version %d, expected %d", i+1, m.Version, i+1)
+		}
+	}
+	if n := len(migrations); n == 0 || migrations[n-1].Version != ExpectedSchemaVersion {
+		addf("last migration does not match ExpectedSchemaVersion %d", ExpectedSchemaVersion)
+	}
+
+	fresh, err := migratedSnapshot(ctx, 0)
+	if err != nil {
+		addf("fresh database: %v", err)
+		return result, nil
+	}
+	result.Version = fresh.Version
+	result.Tables = len(fresh.Tables)
+	result.Indexes = len(fresh.Indexes)
+	result.PathsChecked = 1
+
+	if fresh.Version != ExpectedSchemaVersion {
+		addf("fresh database reached version %d, expected %d", fresh.Version, ExpectedSchemaVersion)
+	}
+	for _, diff := range diffSchema(expectedSnapshot(), fresh) {
+		addf("fresh database: %s", diff)
+	}
+
+	for _, m := range migrations {
+		if m.Version >= ExpectedSchemaVersion {
+			break
+		}
+		upgraded, err := migratedSnapshot(ctx, m.Version)
+		result.PathsChecked++
+		if err != nil {
+			addf("upgrade from version %d: %v", m.Version, err)
+			continue
+		}
+		for _, diff := range diffSchema(fresh, upgraded) {
+			addf("upgrade from version %d: %s", m.Version, diff)
+		}
+	}
+
+	return result, nil
+}
+
+// migratedSnapshot builds an in-memory database at startVersion, then runs the
+// normal upgrade to the latest version and returns the resulting schema.
+func migratedSnapshot(ctx context.Context, startVersion int) (*SchemaSnapshot, error) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open in-memory database: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+	// Every connection to :memory: is a separate database
+	db.SetMaxOpenConns(1)
+
+	for _, m := range migrations {
+		if m.Version > startVersion {
+			break
+		}
+		if err := applyMigration(ctx, db, m); err != nil {
+			return nil, err
+		}
+	}
+	if err := applyMigrations(ctx, db, startVersion, ExpectedSchemaVersion, nil); err != nil {
+		return nil, err
+	}
+
+	return snapshotSchema(ctx, db)
+}
+
+// snapshotSchema reads the tables, columns and named indexes of a database.
+func snapshotSchema(ctx context.Context, db *sql.DB) (*SchemaSnapshot, error) {
+	snapshot := &SchemaSnapshot{Tables: make(map[string][]string)}
+	if err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&snapshot.Version); err != nil {
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT type, name FROM sqlite_master
+		WHERE type IN ('table', 'index') AND name NOT LIKE 'sqlite_%'
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schema objects: %w", err)
+	}
+	var tables []string
+	for rows.Next() {
+		var kind, name string
+		if err := rows.Scan(&kind, &name); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("failed to scan schema object: %w", err)
+		}
+		if kind == "table" {
+			tables = append(tables, name)
+		} else {
+			snapshot.Indexes = append(snapshot.Indexes, name)
+		}
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, table := range tables {
+		columns, err := tableColumns(ctx, db, table)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.Tables[table] = columns
+	}
+	sort.Strings(snapshot.Indexes)
+
+	return snapshot, nil
+}
+
+func tableColumns(ctx context.Context, db *sql.DB, table string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var columns []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan column of %s: %w", table, err)
+		}
+		columns = append(columns, name)
+	}
+	sort.Strings(columns)
+	return columns, rows.Err()
+}
+
+// expectedSnapshot returns expectedTables and expectedIndexes as a snapshot.
+func expectedSnapshot() *SchemaSnapshot {
+	snapshot := &SchemaSnapshot{
+		Tables:  make(map[string][]string, len(expectedTables)),
+		Indexes: slices.Clone(expectedIndexes),
+		Version: ExpectedSchemaVersion,
+	}
+	for table, columns := range expectedTables {
+		sorted := slices.Clone(columns)
+		sort.Strings(sorted)
+		snapshot.Tables[table] = sorted
+	}
+	sort.Strings(snapshot.Indexes)
+	return snapshot
+}
+
+// diffSchema describes how got differs from want, in a stable order.
+func diffSchema(want, got *SchemaSnapshot) []string {
+	var diffs []string
+
+	tables := make([]string, 0, len(want.Tables)+len(got.Tables))
+	for table := range want.Tables {
+		tables = append(tables, table)
+	}
+	for table := range got.Tables {
+		if _, ok := want.Tables[table]; !ok {
+			tables = append(tables, table)
+		}
+	}
+	sort.Strings(tables)
+
+	for _, table := range tables {
+		wantCols, inWant := want.Tables[table]
+		gotCols, inGot := got.Tables[table]
+		switch {
+		case !inGot:
+			diffs = append(diffs, fmt.Sprintf("missing table %s", table))
+		case !inWant:
+			diffs = append(diffs, fmt.Sprintf("unexpected table %s", table))
+		default:
+			for _, col := range missingFrom(wantCols, gotCols) {
+				diffs = append(diffs, fmt.Sprintf("missing column %s.%s", table, col))
+			}
+			for _, col := range missingFrom(gotCols, wantCols) {
+				diffs = append(diffs, fmt.Sprintf("unexpected column %s.%s", table, col))
+			}
+		}
+	}
+
+	for _, index := range missingFrom(want.Indexes, got.Indexes) {
+		diffs = append(diffs, fmt.Sprintf("missing index %s", index))
+	}
+	for _, index := range missingFrom(got.Indexes, want.Indexes) {
+		diffs = append(diffs, fmt.Sprintf("unexpected index %s", index))
+	}
+
+	return diffs
+}
+
+// missingFrom returns the entries of want that are not in got.
+func missingFrom(want, got []string) []string {
+	var missing []string
+	for _, name := range want {
+		if !slices.Contains(got, name) {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestVerifyMigrations(t *testing.T) {
+	result, err := VerifyMigrations(context.Background())
+	if err != nil {
+		t.Fatalf("VerifyMigrations failed: %v", err)
+	}
+	if !result.OK() {
+		t.Fatalf("migration chain drifted from the expected schema:\n%s", strings.Join(result.Problems, "\n"))
+	}
+	if result.Version != ExpectedSchemaVersion {
+		t.Errorf("fresh database at version %d, want %d", result.Version, ExpectedSchemaVersion)
+	}
+	if result.PathsChecked != ExpectedSchemaVersion {
+		t.Errorf("checked %d upgrade paths, want %d", result.PathsChecked, ExpectedSchemaVersion)
+	}
+	if result.Tables != len(expectedTables) || result.Indexes != len(expectedIndexes) {
+		t.Errorf("got %d tables and %d indexes, want %d and %d",
+			result.Tables, result.Indexes, len(expectedTables), len(expectedIndexes))
+	}
+}
+
+func TestDiffSchema(t *testing.T) {
+	want := &SchemaSnapshot{
+		Tables:  map[string][]string{"vendors": {"category", "name"}, "progress": {"id"}},
+		Indexes: []string{"idx_vendors_source"},
+	}
+	got := &SchemaSnapshot{
+		Tables:  map[string][]string{"vendors": {"name", "source"}, "extra": {"id"}},
+		Indexes: []string{"idx_extra"},
+	}
+
+	diffs := diffSchema(want, got)
+	expected := []string{
+		"unexpected table extra",
+		"missing table progress",
+		"missing column vendors.category",
+		"unexpected column vendors.source",
+		"missing index idx_vendors_source",
+		"unexpected index idx_extra",
+	}
+	if strings.Join(diffs, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("diffSchema() =\n%s\nwant\n%s", strings.Join(diffs, "\n"), strings.Join(expected, "\n"))
+	}
+
+	if diffs := diffSchema(want, want); len(diffs) != 0 {
+		t.Errorf("identical snapshots reported differences: %v", diffs)
+	}
+}
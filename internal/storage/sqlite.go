@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -15,13 +16,25 @@ import (
 	_ "github.com/mattn/go-sqlite3" // SQLite driver
 )
 
+// ErrReadOnly is returned by write operations on a storage opened in read-only mode.
+var ErrReadOnly = errors.New("storage is read-only")
+
 // SQLiteStorage implements the Storage interface using SQLite.
 type SQLiteStorage struct {
 	cacheExpiry time.Time
 	db          *sql.DB
 	vendorCache map[string]*model.Vendor
 	dbPath      string
-	cacheMutex  sync.RWMutex
+	// regexPrecedence orders competing regex vendor matches; empty means PrecedenceUseCount
+	regexPrecedence model.RegexVendorPrecedence
+	cacheMutex      sync.RWMutex
+	readOnly        bool
+	// vendorCaseSensitive makes exact vendor rules match merchant names only
+	// with the same case; by default "AMAZON" and "Amazon" share a rule
+	vendorCaseSensitive bool
+	// overwriteUserModified lets saves replace classifications the user made
+	// or corrected; by default they are protected
+	overwriteUserModified bool
 }
 
 // NewSQLiteStorage creates a new SQLite storage instance.
@@ -60,6 +73,85 @@ func NewSQLiteStorage(dbPath string) (*SQLiteStorage, error) {
 	}, nil
 }
 
+// NewSQLiteStorageReadOnly opens an existing SQLite database in read-only mode.
+// All write operations return ErrReadOnly, which makes it safe to run reports
+// while another process (e.g. a classify run) holds the primary connection.
+func NewSQLiteStorageReadOnly(dbPath string) (*SQLiteStorage, error) {
+	if err := validateString(dbPath, "dbPath"); err != nil {
+		return nil, err
+	}
+
+	// Unlike read-write mode, never create the database or its directory
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, fmt.Errorf("failed to open database for reading: %w", err)
+	}
+
+	// The journal mode is left untouched since changing it requires write access
+	db, err := sql.Open("sqlite3", "file:"+dbPath+"?mode=ro&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return &SQLiteStorage{
+		db:          db,
+		dbPath:      dbPath,
+		vendorCache: make(map[string]*model.Vendor),
+		readOnly:    true,
+	}, nil
+}
+
+// IsReadOnly reports whether the storage was opened in read-only mode.
+func (s *SQLiteStorage) IsReadOnly() bool {
+	return s.readOnly
+}
+
+// SetRegexVendorPrecedence controls which regex vendor wins when several match.
+func (s *SQLiteStorage) SetRegexVendorPrecedence(precedence model.RegexVendorPrecedence) error {
+	switch precedence {
+	case "", model.PrecedenceUseCount, model.PrecedenceSpecificity:
+		s.regexPrecedence = precedence
+		return nil
+	default:
+		return fmt.Errorf("invalid regex vendor precedence %q (use %s or %s)",
+			precedence, model.PrecedenceUseCount, model.PrecedenceSpecificity)
+	}
+}
+
+// SetVendorCaseSensitive controls whether exact vendor rules match merchant
+// names case-sensitively. Regex vendors are unaffected; their patterns decide.
+func (s *SQLiteStorage) SetVendorCaseSensitive(caseSensitive bool) {
+	s.vendorCaseSensitive = caseSensitive
+
+	// Cached case-insensitive matches may no longer apply
+	s.cacheMutex.Lock()
+	s.vendorCache = make(map[string]*model.Vendor)
+	s.cacheMutex.Unlock()
+}
+
+// SetOverwriteUserModified controls whether saving a classification may
+// replace one with StatusUserModified. By default such saves fail with
+// ErrUserModified, unless the new classification is itself the user's.
+func (s *SQLiteStorage) SetOverwriteUserModified(overwrite bool) {
+	s.overwriteUserModified = overwrite
+}
+
+// checkWritable returns ErrReadOnly if the storage does not permit writes.
+func (s *SQLiteStorage) checkWritable(operation string) error {
+	if s.readOnly {
+		return fmt.Errorf("%w: cannot %s", ErrReadOnly, operation)
+	}
+	return nil
+}
+
 // Close closes the database connection.
 func (s *SQLiteStorage) Close() error {
 	return s.db.Close()
@@ -80,6 +172,9 @@ func (s *SQLiteStorage) BeginTx(ctx context.Context) (service.Transaction, error
 	if err := validateContext(ctx); err != nil {
 		return nil, err
 	}
+	if err := s.checkWritable("begin transaction"); err != nil {
+		return nil, err
+	}
 
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -134,6 +229,26 @@ func (t *sqliteTransaction) GetTransactionByID(ctx context.Context, id string) (
 	return t.storage.getTransactionByIDTx(ctx, t.tx, id)
 }
 
+func (t *sqliteTransaction) GetTransactionByHash(ctx context.Context, hash string) (*model.Transaction, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if err := validateString(hash, "hash"); err != nil {
+		return nil, err
+	}
+	return t.storage.getTransactionByHashTx(ctx, t.tx, hash)
+}
+
+func (t *sqliteTransaction) QueryTransactions(ctx context.Context, query model.TransactionQuery) ([]model.Classification, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if err := query.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid transaction query: %w", err)
+	}
+	return t.storage.queryTransactionsTx(ctx, t.tx, query)
+}
+
 func (t *sqliteTransaction) GetVendor(ctx context.Context, merchantName string) (*model.Vendor, error) {
 	if err := validateContext(ctx); err != nil {
 		return nil, err
@@ -144,6 +259,16 @@ func (t *sqliteTransaction) GetVendor(ctx context.Context, merchantName string)
 	return t.storage.getVendorTx(ctx, t.tx, merchantName)
 }
 
+func (t *sqliteTransaction) GetVendorRule(ctx context.Context, merchantName string) (*model.Vendor, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if err := validateString(merchantName, "merchantName"); err != nil {
+		return nil, err
+	}
+	return t.storage.getVendorRuleTx(ctx, t.tx, merchantName)
+}
+
 func (t *sqliteTransaction) FindVendorMatch(ctx context.Context, merchantName string) (*model.Vendor, error) {
 	if err := validateContext(ctx); err != nil {
 		return nil, err
@@ -175,6 +300,16 @@ func (t *sqliteTransaction) DeleteVendor(ctx context.Context, merchantName strin
 	return t.storage.deleteVendorTx(ctx, t.tx, merchantName)
 }
 
+func (t *sqliteTransaction) SetVendorMultiCategory(ctx context.Context, merchantName string, multiCategory bool) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := validateString(merchantName, "merchantName"); err != nil {
+		return err
+	}
+	return t.storage.setVendorMultiCategoryTx(ctx, t.tx, merchantName, multiCategory)
+}
+
 func (t *sqliteTransaction) GetAllVendors(ctx context.Context) ([]model.Vendor, error) {
 	if err := validateContext(ctx); err != nil {
 		return nil, err
@@ -182,6 +317,97 @@ func (t *sqliteTransaction) GetAllVendors(ctx context.Context) ([]model.Vendor,
 	return t.storage.getAllVendorsTx(ctx, t.tx)
 }
 
+func (t *sqliteTransaction) RecomputeVendorUseCounts(ctx context.Context) ([]model.VendorRecount, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	return t.storage.recomputeVendorUseCountsTx(ctx, t.tx)
+}
+
+func (t *sqliteTransaction) SaveMerchantHint(ctx context.Context, hint *model.MerchantHint) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	return t.storage.saveMerchantHintTx(ctx, t.tx, hint)
+}
+
+func (t *sqliteTransaction) GetMerchantHints(ctx context.Context) ([]model.MerchantHint, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	return t.storage.getMerchantHintsTx(ctx, t.tx)
+}
+
+func (t *sqliteTransaction) DeleteMerchantHint(ctx context.Context, merchantName string) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	return t.storage.deleteMerchantHintTx(ctx, t.tx, merchantName)
+}
+
+func (t *sqliteTransaction) SaveMerchantEmbeddings(ctx context.Context, embeddings []model.MerchantEmbedding) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	return t.storage.saveMerchantEmbeddingsTx(ctx, t.tx, embeddings)
+}
+
+func (t *sqliteTransaction) GetMerchantEmbeddings(ctx context.Context, embeddingModel string) ([]model.MerchantEmbedding, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	return t.storage.getMerchantEmbeddingsTx(ctx, t.tx, embeddingModel)
+}
+
+func (t *sqliteTransaction) GetMerchantsWithoutEmbedding(ctx context.Context, embeddingModel string) ([]string, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	return t.storage.getMerchantsWithoutEmbeddingTx(ctx, t.tx, embeddingModel)
+}
+
+func (t *sqliteTransaction) SaveCategoryAlias(ctx context.Context, alias, category string) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	return t.storage.saveCategoryAliasTx(ctx, t.tx, alias, category)
+}
+
+func (t *sqliteTransaction) GetCategoryAliases(ctx context.Context) ([]model.CategoryAlias, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	return t.storage.getCategoryAliasesTx(ctx, t.tx)
+}
+
+func (t *sqliteTransaction) DeleteCategoryAlias(ctx context.Context, alias string) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	return t.storage.deleteCategoryAliasTx(ctx, t.tx, alias)
+}
+
+func (t *sqliteTransaction) SaveCategorySnapshot(ctx context.Context, snapshot *model.CategorySnapshot) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	return t.storage.saveCategorySnapshotTx(ctx, t.tx, snapshot)
+}
+
+func (t *sqliteTransaction) GetCategorySnapshot(ctx context.Context, id int64) (*model.CategorySnapshot, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	return t.storage.getCategorySnapshotTx(ctx, t.tx, id)
+}
+
+func (t *sqliteTransaction) GetCategorySnapshots(ctx context.Context) ([]model.CategorySnapshot, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	return t.storage.getCategorySnapshotsTx(ctx, t.tx)
+}
+
 func (t *sqliteTransaction) SaveClassification(ctx context.Context, classification *model.Classification) error {
 	if err := validateContext(ctx); err != nil {
 		return err
@@ -192,6 +418,18 @@ func (t *sqliteTransaction) SaveClassification(ctx context.Context, classificati
 	return t.storage.saveClassificationTx(ctx, t.tx, classification)
 }
 
+func (t *sqliteTransaction) SaveClassifications(ctx context.Context, classifications []model.Classification) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	for i := range classifications {
+		if err := validateClassification(&classifications[i]); err != nil {
+			return fmt.Errorf("classification for transaction %s: %w", classifications[i].Transaction.ID, err)
+		}
+	}
+	return t.storage.saveClassificationsTx(ctx, t.tx, classifications)
+}
+
 func (t *sqliteTransaction) GetClassificationsByDateRange(ctx context.Context, start, end time.Time) ([]model.Classification, error) {
 	if err := validateContext(ctx); err != nil {
 		return nil, err
@@ -210,6 +448,57 @@ func (t *sqliteTransaction) GetClassificationsByConfidence(ctx context.Context,
 	return t.storage.GetClassificationsByConfidence(ctx, maxConfidence, excludeUserModified)
 }
 
+func (t *sqliteTransaction) GetCategoryChanges(ctx context.Context, since time.Time) ([]model.CategoryChange, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	return t.storage.getCategoryChangesTx(ctx, t.tx, since)
+}
+
+func (t *sqliteTransaction) SaveClassificationHistory(ctx context.Context, classifications []model.Classification) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	return t.storage.saveClassificationHistoryTx(ctx, t.tx, classifications)
+}
+
+func (t *sqliteTransaction) GetConfidenceOutcomes(ctx context.Context) ([]model.ConfidenceOutcome, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	return t.storage.getConfidenceOutcomesTx(ctx, t.tx)
+}
+
+func (t *sqliteTransaction) HasClassificationHistory(ctx context.Context, merchantName string) (bool, error) {
+	if err := validateContext(ctx); err != nil {
+		return false, err
+	}
+	if err := validateString(merchantName, "merchantName"); err != nil {
+		return false, err
+	}
+	return t.storage.hasClassificationHistoryTx(ctx, t.tx, merchantName)
+}
+
+func (t *sqliteTransaction) UpdateBusinessPercentByCategory(ctx context.Context, categoryName string, businessPercent int) (int64, error) {
+	if err := validateContext(ctx); err != nil {
+		return 0, err
+	}
+	if err := validateString(categoryName, "categoryName"); err != nil {
+		return 0, err
+	}
+	return t.storage.updateBusinessPercentByCategoryTx(ctx, t.tx, categoryName, businessPercent)
+}
+
+func (t *sqliteTransaction) UpdateBusinessPercentByVendor(ctx context.Context, merchantName string, businessPercent int) (int64, error) {
+	if err := validateContext(ctx); err != nil {
+		return 0, err
+	}
+	if err := validateString(merchantName, "merchantName"); err != nil {
+		return 0, err
+	}
+	return t.storage.updateBusinessPercentByVendorTx(ctx, t.tx, merchantName, businessPercent)
+}
+
 func (t *sqliteTransaction) Migrate(_ context.Context) error {
 	// Migrations should not be run within a transaction
 	return fmt.Errorf("migrations cannot be run within a transaction")
@@ -237,6 +526,16 @@ func (t *sqliteTransaction) UpdateTransactionCategoriesByID(ctx context.Context,
 	return t.storage.UpdateTransactionCategoriesByID(ctx, fromCategoryID, toCategoryID)
 }
 
+func (t *sqliteTransaction) UpdateTransactionDirections(ctx context.Context, directions map[string]model.TransactionDirection) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := validateDirections(directions); err != nil {
+		return err
+	}
+	return t.storage.updateTransactionDirectionsTx(ctx, t.tx, directions)
+}
+
 func (t *sqliteTransaction) GetTransactionCount(ctx context.Context) (int, error) {
 	return t.storage.GetTransactionCount(ctx)
 }
@@ -261,6 +560,18 @@ func (t *sqliteTransaction) GetMerchantSummary(ctx context.Context, start, end t
 	return t.storage.GetMerchantSummary(ctx, start, end)
 }
 
+func (t *sqliteTransaction) GetClassificationCoverage(ctx context.Context, start, end time.Time) ([]model.CoverageMonth, error) {
+	return t.storage.GetClassificationCoverage(ctx, start, end)
+}
+
+func (t *sqliteTransaction) GetUnclassifiedMerchants(ctx context.Context, start, end time.Time, limit int) ([]model.UnclassifiedMerchant, error) {
+	return t.storage.GetUnclassifiedMerchants(ctx, start, end, limit)
+}
+
+func (t *sqliteTransaction) GetCategoryAmountStats(ctx context.Context) (map[string]model.CategoryAmountStats, error) {
+	return t.storage.GetCategoryAmountStats(ctx)
+}
+
 func (t *sqliteTransaction) GetVendorsByCategory(ctx context.Context, categoryName string) ([]model.Vendor, error) {
 	if err := validateContext(ctx); err != nil {
 		return nil, err
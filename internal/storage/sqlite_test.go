@@ -2,10 +2,12 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/Veraticus/the-spice-must-flow/internal/common"
 	"github.com/Veraticus/the-spice-must-flow/internal/model"
 )
 
@@ -300,6 +302,29 @@ func TestSQLiteStorage_GetTransactionByID(t *testing.T) {
 	}
 }
 
+func TestSQLiteStorage_GetTransactionByHash(t *testing.T) {
+	store, cleanup := createTestStorage(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	txns := createTestTransactions(2)
+	if err := store.SaveTransactions(ctx, txns); err != nil {
+		t.Fatalf("SaveTransactions() error = %v", err)
+	}
+
+	got, err := store.GetTransactionByHash(ctx, txns[1].Hash)
+	if err != nil {
+		t.Fatalf("GetTransactionByHash() error = %v", err)
+	}
+	if got.ID != txns[1].ID {
+		t.Errorf("GetTransactionByHash() ID = %s, want %s", got.ID, txns[1].ID)
+	}
+
+	if _, err := store.GetTransactionByHash(ctx, "no-such-hash"); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("GetTransactionByHash() error = %v, want ErrNotFound", err)
+	}
+}
+
 func TestSQLiteStorage_VendorOperations(t *testing.T) {
 	tests := []struct {
 		vendor   *model.Vendor
@@ -794,3 +819,71 @@ func TestSQLiteStorage_ConcurrentAccess(t *testing.T) {
 		t.Errorf("Concurrent access error: %v", err)
 	}
 }
+
+func TestSQLiteStorage_ReadOnly(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+
+	// Seed a database through a writable connection that stays open, like a classify run would
+	writer, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = writer.Close() }()
+	if err = writer.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	if _, err = writer.CreateCategory(ctx, "Groceries", "Food"); err != nil {
+		t.Fatalf("Failed to create category: %v", err)
+	}
+
+	reader, err := NewSQLiteStorageReadOnly(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open read-only storage: %v", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	if !reader.IsReadOnly() {
+		t.Error("Expected storage to report read-only mode")
+	}
+	if err = reader.Migrate(ctx); err != nil {
+		t.Errorf("Migrate on up-to-date read-only storage should succeed: %v", err)
+	}
+
+	categories, err := reader.GetCategories(ctx)
+	if err != nil {
+		t.Fatalf("Failed to read categories: %v", err)
+	}
+	if len(categories) != 1 {
+		t.Errorf("Expected 1 category, got %d", len(categories))
+	}
+
+	writes := map[string]func() error{
+		"CreateCategory": func() error {
+			_, createErr := reader.CreateCategory(ctx, "Dining", "Restaurants")
+			return createErr
+		},
+		"SaveVendor": func() error {
+			return reader.SaveVendor(ctx, &model.Vendor{Name: "Store", Category: "Groceries"})
+		},
+		"SaveTransactions": func() error {
+			return reader.SaveTransactions(ctx, createTestTransactions(1))
+		},
+		"ClearAllClassifications": func() error {
+			return reader.ClearAllClassifications(ctx)
+		},
+		"BeginTx": func() error {
+			_, txErr := reader.BeginTx(ctx)
+			return txErr
+		},
+	}
+	for name, write := range writes {
+		if writeErr := write(); !errors.Is(writeErr, ErrReadOnly) {
+			t.Errorf("%s: expected ErrReadOnly, got %v", name, writeErr)
+		}
+	}
+
+	if _, err = NewSQLiteStorageReadOnly(filepath.Join(t.TempDir(), "missing.db")); err == nil {
+		t.Error("Expected error opening a missing database read-only")
+	}
+}
@@ -19,6 +19,9 @@ func (s *SQLiteStorage) SaveTransactions(ctx context.Context, transactions []mod
 	if err := validateContext(ctx); err != nil {
 		return err
 	}
+	if err := s.checkWritable("save transactions"); err != nil {
+		return err
+	}
 	if err := validateTransactions(transactions); err != nil {
 		return err
 	}
@@ -47,6 +50,15 @@ func (s *SQLiteStorage) saveTransactionsTx(ctx context.Context, tx *sql.Tx, tran
 	// Use appropriate columns based on schema version
 	var stmt *sql.Stmt
 	switch {
+	case schemaVersion >= 23:
+		// Schema with original foreign-currency amount
+		stmt, err = tx.PrepareContext(ctx, `
+			INSERT OR IGNORE INTO transactions (
+				id, hash, date, name, merchant_name, amount, 
+				categories, account_id, transaction_type, check_number, direction,
+				original_amount, original_currency
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`)
 	case schemaVersion >= 7:
 		// Schema with direction field
 		stmt, err = tx.PrepareContext(ctx, `
@@ -94,6 +106,23 @@ func (s *SQLiteStorage) saveTransactionsTx(ctx context.Context, tx *sql.Tx, tran
 		}
 
 		switch {
+		case schemaVersion >= 23:
+			originalAmount, originalCurrency := originalCurrencyValues(txn)
+			_, err = stmt.ExecContext(ctx,
+				txn.ID,
+				txn.Hash,
+				txn.Date,
+				txn.Name,
+				txn.MerchantName,
+				txn.Amount,
+				categoriesJSON,
+				txn.AccountID,
+				txn.Type,
+				txn.CheckNumber,
+				string(txn.Direction),
+				originalAmount,
+				originalCurrency,
+			)
 		case schemaVersion >= 7:
 			_, err = stmt.ExecContext(ctx,
 				txn.ID,
@@ -142,6 +171,16 @@ func (s *SQLiteStorage) saveTransactionsTx(ctx context.Context, tx *sql.Tx, tran
 	return nil
 }
 
+// originalCurrencyValues returns the nullable column values for a transaction's
+// original foreign-currency amount. Home-currency transactions store NULLs.
+func originalCurrencyValues(txn model.Transaction) (sql.NullFloat64, sql.NullString) {
+	if !txn.IsForeignCurrency() {
+		return sql.NullFloat64{}, sql.NullString{}
+	}
+	return sql.NullFloat64{Float64: txn.OriginalAmount, Valid: true},
+		sql.NullString{String: txn.OriginalCurrency, Valid: true}
+}
+
 // GetTransactionsToClassify retrieves unclassified transactions.
 func (s *SQLiteStorage) GetTransactionsToClassify(ctx context.Context, fromDate *time.Time) ([]model.Transaction, error) {
 	if err := validateContext(ctx); err != nil {
@@ -160,7 +199,18 @@ func (s *SQLiteStorage) getTransactionsToClassifyTx(ctx context.Context, q query
 
 	// Build query based on schema version
 	var query string
-	if schemaVersion >= 5 {
+	switch {
+	case schemaVersion >= 7:
+		// Schema with direction field
+		query = `
+			SELECT t.id, t.hash, t.date, t.name, t.merchant_name, 
+			       t.amount, t.categories, t.account_id, 
+			       t.transaction_type, t.check_number, t.direction
+			FROM transactions t
+			LEFT JOIN classifications c ON t.id = c.transaction_id
+			WHERE c.transaction_id IS NULL
+		`
+	case schemaVersion >= 5:
 		query = `
 			SELECT t.id, t.hash, t.date, t.name, t.merchant_name, 
 			       t.amount, t.categories, t.account_id, 
@@ -169,7 +219,7 @@ func (s *SQLiteStorage) getTransactionsToClassifyTx(ctx context.Context, q query
 			LEFT JOIN classifications c ON t.id = c.transaction_id
 			WHERE c.transaction_id IS NULL
 		`
-	} else {
+	default:
 		query = `
 			SELECT t.id, t.hash, t.date, t.name, t.merchant_name, 
 			       t.amount, t.plaid_categories, t.account_id
@@ -200,9 +250,10 @@ func (s *SQLiteStorage) getTransactionsToClassifyTx(ctx context.Context, q query
 		var categoriesJSON sql.NullString
 		var txType sql.NullString
 		var checkNum sql.NullString
+		var direction sql.NullString
 
 		if schemaVersion >= 5 {
-			err := rows.Scan(
+			dest := []any{
 				&txn.ID,
 				&txn.Hash,
 				&txn.Date,
@@ -213,7 +264,11 @@ func (s *SQLiteStorage) getTransactionsToClassifyTx(ctx context.Context, q query
 				&txn.AccountID,
 				&txType,
 				&checkNum,
-			)
+			}
+			if schemaVersion >= 7 {
+				dest = append(dest, &direction)
+			}
+			err := rows.Scan(dest...)
 			if err != nil {
 				return nil, fmt.Errorf("failed to scan transaction: %w", err)
 			}
@@ -233,6 +288,7 @@ func (s *SQLiteStorage) getTransactionsToClassifyTx(ctx context.Context, q query
 			if checkNum.Valid {
 				txn.CheckNumber = checkNum.String
 			}
+			txn.Direction = model.TransactionDirection(direction.String)
 		} else {
 			// Old schema
 			err := rows.Scan(
@@ -279,16 +335,40 @@ func (s *SQLiteStorage) GetTransactionByID(ctx context.Context, id string) (*mod
 	return s.getTransactionByIDTx(ctx, s.db, id)
 }
 
+// GetTransactionByHash retrieves a single transaction by its duplicate detection hash.
+func (s *SQLiteStorage) GetTransactionByHash(ctx context.Context, hash string) (*model.Transaction, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if err := validateString(hash, "hash"); err != nil {
+		return nil, err
+	}
+	return s.getTransactionByHashTx(ctx, s.db, hash)
+}
+
 func (s *SQLiteStorage) getTransactionByIDTx(ctx context.Context, q queryable, id string) (*model.Transaction, error) {
+	return s.getTransactionWhereTx(ctx, q, "id", id)
+}
+
+func (s *SQLiteStorage) getTransactionByHashTx(ctx context.Context, q queryable, hash string) (*model.Transaction, error) {
+	return s.getTransactionWhereTx(ctx, q, "hash", hash)
+}
+
+// getTransactionWhereTx retrieves the transaction whose column equals value.
+// column must be a unique column name, never user input.
+func (s *SQLiteStorage) getTransactionWhereTx(ctx context.Context, q queryable, column, value string) (*model.Transaction, error) {
 	var txn model.Transaction
 	var categories sql.NullString
+	var originalAmount sql.NullFloat64
+	var originalCurrency sql.NullString
 
 	err := q.QueryRowContext(ctx, `
 		SELECT id, hash, date, name, merchant_name, 
-		       amount, categories, account_id
+		       amount, categories, account_id,
+		       original_amount, original_currency
 		FROM transactions
-		WHERE id = ?
-	`, id).Scan(
+		WHERE `+column+` = ?
+	`, value).Scan(
 		&txn.ID,
 		&txn.Hash,
 		&txn.Date,
@@ -297,6 +377,8 @@ func (s *SQLiteStorage) getTransactionByIDTx(ctx context.Context, q queryable, i
 		&txn.Amount,
 		&categories,
 		&txn.AccountID,
+		&originalAmount,
+		&originalCurrency,
 	)
 
 	if err == sql.ErrNoRows {
@@ -313,9 +395,19 @@ func (s *SQLiteStorage) getTransactionByIDTx(ctx context.Context, q queryable, i
 		}
 	}
 
+	setOriginalCurrency(&txn, originalAmount, originalCurrency)
+
 	return &txn, nil
 }
 
+// setOriginalCurrency copies nullable original-currency columns onto a transaction.
+func setOriginalCurrency(txn *model.Transaction, amount sql.NullFloat64, currency sql.NullString) {
+	if amount.Valid && currency.Valid {
+		txn.OriginalAmount = amount.Float64
+		txn.OriginalCurrency = currency.String
+	}
+}
+
 // GetTransactionsByCategory retrieves all transactions for a specific category.
 func (s *SQLiteStorage) GetTransactionsByCategory(ctx context.Context, categoryName string) ([]model.Transaction, error) {
 	if err := validateContext(ctx); err != nil {
@@ -405,6 +497,9 @@ func (s *SQLiteStorage) UpdateTransactionCategories(ctx context.Context, fromCat
 	if err := validateContext(ctx); err != nil {
 		return err
 	}
+	if err := s.checkWritable("update transaction categories"); err != nil {
+		return err
+	}
 	if err := validateString(fromCategory, "fromCategory"); err != nil {
 		return err
 	}
@@ -448,6 +543,9 @@ func (s *SQLiteStorage) UpdateTransactionCategoriesByID(ctx context.Context, fro
 	if err := validateContext(ctx); err != nil {
 		return err
 	}
+	if err := s.checkWritable("update transaction categories"); err != nil {
+		return err
+	}
 
 	// Get category names from IDs
 	var fromCategory, toCategory string
@@ -474,6 +572,64 @@ func (s *SQLiteStorage) UpdateTransactionCategoriesByID(ctx context.Context, fro
 	return s.UpdateTransactionCategories(ctx, fromCategory, toCategory)
 }
 
+// UpdateTransactionDirections sets the direction of each transaction in the map,
+// keyed by transaction ID, in a single database transaction.
+func (s *SQLiteStorage) UpdateTransactionDirections(ctx context.Context, directions map[string]model.TransactionDirection) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := s.checkWritable("update transaction directions"); err != nil {
+		return err
+	}
+	if err := validateDirections(directions); err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := s.updateTransactionDirectionsTx(ctx, tx, directions); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLiteStorage) updateTransactionDirectionsTx(ctx context.Context, q queryable, directions map[string]model.TransactionDirection) error {
+	for id, direction := range directions {
+		result, err := q.ExecContext(ctx, `UPDATE transactions SET direction = ? WHERE id = ?`, string(direction), id)
+		if err != nil {
+			return fmt.Errorf("failed to update direction of transaction %s: %w", id, err)
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rows == 0 {
+			return fmt.Errorf("transaction %s not found", id)
+		}
+	}
+	return nil
+}
+
+// validateDirections checks every transaction ID and direction in the map.
+func validateDirections(directions map[string]model.TransactionDirection) error {
+	for id, direction := range directions {
+		if err := validateString(id, "transactionID"); err != nil {
+			return err
+		}
+		switch direction {
+		case model.DirectionIncome, model.DirectionExpense, model.DirectionTransfer:
+		default:
+			return fmt.Errorf("invalid direction %q for transaction %s", direction, id)
+		}
+	}
+	return nil
+}
+
 // GetTransactionCount returns the total number of transactions.
 func (s *SQLiteStorage) GetTransactionCount(ctx context.Context) (int, error) {
 	if err := validateContext(ctx); err != nil {
@@ -617,6 +773,324 @@ func (s *SQLiteStorage) GetMerchantSummary(ctx context.Context, start, end time.
 	return summary, rows.Err()
 }
 
+// GetClassificationCoverage counts classified, pending and skipped transactions
+// per month between start and end, oldest month first. A zero start or end
+// leaves that side of the range open.
+func (s *SQLiteStorage) GetClassificationCoverage(ctx context.Context, start, end time.Time) ([]model.CoverageMonth, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	where, args := coverageDateFilter(start, end)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT strftime('%Y-%m', t.date) AS month,
+		       COUNT(*),
+		       SUM(CASE WHEN c.transaction_id IS NULL THEN 1 ELSE 0 END),
+		       SUM(CASE WHEN c.status = ? THEN 1 ELSE 0 END)
+		FROM transactions t
+		LEFT JOIN classifications c ON t.id = c.transaction_id
+		`+where+`
+		GROUP BY month
+		ORDER BY month
+	`, append([]any{string(model.StatusUnclassified)}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query classification coverage: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var months []model.CoverageMonth
+	for rows.Next() {
+		var m model.CoverageMonth
+		if err := rows.Scan(&m.Month, &m.Total, &m.Pending, &m.Skipped); err != nil {
+			return nil, fmt.Errorf("failed to scan classification coverage: %w", err)
+		}
+		m.Classified = m.Total - m.Pending - m.Skipped
+		months = append(months, m)
+	}
+
+	return months, rows.Err()
+}
+
+// GetUnclassifiedMerchants returns the merchants with the most transactions
+// lacking a category between start and end, largest amount first on ties.
+// A zero start or end leaves that side of the range open; limit <= 0 returns all.
+func (s *SQLiteStorage) GetUnclassifiedMerchants(ctx context.Context, start, end time.Time, limit int) ([]model.UnclassifiedMerchant, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	where, args := coverageDateFilter(start, end)
+	if where == "" {
+		where = "WHERE "
+	} else {
+		where += " AND "
+	}
+	where += "(c.transaction_id IS NULL OR c.status = ?)"
+	args = append(args, string(model.StatusUnclassified))
+
+	query := `
+		SELECT COALESCE(NULLIF(t.merchant_name, ''), t.name) AS merchant,
+		       COUNT(*),
+		       SUM(CASE WHEN c.transaction_id IS NULL THEN 1 ELSE 0 END),
+		       SUM(ABS(t.amount))
+		FROM transactions t
+		LEFT JOIN classifications c ON t.id = c.transaction_id
+		` + where + `
+		GROUP BY merchant
+		ORDER BY COUNT(*) DESC, SUM(ABS(t.amount)) DESC, merchant
+	`
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unclassified merchants: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var merchants []model.UnclassifiedMerchant
+	for rows.Next() {
+		var m model.UnclassifiedMerchant
+		if err := rows.Scan(&m.Merchant, &m.Count, &m.Pending, &m.Amount); err != nil {
+			return nil, fmt.Errorf("failed to scan unclassified merchant: %w", err)
+		}
+		m.Skipped = m.Count - m.Pending
+		merchants = append(merchants, m)
+	}
+
+	return merchants, rows.Err()
+}
+
+// GetCategoryAmountStats returns the count, average and largest amount of the
+// transactions classified in each category, keyed by category name. Refunds are
+// left out so they don't skew their purchase category.
+func (s *SQLiteStorage) GetCategoryAmountStats(ctx context.Context) (map[string]model.CategoryAmountStats, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT c.category, COUNT(*), AVG(ABS(t.amount)), MAX(ABS(t.amount))
+		FROM transactions t
+		JOIN classifications c ON t.id = c.transaction_id
+		WHERE c.status != ? AND c.category IS NOT NULL AND c.category != ''
+			AND COALESCE(t.is_refund, 0) = 0
+		GROUP BY c.category
+	`, string(model.StatusUnclassified))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query category amount stats: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	stats := make(map[string]model.CategoryAmountStats)
+	for rows.Next() {
+		var st model.CategoryAmountStats
+		if err := rows.Scan(&st.Category, &st.Count, &st.Average, &st.Max); err != nil {
+			return nil, fmt.Errorf("failed to scan category amount stats: %w", err)
+		}
+		stats[st.Category] = st
+	}
+
+	return stats, rows.Err()
+}
+
+// coverageDateFilter builds a WHERE clause for an optional date range.
+func coverageDateFilter(start, end time.Time) (string, []any) {
+	var conditions []string
+	var args []any
+	if !start.IsZero() {
+		conditions = append(conditions, "t.date >= ?")
+		args = append(args, start)
+	}
+	if !end.IsZero() {
+		conditions = append(conditions, "t.date <= ?")
+		args = append(args, end)
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// QueryTransactions returns transactions matching the query along with their
+// classification. Unclassified transactions have StatusUnclassified and no category.
+func (s *SQLiteStorage) QueryTransactions(ctx context.Context, query model.TransactionQuery) ([]model.Classification, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if err := query.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid transaction query: %w", err)
+	}
+	return s.queryTransactionsTx(ctx, s.db, query)
+}
+
+func (s *SQLiteStorage) queryTransactionsTx(ctx context.Context, q queryable, query model.TransactionQuery) ([]model.Classification, error) {
+	sqlQuery, args := buildTransactionQuery(query)
+
+	rows, err := q.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transactions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var results []model.Classification
+	for rows.Next() {
+		var c model.Classification
+		var categories sql.NullString
+		var txType sql.NullString
+		var checkNum sql.NullString
+		var direction sql.NullString
+		var originalAmount sql.NullFloat64
+		var originalCurrency sql.NullString
+		var category sql.NullString
+		var status sql.NullString
+		var confidence sql.NullFloat64
+		var classifiedAt sql.NullTime
+		var notes sql.NullString
+		var businessPercent sql.NullFloat64
+
+		err := rows.Scan(
+			&c.Transaction.ID,
+			&c.Transaction.Hash,
+			&c.Transaction.Date,
+			&c.Transaction.Name,
+			&c.Transaction.MerchantName,
+			&c.Transaction.Amount,
+			&categories,
+			&c.Transaction.AccountID,
+			&txType,
+			&checkNum,
+			&direction,
+			&originalAmount,
+			&originalCurrency,
+			&category,
+			&status,
+			&confidence,
+			&classifiedAt,
+			&notes,
+			&businessPercent,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+
+		if categories.Valid && categories.String != "" {
+			if err := json.Unmarshal([]byte(categories.String), &c.Transaction.Category); err != nil {
+				slog.Warn("Failed to parse categories JSON", "error", err, "json", categories.String)
+			}
+		}
+		c.Transaction.Type = txType.String
+		c.Transaction.CheckNumber = checkNum.String
+		c.Transaction.Direction = model.TransactionDirection(direction.String)
+		setOriginalCurrency(&c.Transaction, originalAmount, originalCurrency)
+
+		c.Status = model.StatusUnclassified
+		if status.Valid {
+			c.Status = model.ClassificationStatus(status.String)
+			c.Category = category.String
+			c.Confidence = confidence.Float64
+			c.ClassifiedAt = classifiedAt.Time
+			c.Notes = notes.String
+			c.BusinessPercent = businessPercent.Float64
+		}
+
+		results = append(results, c)
+	}
+
+	return results, rows.Err()
+}
+
+// buildTransactionQuery renders a TransactionQuery into SQL and its arguments.
+func buildTransactionQuery(query model.TransactionQuery) (string, []any) {
+	var where []string
+	var args []any
+
+	if query.StartDate != nil {
+		where = append(where, "t.date >= ?")
+		args = append(args, *query.StartDate)
+	}
+	if query.EndDate != nil {
+		where = append(where, "t.date <= ?")
+		args = append(args, *query.EndDate)
+	}
+	if query.MinAmount != nil {
+		where = append(where, "ABS(t.amount) >= ?")
+		args = append(args, *query.MinAmount)
+	}
+	if query.MaxAmount != nil {
+		where = append(where, "ABS(t.amount) <= ?")
+		args = append(args, *query.MaxAmount)
+	}
+	if query.Category != "" {
+		where = append(where, "c.category = ? COLLATE NOCASE")
+		args = append(args, query.Category)
+	}
+	if query.Merchant != "" {
+		pattern := "%" + escapeLike(query.Merchant) + "%"
+		where = append(where, `(t.merchant_name LIKE ? ESCAPE '\' OR t.name LIKE ? ESCAPE '\')`)
+		args = append(args, pattern, pattern)
+	}
+	if query.Direction != "" {
+		where = append(where, "t.direction = ?")
+		args = append(args, string(query.Direction))
+	}
+	if query.MissingDirection {
+		where = append(where, "COALESCE(t.direction, '') = ''")
+	}
+	if query.Status != "" {
+		where = append(where, "COALESCE(c.status, ?) = ?")
+		args = append(args, string(model.StatusUnclassified), string(query.Status))
+	}
+
+	sqlQuery := `
+		SELECT
+			t.id, t.hash, t.date, t.name, t.merchant_name,
+			t.amount, t.categories, t.account_id,
+			t.transaction_type, t.check_number, t.direction,
+			t.original_amount, t.original_currency,
+			c.category, c.status, c.confidence, c.classified_at, c.notes,
+			c.business_percent
+		FROM transactions t
+		LEFT JOIN classifications c ON c.transaction_id = t.id
+	`
+	if len(where) > 0 {
+		sqlQuery += " WHERE " + strings.Join(where, " AND ")
+	}
+
+	order := "ASC"
+	if query.Descending {
+		order = "DESC"
+	}
+	var sortColumn string
+	switch query.SortBy {
+	case model.SortByAmount:
+		sortColumn = "ABS(t.amount)"
+	case model.SortByMerchant:
+		sortColumn = "t.merchant_name COLLATE NOCASE"
+	case model.SortByCategory:
+		sortColumn = "c.category COLLATE NOCASE"
+	default:
+		sortColumn = "t.date"
+	}
+	// Tie-break on date and id so results are stable across runs
+	sqlQuery += fmt.Sprintf(" ORDER BY %s %s, t.date %s, t.id", sortColumn, order, order)
+
+	if query.Limit > 0 {
+		sqlQuery += " LIMIT ?"
+		args = append(args, query.Limit)
+	}
+
+	return sqlQuery, args
+}
+
+// escapeLike escapes LIKE wildcards so user input matches literally.
+func escapeLike(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	return replacer.Replace(s)
+}
+
 // scanTransactions is a helper method to scan transaction rows based on schema version.
 func (s *SQLiteStorage) scanTransactions(_ context.Context, rows *sql.Rows, schemaVersion int) ([]model.Transaction, error) {
 	var transactions []model.Transaction
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -391,3 +392,281 @@ func TestSQLiteStorage_TransactionClassificationState(t *testing.T) {
 		t.Error("Wrong transactions remained unclassified")
 	}
 }
+
+func TestSQLiteStorage_QueryTransactions(t *testing.T) {
+	store, cleanup := createTestStorageWithCategories(t, "Groceries", "Dining", "Salary")
+	defer cleanup()
+	ctx := context.Background()
+
+	base := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	transactions := []model.Transaction{
+		{ID: "grocery-1", Date: base, Name: "WHOLE FOODS #12", MerchantName: "Whole Foods", Amount: 85.20, AccountID: "acc1", Direction: model.DirectionExpense},
+		{ID: "grocery-2", Date: base.AddDate(0, 0, 10), Name: "WHOLE FOODS #12", MerchantName: "Whole Foods", Amount: 42.10, AccountID: "acc1", Direction: model.DirectionExpense},
+		{ID: "dining-1", Date: base.AddDate(0, 0, 5), Name: "CAFE 100%", MerchantName: "Corner Cafe", Amount: 12.50, AccountID: "acc1", Direction: model.DirectionExpense},
+		{ID: "salary-1", Date: base.AddDate(0, 0, 14), Name: "PAYROLL", MerchantName: "Acme Corp", Amount: -2500.00, AccountID: "acc1", Direction: model.DirectionIncome},
+		{ID: "unknown-1", Date: base.AddDate(0, 1, 0), Name: "MYSTERY SHOP", MerchantName: "Mystery Shop", Amount: 19.99, AccountID: "acc2", Direction: model.DirectionExpense},
+	}
+	for i := range transactions {
+		transactions[i].Hash = transactions[i].GenerateHash()
+	}
+	if err := store.SaveTransactions(ctx, transactions); err != nil {
+		t.Fatalf("Failed to save transactions: %v", err)
+	}
+
+	classify := map[string]struct {
+		category string
+		status   model.ClassificationStatus
+	}{
+		"grocery-1": {"Groceries", model.StatusClassifiedByAI},
+		"grocery-2": {"Groceries", model.StatusUserModified},
+		"dining-1":  {"Dining", model.StatusClassifiedByRule},
+		"salary-1":  {"Salary", model.StatusClassifiedByAI},
+	}
+	for _, txn := range transactions {
+		c, ok := classify[txn.ID]
+		if !ok {
+			continue
+		}
+		if err := store.SaveClassification(ctx, &model.Classification{
+			Transaction:  txn,
+			Category:     c.category,
+			Status:       c.status,
+			Confidence:   0.9,
+			ClassifiedAt: time.Now(),
+		}); err != nil {
+			t.Fatalf("Failed to save classification: %v", err)
+		}
+	}
+
+	ptrTime := func(tm time.Time) *time.Time { return &tm }
+	ptrFloat := func(f float64) *float64 { return &f }
+
+	tests := []struct {
+		name  string
+		query model.TransactionQuery
+		want  []string
+	}{
+		{
+			name:  "no filters sorts by date ascending",
+			query: model.TransactionQuery{},
+			want:  []string{"grocery-1", "dining-1", "grocery-2", "salary-1", "unknown-1"},
+		},
+		{
+			name:  "date range",
+			query: model.TransactionQuery{StartDate: ptrTime(base.AddDate(0, 0, 1)), EndDate: ptrTime(base.AddDate(0, 0, 14))},
+			want:  []string{"dining-1", "grocery-2", "salary-1"},
+		},
+		{
+			name:  "category is case-insensitive",
+			query: model.TransactionQuery{Category: "groceries"},
+			want:  []string{"grocery-1", "grocery-2"},
+		},
+		{
+			name:  "merchant substring matches name or description",
+			query: model.TransactionQuery{Merchant: "whole"},
+			want:  []string{"grocery-1", "grocery-2"},
+		},
+		{
+			name:  "merchant wildcards match literally",
+			query: model.TransactionQuery{Merchant: "100%"},
+			want:  []string{"dining-1"},
+		},
+		{
+			name:  "amount range uses absolute amounts",
+			query: model.TransactionQuery{MinAmount: ptrFloat(40), MaxAmount: ptrFloat(3000)},
+			want:  []string{"grocery-1", "grocery-2", "salary-1"},
+		},
+		{
+			name:  "direction",
+			query: model.TransactionQuery{Direction: model.DirectionIncome},
+			want:  []string{"salary-1"},
+		},
+		{
+			name:  "unclassified status",
+			query: model.TransactionQuery{Status: model.StatusUnclassified},
+			want:  []string{"unknown-1"},
+		},
+		{
+			name:  "classified status",
+			query: model.TransactionQuery{Status: model.StatusUserModified},
+			want:  []string{"grocery-2"},
+		},
+		{
+			name:  "sort by amount descending with limit",
+			query: model.TransactionQuery{SortBy: model.SortByAmount, Descending: true, Limit: 2},
+			want:  []string{"salary-1", "grocery-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.QueryTransactions(ctx, tt.query)
+			if err != nil {
+				t.Fatalf("QueryTransactions failed: %v", err)
+			}
+			var ids []string
+			for _, c := range got {
+				ids = append(ids, c.Transaction.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Expected %v, got %v", tt.want, ids)
+			}
+		})
+	}
+
+	t.Run("returns classification details", func(t *testing.T) {
+		got, err := store.QueryTransactions(ctx, model.TransactionQuery{Merchant: "Mystery"})
+		if err != nil {
+			t.Fatalf("QueryTransactions failed: %v", err)
+		}
+		if len(got) != 1 || got[0].Status != model.StatusUnclassified || got[0].Category != "" {
+			t.Errorf("Expected one unclassified transaction without category, got %+v", got)
+		}
+
+		got, err = store.QueryTransactions(ctx, model.TransactionQuery{Category: "Dining"})
+		if err != nil {
+			t.Fatalf("QueryTransactions failed: %v", err)
+		}
+		if len(got) != 1 || got[0].Status != model.StatusClassifiedByRule || got[0].Transaction.Direction != model.DirectionExpense {
+			t.Errorf("Expected rule-classified expense, got %+v", got)
+		}
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		if _, err := store.QueryTransactions(ctx, model.TransactionQuery{MinAmount: ptrFloat(10), MaxAmount: ptrFloat(5)}); err == nil {
+			t.Error("Expected error for inverted amount range")
+		}
+		if _, err := store.QueryTransactions(ctx, model.TransactionQuery{SortBy: "color"}); err == nil {
+			t.Error("Expected error for unknown sort field")
+		}
+	})
+}
+
+func TestSQLiteStorage_UpdateTransactionDirections(t *testing.T) {
+	store, cleanup := createTestStorage(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	txns := createTestTransactions(3)
+	txns[0].Direction = model.DirectionExpense
+	txns[1].Direction = ""
+	txns[2].Direction = ""
+	if err := store.SaveTransactions(ctx, txns); err != nil {
+		t.Fatalf("Failed to save transactions: %v", err)
+	}
+
+	missing, err := store.QueryTransactions(ctx, model.TransactionQuery{MissingDirection: true})
+	if err != nil {
+		t.Fatalf("Failed to query transactions: %v", err)
+	}
+	if len(missing) != 2 {
+		t.Fatalf("Expected 2 transactions without a direction, got %d", len(missing))
+	}
+
+	err = store.UpdateTransactionDirections(ctx, map[string]model.TransactionDirection{
+		txns[1].ID: model.DirectionIncome,
+		txns[2].ID: model.DirectionTransfer,
+	})
+	if err != nil {
+		t.Fatalf("Failed to update directions: %v", err)
+	}
+
+	missing, err = store.QueryTransactions(ctx, model.TransactionQuery{MissingDirection: true})
+	if err != nil {
+		t.Fatalf("Failed to query transactions: %v", err)
+	}
+	if len(missing) != 0 {
+		t.Errorf("Expected no transactions without a direction, got %d", len(missing))
+	}
+
+	transfers, err := store.QueryTransactions(ctx, model.TransactionQuery{Direction: model.DirectionTransfer})
+	if err != nil {
+		t.Fatalf("Failed to query transactions: %v", err)
+	}
+	if len(transfers) != 1 || transfers[0].Transaction.ID != txns[2].ID {
+		t.Errorf("Expected %s to be the only transfer, got %v", txns[2].ID, transfers)
+	}
+
+	if err := store.UpdateTransactionDirections(ctx, map[string]model.TransactionDirection{txns[0].ID: "sideways"}); err == nil {
+		t.Error("Expected an error for an invalid direction")
+	}
+	if err := store.UpdateTransactionDirections(ctx, map[string]model.TransactionDirection{"missing": model.DirectionIncome}); err == nil {
+		t.Error("Expected an error for an unknown transaction")
+	}
+}
+
+func TestSQLiteStorage_ClassificationCoverage(t *testing.T) {
+	store, cleanup := createTestStorageWithCategories(t, "Food")
+	defer cleanup()
+	ctx := context.Background()
+
+	jan := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC)
+	txns := []model.Transaction{
+		{ID: "jan-classified", Date: jan, Name: "Cafe", MerchantName: "Cafe", Amount: 5, AccountID: "acc1"},
+		{ID: "jan-pending", Date: jan, Name: "Mystery", MerchantName: "Mystery", Amount: 20, AccountID: "acc1"},
+		{ID: "feb-pending", Date: feb, Name: "Mystery", MerchantName: "Mystery", Amount: -30, AccountID: "acc1"},
+		{ID: "feb-skipped", Date: feb, Name: "HARDWARE 123", MerchantName: "", Amount: 100, AccountID: "acc1"},
+	}
+	if err := store.SaveTransactions(ctx, txns); err != nil {
+		t.Fatalf("Failed to save transactions: %v", err)
+	}
+	for _, c := range []model.Classification{
+		{Transaction: txns[0], Category: "Food", Status: model.StatusClassifiedByAI, Confidence: 0.9, ClassifiedAt: jan},
+		{Transaction: txns[3], Status: model.StatusUnclassified, ClassifiedAt: feb},
+	} {
+		if err := store.SaveClassification(ctx, &c); err != nil {
+			t.Fatalf("Failed to save classification: %v", err)
+		}
+	}
+
+	months, err := store.GetClassificationCoverage(ctx, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("GetClassificationCoverage failed: %v", err)
+	}
+	want := []model.CoverageMonth{
+		{Month: "2024-01", Total: 2, Classified: 1, Pending: 1},
+		{Month: "2024-02", Total: 2, Pending: 1, Skipped: 1},
+	}
+	if len(months) != len(want) {
+		t.Fatalf("Expected %d months, got %v", len(want), months)
+	}
+	for i := range want {
+		if months[i] != want[i] {
+			t.Errorf("Month %d: expected %+v, got %+v", i, want[i], months[i])
+		}
+	}
+
+	febOnly, err := store.GetClassificationCoverage(ctx, feb.AddDate(0, 0, -1), time.Time{})
+	if err != nil {
+		t.Fatalf("GetClassificationCoverage failed: %v", err)
+	}
+	if len(febOnly) != 1 || febOnly[0].Month != "2024-02" {
+		t.Errorf("Expected only February, got %v", febOnly)
+	}
+
+	merchants, err := store.GetUnclassifiedMerchants(ctx, time.Time{}, time.Time{}, 0)
+	if err != nil {
+		t.Fatalf("GetUnclassifiedMerchants failed: %v", err)
+	}
+	wantMerchants := []model.UnclassifiedMerchant{
+		{Merchant: "Mystery", Count: 2, Pending: 2, Amount: 50},
+		{Merchant: "HARDWARE 123", Count: 1, Skipped: 1, Amount: 100},
+	}
+	if len(merchants) != len(wantMerchants) {
+		t.Fatalf("Expected %d merchants, got %v", len(wantMerchants), merchants)
+	}
+	for i := range wantMerchants {
+		if merchants[i] != wantMerchants[i] {
+			t.Errorf("Merchant %d: expected %+v, got %+v", i, wantMerchants[i], merchants[i])
+		}
+	}
+
+	top, err := store.GetUnclassifiedMerchants(ctx, time.Time{}, time.Time{}, 1)
+	if err != nil {
+		t.Fatalf("GetUnclassifiedMerchants failed: %v", err)
+	}
+	if len(top) != 1 || top[0].Merchant != "Mystery" {
+		t.Errorf("Expected only Mystery with limit 1, got %v", top)
+	}
+}
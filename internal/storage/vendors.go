@@ -3,13 +3,19 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Veraticus/the-spice-must-flow/internal/common"
 	"github.com/Veraticus/the-spice-must-flow/internal/model"
 )
 
+// ErrMultiCategoryVendor is returned when saving a vendor rule for a merchant
+// marked multi-category.
+var ErrMultiCategoryVendor = errors.New("vendor is marked multi-category")
+
 // GetVendor retrieves a vendor by name.
 func (s *SQLiteStorage) GetVendor(ctx context.Context, merchantName string) (*model.Vendor, error) {
 	if err := validateContext(ctx); err != nil {
@@ -27,12 +33,24 @@ func (s *SQLiteStorage) GetVendor(ctx context.Context, merchantName string) (*mo
 	return s.getVendorTx(ctx, s.db, merchantName)
 }
 
+// GetVendorRule retrieves the exact vendor rule of a merchant: the vendor of
+// that name or, unless vendor matching is case sensitive, of another spelling.
+func (s *SQLiteStorage) GetVendorRule(ctx context.Context, merchantName string) (*model.Vendor, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if err := validateString(merchantName, "merchantName"); err != nil {
+		return nil, err
+	}
+	return s.getVendorRuleTx(ctx, s.db, merchantName)
+}
+
 func (s *SQLiteStorage) getVendorTx(ctx context.Context, q queryable, merchantName string) (*model.Vendor, error) {
 	var vendor model.Vendor
 	var source string
 
 	err := q.QueryRowContext(ctx, `
-		SELECT name, category, last_updated, use_count, source, is_regex
+		SELECT name, category, last_updated, use_count, source, is_regex, multi_category
 		FROM vendors
 		WHERE name = ?
 	`, merchantName).Scan(
@@ -42,6 +60,7 @@ func (s *SQLiteStorage) getVendorTx(ctx context.Context, q queryable, merchantNa
 		&vendor.UseCount,
 		&source,
 		&vendor.IsRegex,
+		&vendor.MultiCategory,
 	)
 
 	if err == sql.ErrNoRows {
@@ -65,6 +84,9 @@ func (s *SQLiteStorage) SaveVendor(ctx context.Context, vendor *model.Vendor) er
 	if err := validateContext(ctx); err != nil {
 		return err
 	}
+	if err := s.checkWritable("save vendor"); err != nil {
+		return err
+	}
 	if err := validateVendor(vendor); err != nil {
 		return err
 	}
@@ -92,6 +114,22 @@ func (s *SQLiteStorage) saveVendorTx(ctx context.Context, tx *sql.Tx, vendor *mo
 		vendor.Source = model.SourceAuto
 	}
 
+	// Without case-sensitive matching "AMAZON" and "Amazon" share one rule, so
+	// update an existing rule under its own spelling rather than adding a twin
+	if !vendor.IsRegex && !s.vendorCaseSensitive {
+		var existing string
+		err := tx.QueryRowContext(ctx, `
+			SELECT name FROM vendors WHERE name = ? COLLATE NOCASE AND is_regex = FALSE
+			ORDER BY name = ? DESC, use_count DESC, name LIMIT 1
+		`, vendor.Name, vendor.Name).Scan(&existing)
+		switch {
+		case err == nil:
+			vendor.Name = existing
+		case err != sql.ErrNoRows:
+			return fmt.Errorf("failed to check vendor: %w", err)
+		}
+	}
+
 	// Validate category exists
 	var categoryExists bool
 	err := tx.QueryRowContext(ctx, `
@@ -106,6 +144,15 @@ func (s *SQLiteStorage) saveVendorTx(ctx context.Context, tx *sql.Tx, vendor *mo
 		return fmt.Errorf("category '%s' does not exist", vendor.Category)
 	}
 
+	// A multi-category merchant must not get a rule through an ordinary save
+	multiCategory, err := s.isMultiCategoryVendorTx(ctx, tx, vendor.Name)
+	if err != nil {
+		return err
+	}
+	if multiCategory {
+		return fmt.Errorf("%w: %s", ErrMultiCategoryVendor, vendor.Name)
+	}
+
 	_, err = tx.ExecContext(ctx, `
 		INSERT INTO vendors (name, category, last_updated, use_count, source, is_regex)
 		VALUES (?, ?, ?, ?, ?, ?)
@@ -137,7 +184,7 @@ func (s *SQLiteStorage) GetAllVendors(ctx context.Context) ([]model.Vendor, erro
 
 func (s *SQLiteStorage) getAllVendorsTx(ctx context.Context, q queryable) ([]model.Vendor, error) {
 	rows, err := q.QueryContext(ctx, `
-		SELECT name, category, last_updated, use_count, source, is_regex
+		SELECT name, category, last_updated, use_count, source, is_regex, multi_category
 		FROM vendors
 		ORDER BY name
 	`)
@@ -157,6 +204,7 @@ func (s *SQLiteStorage) getAllVendorsTx(ctx context.Context, q queryable) ([]mod
 			&vendor.UseCount,
 			&source,
 			&vendor.IsRegex,
+			&vendor.MultiCategory,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan vendor: %w", err)
@@ -173,6 +221,9 @@ func (s *SQLiteStorage) DeleteVendor(ctx context.Context, merchantName string) e
 	if err := validateContext(ctx); err != nil {
 		return err
 	}
+	if err := s.checkWritable("delete vendor"); err != nil {
+		return err
+	}
 	if err := validateString(merchantName, "merchantName"); err != nil {
 		return err
 	}
@@ -299,7 +350,7 @@ func (s *SQLiteStorage) GetVendorsByCategory(ctx context.Context, categoryName s
 
 func (s *SQLiteStorage) getVendorsByCategoryTx(ctx context.Context, q queryable, categoryName string) ([]model.Vendor, error) {
 	rows, err := q.QueryContext(ctx, `
-		SELECT name, category, last_updated, use_count, source, is_regex
+		SELECT name, category, last_updated, use_count, source, is_regex, multi_category
 		FROM vendors
 		WHERE category = ?
 		ORDER BY name
@@ -320,6 +371,7 @@ func (s *SQLiteStorage) getVendorsByCategoryTx(ctx context.Context, q queryable,
 			&vendor.UseCount,
 			&source,
 			&vendor.IsRegex,
+			&vendor.MultiCategory,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan vendor: %w", err)
@@ -357,6 +409,9 @@ func (s *SQLiteStorage) UpdateVendorCategories(ctx context.Context, fromCategory
 	if err := validateContext(ctx); err != nil {
 		return err
 	}
+	if err := s.checkWritable("update vendor categories"); err != nil {
+		return err
+	}
 	if err := validateString(fromCategory, "fromCategory"); err != nil {
 		return err
 	}
@@ -405,6 +460,9 @@ func (s *SQLiteStorage) UpdateVendorCategoriesByID(ctx context.Context, fromCate
 	if err := validateContext(ctx); err != nil {
 		return err
 	}
+	if err := s.checkWritable("update vendor categories"); err != nil {
+		return err
+	}
 
 	// Get category names from IDs
 	var fromCategory, toCategory string
@@ -441,7 +499,7 @@ func (s *SQLiteStorage) GetVendorsBySource(ctx context.Context, source model.Ven
 
 func (s *SQLiteStorage) getVendorsBySourceTx(ctx context.Context, q queryable, source model.VendorSource) ([]model.Vendor, error) {
 	rows, err := q.QueryContext(ctx, `
-		SELECT name, category, last_updated, use_count, source, is_regex
+		SELECT name, category, last_updated, use_count, source, is_regex, multi_category
 		FROM vendors
 		WHERE source = ?
 		ORDER BY name
@@ -462,6 +520,7 @@ func (s *SQLiteStorage) getVendorsBySourceTx(ctx context.Context, q queryable, s
 			&vendor.UseCount,
 			&sourceStr,
 			&vendor.IsRegex,
+			&vendor.MultiCategory,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan vendor: %w", err)
@@ -478,6 +537,9 @@ func (s *SQLiteStorage) DeleteVendorsBySource(ctx context.Context, source model.
 	if err := validateContext(ctx); err != nil {
 		return err
 	}
+	if err := s.checkWritable("delete vendors"); err != nil {
+		return err
+	}
 
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -514,7 +576,8 @@ func (s *SQLiteStorage) DeleteVendorsBySource(ctx context.Context, source model.
 }
 
 // FindVendorMatch looks for a vendor that matches the given merchant name.
-// It first checks for an exact match, then checks regex vendors.
+// It first checks for an exact match, then, unless vendor matching is case
+// sensitive, for one ignoring case, and finally checks regex vendors.
 func (s *SQLiteStorage) FindVendorMatch(ctx context.Context, merchantName string) (*model.Vendor, error) {
 	if err := validateContext(ctx); err != nil {
 		return nil, err
@@ -523,7 +586,7 @@ func (s *SQLiteStorage) FindVendorMatch(ctx context.Context, merchantName string
 		return nil, err
 	}
 
-	// First try exact match (for performance)
+	// Exact matches always beat regex vendors, however broad the regex
 	vendor, err := s.GetVendor(ctx, merchantName)
 	if err == nil && vendor != nil && !vendor.IsRegex {
 		return vendor, nil
@@ -532,13 +595,66 @@ func (s *SQLiteStorage) FindVendorMatch(ctx context.Context, merchantName string
 		return nil, err
 	}
 
+	if !s.vendorCaseSensitive {
+		vendor, err = s.findExactVendorIgnoreCase(ctx, s.db, merchantName)
+		if err == nil {
+			return vendor, nil
+		}
+		if err != sql.ErrNoRows {
+			return nil, err
+		}
+	}
+
 	// If no exact match, check regex vendors
 	return s.findRegexVendorMatch(ctx, merchantName)
 }
 
+// getVendorRuleTx returns the exact vendor rule of a merchant: the vendor of
+// that name or, unless vendor matching is case sensitive, of another spelling.
+func (s *SQLiteStorage) getVendorRuleTx(ctx context.Context, q queryable, merchantName string) (*model.Vendor, error) {
+	vendor, err := s.getVendorTx(ctx, q, merchantName)
+	if err != sql.ErrNoRows || s.vendorCaseSensitive {
+		return vendor, err
+	}
+	return s.findExactVendorIgnoreCase(ctx, q, merchantName)
+}
+
+// findExactVendorIgnoreCase finds a non-regex vendor whose name equals the merchant ignoring case.
+func (s *SQLiteStorage) findExactVendorIgnoreCase(ctx context.Context, q queryable, merchantName string) (*model.Vendor, error) {
+	var vendor model.Vendor
+	var source string
+
+	err := q.QueryRowContext(ctx, `
+		SELECT name, category, last_updated, use_count, source, is_regex, multi_category
+		FROM vendors
+		WHERE name = ? COLLATE NOCASE AND is_regex = FALSE
+		ORDER BY use_count DESC, name
+		LIMIT 1
+	`, merchantName).Scan(
+		&vendor.Name,
+		&vendor.Category,
+		&vendor.LastUpdated,
+		&vendor.UseCount,
+		&source,
+		&vendor.IsRegex,
+		&vendor.MultiCategory,
+	)
+	if err == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vendor: %w", err)
+	}
+	vendor.Source = model.VendorSource(source)
+
+	return &vendor, nil
+}
+
+// findRegexVendorMatch returns the matching regex vendor that ranks first under
+// the configured precedence. Ties fall back to the vendor name for determinism.
 func (s *SQLiteStorage) findRegexVendorMatch(ctx context.Context, merchantName string) (*model.Vendor, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT name, category, last_updated, use_count, source, is_regex
+		SELECT name, category, last_updated, use_count, source, is_regex, multi_category
 		FROM vendors
 		WHERE is_regex = TRUE
 		ORDER BY use_count DESC, name
@@ -548,6 +664,7 @@ func (s *SQLiteStorage) findRegexVendorMatch(ctx context.Context, merchantName s
 	}
 	defer func() { _ = rows.Close() }()
 
+	var best *model.Vendor
 	for rows.Next() {
 		var vendor model.Vendor
 		var source string
@@ -558,6 +675,7 @@ func (s *SQLiteStorage) findRegexVendorMatch(ctx context.Context, merchantName s
 			&vendor.UseCount,
 			&source,
 			&vendor.IsRegex,
+			&vendor.MultiCategory,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan vendor: %w", err)
@@ -570,10 +688,9 @@ func (s *SQLiteStorage) findRegexVendorMatch(ctx context.Context, merchantName s
 			// Invalid regex, skip this vendor
 			continue
 		}
-		if matched {
-			// Cache the match for future use
-			s.cacheVendor(&vendor)
-			return &vendor, nil
+		if matched && (best == nil || regexVendorBeats(s.regexPrecedence, &vendor, best)) {
+			match := vendor
+			best = &match
 		}
 	}
 
@@ -581,5 +698,279 @@ func (s *SQLiteStorage) findRegexVendorMatch(ctx context.Context, merchantName s
 		return nil, fmt.Errorf("error iterating regex vendors: %w", err)
 	}
 
-	return nil, sql.ErrNoRows
+	if best == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	// Cache the match for future use
+	s.cacheVendor(best)
+	return best, nil
+}
+
+// regexVendorBeats reports whether candidate should win over current under the
+// given precedence. Rows arrive ordered by use count then name, so equal
+// candidates keep the earlier vendor.
+func regexVendorBeats(precedence model.RegexVendorPrecedence, candidate, current *model.Vendor) bool {
+	candidateSpecificity := regexSpecificity(candidate.Name)
+	currentSpecificity := regexSpecificity(current.Name)
+
+	if precedence == model.PrecedenceSpecificity {
+		if candidateSpecificity != currentSpecificity {
+			return candidateSpecificity > currentSpecificity
+		}
+		return candidate.UseCount > current.UseCount
+	}
+
+	if candidate.UseCount != current.UseCount {
+		return candidate.UseCount > current.UseCount
+	}
+	return candidateSpecificity > currentSpecificity
+}
+
+// regexSpecificity counts the literal characters in a pattern, so "AMAZON MKTP.*"
+// is more specific than ".*AMAZON.*".
+func regexSpecificity(pattern string) int {
+	count := 0
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			count++
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case strings.ContainsRune(".*+?()[]{}|^$", r):
+		default:
+			count++
+		}
+	}
+	return count
+}
+
+// RecomputeVendorUseCounts recounts each vendor's uses from the classifications
+// it accounts for and stores the corrected counts. A classification counts
+// toward the vendor its merchant resolves to, as FindVendorMatch would resolve
+// it, when it has that vendor's category. It returns the vendors whose count changed.
+func (s *SQLiteStorage) RecomputeVendorUseCounts(ctx context.Context) ([]model.VendorRecount, error) {
+	if err := validateContext(ctx); err != nil {
+		return nil, err
+	}
+	if err := s.checkWritable("recompute vendor use counts"); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	changes, err := s.recomputeVendorUseCountsTx(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return changes, nil
+}
+
+func (s *SQLiteStorage) recomputeVendorUseCountsTx(ctx context.Context, tx *sql.Tx) ([]model.VendorRecount, error) {
+	vendors, err := s.getAllVendorsTx(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT t.merchant_name, c.category, COUNT(*)
+		FROM classifications c
+		JOIN transactions t ON t.id = c.transaction_id
+		WHERE t.merchant_name != '' AND c.category IS NOT NULL AND c.category != ''
+			AND c.status != ?
+		GROUP BY t.merchant_name, c.category
+	`, string(model.StatusUnclassified))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count classifications by merchant: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	counts := make(map[string]int, len(vendors))
+	for rows.Next() {
+		var merchant, category string
+		var count int
+		if err := rows.Scan(&merchant, &category, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan classification count: %w", err)
+		}
+		if vendor := resolveVendor(vendors, merchant, s.regexPrecedence, s.vendorCaseSensitive); vendor != nil && vendor.Category == category {
+			counts[vendor.Name] += count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating classification counts: %w", err)
+	}
+	_ = rows.Close()
+
+	var changes []model.VendorRecount
+	for _, vendor := range vendors {
+		count := counts[vendor.Name]
+		if count == vendor.UseCount {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE vendors SET use_count = ? WHERE name = ?
+		`, count, vendor.Name); err != nil {
+			return nil, fmt.Errorf("failed to update use count for vendor %q: %w", vendor.Name, err)
+		}
+		changes = append(changes, model.VendorRecount{Name: vendor.Name, OldCount: vendor.UseCount, NewCount: count})
+	}
+
+	// Clear cache since cached vendors carry the old counts
+	s.cacheMutex.Lock()
+	s.vendorCache = make(map[string]*model.Vendor)
+	s.cacheMutex.Unlock()
+
+	return changes, nil
+}
+
+// resolveVendor picks the vendor FindVendorMatch would return for merchantName
+// from an in-memory list: an exact name, then a case-insensitive name unless
+// matching is case sensitive, then the winning regex under the given precedence.
+func resolveVendor(vendors []model.Vendor, merchantName string, precedence model.RegexVendorPrecedence, caseSensitive bool) *model.Vendor {
+	var caseless, regex *model.Vendor
+	for i := range vendors {
+		vendor := &vendors[i]
+		if vendor.IsRegex {
+			matched, err := common.MatchRegex(vendor.Name, merchantName)
+			if err != nil || !matched {
+				continue
+			}
+			// Vendors are sorted by name, so ties keep the earlier name as the query does
+			if regex == nil || regexVendorBeats(precedence, vendor, regex) {
+				regex = vendor
+			}
+			continue
+		}
+		if vendor.Name == merchantName {
+			return vendor
+		}
+		if !caseSensitive && strings.EqualFold(vendor.Name, merchantName) &&
+			(caseless == nil || vendor.UseCount > caseless.UseCount) {
+			caseless = vendor
+		}
+	}
+	if caseless != nil {
+		return caseless
+	}
+	return regex
+}
+
+// SetVendorMultiCategory marks or unmarks a merchant as spanning many
+// categories. Names match existing vendors ignoring case, unless vendor
+// matching is case sensitive. Marking a merchant without a vendor rule adds a
+// vendor with no category; unmarking it removes that vendor again, while a
+// merchant that had a rule before being marked gets the rule back. Unmarking
+// an unknown merchant returns common.ErrNotFound.
+func (s *SQLiteStorage) SetVendorMultiCategory(ctx context.Context, merchantName string, multiCategory bool) error {
+	if err := validateContext(ctx); err != nil {
+		return err
+	}
+	if err := s.checkWritable("mark vendor"); err != nil {
+		return err
+	}
+	if err := validateString(merchantName, "merchantName"); err != nil {
+		return err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := s.setVendorMultiCategoryTx(ctx, tx, merchantName, multiCategory); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLiteStorage) setVendorMultiCategoryTx(ctx context.Context, tx *sql.Tx, merchantName string, multiCategory bool) error {
+	if multiCategory {
+		// Mark an existing rule under its own spelling rather than adding a twin
+		var existing string
+		err := tx.QueryRowContext(ctx, `
+			SELECT name FROM vendors WHERE name = ? COLLATE `+s.vendorNameCollation()+` ORDER BY name = ? DESC, name LIMIT 1
+		`, merchantName, merchantName).Scan(&existing)
+		switch {
+		case err == nil:
+			merchantName = existing
+		case err != sql.ErrNoRows:
+			return fmt.Errorf("failed to check vendor: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO vendors (name, category, last_updated, use_count, source, is_regex, multi_category)
+			VALUES (?, '', ?, 0, ?, FALSE, TRUE)
+			ON CONFLICT(name) DO UPDATE SET
+				multi_category = TRUE,
+				last_updated = excluded.last_updated
+		`, merchantName, time.Now(), model.SourceManual)
+		if err != nil {
+			return fmt.Errorf("failed to mark vendor multi-category: %w", err)
+		}
+	} else {
+		// Without a category of its own the vendor only existed for the mark
+		deleted, err := tx.ExecContext(ctx, `
+			DELETE FROM vendors WHERE name = ? COLLATE `+s.vendorNameCollation()+` AND category = '' AND multi_category = TRUE
+		`, merchantName)
+		if err != nil {
+			return fmt.Errorf("failed to unmark vendor: %w", err)
+		}
+		updated, err := tx.ExecContext(ctx, `
+			UPDATE vendors SET multi_category = FALSE WHERE name = ? COLLATE `+s.vendorNameCollation()+` AND multi_category = TRUE
+		`, merchantName)
+		if err != nil {
+			return fmt.Errorf("failed to unmark vendor: %w", err)
+		}
+		deletedRows, err := deleted.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		updatedRows, err := updated.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if deletedRows == 0 && updatedRows == 0 {
+			return common.ErrNotFound
+		}
+	}
+
+	// Cached vendors, including regex and case-insensitive matches, may be stale
+	s.cacheMutex.Lock()
+	s.vendorCache = make(map[string]*model.Vendor)
+	s.cacheMutex.Unlock()
+
+	return nil
+}
+
+// isMultiCategoryVendorTx reports whether a merchant is marked multi-category,
+// ignoring case unless vendor matching is case sensitive.
+func (s *SQLiteStorage) isMultiCategoryVendorTx(ctx context.Context, q queryable, merchantName string) (bool, error) {
+	var multiCategory bool
+	err := q.QueryRowContext(ctx, `
+		SELECT EXISTS(SELECT 1 FROM vendors WHERE name = ? COLLATE `+s.vendorNameCollation()+` AND multi_category = TRUE)
+	`, merchantName).Scan(&multiCategory)
+	if err != nil {
+		return false, fmt.Errorf("failed to check vendor: %w", err)
+	}
+	return multiCategory, nil
+}
+
+// vendorNameCollation is the SQLite collation merchant names are compared to
+// vendor names with.
+func (s *SQLiteStorage) vendorNameCollation() string {
+	if s.vendorCaseSensitive {
+		return "BINARY"
+	}
+	return "NOCASE"
 }
@@ -3,10 +3,13 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/Veraticus/the-spice-must-flow/internal/common"
 	"github.com/Veraticus/the-spice-must-flow/internal/model"
 )
 
@@ -849,6 +852,107 @@ func TestFindVendorMatch_RegexPriority(t *testing.T) {
 	}
 }
 
+func TestFindVendorMatch_ExactBeatsRegex(t *testing.T) {
+	ctx := context.Background()
+	store, cleanup := createTestStorageWithCategories(t, "Shopping", "Marketplace")
+	defer cleanup()
+
+	vendors := []*model.Vendor{
+		{Name: "Amazon", Category: "Shopping", Source: model.SourceManual},
+		// A heavily used regex must still lose to an exact rule.
+		{Name: ".*amazon.*", Category: "Marketplace", Source: model.SourceManual, IsRegex: true, UseCount: 500},
+	}
+	for _, v := range vendors {
+		if err := store.SaveVendor(ctx, v); err != nil {
+			t.Fatalf("Failed to save vendor %q: %v", v.Name, err)
+		}
+	}
+
+	for _, precedence := range []model.RegexVendorPrecedence{model.PrecedenceUseCount, model.PrecedenceSpecificity} {
+		if err := store.SetRegexVendorPrecedence(precedence); err != nil {
+			t.Fatalf("Failed to set precedence: %v", err)
+		}
+		for _, merchant := range []string{"Amazon", "amazon", "AMAZON"} {
+			match, err := store.FindVendorMatch(ctx, merchant)
+			if err != nil {
+				t.Fatalf("%s/%s: failed to find vendor match: %v", precedence, merchant, err)
+			}
+			if match.Name != "Amazon" || match.Category != "Shopping" {
+				t.Errorf("%s/%s: expected exact vendor Amazon/Shopping, got %s/%s",
+					precedence, merchant, match.Name, match.Category)
+			}
+		}
+	}
+
+	// Merchants that only the regex covers still fall through to it.
+	match, err := store.FindVendorMatch(ctx, "www.amazon.com")
+	if err != nil {
+		t.Fatalf("Failed to find vendor match: %v", err)
+	}
+	if match.Name != ".*amazon.*" {
+		t.Errorf("Expected regex vendor, got %q", match.Name)
+	}
+}
+
+func TestFindVendorMatch_RegexSpecificityPrecedence(t *testing.T) {
+	ctx := context.Background()
+	store, cleanup := createTestStorageWithCategories(t, "Broad", "Specific")
+	defer cleanup()
+
+	vendors := []*model.Vendor{
+		{Name: "AMAZON.*", Category: "Broad", Source: model.SourceManual, IsRegex: true, UseCount: 100},
+		{Name: "AMAZON MKTPL.*", Category: "Specific", Source: model.SourceManual, IsRegex: true, UseCount: 5},
+	}
+	for _, v := range vendors {
+		if err := store.SaveVendor(ctx, v); err != nil {
+			t.Fatalf("Failed to save vendor %q: %v", v.Name, err)
+		}
+	}
+
+	tests := []struct {
+		precedence model.RegexVendorPrecedence
+		want       string
+	}{
+		{precedence: "", want: "AMAZON.*"},
+		{precedence: model.PrecedenceUseCount, want: "AMAZON.*"},
+		{precedence: model.PrecedenceSpecificity, want: "AMAZON MKTPL.*"},
+	}
+	for _, tt := range tests {
+		if err := store.SetRegexVendorPrecedence(tt.precedence); err != nil {
+			t.Fatalf("Failed to set precedence %q: %v", tt.precedence, err)
+		}
+		match, err := store.FindVendorMatch(ctx, "AMAZON MKTPL*AB12")
+		if err != nil {
+			t.Fatalf("%q: failed to find vendor match: %v", tt.precedence, err)
+		}
+		if match.Name != tt.want {
+			t.Errorf("%q: expected %q, got %q", tt.precedence, tt.want, match.Name)
+		}
+	}
+
+	if err := store.SetRegexVendorPrecedence("newest"); err == nil {
+		t.Error("Expected error for unknown precedence")
+	}
+}
+
+func TestRegexSpecificity(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    int
+	}{
+		{pattern: ".*", want: 0},
+		{pattern: ".*amazon.*", want: 6},
+		{pattern: "^AMAZON MKTPL.*$", want: 12},
+		{pattern: `UBER\s*EATS`, want: 9},
+		{pattern: "(SHELL|EXXON)", want: 10},
+	}
+	for _, tt := range tests {
+		if got := regexSpecificity(tt.pattern); got != tt.want {
+			t.Errorf("regexSpecificity(%q) = %d, want %d", tt.pattern, got, tt.want)
+		}
+	}
+}
+
 func TestFindVendorMatch_InvalidRegex(t *testing.T) {
 	ctx := context.Background()
 	store, cleanup := createTestStorage(t)
@@ -952,3 +1056,299 @@ func TestVendorCRUD_WithRegex(t *testing.T) {
 		t.Error("Expected IsRegex to be false in list")
 	}
 }
+
+func TestSQLiteStorage_RecomputeVendorUseCounts(t *testing.T) {
+	store, cleanup := createTestStorageWithCategories(t, "Shopping", "Food", "Income")
+	defer cleanup()
+	ctx := context.Background()
+
+	vendors := []*model.Vendor{
+		{Name: "Amazon", Category: "Shopping", UseCount: 40},
+		{Name: "PAYROLL.*COMPANY", Category: "Income", IsRegex: true},
+		{Name: "Unused", Category: "Food", UseCount: 5},
+	}
+	for _, v := range vendors {
+		if err := store.SaveVendor(ctx, v); err != nil {
+			t.Fatalf("Failed to save vendor: %v", err)
+		}
+	}
+
+	classified := []struct {
+		merchant string
+		category string
+		status   model.ClassificationStatus
+	}{
+		{"Amazon", "Shopping", model.StatusClassifiedByAI},
+		{"Amazon", "Shopping", model.StatusClassifiedByAI},
+		{"amazon", "Shopping", model.StatusClassifiedByAI}, // Case-insensitive match
+		{"Amazon", "Food", model.StatusClassifiedByAI},     // Different category, not the vendor's doing
+		{"PAYROLL ACME COMPANY", "Income", model.StatusClassifiedByAI},
+		{"PAYROLL 42 COMPANY", "Income", model.StatusClassifiedByAI},
+		{"Unused", "", model.StatusUnclassified}, // Skipped transactions don't count
+	}
+	for i, c := range classified {
+		txn := model.Transaction{
+			ID:           fmt.Sprintf("txn-%d", i),
+			MerchantName: c.merchant,
+			Name:         c.merchant,
+			Amount:       float64(10 + i),
+			Date:         makeTestTime().AddDate(0, 0, i),
+			AccountID:    "acc1",
+		}
+		txn.Hash = txn.GenerateHash()
+		if err := store.SaveTransactions(ctx, []model.Transaction{txn}); err != nil {
+			t.Fatalf("Failed to save transaction: %v", err)
+		}
+		classification := &model.Classification{Transaction: txn, Category: c.category, Status: c.status}
+		if err := store.SaveClassification(ctx, classification); err != nil {
+			t.Fatalf("Failed to save classification: %v", err)
+		}
+	}
+
+	changes, err := store.RecomputeVendorUseCounts(ctx)
+	if err != nil {
+		t.Fatalf("RecomputeVendorUseCounts failed: %v", err)
+	}
+
+	want := map[string][2]int{
+		"Amazon":           {40, 3},
+		"PAYROLL.*COMPANY": {0, 2},
+		"Unused":           {5, 0},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("Got %d changes, want %d: %+v", len(changes), len(want), changes)
+	}
+	for _, change := range changes {
+		counts, ok := want[change.Name]
+		if !ok {
+			t.Errorf("Unexpected change for vendor %q", change.Name)
+			continue
+		}
+		if change.OldCount != counts[0] || change.NewCount != counts[1] {
+			t.Errorf("Vendor %q: got %d -> %d, want %d -> %d",
+				change.Name, change.OldCount, change.NewCount, counts[0], counts[1])
+		}
+
+		vendor, err := store.GetVendor(ctx, change.Name)
+		if err != nil {
+			t.Fatalf("Failed to get vendor: %v", err)
+		}
+		if vendor.UseCount != counts[1] {
+			t.Errorf("Stored use count for %q = %d, want %d", change.Name, vendor.UseCount, counts[1])
+		}
+	}
+
+	// A second run has nothing left to fix
+	changes, err = store.RecomputeVendorUseCounts(ctx)
+	if err != nil {
+		t.Fatalf("RecomputeVendorUseCounts failed: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("Second recount changed %d vendors, want none: %+v", len(changes), changes)
+	}
+}
+
+func TestSQLiteStorage_SetVendorMultiCategory(t *testing.T) {
+	store, cleanup := createTestStorageWithCategories(t, "Shopping", "Groceries")
+	defer cleanup()
+	ctx := context.Background()
+
+	if err := store.SaveVendor(ctx, &model.Vendor{Name: "Target", Category: "Shopping"}); err != nil {
+		t.Fatalf("SaveVendor failed: %v", err)
+	}
+	// Warm the cache so the mark has to invalidate it
+	if _, err := store.FindVendorMatch(ctx, "Target"); err != nil {
+		t.Fatalf("FindVendorMatch failed: %v", err)
+	}
+
+	// Marking matches the existing rule ignoring case
+	if err := store.SetVendorMultiCategory(ctx, "TARGET", true); err != nil {
+		t.Fatalf("SetVendorMultiCategory failed: %v", err)
+	}
+	vendor, err := store.FindVendorMatch(ctx, "Target")
+	if err != nil {
+		t.Fatalf("FindVendorMatch failed: %v", err)
+	}
+	if !vendor.MultiCategory || vendor.Category != "Shopping" {
+		t.Errorf("expected marked vendor keeping its category, got %+v", vendor)
+	}
+
+	// Neither a vendor save nor a classification turns it back into a rule
+	err = store.SaveVendor(ctx, &model.Vendor{Name: "Target", Category: "Groceries"})
+	if !errors.Is(err, ErrMultiCategoryVendor) {
+		t.Errorf("expected ErrMultiCategoryVendor, got %v", err)
+	}
+	txn := model.Transaction{ID: "t1", Hash: "h1", Date: time.Now(), Name: "TARGET 123", MerchantName: "Target", Amount: 25, AccountID: "acc1"}
+	if err := store.SaveTransactions(ctx, []model.Transaction{txn}); err != nil {
+		t.Fatalf("SaveTransactions failed: %v", err)
+	}
+	err = store.SaveClassification(ctx, &model.Classification{Transaction: txn, Category: "Groceries", Status: model.StatusUserModified, Confidence: 1})
+	if err != nil {
+		t.Fatalf("SaveClassification failed: %v", err)
+	}
+	vendor, err = store.GetVendor(ctx, "Target")
+	if err != nil {
+		t.Fatalf("GetVendor failed: %v", err)
+	}
+	if vendor.Category != "Shopping" || vendor.UseCount != 0 {
+		t.Errorf("classification changed the marked vendor: %+v", vendor)
+	}
+
+	// Unmarking brings the rule back
+	if err := store.SetVendorMultiCategory(ctx, "Target", false); err != nil {
+		t.Fatalf("SetVendorMultiCategory failed: %v", err)
+	}
+	vendor, err = store.GetVendor(ctx, "Target")
+	if err != nil {
+		t.Fatalf("GetVendor failed: %v", err)
+	}
+	if vendor.MultiCategory || vendor.Category != "Shopping" {
+		t.Errorf("expected the rule back, got %+v", vendor)
+	}
+	if err := store.SetVendorMultiCategory(ctx, "Target", false); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("expected ErrNotFound unmarking an unmarked vendor, got %v", err)
+	}
+
+	// A merchant without a rule is added for the mark and removed with it
+	if err := store.SetVendorMultiCategory(ctx, "Amazon", true); err != nil {
+		t.Fatalf("SetVendorMultiCategory failed: %v", err)
+	}
+	if vendor, err := store.GetVendor(ctx, "Amazon"); err != nil || !vendor.MultiCategory || vendor.Category != "" {
+		t.Errorf("expected marked vendor without category, got %+v, %v", vendor, err)
+	}
+	if err := store.SetVendorMultiCategory(ctx, "Amazon", false); err != nil {
+		t.Fatalf("SetVendorMultiCategory failed: %v", err)
+	}
+	if _, err := store.GetVendor(ctx, "Amazon"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected the vendor to be removed, got %v", err)
+	}
+}
+
+func TestFindVendorMatch_CaseSensitivity(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		caseSensitive bool
+		want          map[string]string // merchant -> matching vendor name
+	}{
+		{
+			caseSensitive: false,
+			want: map[string]string{
+				"AMAZON":      "AMAZON",
+				"Amazon":      "Amazon",
+				"amazon":      "AMAZON", // Ignoring case, the more used rule wins
+				"Whole Foods": "(?i)^whole foods",
+				"STARBUCKS":   "Starbucks",
+				"starbucks":   "Starbucks",
+			},
+		},
+		{
+			caseSensitive: true,
+			want: map[string]string{
+				"AMAZON":      "AMAZON",
+				"Amazon":      "Amazon",
+				"amazon":      ".*amazon.*", // Only the regex matches now
+				"Whole Foods": "(?i)^whole foods",
+				"STARBUCKS":   "",
+				"starbucks":   "",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("case sensitive %t", tt.caseSensitive), func(t *testing.T) {
+			store, cleanup := createTestStorageWithCategories(t, "Shopping", "Marketplace", "Groceries", "Coffee")
+			defer cleanup()
+
+			// Vendors that differ only in case predate the setting
+			vendors := []*model.Vendor{
+				{Name: "AMAZON", Category: "Shopping", UseCount: 10},
+				{Name: "Amazon", Category: "Marketplace", UseCount: 2},
+				{Name: ".*amazon.*", Category: "Marketplace", IsRegex: true},
+				{Name: "(?i)^whole foods", Category: "Groceries", IsRegex: true},
+				{Name: "Starbucks", Category: "Coffee"},
+			}
+			for _, v := range vendors {
+				if _, err := store.db.ExecContext(ctx, `
+					INSERT INTO vendors (name, category, use_count, source, is_regex) VALUES (?, ?, ?, ?, ?)
+				`, v.Name, v.Category, v.UseCount, model.SourceManual, v.IsRegex); err != nil {
+					t.Fatalf("Failed to insert vendor %q: %v", v.Name, err)
+				}
+			}
+			store.SetVendorCaseSensitive(tt.caseSensitive)
+
+			for merchant, want := range tt.want {
+				match, err := store.FindVendorMatch(ctx, merchant)
+				if want == "" {
+					if !errors.Is(err, sql.ErrNoRows) {
+						t.Errorf("%s: expected no match, got %+v, %v", merchant, match, err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("%s: failed to find vendor match: %v", merchant, err)
+				}
+				if match.Name != want {
+					t.Errorf("%s: expected vendor %q, got %q", merchant, want, match.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestSaveVendor_CaseSensitivity(t *testing.T) {
+	ctx := context.Background()
+	store, cleanup := createTestStorageWithCategories(t, "Shopping", "Marketplace")
+	defer cleanup()
+
+	if err := store.SaveVendor(ctx, &model.Vendor{Name: "AMAZON", Category: "Shopping", UseCount: 3}); err != nil {
+		t.Fatalf("SaveVendor failed: %v", err)
+	}
+
+	// Ignoring case, a rule for another spelling updates the existing one
+	vendor := &model.Vendor{Name: "Amazon", Category: "Marketplace", UseCount: 4}
+	if err := store.SaveVendor(ctx, vendor); err != nil {
+		t.Fatalf("SaveVendor failed: %v", err)
+	}
+	if vendor.Name != "AMAZON" {
+		t.Errorf("Expected the saved vendor to take the existing spelling, got %q", vendor.Name)
+	}
+	vendors, err := store.GetAllVendors(ctx)
+	if err != nil {
+		t.Fatalf("GetAllVendors failed: %v", err)
+	}
+	if len(vendors) != 1 || vendors[0].Category != "Marketplace" {
+		t.Fatalf("Expected one updated AMAZON rule, got %+v", vendors)
+	}
+	if rule, err := store.GetVendorRule(ctx, "amazon"); err != nil || rule.Name != "AMAZON" {
+		t.Errorf("Expected amazon's rule to be AMAZON, got %+v, %v", rule, err)
+	}
+
+	// Regex vendors are never folded into an exact rule
+	if err := store.SaveVendor(ctx, &model.Vendor{Name: "amazon", Category: "Shopping", IsRegex: true}); err != nil {
+		t.Fatalf("SaveVendor failed: %v", err)
+	}
+
+	// Case-sensitively the spellings are separate rules
+	store.SetVendorCaseSensitive(true)
+	if err := store.SaveVendor(ctx, &model.Vendor{Name: "Amazon", Category: "Shopping"}); err != nil {
+		t.Fatalf("SaveVendor failed: %v", err)
+	}
+	vendors, err = store.GetAllVendors(ctx)
+	if err != nil {
+		t.Fatalf("GetAllVendors failed: %v", err)
+	}
+	if len(vendors) != 3 {
+		t.Errorf("Expected AMAZON, Amazon and the amazon regex, got %+v", vendors)
+	}
+	if _, err := store.GetVendorRule(ctx, "AmAzOn"); err != sql.ErrNoRows {
+		t.Errorf("Expected no rule for another spelling, got %v", err)
+	}
+
+	// A multi-category mark only covers its own spelling
+	if err := store.SetVendorMultiCategory(ctx, "Amazon", true); err != nil {
+		t.Fatalf("SetVendorMultiCategory failed: %v", err)
+	}
+	if err := store.SaveVendor(ctx, &model.Vendor{Name: "AMAZON", Category: "Shopping"}); err != nil {
+		t.Errorf("Expected AMAZON to stay an ordinary rule, got %v", err)
+	}
+}
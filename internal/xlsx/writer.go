@@ -0,0 +1,595 @@
+// Package xlsx writes reports as Excel workbooks, an alternative to Google Sheets.
+package xlsx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
+	"github.com/Veraticus/the-spice-must-flow/internal/sheets"
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+)
+
+// Worksheet names, the same as the Google Sheets tabs.
+const (
+	ExpensesSheet         = "Expenses"
+	IncomeSheet           = "Income"
+	VendorSummarySheet    = "Vendor Summary"
+	CategorySummarySheet  = "Category Summary"
+	BusinessExpensesSheet = "Business Expenses"
+	MonthlyFlowSheet      = "Monthly Flow"
+	VendorLookupSheet     = "Vendor Lookup"
+	CategoryLookupSheet   = "Category Lookup"
+	BusinessRulesSheet    = "Business Rules"
+	ForeignCurrencySheet  = "Foreign Currency"
+)
+
+// Number formats, matching the Google Sheets report.
+const (
+	currencyFormat      = "$#,##0.00"
+	wholeCurrencyFormat = "$#,##0"
+	percentFormat       = "0%"
+	dateFormat          = "yyyy-mm-dd"
+	amountFormat        = "#,##0.00"
+	rateFormat          = "0.000000"
+)
+
+// Writer implements the ReportWriter interface with an Excel workbook holding
+// the same tabs as the Google Sheets report. The numbers come from the same
+// aggregation, and the Sheets formulas are written as their Excel
+// equivalents, so editing the lookup sheets still changes the categories and
+// business percentages of the transactions.
+type Writer struct {
+	logger *slog.Logger
+	path   string
+	config sheets.Config
+}
+
+// lookupHeadroom is how many rows can be added to a lookup sheet and still be
+// found. Lookups use bounded ranges rather than whole columns, which keeps
+// recalculating large workbooks fast.
+const lookupHeadroom = 1000
+
+// formula is a cell holding an Excel formula, without the leading "=".
+type formula string
+
+// worksheet is the content of a worksheet: rows of cells starting at A1, the
+// number format of some columns below the header row, and bold rows.
+type worksheet struct {
+	name     string
+	rows     [][]any
+	formats  map[int]string // 1-based column to number format
+	boldRows []int          // 1-based rows besides the header
+}
+
+// NewWriter creates an Excel report writer for the workbook at path. Only the
+// report settings of config are used, like deductible rounding and the sign
+// convention.
+func NewWriter(path string, config sheets.Config, logger *slog.Logger) (*Writer, error) {
+	if path == "" {
+		return nil, fmt.Errorf("output file is required")
+	}
+	if !strings.EqualFold(filepath.Ext(path), ".xlsx") {
+		return nil, fmt.Errorf("output file %q must end in .xlsx", path)
+	}
+	if err := config.ValidateReport(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return &Writer{
+		path:   path,
+		config: config,
+		logger: logger,
+	}, nil
+}
+
+// Write implements the ReportWriter interface. The workbook is replaced if it
+// exists. The Foreign Currency sheet is only added when the report has
+// foreign-currency transactions.
+func (w *Writer) Write(ctx context.Context, classifications []model.Classification, summary *service.ReportSummary, categories []model.Category) error {
+	w.logger.Info("starting Excel report generation",
+		"classifications", len(classifications),
+		"path", w.path)
+
+	data, err := sheets.Aggregate(classifications, summary, categories, w.config)
+	if err != nil {
+		return fmt.Errorf("failed to aggregate data: %w", err)
+	}
+
+	lookups := lookupSizes{
+		vendors:    len(data.VendorLookup),
+		categories: len(data.CategoryLookup),
+		rules:      len(data.BusinessRulesLookup),
+	}
+	worksheets := []worksheet{
+		expensesSheet(data.Expenses, lookups),
+		incomeSheet(data.Income, lookups),
+		w.vendorSummarySheet(data.VendorSummary, lookups),
+		categorySummarySheet(data.CategorySummary, data.DateRange.Start.Year(), lookups),
+		w.businessExpensesSheet(data.BusinessExpenses),
+		monthlyFlowSheet(data.MonthlyFlow),
+		vendorLookupSheet(data.VendorLookup),
+		categoryLookupSheet(data.CategoryLookup),
+		businessRulesSheet(data.BusinessRulesLookup),
+	}
+	if len(data.ForeignCurrency) > 0 {
+		worksheets = append(worksheets, foreignCurrencySheet(data.ForeignCurrency, data.TotalFXGainLoss))
+	}
+
+	file := excelize.NewFile()
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil {
+			w.logger.Warn("failed to close workbook", "error", closeErr)
+		}
+	}()
+
+	styles, err := newStyles(file)
+	if err != nil {
+		return fmt.Errorf("failed to create styles: %w", err)
+	}
+
+	for i, sheet := range worksheets {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if i == 0 {
+			err = file.SetSheetName(file.GetSheetName(0), sheet.name)
+		} else {
+			_, err = file.NewSheet(sheet.name)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to add %s sheet: %w", sheet.name, err)
+		}
+		if err := writeWorksheet(file, sheet, styles); err != nil {
+			return fmt.Errorf("failed to write %s sheet: %w", sheet.name, err)
+		}
+	}
+
+	// No formula has a cached value, so have Excel calculate them on open
+	fullCalcOnLoad := true
+	if err := file.SetCalcProps(&excelize.CalcPropsOptions{FullCalcOnLoad: &fullCalcOnLoad}); err != nil {
+		return fmt.Errorf("failed to set calculation properties: %w", err)
+	}
+
+	if dir := filepath.Dir(w.path); dir != "." {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+	if err := file.SaveAs(w.path); err != nil {
+		return fmt.Errorf("failed to save workbook: %w", err)
+	}
+
+	w.logger.Info("Excel report generation completed",
+		"path", w.path,
+		"sheets", len(worksheets),
+		"total_income", data.TotalIncome,
+		"total_expenses", data.TotalExpenses,
+		"net_flow", data.TotalIncome.Sub(data.TotalExpenses))
+
+	return nil
+}
+
+// lookupSizes are the rows written to each lookup sheet.
+type lookupSizes struct {
+	vendors    int
+	categories int
+	rules      int
+}
+
+// styles holds the IDs of the workbook's cell styles.
+type styles struct {
+	header  int
+	bold    int
+	total   int
+	formats map[string]int
+}
+
+func newStyles(file *excelize.File) (*styles, error) {
+	s := &styles{formats: make(map[string]int)}
+
+	var err error
+	if s.header, err = file.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"E6E6E6"}},
+	}); err != nil {
+		return nil, err
+	}
+	if s.bold, err = file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}}); err != nil {
+		return nil, err
+	}
+	totalFormat := currencyFormat
+	if s.total, err = file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}, CustomNumFmt: &totalFormat}); err != nil {
+		return nil, err
+	}
+
+	for _, numberFormat := range []string{currencyFormat, wholeCurrencyFormat, percentFormat, dateFormat, amountFormat, rateFormat} {
+		numberFormat := numberFormat
+		id, styleErr := file.NewStyle(&excelize.Style{CustomNumFmt: &numberFormat})
+		if styleErr != nil {
+			return nil, styleErr
+		}
+		s.formats[numberFormat] = id
+	}
+	return s, nil
+}
+
+// writeWorksheet writes a worksheet's cells and styles, with a bold header
+// row frozen at the top.
+func writeWorksheet(file *excelize.File, sheet worksheet, s *styles) error {
+	width := 0
+	for r, row := range sheet.rows {
+		width = max(width, len(row))
+		for c, value := range row {
+			cell, err := excelize.CoordinatesToCellName(c+1, r+1)
+			if err != nil {
+				return err
+			}
+			if f, ok := value.(formula); ok {
+				err = file.SetCellFormula(sheet.name, cell, string(f))
+			} else {
+				err = file.SetCellValue(sheet.name, cell, value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if width == 0 {
+		return nil
+	}
+
+	lastColumn, err := excelize.ColumnNumberToName(width)
+	if err != nil {
+		return err
+	}
+	if err := file.SetCellStyle(sheet.name, "A1", lastColumn+"1", s.header); err != nil {
+		return err
+	}
+
+	if len(sheet.rows) > 1 {
+		for column, numberFormat := range sheet.formats {
+			name, nameErr := excelize.ColumnNumberToName(column)
+			if nameErr != nil {
+				return nameErr
+			}
+			if err := file.SetCellStyle(sheet.name, name+"2", fmt.Sprintf("%s%d", name, len(sheet.rows)), s.formats[numberFormat]); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, row := range sheet.boldRows {
+		style := s.bold
+		if len(sheet.rows[row-1]) > 1 {
+			style = s.total
+		}
+		if err := file.SetCellStyle(sheet.name, fmt.Sprintf("A%d", row), fmt.Sprintf("%s%d", lastColumn, row), style); err != nil {
+			return err
+		}
+	}
+
+	if err := file.SetColWidth(sheet.name, "A", lastColumn, 16); err != nil {
+		return err
+	}
+	return file.SetPanes(sheet.name, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+}
+
+// lookupRange is the range from A1 to lastColumn of a lookup sheet with rows
+// rows below its header, plus the headroom for rows added by hand.
+func lookupRange(sheet, lastColumn string, rows int) string {
+	return fmt.Sprintf("'%s'!$A$1:$%s$%d", sheet, lastColumn, rows+1+lookupHeadroom)
+}
+
+// quote escapes text for a string literal in a formula.
+func quote(text string) string {
+	return `"` + strings.ReplaceAll(text, `"`, `""`) + `"`
+}
+
+// expensesSheet lists expenses. The category comes from the Vendor Lookup
+// sheet and the business percentage from the Business Rules sheet or the
+// category's default in Category Lookup, falling back to the report's values.
+func expensesSheet(expenses []sheets.ExpenseRow, lookups lookupSizes) worksheet {
+	sheet := worksheet{
+		name:    ExpensesSheet,
+		rows:    [][]any{{"Date", "Amount", "Vendor", "Category", "Business %", "Notes"}},
+		formats: map[int]string{1: dateFormat, 2: currencyFormat, 5: percentFormat},
+	}
+
+	// INDEX(...,0) evaluates the array match without entering it as an
+	// array formula
+	lastRule := lookups.rules + 1 + lookupHeadroom
+	vendorLookup := lookupRange(VendorLookupSheet, "B", lookups.vendors)
+	categoryLookup := lookupRange(CategoryLookupSheet, "D", lookups.categories)
+	for i, expense := range expenses {
+		row := i + 2
+		categoryFormula := formula(fmt.Sprintf(`IFERROR(VLOOKUP(C%d,%s,2,FALSE),%s)`, row, vendorLookup, quote(expense.Category)))
+		businessPctFormula := formula(fmt.Sprintf(
+			`IFERROR(INDEX('Business Rules'!$C$1:$C$%d,MATCH(1,INDEX((C%d='Business Rules'!$A$1:$A$%d)*(D%d='Business Rules'!$B$1:$B$%d),0),0)),IFERROR(VLOOKUP(D%d,%s,4,FALSE)/100,%g))`,
+			lastRule, row, lastRule, row, lastRule, row, categoryLookup, float64(expense.BusinessPct)/100,
+		))
+		sheet.rows = append(sheet.rows, []any{
+			expense.Date,
+			expense.Amount.InexactFloat64(),
+			expense.Vendor,
+			categoryFormula,
+			businessPctFormula,
+			expense.Notes,
+		})
+	}
+	return sheet
+}
+
+// incomeSheet lists income, with the category from the Vendor Lookup sheet.
+func incomeSheet(income []sheets.IncomeRow, lookups lookupSizes) worksheet {
+	sheet := worksheet{
+		name:    IncomeSheet,
+		rows:    [][]any{{"Date", "Amount", "Source", "Category", "Notes"}},
+		formats: map[int]string{1: dateFormat, 2: currencyFormat},
+	}
+	vendorLookup := lookupRange(VendorLookupSheet, "B", lookups.vendors)
+	for i, inc := range income {
+		row := i + 2
+		sheet.rows = append(sheet.rows, []any{
+			inc.Date,
+			inc.Amount.InexactFloat64(),
+			inc.Source,
+			formula(fmt.Sprintf(`IFERROR(VLOOKUP(C%d,%s,2,FALSE),%s)`, row, vendorLookup, quote(inc.Category))),
+			inc.Notes,
+		})
+	}
+	return sheet
+}
+
+// vendorSummarySheet totals each vendor's transactions with formulas over the
+// Expenses and Income sheets. Confidence and consistency are values, since
+// the sheets don't carry confidences.
+func (w *Writer) vendorSummarySheet(vendors []sheets.VendorSummaryRow, lookups lookupSizes) worksheet {
+	header := []any{"Vendor Name", "Category", "Total Amount", "Transaction Count"}
+	if w.config.VendorConfidence {
+		header = append(header, "Avg Confidence", "Consistency")
+	}
+	sheet := worksheet{
+		name:    VendorSummarySheet,
+		rows:    [][]any{header},
+		formats: map[int]string{3: currencyFormat},
+	}
+	if w.config.VendorConfidence {
+		sheet.formats[5] = percentFormat
+		sheet.formats[6] = percentFormat
+	}
+
+	vendorLookup := lookupRange(VendorLookupSheet, "B", lookups.vendors)
+	for i, vendor := range vendors {
+		row := i + 2
+		cells := []any{
+			vendor.VendorName,
+			formula(fmt.Sprintf(`IFERROR(VLOOKUP(A%d,%s,2,FALSE),"")`, row, vendorLookup)),
+			formula(fmt.Sprintf(`SUMIF(Expenses!$C:$C,A%d,Expenses!$B:$B)+SUMIF(Income!$C:$C,A%d,Income!$B:$B)`, row, row)),
+			formula(fmt.Sprintf(`COUNTIF(Expenses!$C:$C,A%d)+COUNTIF(Income!$C:$C,A%d)`, row, row)),
+		}
+		if w.config.VendorConfidence {
+			var consistency any = "n/a"
+			if vendor.TransactionCount > 1 {
+				consistency = vendor.Consistency
+			}
+			cells = append(cells, vendor.AverageConfidence, consistency)
+		}
+		sheet.rows = append(sheet.rows, cells)
+	}
+	return sheet
+}
+
+// categorySummarySheet totals each category with formulas over the Expenses
+// and Income sheets, income categories first, with a month per column of the
+// report's year.
+func categorySummarySheet(categories []sheets.CategorySummaryRow, year int, lookups lookupSizes) worksheet {
+	sheet := worksheet{
+		name: CategorySummarySheet,
+		rows: [][]any{{
+			"Category", "Type", "Total Amount", "Count", "Avg Business % (Edit in Category Lookup)",
+			"Jan", "Feb", "Mar", "Apr", "May", "Jun",
+			"Jul", "Aug", "Sep", "Oct", "Nov", "Dec",
+		}},
+		formats: map[int]string{3: currencyFormat, 5: percentFormat},
+	}
+	for column := 6; column <= 17; column++ {
+		sheet.formats[column] = wholeCurrencyFormat
+	}
+
+	var incomeCategories, expenseCategories []sheets.CategorySummaryRow
+	for _, cat := range categories {
+		if cat.Type == "Income" {
+			incomeCategories = append(incomeCategories, cat)
+		} else {
+			expenseCategories = append(expenseCategories, cat)
+		}
+	}
+
+	categoryLookup := lookupRange(CategoryLookupSheet, "B", lookups.categories)
+	section := func(title, tab, defaultType string, group []sheets.CategorySummaryRow) {
+		if len(group) == 0 {
+			return
+		}
+		sheet.rows = append(sheet.rows, []any{}, []any{title})
+		sheet.boldRows = append(sheet.boldRows, len(sheet.rows))
+
+		for _, cat := range group {
+			row := len(sheet.rows) + 1
+			var businessPct any = ""
+			if tab == ExpensesSheet {
+				businessPct = formula(fmt.Sprintf(`IFERROR(AVERAGEIF(Expenses!$D:$D,A%d,Expenses!$E:$E),0)`, row))
+			}
+			cells := []any{
+				cat.CategoryName,
+				formula(fmt.Sprintf(`IFERROR(VLOOKUP(A%d,%s,2,FALSE),%s)`, row, categoryLookup, quote(defaultType))),
+				formula(fmt.Sprintf(`SUMIF(%s!$D:$D,A%d,%s!$B:$B)`, tab, row, tab)),
+				formula(fmt.Sprintf(`COUNTIF(%s!$D:$D,A%d)`, tab, row)),
+				businessPct,
+			}
+			for month := 1; month <= 12; month++ {
+				cells = append(cells, formula(fmt.Sprintf(
+					`SUMIFS(%s!$B:$B,%s!$D:$D,A%d,%s!$A:$A,">="&DATE(%d,%d,1),%s!$A:$A,"<"&DATE(%d,%d,1))`,
+					tab, tab, row, tab, year, month, tab, year, month+1,
+				)))
+			}
+			sheet.rows = append(sheet.rows, cells)
+		}
+	}
+	section("INCOME CATEGORIES", IncomeSheet, "Income", incomeCategories)
+	section("EXPENSE CATEGORIES", ExpensesSheet, "Expense", expenseCategories)
+
+	return sheet
+}
+
+// businessExpensesSheet lists business expenses under a header per category,
+// with the deductible subtotal of each category and the grand total rounded
+// like the Google Sheets report.
+func (w *Writer) businessExpensesSheet(expenses []sheets.BusinessExpenseRow) worksheet {
+	sheet := worksheet{
+		name:    BusinessExpensesSheet,
+		rows:    [][]any{{"Date", "Vendor", "Category", "Amount", "Business %", "Deductible", "Notes"}},
+		formats: map[int]string{1: dateFormat, 4: currencyFormat, 5: percentFormat, 6: currencyFormat},
+	}
+	total := func(label string, amount decimal.Decimal) {
+		sheet.rows = append(sheet.rows, []any{"", "", label, "", "", w.config.RoundDeductibleTotal(amount).InexactFloat64(), ""})
+		sheet.boldRows = append(sheet.boldRows, len(sheet.rows))
+	}
+
+	currentCategory := ""
+	categoryTotal := decimal.Zero
+	grandTotal := decimal.Zero
+	for _, expense := range expenses {
+		if expense.Category != currentCategory {
+			if currentCategory != "" && !categoryTotal.IsZero() {
+				total(fmt.Sprintf("Subtotal - %s", currentCategory), categoryTotal)
+			}
+			sheet.rows = append(sheet.rows, []any{}, []any{fmt.Sprintf("CATEGORY: %s", expense.Category)})
+			sheet.boldRows = append(sheet.boldRows, len(sheet.rows))
+			currentCategory = expense.Category
+			categoryTotal = decimal.Zero
+		}
+
+		sheet.rows = append(sheet.rows, []any{
+			expense.Date,
+			expense.Vendor,
+			expense.Category,
+			expense.OriginalAmount.InexactFloat64(),
+			float64(expense.BusinessPct) / 100,
+			expense.DeductibleAmount.InexactFloat64(),
+			expense.Notes,
+		})
+		categoryTotal = categoryTotal.Add(expense.DeductibleAmount)
+		grandTotal = grandTotal.Add(expense.DeductibleAmount)
+	}
+	if currentCategory != "" && !categoryTotal.IsZero() {
+		total(fmt.Sprintf("Subtotal - %s", currentCategory), categoryTotal)
+	}
+
+	if !grandTotal.IsZero() {
+		sheet.rows = append(sheet.rows, []any{})
+		total("GRAND TOTAL (Schedule C)", grandTotal)
+	}
+	return sheet
+}
+
+// monthlyFlowSheet lists each month's cash flow, then the totals and the
+// monthly averages.
+func monthlyFlowSheet(monthlyFlow []sheets.MonthlyFlowRow) worksheet {
+	sheet := worksheet{
+		name:    MonthlyFlowSheet,
+		rows:    [][]any{{"Month", "Total Income", "Total Expenses", "Net Flow", "Running Balance"}},
+		formats: map[int]string{2: currencyFormat, 3: currencyFormat, 4: currencyFormat, 5: currencyFormat},
+	}
+	if len(monthlyFlow) == 0 {
+		return sheet
+	}
+
+	// Sum net flow rather than derive it, since the columns may be signed
+	var totalIncome, totalExpenses, netFlow decimal.Decimal
+	for _, month := range monthlyFlow {
+		sheet.rows = append(sheet.rows, []any{
+			month.Month,
+			month.TotalIncome.InexactFloat64(),
+			month.TotalExpenses.InexactFloat64(),
+			month.NetFlow.InexactFloat64(),
+			month.RunningBalance.InexactFloat64(),
+		})
+		totalIncome = totalIncome.Add(month.TotalIncome)
+		totalExpenses = totalExpenses.Add(month.TotalExpenses)
+		netFlow = netFlow.Add(month.NetFlow)
+	}
+
+	monthCount := decimal.NewFromInt(int64(len(monthlyFlow)))
+	sheet.rows = append(sheet.rows,
+		[]any{},
+		[]any{"YEARLY TOTALS", totalIncome.InexactFloat64(), totalExpenses.InexactFloat64(), netFlow.InexactFloat64(), ""},
+		[]any{"MONTHLY AVERAGES", totalIncome.Div(monthCount).InexactFloat64(), totalExpenses.Div(monthCount).InexactFloat64(), netFlow.Div(monthCount).InexactFloat64(), ""},
+	)
+	sheet.boldRows = append(sheet.boldRows, len(sheet.rows)-1, len(sheet.rows))
+	return sheet
+}
+
+func foreignCurrencySheet(rows []sheets.ForeignCurrencyRow, totalGainLoss decimal.Decimal) worksheet {
+	sheet := worksheet{
+		name: ForeignCurrencySheet,
+		rows: [][]any{{"Date", "Vendor", "Category", "Currency", "Original Amount", "Posted Amount", "Effective Rate", "Average Rate", "FX Gain/Loss"}},
+		formats: map[int]string{
+			1: dateFormat, 5: amountFormat, 6: currencyFormat,
+			7: rateFormat, 8: rateFormat, 9: currencyFormat,
+		},
+	}
+	for _, row := range rows {
+		sheet.rows = append(sheet.rows, []any{
+			row.Date,
+			row.Vendor,
+			row.Category,
+			row.OriginalCurrency,
+			row.OriginalAmount.InexactFloat64(),
+			row.PostedAmount.InexactFloat64(),
+			row.EffectiveRate.Round(6).InexactFloat64(),
+			row.AverageRate.Round(6).InexactFloat64(),
+			row.FXGainLoss.InexactFloat64(),
+		})
+	}
+	sheet.rows = append(sheet.rows, []any{}, []any{"TOTAL FX GAIN/LOSS", "", "", "", "", "", "", "", totalGainLoss.InexactFloat64()})
+	sheet.boldRows = append(sheet.boldRows, len(sheet.rows))
+	return sheet
+}
+
+func vendorLookupSheet(vendors []sheets.VendorLookupRow) worksheet {
+	sheet := worksheet{name: VendorLookupSheet, rows: [][]any{{"Vendor", "Category"}}}
+	for _, vendor := range vendors {
+		sheet.rows = append(sheet.rows, []any{vendor.VendorName, vendor.Category})
+	}
+	return sheet
+}
+
+func categoryLookupSheet(categories []sheets.CategoryLookupRow) worksheet {
+	sheet := worksheet{name: CategoryLookupSheet, rows: [][]any{{"Category", "Type", "Description", "Default Business %"}}}
+	for _, cat := range categories {
+		sheet.rows = append(sheet.rows, []any{cat.CategoryName, cat.Type, cat.Description, cat.DefaultBusinessPct})
+	}
+	return sheet
+}
+
+// businessRulesSheet lists vendor-specific business percentages. Unlike
+// Category Lookup's whole numbers they are fractions, so the Expenses sheet
+// shows them as they are.
+func businessRulesSheet(rules []sheets.BusinessRuleLookupRow) worksheet {
+	sheet := worksheet{
+		name:    BusinessRulesSheet,
+		rows:    [][]any{{"Vendor Pattern", "Category", "Business %", "Notes"}},
+		formats: map[int]string{3: percentFormat},
+	}
+	for _, rule := range rules {
+		sheet.rows = append(sheet.rows, []any{rule.VendorPattern, rule.Category, float64(rule.BusinessPct) / 100, rule.Notes})
+	}
+	return sheet
+}
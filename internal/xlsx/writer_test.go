@@ -0,0 +1,185 @@
+package xlsx
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Veraticus/the-spice-must-flow/internal/model"
+	"github.com/Veraticus/the-spice-must-flow/internal/service"
+	"github.com/Veraticus/the-spice-must-flow/internal/sheets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func testReport() ([]model.Classification, *service.ReportSummary, []model.Category) {
+	classifications := []model.Classification{
+		{
+			Transaction: model.Transaction{ID: "1", Hash: "h1", Date: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), MerchantName: "Grocery Store", Amount: 50.10},
+			Category:    "Groceries",
+			Status:      model.StatusClassifiedByAI,
+			Confidence:  0.95,
+		},
+		{
+			Transaction:     model.Transaction{ID: "2", Hash: "h2", Date: time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC), MerchantName: "Gas Station", Amount: 40},
+			Category:        "Transportation",
+			Status:          model.StatusUserModified,
+			Confidence:      1.0,
+			BusinessPercent: 50,
+		},
+		{
+			Transaction: model.Transaction{ID: "3", Hash: "h3", Date: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), MerchantName: "Salary Deposit", Amount: 1000},
+			Category:    "Income",
+			Status:      model.StatusClassifiedByRule,
+			Confidence:  1.0,
+		},
+	}
+	summary := &service.ReportSummary{
+		DateRange: service.DateRange{
+			Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	categories := []model.Category{
+		{ID: 1, Name: "Groceries", Type: model.CategoryTypeExpense},
+		{ID: 2, Name: "Transportation", Type: model.CategoryTypeExpense, DefaultBusinessPercent: 50},
+		{ID: 3, Name: "Income", Type: model.CategoryTypeIncome},
+	}
+	return classifications, summary, categories
+}
+
+func writeReport(t *testing.T, config sheets.Config) *excelize.File {
+	t.Helper()
+	classifications, summary, categories := testReport()
+	path := filepath.Join(t.TempDir(), "reports", "report.xlsx")
+
+	writer, err := NewWriter(path, config, slog.Default())
+	require.NoError(t, err)
+	require.NoError(t, writer.Write(context.Background(), classifications, summary, categories))
+
+	file, err := excelize.OpenFile(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = file.Close() })
+	return file
+}
+
+func TestNewWriter(t *testing.T) {
+	_, err := NewWriter("", sheets.DefaultConfig(), slog.Default())
+	require.Error(t, err)
+
+	_, err = NewWriter("report.csv", sheets.DefaultConfig(), slog.Default())
+	require.ErrorContains(t, err, ".xlsx")
+
+	config := sheets.DefaultConfig()
+	config.DeductibleRounding = "sometimes"
+	_, err = NewWriter("report.xlsx", config, slog.Default())
+	require.Error(t, err)
+
+	_, err = NewWriter("Report.XLSX", sheets.DefaultConfig(), slog.Default())
+	require.NoError(t, err)
+}
+
+func TestWriter_Write(t *testing.T) {
+	file := writeReport(t, sheets.DefaultConfig())
+
+	assert.Equal(t, []string{
+		ExpensesSheet, IncomeSheet, VendorSummarySheet, CategorySummarySheet, BusinessExpensesSheet,
+		MonthlyFlowSheet, VendorLookupSheet, CategoryLookupSheet, BusinessRulesSheet,
+	}, file.GetSheetList(), "no foreign currency sheet without foreign transactions")
+
+	header, err := file.GetRows(ExpensesSheet)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Date", "Amount", "Vendor", "Category", "Business %", "Notes"}, header[0])
+
+	headerStyle, err := file.GetCellStyle(ExpensesSheet, "A1")
+	require.NoError(t, err)
+	style, err := file.GetStyle(headerStyle)
+	require.NoError(t, err)
+	assert.True(t, style.Font.Bold)
+
+	dateStyle, err := file.GetCellStyle(ExpensesSheet, "A2")
+	require.NoError(t, err)
+	style, err = file.GetStyle(dateStyle)
+	require.NoError(t, err)
+	require.NotNil(t, style.CustomNumFmt)
+	assert.Equal(t, dateFormat, *style.CustomNumFmt)
+
+	amountStyle, err := file.GetCellStyle(ExpensesSheet, "B2")
+	require.NoError(t, err)
+	style, err = file.GetStyle(amountStyle)
+	require.NoError(t, err)
+	require.NotNil(t, style.CustomNumFmt)
+	assert.Equal(t, currencyFormat, *style.CustomNumFmt)
+
+	formula, err := file.GetCellFormula(ExpensesSheet, "D2")
+	require.NoError(t, err)
+	assert.Contains(t, formula, "VLOOKUP(C2,'Vendor Lookup'!$A$1:$B$1004,2,FALSE)")
+
+	rows, err := file.GetRows(BusinessExpensesSheet)
+	require.NoError(t, err)
+	var labels []string
+	for _, row := range rows {
+		if len(row) > 2 {
+			labels = append(labels, row[2])
+		}
+	}
+	assert.Contains(t, labels, "Subtotal - Transportation")
+	assert.Contains(t, labels, "GRAND TOTAL (Schedule C)")
+}
+
+func TestWriter_Write_LookupsDriveCategories(t *testing.T) {
+	file := writeReport(t, sheets.DefaultConfig())
+
+	rows, err := file.GetRows(ExpensesSheet)
+	require.NoError(t, err)
+	gasRow := 0
+	for i, row := range rows {
+		if len(row) > 2 && row[2] == "Gas Station" {
+			gasRow = i + 1
+		}
+	}
+	require.NotZero(t, gasRow)
+	category := func() string {
+		value, calcErr := file.CalcCellValue(ExpensesSheet, "D"+itoa(gasRow))
+		require.NoError(t, calcErr)
+		return value
+	}
+	businessPct := func() string {
+		value, calcErr := file.CalcCellValue(ExpensesSheet, "E"+itoa(gasRow), excelize.Options{RawCellValue: true})
+		require.NoError(t, calcErr)
+		return value
+	}
+
+	assert.Equal(t, "Transportation", category())
+	assert.Equal(t, "0.5", businessPct(), "the category default from Category Lookup")
+
+	// Re-pointing the vendor in the lookup sheet re-categorizes its expenses
+	lookups, err := file.GetRows(VendorLookupSheet)
+	require.NoError(t, err)
+	for i, row := range lookups {
+		if row[0] == "Gas Station" {
+			require.NoError(t, file.SetCellValue(VendorLookupSheet, "B"+itoa(i+1), "Groceries"))
+		}
+	}
+	assert.Equal(t, "Groceries", category())
+	assert.Equal(t, "0", businessPct())
+}
+
+func TestWriter_Write_VendorConfidence(t *testing.T) {
+	config := sheets.DefaultConfig()
+	config.VendorConfidence = true
+	file := writeReport(t, config)
+
+	rows, err := file.GetRows(VendorSummarySheet)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Vendor Name", "Category", "Total Amount", "Transaction Count", "Avg Confidence", "Consistency"}, rows[0])
+	assert.Equal(t, "n/a", rows[1][5])
+}
+
+func itoa(n int) string {
+	name, _ := excelize.CoordinatesToCellName(1, n)
+	return name[1:]
+}